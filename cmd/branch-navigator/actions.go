@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+func actionDetailsFor(act action) ui.ActionDetails {
+	spec, _ := app.LookupAction(act)
+	return spec.Details
+}
+
+// actionFlags records which action flags were passed on the command line.
+type actionFlags struct {
+	set map[action]*bool
+}
+
+// define adds a flag for every registered action to fs.
+func (f *actionFlags) define(fs *flag.FlagSet, usage func(string) string) {
+	f.set = make(map[action]*bool, len(app.Actions))
+	for _, spec := range app.Actions {
+		f.set[spec.Action] = fs.Bool(spec.Flag, false, usage(spec.Flag))
+	}
+}
+
+// explicit reports whether any action flag was passed.
+func (f actionFlags) explicit() bool {
+	for _, set := range f.set {
+		if *set {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveAction(flags actionFlags) (action, error) {
+	selected := []action{}
+	names := make([]string, len(app.Actions))
+	for i, spec := range app.Actions {
+		if set := flags.set[spec.Action]; set != nil && *set {
+			selected = append(selected, spec.Action)
+		}
+		names[i] = flagName(spec.Flag)
+	}
+
+	switch len(selected) {
+	case 0:
+		return actionCheckout, nil
+	case 1:
+		return selected[0], nil
+	default:
+		last := len(names) - 1
+		return "", errors.New("only one of " + strings.Join(names[:last], ", ") + ", or " + names[last] + " may be specified")
+	}
+}
+
+// flagName spells a flag the way the usage does: -c, but --archive.
+func flagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// menuDetails describes the branch list of --menu, where the action is
+// chosen after the branch. Any branch can be picked; menuActions narrows
+// the actions to the ones that apply to it.
+var menuDetails = ui.ActionDetails{
+	Name:         "Choose branch",
+	Description:  "Select a branch, then choose the action to run on it.",
+	EnterLabel:   "choose an action for the selected branch",
+	AllowCurrent: true,
+}
+
+// pickMenuAction shows the actions applicable to the selected branch and
+// returns the one chosen; ok is false when the user quits.
+func pickMenuAction(style selectorStyle, layout ui.Layout, in io.Reader, out io.Writer, selected ui.Branch, custom map[string]config.CustomAction) (act action, ok bool, err error) {
+	specs := app.MenuActions(selected, customActionSpecs(custom))
+	rows := make([]ui.Branch, len(specs))
+	for i, spec := range specs {
+		rows[i] = ui.Branch{Name: string(spec.Action), Detail: spec.Details.Description}
+	}
+	menu := style.selector(in, out, ui.ActionDetails{
+		Name:        "Branch " + selected.Name,
+		Description: "Choose what to do with the selected branch.",
+		EnterLabel:  "run the action",
+	})
+	menu.SetLayout(layout)
+	picked, err := menu.Select(rows)
+	if err != nil || picked.Quit {
+		return "", false, err
+	}
+	return action(picked.Branch), true, nil
+}
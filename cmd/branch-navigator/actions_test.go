@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/ui"
+)
+
+func TestActionRegistryFlags(t *testing.T) {
+	t.Parallel()
+
+	documented := map[string]bool{}
+	for _, f := range rootCommand.Flags {
+		documented[f.Name] = true
+	}
+	for _, spec := range app.Actions {
+		if !documented[spec.Flag] {
+			t.Errorf("action %s uses flag %q, which rootCommand does not document", spec.Action, spec.Flag)
+		}
+	}
+}
+
+func TestPickMenuAction(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys     string
+		selected ui.Branch
+		want     action
+		wantOK   bool
+	}{
+		"merge":         {keys: "2\n", selected: ui.Branch{Name: "feature/x"}, want: actionMerge, wantOK: true},
+		"remote diff":   {keys: "6\n", selected: ui.Branch{Name: "origin/x", Remote: true}, want: actionDiff, wantOK: true},
+		"current push":  {keys: "1\n", selected: ui.Branch{Name: "main", Current: true}, want: actionPush, wantOK: true},
+		"quit the menu": {keys: "q\n", selected: ui.Branch{Name: "feature/x"}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			got, ok, err := pickMenuAction(testStyle, ui.LayoutPlain, newKeys(tc.keys), out, tc.selected, nil)
+			if err != nil {
+				t.Fatalf("pickMenuAction returned error: %v", err)
+			}
+			if got != tc.want || ok != tc.wantOK {
+				t.Fatalf("pickMenuAction() = %q, %v, want %q, %v", got, ok, tc.want, tc.wantOK)
+			}
+			if !strings.Contains(out.String(), "Branch "+tc.selected.Name) {
+				t.Fatalf("expected the menu to name the branch, got %q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+// acceptsMultiSelect reports whether --multi can apply act to several
+// branches at once.
+func acceptsMultiSelect(act action) bool {
+	switch act {
+	case actionMergeCheck, actionPush, actionArchive:
+		return true
+	default:
+		return false
+	}
+}
+
+// runBatchAction applies act to every branch, reporting progress on errOut
+// and a line per branch on out when all of them are done. It returns the
+// exit code of the batch, which tells a partial failure from a complete one.
+func runBatchAction(ctx context.Context, client *git.Client, cfg config.Config, style selectorStyle, act action, branches []string, out, errOut io.Writer) int {
+	step := batchStep(client, cfg, style, act, errOut)
+	if step == nil {
+		fmt.Fprintf(errOut, "--multi cannot be used with the %s action\n", act)
+		return 2
+	}
+
+	report := app.RunBatch(ctx, branches, step, errOut)
+	fmt.Fprintf(out, "%s: %d %s\n", actionDetailsFor(act).Name, len(branches), plural(len(branches), "branch", "branches"))
+	report.Write(out)
+	return report.ExitCode()
+}
+
+// batchStep returns the step that applies act, one of the actions
+// acceptsMultiSelect accepts, to a branch without asking anything, or nil
+// for any other action. The output of git goes to errOut.
+func batchStep(client *git.Client, cfg config.Config, style selectorStyle, act action, errOut io.Writer) app.BatchFunc {
+	switch act {
+	case actionMergeCheck:
+		return func(ctx context.Context, branch string) (string, error) {
+			prediction, err := client.PredictMerge(ctx, branch)
+			if err != nil {
+				return "", err
+			}
+			return mergeCheckOutcome(prediction)
+		}
+	case actionPush:
+		return func(ctx context.Context, branch string) (string, error) {
+			if err := handlePushAction(ctx, client, style, nil, errOut, errOut, branch, cfg.PushAutoSetupUpstream); err != nil {
+				return "", err
+			}
+			return "pushed", nil
+		}
+	case actionArchive:
+		return func(ctx context.Context, branch string) (string, error) {
+			tag, err := client.ArchiveBranch(ctx, branch)
+			if err != nil {
+				return "", err
+			}
+			return "archived as tag " + tag, nil
+		}
+	default:
+		return nil
+	}
+}
+
+// mergeCheckOutcome reports a clean trial merge as a success and one with
+// conflicts as a failure naming the files.
+func mergeCheckOutcome(prediction git.MergePrediction) (string, error) {
+	switch n := len(prediction.Conflicts); {
+	case n == 0:
+		return "merges cleanly", nil
+	case n > maxListedConflicts:
+		return "", fmt.Errorf("conflicts in %d files: %s, …", n, strings.Join(prediction.Conflicts[:maxListedConflicts], ", "))
+	default:
+		return "", fmt.Errorf("conflicts in %d %s: %s", n, plural(n, "file", "files"), strings.Join(prediction.Conflicts, ", "))
+	}
+}
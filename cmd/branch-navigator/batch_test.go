@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestRunBatchAction(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		act      action
+		branches []string
+		wantCode int
+		wantOut  []string
+		wantTags string
+	}{
+		"merge-check": {
+			act:      actionMergeCheck,
+			branches: []string{"clean", "conflicting"},
+			wantCode: app.BatchPartial,
+			wantOut:  []string{"Merge check: 2 branches\n", "  ✓ clean        merges cleanly\n", "  ✗ conflicting  conflicts in 1 file: notes.txt\n", "1 succeeded, 1 failed.\n"},
+		},
+		"archive": {
+			act:      actionArchive,
+			branches: []string{"clean", "conflicting"},
+			wantCode: app.BatchSucceeded,
+			wantOut:  []string{"  ✓ clean        archived as tag archive/clean\n", "2 succeeded, 0 failed.\n"},
+			wantTags: "archive/clean\narchive/conflicting",
+		},
+		"archive of the current branch": {
+			act:      actionArchive,
+			branches: []string{gittest.DefaultBranch},
+			wantCode: app.BatchFailed,
+			wantOut:  []string{"cannot archive the current branch", "0 succeeded, 1 failed.\n"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			repo.WriteFile("notes.txt", "base\n")
+			repo.Commit("add notes")
+			repo.Branch("clean", "")
+			repo.CheckoutNew("conflicting")
+			repo.WriteFile("notes.txt", "from feature\n")
+			repo.Commit("edit notes on feature")
+			repo.Checkout(gittest.DefaultBranch)
+			repo.WriteFile("notes.txt", "from main\n")
+			repo.Commit("edit notes on main")
+
+			client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			code := runBatchAction(context.Background(), client, config.Default(), testStyle, tc.act, tc.branches, out, errOut)
+			if code != tc.wantCode {
+				t.Fatalf("exit code %d, want %d; output:\n%s%s", code, tc.wantCode, out, errOut)
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+			if !strings.Contains(errOut.String(), "==> [1/") {
+				t.Fatalf("progress = %q, want every branch announced", errOut.String())
+			}
+			if got := repo.Git("tag", "--list", "archive/*"); got != tc.wantTags {
+				t.Fatalf("archive tags = %q, want %q", got, tc.wantTags)
+			}
+		})
+	}
+}
+
+func TestParseArgsMulti(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"push":             {args: []string{"--multi", "--push"}},
+		"merge-check":      {args: []string{"--merge-check", "--multi"}},
+		"checkout refused": {args: []string{"--multi"}, wantErr: "--multi can only be used with --merge-check, --push, or --archive"},
+		"pick refused":     {args: []string{"--multi", "--archive", "--pick", "x"}, wantErr: "--multi cannot be combined with"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("parseArgs error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if !opts.multi {
+				t.Fatal("expected --multi to be set")
+			}
+		})
+	}
+}
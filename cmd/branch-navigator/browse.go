@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/hosting"
+)
+
+// urlOpener shows a URL in a web browser.
+type urlOpener interface {
+	Open(url string) error
+}
+
+// handleBrowseAction opens the code host's page for branch, on the remote
+// chosen by branchRepository. template is the browse.url_template setting
+// for self-hosted servers.
+func handleBrowseAction(ctx context.Context, client *git.Client, opener urlOpener, out io.Writer, branch string, remote bool, template string) error {
+	if client == nil {
+		return fmt.Errorf("git client is not configured")
+	}
+
+	repo, remoteBranch, err := branchRepository(ctx, client, branch, remote)
+	if err != nil {
+		return err
+	}
+	page, err := hosting.BranchURL(repo, remoteBranch, template)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Opening %s\n", page)
+	return opener.Open(page)
+}
+
+// branchRepository returns the repository on the code host that holds
+// branch, and the name of the branch there. A local branch is looked up on
+// the remote of its upstream, or on origin when it has none; a
+// remote-tracking branch on its own remote.
+func branchRepository(ctx context.Context, client *git.Client, branch string, remote bool) (hosting.Repository, string, error) {
+	remotes, err := client.Remotes(ctx)
+	if err != nil {
+		return hosting.Repository{}, "", err
+	}
+	remoteName, remoteBranch := git.DefaultRemote, branch
+	if remote {
+		// Prefer the longest remote name, in case one is a prefix of another.
+		matched := ""
+		for _, candidate := range remotes {
+			if strings.HasPrefix(branch, candidate.Name+"/") && len(candidate.Name) > len(matched) {
+				matched = candidate.Name
+			}
+		}
+		if matched != "" {
+			remoteName, remoteBranch = matched, strings.TrimPrefix(branch, matched+"/")
+		}
+	} else if upstream, err := client.BranchUpstream(ctx, branch); err == nil && upstream.Remote != "" {
+		remoteName, remoteBranch = upstream.Remote, upstream.Branch
+	}
+
+	url := ""
+	for _, candidate := range remotes {
+		if candidate.Name == remoteName {
+			url = candidate.URL
+		}
+	}
+	if url == "" {
+		return hosting.Repository{}, "", fmt.Errorf("remote '%s' is not configured", remoteName)
+	}
+	repo, err := hosting.ParseRemoteURL(url)
+	if err != nil {
+		return hosting.Repository{}, "", err
+	}
+	return repo, remoteBranch, nil
+}
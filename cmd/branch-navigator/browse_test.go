@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+// recordingOpener remembers the URLs it was asked to open.
+type recordingOpener struct {
+	urls []string
+}
+
+func (o *recordingOpener) Open(url string) error {
+	o.urls = append(o.urls, url)
+	return nil
+}
+
+func TestHandleBrowseAction(t *testing.T) {
+	t.Parallel()
+
+	remotes := "origin\tgit@github.com:org/repo.git (fetch)\n" +
+		"origin-fork\thttps://gitlab.com/me/repo.git (fetch)\n" +
+		"internal\tssh://git@git.example.com/tools/repo.git (fetch)"
+	upstreamArgs := "for-each-ref --format=%(upstream:remotename)%09%(upstream:remoteref) refs/heads/feature/x"
+
+	cases := map[string]struct {
+		branch   string
+		remote   bool
+		upstream string
+		template string
+		want     string
+		wantErr  string
+	}{
+		"local without upstream": {
+			branch: "feature/x",
+			want:   "https://github.com/org/repo/tree/feature/x",
+		},
+		"local with upstream": {
+			branch:   "feature/x",
+			upstream: "origin-fork\trefs/heads/topic/x",
+			want:     "https://gitlab.com/me/repo/-/tree/topic/x",
+		},
+		"remote-tracking branch": {
+			branch: "origin-fork/feature/y",
+			remote: true,
+			want:   "https://gitlab.com/me/repo/-/tree/feature/y",
+		},
+		"self-hosted with template": {
+			branch:   "internal/main",
+			remote:   true,
+			template: "https://{host}/{path}/src/branch/{branch}",
+			want:     "https://git.example.com/tools/repo/src/branch/main",
+		},
+		"self-hosted without template": {
+			branch:  "internal/main",
+			remote:  true,
+			wantErr: "set browse.url_template",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"remote -v": remotes, upstreamArgs: tc.upstream}}
+			opener := &recordingOpener{}
+			out := &bytes.Buffer{}
+			err := handleBrowseAction(context.Background(), git.NewClient(runner), opener, out, tc.branch, tc.remote, tc.template)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handleBrowseAction returned error: %v", err)
+			}
+			if len(opener.urls) != 1 || opener.urls[0] != tc.want {
+				t.Fatalf("opened %q, want %q", opener.urls, tc.want)
+			}
+			if out.String() != "Opening "+tc.want+"\n" {
+				t.Fatalf("output = %q", out.String())
+			}
+		})
+	}
+}
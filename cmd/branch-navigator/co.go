@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/history"
+)
+
+// coSelection is what co leaves to the selector when no single branch
+// contains its text: the root options to run with and the filter to open
+// the list with.
+type coSelection struct {
+	args  []string
+	query string
+}
+
+// runCoCommand implements the co subcommand. It checks out the one local
+// branch containing the text and returns nil with the process exit code;
+// when there is no such branch, it returns the selection the selector
+// should open with instead. newClient builds the client from the
+// --git-config settings, and uncommitted changes are handled as in the
+// selector, with questions read from in.
+func runCoCommand(ctx context.Context, newClient func(gitConfig []string) *git.Client, store *history.Store, args []string, in io.Reader, out, errOut io.Writer) (*coSelection, int) {
+	fs := flag.NewFlagSet("branch-navigator co", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(out, coCommand.Usage(programName))
+	}
+	forceState := fs.Bool("force-state", false, coCommand.FlagUsage("force-state"))
+	var gitConfig []string
+	fs.Func("git-config", coCommand.FlagUsage("git-config"), gitConfigFlag(&gitConfig))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, 0
+		}
+		return nil, 2
+	}
+
+	text := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if text == "" {
+		fmt.Fprint(errOut, coCommand.Usage(programName))
+		return nil, 2
+	}
+
+	client := newClient(gitConfig)
+	branches, err := client.BranchesByCommitDate(ctx)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return nil, 1
+	}
+	branch, ok := containingBranch(text, branches)
+	if !ok {
+		selection := &coSelection{query: text}
+		if *forceState {
+			selection.args = []string{"--force-state"}
+		}
+		for _, setting := range gitConfig {
+			selection.args = append(selection.args, "--git-config", setting)
+		}
+		return selection, 0
+	}
+
+	if _, err := checkOperation(ctx, client, actionCheckout, *forceState); err != nil {
+		fmt.Fprintln(errOut, err)
+		return nil, 1
+	}
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return nil, 1
+	}
+	proceed, err := prepareCheckout(ctx, client, in, out, branch)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return nil, 1
+	}
+	if !proceed {
+		fmt.Fprintln(out, "Checkout cancelled.")
+		return nil, 0
+	}
+	message, ok, err := checkoutBranch(ctx, client, in, out, branch, false)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return nil, 1
+	}
+	if !ok {
+		fmt.Fprintln(out, "Checkout cancelled.")
+		return nil, 0
+	}
+	printIfNotEmpty(out, message)
+	if branch != current {
+		recordAction(ctx, store, client, errOut, actionCheckout, current, branch)
+	}
+	return nil, 0
+}
+
+// containingBranch returns the branch named text, or else the only branch
+// whose name contains text, ignoring case.
+func containingBranch(text string, branches []string) (string, bool) {
+	lower := strings.ToLower(text)
+	found := ""
+	count := 0
+	for _, branch := range branches {
+		if branch == text {
+			return branch, true
+		}
+		if strings.Contains(strings.ToLower(branch), lower) {
+			found = branch
+			count++
+		}
+	}
+	return found, count == 1
+}
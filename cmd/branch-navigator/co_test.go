@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestContainingBranch(t *testing.T) {
+	t.Parallel()
+
+	branches := []string{"main", "main-old", "feature/fix-login", "feature/Fix-Logout", "release/1.0"}
+	cases := map[string]struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		"unique substring":    {text: "login", want: "feature/fix-login", wantOK: true},
+		"ignores case":        {text: "LOGOUT", want: "feature/Fix-Logout", wantOK: true},
+		"whole name wins":     {text: "main", want: "main", wantOK: true},
+		"several candidates":  {text: "fix-log"},
+		"no candidate":        {text: "hotfix"},
+		"prefix of many":      {text: "feature/"},
+		"unique with a slash": {text: "release/", want: "release/1.0", wantOK: true},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := containingBranch(tc.text, branches)
+			if ok != tc.wantOK || (ok && got != tc.want) {
+				t.Fatalf("containingBranch(%q) = %q, %v; want %q, %v", tc.text, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestRunCoCommand(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/fix-login", "")
+	repo.Branch("feature/fix-logout", "")
+	var gotConfig []string
+	newClient := func(gitConfig []string) *git.Client {
+		gotConfig = gitConfig
+		return git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env(), Config: gitConfig})
+	}
+	ctx := context.Background()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	selection, code := runCoCommand(ctx, newClient, nil, []string{"--force-state", "--git-config", "core.abbrev=12", "fix-log"}, newKeys(""), out, errOut)
+	if code != 0 || selection == nil {
+		t.Fatalf("co fix-log = %+v, exit code %d; want the selector; stderr %q", selection, code, errOut.String())
+	}
+	if selection.query != "fix-log" || strings.Join(selection.args, " ") != "--force-state --git-config core.abbrev=12" {
+		t.Fatalf("selection = %+v, want the text as the filter and --force-state and --git-config kept", selection)
+	}
+	if strings.Join(gotConfig, " ") != "core.abbrev=12" {
+		t.Fatalf("client config = %v, want core.abbrev=12", gotConfig)
+	}
+	if got := repo.Git("branch", "--show-current"); got != gittest.DefaultBranch {
+		t.Fatalf("current branch = %s, want nothing checked out", got)
+	}
+
+	selection, code = runCoCommand(ctx, newClient, nil, []string{"logout"}, newKeys(""), out, errOut)
+	if code != 0 || selection != nil {
+		t.Fatalf("co logout = %+v, exit code %d; want a checkout; stderr %q", selection, code, errOut.String())
+	}
+	if got := repo.Git("branch", "--show-current"); got != "feature/fix-logout" {
+		t.Fatalf("current branch = %s, want feature/fix-logout", got)
+	}
+
+	if selection, code := runCoCommand(ctx, newClient, nil, nil, newKeys(""), out, errOut); code != 2 || selection != nil {
+		t.Fatalf("co without text = %+v, exit code %d; want usage", selection, code)
+	}
+	if selection, code := runCoCommand(ctx, newClient, nil, []string{"--git-config", "abbrev", "login"}, newKeys(""), out, errOut); code != 2 || selection != nil {
+		t.Fatalf("co with an invalid --git-config = %+v, exit code %d; want 2", selection, code)
+	}
+}
+
+func TestRunCoCommandUncommittedChanges(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys       string
+		wantBranch string
+		wantOut    string
+	}{
+		"cancel":  {keys: "c\n", wantBranch: gittest.DefaultBranch, wantOut: "Checkout cancelled."},
+		"proceed": {keys: "p\n", wantBranch: "feature/fix-login"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			repo.Branch("feature/fix-login", "")
+			repo.WriteFile("notes.txt", "draft")
+			newClient := func(gitConfig []string) *git.Client {
+				return git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env(), Config: gitConfig})
+			}
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			selection, code := runCoCommand(context.Background(), newClient, nil, []string{"login"}, newKeys(tc.keys), out, errOut)
+			if code != 0 || selection != nil {
+				t.Fatalf("co login = %+v, exit code %d; stderr %q", selection, code, errOut.String())
+			}
+			if !strings.Contains(out.String(), "uncommitted changes: 1 modified") || !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("output = %q, want the question about uncommitted changes and %q", out.String(), tc.wantOut)
+			}
+			if got := repo.Git("branch", "--show-current"); got != tc.wantBranch {
+				t.Fatalf("current branch = %s, want %s", got, tc.wantBranch)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import "branch-navigator/internal/cli"
+
+const programName = "branch-navigator"
+
+// switchCommand documents the switch subcommand.
+var switchCommand = cli.Command{
+	Name:     "switch",
+	Synopsis: "<query>",
+	Summary:  "check out the branch that best fuzzy-matches <query>",
+	Description: `Fuzzy-match <query> against local branches and check out the best match.
+When several branches match equally well, they are listed and nothing is checked out.
+While a merge, rebase, or bisect is in progress, nothing is checked out either
+unless --force-state is given.`,
+	Flags: []cli.Flag{
+		{Name: "force-state", Usage: "check out the match even while a merge, rebase, or bisect is in progress"},
+	},
+}
+
+// coCommand documents the co subcommand.
+var coCommand = cli.Command{
+	Name:     "co",
+	Synopsis: "<text>",
+	Summary:  "check out the one local branch whose name contains <text>, or pick among the candidates in the selector",
+	Description: `Check out the local branch whose name contains <text>, ignoring case, right
+away when it is the only one or its whole name. Otherwise open the selector
+with <text> as its filter, so that you pick among the candidates, or among
+fuzzy matches when no name contains it.
+Uncommitted changes are handled as in the selector. While a merge, rebase,
+or bisect is in progress, nothing is checked out unless --force-state is
+given.`,
+	Flags: []cli.Flag{
+		{Name: "force-state", Usage: "check out the branch even while a merge, rebase, or bisect is in progress"},
+		{Name: "git-config", Arg: "KEY=VALUE", Usage: "pass -c KEY=VALUE to every git command, as in the selector (repeatable)"},
+	},
+}
+
+// listCommand documents the list subcommand.
+var listCommand = cli.Command{
+	Name:     "list",
+	Synopsis: "[--json | --format FORMAT] [--columns LIST]",
+	Summary:  "print local branches as plain names, JSON, TSV, or CSV",
+	Description: `Print local branches, the current branch first and the rest by most recent commit.
+JSON output is an array of objects with name, current, lastCommitDate,
+upstream, ahead, behind, and merged fields. TSV and CSV output have one row per
+branch; CSV starts with a header row.`,
+	Flags: []cli.Flag{
+		{Name: "json", Usage: "shorthand for --format json"},
+		{Name: "format", Arg: "FORMAT", Usage: "output format: plain, json, tsv, or csv (default plain)"},
+		{Name: "columns", Arg: "LIST", Usage: "comma-separated tsv/csv columns from name, current, date, upstream, ahead, behind, merged, commits (default all)"},
+	},
+}
+
+// reposCommand documents the repos subcommand.
+var reposCommand = cli.Command{
+	Name:     "repos",
+	Synopsis: "[-n N]",
+	Summary:  "pick a recent repository, then a branch in it; prints the repository path",
+	Description: `Pick a recently used repository, then a branch in it to check out.
+The selector is drawn on stderr and the repository path is printed on stdout,
+so a shell function can run: cd "$(branch-navigator repos)"`,
+	Flags: []cli.Flag{
+		{Name: "n", Arg: "N", Usage: "maximum number of branches to list (default 10)"},
+	},
+}
+
+// sweepCommand documents the sweep subcommand.
+var sweepCommand = cli.Command{
+	Name:     "sweep",
+	Synopsis: "[--root DIR] [--depth N]",
+	Summary:  "find merged and gone branches in every repository under a directory and delete the marked ones",
+	Description: `Search DIR for git repositories and list, in one selector, the local branches
+of each that are merged into its base branch or whose upstream is gone. Mark
+rows with Space (a marks every row) and press Enter to delete the marked
+branches after a confirmation. Each branch is backed up first, as with -d;
+branches that are gone but not merged are force-deleted.`,
+	Flags: []cli.Flag{
+		{Name: "root", Arg: "DIR", Usage: "directory to search for repositories (default the current directory)"},
+		{Name: "depth", Arg: "N", Usage: "how many directory levels below DIR to search (default 4)"},
+	},
+}
+
+// docsCommand documents the docs subcommand.
+var docsCommand = cli.Command{
+	Name:     "docs",
+	Synopsis: "man|help",
+	Summary:  "print the branch-navigator(1) man page or the long-form help",
+	Description: `Render documentation from the command definitions. "man" prints a roff
+man page for section 1 (dated from SOURCE_DATE_EPOCH when set); "help" prints
+the usage of every command.`,
+}
+
+// historyCommand documents the history subcommand.
+var historyCommand = cli.Command{
+	Name:     "history",
+	Synopsis: "[-n N] [--all] [--replay N]",
+	Summary:  "list past checkouts made with branch-navigator, or jump back to an earlier branch",
+	Description: `List checkouts made through branch-navigator in the current repository, most
+recent first, with their time, previous and new branch, and repository.
+--replay N checks out the branch that was current before the Nth listed
+checkout, like git checkout @{-N}.`,
+	Flags: []cli.Flag{
+		{Name: "n", Arg: "N", Usage: "number of checkouts to list (default 20)"},
+		{Name: "all", Usage: "list checkouts from every repository"},
+		{Name: "replay", Arg: "N", Usage: "check out the branch you left in the Nth most recent checkout"},
+	},
+}
+
+// initCommand documents the init subcommand.
+var initCommand = cli.Command{
+	Name:     "init",
+	Synopsis: "zsh --widget | powershell [--widget]",
+	Summary:  "print shell integration code to load from your shell's startup file",
+	Description: `Print shell code that integrates branch-navigator with the line editor.
+"zsh --widget" registers a ZLE widget bound to Ctrl+G that runs the selector
+with --print and puts the resulting git switch command on the command line:
+it runs at once on an empty line and is inserted at the cursor otherwise.
+Add eval "$(branch-navigator init zsh --widget)" to ~/.zshrc.
+
+"powershell" registers an argument completer for the subcommands, flags, and
+branch names; with --widget it also binds Ctrl+G in PSReadLine to open the
+selector without losing the command line. Add
+branch-navigator init powershell --widget | Out-String | Invoke-Expression
+to your $PROFILE.`,
+	Flags: []cli.Flag{
+		{Name: "widget", Usage: "print the ZLE widget or PSReadLine key handler and its Ctrl+G key binding"},
+	},
+}
+
+// installAliasCommand documents the install-alias subcommand.
+var installAliasCommand = cli.Command{
+	Name:     "install-alias",
+	Synopsis: "[--name NAME] [--local] [--force] [-- OPTION...]",
+	Summary:  "make branch-navigator available as git nav",
+	Description: `Write alias.nav = !branch-navigator to the global git configuration and read
+it back to verify it, so the tool runs as git nav, git nav -m, and so on.
+Options after --, such as -- --theme nord, are stored in the alias and
+passed on every run. An existing alias with a different value is only
+replaced with --force.`,
+	Flags: []cli.Flag{
+		{Name: "name", Arg: "NAME", Usage: "alias name (default nav)"},
+		{Name: "local", Usage: "write the alias to the current repository instead of the global configuration"},
+		{Name: "force", Usage: "replace an existing alias of the same name"},
+	},
+}
+
+// themesCommand documents the themes subcommand.
+var themesCommand = cli.Command{
+	Name:     "themes",
+	Synopsis: "[--pick]",
+	Summary:  "preview every color theme, or pick one and save it to the config file",
+	Description: `Draw a sample selector frame in the dark and light variant of every theme so
+palettes can be compared. With --pick, move through the themes with j/k or the
+arrow keys to preview each one and press Enter to write theme = "NAME" to the
+configuration file; --theme and BRANCH_NAVIGATOR_THEME still take precedence.`,
+	Flags: []cli.Flag{
+		{Name: "pick", Usage: "choose a theme interactively and save it as the default"},
+	},
+}
+
+// configCommand documents the config subcommand.
+var configCommand = cli.Command{
+	Name:     "config",
+	Synopsis: "init [--force] | get|set|list [--repo] [KEY [VALUE]]",
+	Summary:  "write a commented config file, or read and change its settings or those of one repository",
+	Description: `"init" writes a configuration file listing every setting at its default,
+commented out with what it does, and refuses to replace an existing file
+unless --force is given. "get KEY" prints the value of a setting in effect, such as ui.border or
+pipelines.setup, and "list" prints every setting as key = value, defaults
+included. "set KEY VALUE" writes a setting to the configuration file, keeping
+the rest of it as it was; lists may be given as comma-separated items, such as
+"main, release/*". With --repo, set writes an override for the current
+repository only, kept in its git directory as branch-navigator.toml and read
+on top of the configuration file; get and list then show only those overrides.`,
+	Flags: []cli.Flag{
+		{Name: "repo", Usage: "read or write the overrides of the current repository instead of the config file"},
+		{Name: "force", Usage: "let init replace an existing config file"},
+	},
+}
+
+// statsCommand documents the stats subcommand.
+var statsCommand = cli.Command{
+	Name:     "stats",
+	Synopsis: "[--json] [-n N]",
+	Summary:  "show switch counts, most-used branches, and action frequency from local history",
+	Description: `Summarize the local history store: checkouts and total actions per repository,
+the most-used branches, and how often each action ran. Nothing leaves your machine.`,
+	Flags: []cli.Flag{
+		{Name: "json", Usage: "print the statistics as a JSON object"},
+		{Name: "n", Arg: "N", Usage: "number of most-used branches to show (default 10)"},
+	},
+}
+
+// reportCommand documents the report subcommand.
+var reportCommand = cli.Command{
+	Name:     "report",
+	Synopsis: "[--json]",
+	Summary:  "count the local branches that are merged, stale, unpushed, diverged, or whose upstream is gone",
+	Description: `Summarize the health of the local branches for cleanups and dashboards: how
+many are merged into the base branch, stale (see stale.after_days in the config
+file), tracking an upstream that is gone, ahead of their upstream (unpushed),
+or both ahead of and behind it (diverged), and which. A branch can be in
+several states. The metadata is shared with the selector's labels and cached
+the same way.`,
+	Flags: []cli.Flag{
+		{Name: "json", Usage: "print the report as a JSON object with the count and branches of each state"},
+	},
+}
+
+// doctorCommand documents the doctor subcommand.
+var doctorCommand = cli.Command{
+	Name:    "doctor",
+	Summary: "diagnose git, the repository, the terminal, the config file, and the history store",
+	Description: `Check git availability and version, the current repository, terminal and
+color support, the configuration file, and whether the history store is
+writable. Each finding is printed with a hint on how to fix it; the exit
+status is 1 when any check fails.`,
+}
+
+// rootCommand is the structured description of the command line, used for
+// -h output, the long-form help, and the man page.
+var rootCommand = cli.Command{
+	Name:     programName,
+	Synopsis: "[-c|-m|-d] [-n N] [-h]",
+	Summary:  "pick a recently used Git branch and act on it",
+	Description: `branch-navigator lists the current branch followed by the branches you used most
+recently and runs an action on the one you select. Move with j/k or the arrow
+keys, press Enter to run the action, / to filter, y to copy the branch name,
+Tab or o to expand the branch's details, m, s, or r to toggle merged, stale,
+or remote branches, Ctrl+R or F5 to refresh the list, Esc to step back out of
+the filter or the marks, and q to quit.`,
+	Flags: []cli.Flag{
+		{Name: "c", Usage: "checkout the selected branch (default)"},
+		{Name: "m", Usage: "merge the selected branch into the current branch"},
+		{Name: "merge-check", Usage: "predict whether the selected branch merges into the current branch without conflicts, without merging it (exit status 1 on conflicts)"},
+		{Name: "d", Usage: "delete the selected local branch and, with delete.remote in the config file, its upstream of the same name after confirming"},
+		{Name: "archive", Usage: "tag the selected branch as archive/<branch> and delete it"},
+		{Name: "unarchive", Usage: "restore a branch from its archive/<branch> tag"},
+		{Name: "rebase-i", Usage: "interactively rebase the current branch onto the selected branch"},
+		{Name: "push", Usage: "push the selected branch (sets the upstream when push.auto_setup_upstream is enabled)"},
+		{Name: "remote-admin", Usage: "list remotes and fetch, prune, or change the URL of the selected one"},
+		{Name: "then", Arg: "STEP", Usage: "after the action succeeds, run STEP in the repository root: pull, a shell command such as \"make setup\", or a pipeline named in the [pipelines] configuration table (repeatable)"},
+		{Name: "action", Arg: "NAME", Usage: "run the action NAME defined in an [actions.NAME] table of the configuration on the selected branch"},
+		{Name: "multi", Usage: "mark several branches with Space and apply --merge-check, --push, or --archive to each, then report the result per branch (exit status 4 when only some succeed)"},
+		{Name: "menu", Usage: "after picking a branch, choose the action to run on it from a second list instead of passing an action flag"},
+		{Name: "head-history", Usage: "list recent positions of HEAD from the reflog, detached checkouts and resets included, and check out the selected one"},
+		{Name: "log", Usage: "show the commits on the selected branch that the current branch lacks, through $GIT_PAGER or $PAGER"},
+		{Name: "browse", Usage: "open the selected branch on its code host (GitHub, GitLab, Bitbucket, or browse.url_template)"},
+		{Name: "create", Usage: "create a branch from the selected branch and switch to it, named by create.template when set"},
+		{Name: "copy", Usage: "create a branch at the tip of the selected branch without switching to it"},
+		{Name: "reset", Usage: "reset the current branch to the tip of the selected branch, choosing --soft, --mixed, or --hard"},
+		{Name: "diff", Usage: "show the changes of the selected branch since it forked from the current branch, through the pager"},
+		{Name: "n", Arg: "N", Usage: "maximum number of branches to list (default 10, 0 for no limit)"},
+		{Name: "limit", Arg: "N", Usage: "alias for -n"},
+		{Name: "all", Usage: "list every branch, loading rows as you scroll (same as -n 0)"},
+		{Name: "max-reflog", Arg: "N", Usage: "read at most N reflog entries when finding recent branches (default 300, 0 for the whole reflog)"},
+		{Name: "author", Arg: "PATTERN", Usage: "list only branches whose last commit author (\"Name <email>\") matches the regular expression PATTERN"},
+		{Name: "since", Arg: "DATE", Usage: "list only branches whose last commit is on or after DATE, an age such as 2.weeks or a date such as 2024-05-01"},
+		{Name: "before", Arg: "DATE", Usage: "list only branches whose last commit is before DATE, such as 3.months"},
+		{Name: "contains", Arg: "COMMIT", Usage: "list only branches that contain COMMIT, as git branch --contains"},
+		{Name: "no-merged", Usage: "leave out branches already merged into the base branch"},
+		{Name: "regex", Usage: "treat the filter query as a regular expression instead of a fuzzy pattern"},
+		{Name: "pick", Arg: "BRANCH", Usage: "act on BRANCH without showing the selector, for scripts and CI"},
+		{Name: "stdin", Usage: "read the name of the branch to act on from stdin instead of showing the selector"},
+		{Name: "plain", Usage: "print a numbered list and read the choice as a number (default when stdin or stdout is not a terminal or TERM=dumb)"},
+		{Name: "interactive", Usage: "always draw the interactive selector, even without a terminal"},
+		{Name: "record", Arg: "FILE", Usage: "save the keys read, the frames drawn, and the git commands run, with a hash of their output, to FILE for a bug report"},
+		{Name: "replay", Arg: "FILE", Usage: "replay a session saved with --record: draw it again from the recorded keys and report where the rendering differs, without running the action"},
+		{Name: "script", Arg: "KEYS", Usage: "drive the selector with synthetic keys such as \"j j enter\" instead of stdin, for end-to-end tests and demo recordings", Hidden: true},
+		{Name: "print", Usage: "draw the selector on stderr and print the git switch command for the selection instead of running it"},
+		{Name: "git-config", Arg: "KEY=VALUE", Usage: "pass -c KEY=VALUE to every git command of this run, such as merge.ff=false (repeatable)"},
+		{Name: "height", Arg: "N[%]", Usage: "draw the selector in N lines (or N% of the terminal) below the prompt instead of clearing the screen"},
+		{Name: "submodule", Usage: "choose one of the repository's submodules first, then pick a branch and run the action inside it"},
+		{Name: "S", Usage: "sign the merge commit of -m with GPG (see merge.gpg_sign in the config file)"},
+		{Name: "gpg-sign", Usage: "alias for -S; --gpg-sign=KEYID signs with the key KEYID and --gpg-sign=false turns merge.gpg_sign off"},
+		{Name: "signoff", Usage: "add a Signed-off-by trailer to the merge commit of -m (see merge.signoff in the config file)"},
+		{Name: "force-state", Usage: "check out, merge, or create a branch even while a merge, rebase, cherry-pick, revert, or bisect is in progress"},
+		{Name: "no-header", Usage: "hide the action header above the list (see ui.header in the config file)"},
+		{Name: "watch", Usage: "refresh the list while it is open whenever branches change in another terminal (see ui.auto_refresh in the config file)"},
+		{Name: "result-file", Arg: "FILE", Usage: "write the picked branch and the outcome of the action as a JSON object to FILE when the run ends, apart from the output meant for people"},
+		{Name: "result-fd", Arg: "N", Usage: "write the JSON object of --result-file to the open file descriptor N (3 or higher) instead"},
+		{Name: "serve", Usage: "answer JSON-RPC requests for the ranked branches and their actions on stdin instead of showing the selector, for editor plugins; only --git-config applies"},
+		{Name: "theme", Arg: "NAME", Usage: "color theme (catppuccin, nord, classic, solarized, gruvbox, one; add -dark or -light to pin a variant and -16 or 16 for basic ANSI colors; default catppuccin)"},
+		{Name: "h", Usage: "show this help message"},
+	},
+	Commands: []cli.Command{switchCommand, coCommand, listCommand, reportCommand, reposCommand, sweepCommand, historyCommand, statsCommand, themesCommand, configCommand, doctorCommand, initCommand, installAliasCommand, docsCommand},
+	Sections: []cli.Section{
+		{
+			Title: "Environment",
+			Body: `BRANCH_NAVIGATOR_CONFIG overrides the configuration file location.
+
+BRANCH_NAVIGATOR_STATE_DIR overrides the directory holding history and selector state.
+
+BRANCH_NAVIGATOR_THEME selects the color theme when --theme is not given, ahead of the theme setting.
+
+XDG_CONFIG_HOME and XDG_STATE_HOME are honoured as usual.`,
+		},
+		{
+			Title: "Files",
+			Body: `~/.config/branch-navigator/config.toml holds persistent settings.
+
+~/.local/state/branch-navigator/history.jsonl records completed actions.
+
+~/.local/state/branch-navigator/state.json remembers the last selection and filter per repository.`,
+		},
+	},
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+// loadConfig reads the configuration file at config.Path with the overrides
+// of the repository git runs in, if any, on top.
+func loadConfig(ctx context.Context, client *git.Client) (config.Config, error) {
+	path, err := config.Path()
+	if err != nil {
+		return config.Config{}, err
+	}
+	return config.LoadFiles(configPaths(ctx, client, path)...)
+}
+
+// configPaths returns userPath followed by the overrides file of the
+// repository git runs in, when it runs in one.
+func configPaths(ctx context.Context, client *git.Client, userPath string) []string {
+	paths := []string{userPath}
+	if repoPath, err := repoConfigPath(ctx, client); err == nil {
+		paths = append(paths, repoPath)
+	}
+	return paths
+}
+
+// repoConfigPath returns the location of the overrides of the repository
+// git runs in.
+func repoConfigPath(ctx context.Context, client *git.Client) (string, error) {
+	info, err := client.RepoInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return config.RepoPath(info.CommonDir()), nil
+}
+
+// runConfigCommand implements the config subcommand and returns the process
+// exit code. Settings are written to the configuration file at userPath,
+// or with --repo to the overrides of the repository client runs in; init
+// writes the commented template to userPath.
+func runConfigCommand(ctx context.Context, client *git.Client, userPath string, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator config", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, configCommand.Usage(programName))
+	}
+	repo := fs.Bool("repo", false, configCommand.FlagUsage("repo"))
+	force := fs.Bool("force", false, configCommand.FlagUsage("force"))
+	// Accept flags anywhere, as in "config set theme nord --repo".
+	var operands []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			return 2
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		operands = append(operands, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(operands) == 0 {
+		fs.Usage()
+		return 2
+	}
+	op, operands := operands[0], operands[1:]
+	want := map[string]int{"get": 1, "set": 2, "list": 0, "init": 0}
+	if n, ok := want[op]; !ok || len(operands) != n || (op == "init" && *repo) || (op != "init" && *force) {
+		fs.Usage()
+		return 2
+	}
+
+	path := userPath
+	if *repo {
+		repoPath, err := repoConfigPath(ctx, client)
+		if err != nil {
+			fmt.Fprintf(errOut, "--repo needs a git repository: %v\n", err)
+			return 1
+		}
+		path = repoPath
+	}
+
+	switch op {
+	case "init":
+		err := config.WriteTemplate(path, *force)
+		if errors.Is(err, os.ErrExist) {
+			fmt.Fprintf(errOut, "%s already exists; use --force to replace it\n", path)
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		fmt.Fprintf(out, "Wrote %s\n", path)
+		return 0
+	case "set":
+		key, value := operands[0], operands[1]
+		if err := config.Set(path, key, value); err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		settings, err := config.FileSettings(path)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		for _, setting := range settings {
+			if setting.Key == key {
+				fmt.Fprintf(out, "Saved %s = %s to %s\n", setting.Key, setting.Value, path)
+			}
+		}
+		return 0
+	case "get":
+		key := operands[0]
+		if *repo {
+			settings, err := config.FileSettings(path)
+			if err != nil {
+				fmt.Fprintln(errOut, err)
+				return 1
+			}
+			for _, setting := range settings {
+				if setting.Key == key {
+					fmt.Fprintln(out, plainValue(setting.Value))
+					return 0
+				}
+			}
+			fmt.Fprintf(errOut, "%s is not set in %s\n", key, path)
+			return 1
+		}
+		cfg, err := config.LoadFiles(configPaths(ctx, client, userPath)...)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		value, err := cfg.Get(key)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		fmt.Fprintln(out, plainValue(value))
+		return 0
+	default:
+		var settings []config.Setting
+		if *repo {
+			var err error
+			if settings, err = config.FileSettings(path); err != nil {
+				fmt.Fprintln(errOut, err)
+				return 1
+			}
+		} else {
+			cfg, err := config.LoadFiles(configPaths(ctx, client, userPath)...)
+			if err != nil {
+				fmt.Fprintln(errOut, err)
+				return 1
+			}
+			settings = cfg.Settings()
+		}
+		for _, setting := range settings {
+			fmt.Fprintf(out, "%s = %s\n", setting.Key, setting.Value)
+		}
+		return 0
+	}
+}
+
+// plainValue returns a value as written in the configuration file with the
+// quotes of a string removed, the way get prints it for scripts.
+func plainValue(value string) string {
+	if s, err := strconv.Unquote(value); err == nil {
+		return s
+	}
+	return value
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestRunConfigCommandSetsAndGets(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	userPath := filepath.Join(t.TempDir(), "config.toml")
+	ctx := context.Background()
+	run := func(args ...string) (string, string, int) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		errOut := &bytes.Buffer{}
+		code := runConfigCommand(ctx, client, userPath, args, out, errOut)
+		return out.String(), errOut.String(), code
+	}
+
+	if out, errOut, code := run("set", "theme", "nord"); code != 0 || out != "Saved theme = \"nord\" to "+userPath+"\n" {
+		t.Fatalf("set theme: exit code %d, stdout %q, stderr %q", code, out, errOut)
+	}
+	if _, errOut, code := run("set", "--repo", "theme", "gruvbox"); code != 0 {
+		t.Fatalf("set --repo theme: exit code %d, stderr %q", code, errOut)
+	}
+	if _, errOut, code := run("set", "confirm.protected", "trunk, stable/*", "--repo"); code != 0 {
+		t.Fatalf("set --repo confirm.protected: exit code %d, stderr %q", code, errOut)
+	}
+
+	if out, _, code := run("get", "theme"); code != 0 || out != "gruvbox\n" {
+		t.Fatalf("get theme = %q (exit code %d), want the repository's override", out, code)
+	}
+	if out, _, code := run("get", "stale.after_days"); code != 0 || out != "90\n" {
+		t.Fatalf("get stale.after_days = %q (exit code %d), want the default", out, code)
+	}
+	if out, _, code := run("get", "--repo", "confirm.protected"); code != 0 || out != "[\"trunk\", \"stable/*\"]\n" {
+		t.Fatalf("get --repo confirm.protected = %q (exit code %d)", out, code)
+	}
+	if _, errOut, code := run("get", "--repo", "ui.border"); code != 1 || !strings.Contains(errOut, "ui.border is not set") {
+		t.Fatalf("get --repo of an unset key: exit code %d, stderr %q", code, errOut)
+	}
+
+	out, _, code := run("list", "--repo")
+	if want := "confirm.protected = [\"trunk\", \"stable/*\"]\ntheme = \"gruvbox\"\n"; code != 0 || out != want {
+		t.Fatalf("list --repo = %q (exit code %d), want %q", out, code, want)
+	}
+	out, _, code = run("list")
+	if code != 0 || !strings.Contains(out, "theme = \"gruvbox\"\n") || !strings.Contains(out, "ui.border = false\n") {
+		t.Fatalf("list = %q (exit code %d), want every setting in effect", out, code)
+	}
+
+	// The user's file keeps its own theme; the override lives in the
+	// repository.
+	user, err := config.LoadFile(userPath)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if user.Theme != "nord" {
+		t.Fatalf("user theme = %q, want nord", user.Theme)
+	}
+	if _, err := os.Stat(filepath.Join(repo.Dir, ".git", "branch-navigator.toml")); err != nil {
+		t.Fatalf("expected the override in the git directory: %v", err)
+	}
+}
+
+func TestRunConfigCommandInit(t *testing.T) {
+	t.Parallel()
+
+	userPath := filepath.Join(t.TempDir(), "branch-navigator", "config.toml")
+	client := git.NewClient(&git.CLI{Dir: t.TempDir()})
+	run := func(args ...string) (string, string, int) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		errOut := &bytes.Buffer{}
+		code := runConfigCommand(context.Background(), client, userPath, args, out, errOut)
+		return out.String(), errOut.String(), code
+	}
+
+	if out, errOut, code := run("init"); code != 0 || out != "Wrote "+userPath+"\n" {
+		t.Fatalf("init: exit code %d, stdout %q, stderr %q", code, out, errOut)
+	}
+	data, err := os.ReadFile(userPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "#retention_days = 30\n") {
+		t.Fatalf("template lacks the commented defaults:\n%s", data)
+	}
+
+	if _, errOut, code := run("set", "theme", "nord"); code != 0 {
+		t.Fatalf("set theme: exit code %d, stderr %q", code, errOut)
+	}
+	if _, errOut, code := run("set", "stale.after_days", "14"); code != 0 {
+		t.Fatalf("set stale.after_days: exit code %d, stderr %q", code, errOut)
+	}
+	data, err = os.ReadFile(userPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "\ntheme = \"nord\"\n") || !strings.Contains(string(data), "[stale]\n# Days without commits after which a branch counts as stale (0 = never)\nafter_days = 14\n") {
+		t.Fatalf("set did not take over the commented defaults:\n%s", data)
+	}
+	if _, errOut, code := run("init"); code != 1 || !strings.Contains(errOut, "already exists; use --force") {
+		t.Fatalf("init over a file: exit code %d, stderr %q", code, errOut)
+	}
+	if out, _, _ := run("get", "theme"); out != "nord\n" {
+		t.Fatalf("refused init changed the file: theme = %q", out)
+	}
+	if _, errOut, code := run("init", "--force"); code != 0 {
+		t.Fatalf("init --force: exit code %d, stderr %q", code, errOut)
+	}
+	if out, _, _ := run("get", "theme"); out != "\n" {
+		t.Fatalf("init --force kept theme = %q", out)
+	}
+}
+
+func TestRunConfigCommandRejectsBadInput(t *testing.T) {
+	t.Parallel()
+
+	userPath := filepath.Join(t.TempDir(), "config.toml")
+	cases := map[string]struct {
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		"no operation":  {args: nil, wantCode: 2, wantErr: "Usage:"},
+		"unknown op":    {args: []string{"unset", "theme"}, wantCode: 2, wantErr: "Usage:"},
+		"missing value": {args: []string{"set", "theme"}, wantCode: 2, wantErr: "Usage:"},
+		"unknown key":   {args: []string{"set", "colour", "red"}, wantCode: 1, wantErr: `unknown key "colour"`},
+		"invalid value": {args: []string{"set", "ui.border", "maybe"}, wantCode: 1, wantErr: "ui.border"},
+		"get unknown":   {args: []string{"get", "colour"}, wantCode: 1, wantErr: `unknown key "colour"`},
+		"init repo":     {args: []string{"init", "--repo"}, wantCode: 2, wantErr: "Usage:"},
+		"force set":     {args: []string{"set", "--force", "theme", "nord"}, wantCode: 2, wantErr: "Usage:"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			errOut := &bytes.Buffer{}
+			client := git.NewClient(&git.CLI{Dir: t.TempDir()})
+			if code := runConfigCommand(context.Background(), client, userPath, tc.args, &bytes.Buffer{}, errOut); code != tc.wantCode {
+				t.Fatalf("exit code %d, want %d; stderr: %s", code, tc.wantCode, errOut.String())
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr %q, want it to mention %q", errOut.String(), tc.wantErr)
+			}
+		})
+	}
+	if _, err := os.Stat(userPath); !os.IsNotExist(err) {
+		t.Fatalf("rejected settings were written: %v", err)
+	}
+}
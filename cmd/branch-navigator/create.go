@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/branchname"
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// createTemplateKey is the git configuration key that sets the branch name
+// template for a single repository.
+const createTemplateKey = "branch-navigator.createTemplate"
+
+// branchNameField labels the prompt for a branch name without a template.
+const branchNameField = "Branch name"
+
+// createTemplate returns the branch name template of the current
+// repository: its createTemplateKey setting, or create.template otherwise.
+func createTemplate(ctx context.Context, client *git.Client, cfg config.Config) (string, error) {
+	value, ok, err := client.ConfigValue(ctx, git.ConfigLocal, createTemplateKey)
+	if err != nil {
+		return "", err
+	}
+	if ok && strings.TrimSpace(value) != "" {
+		return strings.TrimSpace(value), nil
+	}
+	return cfg.CreateTemplate, nil
+}
+
+// handleCreateAction asks for the name of a new branch and creates it at
+// start, switching to it for --create but not for --copy. With a template
+// the form asks for each placeholder and slugifies the answers; without one
+// it asks for the whole name. It returns the new branch, or "" when the form
+// was cancelled.
+func handleCreateAction(ctx context.Context, client *git.Client, act action, in io.Reader, out io.Writer, start, template string) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("git client is not configured")
+	}
+
+	title := fmt.Sprintf("New branch from '%s'", start)
+	if act == actionCopy {
+		title = fmt.Sprintf("Copy of '%s'", start)
+	}
+	form := ui.Form{Title: title, Fields: []string{branchNameField}}
+	var tmpl branchname.Template
+	if strings.TrimSpace(template) != "" {
+		var err error
+		tmpl, err = branchname.Parse(template)
+		if err != nil {
+			return "", err
+		}
+		form.Title += " as " + strings.TrimSpace(template)
+		form.Fields = tmpl.Placeholders()
+	}
+
+	values, ok, err := form.Fill(in, out)
+	if err != nil || !ok {
+		return "", err
+	}
+	name := values[branchNameField]
+	if strings.TrimSpace(template) != "" {
+		if name, err = tmpl.Expand(values); err != nil {
+			return "", err
+		}
+	}
+
+	create := client.CreateBranch
+	if act == actionCopy {
+		create = client.CreateBranchAt
+	}
+	message, err := create(ctx, name, start)
+	if err != nil {
+		return "", err
+	}
+	printIfNotEmpty(out, message)
+	fmt.Fprintf(out, "Created branch '%s' from '%s'\n", name, start)
+	return name, nil
+}
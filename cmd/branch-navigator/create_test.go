@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+func TestCreateTemplate(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.CreateTemplate = "feature/{slug}"
+
+	cases := map[string]struct {
+		outputs map[string]string
+		want    string
+	}{
+		"configuration file": {want: "feature/{slug}"},
+		"repository setting": {
+			outputs: map[string]string{"config --local --get branch-navigator.createTemplate": "{ticket}/{slug}\n"},
+			want:    "{ticket}/{slug}",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := createTemplate(context.Background(), git.NewClient(&recordingRunner{outputs: tc.outputs}), cfg)
+			if err != nil {
+				t.Fatalf("createTemplate returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("createTemplate() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleCreateAction(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		copy      bool
+		template  string
+		input     string
+		want      string
+		wantCalls [][]string
+		wantOut   string
+		wantErr   string
+	}{
+		"whole name": {
+			input:     "spike/cache\n",
+			want:      "spike/cache",
+			wantCalls: [][]string{{"checkout", "-b", "spike/cache", "main"}},
+			wantOut:   "New branch from 'main'\nBranch name: Created branch 'spike/cache' from 'main'\n",
+		},
+		"template": {
+			template:  "feature/{ticket}-{slug}",
+			input:     "ABC-42\nFix the login bug\n",
+			want:      "feature/abc-42-fix-the-login-bug",
+			wantCalls: [][]string{{"checkout", "-b", "feature/abc-42-fix-the-login-bug", "main"}},
+			wantOut:   "New branch from 'main' as feature/{ticket}-{slug}\nticket: slug: Created branch 'feature/abc-42-fix-the-login-bug' from 'main'\n",
+		},
+		"copy stays on the current branch": {
+			copy:      true,
+			input:     "experiment/cache\n",
+			want:      "experiment/cache",
+			wantCalls: [][]string{{"branch", "experiment/cache", "main"}},
+			wantOut:   "Copy of 'main'\nBranch name: Created branch 'experiment/cache' from 'main'\n",
+		},
+		"cancelled": {
+			template: "feature/{ticket}-{slug}",
+			input:    "ABC-42\n\n",
+			wantOut:  "New branch from 'main' as feature/{ticket}-{slug}\nticket: slug: ",
+		},
+		"nothing left after slugifying": {
+			template: "feature/{slug}",
+			input:    "???\n",
+			wantErr:  "{slug} needs a value with letters or digits",
+			wantOut:  "New branch from 'main' as feature/{slug}\nslug: ",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{}
+			out := &bytes.Buffer{}
+			act := actionCreate
+			if tc.copy {
+				act = actionCopy
+			}
+			got, err := handleCreateAction(context.Background(), git.NewClient(runner), act, strings.NewReader(tc.input), out, "main", tc.template)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("handleCreateAction returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("handleCreateAction() = %q, want %q", got, tc.want)
+			}
+			if !reflect.DeepEqual(runner.calls, tc.wantCalls) {
+				t.Fatalf("git calls = %q, want %q", runner.calls, tc.wantCalls)
+			}
+			if out.String() != tc.wantOut {
+				t.Fatalf("output = %q, want %q", out.String(), tc.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// checkCustomActions rejects user-defined actions named like a built-in
+// action, which --action and the menu could not tell apart.
+func checkCustomActions(actions map[string]config.CustomAction) error {
+	for _, spec := range app.Actions {
+		if _, ok := actions[string(spec.Action)]; ok {
+			return fmt.Errorf("actions.%s: the name is taken by a built-in action", spec.Action)
+		}
+	}
+	return nil
+}
+
+// customActionSpecs returns the user-defined actions as registry entries
+// for the menu, sorted by name.
+func customActionSpecs(actions map[string]config.CustomAction) []app.ActionSpec {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	specs := make([]app.ActionSpec, len(names))
+	for i, name := range names {
+		specs[i] = app.ActionSpec{Action: action(name), Details: customActionDetails(name, actions[name]), Menu: true}
+	}
+	return specs
+}
+
+// customActionDetails describes a user-defined action in the selector. The
+// command may as well act on the current branch, so it can be picked too.
+func customActionDetails(name string, custom config.CustomAction) ui.ActionDetails {
+	description := custom.Description
+	if description == "" {
+		description = "Run " + custom.Command + "."
+	}
+	return ui.ActionDetails{
+		Name:         name,
+		Description:  description,
+		EnterLabel:   "run " + name + " on the selected branch",
+		AllowCurrent: true,
+	}
+}
+
+// expandActionCommand replaces {branch} in command with the shell-quoted
+// branch.
+func expandActionCommand(command, branch string) string {
+	return strings.ReplaceAll(command, "{branch}", shellQuote(branch))
+}
+
+// runCustomAction runs a user-defined action on branch in the root of the
+// repository client works in, asking first when it is set to confirm. It
+// reports whether the command ran.
+func runCustomAction(ctx context.Context, client *git.Client, name string, custom config.CustomAction, branch string, in io.Reader, out, errOut io.Writer) (bool, error) {
+	command := expandActionCommand(custom.Command, branch)
+	if custom.Confirm {
+		confirmed, err := ui.Confirmation{
+			Summary: fmt.Sprintf("Run %s on '%s'?\n  %s", name, branch, command),
+		}.Confirm(in, out)
+		if err != nil || !confirmed {
+			return false, err
+		}
+	}
+	root, _ := client.RepoRoot(ctx)
+	if err := runShellStep(ctx, root, command, in, out, errOut); err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	return true, nil
+}
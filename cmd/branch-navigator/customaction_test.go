@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+func TestExpandActionCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		command string
+		branch  string
+		want    string
+	}{
+		"plain branch": {command: "git log {branch}", branch: "feature/x", want: "git log feature/x"},
+		"quoted":       {command: "echo {branch} {branch}", branch: "it's", want: `echo 'it'\''s' 'it'\''s'`},
+		"no branch":    {command: "make", branch: "main", want: "make"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := expandActionCommand(tc.command, tc.branch); got != tc.want {
+				t.Fatalf("expandActionCommand() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckCustomActions(t *testing.T) {
+	t.Parallel()
+
+	if err := checkCustomActions(map[string]config.CustomAction{"fixup": {Command: "x"}}); err != nil {
+		t.Fatalf("checkCustomActions returned error: %v", err)
+	}
+	err := checkCustomActions(map[string]config.CustomAction{"merge": {Command: "x"}})
+	if err == nil || err.Error() != "actions.merge: the name is taken by a built-in action" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestMenuActionsCustom(t *testing.T) {
+	t.Parallel()
+
+	custom := map[string]config.CustomAction{
+		"show":  {Command: "git show {branch}"},
+		"fixup": {Command: "git rebase -i --autosquash {branch}", Description: "Squash fixups"},
+	}
+	specs := app.MenuActions(ui.Branch{Name: "main", Current: true}, customActionSpecs(custom))
+	var names []string
+	for _, spec := range specs {
+		names = append(names, string(spec.Action))
+	}
+	if got := strings.Join(names, " "); got != "push browse create copy fixup show" {
+		t.Fatalf("MenuActions() = %s", got)
+	}
+	if got := specs[4].Details.Description; got != "Squash fixups" {
+		t.Fatalf("fixup description = %q", got)
+	}
+	if got := specs[5].Details.Description; got != "Run git show {branch}." {
+		t.Fatalf("show description = %q", got)
+	}
+}
+
+func TestRunCustomAction(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands use sh")
+	}
+
+	cases := map[string]struct {
+		custom  config.CustomAction
+		answer  string
+		wantRan bool
+		wantOut string
+	}{
+		"runs": {
+			custom:  config.CustomAction{Command: "echo on {branch}"},
+			wantRan: true,
+			wantOut: "on feature/x\n",
+		},
+		"confirmed": {
+			custom:  config.CustomAction{Command: "echo on {branch}", Confirm: true},
+			answer:  "y\n",
+			wantRan: true,
+			wantOut: "  echo on feature/x\nProceed? [y/N] on feature/x\n",
+		},
+		"declined": {
+			custom:  config.CustomAction{Command: "echo on {branch}", Confirm: true},
+			answer:  "n\n",
+			wantOut: "Run show on 'feature/x'?",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": t.TempDir()}}
+			out := &bytes.Buffer{}
+			ran, err := runCustomAction(context.Background(), git.NewClient(runner), "show", tc.custom, "feature/x", strings.NewReader(tc.answer), out, &bytes.Buffer{})
+			if err != nil {
+				t.Fatalf("runCustomAction returned error: %v", err)
+			}
+			if ran != tc.wantRan {
+				t.Fatalf("ran = %v, want %v", ran, tc.wantRan)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+			if !tc.wantRan && strings.Contains(out.String(), "] on feature/x") {
+				t.Fatalf("the declined command ran: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestRunCustomActionFails(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands use sh")
+	}
+	runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": t.TempDir()}}
+	_, err := runCustomAction(context.Background(), git.NewClient(runner), "deploy", config.CustomAction{Command: "exit 4"}, "main", strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || err.Error() != "deploy: exit status 4" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestParseArgsCustomAction(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--action", "fixup"}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if opts.customAction != "fixup" {
+		t.Fatalf("customAction = %q, want fixup", opts.customAction)
+	}
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"with a flag":  {args: []string{"--action", "fixup", "-m"}, wantErr: "--action cannot be combined with another action flag or --menu"},
+		"with a menu":  {args: []string{"--action", "fixup", "--menu"}, wantErr: "--action cannot be combined"},
+		"with --print": {args: []string{"--action", "fixup", "--print"}, wantErr: "--print can only be used with checkout"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			_, err := parseArgs(tc.args, usage, usage)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// deletePlan is what the delete action does to a branch. It is worked out
+// and confirmed as a whole before any step runs.
+type deletePlan struct {
+	branch string
+	// force deletes the local branch with git branch -D because it is not
+	// fully merged.
+	force bool
+	// remote is the upstream branch deleted along with it; the zero value
+	// leaves the remote alone.
+	remote git.Upstream
+	// kept explains why an upstream of the same name is left alone.
+	kept string
+	// unpushed warns that the branch has commits its upstream lacks; only
+	// a forced deletion has them, since git branch -d checks the upstream.
+	unpushed string
+}
+
+// planDelete works out the steps of deleting branch: the local branch,
+// forced when git branch -d would refuse, and, with delete.remote, its
+// upstream when that has the same name, still exists, and is not protected.
+// Only the configured upstream is ever deleted: a branch that tracks nothing
+// leaves every remote alone, since a branch of the same name there may be
+// someone else's.
+func planDelete(ctx context.Context, client *git.Client, cfg config.Config, branch string) (deletePlan, error) {
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return deletePlan{}, err
+	}
+	if branch == strings.TrimSpace(current) {
+		return deletePlan{}, fmt.Errorf("%w: '%s'", git.ErrDeleteCurrentBranch, branch)
+	}
+	merged, err := client.FullyMerged(ctx, branch)
+	if err != nil {
+		return deletePlan{}, err
+	}
+	plan := deletePlan{branch: branch, force: !merged}
+	statuses, err := client.BranchStatuses(ctx)
+	if err != nil {
+		return deletePlan{}, err
+	}
+	for _, status := range statuses {
+		if status.Name == branch && isUnpushed(status, merged) {
+			plan.unpushed = unpushedWarning(status)
+		}
+	}
+	if !cfg.DeleteRemote {
+		return plan, nil
+	}
+
+	upstream, err := client.BranchUpstream(ctx, branch)
+	if err != nil {
+		return deletePlan{}, err
+	}
+	if upstream.Remote == "" || upstream.Branch != branch {
+		return plan, nil
+	}
+	exists, err := client.RemoteBranchExists(ctx, upstream.Remote, upstream.Branch)
+	if err != nil || !exists {
+		return plan, err
+	}
+	patterns, err := protectedBranches(ctx, client, cfg)
+	if err != nil {
+		return deletePlan{}, err
+	}
+	if isProtected(upstream.Branch, patterns) {
+		plan.kept = fmt.Sprintf("%s is protected and is kept.", upstream)
+		return plan, nil
+	}
+	plan.remote = upstream
+	return plan, nil
+}
+
+// steps describes each step of the plan in the order they run.
+func (p deletePlan) steps() []string {
+	local := "delete the local branch " + p.branch
+	if p.force {
+		local = "force-delete the local branch " + p.branch + ", which is not fully merged"
+	}
+	steps := []string{local}
+	if p.remote.Remote != "" {
+		steps = append(steps, fmt.Sprintf("delete %s with git push %s --delete %s", p.remote, p.remote.Remote, p.remote.Branch))
+	}
+	return steps
+}
+
+// summary lists the steps of the plan for its confirmation.
+func (p deletePlan) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Deleting %s will:\n", p.branch)
+	for i, step := range p.steps() {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, step)
+	}
+	if p.kept != "" {
+		fmt.Fprintln(&b, p.kept)
+	}
+	if p.unpushed != "" {
+		fmt.Fprintln(&b, "Warning: "+p.unpushed)
+	}
+	return b.String()
+}
+
+// unpushedWarning explains which commits of an unpushed branch no remote has.
+func unpushedWarning(status git.BranchStatus) string {
+	switch {
+	case status.Ahead > 0:
+		return fmt.Sprintf("%s has %s that %s never pushed to %s.", status.Name, commitCountLabel(status.Ahead), plural(status.Ahead, "was", "were"), status.Upstream)
+	case status.Gone:
+		return fmt.Sprintf("the upstream of %s, %s, is gone, so no remote has its commits.", status.Name, status.Upstream)
+	default:
+		return fmt.Sprintf("%s was never pushed, so no remote has its commits.", status.Name)
+	}
+}
+
+// confirmDelete asks once for the whole plan. A forced deletion has to be
+// confirmed by typing the branch name, and one that reaches a remote with
+// y. A plain local deletion runs without asking, like git branch -d; the
+// backup covers it.
+func confirmDelete(plan deletePlan, in io.Reader, out io.Writer) (bool, error) {
+	switch {
+	case plan.force:
+		return ui.TypedConfirmation{
+			Warning: plan.summary() + fmt.Sprintf("Branch '%s' is not fully merged; force-deleting it removes commits that no other branch contains.", plan.branch),
+			Token:   plan.branch,
+		}.Confirm(in, out)
+	case plan.remote.Remote != "":
+		return ui.Confirmation{Summary: plan.summary()}.Confirm(in, out)
+	default:
+		return true, nil
+	}
+}
+
+// handleDeleteAction plans the deletion of branch, confirms it, and runs
+// its steps.
+func handleDeleteAction(ctx context.Context, client *git.Client, cfg config.Config, style selectorStyle, in io.Reader, out, errOut io.Writer, branch string) error {
+	if client == nil {
+		return fmt.Errorf("git client is not configured")
+	}
+	plan, err := planDelete(ctx, client, cfg, branch)
+	if err != nil {
+		return err
+	}
+	confirmed, err := confirmDelete(plan, in, out)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return fmt.Errorf("branch deletion aborted")
+	}
+	return executeDelete(ctx, client, style, plan, backupRetention(cfg), out, errOut)
+}
+
+// executeDelete backs up the branch and runs the steps of plan in order,
+// reporting each one as it finishes. It stops at the first step that fails.
+func executeDelete(ctx context.Context, client *git.Client, style selectorStyle, plan deletePlan, retention time.Duration, out, errOut io.Writer) error {
+	now := time.Now()
+	backup, err := client.BackupBranch(ctx, plan.branch, now)
+	if err != nil {
+		return fmt.Errorf("failed to back up branch '%s' before deletion: %w", plan.branch, err)
+	}
+	if retention > 0 {
+		if _, err := client.PruneBackups(ctx, now.Add(-retention)); err != nil {
+			fmt.Fprintf(errOut, "warning: failed to prune expired branch backups: %v\n", err)
+		}
+	}
+
+	steps := plan.steps()
+	// status prefixes the report of each step with its number when there
+	// is more than one.
+	status := func(step int, format string, args ...any) {
+		if len(steps) > 1 {
+			fmt.Fprintf(out, "[%d/%d] ", step, len(steps))
+		}
+		fmt.Fprintf(out, format+"\n", args...)
+	}
+
+	result, err := client.DeleteBranch(ctx, plan.branch, git.DeleteOptions{Force: plan.force})
+	if err != nil {
+		// The branch is still there, so the backup would only linger.
+		_ = client.DeleteBackup(ctx, backup)
+		printIfNotEmpty(errOut, result.Stderr)
+		if errors.Is(err, git.ErrBranchNotFullyMerged) {
+			return fmt.Errorf("%w; run the delete again to force it", err)
+		}
+		return err
+	}
+	deleted := strings.TrimSpace(result.Stdout)
+	if deleted == "" {
+		deleted = fmt.Sprintf("Deleted branch %s.", plan.branch)
+	}
+	status(1, "%s", deleted)
+	printIfNotEmpty(errOut, result.Stderr)
+	printBackupHint(out, backup)
+
+	if plan.remote.Remote == "" {
+		return nil
+	}
+	var pushed git.PushResult
+	err = networkProgress(ctx, client, style, errOut, "Deleting "+plan.remote.String(), func(ctx context.Context) error {
+		var err error
+		pushed, err = client.DeleteRemoteBranch(ctx, plan.remote.Remote, plan.remote.Branch)
+		return err
+	})
+	printIfNotEmpty(errOut, pushed.Stderr)
+	if err != nil {
+		status(2, "Failed to delete %s; the local branch is already deleted.", plan.remote)
+		return err
+	}
+	printIfNotEmpty(out, pushed.Stdout)
+	status(2, "Deleted %s.", plan.remote)
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/pkg/gittest"
+)
+
+// deleteRepo returns a repository with an origin remote and these branches:
+// merged, which has nothing of its own; pushed, which has a commit on
+// origin/pushed and tracks it; unmerged, which has a commit that only it
+// contains and tracks origin/unmerged; release/1, which is merged and
+// tracks origin/release/1; unlinked, which is merged and on origin but
+// tracks nothing; and ahead, which is merged but has a commit that
+// origin/ahead, which it tracks, lacks.
+func deleteRepo(t *testing.T) *gittest.Repo {
+	t.Helper()
+	repo := gittest.New(t)
+	repo.AddRemote("origin")
+	repo.Branch("merged", "")
+	repo.Branch("release/1", "")
+	repo.Branch("unlinked", "")
+	repo.Branch("ahead", "")
+	repo.CheckoutNew("pushed")
+	repo.Commit("pushed work")
+	repo.CheckoutNew("unmerged")
+	repo.Push("origin", "pushed", "unmerged", "release/1", "unlinked", "ahead")
+	repo.Commit("local work")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.Commit("main work")
+	repo.Git("branch", "--force", "ahead", gittest.DefaultBranch)
+	for _, branch := range []string{"pushed", "unmerged", "release/1", "ahead"} {
+		repo.Git("branch", "--set-upstream-to=origin/"+branch, branch)
+	}
+	return repo
+}
+
+func TestPlanDelete(t *testing.T) {
+	t.Parallel()
+
+	repo := deleteRepo(t)
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	withRemote := config.Default()
+	withRemote.DeleteRemote = true
+
+	cases := map[string]struct {
+		branch  string
+		cfg     config.Config
+		want    deletePlan
+		wantErr string
+	}{
+		"merged": {branch: "merged", cfg: withRemote, want: deletePlan{branch: "merged"}},
+		"pushed": {
+			branch: "pushed",
+			cfg:    withRemote,
+			want:   deletePlan{branch: "pushed", remote: git.Upstream{Remote: "origin", Branch: "pushed"}},
+		},
+		"unmerged": {
+			branch: "unmerged",
+			cfg:    withRemote,
+			want: deletePlan{
+				branch:   "unmerged",
+				force:    true,
+				remote:   git.Upstream{Remote: "origin", Branch: "unmerged"},
+				unpushed: "unmerged has 1 commit that was never pushed to origin/unmerged.",
+			},
+		},
+		"no upstream": {
+			branch: "unlinked",
+			cfg:    withRemote,
+			want:   deletePlan{branch: "unlinked"},
+		},
+		"delete.remote off by default": {branch: "pushed", cfg: config.Default(), want: deletePlan{branch: "pushed"}},
+		"unpushed but merged": {
+			branch: "ahead",
+			cfg:    config.Default(),
+			want:   deletePlan{branch: "ahead", force: true, unpushed: "ahead has 1 commit that was never pushed to origin/ahead."},
+		},
+		"protected upstream": {
+			branch: "release/1",
+			cfg:    withRemote,
+			want:   deletePlan{branch: "release/1", kept: "origin/release/1 is protected and is kept."},
+		},
+		"current branch": {branch: gittest.DefaultBranch, cfg: withRemote, wantErr: "cannot delete the current branch"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := planDelete(context.Background(), client, tc.cfg, tc.branch)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("planDelete error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planDelete returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("planDelete() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeletePlanSummary(t *testing.T) {
+	t.Parallel()
+
+	plan := deletePlan{branch: "feature/x", force: true, remote: git.Upstream{Remote: "origin", Branch: "feature/x"}}
+	want := "Deleting feature/x will:\n" +
+		"  1. force-delete the local branch feature/x, which is not fully merged\n" +
+		"  2. delete origin/feature/x with git push origin --delete feature/x\n"
+	if got := plan.summary(); got != want {
+		t.Fatalf("summary() = %q, want %q", got, want)
+	}
+}
+
+func TestHandleDeleteAction(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		branch string
+		answer string
+		// localOnly runs with the default config, which leaves remotes
+		// alone.
+		localOnly  bool
+		wantErr    string
+		wantGone   bool
+		wantRemote bool
+		wantOut    []string
+	}{
+		"remote kept by default": {
+			branch:     "pushed",
+			localOnly:  true,
+			wantGone:   true,
+			wantRemote: true,
+			wantOut:    []string{"Deleted branch pushed"},
+		},
+		"merged without asking": {
+			branch:   "merged",
+			wantGone: true,
+			wantOut:  []string{"Deleted branch merged", "Backup of merged saved as"},
+		},
+		"pushed": {
+			branch:   "pushed",
+			answer:   "y\n",
+			wantGone: true,
+			wantOut:  []string{"  2. delete origin/pushed with git push origin --delete pushed\n", "[1/2] Deleted branch pushed", "[2/2] Deleted origin/pushed.\n"},
+		},
+		"unmerged typed": {
+			branch:   "unmerged",
+			answer:   "unmerged\n",
+			wantGone: true,
+			wantOut:  []string{"  1. force-delete the local branch unmerged", "Warning: unmerged has 1 commit that was never pushed to origin/unmerged.\n", "Type unmerged to confirm", "[2/2] Deleted origin/unmerged.\n"},
+		},
+		"unmerged answered y": {
+			branch:     "unmerged",
+			answer:     "y\n",
+			wantErr:    "branch deletion aborted",
+			wantRemote: true,
+		},
+		"pushed declined": {
+			branch:     "pushed",
+			answer:     "\n",
+			wantErr:    "branch deletion aborted",
+			wantRemote: true,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := deleteRepo(t)
+			client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+			cfg := config.Default()
+			cfg.DeleteRemote = !tc.localOnly
+			out := &bytes.Buffer{}
+			err := handleDeleteAction(context.Background(), client, cfg, testStyle, strings.NewReader(tc.answer), out, &bytes.Buffer{}, tc.branch)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("handleDeleteAction error = %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("handleDeleteAction returned error: %v; output:\n%s", err, out)
+			}
+			if gone := repo.Git("branch", "--list", tc.branch) == ""; gone != tc.wantGone {
+				t.Fatalf("local branch deleted = %v, want %v", gone, tc.wantGone)
+			}
+			remote := repo.Git("branch", "--remotes", "--list", "origin/"+tc.branch) != ""
+			if remote != tc.wantRemote {
+				t.Fatalf("origin/%s exists = %v, want %v", tc.branch, remote, tc.wantRemote)
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteDeleteDropsBackupOnFailure(t *testing.T) {
+	t.Parallel()
+
+	// git refuses to delete the branch that is checked out.
+	repo := gittest.New(t)
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	plan := deletePlan{branch: gittest.DefaultBranch}
+
+	err := executeDelete(context.Background(), client, testStyle, plan, 0, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected deleting the current branch to fail")
+	}
+	if backups := repo.Git("for-each-ref", "refs/branch-navigator/backup/"); backups != "" {
+		t.Fatalf("backups left after the failed delete: %q", backups)
+	}
+	if repo.Git("branch", "--list", gittest.DefaultBranch) == "" {
+		t.Fatal("expected the current branch to be kept")
+	}
+}
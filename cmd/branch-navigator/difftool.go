@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/pager"
+
+	"golang.org/x/term"
+)
+
+// diffTool is a formatter configured with diff.tool.
+type diffTool struct {
+	// command is the shell command line that runs the tool.
+	command string
+	// external tools compare two files themselves, so git runs them as
+	// diff.external instead of piping its diff into them.
+	external bool
+}
+
+// externalDiffTools names the tools that cannot read a diff on stdin.
+var externalDiffTools = map[string]bool{"difft": true, "difftastic": true}
+
+// resolveDiffTool turns the diff.tool setting into a diffTool. It fails
+// when the program is not on PATH.
+func resolveDiffTool(setting string, lookPath func(string) (string, error)) (diffTool, error) {
+	fields := strings.Fields(setting)
+	if len(fields) == 0 {
+		return diffTool{}, nil
+	}
+	name := filepath.Base(fields[0])
+	if name == "difftastic" {
+		// The difftastic package installs the binary as difft.
+		fields[0] = "difft"
+	}
+	if _, err := lookPath(fields[0]); err != nil {
+		return diffTool{}, fmt.Errorf("diff.tool %q is not on PATH", fields[0])
+	}
+	return diffTool{command: strings.Join(fields, " "), external: externalDiffTools[name]}, nil
+}
+
+// outputEnv gathers what the log and diff actions depend on so that tests
+// can substitute it.
+type outputEnv struct {
+	out       io.Writer
+	errOut    io.Writer
+	lookupEnv func(string) (string, bool)
+	lookPath  func(string) (string, error)
+	// diffTool is the diff.tool setting; empty shows git's own diff.
+	diffTool string
+}
+
+// defaultOutputEnv writes to the process's stdout and stderr.
+func defaultOutputEnv(diffTool string) outputEnv {
+	return outputEnv{out: os.Stdout, errOut: os.Stderr, lookupEnv: os.LookupEnv, lookPath: exec.LookPath, diffTool: diffTool}
+}
+
+// handleOutputAction shows the log or diff of branch. On a terminal the
+// output keeps its colors and goes through the pager; otherwise it is
+// written to out without colors. A diff goes through diff.tool first when
+// the tool is installed.
+func handleOutputAction(ctx context.Context, client *git.Client, act action, env outputEnv, branch string) error {
+	p, err := pager.Open(env.out, env.lookupEnv)
+	if err != nil {
+		return err
+	}
+	file, ok := env.out.(*os.File)
+	color := p.Paging() || ok && term.IsTerminal(int(file.Fd()))
+
+	err = writeOutput(ctx, client, act, env, branch, color, p)
+	if closeErr := p.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeOutput(ctx context.Context, client *git.Client, act action, env outputEnv, branch string, color bool, out io.Writer) error {
+	if act == actionLog {
+		text, err := client.BranchLog(ctx, branch, color)
+		printIfNotEmpty(out, text)
+		return err
+	}
+
+	tool, err := resolveDiffTool(env.diffTool, env.lookPath)
+	if err != nil {
+		fmt.Fprintf(env.errOut, "warning: %v; showing git's diff\n", err)
+	}
+	opts := git.DiffOptions{Color: color}
+	if tool.external {
+		opts.External = tool.command
+		if color {
+			opts.External += " --color=always"
+		}
+	}
+	text, err := client.BranchDiff(ctx, branch, opts)
+	if err != nil {
+		return err
+	}
+	if tool.command == "" || tool.external {
+		printIfNotEmpty(out, text)
+		return nil
+	}
+	return runDiffFilter(ctx, tool.command, text+"\n", out, env.errOut)
+}
+
+// runDiffFilter pipes diff through command, a formatter such as delta that
+// reads a diff on stdin.
+func runDiffFilter(ctx context.Context, command, diff string, out, errOut io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = strings.NewReader(diff)
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("diff.tool %q: %w", command, err)
+	}
+	return nil
+}
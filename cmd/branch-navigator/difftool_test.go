@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func testOutputEnv(out, errOut *bytes.Buffer, diffTool string, installed ...string) outputEnv {
+	return outputEnv{
+		out:       out,
+		errOut:    errOut,
+		lookupEnv: func(string) (string, bool) { return "", false },
+		lookPath: func(name string) (string, error) {
+			for _, tool := range installed {
+				if tool == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+		diffTool: diffTool,
+	}
+}
+
+func TestResolveDiffTool(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		setting string
+		want    diffTool
+		wantErr string
+	}{
+		"unset":      {},
+		"delta":      {setting: "delta --side-by-side", want: diffTool{command: "delta --side-by-side"}},
+		"difft":      {setting: "difft", want: diffTool{command: "difft", external: true}},
+		"difftastic": {setting: "difftastic --display inline", want: diffTool{command: "difft --display inline", external: true}},
+		"missing":    {setting: "diff-so-fancy", wantErr: `diff.tool "diff-so-fancy" is not on PATH`},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			env := testOutputEnv(nil, nil, "", "delta", "difft")
+			got, err := resolveDiffTool(tc.setting, env.lookPath)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveDiffTool returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("resolveDiffTool() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleOutputAction(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		act        action
+		diffTool   string
+		installed  []string
+		gitArgs    string
+		want       string
+		wantStderr string
+	}{
+		"log": {
+			act:     actionLog,
+			gitArgs: "log --no-color --decorate HEAD..feature/x",
+			want:    "commit abc\n",
+		},
+		"diff": {
+			act:     actionDiff,
+			gitArgs: "diff --no-color HEAD...feature/x",
+			want:    "+added\n",
+		},
+		"external tool": {
+			act:       actionDiff,
+			diffTool:  "difftastic",
+			installed: []string{"difft"},
+			gitArgs:   "-c diff.external=difft diff --no-color --ext-diff HEAD...feature/x",
+			want:      "+added\n",
+		},
+		"missing tool": {
+			act:        actionDiff,
+			diffTool:   "delta",
+			gitArgs:    "diff --no-color HEAD...feature/x",
+			want:       "+added\n",
+			wantStderr: `warning: diff.tool "delta" is not on PATH; showing git's diff`,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{tc.gitArgs: strings.TrimSpace(tc.want)}}
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			env := testOutputEnv(out, errOut, tc.diffTool, tc.installed...)
+			if err := handleOutputAction(context.Background(), git.NewClient(runner), tc.act, env, "feature/x"); err != nil {
+				t.Fatalf("handleOutputAction returned error: %v", err)
+			}
+			if out.String() != tc.want {
+				t.Fatalf("output = %q, want %q", out.String(), tc.want)
+			}
+			if !strings.Contains(errOut.String(), tc.wantStderr) {
+				t.Fatalf("stderr = %q, want it to contain %q", errOut.String(), tc.wantStderr)
+			}
+		})
+	}
+}
+
+func TestHandleOutputActionPipesThroughTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell pipeline test is not supported on Windows")
+	}
+	t.Parallel()
+
+	runner := &recordingRunner{outputs: map[string]string{"diff --no-color HEAD...feature/x": "+added"}}
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	env := testOutputEnv(out, errOut, "tr a-z A-Z", "tr")
+	if err := handleOutputAction(context.Background(), git.NewClient(runner), actionDiff, env, "feature/x"); err != nil {
+		t.Fatalf("handleOutputAction returned error: %v", err)
+	}
+	if out.String() != "+ADDED\n" {
+		t.Fatalf("output = %q, want the diff formatted by the tool", out.String())
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// statusSummary describes uncommitted changes compactly, such as
+// "3 modified, 1 untracked".
+func statusSummary(status git.WorkTreeStatus) string {
+	parts := []string{}
+	if status.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", status.Modified))
+	}
+	if status.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("%d untracked", status.Untracked))
+	}
+	if status.Conflicted > 0 {
+		parts = append(parts, fmt.Sprintf("%d conflicted", status.Conflicted))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// prepareCheckout looks at the working tree before switching to branch.
+// When it has uncommitted changes, it says how many and asks whether to
+// carry them over, stash them first, or cancel. It reports whether the
+// checkout should go ahead.
+func prepareCheckout(ctx context.Context, client *git.Client, in io.Reader, out io.Writer, branch string) (bool, error) {
+	if client == nil {
+		return false, fmt.Errorf("git client is not configured")
+	}
+	status, err := client.WorkTreeStatus(ctx)
+	if err != nil {
+		return false, err
+	}
+	if status.Clean() {
+		return true, nil
+	}
+
+	answer, err := ui.Choice{
+		Summary: fmt.Sprintf("The working tree has uncommitted changes: %s.", statusSummary(status)),
+		Options: []ui.ChoiceOption{
+			{Key: "p", Label: fmt.Sprintf("proceed; git carries the changes over to '%s'", branch)},
+			{Key: "s", Label: "stash them first (git stash pop brings them back)"},
+			{Key: "c", Label: "cancel"},
+		},
+		Default: "p",
+	}.Ask(in, out)
+	if err != nil {
+		return false, err
+	}
+	switch answer {
+	case "s":
+		message, err := client.Stash(ctx, "branch-navigator: before switching to "+branch)
+		if err != nil {
+			return false, err
+		}
+		printIfNotEmpty(out, message)
+		return true, nil
+	case "c":
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// checkoutBranch checks out branch, or tracks it when remote is set. When
+// git refuses because uncommitted changes conflict with the target, it asks
+// whether to merge the changes into the target with git checkout -m, stash
+// them and check out again, or cancel. ok is false when the user cancels.
+func checkoutBranch(ctx context.Context, client *git.Client, in io.Reader, out io.Writer, branch string, remote bool) (message string, ok bool, err error) {
+	if client == nil {
+		return "", false, fmt.Errorf("git client is not configured")
+	}
+	checkout := client.CheckoutBranch
+	if remote {
+		checkout = client.CheckoutRemoteBranch
+	}
+	merge := func(ctx context.Context, ref string) (string, error) {
+		return client.CheckoutMerge(ctx, ref, remote)
+	}
+	return checkoutWithChanges(ctx, client, in, out, branch, checkout, merge)
+}
+
+// checkoutCommit detaches HEAD at commit, handling conflicting uncommitted
+// changes like checkoutBranch.
+func checkoutCommit(ctx context.Context, client *git.Client, in io.Reader, out io.Writer, commit string) (message string, ok bool, err error) {
+	if client == nil {
+		return "", false, fmt.Errorf("git client is not configured")
+	}
+	checkout := func(ctx context.Context, ref string) (string, error) {
+		return client.CheckoutDetached(ctx, ref, false)
+	}
+	merge := func(ctx context.Context, ref string) (string, error) {
+		return client.CheckoutDetached(ctx, ref, true)
+	}
+	return checkoutWithChanges(ctx, client, in, out, commit, checkout, merge)
+}
+
+// checkoutWithChanges runs checkout on branch and, when git refuses over
+// conflicting uncommitted changes, offers to run merge instead, to stash
+// the changes and run checkout again, or to cancel.
+func checkoutWithChanges(ctx context.Context, client *git.Client, in io.Reader, out io.Writer, branch string, checkout, merge func(context.Context, string) (string, error)) (message string, ok bool, err error) {
+	message, err = checkout(ctx, branch)
+	if !errors.Is(err, git.ErrLocalChangesOverwritten) {
+		return message, err == nil, err
+	}
+
+	answer, askErr := ui.Choice{
+		Summary: fmt.Sprintf("Your uncommitted changes conflict with '%s', so git refused the checkout.", branch),
+		Options: []ui.ChoiceOption{
+			{Key: "m", Label: "attempt to merge your changes into the target branch (git checkout -m)"},
+			{Key: "s", Label: "stash them first (git stash pop brings them back)"},
+			{Key: "c", Label: "cancel"},
+		},
+		Default: "c",
+	}.Ask(in, out)
+	if askErr != nil {
+		return "", false, askErr
+	}
+	switch answer {
+	case "m":
+		message, err = merge(ctx, branch)
+		if err != nil {
+			return "", false, err
+		}
+		if status, statusErr := client.WorkTreeStatus(ctx); statusErr == nil && status.Conflicted > 0 {
+			message = strings.TrimSpace(message + "\n" + fmt.Sprintf("%d %s did not merge cleanly; resolve the conflicts and git add them.", status.Conflicted, plural(status.Conflicted, "file", "files")))
+		}
+		return message, true, nil
+	case "s":
+		stashed, err := client.Stash(ctx, "branch-navigator: before switching to "+branch)
+		if err != nil {
+			return "", false, err
+		}
+		printIfNotEmpty(out, stashed)
+		message, err = checkout(ctx, branch)
+		return message, err == nil, err
+	default:
+		return "", false, nil
+	}
+}
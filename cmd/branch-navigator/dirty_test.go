@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func TestStatusSummary(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		status git.WorkTreeStatus
+		want   string
+	}{
+		"modified and untracked": {status: git.WorkTreeStatus{Modified: 3, Untracked: 1}, want: "3 modified, 1 untracked"},
+		"untracked only":         {status: git.WorkTreeStatus{Untracked: 2}, want: "2 untracked"},
+		"conflicts":              {status: git.WorkTreeStatus{Modified: 1, Conflicted: 2}, want: "1 modified, 2 conflicted"},
+		"clean":                  {want: ""},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := statusSummary(tc.status); got != tc.want {
+				t.Fatalf("statusSummary() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrepareCheckout(t *testing.T) {
+	t.Parallel()
+
+	stash := []string{"stash", "push", "--include-untracked", "-m", "branch-navigator: before switching to feature/x"}
+
+	cases := map[string]struct {
+		status      string
+		answer      string
+		wantProceed bool
+		wantStash   bool
+		wantOut     string
+	}{
+		"clean tree is not asked": {
+			wantProceed: true,
+		},
+		"proceed": {
+			status:      "M main.go\n?? notes.txt",
+			answer:      "p\n",
+			wantProceed: true,
+			wantOut:     "The working tree has uncommitted changes: 1 modified, 1 untracked.\n",
+		},
+		"enter proceeds": {
+			status:      "M main.go",
+			answer:      "\n",
+			wantProceed: true,
+			wantOut:     "Choose [P/s/c] ",
+		},
+		"stash first": {
+			status:      "M main.go",
+			answer:      "s\n",
+			wantProceed: true,
+			wantStash:   true,
+		},
+		"cancel": {
+			status: "?? notes.txt",
+			answer: "c\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"status --porcelain": tc.status}}
+			out := &bytes.Buffer{}
+			proceed, err := prepareCheckout(context.Background(), git.NewClient(runner), strings.NewReader(tc.answer), out, "feature/x")
+			if err != nil {
+				t.Fatalf("prepareCheckout returned error: %v", err)
+			}
+			if proceed != tc.wantProceed {
+				t.Fatalf("proceed = %v, want %v", proceed, tc.wantProceed)
+			}
+			stashed := false
+			for _, call := range runner.calls {
+				stashed = stashed || reflect.DeepEqual(call, stash)
+			}
+			if stashed != tc.wantStash {
+				t.Fatalf("stashed = %v, want %v (calls %v)", stashed, tc.wantStash, runner.calls)
+			}
+			if tc.status == "" && out.Len() > 0 {
+				t.Fatalf("clean tree printed %q", out.String())
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+		})
+	}
+}
+
+// refusingRunner fails the first checkout the way git does when it would
+// overwrite uncommitted changes.
+type refusingRunner struct {
+	recordingRunner
+	refused bool
+}
+
+func (r *refusingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	out, _ := r.recordingRunner.Run(ctx, args...)
+	if args[0] == "checkout" && !r.refused {
+		r.refused = true
+		return "", errors.New("git checkout: exit status 1: error: Your local changes to the following files would be overwritten by checkout:\n\tmain.go")
+	}
+	return out, nil
+}
+
+func TestCheckoutBranch(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		remote    bool
+		answer    string
+		status    string
+		wantOK    bool
+		wantCalls [][]string
+		wantOut   string
+	}{
+		"merge": {
+			answer: "m\n",
+			wantOK: true,
+			wantCalls: [][]string{
+				{"rev-parse", "--abbrev-ref", "HEAD"},
+				{"checkout", "feature/x"},
+				{"checkout", "-m", "feature/x"},
+				{"status", "--porcelain"},
+			},
+			wantOut: "attempt to merge your changes into the target branch",
+		},
+		"merge with conflicts": {
+			answer: "m\n",
+			status: "UU main.go",
+			wantOK: true,
+			wantCalls: [][]string{
+				{"rev-parse", "--abbrev-ref", "HEAD"},
+				{"checkout", "feature/x"},
+				{"checkout", "-m", "feature/x"},
+				{"status", "--porcelain"},
+			},
+		},
+		"merge remote": {
+			remote: true,
+			answer: "m\n",
+			wantOK: true,
+			wantCalls: [][]string{
+				{"checkout", "--track", "origin/feature/x"},
+				{"checkout", "-m", "--track", "origin/feature/x"},
+				{"status", "--porcelain"},
+			},
+		},
+		"stash and retry": {
+			answer: "s\n",
+			wantOK: true,
+			wantCalls: [][]string{
+				{"rev-parse", "--abbrev-ref", "HEAD"},
+				{"checkout", "feature/x"},
+				{"stash", "push", "--include-untracked", "-m", "branch-navigator: before switching to feature/x"},
+				{"rev-parse", "--abbrev-ref", "HEAD"},
+				{"checkout", "feature/x"},
+			},
+		},
+		"enter cancels": {
+			answer: "\n",
+			wantCalls: [][]string{
+				{"rev-parse", "--abbrev-ref", "HEAD"},
+				{"checkout", "feature/x"},
+			},
+			wantOut: "Choose [m/s/C] ",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &refusingRunner{recordingRunner: recordingRunner{outputs: map[string]string{
+				"rev-parse --abbrev-ref HEAD": "main",
+				"status --porcelain":          tc.status,
+			}}}
+			branch := "feature/x"
+			if tc.remote {
+				branch = "origin/feature/x"
+			}
+			out := &bytes.Buffer{}
+			message, ok, err := checkoutBranch(context.Background(), git.NewClient(runner), strings.NewReader(tc.answer), out, branch, tc.remote)
+			if err != nil {
+				t.Fatalf("checkoutBranch returned error: %v", err)
+			}
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !reflect.DeepEqual(runner.calls, tc.wantCalls) {
+				t.Fatalf("calls = %v, want %v", runner.calls, tc.wantCalls)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+			if tc.status != "" && !strings.Contains(message, "1 file did not merge cleanly") {
+				t.Fatalf("expected a conflict note, got %q", message)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/cli"
+)
+
+// runDocsCommand implements the docs subcommand and returns the process exit code.
+func runDocsCommand(args []string, now time.Time, out, errOut io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprint(errOut, docsCommand.Usage(programName))
+		return 2
+	}
+
+	switch args[0] {
+	case "man":
+		fmt.Fprint(out, cli.Man(rootCommand, manDate(os.Getenv("SOURCE_DATE_EPOCH"), now)))
+	case "help":
+		fmt.Fprint(out, cli.Help(rootCommand))
+	case "-h", "-help", "--help":
+		fmt.Fprint(out, docsCommand.Usage(programName))
+	default:
+		fmt.Fprint(errOut, docsCommand.Usage(programName))
+		return 2
+	}
+	return 0
+}
+
+// manDate returns the man page date, honouring SOURCE_DATE_EPOCH so that
+// packaged pages are reproducible.
+func manDate(sourceDateEpoch string, now time.Time) string {
+	if seconds, err := strconv.ParseInt(strings.TrimSpace(sourceDateEpoch), 10, 64); err == nil {
+		now = time.Unix(seconds, 0)
+	}
+	return now.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRootFlagsMatchCommandModel(t *testing.T) {
+	t.Parallel()
+
+	opts := cliOptions{limit: 10}
+	fs := newRootFlagSet(&opts, &actionFlags{})
+
+	defined := map[string]bool{"h": true}
+	fs.VisitAll(func(f *flag.Flag) {
+		defined[f.Name] = true
+		if f.Usage == "" {
+			t.Errorf("flag -%s is missing from rootCommand", f.Name)
+		}
+	})
+	for _, f := range rootCommand.Flags {
+		if !defined[f.Name] {
+			t.Errorf("rootCommand documents -%s, which is not defined", f.Name)
+		}
+	}
+}
+
+func TestRunDocsCommand(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		args     []string
+		wantCode int
+		wantOut  string
+		wantErr  string
+	}{
+		"man":     {args: []string{"man"}, wantOut: ".SH NAME\nbranch\\-navigator \\- "},
+		"help":    {args: []string{"help"}, wantOut: "Usage: branch-navigator list "},
+		"missing": {wantCode: 2, wantErr: "Usage: branch-navigator docs man|help"},
+		"unknown": {args: []string{"html"}, wantCode: 2, wantErr: "Usage: branch-navigator docs"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			if code := runDocsCommand(tc.args, now, out, errOut); code != tc.wantCode {
+				t.Fatalf("exit code %d, want %d", code, tc.wantCode)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("stdout %q does not contain %q", out.String(), tc.wantOut)
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr %q does not contain %q", errOut.String(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestManDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	if got := manDate("", now); got != "2024-05-01" {
+		t.Fatalf("manDate without SOURCE_DATE_EPOCH = %q", got)
+	}
+	if got := manDate("0", now); got != "1970-01-01" {
+		t.Fatalf("manDate with SOURCE_DATE_EPOCH = %q", got)
+	}
+}
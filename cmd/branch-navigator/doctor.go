@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/xdg"
+	"branch-navigator/internal/ui"
+
+	"golang.org/x/term"
+)
+
+// minGitVersion is the oldest git release providing every ref format the tool uses.
+const minGitVersion = "2.16"
+
+// doctorStatus grades a diagnostic finding.
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorWarn:
+		return "warn"
+	case doctorFail:
+		return "FAIL"
+	default:
+		return "ok"
+	}
+}
+
+// doctorFinding is the outcome of one diagnostic check.
+type doctorFinding struct {
+	status  doctorStatus
+	check   string
+	summary string
+	// hint suggests how to resolve a warning or failure.
+	hint string
+}
+
+// doctorEnv gathers what doctor inspects so that tests can substitute it.
+type doctorEnv struct {
+	client     *git.Client
+	lookPath   func(string) (string, error)
+	getenv     func(string) string
+	stdinTTY   bool
+	stdoutTTY  bool
+	configPath func() (string, error)
+	stateDir   func() (string, error)
+}
+
+// defaultDoctorEnv inspects the running process.
+func defaultDoctorEnv() doctorEnv {
+	return doctorEnv{
+		client:     git.NewDefaultClient(),
+		lookPath:   exec.LookPath,
+		getenv:     os.Getenv,
+		stdinTTY:   term.IsTerminal(int(os.Stdin.Fd())),
+		stdoutTTY:  term.IsTerminal(int(os.Stdout.Fd())),
+		configPath: config.Path,
+		stateDir:   xdg.StateDir,
+	}
+}
+
+// runDoctorCommand implements the doctor subcommand and returns the process
+// exit code, which is 1 when any check fails.
+func runDoctorCommand(ctx context.Context, env doctorEnv, args []string, out, errOut io.Writer) int {
+	if len(args) > 0 {
+		if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+			fmt.Fprint(out, doctorCommand.Usage(programName))
+			return 0
+		}
+		fmt.Fprint(errOut, doctorCommand.Usage(programName))
+		return 2
+	}
+
+	failed := false
+	for _, finding := range doctorFindings(ctx, env) {
+		fmt.Fprintf(out, "%-6s %s: %s\n", "["+finding.status.String()+"]", finding.check, finding.summary)
+		if finding.hint != "" {
+			fmt.Fprintf(out, "       hint: %s\n", finding.hint)
+		}
+		if finding.status == doctorFail {
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func doctorFindings(ctx context.Context, env doctorEnv) []doctorFinding {
+	findings := []doctorFinding{checkGit(ctx, env)}
+	if findings[0].status != doctorFail {
+		findings = append(findings, checkRepository(ctx, env))
+	}
+	return append(findings,
+		checkTerminal(env),
+		checkColor(env),
+		checkConfig(env),
+		checkStateDir(env),
+	)
+}
+
+func checkGit(ctx context.Context, env doctorEnv) doctorFinding {
+	finding := doctorFinding{check: "git"}
+	if _, err := env.lookPath("git"); err != nil {
+		finding.status = doctorFail
+		finding.summary = "git executable not found in PATH"
+		finding.hint = "install git " + minGitVersion + " or newer and make sure it is on PATH"
+		return finding
+	}
+	version, err := env.client.Version(ctx)
+	if err != nil {
+		finding.status = doctorFail
+		finding.summary = fmt.Sprintf("cannot run git: %v", err)
+		finding.hint = "check that `git --version` works in this shell"
+		return finding
+	}
+	finding.summary = "version " + version
+	if git.CompareVersions(version, minGitVersion) < 0 {
+		finding.status = doctorWarn
+		finding.hint = "upgrade to git " + minGitVersion + " or newer; older releases lack ref formats used for upstream and merge status"
+	}
+	return finding
+}
+
+func checkRepository(ctx context.Context, env doctorEnv) doctorFinding {
+	finding := doctorFinding{check: "repository"}
+	root, err := env.client.RepoRoot(ctx)
+	if err != nil {
+		finding.status = doctorFail
+		finding.summary = "not inside a git repository"
+		finding.hint = "run branch-navigator from a repository's working tree, or use `branch-navigator repos`"
+		return finding
+	}
+	finding.summary = root
+	current, err := env.client.CurrentBranch(ctx)
+	if err == nil && strings.TrimSpace(current) == "HEAD" {
+		finding.status = doctorWarn
+		finding.summary += " (detached HEAD)"
+		finding.hint = "check out a branch so that recent branches and actions work as expected"
+	}
+	return finding
+}
+
+func checkTerminal(env doctorEnv) doctorFinding {
+	finding := doctorFinding{check: "terminal", summary: "stdin and stdout are terminals"}
+	switch {
+	case !env.stdinTTY && !env.stdoutTTY:
+		finding.summary = "stdin and stdout are not terminals"
+	case !env.stdinTTY:
+		finding.summary = "stdin is not a terminal"
+	case !env.stdoutTTY:
+		finding.summary = "stdout is not a terminal"
+	default:
+		return finding
+	}
+	finding.status = doctorWarn
+	finding.hint = "the selector needs an interactive terminal for single-key input; use `switch` or `list` in scripts"
+	return finding
+}
+
+func checkColor(env doctorEnv) doctorFinding {
+	finding := doctorFinding{check: "colors"}
+	if name := strings.TrimSpace(env.getenv("BRANCH_NAVIGATOR_THEME")); name != "" {
+		if _, ok := ui.ThemeByName(name); !ok {
+			finding.status = doctorFail
+			finding.summary = fmt.Sprintf("BRANCH_NAVIGATOR_THEME=%q is not a known theme", name)
+			finding.hint = "use one of: " + strings.Join(ui.AvailableThemeNames(), ", ")
+			return finding
+		}
+	}
+
+	termName := env.getenv("TERM")
+	depth := ui.DetectColorDepth(env.getenv)
+	switch {
+	case termName == "" || termName == "dumb":
+		finding.status = doctorWarn
+		finding.summary = fmt.Sprintf("TERM=%q cannot render colors or clear the screen", termName)
+		finding.hint = "run from a terminal emulator with TERM set, such as xterm-256color"
+	case depth == ui.ColorsTrue:
+		finding.summary = "24-bit color"
+	case depth == ui.Colors256:
+		finding.summary = "256 colors"
+	default:
+		finding.status = doctorWarn
+		finding.summary = fmt.Sprintf("TERM=%s looks limited to 16 colors; themes use basic ANSI colors", termName)
+		finding.hint = "if your terminal supports more, set TERM=xterm-256color or COLORTERM=truecolor"
+	}
+	return finding
+}
+
+func checkConfig(env doctorEnv) doctorFinding {
+	finding := doctorFinding{check: "config"}
+	path, err := env.configPath()
+	if err != nil {
+		finding.status = doctorWarn
+		finding.summary = fmt.Sprintf("cannot locate the config file: %v", err)
+		finding.hint = "set BRANCH_NAVIGATOR_CONFIG to the file to use"
+		return finding
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		finding.summary = fmt.Sprintf("%s not found; using defaults", path)
+		return finding
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		finding.status = doctorFail
+		finding.summary = err.Error()
+		finding.hint = "fix the reported line or remove the file to fall back to defaults"
+		return finding
+	}
+	if _, ok := ui.ThemeByName(cfg.Theme); !ok {
+		finding.status = doctorFail
+		finding.summary = fmt.Sprintf("%s: theme = %q is not a known theme", path, cfg.Theme)
+		finding.hint = "run `branch-navigator themes --pick` to choose one"
+		return finding
+	}
+	finding.summary = path
+	return finding
+}
+
+func checkStateDir(env doctorEnv) doctorFinding {
+	finding := doctorFinding{check: "history"}
+	hint := "set BRANCH_NAVIGATOR_STATE_DIR to a writable directory"
+	dir, err := env.stateDir()
+	if err != nil {
+		finding.status = doctorFail
+		finding.summary = fmt.Sprintf("cannot locate the state directory: %v", err)
+		finding.hint = hint
+		return finding
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		finding.status = doctorFail
+		finding.summary = fmt.Sprintf("cannot create %s: %v", dir, err)
+		finding.hint = hint
+		return finding
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		finding.status = doctorFail
+		finding.summary = fmt.Sprintf("%s is not writable: %v", dir, err)
+		finding.hint = hint
+		return finding
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	finding.summary = dir + " is writable"
+	return finding
+}
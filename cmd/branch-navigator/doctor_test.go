@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+// doctorRunner answers git commands from outputs, failing those listed in errs.
+type doctorRunner struct {
+	outputs map[string]string
+	errs    map[string]error
+}
+
+func (r *doctorRunner) Run(ctx context.Context, args ...string) (string, error) {
+	key := strings.Join(args, " ")
+	return r.outputs[key], r.errs[key]
+}
+
+func healthyDoctorEnv(t *testing.T) doctorEnv {
+	t.Helper()
+	dir := t.TempDir()
+	return doctorEnv{
+		client: git.NewClient(&doctorRunner{outputs: map[string]string{
+			"--version":                   "git version 2.43.0\n",
+			"rev-parse --show-toplevel":   "/src/app\n",
+			"rev-parse --abbrev-ref HEAD": "main",
+		}}),
+		lookPath:   func(name string) (string, error) { return "/usr/bin/" + name, nil },
+		getenv:     fakeEnvLookup(map[string]string{"TERM": "xterm-256color"}),
+		stdinTTY:   true,
+		stdoutTTY:  true,
+		configPath: func() (string, error) { return filepath.Join(dir, "config.toml"), nil },
+		stateDir:   func() (string, error) { return filepath.Join(dir, "state"), nil },
+	}
+}
+
+func fakeEnvLookup(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestRunDoctorCommandHealthy(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	if code := runDoctorCommand(context.Background(), healthyDoctorEnv(t), nil, out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out.String())
+	}
+	for _, want := range []string{
+		"[ok]   git: version 2.43.0\n",
+		"[ok]   repository: /src/app\n",
+		"[ok]   terminal: stdin and stdout are terminals\n",
+		"[ok]   colors: 256 colors\n",
+		"config.toml not found; using defaults\n",
+		"is writable\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "hint:") {
+		t.Fatalf("healthy environment should not print hints:\n%s", out.String())
+	}
+}
+
+func TestRunDoctorCommandFailures(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		modify   func(t *testing.T, env *doctorEnv)
+		wantCode int
+		want     string
+	}{
+		"git missing": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+			},
+			wantCode: 1,
+			want:     "[FAIL] git: git executable not found in PATH\n       hint: install git 2.16 or newer",
+		},
+		"old git": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.client = git.NewClient(&doctorRunner{outputs: map[string]string{
+					"--version":                 "git version 2.9.5\n",
+					"rev-parse --show-toplevel": "/src/app\n",
+				}})
+			},
+			want: "[warn] git: version 2.9.5\n       hint: upgrade to git 2.16 or newer",
+		},
+		"outside a repository": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.client = git.NewClient(&doctorRunner{
+					outputs: map[string]string{"--version": "git version 2.43.0\n"},
+					errs:    map[string]error{"rev-parse --show-toplevel": errors.New("not a git repository")},
+				})
+			},
+			wantCode: 1,
+			want:     "[FAIL] repository: not inside a git repository",
+		},
+		"detached HEAD": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.client = git.NewClient(&doctorRunner{outputs: map[string]string{
+					"--version":                   "git version 2.43.0\n",
+					"rev-parse --show-toplevel":   "/src/app\n",
+					"rev-parse --abbrev-ref HEAD": "HEAD",
+				}})
+			},
+			want: "[warn] repository: /src/app (detached HEAD)",
+		},
+		"piped stdout": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.stdoutTTY = false
+			},
+			want: "[warn] terminal: stdout is not a terminal",
+		},
+		"dumb terminal": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.getenv = fakeEnvLookup(map[string]string{"TERM": "dumb"})
+			},
+			want: "[warn] colors: TERM=\"dumb\" cannot render colors",
+		},
+		"basic terminal": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.getenv = fakeEnvLookup(map[string]string{"TERM": "xterm"})
+			},
+			want: "[warn] colors: TERM=xterm looks limited to 16 colors",
+		},
+		"truecolor": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.getenv = fakeEnvLookup(map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"})
+			},
+			want: "[ok]   colors: 24-bit color",
+		},
+		"unknown theme": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				env.getenv = fakeEnvLookup(map[string]string{"TERM": "xterm-256color", "BRANCH_NAVIGATOR_THEME": "neon"})
+			},
+			wantCode: 1,
+			want:     "[FAIL] colors: BRANCH_NAVIGATOR_THEME=\"neon\" is not a known theme",
+		},
+		"invalid config": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				path := filepath.Join(t.TempDir(), "config.toml")
+				if err := os.WriteFile(path, []byte("colour = 'red'\n"), 0o600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+				env.configPath = func() (string, error) { return path, nil }
+			},
+			wantCode: 1,
+			want:     `unknown key "colour"`,
+		},
+		"unknown configured theme": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				path := filepath.Join(t.TempDir(), "config.toml")
+				if err := os.WriteFile(path, []byte("theme = 'neon'\n"), 0o600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+				env.configPath = func() (string, error) { return path, nil }
+			},
+			wantCode: 1,
+			want:     `theme = "neon" is not a known theme`,
+		},
+		"valid config": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				path := filepath.Join(t.TempDir(), "config.toml")
+				if err := os.WriteFile(path, []byte("base = 'main'\n"), 0o600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+				env.configPath = func() (string, error) { return path, nil }
+			},
+			want: "[ok]   config: ",
+		},
+		"unwritable state dir": {
+			modify: func(t *testing.T, env *doctorEnv) {
+				file := filepath.Join(t.TempDir(), "file")
+				if err := os.WriteFile(file, nil, 0o600); err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+				env.stateDir = func() (string, error) { return filepath.Join(file, "state"), nil }
+			},
+			wantCode: 1,
+			want:     "hint: set BRANCH_NAVIGATOR_STATE_DIR to a writable directory",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			env := healthyDoctorEnv(t)
+			tc.modify(t, &env)
+			out := &bytes.Buffer{}
+			if code := runDoctorCommand(context.Background(), env, nil, out, &bytes.Buffer{}); code != tc.wantCode {
+				t.Fatalf("exit code %d, want %d; output:\n%s", code, tc.wantCode, out.String())
+			}
+			if !strings.Contains(out.String(), tc.want) {
+				t.Fatalf("output missing %q:\n%s", tc.want, out.String())
+			}
+		})
+	}
+}
+
+func TestRunDoctorCommandRejectsArguments(t *testing.T) {
+	t.Parallel()
+
+	errOut := &bytes.Buffer{}
+	if code := runDoctorCommand(context.Background(), healthyDoctorEnv(t), []string{"extra"}, &bytes.Buffer{}, errOut); code != 2 {
+		t.Fatalf("exit code %d, want 2", code)
+	}
+	if !strings.Contains(errOut.String(), "Usage: branch-navigator doctor") {
+		t.Fatalf("usage missing from stderr: %q", errOut.String())
+	}
+}
@@ -0,0 +1,348 @@
+//go:build integration && linux
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"branch-navigator/pkg/gittest"
+)
+
+// The end-to-end tests run the real command in a pseudo-terminal against
+// repositories built by gittest:
+//
+//	go test -tags integration ./cmd/branch-navigator
+//
+// As in the tests of main, the test binary doubles as the command.
+
+// e2eTimeout bounds how long a session waits for expected output.
+const e2eTimeout = 10 * time.Second
+
+// ptySession is a run of the command attached to a pseudo-terminal.
+type ptySession struct {
+	t      *testing.T
+	cmd    *exec.Cmd
+	master *os.File
+
+	mu     sync.Mutex
+	output bytes.Buffer
+	// read is how much of output previous expect calls consumed.
+	read int
+	done chan struct{}
+}
+
+// start runs the command with args in repo on a fresh 80x24 terminal.
+func start(t *testing.T, repo *gittest.Repo, args ...string) *ptySession {
+	t.Helper()
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminals are unavailable: %v", err)
+	}
+	home := t.TempDir()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = repo.Dir
+	cmd.Env = append(repo.Env(),
+		runMainEnv+"=1",
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+home+"/config",
+		"XDG_STATE_HOME="+home+"/state",
+		"TERM=xterm-256color",
+	)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	slave.Close()
+
+	s := &ptySession{t: t, cmd: cmd, master: master, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := master.Read(buf)
+			s.mu.Lock()
+			s.output.Write(buf[:n])
+			s.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		master.Close()
+	})
+	return s
+}
+
+// expect waits until the output since the previous expect contains text.
+// Keys sent before the selector is drawn may be lost while it sets up the
+// terminal, so wait for the list before typing.
+func (s *ptySession) expect(text string) {
+	s.t.Helper()
+	deadline := time.Now().Add(e2eTimeout)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		rest := s.output.String()[s.read:]
+		if i := strings.Index(rest, text); i >= 0 {
+			s.read += i + len(text)
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.t.Fatalf("timed out waiting for %q; output:\n%s", text, s.transcript())
+}
+
+// send types keys into the terminal.
+func (s *ptySession) send(keys string) {
+	s.t.Helper()
+	if _, err := s.master.WriteString(keys); err != nil {
+		s.t.Fatalf("send %q: %v", keys, err)
+	}
+}
+
+// wait waits for the command to exit and returns its exit code.
+func (s *ptySession) wait() int {
+	s.t.Helper()
+	exited := make(chan error, 1)
+	go func() { exited <- s.cmd.Wait() }()
+	select {
+	case err := <-exited:
+		<-s.done
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		if err != nil {
+			s.t.Fatalf("wait: %v", err)
+		}
+		return 0
+	case <-time.After(e2eTimeout):
+		s.t.Fatalf("command did not exit; output:\n%s", s.transcript())
+		return -1
+	}
+}
+
+// transcript returns everything the command wrote.
+func (s *ptySession) transcript() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.output.String()
+}
+
+// openPTY opens a pseudo-terminal pair sized 80x24.
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	n, err := unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	if err := unix.IoctlSetWinsize(int(slave.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: 24, Col: 80}); err != nil {
+		master.Close()
+		slave.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+func TestE2ECheckout(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/a", "")
+	repo.Branch("feature/b", "")
+	repo.Visit("feature/a", "feature/b", gittest.DefaultBranch)
+
+	s := start(t, repo)
+	s.expect("Select a branch:")
+	s.send("/feature/a")
+	s.expect("feature/a")
+	s.send("\r")
+	if code := s.wait(); code != 0 {
+		t.Fatalf("exit code %d; output:\n%s", code, s.transcript())
+	}
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != "feature/a" {
+		t.Fatalf("current branch = %q, want feature/a", got)
+	}
+}
+
+func TestE2ECoOpensFilteredSelector(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/a", "")
+	repo.Branch("feature/b", "")
+	repo.Visit("feature/a", "feature/b", gittest.DefaultBranch)
+
+	// Both branches contain "feature", so co leaves the choice to the
+	// selector, filtered to them with the most recent first.
+	s := start(t, repo, "co", "feature")
+	s.expect("Select a branch:")
+	s.expect("feature/a")
+	s.send("\r")
+	if code := s.wait(); code != 0 {
+		t.Fatalf("exit code %d; output:\n%s", code, s.transcript())
+	}
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != "feature/b" {
+		t.Fatalf("current branch = %q, want feature/b", got)
+	}
+}
+
+func TestE2EMergeConflict(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.WriteFile("notes.txt", "base\n")
+	repo.Commit("add notes")
+	repo.CheckoutNew("feature/a")
+	repo.WriteFile("notes.txt", "from feature\n")
+	repo.Commit("edit notes on feature")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.WriteFile("notes.txt", "from main\n")
+	repo.Commit("edit notes on main")
+
+	s := start(t, repo, "-m")
+	s.expect("Select a branch:")
+	s.send("/feature/a")
+	s.expect("feature/a")
+	s.send("\r")
+	s.expect("'main' is a protected branch.")
+	s.send("main\r")
+	if code := s.wait(); code != 1 {
+		t.Fatalf("exit code %d, want 1; output:\n%s", code, s.transcript())
+	}
+	if !strings.Contains(s.transcript(), "CONFLICT") {
+		t.Fatalf("expected git's conflict report; output:\n%s", s.transcript())
+	}
+	if got := repo.Git("diff", "--name-only", "--diff-filter=U"); got != "notes.txt" {
+		t.Fatalf("unmerged paths = %q, want notes.txt", got)
+	}
+}
+
+func TestE2EForceDelete(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("feature/a")
+	tip := repo.Commit("unmerged work")
+	repo.Checkout(gittest.DefaultBranch)
+
+	s := start(t, repo, "-d")
+	s.expect("Select a branch:")
+	s.send("/feature/a")
+	s.expect("feature/a")
+	s.send("\r")
+	s.expect("Type feature/a to confirm")
+	s.send("feature/a\r")
+	if code := s.wait(); code != 0 {
+		t.Fatalf("exit code %d; output:\n%s", code, s.transcript())
+	}
+	if got := repo.Git("branch", "--list", "feature/a"); got != "" {
+		t.Fatalf("feature/a still exists: %q", got)
+	}
+	backups := repo.Git("for-each-ref", "--format=%(objectname)", "refs/branch-navigator/backup/")
+	if backups != tip {
+		t.Fatalf("backup refs point at %q, want %q", backups, tip)
+	}
+}
+
+// TestE2EScript drives the selector with --script, without a pseudo-terminal.
+func TestE2EScript(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/a", "")
+	repo.Branch("feature/b", "")
+
+	home := t.TempDir()
+	cmd := exec.Command(os.Args[0], "--script", "/ feature/b enter")
+	cmd.Dir = repo.Dir
+	cmd.Env = append(repo.Env(),
+		runMainEnv+"=1",
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+home+"/config",
+		"XDG_STATE_HOME="+home+"/state",
+		"TERM=xterm-256color",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run: %v; output:\n%s", err, output)
+	}
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != "feature/b" {
+		t.Fatalf("current branch = %q, want feature/b; output:\n%s", got, output)
+	}
+}
+
+// TestE2EResultChannel reads the picked branch from descriptor 3 while
+// stdout carries git's output.
+func TestE2EResultChannel(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/a", "")
+	repo.Branch("feature/b", "")
+
+	home := t.TempDir()
+	run := func(script string, extra ...string) string {
+		t.Helper()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("failed to create pipe: %v", err)
+		}
+		defer r.Close()
+		cmd := exec.Command(os.Args[0], append([]string{"--script", script, "--result-fd", "3"}, extra...)...)
+		cmd.Dir = repo.Dir
+		cmd.ExtraFiles = []*os.File{w}
+		cmd.Env = append(repo.Env(),
+			runMainEnv+"=1",
+			"HOME="+home,
+			"XDG_CONFIG_HOME="+home+"/config",
+			"XDG_STATE_HOME="+home+"/state",
+			"TERM=xterm-256color",
+		)
+		output, err := cmd.CombinedOutput()
+		w.Close()
+		if err != nil {
+			t.Fatalf("run: %v; output:\n%s", err, output)
+		}
+		result, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to read the result: %v", err)
+		}
+		return string(result)
+	}
+
+	if got, want := run("/ feature/b enter"), `{"action":"checkout","branch":"feature/b","outcome":"done","exitCode":0}`+"\n"; got != want {
+		t.Fatalf("result = %s, want %s", got, want)
+	}
+	if got, want := run("q"), `{"action":"checkout","outcome":"quit","exitCode":0}`+"\n"; got != want {
+		t.Fatalf("result after quitting = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// emptyCleanup plans the deletion of the empty branches for the e key of
+// the selector: the local branches whose tip is the tip of the base branch.
+// They hold no commits of their own, typically left behind by work that
+// never started. The restore hints of their backups go to hints, which the
+// caller shows once the selector has closed.
+func emptyCleanup(ctx context.Context, client *git.Client, cfg config.Config, hints io.Writer) ui.Cleanup {
+	return func() (string, func() (string, error), error) {
+		base, names, err := deletableEmptyBranches(ctx, client, cfg)
+		switch {
+		case err != nil:
+			return "", nil, err
+		case base == "":
+			return "no base branch found, so no branch counts as empty", nil, nil
+		case len(names) == 0:
+			return "no empty branches to delete", nil, nil
+		}
+		question := fmt.Sprintf("Delete %d empty %s (%s)?", len(names), plural(len(names), "branch", "branches"), reportedNames(names))
+		return question, func() (string, error) {
+			return deleteEmptyBranches(ctx, client, cfg, names, hints)
+		}, nil
+	}
+}
+
+// deletableEmptyBranches returns the base branch and the empty branches
+// that may be deleted: all but the current branch and protected ones.
+func deletableEmptyBranches(ctx context.Context, client *git.Client, cfg config.Config) (string, []string, error) {
+	base, err := baseBranchName(ctx, client, cfg)
+	if err != nil || base == "" {
+		return "", nil, err
+	}
+	names, err := client.EmptyBranches(ctx, base)
+	if err != nil {
+		return "", nil, err
+	}
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	patterns, err := protectedBranches(ctx, client, cfg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	deletable := []string{}
+	for _, name := range names {
+		if name != strings.TrimSpace(current) && !isProtected(name, patterns) {
+			deletable = append(deletable, name)
+		}
+	}
+	return base, deletable, nil
+}
+
+// deleteEmptyBranches backs up and deletes the confirmed branches that are
+// still empty, writes the restore hint of each backup to hints, and returns
+// the notice to show. They are force-deleted, since git would refuse those
+// not merged into the current branch. Branches that gained commits since the
+// question was asked are kept, and a branch whose backup fails is not
+// deleted.
+func deleteEmptyBranches(ctx context.Context, client *git.Client, cfg config.Config, confirmed []string, hints io.Writer) (string, error) {
+	_, names, err := deletableEmptyBranches(ctx, client, cfg)
+	if err != nil {
+		return "", err
+	}
+	empty := make(map[string]bool, len(names))
+	for _, name := range names {
+		empty[name] = true
+	}
+
+	deleted := []string{}
+	for _, name := range confirmed {
+		if !empty[name] {
+			continue
+		}
+		backup, err := client.BackupBranch(ctx, name, time.Now())
+		if err != nil {
+			return "", fmt.Errorf("deleted %d of %d empty branches, then backing up '%s' failed: %w", len(deleted), len(confirmed), name, err)
+		}
+		if result, err := client.DeleteBranch(ctx, name, git.DeleteOptions{Force: true}); err != nil {
+			_ = client.DeleteBackup(ctx, backup)
+			if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+				err = fmt.Errorf("%w: %s", err, stderr)
+			}
+			return "", fmt.Errorf("deleted %d of %d empty branches, then '%s' failed: %w", len(deleted), len(confirmed), name, err)
+		}
+		printBackupHint(hints, backup)
+		deleted = append(deleted, name)
+	}
+	if len(deleted) == 0 {
+		return "no empty branches left to delete", nil
+	}
+	return fmt.Sprintf("deleted %d empty %s: %s", len(deleted), plural(len(deleted), "branch", "branches"), reportedNames(deleted)), nil
+}
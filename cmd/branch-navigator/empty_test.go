@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestEmptyBranches(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("spike", "")
+	repo.Branch("later", "")
+	repo.Branch("develop", "")
+	repo.Git("config", protectedBranchesKey, "main develop")
+	repo.CheckoutNew("feature/x")
+	repo.Commit("work")
+	repo.Branch("idle", "")
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	ctx := context.Background()
+	cfg := config.Default()
+
+	annotator, err := newBranchAnnotator(ctx, client, actionCheckout, cfg, navigator.DefaultMaxReflog, branchFilter{}, repo.Now(), nil)
+	if err != nil {
+		t.Fatalf("newBranchAnnotator returned error: %v", err)
+	}
+	annotator.enrich(ctx)
+	branches := []ui.Branch{{Name: "feature/x", Current: true}, {Name: "spike"}, {Name: "idle"}, {Name: "main"}}
+	annotator.classify(branches)
+	for _, branch := range branches[1:] {
+		detail := annotator.detail(branch)
+		if empty := strings.HasPrefix(detail, "(empty)"); empty != (branch.Name == "spike") {
+			t.Fatalf("detail of %s = %q", branch.Name, detail)
+		}
+	}
+
+	hints := &bytes.Buffer{}
+	question, run, err := emptyCleanup(ctx, client, cfg, hints)()
+	if err != nil || run == nil {
+		t.Fatalf("cleanup = %q, %v; want a question", question, err)
+	}
+	if want := "Delete 2 empty branches (later, spike)?"; question != want {
+		t.Fatalf("question = %q, want %q", question, want)
+	}
+	// A branch that gains commits after the question is kept.
+	repo.Checkout("later")
+	repo.Commit("started after all")
+	repo.Checkout("feature/x")
+
+	notice, err := run()
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if want := "deleted 1 empty branch: spike"; notice != want {
+		t.Fatalf("notice = %q, want %q", notice, want)
+	}
+	if got, want := repo.Git("branch", "--format=%(refname:short)"), "develop\nfeature/x\nidle\nlater\nmain"; got != want {
+		t.Fatalf("branches = %q, want %q", got, want)
+	}
+	backups := repo.Git("for-each-ref", "--format=%(refname)", "refs/branch-navigator/backup")
+	if !strings.HasPrefix(backups, "refs/branch-navigator/backup/spike@") || strings.Contains(backups, "\n") {
+		t.Fatalf("backups = %q, want one of spike", backups)
+	}
+	if want := "Backup of spike saved as " + backups + " (restore with: git branch spike " + backups + ")\n"; hints.String() != want {
+		t.Fatalf("hints = %q, want %q", hints.String(), want)
+	}
+
+	if question, run, err := emptyCleanup(ctx, client, cfg, hints)(); err != nil || run != nil || question != "no empty branches to delete" {
+		t.Fatalf("second cleanup = %q, %v, %v; want nothing to delete", question, run != nil, err)
+	}
+}
+
+// failingRunner runs git for real but fails the commands that start with
+// prefix, as git does when a ref is locked or the repository is corrupt.
+type failingRunner struct {
+	git.Runner
+	prefix string
+}
+
+func (r failingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	if command := strings.Join(args, " "); r.prefix != "" && strings.HasPrefix(command, r.prefix) {
+		return "", errors.New("git " + command + ": exit status 128")
+	}
+	return r.Runner.Run(ctx, args...)
+}
+
+func TestEmptyCleanupFailures(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		fail         string
+		base         string
+		deleteFirst  bool
+		wantQuestion string
+		wantPlanErr  string
+		wantRunErr   string
+	}{
+		"no base branch":    {base: "trunk", wantQuestion: "no base branch found, so no branch counts as empty"},
+		"listing fails":     {fail: "for-each-ref --points-at", wantPlanErr: "for-each-ref --points-at=main"},
+		"current fails":     {fail: "rev-parse --abbrev-ref HEAD", wantPlanErr: "rev-parse --abbrev-ref HEAD"},
+		"protected fails":   {fail: "config --local --get " + protectedBranchesKey, wantPlanErr: protectedBranchesKey},
+		"backup fails":      {fail: "update-ref", wantQuestion: "Delete 1 empty branch (spike)?", wantRunErr: "deleted 0 of 1 empty branches, then backing up 'spike' failed"},
+		"delete fails":      {fail: "branch -D", wantQuestion: "Delete 1 empty branch (spike)?", wantRunErr: "deleted 0 of 1 empty branches, then 'spike' failed"},
+		"deleted meanwhile": {deleteFirst: true, wantQuestion: "Delete 1 empty branch (spike)?"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			repo.Branch("spike", "")
+			client := git.NewClient(failingRunner{Runner: &git.CLI{Dir: repo.Dir, Env: repo.Env()}, prefix: tc.fail})
+			cfg := config.Default()
+			cfg.Base = tc.base
+
+			question, run, err := emptyCleanup(context.Background(), client, cfg, &bytes.Buffer{})()
+			if tc.wantPlanErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantPlanErr) {
+					t.Fatalf("cleanup error = %v, want it to mention %q", err, tc.wantPlanErr)
+				}
+				return
+			}
+			if err != nil || question != tc.wantQuestion {
+				t.Fatalf("cleanup = %q, %v; want %q", question, err, tc.wantQuestion)
+			}
+			if run == nil {
+				return
+			}
+			if tc.deleteFirst {
+				repo.Git("branch", "-D", "spike")
+			}
+			notice, err := run()
+			if tc.wantRunErr == "" {
+				if err != nil || notice != "no empty branches left to delete" {
+					t.Fatalf("run = %q, %v; want nothing left to delete", notice, err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tc.wantRunErr) {
+				t.Fatalf("run error = %v, want it to start with %q", err, tc.wantRunErr)
+			}
+			if repo.Git("branch", "--list", "spike") == "" {
+				t.Fatal("spike was deleted despite the failure")
+			}
+			if backups := repo.Git("for-each-ref", "refs/branch-navigator/backup/"); backups != "" {
+				t.Fatalf("backups left after the failure: %q", backups)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/hosting"
+	"branch-navigator/internal/ui"
+)
+
+// expandedCommits is how many of the latest commits an expanded row lists.
+const expandedCommits = 3
+
+// detailLabelWidth aligns the values of the detail lines, after the longest
+// label, "pull request".
+const detailLabelWidth = len("pull request")
+
+// branchExpander returns the Expander behind Tab in the selector. It shows
+// the upstream of a local branch, its latest commits, the page listing its
+// pull requests when the code host is known, and its description.
+func branchExpander(ctx context.Context, client *git.Client) ui.Expander {
+	return func(branch ui.Branch) ([]string, error) {
+		return branchDetails(ctx, client, branch)
+	}
+}
+
+// branchDetails builds the detail lines of branch. Only a failure to list
+// its commits is an error; the pull request page is left out when the
+// remote is not on a known code host.
+func branchDetails(ctx context.Context, client *git.Client, branch ui.Branch) ([]string, error) {
+	var lines []string
+	add := func(label, value string) {
+		lines = append(lines, fmt.Sprintf("%-*s %s", detailLabelWidth, label, value))
+	}
+
+	if !branch.Remote {
+		upstream, err := client.BranchUpstream(ctx, branch.Name)
+		if err != nil {
+			return nil, err
+		}
+		if upstream.Remote == "" {
+			add("upstream", "none")
+		} else {
+			exists, err := client.RemoteBranchExists(ctx, upstream.Remote, upstream.Branch)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				add("upstream", upstream.String())
+			} else {
+				add("upstream", upstream.String()+" (gone)")
+			}
+		}
+	}
+
+	commits, err := client.RecentCommits(ctx, branch.Name, expandedCommits)
+	if err != nil {
+		return nil, err
+	}
+	label := "commits"
+	for _, commit := range commits {
+		add(label, commit.Short+" "+commit.Subject)
+		label = ""
+	}
+
+	if repo, remoteBranch, err := branchRepository(ctx, client, branch.Name, branch.Remote); err == nil {
+		if page, err := hosting.PullRequestsURL(repo, remoteBranch); err == nil {
+			add("pull request", page)
+		}
+	}
+
+	if !branch.Remote {
+		description, err := client.BranchDescription(ctx, branch.Name)
+		if err != nil {
+			return nil, err
+		}
+		label := "description"
+		for _, line := range strings.Split(description, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				add(label, line)
+				label = ""
+			}
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestBranchDetails(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("feature")
+	repo.Commit("first change")
+	repo.Commit("second change")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.Branch("plain", "")
+	repo.Git("remote", "add", "origin", "https://github.com/org/repo.git")
+	repo.Git("config", "branch.feature.remote", "origin")
+	repo.Git("config", "branch.feature.merge", "refs/heads/feature")
+	repo.Git("config", "branch.feature.description", "Rewrite the parser\n\nin two steps")
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+
+	lines, err := branchDetails(context.Background(), client, ui.Branch{Name: "feature"})
+	if err != nil {
+		t.Fatalf("branchDetails returned error: %v", err)
+	}
+	short := func(rev string) string { return repo.Git("rev-parse", "--short", rev) }
+	want := []string{
+		"upstream     origin/feature (gone)",
+		"commits      " + short("feature") + " second change",
+		"             " + short("feature~1") + " first change",
+		"             " + short("feature~2") + " initial commit",
+		"pull request https://github.com/org/repo/pulls?q=is%3Apr+head%3Afeature",
+		"description  Rewrite the parser",
+		"             in two steps",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("branchDetails() = %q, want %q", lines, want)
+	}
+
+	lines, err = branchDetails(context.Background(), client, ui.Branch{Name: "plain"})
+	if err != nil {
+		t.Fatalf("branchDetails returned error: %v", err)
+	}
+	if len(lines) != 3 || lines[0] != "upstream     none" {
+		t.Fatalf("branchDetails() without an upstream = %q", lines)
+	}
+}
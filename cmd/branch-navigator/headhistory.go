@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/timefmt"
+	"branch-navigator/internal/ui"
+)
+
+// runHeadHistory lists the recent positions of HEAD from the reflog,
+// detached checkouts and resets included, and checks out the selected one.
+// The checkout detaches HEAD at the commit of the entry.
+func runHeadHistory(ctx context.Context, client *git.Client, style selectorStyle, layout ui.Layout, format timefmt.Format, maxEntries int, in io.Reader, out io.Writer) error {
+	if client == nil {
+		return fmt.Errorf("git client is not configured")
+	}
+
+	entries, err := client.HeadHistory(ctx, maxEntries)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("the HEAD reflog is empty")
+	}
+
+	now := time.Now()
+	rows := make([]ui.Branch, len(entries))
+	bySelector := make(map[string]git.HeadEntry, len(entries))
+	for i, entry := range entries {
+		rows[i] = headEntryRow(entry, format, now)
+		bySelector[entry.Selector] = entry
+	}
+
+	picker := style.selector(in, out, ui.ActionDetails{
+		Name:        "HEAD history",
+		Description: "Recent positions of HEAD from the reflog; the selected one is checked out as a detached HEAD.",
+		EnterLabel:  "check out the selected commit",
+	})
+	picker.SetLayout(layout)
+	picked, err := picker.Select(rows)
+	if err != nil || picked.Quit || picked.AlreadyOn {
+		return err
+	}
+	entry := bySelector[picked.Branch]
+
+	proceed, err := prepareCheckout(ctx, client, in, out, entry.Short)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Fprintln(out, "Checkout cancelled.")
+		return nil
+	}
+	message, ok, err := checkoutCommit(ctx, client, in, out, entry.Short)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Fprintln(out, "Checkout cancelled.")
+		return nil
+	}
+	printIfNotEmpty(out, message)
+	fmt.Fprintf(out, "HEAD is now detached at %s (%s); git switch -c <name> keeps commits made here.\n", entry.Short, entry.Selector)
+	return nil
+}
+
+// headEntryRow shows an entry as its HEAD@{n} selector followed by the
+// commit, the reflog message, and when HEAD moved. HEAD@{0} is where HEAD
+// is now, so it is the current row.
+func headEntryRow(entry git.HeadEntry, format timefmt.Format, now time.Time) ui.Branch {
+	detail := entry.Short + " " + entry.Subject
+	if !entry.Time.IsZero() {
+		detail += " (" + format.Format(entry.Time, now) + ")"
+	}
+	return ui.Branch{Name: entry.Selector, Detail: detail, Current: entry.Selector == "HEAD@{0}"}
+}
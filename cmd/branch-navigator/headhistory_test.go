@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/timefmt"
+	"branch-navigator/internal/ui"
+)
+
+func TestRunHeadHistory(t *testing.T) {
+	t.Parallel()
+
+	reflogArgs := []string{"reflog", "-n", "20", "--date=unix", "--format=%H%x09%h%x09%gd%x09%gs"}
+	reflog := "aaaa1111\taaaa111\tHEAD@{300}\treset: moving to HEAD~1\n" +
+		"bbbb2222\tbbbb222\tHEAD@{200}\tcheckout: moving from main to bbbb2222\n"
+
+	cases := map[string]struct {
+		keys      string
+		status    string
+		useSwitch bool
+		wantCalls [][]string
+		wantOut   string
+	}{
+		"checkout detached entry": {
+			keys: "2\n",
+			wantCalls: [][]string{
+				reflogArgs,
+				{"status", "--porcelain"},
+				{"checkout", "--detach", "bbbb222"},
+			},
+			wantOut: "HEAD is now detached at bbbb222 (HEAD@{1})",
+		},
+		"switch detaches": {
+			keys:      "2\n",
+			useSwitch: true,
+			wantCalls: [][]string{
+				reflogArgs,
+				{"status", "--porcelain"},
+				{"--version"},
+				{"switch", "--detach", "bbbb222"},
+			},
+			wantOut: "HEAD is now detached at bbbb222 (HEAD@{1})",
+		},
+		"current entry": {
+			keys:      "1\n",
+			wantCalls: [][]string{reflogArgs},
+			wantOut:   "already on 'HEAD@{0}'",
+		},
+		"cancel over changes": {
+			keys:   "2\nc\n",
+			status: "M main.go",
+			wantCalls: [][]string{
+				reflogArgs,
+				{"status", "--porcelain"},
+			},
+			wantOut: "Checkout cancelled.",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{
+				strings.Join(reflogArgs, " "): reflog,
+				"rev-parse --abbrev-ref HEAD": "main",
+				"status --porcelain":          tc.status,
+				"--version":                   "git version 2.43.0\n",
+			}}
+			client := git.NewClient(runner)
+			client.SetSwitch(tc.useSwitch)
+			out := &bytes.Buffer{}
+			err := runHeadHistory(context.Background(), client, testStyle, ui.LayoutPlain, timefmt.Relative, 20, newKeys(tc.keys), out)
+			if err != nil {
+				t.Fatalf("runHeadHistory returned error: %v", err)
+			}
+			if !reflect.DeepEqual(runner.calls, tc.wantCalls) {
+				t.Fatalf("unexpected git calls: got %v, want %v", runner.calls, tc.wantCalls)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+		})
+	}
+}
+
+func TestRunHeadHistoryEmpty(t *testing.T) {
+	t.Parallel()
+
+	err := runHeadHistory(context.Background(), git.NewClient(&recordingRunner{}), testStyle, ui.LayoutPlain, timefmt.Relative, 0, strings.NewReader(""), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "reflog is empty") {
+		t.Fatalf("expected empty reflog error, got %v", err)
+	}
+}
+
+func TestHeadEntryRow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(10000, 0)
+	entry := git.HeadEntry{Selector: "HEAD@{0}", Short: "abc1234", Subject: "commit: Add parser", Time: now.Add(-2 * time.Hour)}
+	got := headEntryRow(entry, timefmt.Layout("15:04"), now)
+	want := ui.Branch{Name: "HEAD@{0}", Detail: "abc1234 commit: Add parser (" + entry.Time.Format("15:04") + ")", Current: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("headEntryRow() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/history"
+)
+
+// openHistory returns the default history store, or nil when its location
+// cannot be determined; history is a convenience and never blocks an action.
+func openHistory() *history.Store {
+	store, err := history.OpenDefault()
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// recordAction appends a history entry for a completed action. Failures are
+// reported as warnings only.
+func recordAction(ctx context.Context, store *history.Store, client *git.Client, errOut io.Writer, act action, from, branch string) {
+	if store == nil || client == nil {
+		return
+	}
+
+	repo, err := client.RepoRoot(ctx)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: failed to record history: %v\n", err)
+		return
+	}
+	entry := history.Entry{
+		Time:   time.Now().UTC(),
+		Repo:   repo,
+		Action: string(act),
+		From:   from,
+		Branch: branch,
+	}
+	if err := store.Append(entry); err != nil {
+		fmt.Fprintf(errOut, "warning: failed to record history: %v\n", err)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"branch-navigator/internal/cli"
+)
+
+// zshWidget registers a ZLE widget on Ctrl+G. The selector is drawn on the
+// terminal while --print hands the resulting git command back on stdout; it
+// runs straight away on an empty command line and is inserted at the cursor
+// otherwise.
+const zshWidget = `# branch-navigator ZLE widget: press Ctrl+G to pick a branch.
+branch-navigator-widget() {
+  local cmd
+  cmd="$(command branch-navigator --print < /dev/tty)"
+  local ret=$?
+  zle reset-prompt
+  if [[ -n $cmd ]]; then
+    if [[ -z $BUFFER ]]; then
+      BUFFER=$cmd
+      zle accept-line
+    else
+      LBUFFER+=$cmd
+    fi
+  fi
+  return $ret
+}
+zle -N branch-navigator-widget
+bindkey '^G' branch-navigator-widget
+`
+
+// powerShellCompleter registers a native argument completer. %s is replaced
+// by the flags of the program and of each subcommand, keyed by the
+// subcommand name and by the empty string for the program. Branch names are
+// completed after the words that take one.
+const powerShellCompleter = `# branch-navigator argument completer for PowerShell.
+Register-ArgumentCompleter -Native -CommandName branch-navigator -ScriptBlock {
+  param($wordToComplete, $commandAst, $cursorPosition)
+  $flags = @{
+%s  }
+  $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+  if ($wordToComplete) {
+    $words = @($words | Select-Object -SkipLast 1)
+  }
+  $command = ''
+  if ($words.Count -gt 0 -and $flags.ContainsKey($words[0])) {
+    $command = $words[0]
+  }
+  $takesBranch = ($words.Count -eq 1 -and $command -in @('switch', 'co')) -or
+    ($command -eq '' -and $words.Count -gt 0 -and $words[-1] -in @('--pick', '--contains'))
+  if ($takesBranch) {
+    $candidates = @(git for-each-ref --format='%%(refname:lstrip=2)' refs/heads 2>$null)
+  } elseif ($words.Count -eq 0 -and $wordToComplete -notlike '-*') {
+    $candidates = @($flags.Keys | Where-Object { $_ } | Sort-Object)
+  } else {
+    $candidates = $flags[$command]
+  }
+  $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+    [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+  }
+}
+`
+
+// powerShellKeyHandler binds Ctrl+G in PSReadLine. The selector runs on the
+// console with the command line left as it was, and the prompt is redrawn
+// afterwards so that it shows the branch that was checked out.
+const powerShellKeyHandler = `# branch-navigator PSReadLine key handler: press Ctrl+G to pick a branch.
+Set-PSReadLineKeyHandler -Chord Ctrl+g -BriefDescription BranchNavigator -Description 'Pick a branch with branch-navigator' -ScriptBlock {
+  branch-navigator
+  [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+}
+`
+
+// powerShellInit returns the PowerShell integration: the argument completer
+// for root and its subcommands, followed by the key handler when widget is set.
+func powerShellInit(root cli.Command, widget bool) string {
+	table := map[string][]string{"": flagWords(root.Flags)}
+	for _, sub := range root.Commands {
+		table[sub.Name] = flagWords(sub.Flags)
+	}
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries strings.Builder
+	for _, name := range names {
+		// Always quoted: a bare --flag would be an expression here.
+		quoted := make([]string, len(table[name]))
+		for i, word := range table[name] {
+			quoted[i] = "'" + word + "'"
+		}
+		fmt.Fprintf(&entries, "    '%s' = @(%s)\n", name, strings.Join(quoted, ", "))
+	}
+	script := fmt.Sprintf(powerShellCompleter, entries.String())
+	if widget {
+		script += powerShellKeyHandler
+	}
+	return script
+}
+
+// flagWords returns the flags as typed on the command line, leaving out the
+// hidden ones.
+func flagWords(flags []cli.Flag) []string {
+	words := []string{}
+	for _, f := range flags {
+		if f.Hidden {
+			continue
+		}
+		if len(f.Name) == 1 {
+			words = append(words, "-"+f.Name)
+		} else {
+			words = append(words, "--"+f.Name)
+		}
+	}
+	return words
+}
+
+// runInitCommand implements the init subcommand and returns the process exit code.
+func runInitCommand(args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator init", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, initCommand.Usage(programName))
+	}
+	widget := fs.Bool("widget", false, initCommand.FlagUsage("widget"))
+	// Accept flags on either side of the shell name, as in "init zsh --widget".
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	shell := fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			return 2
+		}
+	}
+	if shell == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	switch strings.ToLower(shell) {
+	case "zsh":
+		if !*widget {
+			fmt.Fprintln(errOut, "init zsh: only the --widget integration is available")
+			return 2
+		}
+		fmt.Fprint(out, zshWidget)
+	case "powershell", "pwsh":
+		fmt.Fprint(out, powerShellInit(rootCommand, *widget))
+	default:
+		fmt.Fprintf(errOut, "init: unsupported shell %q (supported: zsh, powershell)\n", shell)
+		return 2
+	}
+	return 0
+}
+
+// printCommand returns the git command that checks out the selected branch
+// in dir, or in the current directory when dir is empty, quoted for POSIX
+// shells, as printed by --print.
+func printCommand(dir, branch string, remote bool) string {
+	git := "git "
+	if dir != "" {
+		git += "-C " + shellQuote(dir) + " "
+	}
+	if remote {
+		return git + "switch --track " + shellQuote(branch)
+	}
+	return git + "switch " + shellQuote(branch)
+}
+
+// shellQuote returns s unchanged when it only contains characters that are
+// safe in a shell word, and wraps it in single quotes otherwise.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./@+:,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
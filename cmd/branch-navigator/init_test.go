@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/cli"
+)
+
+func TestRunInitCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args     []string
+		wantCode int
+		wantOut  string
+		wantErr  string
+	}{
+		"zsh widget":         {args: []string{"zsh", "--widget"}, wantOut: "bindkey '^G' branch-navigator-widget"},
+		"flag before shell":  {args: []string{"--widget", "zsh"}, wantOut: "zle -N branch-navigator-widget"},
+		"zsh without widget": {args: []string{"zsh"}, wantCode: 2, wantErr: "only the --widget integration is available"},
+		"powershell":         {args: []string{"powershell"}, wantOut: "Register-ArgumentCompleter -Native -CommandName branch-navigator"},
+		"powershell widget":  {args: []string{"pwsh", "--widget"}, wantOut: "Set-PSReadLineKeyHandler -Chord Ctrl+g"},
+		"unsupported shell":  {args: []string{"fish", "--widget"}, wantCode: 2, wantErr: `unsupported shell "fish"`},
+		"missing shell":      {wantCode: 2, wantErr: "Usage: branch-navigator init zsh --widget | powershell [--widget]"},
+		"extra arguments":    {args: []string{"zsh", "--widget", "bash"}, wantCode: 2, wantErr: "Usage: branch-navigator init"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			if code := runInitCommand(tc.args, out, errOut); code != tc.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr %q)", code, tc.wantCode, errOut.String())
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("stdout = %q, want it to contain %q", out.String(), tc.wantOut)
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr = %q, want it to contain %q", errOut.String(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPowerShellInit(t *testing.T) {
+	t.Parallel()
+
+	root := cli.Command{
+		Flags: []cli.Flag{{Name: "d"}, {Name: "theme", Arg: "NAME"}, {Name: "script", Hidden: true}},
+		Commands: []cli.Command{
+			{Name: "switch", Flags: []cli.Flag{{Name: "force-state"}}},
+			{Name: "doctor"},
+		},
+	}
+	script := powerShellInit(root, false)
+	for _, want := range []string{
+		"    '' = @('-d', '--theme')\n",
+		"    'doctor' = @()\n",
+		"    'switch' = @('--force-state')\n",
+		"--format='%(refname:lstrip=2)' refs/heads",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("completer does not contain %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "--script") || strings.Contains(script, "Set-PSReadLineKeyHandler") {
+		t.Fatalf("completer lists a hidden flag or binds a key without --widget:\n%s", script)
+	}
+	if widget := powerShellInit(root, true); !strings.HasPrefix(widget, script) || !strings.Contains(widget, "InvokePrompt()") {
+		t.Fatalf("widget does not follow the completer with the key handler:\n%s", widget)
+	}
+}
+
+func TestPrintCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		dir    string
+		branch string
+		remote bool
+		want   string
+	}{
+		"local":        {branch: "feature/login-v2", want: "git switch feature/login-v2"},
+		"remote":       {branch: "origin/topic", remote: true, want: "git switch --track origin/topic"},
+		"needs quotes": {branch: "fix$(id)", want: "git switch 'fix$(id)'"},
+		"single quote": {branch: "it's", want: `git switch 'it'\''s'`},
+		"non-ascii":    {branch: "機能", want: "git switch '機能'"},
+		"submodule":    {dir: "lib/core", branch: "main", want: "git -C lib/core switch main"},
+		"quoted dir":   {dir: "lib/my lib", branch: "origin/x", remote: true, want: "git -C 'lib/my lib' switch --track origin/x"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := printCommand(tc.dir, tc.branch, tc.remote); got != tc.want {
+				t.Fatalf("printCommand(%q, %q, %v) = %q, want %q", tc.dir, tc.branch, tc.remote, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+)
+
+// runInstallAliasCommand implements the install-alias subcommand and returns
+// the process exit code. Arguments after -- become default options of the alias.
+func runInstallAliasCommand(ctx context.Context, client *git.Client, lookPath func(string) (string, error), args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator install-alias", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, installAliasCommand.Usage(programName))
+	}
+	name := fs.String("name", "nav", installAliasCommand.FlagUsage("name"))
+	local := fs.Bool("local", false, installAliasCommand.FlagUsage("local"))
+	force := fs.Bool("force", false, installAliasCommand.FlagUsage("force"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if !validAliasName(*name) {
+		fmt.Fprintf(errOut, "invalid alias name %q: use letters, digits, and '-'\n", *name)
+		return 2
+	}
+	options := fs.Args()
+	if _, err := parseArgs(options, io.Discard, io.Discard); err != nil {
+		fmt.Fprintf(errOut, "invalid alias options %q: %v\n", strings.Join(options, " "), err)
+		return 2
+	}
+
+	scope := git.ConfigGlobal
+	if *local {
+		scope = git.ConfigLocal
+	}
+	key := "alias." + *name
+	value := aliasCommand(options)
+
+	existing, ok, err := client.ConfigValue(ctx, scope, key)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	switch {
+	case ok && existing == value:
+		fmt.Fprintf(out, "git %s is already installed: %s = %s\n", *name, key, value)
+	case ok && !*force:
+		fmt.Fprintf(errOut, "%s is already set to %q; pass --force to replace it\n", key, existing)
+		return 1
+	default:
+		if err := client.SetConfigValue(ctx, scope, key, value); err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		got, ok, err := client.ConfigValue(ctx, scope, key)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		if !ok || got != value {
+			fmt.Fprintf(errOut, "%s reads back as %q after writing %q; check your git configuration\n", key, got, value)
+			return 1
+		}
+		fmt.Fprintf(out, "Installed git %s: %s = %s\n", *name, key, value)
+	}
+
+	if _, err := lookPath(programName); err != nil {
+		fmt.Fprintf(errOut, "warning: %s is not on PATH, so git %s cannot run it yet\n", programName, *name)
+	}
+	fmt.Fprintf(out, "Try git %s, or git %s -m to merge.\n", *name, *name)
+	return 0
+}
+
+// aliasCommand returns the shell alias that runs branch-navigator with the
+// given default options; git appends the arguments passed to the alias.
+func aliasCommand(options []string) string {
+	parts := []string{"!" + programName}
+	for _, option := range options {
+		parts = append(parts, shellQuote(option))
+	}
+	return strings.Join(parts, " ")
+}
+
+// validAliasName reports whether name can be used as a git alias name.
+func validAliasName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
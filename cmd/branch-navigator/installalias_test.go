@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+// configRunner emulates git config over an in-memory set of values; reads of
+// unset keys fail with exit status 1 like git does.
+type configRunner struct {
+	values  map[string]string
+	unset   error
+	ignored bool // drop writes, as if another file overrode the value
+}
+
+func (r *configRunner) Run(ctx context.Context, args ...string) (string, error) {
+	if len(args) == 4 && args[0] == "config" && args[2] == "--get" {
+		value, ok := r.values[args[1]+" "+args[3]]
+		if !ok {
+			return "", r.unset
+		}
+		return value, nil
+	}
+	if len(args) == 4 && args[0] == "config" {
+		if !r.ignored {
+			r.values[args[1]+" "+args[2]] = args[3]
+		}
+		return "", nil
+	}
+	return "", errors.New("unexpected git call: " + strings.Join(args, " "))
+}
+
+func unsetConfigError(t *testing.T) error {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell exit codes are not supported on Windows")
+	}
+	return exec.Command("sh", "-c", "exit 1").Run()
+}
+
+func TestRunInstallAliasCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args      []string
+		values    map[string]string
+		ignored   bool
+		missing   bool
+		wantCode  int
+		wantValue map[string]string
+		wantOut   string
+		wantErr   string
+	}{
+		"global default": {
+			wantValue: map[string]string{"--global alias.nav": "!branch-navigator"},
+			wantOut:   "Installed git nav: alias.nav = !branch-navigator\nTry git nav, or git nav -m to merge.\n",
+		},
+		"options and name": {
+			args:      []string{"--name", "bn", "--local", "--", "--theme", "nord", "-n", "20"},
+			wantValue: map[string]string{"--local alias.bn": "!branch-navigator --theme nord -n 20"},
+			wantOut:   "Installed git bn: alias.bn = !branch-navigator --theme nord -n 20\n",
+		},
+		"already installed": {
+			values:    map[string]string{"--global alias.nav": "!branch-navigator"},
+			wantValue: map[string]string{"--global alias.nav": "!branch-navigator"},
+			wantOut:   "git nav is already installed",
+		},
+		"existing alias": {
+			values:    map[string]string{"--global alias.nav": "!tig"},
+			wantCode:  1,
+			wantValue: map[string]string{"--global alias.nav": "!tig"},
+			wantErr:   `alias.nav is already set to "!tig"; pass --force to replace it`,
+		},
+		"force": {
+			args:      []string{"--force"},
+			values:    map[string]string{"--global alias.nav": "!tig"},
+			wantValue: map[string]string{"--global alias.nav": "!branch-navigator"},
+			wantOut:   "Installed git nav",
+		},
+		"verification fails": {
+			ignored:  true,
+			wantCode: 1,
+			wantErr:  `alias.nav reads back as "" after writing "!branch-navigator"`,
+		},
+		"binary not on PATH": {
+			missing:   true,
+			wantValue: map[string]string{"--global alias.nav": "!branch-navigator"},
+			wantErr:   "warning: branch-navigator is not on PATH",
+		},
+		"invalid name": {
+			args:     []string{"--name", "my alias"},
+			wantCode: 2,
+			wantErr:  `invalid alias name "my alias"`,
+		},
+		"invalid options": {
+			args:     []string{"--", "-m", "-d"},
+			wantCode: 2,
+			wantErr:  `invalid alias options "-m -d"`,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			values := map[string]string{}
+			for k, v := range tc.values {
+				values[k] = v
+			}
+			runner := &configRunner{values: values, unset: unsetConfigError(t), ignored: tc.ignored}
+			lookPath := func(name string) (string, error) { return "/usr/local/bin/" + name, nil }
+			if tc.missing {
+				lookPath = func(name string) (string, error) { return "", exec.ErrNotFound }
+			}
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			code := runInstallAliasCommand(context.Background(), git.NewClient(runner), lookPath, tc.args, out, errOut)
+			if code != tc.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr %q)", code, tc.wantCode, errOut.String())
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("stdout = %q, want it to contain %q", out.String(), tc.wantOut)
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr = %q, want it to contain %q", errOut.String(), tc.wantErr)
+			}
+			for key, want := range tc.wantValue {
+				if got := values[key]; got != want {
+					t.Fatalf("config %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
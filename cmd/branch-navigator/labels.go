@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// branchLabelsVariable is the variable of the branch.<name> section of the
+// repository's git configuration that holds the labels of a branch,
+// separated by commas. git renames the section along with the branch and
+// removes it when the branch is deleted, so labels follow their branch.
+const branchLabelsVariable = "navigatorLabels"
+
+// branchLabelsKey returns the git configuration key of the labels of branch.
+func branchLabelsKey(branch string) string {
+	return "branch." + branch + "." + branchLabelsVariable
+}
+
+// branchLabels returns the labels of each local branch that has any.
+func branchLabels(ctx context.Context, client *git.Client) (map[string][]string, error) {
+	// git reports the variable in lowercase.
+	suffix := "." + strings.ToLower(branchLabelsVariable)
+	values, err := client.ConfigValues(ctx, git.ConfigLocal, `^branch\..*\`+suffix+`$`)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string][]string, len(values))
+	for key, value := range values {
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), suffix)
+		if parsed := ui.ParseLabels(value); len(parsed) > 0 {
+			labels[name] = parsed
+		}
+	}
+	return labels, nil
+}
+
+// branchLabeler saves the labels edited in the selector, removing the key
+// when none are left.
+func branchLabeler(ctx context.Context, client *git.Client) ui.Labeler {
+	return func(branch ui.Branch, labels []string) error {
+		if branch.Remote {
+			return errors.New("only local branches can be labelled")
+		}
+		key := branchLabelsKey(branch.Name)
+		if len(labels) == 0 {
+			return client.UnsetConfigValue(ctx, git.ConfigLocal, key)
+		}
+		return client.SetConfigValue(ctx, git.ConfigLocal, key, strings.Join(labels, ", "))
+	}
+}
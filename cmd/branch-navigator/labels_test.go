@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestBranchLabels(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/Login.v2", "")
+	repo.Branch("spike", "")
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	ctx := context.Background()
+	label := branchLabeler(ctx, client)
+
+	if err := label(ui.Branch{Name: "feature/Login.v2"}, []string{"waiting on review", "demo Friday"}); err != nil {
+		t.Fatalf("labeler returned error: %v", err)
+	}
+	if err := label(ui.Branch{Name: "spike"}, []string{"try"}); err != nil {
+		t.Fatalf("labeler returned error: %v", err)
+	}
+	if got := repo.Git("config", "--local", "branch.feature/Login.v2.navigatorLabels"); got != "waiting on review, demo Friday" {
+		t.Fatalf("stored labels = %q", got)
+	}
+	// The labels follow a renamed branch.
+	repo.Git("branch", "-m", "spike", "spike2")
+	if err := label(ui.Branch{Name: "origin/spike", Remote: true}, []string{"x"}); err == nil {
+		t.Fatal("expected an error for a remote-tracking branch")
+	}
+
+	got, err := branchLabels(ctx, client)
+	if err != nil {
+		t.Fatalf("branchLabels returned error: %v", err)
+	}
+	want := map[string][]string{"feature/Login.v2": {"waiting on review", "demo Friday"}, "spike2": {"try"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("branchLabels() = %v, want %v", got, want)
+	}
+
+	annotator, err := newBranchAnnotator(ctx, client, actionCheckout, config.Default(), navigator.DefaultMaxReflog, branchFilter{}, repo.Now(), nil)
+	if err != nil {
+		t.Fatalf("newBranchAnnotator returned error: %v", err)
+	}
+	rows := []ui.Branch{{Name: "spike2"}, {Name: "spike2", Remote: true}}
+	annotator.classify(rows)
+	if !reflect.DeepEqual(rows[0].Labels, []string{"try"}) || rows[1].Labels != nil {
+		t.Fatalf("classified labels = %q and %q", rows[0].Labels, rows[1].Labels)
+	}
+
+	if err := label(ui.Branch{Name: "spike2"}, nil); err != nil {
+		t.Fatalf("labeler returned error: %v", err)
+	}
+	if err := label(ui.Branch{Name: "spike2"}, nil); err != nil {
+		t.Fatalf("removing missing labels returned error: %v", err)
+	}
+	if got, err := branchLabels(ctx, client); err != nil || len(got) != 1 {
+		t.Fatalf("branchLabels() = %v, %v; want only feature/Login.v2", got, err)
+	}
+}
+
+func TestBranchLabelsFailures(t *testing.T) {
+	t.Parallel()
+
+	read := func(ctx context.Context, client *git.Client) error {
+		_, err := branchLabels(ctx, client)
+		return err
+	}
+	save := func(labels []string) func(context.Context, *git.Client) error {
+		return func(ctx context.Context, client *git.Client) error {
+			return branchLabeler(ctx, client)(ui.Branch{Name: "spike"}, labels)
+		}
+	}
+	cases := map[string]struct {
+		fail string
+		call func(context.Context, *git.Client) error
+		want string
+	}{
+		"listing": {fail: "config --local --get-regexp", call: read, want: "--get-regexp"},
+		"saving":  {fail: "config --local branch.spike", call: save([]string{"try"}), want: "branch.spike.navigatorLabels"},
+		"removal": {fail: "config --local --unset", call: save(nil), want: "--unset"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			repo.Branch("spike", "")
+			repo.Git("config", "--local", branchLabelsKey("spike"), "old")
+			client := git.NewClient(failingRunner{Runner: &git.CLI{Dir: repo.Dir, Env: repo.Env()}, prefix: tc.fail})
+
+			if err := tc.call(context.Background(), client); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error = %v, want it to mention %q", err, tc.want)
+			}
+			if got := repo.Git("config", "--local", branchLabelsKey("spike")); got != "old" {
+				t.Fatalf("stored labels = %q, want them untouched", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+// listColumns maps the column names accepted by --columns to their values.
+var listColumns = map[string]func(listEntry) string{
+	"name":     func(e listEntry) string { return e.Name },
+	"current":  func(e listEntry) string { return strconv.FormatBool(e.Current) },
+	"date":     func(e listEntry) string { return e.LastCommitDate.Format(time.RFC3339) },
+	"upstream": func(e listEntry) string { return e.Upstream },
+	"ahead":    func(e listEntry) string { return strconv.Itoa(e.Ahead) },
+	"behind":   func(e listEntry) string { return strconv.Itoa(e.Behind) },
+	"merged":   func(e listEntry) string { return strconv.FormatBool(e.Merged) },
+	"commits":  func(e listEntry) string { return strconv.Itoa(e.Commits) },
+}
+
+// defaultListColumns is the tsv/csv column order used without --columns.
+var defaultListColumns = []string{"name", "current", "date", "upstream", "ahead", "behind", "merged", "commits"}
+
+// listEntry is the JSON representation of a branch printed by list --json.
+type listEntry struct {
+	Name           string    `json:"name"`
+	Current        bool      `json:"current"`
+	LastCommitDate time.Time `json:"lastCommitDate"`
+	Upstream       string    `json:"upstream"`
+	Ahead          int       `json:"ahead"`
+	Behind         int       `json:"behind"`
+	Merged         bool      `json:"merged"`
+	// Commits counts the commits the branch has that the base branch lacks.
+	Commits int `json:"commits"`
+}
+
+// runListCommand implements the list subcommand and returns the process exit code.
+func runListCommand(ctx context.Context, client *git.Client, cfg config.Config, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator list", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(out, listCommand.Usage(programName))
+	}
+	asJSON := fs.Bool("json", false, listCommand.FlagUsage("json"))
+	format := fs.String("format", "plain", listCommand.FlagUsage("format"))
+	columnList := fs.String("columns", "", listCommand.FlagUsage("columns"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprint(errOut, listCommand.Usage(programName))
+		return 2
+	}
+	if *asJSON {
+		*format = "json"
+	}
+	columns, err := parseListColumns(*format, *columnList)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	entries, err := listEntries(ctx, client, cfg)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	if err := writeListEntries(out, *format, columns, entries); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+// parseListColumns validates --format and --columns and returns the tsv/csv columns.
+func parseListColumns(format, columnList string) ([]string, error) {
+	switch format {
+	case "plain", "json":
+		if strings.TrimSpace(columnList) != "" {
+			return nil, errors.New("--columns requires --format tsv or csv")
+		}
+		return nil, nil
+	case "tsv", "csv":
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected plain, json, tsv, or csv)", format)
+	}
+
+	if strings.TrimSpace(columnList) == "" {
+		return defaultListColumns, nil
+	}
+	columns := []string{}
+	for _, column := range strings.Split(columnList, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := listColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q (expected %s)", column, strings.Join(defaultListColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func writeListEntries(out io.Writer, format string, columns []string, entries []listEntry) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case "tsv":
+		for _, entry := range entries {
+			if _, err := fmt.Fprintln(out, strings.Join(listRow(entry, columns), "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		writer := csv.NewWriter(out)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := writer.Write(listRow(entry, columns)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		for _, entry := range entries {
+			if _, err := fmt.Fprintln(out, entry.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func listRow(entry listEntry, columns []string) []string {
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = listColumns[column](entry)
+	}
+	return row
+}
+
+// listEntries collects every local branch, the current one first.
+func listEntries(ctx context.Context, client *git.Client, cfg config.Config) ([]listEntry, error) {
+	statuses, err := client.BranchStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := baseBranchName(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergedBranchSet(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := commitCountSet(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]listEntry, 0, len(statuses))
+	for _, status := range statuses {
+		entry := listEntry{
+			Name:           status.Name,
+			Current:        status.Name == current,
+			LastCommitDate: status.CommitDate.UTC(),
+			Upstream:       status.Upstream,
+			Ahead:          status.Ahead,
+			Behind:         status.Behind,
+			Merged:         merged[status.Name],
+			Commits:        commits[status.Name],
+		}
+		if entry.Current {
+			entries = append([]listEntry{entry}, entries...)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
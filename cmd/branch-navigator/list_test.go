@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+func listRunner() *recordingRunner {
+	return &recordingRunner{outputs: map[string]string{
+		"for-each-ref --sort=-committerdate --format=%(refname:lstrip=2)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track) refs/heads": "feature/x\t300\torigin/feature/x\t[ahead 2, behind 1]\n" +
+			"main\t200\torigin/main\t\n" +
+			"old\t100\t\t\n",
+		"rev-parse --abbrev-ref HEAD":                                            "main",
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                  "origin/main",
+		"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes": "refs/heads/main\nrefs/heads/old\n",
+		"--version": "git version 2.43.0",
+		"for-each-ref --format=%(refname)%09%(symref)%09%(ahead-behind:main) refs/heads refs/remotes": "refs/heads/feature/x\t\t4 0\nrefs/heads/main\t\t0 0\nrefs/heads/old\t\t0 3\n",
+	}}
+}
+
+func TestRunListCommandJSON(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	code := runListCommand(context.Background(), git.NewClient(listRunner()), config.Default(), []string{"--json"}, out, errOut)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, errOut.String())
+	}
+
+	var got []listEntry
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out.String())
+	}
+	want := []listEntry{
+		{Name: "main", Current: true, LastCommitDate: time.Unix(200, 0).UTC(), Upstream: "origin/main"},
+		{Name: "feature/x", LastCommitDate: time.Unix(300, 0).UTC(), Upstream: "origin/feature/x", Ahead: 2, Behind: 1, Commits: 4},
+		{Name: "old", LastCommitDate: time.Unix(100, 0).UTC(), Merged: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("list --json = %+v, want %+v", got, want)
+	}
+
+	var raw []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &raw); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	for _, key := range []string{"name", "current", "lastCommitDate", "upstream", "ahead", "behind", "merged", "commits"} {
+		if _, ok := raw[0][key]; !ok {
+			t.Fatalf("field %q missing from %v", key, raw[0])
+		}
+	}
+}
+
+func TestRunListCommandPlain(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	code := runListCommand(context.Background(), git.NewClient(listRunner()), config.Default(), nil, out, &bytes.Buffer{})
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if got, want := out.String(), "main\nfeature/x\nold\n"; got != want {
+		t.Fatalf("list output = %q, want %q", got, want)
+	}
+}
+
+func TestRunListCommandRejectsArguments(t *testing.T) {
+	t.Parallel()
+
+	errOut := &bytes.Buffer{}
+	if code := runListCommand(context.Background(), git.NewClient(&recordingRunner{}), config.Default(), []string{"extra"}, &bytes.Buffer{}, errOut); code != 2 {
+		t.Fatalf("exit code %d, want 2", code)
+	}
+	if !bytes.Contains(errOut.Bytes(), []byte("Usage: branch-navigator list")) {
+		t.Fatalf("usage missing from stderr: %q", errOut.String())
+	}
+}
+
+func TestRunListCommandFormats(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"tsv default columns": {
+			args: []string{"--format", "tsv"},
+			want: "main\ttrue\t1970-01-01T00:03:20Z\torigin/main\t0\t0\tfalse\t0\n" +
+				"feature/x\tfalse\t1970-01-01T00:05:00Z\torigin/feature/x\t2\t1\tfalse\t4\n" +
+				"old\tfalse\t1970-01-01T00:01:40Z\t\t0\t0\ttrue\t0\n",
+		},
+		"tsv selected columns": {
+			args: []string{"--format", "tsv", "--columns", "name, upstream,ahead"},
+			want: "main\torigin/main\t0\nfeature/x\torigin/feature/x\t2\nold\t\t0\n",
+		},
+		"csv has a header": {
+			args: []string{"--format=csv", "--columns=name,date,upstream"},
+			want: "name,date,upstream\n" +
+				"main,1970-01-01T00:03:20Z,origin/main\n" +
+				"feature/x,1970-01-01T00:05:00Z,origin/feature/x\n" +
+				"old,1970-01-01T00:01:40Z,\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			if code := runListCommand(context.Background(), git.NewClient(listRunner()), config.Default(), tc.args, out, errOut); code != 0 {
+				t.Fatalf("exit code %d, stderr %q", code, errOut.String())
+			}
+			if got := out.String(); got != tc.want {
+				t.Fatalf("output = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunListCommandFormatErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"unknown format":        {args: []string{"--format", "xml"}, wantErr: `unknown format "xml"`},
+		"unknown column":        {args: []string{"--format", "csv", "--columns", "name,size"}, wantErr: `unknown column "size"`},
+		"columns without table": {args: []string{"--json", "--columns", "name"}, wantErr: "--columns requires --format tsv or csv"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			errOut := &bytes.Buffer{}
+			if code := runListCommand(context.Background(), git.NewClient(listRunner()), config.Default(), tc.args, &bytes.Buffer{}, errOut); code != 2 {
+				t.Fatalf("exit code %d, want 2", code)
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr %q does not contain %q", errOut.String(), tc.wantErr)
+			}
+		})
+	}
+}
@@ -1,48 +1,232 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"branch-navigator/internal/app"
 	"branch-navigator/internal/git"
+	"branch-navigator/internal/match"
 	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/browser"
+	"branch-navigator/internal/platform/clipboard"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+	"branch-navigator/internal/platform/logfile"
+	"branch-navigator/internal/platform/session"
+	"branch-navigator/internal/timefmt"
 	"branch-navigator/internal/ui"
+
+	"golang.org/x/term"
 )
 
-type action string
+// action names the action a run performs; the built-in ones are registered
+// in app.Actions.
+type action = app.Action
 
 const (
-	actionCheckout action = "checkout"
-	actionMerge    action = "merge"
-	actionDelete   action = "delete"
+	actionCheckout    = app.ActionCheckout
+	actionMerge       = app.ActionMerge
+	actionMergeCheck  = app.ActionMergeCheck
+	actionDelete      = app.ActionDelete
+	actionArchive     = app.ActionArchive
+	actionUnarchive   = app.ActionUnarchive
+	actionRebase      = app.ActionRebase
+	actionRemoteAdmin = app.ActionRemoteAdmin
+	actionHeadHistory = app.ActionHeadHistory
+	actionPush        = app.ActionPush
+	actionLog         = app.ActionLog
+	actionDiff        = app.ActionDiff
+	actionBrowse      = app.ActionBrowse
+	actionCreate      = app.ActionCreate
+	actionCopy        = app.ActionCopy
+	actionReset       = app.ActionReset
 )
 
-const usageText = `Usage: branch-navigator [-c|-m|-d] [-n N] [-h]
-
-Options:
-  -c	checkout the selected branch (default)
-  -m	merge the selected branch into the current branch
-  -d	delete the selected local branch
-  -n	maximum number of branches to list (default 10)
-      --limit N	alias for -n
-      --theme NAME	color theme (catppuccin, nord, classic, solarized, gruvbox, onedark; default catppuccin)
-  -h	show this help message
-`
-
 type cliOptions struct {
 	action action
-	limit  int
-	theme  string
+	// limit caps the number of listed branches; 0 lists every branch.
+	limit int
+	all   bool
+	// maxReflog bounds the reflog entries read; 0 reads the whole reflog.
+	maxReflog int
+	theme     string
+	regex     bool
+	// plain and interactive override the selector layout detected by internal/ui.
+	plain       bool
+	interactive bool
+	// print writes the git command for the selection to stdout instead of running it.
+	print bool
+	// noHeader hides the selector header regardless of ui.header.
+	noHeader bool
+	// watch refreshes the selector when the refs change on disk, as
+	// ui.auto_refresh does.
+	watch bool
+	// resultFile and resultFD name where the picked branch and the outcome
+	// of the action are written as JSON, for shell functions and editor
+	// plugins; zero values write nothing.
+	resultFile string
+	resultFD   int
+	// serve answers JSON-RPC requests on stdin instead of showing the
+	// selector.
+	serve bool
+	// submodule picks a submodule first and runs the action inside it.
+	submodule bool
+	// then lists the steps run after the action succeeds, each a built-in
+	// step, a shell command, or the name of a configured pipeline.
+	then []string
+	// pick names the branch to act on instead of asking for it, as --pick
+	// does; stdin reads that name from stdin.
+	pick  string
+	stdin bool
+	// script lists synthetic keys for the selector, as --script does.
+	script string
+	// record and replay name the session files of --record and --replay.
+	record string
+	replay string
+	// now is the moment relative times are drawn against; a replay takes
+	// it from the recording.
+	now time.Time
+	// customAction names the user-defined action chosen with --action.
+	customAction string
+	// multi lets several branches be marked and applies the action to
+	// each of them.
+	multi bool
+	// menu asks for the action after the branch is picked instead of
+	// taking it from a flag.
+	menu bool
+	// forceState runs actions that switch branches even while a merge,
+	// rebase, or bisect is in progress.
+	forceState bool
+	// merge holds the signing flags of the merge action.
+	merge mergeFlags
+	// height draws the selector inline below the prompt; zero uses the whole screen.
+	height ui.Height
+	// gitConfig holds key=value settings passed to every git command with -c.
+	gitConfig []string
+	// query is the filter the selector opens with instead of the saved
+	// one, as co leaves it when several branches contain its text.
+	query string
+	// filter keeps only the branches selected by --author, --since, --before, --contains, and --no-merged.
+	filter branchFilter
 }
 
 func main() {
-	opts, err := parseArgs(os.Args[1:], os.Stdout, os.Stderr)
+	store := openHistory()
+	stdin := sharedStdin(os.Stdin)
+	// rootArgs are the options of the selector; co replaces them when it
+	// leaves the choice to the selector, along with its filter.
+	rootArgs, query := os.Args[1:], ""
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "switch":
+			os.Exit(runSwitchCommand(context.Background(), git.NewDefaultClient(), store, os.Args[2:], os.Stdout, os.Stderr))
+		case "co":
+			cfg, err := loadConfig(context.Background(), git.NewDefaultClient())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			// co checks out with the git settings and retries of the selector.
+			newClient := func(gitConfig []string) *git.Client {
+				client := git.NewClient(&git.CLI{Config: gitConfig})
+				client.SetRetryPolicy(retryPolicy(cfg, os.Stderr))
+				client.SetSwitch(cfg.CheckoutSwitch)
+				return client
+			}
+			selection, code := runCoCommand(context.Background(), newClient, store, os.Args[2:], stdin, os.Stdout, os.Stderr)
+			if selection == nil {
+				os.Exit(code)
+			}
+			rootArgs, query = selection.args, selection.query
+		case "list":
+			cfg, err := loadConfig(context.Background(), git.NewDefaultClient())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runListCommand(context.Background(), git.NewDefaultClient(), cfg, os.Args[2:], os.Stdout, os.Stderr))
+		case "report":
+			cfg, err := loadConfig(context.Background(), git.NewDefaultClient())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runReportCommand(context.Background(), git.NewDefaultClient(), cfg, os.Args[2:], os.Stdout, os.Stderr))
+		case "history":
+			cfg, err := loadConfig(context.Background(), git.NewDefaultClient())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runHistoryCommand(context.Background(), git.NewDefaultClient(), store, displayTimeFormat(cfg, historyTimeFormat), os.Args[2:], os.Stdout, os.Stderr))
+		case "stats":
+			os.Exit(runStatsCommand(store, os.Args[2:], os.Stdout, os.Stderr))
+		case "themes":
+			path, err := config.Path()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			cfg, err := config.LoadFile(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runThemesCommand(path, cfg, ui.DetectColorDepth(os.Getenv), os.Getenv, os.Args[2:], stdin, os.Stdout, os.Stderr))
+		case "config":
+			path, err := config.Path()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runConfigCommand(context.Background(), git.NewDefaultClient(), path, os.Args[2:], os.Stdout, os.Stderr))
+		case "doctor":
+			os.Exit(runDoctorCommand(context.Background(), defaultDoctorEnv(), os.Args[2:], os.Stdout, os.Stderr))
+		case "init":
+			os.Exit(runInitCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "install-alias":
+			os.Exit(runInstallAliasCommand(context.Background(), git.NewDefaultClient(), exec.LookPath, os.Args[2:], os.Stdout, os.Stderr))
+		case "docs":
+			os.Exit(runDocsCommand(os.Args[2:], time.Now(), os.Stdout, os.Stderr))
+		case "repos":
+			cfg, err := config.Load()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			style, err := newSelectorStyle("", cfg, detectBackground, ui.DetectColorDepth(os.Getenv))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runReposCommand(context.Background(), store, git.NewDefaultClientAt, cfg, style, os.Args[2:], stdin, os.Stdout, os.Stderr))
+		case "sweep":
+			cfg, err := config.Load()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			style, err := newSelectorStyle("", cfg, detectBackground, ui.DetectColorDepth(os.Getenv))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			os.Exit(runSweepCommand(context.Background(), git.NewDefaultClientAt, cfg, style, os.Args[2:], stdin, os.Stdout, os.Stderr))
+		}
+	}
+
+	opts, err := parseArgs(rootArgs, os.Stdout, os.Stderr)
 	if err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return
@@ -50,60 +234,375 @@ func main() {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
-
-	ctx := context.Background()
-	client := git.NewDefaultClient()
-	nav, err := navigator.New(client)
+	opts.query = query
+	var replayed session.Session
+	if opts.replay != "" {
+		opts, replayed, err = loadReplay(opts.replay)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	recorder := newSessionRecorder(opts, rootArgs, replayed)
+	results, err := openResultChannel(opts, os.Stderr)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(2)
 	}
+	// Every return that did not report another outcome ran the action.
+	defer results.finish(outcomeDone, 0, nil)
 
-	branches, err := nav.RecentBranches(ctx, opts.limit)
+	cfg, err := loadConfig(context.Background(), git.NewDefaultClient())
 	if err != nil {
+		results.finish(outcomeFailed, 2, err)
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(2)
 	}
 
-	current, err := client.CurrentBranch(ctx)
+	started := time.Now()
+	logger, err := logfile.Open(logfile.Path(os.Getenv, cfg.LogFile))
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; logging is off\n", err)
+	}
+	if logger != nil {
+		logger.Info("start", "args", os.Args[1:], "action", opts.action)
+	}
+	// fail reports err on stderr and in the log, then exits with code.
+	fail := func(code int, err error) {
+		if logger != nil {
+			logger.Error("exit", "code", code, "err", err.Error(), "duration", time.Since(started))
+		}
+		results.finish(outcomeFailed, code, err)
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(code)
+	}
+	if opts.serve {
+		// stdin carries the requests, so credential prompts stay off.
+		client := git.NewClient(&git.CLI{Config: opts.gitConfig, Log: logger})
+		client.SetRetryPolicy(retryPolicy(cfg, os.Stderr))
+		client.SetSwitch(cfg.CheckoutSwitch)
+		os.Exit(runServe(context.Background(), client, cfg, store, stdin, os.Stdout, os.Stderr))
 	}
 
-	uiBranches := make([]ui.Branch, 0, len(branches)+1)
-	uiBranches = append(uiBranches, ui.Branch{Name: current, Current: true})
-	for _, branch := range branches {
-		uiBranches = append(uiBranches, ui.Branch{Name: branch})
+	if opts.script == "" {
+		opts.script = strings.TrimSpace(os.Getenv(scriptEnv))
+	}
+	input, err := selectorInput(opts, stdin)
+	if err != nil {
+		fail(2, err)
+	}
+	if opts.replay != "" {
+		input = replayed.Keys(time.Sleep)
 	}
 
-	theme, err := resolveTheme(opts.theme)
+	// Without a branch named up front, fail before any work when nobody
+	// can answer the selector, unless a layout was asked for explicitly.
+	if opts.stdin {
+		opts.pick, err = readStdinBranch(stdin)
+		if err != nil {
+			fail(2, err)
+		}
+	}
+	if opts.pick == "" && selectorLayout(opts) == ui.LayoutAuto {
+		stdinTerminal := term.IsTerminal(int(os.Stdin.Fd()))
+		if reason := nonInteractiveReason(os.Getenv, stdinTerminal, term.IsTerminal(int(os.Stdout.Fd()))); reason != "" {
+			fail(exitNonInteractive, errNonInteractive(reason))
+		}
+		// Piped stdin answers the list shown on the terminal. The selector
+		// only sees the buffered reader, so it cannot tell by itself.
+		if !stdinTerminal {
+			opts.plain = true
+		}
+	}
+
+	style, err := newSelectorStyle(opts.theme, cfg, detectBackground, ui.DetectColorDepth(os.Getenv))
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
+	}
+	followUps, err := app.Expand(opts.then, cfg.Pipelines)
+	if err != nil {
+		fail(2, err)
+	}
+	if err := checkCustomActions(cfg.Actions); err != nil {
+		fail(2, err)
+	}
+	if opts.customAction != "" {
+		if _, ok := cfg.Actions[opts.customAction]; !ok {
+			fail(2, fmt.Errorf("unknown action %q; define it in an [actions.%s] table of the configuration", opts.customAction, opts.customAction))
+		}
+		opts.action = action(opts.customAction)
+	}
+	if opts.noHeader {
+		style.header = ui.HeaderNone
 	}
+	style.height = opts.height
 
-	terminal := ui.NewWithTheme(os.Stdin, os.Stdout, actionDetailsFor(opts.action), theme)
-	result, err := terminal.Select(uiBranches)
+	ctx := context.Background()
+	// newClient returns a client running git in dir, or in the current
+	// directory when dir is "".
+	newClient := func(dir string) *git.Client {
+		runner := &git.CLI{Dir: dir, Config: opts.gitConfig, Log: logger}
+		if recorder != nil {
+			runner.Observe = recorder.Git
+		}
+		client := git.NewClient(runner)
+		client.SetRetryPolicy(retryPolicy(cfg, os.Stderr))
+		client.SetCredentialPrompts(term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd())))
+		client.SetSwitch(cfg.CheckoutSwitch)
+		return client
+	}
+	client := newClient("")
+	// submoduleDir is the submodule chosen with --submodule; the action
+	// runs inside it.
+	submoduleDir := ""
+	if opts.submodule {
+		screen := os.Stdout
+		if opts.print {
+			screen = os.Stderr
+		}
+		dir, ok, err := pickSubmodule(ctx, client, style, selectorLayout(opts), stdin, screen)
+		if err != nil {
+			fail(1, err)
+		}
+		if !ok {
+			results.finish(outcomeQuit, 0, nil)
+			return
+		}
+		submoduleDir = dir
+		client = newClient(dir)
+	}
+	// With --menu the action is not known yet; it is checked once chosen.
+	operation, err := checkOperation(ctx, client, opts.action, opts.forceState || opts.menu)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fail(1, err)
+	}
+	if opts.action == actionRemoteAdmin {
+		if err := runRemoteAdmin(ctx, client, style, selectorLayout(opts), stdin, os.Stdout, os.Stderr); err != nil {
+			fail(1, err)
+		}
+		return
+	}
+	if opts.action == actionHeadHistory {
+		// -n caps the entries like it caps branches; --all reads up to
+		// --max-reflog of them.
+		entries := opts.limit
+		if entries == 0 {
+			entries = opts.maxReflog
+		}
+		if err := runHeadHistory(ctx, client, style, selectorLayout(opts), displayTimeFormat(cfg, timefmt.Relative), entries, stdin, os.Stdout); err != nil {
+			fail(1, err)
+		}
+		return
+	}
+
+	// The refs are watched from before the rows are read, so that a ref
+	// changing while they load still refreshes the list.
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	var changed <-chan struct{}
+	if opts.watch || cfg.UIAutoRefresh {
+		changed = watchRefs(watchCtx, client)
+	}
+	source := &rowSource{client: client, opts: opts, cfg: cfg, scorer: scorerFor(cfg), store: store}
+	var listed branchRows
+	err = style.progress(ctx, os.Stderr, "Reading branches", func(ctx context.Context) error {
+		var err error
+		listed, err = source.read(ctx)
+		return err
+	})
+	if err != nil {
+		fail(1, err)
+	}
+	rows := listed.rows
+
+	// The list opens before the slower details are read; they fill in as
+	// they arrive and stop being read once a branch is picked.
+	updates := source.enrich(ctx, listed)
+
+	from := ""
+	if rows.Len() > 0 && rows.Branch(0).Current {
+		from = rows.Branch(0).Name
+	}
+
+	states := openState()
+	repo, saved := loadRepoState(ctx, states, client, os.Stderr)
+
+	// With --print, stdout carries only the resulting command, so the
+	// selector is drawn on stderr.
+	screen := os.Stdout
+	if opts.print {
+		screen = os.Stderr
+	}
+	details := actionDetailsFor(opts.action)
+	// In a bare repository, checkout opens a worktree instead.
+	var repoInfo git.RepoInfo
+	custom, isCustom := cfg.Actions[string(opts.action)]
+	switch {
+	case opts.menu:
+		details = menuDetails
+	case isCustom:
+		details = customActionDetails(string(opts.action), custom)
+	case opts.action == actionCheckout:
+		repoInfo = bareRepoInfo(ctx, client)
+		if repoInfo.Bare() {
+			details = worktreeActionDetails
+		}
+	}
+	terminal := style.selector(input, screen, details)
+	if repo != "" {
+		terminal.SetRepository(filepath.Base(repo))
+	}
+	terminal.SetWarning(operationWarning(operation))
+	terminal.SetClipboard(clipboard.New(screen))
+	terminal.SetLogger(logger)
+	terminal.SetLayout(selectorLayout(opts))
+	terminal.SetMultiSelect(opts.multi)
+	terminal.SetExpander(branchExpander(ctx, client))
+	terminal.SetReloader(source.reloader(ctx))
+	// backupHints collects the restore hints of the branches e deletes,
+	// shown once the selector no longer owns the screen.
+	backupHints := &bytes.Buffer{}
+	setBranchEditors(ctx, terminal, client, cfg, opts.action, backupHints)
+	if recorder != nil {
+		terminal.SetRecorder(recorder)
+	}
+	if opts.replay != "" {
+		terminal.SetSize(replayed.Cols, replayed.Rows)
+	}
+	selector := selectorState(saved, from)
+	if opts.query != "" {
+		selector.Filter = opts.query
+	}
+	selector.Mode = matchMode(opts, cfg)
+	selector.Visibility = listed.visibility
+	selector.Load = listed.load
+	selector.Updates = updates
+	selector.Changed = changed
+	// remotes asks which remote an action takes when several could.
+	remotes := &remotePicker{style: style, layout: selectorLayout(opts), in: stdin, out: screen}
+	var result ui.Result
+	if opts.pick != "" {
+		result, err = pickedResult(ctx, client, remotes, opts.action, details, from, opts.pick, screen)
+	} else {
+		result, err = terminal.SelectFrom(rows, selector)
+	}
+	stopWatching()
+	source.stop()
+	backupHints.WriteTo(screen)
+	if opts.replay != "" && err == nil {
+		os.Exit(reportReplay(replayed, recorder.Session(), result, os.Stdout, os.Stderr))
+	}
+	if opts.record != "" {
+		// Saved before the action runs, and even when the selector failed,
+		// so that the session shows what led up to it.
+		if err := recorder.Save(opts.record); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	if err != nil {
+		fail(1, err)
+	}
+	if opts.pick == "" {
+		saveRepoState(states, repo, nextRepoState(saved, result, from), os.Stderr)
 	}
 
-	if result.Quit || result.AlreadyOn {
+	results.pick(opts.action, result, opts.multi)
+	if result.Quit {
+		results.finish(outcomeQuit, 0, nil)
+		return
+	}
+	if result.AlreadyOn {
+		return
+	}
+	if opts.multi {
+		code := runBatchAction(ctx, client, cfg, style, opts.action, result.Marked, os.Stdout, os.Stderr)
+		outcome := outcomeDone
+		if code != 0 {
+			outcome = outcomeFailed
+		}
+		results.finish(outcome, code, nil)
+		os.Exit(code)
+	}
+	if opts.menu {
+		selected := ui.Branch{Name: result.Branch, Remote: result.Remote, Current: !result.Remote && result.Branch == from}
+		act, ok, err := pickMenuAction(style, selectorLayout(opts), stdin, os.Stdout, selected, cfg.Actions)
+		if err != nil {
+			fail(1, err)
+		}
+		if !ok {
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+		if _, err := checkOperation(ctx, client, act, opts.forceState); err != nil {
+			fail(1, err)
+		}
+		opts.action = act
+		results.pick(act, result, false)
+		custom, isCustom = cfg.Actions[string(act)]
+		if act == actionCheckout {
+			repoInfo = bareRepoInfo(ctx, client)
+		}
+	}
+	if opts.print {
+		if repoInfo.Bare() {
+			local := localBranchName(result.Branch, result.Remote)
+			fmt.Fprintln(os.Stdout, "git worktree add "+shellQuote(worktreePath(repoInfo.Root, local))+" "+shellQuote(local))
+			return
+		}
+		fmt.Fprintln(os.Stdout, printCommand(submoduleDir, result.Branch, result.Remote))
 		return
 	}
 
+	// target is the branch recorded in the history for the action.
+	target := result.Branch
+	// stepsDir is where the --then steps run: the worktree opened for a
+	// bare repository, or the repository the action ran in.
+	stepsDir := submoduleDir
 	switch opts.action {
 	case actionCheckout:
-		message, err := client.CheckoutBranch(ctx, result.Branch)
+		if repoInfo.Bare() {
+			path, err := handleWorktreeCheckout(ctx, client, repoInfo, stdin, os.Stdout, result.Branch, result.Remote)
+			if err != nil {
+				fail(1, err)
+			}
+			if path == "" {
+				fmt.Fprintln(os.Stdout, "Worktree creation cancelled.")
+				results.finish(outcomeCancelled, 0, nil)
+				return
+			}
+			stepsDir = path
+			results.openedWorktree(path)
+			break
+		}
+		proceed, err := prepareCheckout(ctx, client, stdin, os.Stdout, result.Branch)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			fail(1, err)
+		}
+		if !proceed {
+			fmt.Fprintln(os.Stdout, "Checkout cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+		message, ok, err := checkoutBranch(ctx, client, stdin, os.Stdout, result.Branch, result.Remote)
+		if err != nil {
+			fail(1, err)
+		}
+		if !ok {
+			fmt.Fprintln(os.Stdout, "Checkout cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
 		}
 		printIfNotEmpty(os.Stdout, message)
 	case actionMerge:
-		mergeResult, err := client.MergeBranch(ctx, result.Branch, git.MergeOptions{})
+		confirmed, err := confirmMergeInto(ctx, client, cfg, stdin, os.Stdout, result.Branch)
+		if err != nil {
+			fail(1, err)
+		}
+		if !confirmed {
+			fmt.Fprintln(os.Stdout, "Merge cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+		mergeResult, err := client.MergeBranch(ctx, result.Branch, mergeOptions(cfg, opts.merge))
 		printIfNotEmpty(os.Stdout, mergeResult.Stdout)
 		stderrOutput := strings.TrimSpace(mergeResult.Stderr)
 		if err != nil {
@@ -115,38 +614,223 @@ func main() {
 			} else {
 				fmt.Fprintln(os.Stderr, err)
 			}
+			results.finish(outcomeFailed, 1, err)
 			os.Exit(1)
 		}
 		if stderrOutput != "" {
 			fmt.Fprintln(os.Stderr, stderrOutput)
 		}
-	case actionDelete:
-		if err := handleDeleteAction(ctx, client, os.Stdin, os.Stdout, os.Stderr, result.Branch); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+	case actionMergeCheck:
+		prediction, err := client.PredictMerge(ctx, result.Branch)
+		if err != nil {
+			fail(1, err)
+		}
+		current, err := client.CurrentBranch(ctx)
+		if err != nil {
+			fail(1, err)
+		}
+		fmt.Fprint(os.Stdout, mergeSummary(result.Branch, strings.TrimSpace(current), prediction, nil))
+		if len(prediction.Conflicts) > 0 {
+			// The check ran; its exit code tells that the merge would
+			// conflict.
+			results.finish(outcomeDone, 1, nil)
 			os.Exit(1)
 		}
+	case actionDelete:
+		if err := handleDeleteAction(ctx, client, cfg, style, stdin, os.Stdout, os.Stderr, result.Branch); err != nil {
+			fail(1, err)
+		}
+	case actionArchive:
+		tag, err := client.ArchiveBranch(ctx, result.Branch)
+		if err != nil {
+			fail(1, err)
+		}
+		fmt.Fprintf(os.Stdout, "Archived branch '%s' as tag '%s'\n", result.Branch, tag)
+	case actionUnarchive:
+		if err := client.UnarchiveBranch(ctx, result.Branch); err != nil {
+			fail(1, err)
+		}
+		fmt.Fprintf(os.Stdout, "Restored branch '%s' from its archive tag\n", result.Branch)
+	case actionPush:
+		if err := handlePushAction(ctx, client, style, remotes, os.Stdout, os.Stderr, result.Branch, cfg.PushAutoSetupUpstream); err != nil {
+			fail(1, err)
+		}
+	case actionRebase:
+		if err := client.RebaseInteractive(ctx, result.Branch); err != nil {
+			fail(1, err)
+		}
+	case actionBrowse:
+		if err := handleBrowseAction(ctx, client, browser.New(), os.Stdout, result.Branch, result.Remote, cfg.BrowseURLTemplate); err != nil {
+			fail(1, err)
+		}
+	case actionCreate:
+		template, err := createTemplate(ctx, client, cfg)
+		if err != nil {
+			fail(1, err)
+		}
+		target, err = handleCreateAction(ctx, client, opts.action, stdin, os.Stdout, result.Branch, template)
+		if err != nil {
+			fail(1, err)
+		}
+		if target == "" {
+			fmt.Fprintln(os.Stdout, "Branch creation cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+	case actionCopy:
+		target, err = handleCreateAction(ctx, client, opts.action, stdin, os.Stdout, result.Branch, "")
+		if err != nil {
+			fail(1, err)
+		}
+		if target == "" {
+			fmt.Fprintln(os.Stdout, "Branch copy cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+	case actionReset:
+		reset, err := handleResetAction(ctx, client, style, selectorLayout(opts), stdin, os.Stdout, result.Branch)
+		if err != nil {
+			fail(1, err)
+		}
+		if !reset {
+			fmt.Fprintln(os.Stdout, "Reset cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+	case actionLog, actionDiff:
+		if err := handleOutputAction(ctx, client, opts.action, defaultOutputEnv(cfg.DiffTool), result.Branch); err != nil {
+			fail(1, err)
+		}
 	default:
-		fmt.Fprintf(os.Stderr, "%s action is not implemented yet\n", opts.action)
-		os.Exit(2)
+		if !isCustom {
+			fail(2, fmt.Errorf("%s action is not implemented yet", opts.action))
+		}
+		ran, err := runCustomAction(ctx, client, string(opts.action), custom, result.Branch, stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			fail(1, err)
+		}
+		if !ran {
+			fmt.Fprintln(os.Stdout, "Action cancelled.")
+			results.finish(outcomeCancelled, 0, nil)
+			return
+		}
+	}
+
+	results.made(target)
+	recordAction(ctx, store, client, os.Stderr, opts.action, from, target)
+	if len(followUps) > 0 {
+		if err := runFollowUps(ctx, newClient(stepsDir), followUps, stdin, os.Stdout, os.Stderr); err != nil {
+			fail(1, err)
+		}
+	}
+	if logger != nil {
+		logger.Info("done", "action", opts.action, "branch", target, "duration", time.Since(started))
+	}
+}
+
+// newRootFlagSet defines the top-level flags, taking their help text from rootCommand.
+func newRootFlagSet(opts *cliOptions, flags *actionFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(programName, flag.ContinueOnError)
+	usage := rootCommand.FlagUsage
+	flags.define(fs, usage)
+	fs.IntVar(&opts.limit, "n", opts.limit, usage("n"))
+	fs.IntVar(&opts.limit, "limit", opts.limit, usage("limit"))
+	fs.BoolVar(&opts.all, "all", false, usage("all"))
+	fs.IntVar(&opts.maxReflog, "max-reflog", opts.maxReflog, usage("max-reflog"))
+	fs.BoolVar(&opts.regex, "regex", false, usage("regex"))
+	fs.BoolVar(&opts.plain, "plain", false, usage("plain"))
+	fs.BoolVar(&opts.interactive, "interactive", false, usage("interactive"))
+	fs.BoolVar(&opts.print, "print", false, usage("print"))
+	fs.StringVar(&opts.theme, "theme", "", usage("theme"))
+	fs.BoolVar(&opts.noHeader, "no-header", false, usage("no-header"))
+	fs.BoolVar(&opts.watch, "watch", false, usage("watch"))
+	fs.StringVar(&opts.resultFile, "result-file", "", usage("result-file"))
+	fs.IntVar(&opts.resultFD, "result-fd", 0, usage("result-fd"))
+	fs.BoolVar(&opts.serve, "serve", false, usage("serve"))
+	fs.BoolVar(&opts.submodule, "submodule", false, usage("submodule"))
+	fs.BoolVar(&opts.forceState, "force-state", false, usage("force-state"))
+	fs.BoolVar(&opts.menu, "menu", false, usage("menu"))
+	fs.BoolVar(&opts.multi, "multi", false, usage("multi"))
+	fs.Var(&opts.merge.gpgSign, "S", usage("S"))
+	fs.Var(&opts.merge.gpgSign, "gpg-sign", usage("gpg-sign"))
+	fs.BoolFunc("signoff", usage("signoff"), opts.merge.setSignoff)
+	fs.StringVar(&opts.customAction, "action", "", usage("action"))
+	fs.StringVar(&opts.pick, "pick", "", usage("pick"))
+	fs.BoolVar(&opts.stdin, "stdin", false, usage("stdin"))
+	fs.StringVar(&opts.script, "script", "", usage("script"))
+	fs.StringVar(&opts.record, "record", "", usage("record"))
+	fs.StringVar(&opts.replay, "replay", "", usage("replay"))
+	fs.Func("then", usage("then"), func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("--then needs a step")
+		}
+		opts.then = append(opts.then, value)
+		return nil
+	})
+	fs.Func("height", usage("height"), func(value string) error {
+		height, err := ui.ParseHeight(value)
+		if err != nil {
+			return err
+		}
+		opts.height = height
+		return nil
+	})
+	fs.Func("git-config", usage("git-config"), gitConfigFlag(&opts.gitConfig))
+	fs.Func("author", usage("author"), func(value string) error {
+		filter, err := match.NewFilter(value, match.ModeRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --author pattern %q: %w", value, err)
+		}
+		opts.filter.author = filter
+		return nil
+	})
+	fs.Func("since", usage("since"), func(value string) error {
+		since, err := timefmt.ParseDate(value, time.Now())
+		if err != nil {
+			return err
+		}
+		opts.filter.since = since
+		return nil
+	})
+	fs.Func("before", usage("before"), func(value string) error {
+		before, err := timefmt.ParseDate(value, time.Now())
+		if err != nil {
+			return err
+		}
+		opts.filter.before = before
+		return nil
+	})
+	fs.StringVar(&opts.filter.contains, "contains", "", usage("contains"))
+	fs.BoolVar(&opts.filter.noMerged, "no-merged", false, usage("no-merged"))
+	return fs
+}
+
+// gitConfigFlag returns the parser of a repeatable --git-config flag, which
+// appends each key=value setting to dst.
+func gitConfigFlag(dst *[]string) func(string) error {
+	return func(value string) error {
+		key, _, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid git config %q: expected key=value", value)
+		}
+		if !strings.Contains(strings.Trim(key, "."), ".") {
+			return fmt.Errorf("invalid git config %q: key must look like section.name", value)
+		}
+		*dst = append(*dst, value)
+		return nil
 	}
 }
 
 func parseArgs(args []string, usageOut, errorOut io.Writer) (cliOptions, error) {
-	fs := flag.NewFlagSet("branch-navigator", flag.ContinueOnError)
+	opts := cliOptions{limit: 10, maxReflog: navigator.DefaultMaxReflog}
+	var flags actionFlags
+	fs := newRootFlagSet(&opts, &flags)
 	fs.SetOutput(errorOut)
-
 	fs.Usage = func() {
-		fmt.Fprint(usageOut, usageText)
+		fmt.Fprint(usageOut, rootCommand.Usage(""))
 	}
 
-	opts := cliOptions{limit: 10}
-	checkout := fs.Bool("c", false, "checkout the selected branch (default)")
-	merge := fs.Bool("m", false, "merge the selected branch into the current branch")
-	deleteBranch := fs.Bool("d", false, "delete the selected local branch")
-	fs.IntVar(&opts.limit, "n", 10, "maximum number of branches to list")
-	fs.IntVar(&opts.limit, "limit", 10, "maximum number of branches to list")
-	fs.StringVar(&opts.theme, "theme", "", "color theme (catppuccin, nord, classic, solarized, gruvbox, onedark)")
-
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return cliOptions{}, flag.ErrHelp
@@ -154,139 +838,465 @@ func parseArgs(args []string, usageOut, errorOut io.Writer) (cliOptions, error)
 		return cliOptions{}, err
 	}
 
-	act, err := resolveAction(*checkout, *merge, *deleteBranch)
+	act, err := resolveAction(flags)
 	if err != nil {
 		return cliOptions{}, err
 	}
+	if opts.replay != "" && (fs.NFlag() > 1 || fs.NArg() > 0) {
+		return cliOptions{}, fmt.Errorf("--replay runs with the options of the recording and cannot be combined with others")
+	}
+	if opts.serve {
+		others := fs.NArg()
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name != "serve" && f.Name != "git-config" {
+				others++
+			}
+		})
+		if others > 0 {
+			return cliOptions{}, fmt.Errorf("--serve takes its requests on stdin and can only be combined with --git-config")
+		}
+	}
 
-	if opts.limit <= 0 {
-		return cliOptions{}, fmt.Errorf("limit must be greater than 0")
+	if opts.all {
+		opts.limit = 0
+	}
+	if opts.resultFile != "" && opts.resultFD != 0 {
+		return cliOptions{}, fmt.Errorf("--result-file and --result-fd cannot be combined")
+	}
+	if opts.resultFD != 0 && opts.resultFD < 3 {
+		return cliOptions{}, fmt.Errorf("--result-fd must be 3 or higher; 0, 1, and 2 carry the terminal")
+	}
+	if opts.plain && opts.interactive {
+		return cliOptions{}, fmt.Errorf("--plain and --interactive cannot be combined")
+	}
+	if opts.limit < 0 {
+		return cliOptions{}, fmt.Errorf("limit must not be negative")
+	}
+	if opts.maxReflog < 0 {
+		return cliOptions{}, fmt.Errorf("--max-reflog must not be negative")
+	}
+	opts.pick = strings.TrimSpace(opts.pick)
+	opts.script = strings.TrimSpace(opts.script)
+	if opts.pick != "" && opts.stdin {
+		return cliOptions{}, fmt.Errorf("--pick and --stdin cannot be combined")
+	}
+	if opts.record != "" && (opts.plain || opts.pick != "" || opts.stdin) {
+		return cliOptions{}, fmt.Errorf("--record needs the interactive selector and cannot be used with --plain, --pick, or --stdin")
+	}
+	if (opts.pick != "" || opts.stdin) && (opts.menu || act == actionRemoteAdmin || act == actionHeadHistory) {
+		return cliOptions{}, fmt.Errorf("--pick and --stdin cannot be used with --menu, --remote-admin, or --head-history")
+	}
+	if opts.customAction != "" && (flags.explicit() || opts.menu) {
+		return cliOptions{}, fmt.Errorf("--action cannot be combined with another action flag or --menu")
+	}
+	if opts.print && (act != actionCheckout || opts.customAction != "") {
+		return cliOptions{}, fmt.Errorf("--print can only be used with checkout")
+	}
+	if len(opts.then) > 0 && (opts.print || act == actionRemoteAdmin || act == actionHeadHistory) {
+		return cliOptions{}, fmt.Errorf("--then cannot be used with --print, --remote-admin, or --head-history")
+	}
+	if opts.menu && (flags.explicit() || opts.print) {
+		return cliOptions{}, fmt.Errorf("--menu chooses the action itself and cannot be combined with an action flag or --print")
+	}
+	if opts.multi && !acceptsMultiSelect(act) {
+		return cliOptions{}, fmt.Errorf("--multi can only be used with --merge-check, --push, or --archive")
+	}
+	if opts.multi && (opts.menu || opts.pick != "" || opts.stdin || len(opts.then) > 0) {
+		return cliOptions{}, fmt.Errorf("--multi cannot be combined with --menu, --pick, --stdin, or --then")
+	}
+	if opts.merge.set() && act != actionMerge && !opts.menu {
+		return cliOptions{}, fmt.Errorf("-S, --gpg-sign, and --signoff can only be used with -m or --menu")
+	}
+	if opts.filter.active() && (act == actionUnarchive || act == actionRemoteAdmin || act == actionHeadHistory) {
+		return cliOptions{}, fmt.Errorf("--author, --since, --before, --contains, and --no-merged cannot be used with --unarchive, --remote-admin, or --head-history")
+	}
+	if !opts.filter.since.IsZero() && !opts.filter.before.IsZero() && !opts.filter.since.Before(opts.filter.before) {
+		return cliOptions{}, fmt.Errorf("--since must be earlier than --before")
 	}
 
 	opts.action = act
+	opts.now = time.Now()
 	return opts, nil
 }
 
-func actionDetailsFor(act action) ui.ActionDetails {
-	switch act {
-	case actionCheckout:
-		return ui.ActionDetails{
-			Name:        "Checkout branch",
-			Description: "Switch to the selected branch.",
-			EnterLabel:  "checkout the selected branch",
+// setBranchEditors enables the e and l keys, which delete empty branches and
+// label the highlighted one. The unarchive list shows archive tags rather
+// than local branches, so it offers neither.
+func setBranchEditors(ctx context.Context, terminal *ui.UI, client *git.Client, cfg config.Config, act action, hints io.Writer) {
+	if act == actionUnarchive {
+		return
+	}
+	terminal.SetCleanup("delete empty branches", emptyCleanup(ctx, client, cfg, hints))
+	terminal.SetLabeler(branchLabeler(ctx, client))
+}
+
+// loadBranches returns the UI candidates for the selected action: archived
+// branches for unarchive, otherwise the current branch followed by recent ones.
+func loadBranches(ctx context.Context, client *git.Client, nav *navigator.Navigator, opts cliOptions, scorer navigator.Scorer, store *history.Store) ([]ui.Branch, error) {
+	if opts.action == actionUnarchive {
+		archived, err := client.ArchivedBranches(ctx)
+		if err != nil {
+			return nil, err
 		}
-	case actionMerge:
-		return ui.ActionDetails{
-			Name:        "Merge branch",
-			Description: "Merge the selected branch into the current branch.",
-			EnterLabel:  "merge the selected branch into the current branch",
+		if opts.limit > 0 && len(archived) > opts.limit {
+			archived = archived[:opts.limit]
 		}
-	case actionDelete:
-		return ui.ActionDetails{
-			Name:        "Delete branch",
-			Description: "Delete the selected local branch.",
-			EnterLabel:  "delete the selected branch",
+		uiBranches := make([]ui.Branch, 0, len(archived))
+		for _, branch := range archived {
+			uiBranches = append(uiBranches, ui.Branch{Name: branch})
 		}
-	default:
-		return ui.ActionDetails{}
+		return uiBranches, nil
 	}
-}
 
-func resolveAction(checkout, merge, deleteBranch bool) (action, error) {
-	selected := []action{}
-	if checkout {
-		selected = append(selected, actionCheckout)
+	var visits map[string]navigator.Visit
+	if scorer.UsesHistory() {
+		visits = branchVisits(ctx, store, client, os.Stderr)
 	}
-	if merge {
-		selected = append(selected, actionMerge)
+	branches, err := nav.RankedBranches(ctx, opts.limit, scorer, visits, opts.now)
+	if err != nil {
+		return nil, err
 	}
-	if deleteBranch {
-		selected = append(selected, actionDelete)
+
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	switch len(selected) {
-	case 0:
-		return actionCheckout, nil
-	case 1:
-		return selected[0], nil
-	default:
-		return "", errors.New("only one of -c, -m, or -d may be specified")
+	uiBranches := make([]ui.Branch, 0, len(branches)+1)
+	uiBranches = append(uiBranches, ui.Branch{Name: current, Current: true})
+	for _, branch := range branches {
+		uiBranches = append(uiBranches, ui.Branch{Name: branch})
+	}
+	return uiBranches, nil
+}
+
+// streamBranches returns the current branch and a loader for the rest of an
+// unlimited list: recent local branches page by page, then the remote-tracking
+// branches offered for the action.
+func streamBranches(ctx context.Context, client *git.Client, nav *navigator.Navigator, annotator *branchAnnotator) (ui.Provider, ui.Loader, error) {
+	stream, err := nav.Stream(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	first := annotator.keep(annotator.pin([]ui.Branch{{Name: current, Current: true}}))
+	annotator.classify(first)
+	remotesLoaded := false
+	load := func(n int) (ui.Provider, error) {
+		// A page that the branch filter empties is skipped, since an empty
+		// page would end the list.
+		for {
+			names, err := stream.Next(ctx, n)
+			if err != nil {
+				return nil, err
+			}
+			if len(names) == 0 {
+				if remotesLoaded {
+					return nil, nil
+				}
+				remotesLoaded = true
+				return annotator.remoteRows(0), nil
+			}
+			rows := make([]ui.Branch, 0, len(names))
+			for _, name := range names {
+				// The pinned default branch is already on the first page.
+				if annotator.pinned && name == annotator.defaultBranch {
+					continue
+				}
+				rows = append(rows, ui.Branch{Name: name})
+			}
+			if rows = annotator.keep(rows); len(rows) > 0 {
+				annotator.classify(rows)
+				return annotator.rows(rows), nil
+			}
+		}
 	}
+	return annotator.rows(first), load, nil
 }
 
-func resolveTheme(flagValue string) (ui.Theme, error) {
+// backgroundQueryTimeout bounds how long the terminal has to answer the
+// background color query.
+const backgroundQueryTimeout = 150 * time.Millisecond
+
+// detectBackground asks the controlling terminal for its background.
+func detectBackground() ui.Background {
+	return ui.DetectBackground(os.Getenv, backgroundQueryTimeout)
+}
+
+// resolveTheme picks the theme named by --theme, BRANCH_NAVIGATOR_THEME, or
+// the theme setting of the configuration file, Catppuccin by default. Family
+// names take the light or dark variant that suits the terminal; detect is
+// only called for them. On 16-color terminals the theme is mapped to the
+// basic ANSI colors.
+func resolveTheme(flagValue, configured string, detect func() ui.Background, depth ui.ColorDepth) (ui.Theme, error) {
 	name := strings.TrimSpace(flagValue)
 	if name == "" {
 		name = strings.TrimSpace(os.Getenv("BRANCH_NAVIGATOR_THEME"))
 	}
 	if name == "" {
-		return ui.DefaultTheme, nil
+		name = configured
 	}
 
-	theme, ok := ui.ThemeByName(name)
+	family, ok := ui.ThemeFamilyByName(name)
 	if !ok {
-		return ui.Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ui.AvailableThemeNames(), ", "))
+		return ui.Theme{}, fmt.Errorf("unknown theme %q (available: %s; suffixes: -dark, -light, -16, 16, -dark-16, -dark16, -light-16, -light16)", name, strings.Join(ui.AvailableThemeNames(), ", "))
+	}
+	theme := family.Dark
+	if family.Adaptive() {
+		theme = family.For(detect())
+	}
+	if depth == ui.Colors16 {
+		theme = theme.Basic()
 	}
 	return theme, nil
 }
 
+// selectorStyle holds the appearance settings shared by every selector.
+type selectorStyle struct {
+	theme  ui.Theme
+	border bool
+	marker ui.Marker
+	header ui.Header
+	// density and separators control the lines between parts of the frame.
+	density    ui.Density
+	separators bool
+	// height draws selectors inline below the prompt instead of full screen.
+	height ui.Height
+}
+
+// newSelectorStyle resolves the theme like resolveTheme and reads the border,
+// cursor marker, header, and density settings from cfg. On 16-color
+// terminals the marker color is mapped to the basic ANSI colors as well.
+func newSelectorStyle(themeFlag string, cfg config.Config, detect func() ui.Background, depth ui.ColorDepth) (selectorStyle, error) {
+	theme, err := resolveTheme(themeFlag, cfg.Theme, detect, depth)
+	if err != nil {
+		return selectorStyle{}, err
+	}
+	color, err := ui.ParseColor(cfg.UIMarkerColor)
+	if err != nil {
+		return selectorStyle{}, fmt.Errorf("ui.marker_color: %w", err)
+	}
+	marker := ui.Marker{Symbol: cfg.UIMarker, Color: color}
+	if depth == ui.Colors16 {
+		marker = marker.Basic()
+	}
+	header, err := ui.ParseHeader(cfg.UIHeader)
+	if err != nil {
+		return selectorStyle{}, fmt.Errorf("ui.header: %w", err)
+	}
+	density, err := ui.ParseDensity(cfg.UIDensity)
+	if err != nil {
+		return selectorStyle{}, fmt.Errorf("ui.density: %w", err)
+	}
+	return selectorStyle{
+		theme:      theme,
+		border:     cfg.UIBorder,
+		marker:     marker,
+		header:     header,
+		density:    density,
+		separators: cfg.UISeparators,
+	}, nil
+}
+
+// selector returns a selector drawn in s.
+func (s selectorStyle) selector(in io.Reader, out io.Writer, action ui.ActionDetails) *ui.UI {
+	selector := ui.NewWithTheme(in, out, action, s.theme)
+	selector.SetBorder(s.border)
+	selector.SetMarker(s.marker)
+	selector.SetHeader(s.header)
+	selector.SetDensity(s.density, s.separators)
+	selector.SetHeight(s.height)
+	return selector
+}
+
+// retryPolicy builds the retry policy for network commands from cfg. Each
+// retry is announced on errOut.
+func retryPolicy(cfg config.Config, errOut io.Writer) git.RetryPolicy {
+	prefix := ""
+	if file, ok := errOut.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		prefix = ui.EraseLine
+	}
+	return git.RetryPolicy{
+		Retries: cfg.NetworkRetries,
+		Delay:   time.Duration(cfg.NetworkRetryDelaySeconds * float64(time.Second)),
+		Notify: func(attempt git.RetryAttempt) {
+			fmt.Fprintf(errOut, "%swarning: git %s failed: %s; retrying in %s (attempt %d of %d)\n",
+				prefix, strings.Join(attempt.Args, " "), attempt.Reason, attempt.Wait, attempt.Attempt, attempt.Attempts)
+		},
+	}
+}
+
+// progress runs task, animating label on errOut while it takes a while.
+// Only terminals get the animation. During the task Ctrl+C cancels its
+// context instead of killing the process, so the spinner line is cleaned up
+// and git is stopped through the context.
+func (s selectorStyle) progress(ctx context.Context, errOut io.Writer, label string, task func(context.Context) error) error {
+	file, ok := errOut.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return task(ctx)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	return ui.NewSpinner(errOut, s.theme).Run(ctx, label, task)
+}
+
+// networkProgress is progress for commands that contact a remote. When git
+// runs attached to the terminal so that it can ask for credentials, it shows
+// its own progress and the spinner stays off rather than overdraw a prompt.
+func networkProgress(ctx context.Context, client *git.Client, style selectorStyle, errOut io.Writer, label string, task func(context.Context) error) error {
+	if client.CredentialPrompts() {
+		return task(ctx)
+	}
+	return style.progress(ctx, errOut, label, task)
+}
+
 func printIfNotEmpty(w io.Writer, message string) {
 	if trimmed := strings.TrimSpace(message); trimmed != "" {
 		fmt.Fprintln(w, trimmed)
 	}
 }
 
-func handleDeleteAction(ctx context.Context, client *git.Client, in io.Reader, out, errOut io.Writer, branch string) error {
+// displayTimeFormat returns the time.format setting, or fallback when it is
+// not set. The setting is validated when the configuration is loaded.
+func displayTimeFormat(cfg config.Config, fallback timefmt.Format) timefmt.Format {
+	if cfg.TimeFormat == "" {
+		return fallback
+	}
+	format, err := timefmt.Parse(cfg.TimeFormat)
+	if err != nil {
+		return fallback
+	}
+	return format
+}
+
+// matchMode resolves how the selector interprets filter queries; --regex or
+// search.regex switches from fuzzy matching to regular expressions.
+func matchMode(opts cliOptions, cfg config.Config) match.Mode {
+	if opts.regex || cfg.SearchRegex {
+		return match.ModeRegex
+	}
+	return match.ModeFuzzy
+}
+
+// selectorLayout maps --plain and --interactive onto a selector layout,
+// leaving terminal detection to internal/ui when neither is given. A script
+// or a replay always drives the interactive selector.
+func selectorLayout(opts cliOptions) ui.Layout {
+	switch {
+	case opts.plain:
+		return ui.LayoutPlain
+	case opts.interactive, opts.script != "", opts.replay != "":
+		return ui.LayoutInteractive
+	default:
+		return ui.LayoutAuto
+	}
+}
+
+// backupRetention converts the configured retention into a duration; zero disables expiry.
+func backupRetention(cfg config.Config) time.Duration {
+	return time.Duration(cfg.BackupRetentionDays) * 24 * time.Hour
+}
+
+// handlePushAction pushes branch to its upstream remote. Branches without an
+// upstream go to the remote chooseRemote returns, with --set-upstream when
+// autoSetupUpstream is enabled.
+func handlePushAction(ctx context.Context, client *git.Client, style selectorStyle, picker *remotePicker, out, errOut io.Writer, branch string, autoSetupUpstream bool) error {
 	if client == nil {
 		return fmt.Errorf("git client is not configured")
 	}
 
-	result, err := client.DeleteBranch(ctx, branch, git.DeleteOptions{})
-	if err == nil {
-		printIfNotEmpty(out, result.Stdout)
-		printIfNotEmpty(errOut, result.Stderr)
-		return nil
+	upstream, err := client.BranchUpstream(ctx, branch)
+	if err != nil {
+		return err
 	}
 
-	if errors.Is(err, git.ErrBranchNotFullyMerged) {
-		printIfNotEmpty(errOut, result.Stderr)
-		confirmed, confirmErr := confirmBranchDeletion(in, out, branch)
-		if confirmErr != nil {
-			return confirmErr
+	opts := git.PushOptions{Remote: upstream.Remote}
+	if upstream.Remote == "" {
+		remotes, err := client.Remotes(ctx)
+		if err != nil {
+			return err
 		}
-		if !confirmed {
-			return fmt.Errorf("branch deletion aborted")
+		opts.Remote, err = chooseRemote(ctx, client, picker, "Push "+branch+" to", remotes)
+		if err != nil {
+			return err
 		}
-		forcedResult, forceErr := client.DeleteBranch(ctx, branch, git.DeleteOptions{Force: true})
-		if forceErr != nil {
-			printIfNotEmpty(errOut, forcedResult.Stderr)
-			return forceErr
+		if opts.Remote == "" {
+			return fmt.Errorf("push aborted: no remote chosen; set %s to push without asking", defaultRemoteKey)
 		}
-		printIfNotEmpty(out, forcedResult.Stdout)
-		printIfNotEmpty(errOut, forcedResult.Stderr)
-		return nil
+		opts.SetUpstream = autoSetupUpstream
 	}
 
+	var result git.PushResult
+	err = networkProgress(ctx, client, style, errOut, "Pushing "+branch, func(ctx context.Context) error {
+		var err error
+		result, err = client.PushBranch(ctx, branch, opts)
+		return err
+	})
+	printIfNotEmpty(out, result.Stdout)
 	printIfNotEmpty(errOut, result.Stderr)
-	return err
-}
+	if err != nil {
+		return err
+	}
 
-func confirmBranchDeletion(in io.Reader, out io.Writer, branch string) (bool, error) {
-	if _, err := fmt.Fprintf(out, "Branch '%s' is not fully merged. Delete anyway? [y/N]: ", branch); err != nil {
-		return false, err
+	switch {
+	case opts.SetUpstream:
+		fmt.Fprintf(out, "Branch '%s' now tracks '%s/%s'\n", branch, opts.Remote, branch)
+	case upstream.Remote == "":
+		fmt.Fprintf(errOut, "note: '%s' has no upstream; set push.auto_setup_upstream = true to track the pushed branch automatically\n", branch)
 	}
+	return nil
+}
 
-	reader := bufio.NewReader(in)
-	line, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
+func printBackupHint(out io.Writer, backup git.Backup) {
+	fmt.Fprintf(out, "Backup of %s saved as %s (restore with: git branch %s %s)\n", backup.Branch, backup.Ref, backup.Branch, backup.Ref)
+}
+
+// maxListedConflicts caps the conflicting paths shown before a merge.
+const maxListedConflicts = 10
+
+// confirmMerge shows which branch is merged into the current one, together
+// with the conflicts a trial merge predicts, and asks for y before the merge
+// runs.
+func confirmMerge(ctx context.Context, client *git.Client, in io.Reader, out io.Writer, source string) (bool, error) {
+	target, err := client.CurrentBranch(ctx)
+	if err != nil {
 		return false, err
 	}
+	prediction, err := client.PredictMerge(ctx, source)
+	return ui.Confirmation{
+		Summary: mergeSummary(source, strings.TrimSpace(target), prediction, err),
+	}.Confirm(in, out)
+}
 
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return false, nil
+// mergeSummary describes a pending merge. A failed prediction is reported
+// but does not block the merge.
+func mergeSummary(source, target string, prediction git.MergePrediction, predictErr error) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Merge %s → %s\n", source, target)
+	switch {
+	case errors.Is(predictErr, git.ErrMergePredictionUnsupported):
+		fmt.Fprintf(&b, "Conflicts were not checked: %v.\n", predictErr)
+	case predictErr != nil:
+		fmt.Fprintf(&b, "Conflicts could not be checked: %v\n", predictErr)
+	case len(prediction.Conflicts) == 0:
+		b.WriteString("This merge applies cleanly.\n")
+	default:
+		noun := "files"
+		if len(prediction.Conflicts) == 1 {
+			noun = "file"
+		}
+		fmt.Fprintf(&b, "This merge will conflict in %d %s:\n", len(prediction.Conflicts), noun)
+		for i, path := range prediction.Conflicts {
+			if i == maxListedConflicts {
+				fmt.Fprintf(&b, "  … and %d more\n", len(prediction.Conflicts)-maxListedConflicts)
+				break
+			}
+			fmt.Fprintf(&b, "  %s\n", path)
+		}
 	}
-
-	answer := strings.ToLower(line)
-	return answer == "y" || answer == "yes", nil
+	return b.String()
 }
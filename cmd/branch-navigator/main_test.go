@@ -2,14 +2,159 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/match"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/timefmt"
 	"branch-navigator/internal/ui"
+	"branch-navigator/pkg/gittest"
 )
 
+// The test binary doubles as the command: started with runMainEnv set, it
+// runs main instead of the tests, so that the wiring of main is tested as
+// the command runs.
+const runMainEnv = "BRANCH_NAVIGATOR_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the command with args in repo, with stdin, stdout, and
+// stderr piped, and returns its exit code and output. HOME and the XDG
+// directories point into a fresh temporary directory, and CI is unset.
+func runMain(t *testing.T, repo *gittest.Repo, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	home := t.TempDir()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = repo.Dir
+	cmd.Env = append(repo.Env(),
+		runMainEnv+"=1",
+		"CI=",
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, "config"),
+		"XDG_STATE_HOME="+filepath.Join(home, "state"),
+		"TERM=dumb",
+		"GIT_PAGER=cat",
+		"BROWSER=true",
+	)
+	cmd.Stdin = strings.NewReader(stdin)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("run: %v", err)
+	}
+	return cmd.ProcessState.ExitCode(), stdout.String(), stderr.String()
+}
+
+func TestMainCommands(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args  []string
+		stdin string
+		// setup prepares the repository beyond feature/x, which has a
+		// commit of its own, and the empty branch spike.
+		setup      func(repo *gittest.Repo)
+		wantCode   int
+		wantOut    string
+		wantErr    string
+		wantBranch string
+	}{
+		"help":             {args: []string{"--help"}, wantOut: "Usage: branch-navigator"},
+		"unknown flag":     {args: []string{"--bogus"}, wantCode: 2, wantErr: "flag provided but not defined: -bogus"},
+		"no terminal":      {wantCode: exitNonInteractive, wantErr: "cannot choose a branch interactively: stdin and stdout are not terminals"},
+		"unknown theme":    {args: []string{"--theme", "nope", "--pick", "feature/x"}, wantCode: 2, wantErr: `unknown theme "nope"`},
+		"unknown action":   {args: []string{"--action", "deploy", "--pick", "feature/x"}, wantCode: 2, wantErr: `unknown action "deploy"`},
+		"missing branch":   {args: []string{"--pick", "nope"}, wantCode: 1, wantErr: "no branch named 'nope'"},
+		"already on":       {args: []string{"--pick", gittest.DefaultBranch}, wantOut: "already on 'main'", wantBranch: gittest.DefaultBranch},
+		"checkout":         {args: []string{"--pick", "feature/x"}, wantBranch: "feature/x"},
+		"stdin":            {args: []string{"--stdin"}, stdin: "spike\n", wantBranch: "spike"},
+		"plain":            {args: []string{"--plain"}, stdin: "q\n", wantOut: "1) main", wantBranch: gittest.DefaultBranch},
+		"print":            {args: []string{"--print", "--pick", "feature/x"}, wantOut: "git switch feature/x\n", wantBranch: gittest.DefaultBranch},
+		"merge":            {args: []string{"-m", "--pick", "feature/x"}, stdin: "y\n", wantOut: "Merge feature/x → main"},
+		"merge declined":   {args: []string{"-m", "--pick", "feature/x"}, stdin: "n\n", wantOut: "Merge cancelled."},
+		"merge check":      {args: []string{"--merge-check", "--pick", "feature/x"}, wantOut: "Merge feature/x → main"},
+		"delete":           {args: []string{"-d", "--pick", "spike"}, stdin: "y\n", wantOut: "Deleted branch spike"},
+		"archive":          {args: []string{"--archive", "--pick", "spike"}, wantOut: "Archived branch 'spike' as tag 'archive/spike'"},
+		"create":           {args: []string{"--create", "--pick", "feature/x"}, stdin: "feature/y\n", wantBranch: "feature/y"},
+		"create cancelled": {args: []string{"--create", "--pick", "feature/x"}, stdin: "\n", wantOut: "Branch creation cancelled."},
+		"copy":             {args: []string{"--copy", "--pick", "feature/x"}, stdin: "feature/z\n", wantOut: "feature/z"},
+		"log":              {args: []string{"--log", "--pick", "feature/x"}, wantOut: "work on feature/x"},
+		"diff":             {args: []string{"--diff", "--pick", "feature/x"}, wantOut: "feature-x.txt"},
+		"head history":     {args: []string{"--head-history", "--plain"}, stdin: "q\n", wantOut: "1) HEAD@{0} (current branch)"},
+		"remote admin":     {args: []string{"--remote-admin", "--plain"}, wantCode: 1, wantErr: "no remotes are configured"},
+		"serve":            {args: []string{"--serve"}, stdin: `{"jsonrpc":"2.0","id":1,"method":"branches"}` + "\n", wantOut: `"name":"feature/x"`},
+		"unarchive": {
+			args:    []string{"--unarchive", "--pick", "old"},
+			setup:   func(repo *gittest.Repo) { repo.Git("tag", "archive/old") },
+			wantOut: "Restored branch 'old' from its archive tag",
+		},
+		"switch":  {args: []string{"switch", "feature/x"}, wantBranch: "feature/x"},
+		"co":      {args: []string{"co", "spi"}, wantBranch: "spike"},
+		"list":    {args: []string{"list"}, wantOut: "feature/x"},
+		"report":  {args: []string{"report"}, wantOut: "3 local branches (base: main)"},
+		"history": {args: []string{"history"}, wantOut: "No checkouts recorded yet"},
+		"stats":   {args: []string{"stats"}, wantOut: "No history recorded yet"},
+		"themes":  {args: []string{"themes"}, wantOut: "classic"},
+		"config":  {args: []string{"config", "get", "ui.border"}, wantOut: "false\n"},
+		"doctor":  {args: []string{"doctor"}, wantOut: "[warn] terminal: stdin and stdout are not terminals"},
+		"init":    {args: []string{"init", "zsh", "--widget"}, wantOut: "bindkey '^G'"},
+		"docs":    {args: []string{"docs", "help"}, wantOut: "Usage: branch-navigator"},
+		"repos":   {args: []string{"repos", "--bogus"}, wantCode: 2, wantErr: "flag provided but not defined"},
+		"sweep":   {args: []string{"sweep", "--root", "."}, stdin: "q\n", wantOut: ": spike (merged)"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			repo.CheckoutNew("feature/x")
+			repo.WriteFile("feature-x.txt", "x\n")
+			repo.Commit("work on feature/x")
+			repo.Checkout(gittest.DefaultBranch)
+			repo.Branch("spike", "")
+			if tc.setup != nil {
+				tc.setup(repo)
+			}
+
+			code, stdout, stderr := runMain(t, repo, tc.stdin, tc.args...)
+			if code != tc.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstdout:\n%s\nstderr:\n%s", code, tc.wantCode, stdout, stderr)
+			}
+			if !strings.Contains(stdout, tc.wantOut) {
+				t.Fatalf("stdout = %q, want it to contain %q (stderr %q)", stdout, tc.wantOut, stderr)
+			}
+			if !strings.Contains(stderr, tc.wantErr) {
+				t.Fatalf("stderr = %q, want it to contain %q", stderr, tc.wantErr)
+			}
+			if tc.wantBranch != "" {
+				if got := repo.Git("branch", "--show-current"); got != tc.wantBranch {
+					t.Fatalf("current branch = %q, want %q", got, tc.wantBranch)
+				}
+			}
+		})
+	}
+}
+
 func TestParseArgsDefaultActionCheckout(t *testing.T) {
 	t.Parallel()
 
@@ -49,11 +194,147 @@ func TestParseArgsRejectsMultipleActions(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error when multiple actions are specified")
 	}
-	if !strings.Contains(err.Error(), "only one of -c, -m, or -d may be specified") {
+	if !strings.Contains(err.Error(), "only one of -c, -m, --merge-check, -d, --archive, --unarchive, --rebase-i, --remote-admin, --head-history, --push, --log, --diff, --browse, --create, --copy, or --reset may be specified") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
+func TestParseArgsLongActions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args []string
+		want action
+	}{
+		"archive":   {args: []string{"--archive"}, want: actionArchive},
+		"unarchive": {args: []string{"--unarchive"}, want: actionUnarchive},
+		"rebase-i":  {args: []string{"--rebase-i"}, want: actionRebase},
+		"remotes":   {args: []string{"--remote-admin"}, want: actionRemoteAdmin},
+		"head":      {args: []string{"--head-history"}, want: actionHeadHistory},
+		"push":      {args: []string{"--push"}, want: actionPush},
+		"log":       {args: []string{"--log"}, want: actionLog},
+		"diff":      {args: []string{"--diff"}, want: actionDiff},
+		"browse":    {args: []string{"--browse"}, want: actionBrowse},
+		"create":    {args: []string{"--create"}, want: actionCreate},
+		"copy":      {args: []string{"--copy"}, want: actionCopy},
+		"reset":     {args: []string{"--reset"}, want: actionReset},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if opts.action != tc.want {
+				t.Fatalf("expected action %q, got %q", tc.want, opts.action)
+			}
+		})
+	}
+
+	usage := &bytes.Buffer{}
+	if _, err := parseArgs([]string{"--archive", "-d"}, usage, usage); err == nil {
+		t.Fatal("expected error when --archive is combined with -d")
+	}
+}
+
+func TestParseArgsMenu(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--menu"}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if !opts.menu || opts.action != actionCheckout {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	for _, args := range [][]string{{"--menu", "-m"}, {"--menu", "-c"}, {"--menu", "--print"}} {
+		if _, err := parseArgs(args, usage, usage); err == nil || !strings.Contains(err.Error(), "--menu chooses the action itself") {
+			t.Fatalf("parseArgs(%v) error = %v, want a --menu error", args, err)
+		}
+	}
+}
+
+func TestParseArgsAuthor(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--author", "ada@example"}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if author := opts.filter.author; author == nil || !author.Match("Ada Lovelace <ADA@example.com>") || author.Match("Grace Hopper <grace@example.com>") {
+		t.Fatalf("unexpected author filter %+v", author)
+	}
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"invalid pattern": {args: []string{"--author", "ada("}, wantErr: "invalid --author pattern"},
+		"unarchive":       {args: []string{"--author", "ada", "--unarchive"}, wantErr: "--author, --since, --before, --contains, and --no-merged cannot be used with --unarchive, --remote-admin, or --head-history"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			_, err := parseArgs(tc.args, usage, usage)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseArgsActivityDates(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--since", "2024-05-01", "--before", "2024-06-01"}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if got := opts.filter.since.Format("2006-01-02"); got != "2024-05-01" {
+		t.Fatalf("since = %s, want 2024-05-01", got)
+	}
+	if got := opts.filter.before.Format("2006-01-02"); got != "2024-06-01" {
+		t.Fatalf("before = %s, want 2024-06-01", got)
+	}
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"invalid date":    {args: []string{"--since", "soon"}, wantErr: `date "soon" is not an age`},
+		"empty window":    {args: []string{"--since", "1.week", "--before", "2.weeks"}, wantErr: "--since must be earlier than --before"},
+		"with remotes":    {args: []string{"--before", "3.months", "--remote-admin"}, wantErr: "cannot be used with --unarchive, --remote-admin, or --head-history"},
+		"with the author": {args: []string{"--author", "ada(", "--since", "2.weeks"}, wantErr: "invalid --author pattern"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			_, err := parseArgs(tc.args, usage, usage)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestParseArgsLimitAlias(t *testing.T) {
 	t.Parallel()
 
@@ -72,15 +353,171 @@ func TestParseArgsRejectsInvalidLimit(t *testing.T) {
 	t.Parallel()
 
 	usage := &bytes.Buffer{}
-	_, err := parseArgs([]string{"-n", "0"}, usage, usage)
+	_, err := parseArgs([]string{"-n", "-1"}, usage, usage)
 	if err == nil {
-		t.Fatal("expected error when limit is less than 1")
+		t.Fatal("expected error when limit is negative")
 	}
-	if !strings.Contains(err.Error(), "limit must be greater than 0") {
+	if !strings.Contains(err.Error(), "limit must not be negative") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
+func TestParseArgsNoHeader(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--no-header", "-m"}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if !opts.noHeader || opts.action != actionMerge {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+}
+
+func TestParseArgsHeight(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		want    ui.Height
+		wantErr string
+	}{
+		"full screen": {},
+		"lines":       {args: []string{"--height", "15"}, want: ui.Height{Lines: 15}},
+		"percent":     {args: []string{"--height=40%"}, want: ui.Height{Percent: 40}},
+		"invalid":     {args: []string{"--height", "0"}, wantErr: `invalid height "0"`},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if opts.height != tc.want {
+				t.Fatalf("height = %+v, want %+v", opts.height, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseArgsGitConfig(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		"none":        {},
+		"repeatable":  {args: []string{"--git-config", "merge.ff=false", "--git-config=advice.detachedHead=false"}, want: []string{"merge.ff=false", "advice.detachedHead=false"}},
+		"subsection":  {args: []string{"--git-config", "url.git@example.com:.insteadOf=https://example.com/"}, want: []string{"url.git@example.com:.insteadOf=https://example.com/"}},
+		"empty value": {args: []string{"--git-config", "core.pager="}, want: []string{"core.pager="}},
+		"no equals":   {args: []string{"--git-config", "merge.ff"}, wantErr: "expected key=value"},
+		"no section":  {args: []string{"--git-config", "ff=false"}, wantErr: "section.name"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if !reflect.DeepEqual(opts.gitConfig, tc.want) {
+				t.Fatalf("gitConfig = %q, want %q", opts.gitConfig, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseArgsMaxReflog(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		want    int
+		wantErr string
+	}{
+		"default":  {want: 300},
+		"bounded":  {args: []string{"--max-reflog", "50"}, want: 50},
+		"whole":    {args: []string{"--max-reflog", "0"}, want: 0},
+		"negative": {args: []string{"--max-reflog", "-1"}, wantErr: "--max-reflog must not be negative"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if opts.maxReflog != tc.want {
+				t.Fatalf("maxReflog = %d, want %d", opts.maxReflog, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseArgsUnlimited(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"zero":       {"-n", "0"},
+		"all":        {"--all"},
+		"all-wins":   {"-n", "5", "--all"},
+		"limit-zero": {"--limit", "0"},
+	}
+	for name, args := range cases {
+		name := name
+		args := args
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(args, usage, usage)
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if opts.limit != 0 {
+				t.Fatalf("expected no limit, got %d", opts.limit)
+			}
+		})
+	}
+}
+
 func TestParseArgsHelp(t *testing.T) {
 	t.Parallel()
 
@@ -113,6 +550,121 @@ func TestParseArgsTheme(t *testing.T) {
 	}
 }
 
+func TestSelectorLayout(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args []string
+		want ui.Layout
+	}{
+		"default":     {want: ui.LayoutAuto},
+		"plain":       {args: []string{"--plain"}, want: ui.LayoutPlain},
+		"interactive": {args: []string{"--interactive"}, want: ui.LayoutInteractive},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if got := selectorLayout(opts); got != tc.want {
+				t.Fatalf("selectorLayout() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseArgsRejectsConflictingLayouts(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	_, err := parseArgs([]string{"--plain", "--interactive"}, usage, usage)
+	if err == nil || !strings.Contains(err.Error(), "--plain and --interactive cannot be combined") {
+		t.Fatalf("expected layout conflict error, got %v", err)
+	}
+}
+
+func TestParseArgsPrintRequiresCheckout(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--print"}, usage, usage)
+	if err != nil || !opts.print {
+		t.Fatalf("parseArgs(--print) = %+v, %v", opts, err)
+	}
+	_, err = parseArgs([]string{"--print", "-m"}, usage, usage)
+	if err == nil || !strings.Contains(err.Error(), "--print can only be used with checkout") {
+		t.Fatalf("expected --print error with -m, got %v", err)
+	}
+}
+
+func TestDisplayTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	fallback := timefmt.Layout("2006-01-02 15:04")
+	cases := map[string]struct {
+		setting string
+		want    timefmt.Format
+	}{
+		"unset":    {want: fallback},
+		"relative": {setting: "relative", want: timefmt.Relative},
+		"short":    {setting: "short", want: timefmt.Short},
+		"layout":   {setting: "Jan 2", want: timefmt.Layout("Jan 2")},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.Default()
+			cfg.TimeFormat = tc.setting
+			if got := displayTimeFormat(cfg, fallback); got != tc.want {
+				t.Fatalf("displayTimeFormat(%q) = %+v, want %+v", tc.setting, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchMode(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args []string
+		cfg  config.Config
+		want match.Mode
+	}{
+		"default":     {want: match.ModeFuzzy},
+		"flag":        {args: []string{"--regex"}, want: match.ModeRegex},
+		"config":      {cfg: config.Config{SearchRegex: true}, want: match.ModeRegex},
+		"flag+config": {args: []string{"--regex"}, cfg: config.Config{SearchRegex: true}, want: match.ModeRegex},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if got := matchMode(opts, tc.cfg); got != tc.want {
+				t.Fatalf("matchMode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestActionDetailsFor(t *testing.T) {
 	t.Parallel()
 
@@ -148,6 +700,43 @@ func TestActionDetailsFor(t *testing.T) {
 				EnterLabel:  "delete the selected branch",
 			},
 		},
+		{
+			name:   "archive",
+			action: actionArchive,
+			want: ui.ActionDetails{
+				Name:        "Archive branch",
+				Description: "Tag the selected branch as archive/<branch> and delete it.",
+				EnterLabel:  "archive the selected branch",
+			},
+		},
+		{
+			name:   "unarchive",
+			action: actionUnarchive,
+			want: ui.ActionDetails{
+				Name:        "Unarchive branch",
+				Description: "Restore the selected branch from its archive tag.",
+				EnterLabel:  "restore the selected branch",
+			},
+		},
+		{
+			name:   "rebase-interactive",
+			action: actionRebase,
+			want: ui.ActionDetails{
+				Name:        "Interactive rebase",
+				Description: "Rebase the current branch onto the selected branch with git rebase -i.",
+				EnterLabel:  "rebase onto the selected branch",
+			},
+		},
+		{
+			name:   "push",
+			action: actionPush,
+			want: ui.ActionDetails{
+				Name:         "Push branch",
+				Description:  "Push the selected branch to its remote.",
+				EnterLabel:   "push the selected branch",
+				AllowCurrent: true,
+			},
+		},
 		{
 			name:   "unknown",
 			action: action("unknown"),
@@ -170,7 +759,7 @@ func TestActionDetailsFor(t *testing.T) {
 func TestResolveThemeDefault(t *testing.T) {
 	t.Setenv("BRANCH_NAVIGATOR_THEME", "")
 
-	got, err := resolveTheme("")
+	got, err := resolveTheme("", "", unknownBackground, ui.Colors256)
 	if err != nil {
 		t.Fatalf("resolveTheme returned error: %v", err)
 	}
@@ -182,7 +771,7 @@ func TestResolveThemeDefault(t *testing.T) {
 func TestResolveThemeFlag(t *testing.T) {
 	t.Parallel()
 
-	got, err := resolveTheme("catppuccin")
+	got, err := resolveTheme("catppuccin", "", unknownBackground, ui.Colors256)
 	if err != nil {
 		t.Fatalf("resolveTheme returned error: %v", err)
 	}
@@ -194,7 +783,7 @@ func TestResolveThemeFlag(t *testing.T) {
 func TestResolveThemeEnvFallback(t *testing.T) {
 	t.Setenv("BRANCH_NAVIGATOR_THEME", "Mocha")
 
-	got, err := resolveTheme("")
+	got, err := resolveTheme("", "", unknownBackground, ui.Colors256)
 	if err != nil {
 		t.Fatalf("resolveTheme returned error: %v", err)
 	}
@@ -203,14 +792,281 @@ func TestResolveThemeEnvFallback(t *testing.T) {
 	}
 }
 
+func TestResolveThemeConfigFallback(t *testing.T) {
+	t.Setenv("BRANCH_NAVIGATOR_THEME", "")
+
+	got, err := resolveTheme("", "nord-light", unknownBackground, ui.Colors256)
+	if err != nil {
+		t.Fatalf("resolveTheme returned error: %v", err)
+	}
+	if got != ui.ThemeNordLight {
+		t.Fatalf("expected nord-light theme from config, got %+v", got)
+	}
+
+	t.Setenv("BRANCH_NAVIGATOR_THEME", "gruvbox-dark")
+	got, err = resolveTheme("", "nord-light", unknownBackground, ui.Colors256)
+	if err != nil {
+		t.Fatalf("resolveTheme returned error: %v", err)
+	}
+	if got != ui.ThemeGruvbox {
+		t.Fatalf("expected BRANCH_NAVIGATOR_THEME to override the config, got %+v", got)
+	}
+}
+
 func TestResolveThemeUnknown(t *testing.T) {
 	t.Parallel()
 
-	_, err := resolveTheme("unknown")
+	_, err := resolveTheme("unknown", "", unknownBackground, ui.Colors256)
 	if err == nil {
 		t.Fatal("expected error for unknown theme")
 	}
-	if !strings.Contains(err.Error(), "unknown theme") {
-		t.Fatalf("unexpected error: %v", err)
+	for _, want := range []string{"unknown theme", "-dark, -light, -16, 16, -dark-16, -dark16, -light-16, -light16"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestResolveThemeFollowsBackground(t *testing.T) {
+	t.Parallel()
+
+	light := func() ui.Background { return ui.BackgroundLight }
+	cases := map[string]struct {
+		flag string
+		want ui.Theme
+	}{
+		"family":       {flag: "gruvbox", want: ui.ThemeGruvboxLight},
+		"pinned dark":  {flag: "gruvbox-dark", want: ui.ThemeGruvbox},
+		"pinned light": {flag: "one-light", want: ui.ThemeOneLight},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolveTheme(tc.flag, "", light, ui.Colors256)
+			if err != nil {
+				t.Fatalf("resolveTheme returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("resolveTheme(%q) = %+v, want %+v", tc.flag, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveThemePinnedSkipsDetection(t *testing.T) {
+	t.Parallel()
+
+	detect := func() ui.Background {
+		t.Fatal("background detection should not run for a pinned theme")
+		return ui.BackgroundUnknown
+	}
+	if _, err := resolveTheme("mocha", "", detect, ui.Colors256); err != nil {
+		t.Fatalf("resolveTheme returned error: %v", err)
+	}
+}
+
+func TestResolveThemeBasicColors(t *testing.T) {
+	t.Parallel()
+
+	got, err := resolveTheme("nord-dark", "", unknownBackground, ui.Colors16)
+	if err != nil {
+		t.Fatalf("resolveTheme returned error: %v", err)
+	}
+	if got != ui.ThemeNord.Basic() {
+		t.Fatalf("resolveTheme() = %+v, want the basic variant of nord", got)
+	}
+}
+
+func TestNewSelectorStyle(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.UIBorder = true
+	cfg.UIMarker = "➜"
+	cfg.UIMarkerColor = "203"
+	cfg.UIHeader = "compact"
+	cfg.UIDensity = "compact"
+	cases := map[string]struct {
+		depth ui.ColorDepth
+		want  selectorStyle
+	}{
+		"256 colors": {
+			depth: ui.Colors256,
+			want:  selectorStyle{theme: ui.ThemeNord, border: true, marker: ui.Marker{Symbol: "➜", Color: "\033[38;5;203m"}, header: ui.HeaderCompact, density: ui.DensityCompact},
+		},
+		"16 colors": {
+			depth: ui.Colors16,
+			want:  selectorStyle{theme: ui.ThemeNord.Basic(), border: true, marker: ui.Marker{Symbol: "➜", Color: "\033[91m"}, header: ui.HeaderCompact, density: ui.DensityCompact},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newSelectorStyle("nord-dark", cfg, unknownBackground, tc.depth)
+			if err != nil {
+				t.Fatalf("newSelectorStyle returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("newSelectorStyle() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// testStyle draws selectors with the default theme and marker.
+var testStyle = selectorStyle{theme: ui.DefaultTheme, marker: ui.DefaultMarker}
+
+func unknownBackground() ui.Background {
+	return ui.BackgroundUnknown
+}
+
+func TestConfirmMerge(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  bool
+	}{
+		"accepted": {input: "y\n", want: true},
+		"declined": {input: "n\n"},
+		"enter":    {input: "\n"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := git.NewClient(&recordingRunner{outputs: map[string]string{
+				"rev-parse --abbrev-ref HEAD": "main\n",
+				"--version":                   "git version 2.43.0\n",
+				"merge-tree --write-tree --name-only --no-messages HEAD feature/x": "4b825dc642cb6eb9a060e54bf8d69288fbee4904\n",
+			}})
+			out := &bytes.Buffer{}
+			got, err := confirmMerge(context.Background(), client, strings.NewReader(tc.input), out, "feature/x")
+			if err != nil {
+				t.Fatalf("confirmMerge returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("confirmMerge() = %v, want %v", got, tc.want)
+			}
+			if !strings.HasPrefix(out.String(), "Merge feature/x → main\nThis merge applies cleanly.\n") {
+				t.Fatalf("output = %q, want the branches and the prediction", out.String())
+			}
+		})
+	}
+}
+
+func TestMergeSummary(t *testing.T) {
+	t.Parallel()
+
+	many := make([]string, 12)
+	for i := range many {
+		many[i] = fmt.Sprintf("file%02d.go", i)
+	}
+
+	cases := map[string]struct {
+		prediction git.MergePrediction
+		err        error
+		want       []string
+	}{
+		"clean": {
+			want: []string{"This merge applies cleanly."},
+		},
+		"one conflict": {
+			prediction: git.MergePrediction{Conflicts: []string{"go.mod"}},
+			want:       []string{"This merge will conflict in 1 file:\n  go.mod\n"},
+		},
+		"many conflicts": {
+			prediction: git.MergePrediction{Conflicts: many},
+			want:       []string{"will conflict in 12 files:", "  file09.go\n  … and 2 more\n"},
+		},
+		"old git": {
+			err:  fmt.Errorf("%w (found 2.30.0)", git.ErrMergePredictionUnsupported),
+			want: []string{"Conflicts were not checked: conflict prediction needs git 2.38 or newer (found 2.30.0)."},
+		},
+		"failure": {
+			err:  errors.New("bad revision"),
+			want: []string{"Conflicts could not be checked: bad revision"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := mergeSummary("feature/x", "main", tc.prediction, tc.err)
+			if !strings.HasPrefix(got, "Merge feature/x → main\n") {
+				t.Fatalf("summary = %q, want the branches first", got)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("summary = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.NetworkRetries = 3
+	cfg.NetworkRetryDelaySeconds = 0.5
+	errOut := &bytes.Buffer{}
+	policy := retryPolicy(cfg, errOut)
+	if policy.Retries != 3 || policy.Delay != 500*time.Millisecond {
+		t.Fatalf("policy = %d retries after %s, want 3 after 500ms", policy.Retries, policy.Delay)
+	}
+
+	policy.Notify(git.RetryAttempt{Args: []string{"fetch", "origin"}, Attempt: 2, Attempts: 4, Wait: time.Second, Reason: "Connection timed out"})
+	want := "warning: git fetch origin failed: Connection timed out; retrying in 1s (attempt 2 of 4)\n"
+	if errOut.String() != want {
+		t.Fatalf("notice = %q, want %q", errOut.String(), want)
+	}
+}
+
+func TestSetBranchEditors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		act  action
+		want bool
+	}{
+		"checkout":  {act: actionCheckout, want: true},
+		"delete":    {act: actionDelete, want: true},
+		"unarchive": {act: actionUnarchive},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+			out := &bytes.Buffer{}
+			terminal := testStyle.selector(strings.NewReader("q"), out, actionDetailsFor(tc.act))
+			setBranchEditors(context.Background(), terminal, client, config.Default(), tc.act, &bytes.Buffer{})
+			if _, err := terminal.Select([]ui.Branch{{Name: "archive/old"}}); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			for _, hint := range []string{"e to delete empty branches", "l to label"} {
+				if got := strings.Contains(out.String(), hint); got != tc.want {
+					t.Fatalf("help shows %q = %v, want %v", hint, got, tc.want)
+				}
+			}
+		})
 	}
 }
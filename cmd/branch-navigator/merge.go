@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strconv"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+// mergeFlags holds -S/--gpg-sign and --signoff, which take precedence over
+// merge.gpg_sign and merge.signoff for one run.
+type mergeFlags struct {
+	gpgSign gpgSignFlag
+	// signoff is nil unless --signoff was given.
+	signoff *bool
+}
+
+// set reports whether any merge flag was given.
+func (f mergeFlags) set() bool {
+	return f.gpgSign.set || f.signoff != nil
+}
+
+// setSignoff parses the value of --signoff, which is "true" when the flag
+// is given alone.
+func (f *mergeFlags) setSignoff(value string) error {
+	signoff, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	f.signoff = &signoff
+	return nil
+}
+
+// gpgSignFlag is the value of -S and --gpg-sign. Given alone it signs with
+// git's default key; --gpg-sign=KEYID picks the key and --gpg-sign=false
+// turns signing off.
+type gpgSignFlag struct {
+	set  bool
+	sign bool
+	key  string
+}
+
+// IsBoolFlag lets the flag be given without a value.
+func (f *gpgSignFlag) IsBoolFlag() bool { return true }
+
+func (f *gpgSignFlag) String() string {
+	if f == nil || !f.sign {
+		return "false"
+	}
+	if f.key != "" {
+		return f.key
+	}
+	return "true"
+}
+
+func (f *gpgSignFlag) Set(value string) error {
+	f.set = true
+	switch value {
+	case "true":
+		f.sign, f.key = true, ""
+	case "false":
+		f.sign, f.key = false, ""
+	default:
+		f.sign, f.key = true, value
+	}
+	return nil
+}
+
+// mergeOptions returns the options of the merge action: the merge settings
+// of the configuration, overridden by the flags that were given.
+func mergeOptions(cfg config.Config, flags mergeFlags) git.MergeOptions {
+	opts := git.MergeOptions{GPGSign: cfg.MergeGPGSign, Signoff: cfg.MergeSignoff}
+	if flags.gpgSign.set {
+		opts.GPGSign, opts.SigningKey = flags.gpgSign.sign, flags.gpgSign.key
+	}
+	if flags.signoff != nil {
+		opts.Signoff = *flags.signoff
+	}
+	return opts
+}
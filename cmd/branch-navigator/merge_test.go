@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+func TestMergeOptions(t *testing.T) {
+	t.Parallel()
+
+	signing := config.Default()
+	signing.MergeGPGSign = true
+	signing.MergeSignoff = true
+
+	cases := map[string]struct {
+		args    []string
+		cfg     config.Config
+		want    git.MergeOptions
+		wantErr string
+	}{
+		"defaults":            {args: []string{"-m"}, cfg: config.Default()},
+		"configuration":       {args: []string{"-m"}, cfg: signing, want: git.MergeOptions{GPGSign: true, Signoff: true}},
+		"short flag":          {args: []string{"-m", "-S", "--signoff"}, cfg: config.Default(), want: git.MergeOptions{GPGSign: true, Signoff: true}},
+		"key":                 {args: []string{"-m", "--gpg-sign=ABCD1234"}, cfg: config.Default(), want: git.MergeOptions{GPGSign: true, SigningKey: "ABCD1234"}},
+		"flags override":      {args: []string{"-m", "--gpg-sign=false", "--signoff=false"}, cfg: signing},
+		"menu":                {args: []string{"--menu", "-S"}, cfg: config.Default(), want: git.MergeOptions{GPGSign: true}},
+		"checkout is refused": {args: []string{"-S"}, wantErr: "can only be used with -m or --menu"},
+		"invalid signoff":     {args: []string{"-m", "--signoff=maybe"}, wantErr: "invalid boolean value"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			opts, err := parseArgs(tc.args, usage, usage)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error()+usage.String(), tc.wantErr) {
+					t.Fatalf("parseArgs error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs returned error: %v", err)
+			}
+			if got := mergeOptions(tc.cfg, opts.merge); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("mergeOptions() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
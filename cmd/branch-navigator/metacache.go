@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/metacache"
+)
+
+// metadataCache is where the merged state, commit counts, and upstream
+// tracking computed for repo are kept between runs.
+type metadataCache struct {
+	store *metacache.Store
+	repo  string
+}
+
+// openMetadataCache returns the cache of the repository client works in,
+// or nil when it cannot be located. The cache only saves work, so nothing
+// is reported.
+func openMetadataCache(ctx context.Context, client *git.Client) *metadataCache {
+	store, err := metacache.OpenDefault()
+	if err != nil {
+		return nil
+	}
+	repo, err := client.RepoRoot(ctx)
+	if err != nil {
+		return nil
+	}
+	return &metadataCache{store: store, repo: repo}
+}
+
+// refKeys returns the cache key of every branch in states: its tip, the tip
+// of its upstream, and the tip of base, which may be "".
+func refKeys(states []git.RefState, base string) map[string]metacache.Key {
+	tips := make(map[string]string, len(states))
+	for _, state := range states {
+		tips[state.Name] = state.Hash
+	}
+	keys := make(map[string]metacache.Key, len(states))
+	for _, state := range states {
+		keys[state.Name] = metacache.Key{Branch: state.Hash, Upstream: tips[state.Upstream], Base: tips[base]}
+	}
+	return keys
+}
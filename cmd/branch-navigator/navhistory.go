@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/history"
+	"branch-navigator/internal/timefmt"
+	"branch-navigator/internal/ui"
+)
+
+// historyTimeFormat is used for the time column unless time.format is set.
+var historyTimeFormat = timefmt.Layout("2006-01-02 15:04")
+
+// runHistoryCommand implements the history subcommand and returns the process exit code.
+func runHistoryCommand(ctx context.Context, client *git.Client, store *history.Store, format timefmt.Format, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator history", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(out, historyCommand.Usage(programName))
+	}
+	limit := fs.Int("n", 20, historyCommand.FlagUsage("n"))
+	all := fs.Bool("all", false, historyCommand.FlagUsage("all"))
+	replay := fs.Int("replay", 0, historyCommand.FlagUsage("replay"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprint(errOut, historyCommand.Usage(programName))
+		return 2
+	}
+	if *limit <= 0 || *replay < 0 {
+		fmt.Fprintln(errOut, "-n and --replay must be greater than 0")
+		return 2
+	}
+	if *replay > 0 && *all {
+		fmt.Fprintln(errOut, "--replay cannot be combined with --all")
+		return 2
+	}
+	if store == nil {
+		fmt.Fprintln(errOut, "history store is not available")
+		return 1
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	repo := ""
+	if !*all {
+		root, err := client.RepoRoot(ctx)
+		if err != nil && *replay > 0 {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		repo = root
+	}
+	checkouts := recentCheckouts(entries, repo)
+
+	if *replay > 0 {
+		return replayCheckout(ctx, client, store, checkouts, *replay, out, errOut)
+	}
+
+	if len(checkouts) == 0 {
+		fmt.Fprintln(out, "No checkouts recorded yet")
+		return 0
+	}
+	if len(checkouts) > *limit {
+		checkouts = checkouts[:*limit]
+	}
+	now := time.Now()
+	w := ui.NewColumnWriter(out, 2)
+	for i, entry := range checkouts {
+		from := entry.From
+		if from == "" {
+			from = "?"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s → %s\t%s\n", i+1, format.Format(entry.Time, now), from, entry.Branch, entry.Repo)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+// recentCheckouts returns the checkout entries for repo, or for every
+// repository when repo is empty, most recent first.
+func recentCheckouts(entries []history.Entry, repo string) []history.Entry {
+	checkouts := []history.Entry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Action != string(actionCheckout) {
+			continue
+		}
+		if repo != "" && entry.Repo != repo {
+			continue
+		}
+		checkouts = append(checkouts, entry)
+	}
+	return checkouts
+}
+
+// replayCheckout checks out the branch that was current before the nth most
+// recent checkout, like git checkout @{-n}.
+func replayCheckout(ctx context.Context, client *git.Client, store *history.Store, checkouts []history.Entry, n int, out, errOut io.Writer) int {
+	if n > len(checkouts) {
+		fmt.Fprintf(errOut, "only %d checkouts recorded for this repository\n", len(checkouts))
+		return 1
+	}
+	target := checkouts[n-1].From
+	if target == "" {
+		fmt.Fprintf(errOut, "checkout %d did not record the previous branch\n", n)
+		return 1
+	}
+
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	message, err := client.CheckoutBranch(ctx, target)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	printIfNotEmpty(out, message)
+	if target != current {
+		recordAction(ctx, store, client, errOut, actionCheckout, current, target)
+	}
+	return 0
+}
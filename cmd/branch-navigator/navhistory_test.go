@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/history"
+	"branch-navigator/internal/timefmt"
+)
+
+func historyStore(t *testing.T) *history.Store {
+	t.Helper()
+	store := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	for _, entry := range []history.Entry{
+		{Time: time.Unix(100, 0), Repo: "/src/app", Action: "checkout", From: "main", Branch: "feature/x"},
+		{Time: time.Unix(200, 0), Repo: "/src/lib", Action: "checkout", From: "main", Branch: "fix/y"},
+		{Time: time.Unix(300, 0), Repo: "/src/app", Action: "merge", From: "feature/x", Branch: "main"},
+		{Time: time.Unix(400, 0), Repo: "/src/app", Action: "checkout", From: "feature/x", Branch: "release"},
+	} {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+	return store
+}
+
+func historyTime(sec int64) string {
+	return time.Unix(sec, 0).Local().Format("2006-01-02 15:04")
+}
+
+func TestRunHistoryCommandList(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args []string
+		want string
+	}{
+		"current-repo": {
+			want: "1  " + historyTime(400) + "  feature/x → release  /src/app\n" +
+				"2  " + historyTime(100) + "  main → feature/x     /src/app\n",
+		},
+		"all": {
+			args: []string{"--all"},
+			want: "1  " + historyTime(400) + "  feature/x → release  /src/app\n" +
+				"2  " + historyTime(200) + "  main → fix/y         /src/lib\n" +
+				"3  " + historyTime(100) + "  main → feature/x     /src/app\n",
+		},
+		"limit": {
+			args: []string{"-n", "1"},
+			want: "1  " + historyTime(400) + "  feature/x → release  /src/app\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": "/src/app\n"}}
+			out := &bytes.Buffer{}
+			if code := runHistoryCommand(context.Background(), git.NewClient(runner), historyStore(t), historyTimeFormat, tc.args, out, &bytes.Buffer{}); code != 0 {
+				t.Fatalf("exit code %d", code)
+			}
+			if got := out.String(); got != tc.want {
+				t.Fatalf("history output =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunHistoryCommandTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": "/src/app\n"}}
+	out := &bytes.Buffer{}
+	if code := runHistoryCommand(context.Background(), git.NewClient(runner), historyStore(t), timefmt.Short, []string{"-n", "1"}, out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	want := "1  " + time.Unix(400, 0).Local().Format("2006-01-02") + "  feature/x → release  /src/app\n"
+	if got := out.String(); got != want {
+		t.Fatalf("history output = %q, want %q", got, want)
+	}
+}
+
+func TestRunHistoryCommandEmpty(t *testing.T) {
+	t.Parallel()
+
+	store := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": "/src/app\n"}}
+	out := &bytes.Buffer{}
+	if code := runHistoryCommand(context.Background(), git.NewClient(runner), store, historyTimeFormat, nil, out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if got := out.String(); got != "No checkouts recorded yet\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestRunHistoryCommandReplay(t *testing.T) {
+	t.Parallel()
+
+	store := historyStore(t)
+	runner := &recordingRunner{outputs: map[string]string{
+		"rev-parse --show-toplevel":   "/src/app\n",
+		"rev-parse --abbrev-ref HEAD": "release",
+	}}
+	errOut := &bytes.Buffer{}
+	if code := runHistoryCommand(context.Background(), git.NewClient(runner), store, historyTimeFormat, []string{"--replay", "2"}, &bytes.Buffer{}, errOut); code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, errOut.String())
+	}
+
+	var checkout []string
+	for _, call := range runner.calls {
+		if call[0] == "checkout" {
+			checkout = call
+		}
+	}
+	if want := []string{"checkout", "main"}; !reflect.DeepEqual(checkout, want) {
+		t.Fatalf("checkout call = %v, want %v", checkout, want)
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("Entries returned error: %v", err)
+	}
+	last := entries[len(entries)-1]
+	if last.Action != "checkout" || last.From != "release" || last.Branch != "main" {
+		t.Fatalf("unexpected recorded entry %+v", last)
+	}
+}
+
+func TestRunHistoryCommandErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		"out-of-range": {args: []string{"--replay", "5"}, wantCode: 1, wantErr: "only 2 checkouts recorded"},
+		"replay-all":   {args: []string{"--replay", "1", "--all"}, wantCode: 2, wantErr: "cannot be combined"},
+		"zero-limit":   {args: []string{"-n", "0"}, wantCode: 2, wantErr: "greater than 0"},
+		"extra-args":   {args: []string{"main"}, wantCode: 2, wantErr: "Usage:"},
+		"unknown-flag": {args: []string{"--bogus"}, wantCode: 2, wantErr: "flag provided but not defined"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": "/src/app\n"}}
+			errOut := &bytes.Buffer{}
+			code := runHistoryCommand(context.Background(), git.NewClient(runner), historyStore(t), historyTimeFormat, tc.args, &bytes.Buffer{}, errOut)
+			if code != tc.wantCode {
+				t.Fatalf("exit code %d, want %d", code, tc.wantCode)
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr %q does not contain %q", errOut.String(), tc.wantErr)
+			}
+		})
+	}
+}
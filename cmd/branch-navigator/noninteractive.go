@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// exitNonInteractive is the exit code when the selector would need someone
+// to answer it and nobody can, so that scripts can tell it from failures.
+const exitNonInteractive = 3
+
+// nonInteractiveReason explains why nobody can answer the selector: CI is
+// set to a true value, as CI services do, or neither stdin nor stdout is a
+// terminal. It is "" when the selector can be used; with only stdout on a
+// terminal, someone can still read the plain list and pipe in the answer.
+func nonInteractiveReason(getenv func(string) string, stdinTerminal, stdoutTerminal bool) string {
+	if ci, err := strconv.ParseBool(strings.TrimSpace(getenv("CI"))); err == nil && ci {
+		return "CI is set"
+	}
+	if !stdinTerminal && !stdoutTerminal {
+		return "stdin and stdout are not terminals"
+	}
+	return ""
+}
+
+// errNonInteractive is reported instead of printing a selector that no one
+// can answer into a build log.
+func errNonInteractive(reason string) error {
+	return fmt.Errorf("cannot choose a branch interactively: %s\npass --pick <branch> or --stdin to name the branch, or --plain to answer a numbered list from stdin", reason)
+}
+
+// readStdinBranch returns the first non-blank line of in, the branch named
+// with --stdin.
+func readStdinBranch(in io.Reader) (string, error) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			return name, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("--stdin: no branch name on stdin")
+}
+
+// pickedResult stands in for the selector when the branch is named with
+// --pick or --stdin. The name must be a local branch or, failing that, a
+// remote-tracking branch; unarchive takes the archived name as given.
+// Checkout also takes the name of a branch that only exists on remotes,
+// such as feature/x for origin/feature/x, tracking the remote chooseRemote
+// returns when several have it. As in
+// the selector, naming the current branch reports "already on" unless the
+// action allows it.
+func pickedResult(ctx context.Context, client *git.Client, picker *remotePicker, act action, details ui.ActionDetails, current, name string, out io.Writer) (ui.Result, error) {
+	if act == actionUnarchive {
+		return ui.Result{Branch: name}, nil
+	}
+	if name == current && !details.AllowCurrent {
+		fmt.Fprintf(out, "already on '%s'\n", name)
+		return ui.Result{Branch: name, AlreadyOn: true}, nil
+	}
+	refs, err := client.BranchRefs(ctx)
+	if err != nil {
+		return ui.Result{}, err
+	}
+	remote, guessed := false, false
+	for _, ref := range refs {
+		if ref.Remote && strings.HasSuffix(ref.Name, "/"+name) && strings.Count(ref.Name, "/") == strings.Count(name, "/")+1 {
+			guessed = true
+		}
+		if ref.Name != name {
+			continue
+		}
+		if !ref.Remote {
+			return ui.Result{Branch: name}, nil
+		}
+		remote = true
+	}
+	if remote {
+		return ui.Result{Branch: name, Remote: true}, nil
+	}
+	if guessed && act == actionCheckout {
+		remotes, err := client.RemotesWithBranch(ctx, name)
+		if err != nil || len(remotes) < 2 {
+			return ui.Result{Branch: name}, err
+		}
+		remote, err := chooseRemote(ctx, client, picker, "Check out "+name+" from", remotes)
+		if err != nil {
+			return ui.Result{}, err
+		}
+		if remote == "" {
+			return ui.Result{Quit: true}, nil
+		}
+		return ui.Result{Branch: remote + "/" + name, Remote: true}, nil
+	}
+	return ui.Result{}, fmt.Errorf("no branch named '%s'", name)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+func TestNonInteractiveReason(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		ci     string
+		stdin  bool
+		stdout bool
+		want   string
+	}{
+		"terminal":          {stdin: true, stdout: true},
+		"ci true":           {ci: "true", stdin: true, stdout: true, want: "CI is set"},
+		"ci one":            {ci: "1", stdin: true, stdout: true, want: "CI is set"},
+		"ci false":          {ci: "false", stdin: true, stdout: true},
+		"ci unparsable":     {ci: "woodpecker", stdin: true, stdout: true},
+		"piped stdin":       {stdout: true},
+		"piped stdout":      {stdin: true},
+		"no terminal":       {want: "stdin and stdout are not terminals"},
+		"ci and a terminal": {ci: "TRUE", want: "CI is set"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := fakeEnvLookup(map[string]string{"CI": tc.ci})
+			if got := nonInteractiveReason(getenv, tc.stdin, tc.stdout); got != tc.want {
+				t.Fatalf("nonInteractiveReason() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadStdinBranch(t *testing.T) {
+	t.Parallel()
+
+	got, err := readStdinBranch(strings.NewReader("\n  feature/x  \nmain\n"))
+	if err != nil || got != "feature/x" {
+		t.Fatalf("readStdinBranch() = %q, %v, want feature/x", got, err)
+	}
+	if _, err := readStdinBranch(strings.NewReader(" \n")); err == nil || err.Error() != "--stdin: no branch name on stdin" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestPickedResult(t *testing.T) {
+	t.Parallel()
+
+	refs := "refs/heads/main\t300\t\tAda <ada@example.com>\n" +
+		"refs/heads/feature/x\t200\t\tAda <ada@example.com>\n" +
+		"refs/remotes/origin/feature/x\t200\t\tAda <ada@example.com>\n" +
+		"refs/remotes/origin/feature/y\t100\t\tAda <ada@example.com>\n" +
+		"refs/remotes/origin/feature/z\t100\t\tAda <ada@example.com>\n" +
+		"refs/remotes/upstream/feature/z\t100\t\tAda <ada@example.com>"
+	checkout := actionDetailsFor(actionCheckout)
+
+	cases := map[string]struct {
+		act     action
+		details ui.ActionDetails
+		name    string
+		want    ui.Result
+		wantOut string
+		wantErr string
+	}{
+		"local":           {act: actionCheckout, details: checkout, name: "feature/x", want: ui.Result{Branch: "feature/x"}},
+		"remote":          {act: actionCheckout, details: checkout, name: "origin/feature/y", want: ui.Result{Branch: "origin/feature/y", Remote: true}},
+		"current":         {act: actionCheckout, details: checkout, name: "main", want: ui.Result{Branch: "main", AlreadyOn: true}, wantOut: "already on 'main'\n"},
+		"current push":    {act: actionPush, details: actionDetailsFor(actionPush), name: "main", want: ui.Result{Branch: "main"}},
+		"archived":        {act: actionUnarchive, details: actionDetailsFor(actionUnarchive), name: "old", want: ui.Result{Branch: "old"}},
+		"remote guessed":  {act: actionCheckout, details: checkout, name: "feature/y", want: ui.Result{Branch: "feature/y"}},
+		"several remotes": {act: actionCheckout, details: checkout, name: "feature/z", want: ui.Result{Branch: "origin/feature/z", Remote: true}},
+		"guess for push":  {act: actionPush, details: actionDetailsFor(actionPush), name: "feature/y", wantErr: "no branch named 'feature/y'"},
+		"unknown branch":  {act: actionCheckout, details: checkout, name: "nope", wantErr: "no branch named 'nope'"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{
+				"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": refs,
+				"for-each-ref --format=%(refname) refs/remotes": "refs/remotes/origin/feature/y\nrefs/remotes/origin/feature/z\nrefs/remotes/upstream/feature/z",
+				"remote -v": "origin\thttps://example.com/repo.git (fetch)\nupstream\thttps://example.com/up.git (fetch)",
+			}}
+			out := &bytes.Buffer{}
+			got, err := pickedResult(context.Background(), git.NewClient(runner), nil, tc.act, tc.details, "main", tc.name, out)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("pickedResult() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickedResult returned error: %v", err)
+			}
+			if got.Branch != tc.want.Branch || got.Remote != tc.want.Remote || got.AlreadyOn != tc.want.AlreadyOn {
+				t.Fatalf("pickedResult() = %+v, want %+v", got, tc.want)
+			}
+			if out.String() != tc.wantOut {
+				t.Fatalf("output = %q, want %q", out.String(), tc.wantOut)
+			}
+		})
+	}
+}
+
+func TestParseArgsPick(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"-m", "--pick", " feature/x "}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if opts.pick != "feature/x" || opts.action != actionMerge {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"both":         {args: []string{"--pick", "x", "--stdin"}, wantErr: "--pick and --stdin cannot be combined"},
+		"with a menu":  {args: []string{"--stdin", "--menu"}, wantErr: "--pick and --stdin cannot be used with --menu"},
+		"with remotes": {args: []string{"--pick", "x", "--remote-admin"}, wantErr: "cannot be used with --menu, --remote-admin, or --head-history"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			_, err := parseArgs(tc.args, usage, usage)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"branch-navigator/internal/git"
+)
+
+// operationWarning describes an operation left in progress and how to end
+// it, for the banner above the selector; it is "" for git.OperationNone.
+func operationWarning(op git.Operation) string {
+	switch op {
+	case git.OperationMerge:
+		return "A merge is in progress: commit it, or abort it with git merge --abort."
+	case git.OperationBisect:
+		return "A bisect is in progress: end it with git bisect reset."
+	case git.OperationNone:
+		return ""
+	default:
+		return fmt.Sprintf("A %s is in progress: finish it with git %s --continue, or abort it with git %s --abort.", op, op, op)
+	}
+}
+
+// switchesBranch reports whether act moves HEAD to another branch or
+// merges into it, which would wreck an operation in progress.
+func switchesBranch(act action) bool {
+	switch act {
+	case actionCheckout, actionMerge, actionCreate, actionHeadHistory:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkOperation returns the operation in progress in the repository client
+// works in, and an error when act must not run during it unless force is
+// set. Failing to tell is not an error: the action reports a broken
+// repository itself.
+func checkOperation(ctx context.Context, client *git.Client, act action, force bool) (git.Operation, error) {
+	op, err := client.InProgress(ctx)
+	if err != nil {
+		return git.OperationNone, nil
+	}
+	if op != git.OperationNone && switchesBranch(act) && !force {
+		return op, fmt.Errorf("refusing to %s during a %s; pass --force-state to run it anyway\n%s", act, op, operationWarning(op))
+	}
+	return op, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func TestOperationWarning(t *testing.T) {
+	t.Parallel()
+
+	cases := map[git.Operation]string{
+		git.OperationNone:       "",
+		git.OperationMerge:      "A merge is in progress: commit it, or abort it with git merge --abort.",
+		git.OperationRebase:     "A rebase is in progress: finish it with git rebase --continue, or abort it with git rebase --abort.",
+		git.OperationCherryPick: "A cherry-pick is in progress: finish it with git cherry-pick --continue, or abort it with git cherry-pick --abort.",
+		git.OperationRevert:     "A revert is in progress: finish it with git revert --continue, or abort it with git revert --abort.",
+		git.OperationBisect:     "A bisect is in progress: end it with git bisect reset.",
+	}
+
+	for op, want := range cases {
+		op := op
+		want := want
+		t.Run(op.String(), func(t *testing.T) {
+			t.Parallel()
+
+			if got := operationWarning(op); got != want {
+				t.Fatalf("operationWarning(%v) = %q, want %q", op, got, want)
+			}
+		})
+	}
+}
+
+// rebasingRunner answers like a repository stopped in the middle of a rebase.
+func rebasingRunner(t *testing.T) *recordingRunner {
+	t.Helper()
+	gitDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(gitDir, "rebase-merge"), 0o755); err != nil {
+		t.Fatalf("failed to create rebase state: %v", err)
+	}
+	return &recordingRunner{outputs: map[string]string{"rev-parse --absolute-git-dir": gitDir}}
+}
+
+func TestCheckOperation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		act     action
+		force   bool
+		wantErr bool
+	}{
+		"checkout is refused":     {act: actionCheckout, wantErr: true},
+		"merge is refused":        {act: actionMerge, wantErr: true},
+		"create is refused":       {act: actionCreate, wantErr: true},
+		"force-state allows":      {act: actionCheckout, force: true},
+		"other actions only warn": {act: actionLog},
+		"delete is not a switch":  {act: actionDelete},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			op, err := checkOperation(context.Background(), git.NewClient(rebasingRunner(t)), tc.act, tc.force)
+			if op != git.OperationRebase {
+				t.Fatalf("operation = %v, want rebase", op)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("checkOperation error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "refusing to "+string(tc.act)+" during a rebase; pass --force-state") {
+				t.Fatalf("unexpected error %q", err)
+			}
+		})
+	}
+}
+
+func TestRunSwitchCommandDuringRebase(t *testing.T) {
+	t.Parallel()
+
+	runner := rebasingRunner(t)
+	runner.outputs["rev-parse --abbrev-ref HEAD"] = "main"
+	runner.outputs["for-each-ref --format=%(refname:lstrip=2) --sort=-committerdate refs/heads"] = "main\nfeature/x"
+	runner.outputs["branch --list --format=%(refname:lstrip=2)"] = "feature/x\nmain"
+	errOut := &bytes.Buffer{}
+	code := runSwitchCommand(context.Background(), git.NewClient(runner), nil, []string{"feat"}, &bytes.Buffer{}, errOut)
+	if code != 1 || !strings.Contains(errOut.String(), "refusing to checkout during a rebase") {
+		t.Fatalf("unexpected result: code %d, stderr %q", code, errOut.String())
+	}
+	for _, call := range runner.calls {
+		if call[0] == "checkout" {
+			t.Fatalf("checked out during a rebase: %v", runner.calls)
+		}
+	}
+}
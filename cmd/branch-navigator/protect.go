@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// protectedBranchesKey is the git configuration key that sets the protected
+// branch patterns for a single repository.
+const protectedBranchesKey = "branch-navigator.protectedBranches"
+
+// protectedBranches returns the protected branch patterns of the current
+// repository: its protectedBranchesKey setting, separated by spaces or
+// commas, or confirm.protected otherwise. A setting of "none" protects no
+// branch.
+func protectedBranches(ctx context.Context, client *git.Client, cfg config.Config) ([]string, error) {
+	value, ok, err := client.ConfigValue(ctx, git.ConfigLocal, protectedBranchesKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || strings.TrimSpace(value) == "" {
+		return cfg.ProtectedBranches, nil
+	}
+	patterns := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(patterns) == 1 && patterns[0] == "none" {
+		return nil, nil
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern %q", protectedBranchesKey, pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// isProtected reports whether branch matches one of patterns. A * in a
+// pattern does not match a slash, so release/* covers release/1.0 but not
+// release/1.0/hotfix.
+func isProtected(branch string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmMergeInto asks before source is merged into the current branch.
+// A protected current branch has to be typed, after the same summary as
+// confirm.merge shows; otherwise confirm.merge decides whether y is asked
+// for. It reports true when the merge may run.
+func confirmMergeInto(ctx context.Context, client *git.Client, cfg config.Config, in io.Reader, out io.Writer, source string) (bool, error) {
+	patterns, err := protectedBranches(ctx, client, cfg)
+	if err != nil {
+		return false, err
+	}
+	if len(patterns) > 0 {
+		target, err := client.CurrentBranch(ctx)
+		if err != nil {
+			return false, err
+		}
+		target = strings.TrimSpace(target)
+		if isProtected(target, patterns) {
+			prediction, err := client.PredictMerge(ctx, source)
+			return ui.TypedConfirmation{
+				Warning: mergeSummary(source, target, prediction, err) + fmt.Sprintf("'%s' is a protected branch.", target),
+				Token:   target,
+			}.Confirm(in, out)
+		}
+	}
+	if cfg.ConfirmMerge {
+		return confirmMerge(ctx, client, in, out, source)
+	}
+	return true, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+)
+
+func TestProtectedBranches(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		setting string
+		want    []string
+		wantErr bool
+	}{
+		"configuration file": {want: []string{"main", "master", "release/*"}},
+		"repository setting": {setting: "trunk, stable/*\n", want: []string{"trunk", "stable/*"}},
+		"none":               {setting: "none\n"},
+		"invalid pattern":    {setting: "stable/[\n", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := git.NewClient(&recordingRunner{outputs: map[string]string{
+				"config --local --get branch-navigator.protectedBranches": tc.setting,
+			}})
+			got, err := protectedBranches(context.Background(), client, config.Default())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("protectedBranches error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("protectedBranches() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsProtected(t *testing.T) {
+	t.Parallel()
+
+	patterns := []string{"main", "release/*"}
+	cases := map[string]bool{
+		"main":               true,
+		"release/1.0":        true,
+		"release/1.0/hotfix": false,
+		"maintenance":        false,
+		"feature/x":          false,
+	}
+	for branch, want := range cases {
+		if got := isProtected(branch, patterns); got != want {
+			t.Errorf("isProtected(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestConfirmMergeInto(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		current      string
+		confirmMerge bool
+		input        string
+		want         bool
+		wantOut      []string
+	}{
+		"protected typed": {
+			current: "main",
+			input:   "main\n",
+			want:    true,
+			wantOut: []string{"Merge feature/x → main\n", "'main' is a protected branch.", "Type main to confirm"},
+		},
+		"protected answered y": {
+			current: "release/2.0",
+			input:   "y\n",
+			wantOut: []string{"'release/2.0' is a protected branch.", `"y" does not match "release/2.0"`},
+		},
+		"unprotected": {current: "develop", want: true},
+		"unprotected with confirm.merge": {
+			current:      "develop",
+			confirmMerge: true,
+			input:        "y\n",
+			want:         true,
+			wantOut:      []string{"Merge feature/x → develop\n"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.Default()
+			cfg.ConfirmMerge = tc.confirmMerge
+			client := git.NewClient(&recordingRunner{outputs: map[string]string{
+				"rev-parse --abbrev-ref HEAD": tc.current + "\n",
+				"--version":                   "git version 2.43.0\n",
+				"merge-tree --write-tree --name-only --no-messages HEAD feature/x": "4b825dc642cb6eb9a060e54bf8d69288fbee4904\n",
+			}})
+			out := &bytes.Buffer{}
+			got, err := confirmMergeInto(context.Background(), client, cfg, strings.NewReader(tc.input), out, "feature/x")
+			if err != nil {
+				t.Fatalf("confirmMergeInto returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("confirmMergeInto() = %v, want %v", got, tc.want)
+			}
+			if len(tc.wantOut) == 0 && out.Len() != 0 {
+				t.Fatalf("output = %q, want no prompt", out.String())
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
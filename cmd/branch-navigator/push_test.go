@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func TestHandlePushAction(t *testing.T) {
+	t.Parallel()
+
+	upstreamArgs := "for-each-ref --format=%(upstream:remotename)%09%(upstream:remoteref) refs/heads/feature/x"
+
+	cases := map[string]struct {
+		upstream   string
+		remotes    string
+		autoSetup  bool
+		wantPush   []string
+		wantOut    string
+		wantStderr string
+		wantErr    string
+	}{
+		"tracking": {
+			upstream: "fork\trefs/heads/feature/x",
+			wantPush: []string{"push", "fork", "feature/x"},
+		},
+		"auto-setup": {
+			autoSetup: true,
+			wantPush:  []string{"push", "--set-upstream", "origin", "feature/x"},
+			wantOut:   "Branch 'feature/x' now tracks 'origin/feature/x'",
+		},
+		"no-upstream": {
+			wantPush:   []string{"push", "origin", "feature/x"},
+			wantStderr: "has no upstream",
+		},
+		"only remote": {
+			remotes:   "fork\thttps://example.com/fork.git (fetch)",
+			autoSetup: true,
+			wantPush:  []string{"push", "--set-upstream", "fork", "feature/x"},
+			wantOut:   "Branch 'feature/x' now tracks 'fork/feature/x'",
+		},
+		"several remotes": {
+			remotes: "fork\thttps://example.com/fork.git (fetch)\nupstream\thttps://example.com/up.git (fetch)",
+			wantErr: "push aborted: no remote chosen; set branch-navigator.defaultRemote",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{upstreamArgs: tc.upstream, "remote -v": tc.remotes}}
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			err := handlePushAction(context.Background(), git.NewClient(runner), testStyle, nil, out, errOut, "feature/x", tc.autoSetup)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("handlePushAction error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handlePushAction returned error: %v", err)
+			}
+			if last := runner.calls[len(runner.calls)-1]; !reflect.DeepEqual(last, tc.wantPush) {
+				t.Fatalf("unexpected git calls: %v", runner.calls)
+			}
+			if tc.wantOut != "" && !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+			if tc.wantStderr != "" && !strings.Contains(errOut.String(), tc.wantStderr) {
+				t.Fatalf("expected stderr to contain %q, got %q", tc.wantStderr, errOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+)
+
+// scorerFor builds the branch ranking configured by the ranking.* settings.
+func scorerFor(cfg config.Config) navigator.Scorer {
+	return navigator.Scorer{
+		HalfLife: time.Duration(cfg.RankingHalfLifeDays * float64(24*time.Hour)),
+		Weights: navigator.Weights{
+			Frequency: cfg.RankingFrequencyWeight,
+			Recency:   cfg.RankingRecencyWeight,
+			Reflog:    cfg.RankingReflogWeight,
+		},
+	}
+}
+
+// branchVisits summarizes the recorded checkouts in client's repository.
+// Failures are reported as warnings so that ranking falls back to recency order.
+func branchVisits(ctx context.Context, store *history.Store, client *git.Client, errOut io.Writer) map[string]navigator.Visit {
+	if store == nil {
+		return nil
+	}
+	repo, err := client.RepoRoot(ctx)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: failed to read history: %v\n", err)
+		return nil
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: failed to read history: %v\n", err)
+		return nil
+	}
+	return visitsFrom(entries, repo)
+}
+
+// visitsFrom counts the checkouts of each branch in repo and remembers the latest one.
+func visitsFrom(entries []history.Entry, repo string) map[string]navigator.Visit {
+	visits := map[string]navigator.Visit{}
+	for _, entry := range entries {
+		if entry.Repo != repo || entry.Action != string(actionCheckout) {
+			continue
+		}
+		visit := visits[entry.Branch]
+		visit.Count++
+		if entry.Time.After(visit.Last) {
+			visit.Last = entry.Time
+		}
+		visits[entry.Branch] = visit
+	}
+	return visits
+}
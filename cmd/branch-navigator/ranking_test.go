@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+)
+
+func TestScorerFor(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.RankingHalfLifeDays = 1.5
+	cfg.RankingFrequencyWeight = 2
+	cfg.RankingRecencyWeight = 0.5
+
+	want := navigator.Scorer{
+		HalfLife: 36 * time.Hour,
+		Weights:  navigator.Weights{Frequency: 2, Recency: 0.5, Reflog: 1},
+	}
+	if got := scorerFor(cfg); !reflect.DeepEqual(got, want) {
+		t.Fatalf("scorerFor() = %+v, want %+v", got, want)
+	}
+	if scorerFor(config.Default()).UsesHistory() {
+		t.Fatal("default ranking should not need history")
+	}
+}
+
+func TestVisitsFrom(t *testing.T) {
+	t.Parallel()
+
+	entries := []history.Entry{
+		{Time: time.Unix(100, 0), Repo: "/src/app", Action: "checkout", Branch: "feature/x"},
+		{Time: time.Unix(300, 0), Repo: "/src/app", Action: "checkout", Branch: "feature/x"},
+		{Time: time.Unix(200, 0), Repo: "/src/app", Action: "checkout", Branch: "main"},
+		{Time: time.Unix(400, 0), Repo: "/src/app", Action: "merge", Branch: "main"},
+		{Time: time.Unix(500, 0), Repo: "/src/lib", Action: "checkout", Branch: "feature/x"},
+	}
+
+	want := map[string]navigator.Visit{
+		"feature/x": {Count: 2, Last: time.Unix(300, 0)},
+		"main":      {Count: 1, Last: time.Unix(200, 0)},
+	}
+	if got := visitsFrom(entries, "/src/app"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("visitsFrom() = %+v, want %+v", got, want)
+	}
+}
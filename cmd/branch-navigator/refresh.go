@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+	"branch-navigator/internal/platform/refwatch"
+	"branch-navigator/internal/ui"
+)
+
+// branchRows is what the selector lists: the rows read up front, the
+// loader for the rest of a streamed list, and the annotator whose slower
+// details fill in while the list is open.
+type branchRows struct {
+	rows       ui.Provider
+	load       ui.Loader
+	annotator  *branchAnnotator
+	visibility *ui.Visibility
+}
+
+// rowSource reads the rows of the selector when it opens and again on each
+// refresh, with the details of only the latest rows being read.
+type rowSource struct {
+	client *git.Client
+	opts   cliOptions
+	cfg    config.Config
+	scorer navigator.Scorer
+	store  *history.Store
+	// stopEnrich stops reading the details of the latest rows.
+	stopEnrich context.CancelFunc
+}
+
+// read reads the rows as of now: the current branch followed by recent
+// ones, annotated for every action but unarchive.
+func (s *rowSource) read(ctx context.Context) (branchRows, error) {
+	nav, err := navigator.New(s.client)
+	if err != nil {
+		return branchRows{}, err
+	}
+	nav.SetMaxReflog(s.opts.maxReflog)
+
+	var r branchRows
+	if s.opts.action != actionUnarchive {
+		r.annotator, err = newBranchAnnotator(ctx, s.client, s.opts.action, s.cfg, s.opts.maxReflog, s.opts.filter, s.opts.now, openMetadataCache(ctx, s.client))
+		if err != nil {
+			return branchRows{}, err
+		}
+		toggles := defaultVisibility
+		r.visibility = &toggles
+	}
+	if s.opts.limit == 0 && r.annotator != nil && !s.scorer.UsesHistory() {
+		r.rows, r.load, err = streamBranches(ctx, s.client, nav, r.annotator)
+		return r, err
+	}
+	loadOpts := s.opts
+	if s.opts.filter.active() {
+		// Rank every branch so that the limit applies after filtering.
+		loadOpts.limit = 0
+	}
+	branches, err := loadBranches(ctx, s.client, nav, loadOpts, s.scorer, s.store)
+	switch {
+	case err != nil:
+		return branchRows{}, err
+	case r.annotator != nil:
+		r.rows = r.annotator.annotate(branches, s.opts.limit)
+	default:
+		r.rows = ui.Branches(branches)
+	}
+	return r, nil
+}
+
+// enrich starts reading the slower details of r in the background, after
+// stopping those of the rows it replaces. It returns the updates the
+// selector redraws on, or nil when r has no details to read.
+func (s *rowSource) enrich(ctx context.Context, r branchRows) <-chan struct{} {
+	s.stop()
+	if r.annotator == nil {
+		return nil
+	}
+	var enrichCtx context.Context
+	enrichCtx, s.stopEnrich = context.WithCancel(ctx)
+	go r.annotator.enrich(enrichCtx)
+	return r.annotator.updates
+}
+
+// stop stops reading details, as once a branch is picked.
+func (s *rowSource) stop() {
+	if s.stopEnrich != nil {
+		s.stopEnrich()
+	}
+}
+
+// reloader returns the Reloader behind Ctrl+R and F5, which reads the rows
+// again as of the time of the refresh.
+func (s *rowSource) reloader(ctx context.Context) ui.Reloader {
+	return func() (ui.Reloaded, error) {
+		s.opts.now = time.Now()
+		r, err := s.read(ctx)
+		if err != nil {
+			return ui.Reloaded{}, err
+		}
+		return ui.Reloaded{Rows: r.rows, Load: r.load, Updates: s.enrich(ctx, r)}, nil
+	}
+}
+
+// watchRefs returns a channel announcing each change to the refs of the
+// repository until ctx is done, or nil when its git directory is unknown,
+// in which case the list is only refreshed by hand.
+func watchRefs(ctx context.Context, client *git.Client) <-chan struct{} {
+	info, err := client.RepoInfo(ctx)
+	if err != nil {
+		return nil
+	}
+	return refwatch.Watch(ctx, refwatch.Dirs(info.GitDir, info.CommonDir()), refwatch.DefaultInterval)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+	"branch-navigator/pkg/gittest"
+)
+
+// listedNames returns the names of rows followed by every row load adds.
+func listedNames(t *testing.T, rows ui.Provider, load ui.Loader) []string {
+	t.Helper()
+	var names []string
+	for {
+		for i := 0; i < rows.Len(); i++ {
+			names = append(names, rows.Branch(i).Name)
+		}
+		if load == nil {
+			return names
+		}
+		page, err := load(10)
+		if err != nil {
+			t.Fatalf("load returned error: %v", err)
+		}
+		if page == nil || page.Len() == 0 {
+			return names
+		}
+		rows = page
+	}
+}
+
+func TestRowSourceReloads(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature", "")
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	source := &rowSource{
+		client: client,
+		opts:   cliOptions{action: actionCheckout, maxReflog: navigator.DefaultMaxReflog, now: time.Now()},
+		cfg:    config.Default(),
+		scorer: scorerFor(config.Default()),
+	}
+	ctx := context.Background()
+
+	listed, err := source.read(ctx)
+	if err != nil {
+		t.Fatalf("read returned error: %v", err)
+	}
+	first := source.enrich(ctx, listed)
+	if want := []string{gittest.DefaultBranch, "feature"}; !reflect.DeepEqual(listedNames(t, listed.rows, listed.load), want) {
+		t.Fatalf("listed %v, want %v", listedNames(t, listed.rows, listed.load), want)
+	}
+
+	// Fetched in another terminal, so to speak.
+	repo.Branch("fetched", "")
+	reloaded, err := source.reloader(ctx)()
+	if err != nil {
+		t.Fatalf("reload returned error: %v", err)
+	}
+	got := listedNames(t, reloaded.Rows, reloaded.Load)
+	if len(got) != 3 || got[0] != gittest.DefaultBranch {
+		t.Fatalf("reloaded %v, want the current branch, feature, and fetched", got)
+	}
+	if reloaded.Updates == nil {
+		t.Fatal("expected the details of the new rows to be read")
+	}
+
+	// The details of the replaced rows are no longer read.
+	for range first {
+	}
+	source.stop()
+	for range reloaded.Updates {
+	}
+}
+
+func TestWatchRefsAnnouncesNewBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := watchRefs(ctx, client)
+	if changed == nil {
+		t.Fatal("expected the refs of the repository to be watched")
+	}
+
+	repo.Branch("fetched", "")
+	select {
+	case <-changed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the new branch to be announced")
+	}
+	cancel()
+	for range changed {
+	}
+}
+
+// fetchingRunner runs git in a repository and calls fetch once the local
+// branches have been listed by commit date, like a fetch landing after the
+// rows were read but before the selector opens.
+type fetchingRunner struct {
+	git.Runner
+	once  sync.Once
+	fetch func()
+}
+
+func (r *fetchingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	out, err := r.Runner.Run(ctx, args...)
+	if len(args) > 2 && args[0] == "for-each-ref" && args[1] == "--sort=-committerdate" && args[len(args)-1] == "refs/heads" {
+		r.once.Do(r.fetch)
+	}
+	return out, err
+}
+
+func TestWatchRefsAnnouncesChangeWhileRowsLoad(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	runner := &fetchingRunner{
+		Runner: &git.CLI{Dir: repo.Dir, Env: repo.Env()},
+		fetch:  func() { repo.Branch("fetched", "") },
+	}
+	client := git.NewClient(runner)
+	source := &rowSource{
+		client: client,
+		opts:   cliOptions{action: actionCheckout, maxReflog: navigator.DefaultMaxReflog, now: time.Now()},
+		cfg:    config.Default(),
+		scorer: scorerFor(config.Default()),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// As in main, watching starts before the rows are read.
+	changed := watchRefs(ctx, client)
+	listed, err := source.read(ctx)
+	if err != nil {
+		t.Fatalf("read returned error: %v", err)
+	}
+	if names := listedNames(t, listed.rows, listed.load); strings.Contains(strings.Join(names, " "), "fetched") {
+		t.Fatalf("listed %v, want the branch created after the branches were listed left out", names)
+	}
+	select {
+	case <-changed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the branch created while the rows loaded to be announced")
+	}
+	cancel()
+	for range changed {
+	}
+}
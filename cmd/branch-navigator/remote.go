@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// remoteOperation names an action offered for a selected remote.
+type remoteOperation string
+
+const (
+	remoteFetch  remoteOperation = "fetch"
+	remotePrune  remoteOperation = "prune"
+	remoteSetURL remoteOperation = "set-url"
+)
+
+var remoteOperations = []ui.Branch{
+	{Name: string(remoteFetch), Detail: "download objects and refs from the remote"},
+	{Name: string(remotePrune), Detail: "delete remote-tracking branches that no longer exist"},
+	{Name: string(remoteSetURL), Detail: "change the remote URL"},
+}
+
+// runRemoteAdmin lets the user pick a remote and then an operation to run on it.
+func runRemoteAdmin(ctx context.Context, client *git.Client, style selectorStyle, layout ui.Layout, in io.Reader, out, errOut io.Writer) error {
+	if client == nil {
+		return fmt.Errorf("git client is not configured")
+	}
+
+	remotes, err := client.Remotes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		return errors.New("no remotes are configured")
+	}
+
+	entries := make([]ui.Branch, 0, len(remotes))
+	urls := make(map[string]string, len(remotes))
+	for _, remote := range remotes {
+		entries = append(entries, ui.Branch{Name: remote.Name, Detail: remote.URL})
+		urls[remote.Name] = remote.URL
+	}
+
+	picker := style.selector(in, out, ui.ActionDetails{
+		Name:        "Manage remotes",
+		Description: "Select a remote to fetch, prune, or change its URL.",
+		EnterLabel:  "choose an operation",
+	})
+	picker.SetLayout(layout)
+	picked, err := picker.Select(entries)
+	if err != nil {
+		return err
+	}
+	if picked.Quit {
+		return nil
+	}
+	remote := picked.Branch
+
+	menu := style.selector(in, out, ui.ActionDetails{
+		Name:        "Remote " + remote,
+		Description: urls[remote],
+		EnterLabel:  "run the operation",
+	})
+	menu.SetLayout(layout)
+	operation, err := menu.Select(remoteOperations)
+	if err != nil {
+		return err
+	}
+	if operation.Quit {
+		return nil
+	}
+
+	var result git.RemoteResult
+	switch remoteOperation(operation.Branch) {
+	case remoteFetch:
+		err = networkProgress(ctx, client, style, errOut, "Fetching "+remote, func(ctx context.Context) error {
+			var err error
+			result, err = client.FetchRemote(ctx, remote)
+			return err
+		})
+	case remotePrune:
+		err = networkProgress(ctx, client, style, errOut, "Pruning "+remote, func(ctx context.Context) error {
+			var err error
+			result, err = client.PruneRemote(ctx, remote)
+			return err
+		})
+	case remoteSetURL:
+		url, promptErr := promptLine(in, out, fmt.Sprintf("New URL for '%s': ", remote))
+		if promptErr != nil {
+			return promptErr
+		}
+		if url == "" {
+			return errors.New("remote URL update aborted")
+		}
+		result, err = client.SetRemoteURL(ctx, remote, url)
+		if err == nil {
+			fmt.Fprintf(out, "Updated '%s' URL to %s\n", remote, url)
+		}
+	default:
+		return fmt.Errorf("unknown remote operation %q", operation.Branch)
+	}
+
+	printIfNotEmpty(out, result.Stdout)
+	printIfNotEmpty(errOut, result.Stderr)
+	return err
+}
+
+// defaultRemoteKey is the git config key naming, per repository, the remote
+// taken without asking when several could take an action.
+const defaultRemoteKey = "branch-navigator.defaultRemote"
+
+// remotePicker asks which remote an action should use when more than one
+// could take it.
+type remotePicker struct {
+	style  selectorStyle
+	layout ui.Layout
+	in     io.Reader
+	out    io.Writer
+}
+
+// chooseRemote returns the remote among candidates that an action should
+// use. A single candidate is taken as is; among several, the repository's
+// branch-navigator.defaultRemote, then git's checkout.defaultRemote, is
+// taken when it is one of them, and otherwise picker asks. Without a
+// picker, as for --multi, origin is taken when it is a candidate. It
+// returns "" when no remote was chosen, and origin when there are no
+// candidates, leaving git to report that it does not exist.
+func chooseRemote(ctx context.Context, client *git.Client, picker *remotePicker, title string, candidates []git.Remote) (string, error) {
+	switch len(candidates) {
+	case 0:
+		return git.DefaultRemote, nil
+	case 1:
+		return candidates[0].Name, nil
+	}
+	isCandidate := func(name string) bool {
+		for _, remote := range candidates {
+			if remote.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, setting := range []struct {
+		scope git.ConfigScope
+		key   string
+	}{
+		{git.ConfigLocal, defaultRemoteKey},
+		{git.ConfigMerged, "checkout.defaultRemote"},
+	} {
+		value, ok, err := client.ConfigValue(ctx, setting.scope, setting.key)
+		if err != nil {
+			return "", err
+		}
+		if name := strings.TrimSpace(value); ok && isCandidate(name) {
+			return name, nil
+		}
+	}
+	if picker == nil {
+		if isCandidate(git.DefaultRemote) {
+			return git.DefaultRemote, nil
+		}
+		return "", nil
+	}
+
+	entries := make([]ui.Branch, 0, len(candidates))
+	for _, remote := range candidates {
+		entries = append(entries, ui.Branch{Name: remote.Name, Detail: remote.URL})
+	}
+	selector := picker.style.selector(picker.in, picker.out, ui.ActionDetails{
+		Name:        title,
+		Description: fmt.Sprintf("Several remotes could take it; set %s to skip this question.", defaultRemoteKey),
+		EnterLabel:  "use this remote",
+	})
+	selector.SetLayout(picker.layout)
+	picked, err := selector.Select(entries)
+	if err != nil || picked.Quit {
+		return "", err
+	}
+	return picked.Branch, nil
+}
+
+// promptLine writes prompt and returns the trimmed line typed in response.
+func promptLine(in io.Reader, out io.Writer, prompt string) (string, error) {
+	if _, err := fmt.Fprint(out, prompt); err != nil {
+		return "", err
+	}
+
+	reader := ui.LineReader(in)
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
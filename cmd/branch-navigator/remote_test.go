@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// oneByteReader hands out input a byte at a time so consecutive prompts each
+// see only the keys meant for them.
+type oneByteReader struct {
+	r *strings.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
+
+func newKeys(keys string) *oneByteReader {
+	return &oneByteReader{r: strings.NewReader(keys)}
+}
+
+type recordingRunner struct {
+	outputs map[string]string
+	mu      sync.Mutex
+	calls   [][]string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, args)
+	return r.outputs[strings.Join(args, " ")], nil
+}
+
+func TestRunRemoteAdmin(t *testing.T) {
+	t.Parallel()
+
+	remoteList := "origin\tgit@example.com:org/repo.git (fetch)\norigin\tgit@example.com:org/repo.git (push)\nupstream\thttps://example.com/up.git (fetch)"
+
+	cases := map[string]struct {
+		keys      string
+		wantCalls [][]string
+		wantOut   string
+	}{
+		"fetch-origin": {
+			keys:      "\r\r",
+			wantCalls: [][]string{{"remote", "-v"}, {"fetch", "origin"}},
+		},
+		"prune-upstream": {
+			keys:      "j\rj\r",
+			wantCalls: [][]string{{"remote", "-v"}, {"remote", "prune", "upstream"}},
+		},
+		"set-url": {
+			keys:      "\rjj\rgit@example.com:new.git\n",
+			wantCalls: [][]string{{"remote", "-v"}, {"remote", "set-url", "origin", "git@example.com:new.git"}},
+			wantOut:   "Updated 'origin' URL to git@example.com:new.git",
+		},
+		"quit": {
+			keys:      "q",
+			wantCalls: [][]string{{"remote", "-v"}},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"remote -v": remoteList}}
+			out := &bytes.Buffer{}
+			err := runRemoteAdmin(context.Background(), git.NewClient(runner), testStyle, ui.LayoutAuto, newKeys(tc.keys), out, &bytes.Buffer{})
+			if err != nil {
+				t.Fatalf("runRemoteAdmin returned error: %v", err)
+			}
+			if !reflect.DeepEqual(runner.calls, tc.wantCalls) {
+				t.Fatalf("unexpected git calls: got %v, want %v", runner.calls, tc.wantCalls)
+			}
+			if tc.wantOut != "" && !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+		})
+	}
+}
+
+func TestRunRemoteAdminPlain(t *testing.T) {
+	t.Parallel()
+
+	runner := &recordingRunner{outputs: map[string]string{"remote -v": "origin\tgit@example.com:org/repo.git (fetch)\nupstream\thttps://example.com/up.git (fetch)"}}
+	out := &bytes.Buffer{}
+	err := runRemoteAdmin(context.Background(), git.NewClient(runner), testStyle, ui.LayoutPlain, newKeys("2\n2\n"), out, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("runRemoteAdmin returned error: %v", err)
+	}
+	want := [][]string{{"remote", "-v"}, {"remote", "prune", "upstream"}}
+	if !reflect.DeepEqual(runner.calls, want) {
+		t.Fatalf("unexpected git calls: got %v, want %v", runner.calls, want)
+	}
+	if !strings.Contains(out.String(), "2) upstream https://example.com/up.git\n") || strings.Contains(out.String(), "\033") {
+		t.Fatalf("unexpected plain output %q", out.String())
+	}
+}
+
+func TestRunRemoteAdminWithoutRemotes(t *testing.T) {
+	t.Parallel()
+
+	runner := &recordingRunner{}
+	err := runRemoteAdmin(context.Background(), git.NewClient(runner), testStyle, ui.LayoutAuto, newKeys(""), &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "no remotes") {
+		t.Fatalf("expected no remotes error, got %v", err)
+	}
+}
+
+func TestChooseRemote(t *testing.T) {
+	t.Parallel()
+
+	origin := git.Remote{Name: "origin", URL: "git@example.com:org/repo.git"}
+	upstream := git.Remote{Name: "upstream", URL: "https://example.com/up.git"}
+	fork := git.Remote{Name: "fork", URL: "https://example.com/fork.git"}
+	localDefault := "config --local --get " + defaultRemoteKey
+	checkoutDefault := "config --includes --get checkout.defaultRemote"
+
+	cases := map[string]struct {
+		candidates []git.Remote
+		outputs    map[string]string
+		// keys answer the plain picker; without any there is no picker.
+		keys    string
+		want    string
+		wantOut string
+	}{
+		"no remotes":         {want: "origin"},
+		"one remote":         {candidates: []git.Remote{upstream}, want: "upstream"},
+		"repository default": {candidates: []git.Remote{origin, upstream}, outputs: map[string]string{localDefault: "upstream", checkoutDefault: "origin"}, want: "upstream"},
+		"checkout default":   {candidates: []git.Remote{origin, upstream}, outputs: map[string]string{localDefault: "gone", checkoutDefault: "upstream"}, want: "upstream"},
+		"without a picker":   {candidates: []git.Remote{upstream, origin}, want: "origin"},
+		"without origin":     {candidates: []git.Remote{upstream, fork}, want: ""},
+		"picked":             {candidates: []git.Remote{origin, upstream}, keys: "2\n", want: "upstream", wantOut: "2) upstream https://example.com/up.git\n"},
+		"picker quit":        {candidates: []git.Remote{origin, upstream}, keys: "q\n", want: ""},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			var picker *remotePicker
+			if tc.keys != "" {
+				picker = &remotePicker{style: testStyle, layout: ui.LayoutPlain, in: newKeys(tc.keys), out: out}
+			}
+			got, err := chooseRemote(context.Background(), git.NewClient(&recordingRunner{outputs: tc.outputs}), picker, "Push feature/x to", tc.candidates)
+			if err != nil {
+				t.Fatalf("chooseRemote returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("chooseRemote() = %q, want %q", got, tc.want)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// maxReportedNames caps the branches the report table names per state.
+const maxReportedNames = 5
+
+// branchReport summarizes the health of the local branches for the report
+// subcommand. A branch can be in several states, such as merged and stale.
+type branchReport struct {
+	// Base is the branch merged state is checked against; "" when none
+	// was found, which leaves Merged empty.
+	Base  string `json:"base"`
+	Total int    `json:"total"`
+	// Merged branches are merged into Base.
+	Merged reportGroup `json:"merged"`
+	// Stale branches have no commits for stale.after_days.
+	Stale reportGroup `json:"stale"`
+	// Gone branches track an upstream that no longer exists.
+	Gone reportGroup `json:"gone"`
+	// Unpushed branches are ahead of their upstream and not behind it, or
+	// are not merged and have no upstream.
+	Unpushed reportGroup `json:"unpushed"`
+	// Diverged branches are both ahead of and behind their upstream.
+	Diverged reportGroup `json:"diverged"`
+}
+
+// reportGroup lists the branches in one state, most recent commit first.
+type reportGroup struct {
+	Count    int      `json:"count"`
+	Branches []string `json:"branches"`
+}
+
+func (g *reportGroup) add(branch string) {
+	g.Count++
+	g.Branches = append(g.Branches, branch)
+}
+
+// runReportCommand implements the report subcommand and returns the process exit code.
+func runReportCommand(ctx context.Context, client *git.Client, cfg config.Config, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator report", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(out, reportCommand.Usage(programName))
+	}
+	asJSON := fs.Bool("json", false, reportCommand.FlagUsage("json"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprint(errOut, reportCommand.Usage(programName))
+		return 2
+	}
+
+	report, err := buildBranchReport(ctx, client, cfg, time.Now(), openMetadataCache(ctx, client))
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = writeReportTable(out, report)
+	}
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+// buildBranchReport reads the same metadata as the selector's labels,
+// reusing what cache holds, and sorts the local branches into states.
+func buildBranchReport(ctx context.Context, client *git.Client, cfg config.Config, now time.Time, cache *metadataCache) (branchReport, error) {
+	annotator, err := newBranchAnnotator(ctx, client, actionCheckout, cfg, navigator.DefaultMaxReflog, branchFilter{}, now, cache)
+	if err != nil {
+		return branchReport{}, err
+	}
+	annotator.enrich(ctx)
+	names, err := client.BranchesByCommitDate(ctx)
+	if err != nil {
+		return branchReport{}, err
+	}
+	branches := make([]ui.Branch, len(names))
+	for i, name := range names {
+		branches[i] = ui.Branch{Name: name}
+	}
+	annotator.classify(branches)
+
+	report := branchReport{Base: annotator.base, Total: len(branches)}
+	for _, group := range []*reportGroup{&report.Merged, &report.Stale, &report.Gone, &report.Unpushed, &report.Diverged} {
+		// Empty states are listed as [] rather than null.
+		group.Branches = []string{}
+	}
+	for _, branch := range branches {
+		if branch.Merged {
+			report.Merged.add(branch.Name)
+		}
+		if branch.Stale {
+			report.Stale.add(branch.Name)
+		}
+		switch track := annotator.tracks[branch.Name]; {
+		case track.Gone:
+			report.Gone.add(branch.Name)
+		case track.Ahead > 0 && track.Behind > 0:
+			report.Diverged.add(branch.Name)
+		case isUnpushed(track, branch.Merged || branch.Name == report.Base):
+			report.Unpushed.add(branch.Name)
+		}
+	}
+	return report, nil
+}
+
+func writeReportTable(out io.Writer, report branchReport) error {
+	base := report.Base
+	if base == "" {
+		base = "none found; merged branches are not counted"
+	}
+	fmt.Fprintf(out, "%d local %s (base: %s)\n\n", report.Total, plural(report.Total, "branch", "branches"), base)
+	w := ui.NewColumnWriter(out, 2)
+	fmt.Fprintln(w, "STATE\tCOUNT\tBRANCHES")
+	for _, row := range []struct {
+		state string
+		group reportGroup
+	}{
+		{"merged", report.Merged},
+		{"stale", report.Stale},
+		{"upstream gone", report.Gone},
+		{"unpushed", report.Unpushed},
+		{"diverged", report.Diverged},
+	} {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", row.state, row.group.Count, reportedNames(row.group.Branches))
+	}
+	return w.Flush()
+}
+
+// reportedNames joins up to maxReportedNames branches, noting how many more
+// there are.
+func reportedNames(branches []string) string {
+	if len(branches) <= maxReportedNames {
+		return strings.Join(branches, ", ")
+	}
+	return fmt.Sprintf("%s, … and %d more", strings.Join(branches[:maxReportedNames], ", "), len(branches)-maxReportedNames)
+}
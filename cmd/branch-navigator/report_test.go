@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestBuildBranchReport(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("old", "")
+	repo.SetNow(gittest.Epoch.AddDate(0, 0, 30))
+	repo.Commit("main work")
+	repo.AddRemote("origin")
+	repo.Push("origin", gittest.DefaultBranch)
+
+	// unpushed is one commit ahead of its upstream.
+	repo.CheckoutNew("unpushed")
+	repo.Git("push", "--quiet", "-u", "origin", "unpushed")
+	repo.Commit("local work")
+	// diverged has a local commit and another one on the remote.
+	repo.Checkout(gittest.DefaultBranch)
+	repo.CheckoutNew("diverged")
+	repo.Git("push", "--quiet", "-u", "origin", "diverged")
+	repo.Commit("local change")
+	repo.Git("checkout", "--quiet", "-b", "elsewhere", "origin/diverged")
+	repo.Commit("remote change")
+	repo.Git("push", "--quiet", "origin", "elsewhere:diverged")
+	// gone tracks a branch deleted from the remote.
+	repo.Checkout(gittest.DefaultBranch)
+	repo.CheckoutNew("gone")
+	repo.Commit("gone work")
+	repo.Git("push", "--quiet", "-u", "origin", "gone")
+	repo.Git("push", "--quiet", "origin", "--delete", "gone")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.Git("branch", "--quiet", "-D", "elsewhere")
+
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	cfg := config.Default()
+	cfg.StaleAfterDays = 7
+	report, err := buildBranchReport(context.Background(), client, cfg, repo.Now(), nil)
+	if err != nil {
+		t.Fatalf("buildBranchReport returned error: %v", err)
+	}
+
+	want := branchReport{
+		Base:     gittest.DefaultBranch,
+		Total:    5,
+		Merged:   reportGroup{Count: 1, Branches: []string{"old"}},
+		Stale:    reportGroup{Count: 1, Branches: []string{"old"}},
+		Gone:     reportGroup{Count: 1, Branches: []string{"gone"}},
+		Unpushed: reportGroup{Count: 1, Branches: []string{"unpushed"}},
+		Diverged: reportGroup{Count: 1, Branches: []string{"diverged"}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("buildBranchReport() = %+v, want %+v", report, want)
+	}
+
+	out := &bytes.Buffer{}
+	if err := writeReportTable(out, report); err != nil {
+		t.Fatalf("writeReportTable returned error: %v", err)
+	}
+	for _, line := range []string{"5 local branches (base: main)\n", "upstream gone  1      gone\n", "diverged       1      diverged\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Fatalf("table lacks %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestBranchReportJSON(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	report, err := buildBranchReport(context.Background(), client, config.Default(), repo.Now(), nil)
+	if err != nil {
+		t.Fatalf("buildBranchReport returned error: %v", err)
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if want := `{"base":"main","total":1,"merged":{"count":0,"branches":[]},"stale":{"count":0,"branches":[]},"gone":{"count":0,"branches":[]},"unpushed":{"count":0,"branches":[]},"diverged":{"count":0,"branches":[]}}`; string(data) != want {
+		t.Fatalf("JSON = %s, want %s", data, want)
+	}
+}
+
+func TestReportTableNamesAFewBranches(t *testing.T) {
+	t.Parallel()
+
+	got := reportedNames([]string{"a", "b", "c", "d", "e", "f", "g"})
+	if want := "a, b, c, d, e, … and 2 more"; got != want {
+		t.Fatalf("reportedNames() = %q, want %q", got, want)
+	}
+}
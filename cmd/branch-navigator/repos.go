@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+	"branch-navigator/internal/ui"
+)
+
+// clientFactory builds a git client operating in the given repository directory.
+type clientFactory func(dir string) *git.Client
+
+// runReposCommand implements the repos subcommand and returns the process exit code.
+func runReposCommand(ctx context.Context, store *history.Store, newClient clientFactory, cfg config.Config, style selectorStyle, args []string, in io.Reader, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator repos", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, reposCommand.Usage(programName))
+	}
+	limit := fs.Int("n", 10, reposCommand.FlagUsage("n"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *limit <= 0 {
+		fmt.Fprintln(errOut, "limit must be greater than 0")
+		return 2
+	}
+	if store == nil {
+		fmt.Fprintln(errOut, "history store is not available")
+		return 1
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	repos := make([]ui.Branch, 0)
+	for _, visit := range history.Repos(entries) {
+		if info, err := os.Stat(visit.Path); err == nil && info.IsDir() {
+			repos = append(repos, ui.Branch{Name: visit.Path})
+		}
+	}
+	if len(repos) == 0 {
+		fmt.Fprintln(errOut, "no repositories recorded yet; use branch-navigator inside a repository first")
+		return 1
+	}
+
+	picker := style.selector(in, errOut, ui.ActionDetails{
+		Name:        "Switch repository",
+		Description: "Select a recently used repository.",
+		EnterLabel:  "choose a branch in the repository",
+	})
+	picked, err := picker.Select(repos)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if picked.Quit {
+		return 0
+	}
+	repo := picked.Branch
+
+	client := newClient(repo)
+	nav, err := navigator.New(client)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	branches, err := loadBranches(ctx, client, nav, cliOptions{action: actionCheckout, limit: *limit, now: time.Now()}, scorerFor(cfg), store)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	selector := style.selector(in, errOut, actionDetailsFor(actionCheckout))
+	selector.SetRepository(filepath.Base(repo))
+	result, err := selector.Select(branches)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if result.Quit {
+		return 0
+	}
+
+	if !result.AlreadyOn {
+		message, err := client.CheckoutBranch(ctx, result.Branch)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		printIfNotEmpty(errOut, message)
+		recordAction(ctx, store, client, errOut, actionCheckout, branches[0].Name, result.Branch)
+	}
+
+	fmt.Fprintln(out, repo)
+	return 0
+}
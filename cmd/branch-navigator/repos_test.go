@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+)
+
+func TestRunReposCommand(t *testing.T) {
+	t.Parallel()
+
+	older := t.TempDir()
+	newer := t.TempDir()
+	store := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	for _, entry := range []history.Entry{
+		{Time: time.Unix(100, 0), Repo: older, Action: "checkout", Branch: "a"},
+		{Time: time.Unix(200, 0), Repo: newer, Action: "checkout", Branch: "b"},
+		{Time: time.Unix(300, 0), Repo: filepath.Join(older, "missing"), Action: "checkout", Branch: "c"},
+	} {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	runner := &recordingRunner{outputs: map[string]string{
+		"rev-parse --abbrev-ref HEAD": "main",
+		"rev-parse --show-toplevel":   older,
+		"for-each-ref --format=%(refname:lstrip=2) --sort=-committerdate refs/heads": "main\nfeature/x",
+		"branch --list --format=%(refname:lstrip=2)":                                 "feature/x\nmain",
+	}}
+	var openedDir string
+	factory := func(dir string) *git.Client {
+		openedDir = dir
+		return git.NewClient(runner)
+	}
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	// Move to the older repository, pick it, then pick the second branch.
+	code := runReposCommand(context.Background(), store, factory, config.Default(), testStyle, nil, newKeys("j\rj\r"), out, errOut)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr %q", code, errOut.String())
+	}
+	if openedDir != older {
+		t.Fatalf("client opened in %q, want %q", openedDir, older)
+	}
+	if got := strings.TrimSpace(out.String()); got != older {
+		t.Fatalf("stdout = %q, want repository path %q", got, older)
+	}
+	if !reflect.DeepEqual(runner.calls[len(runner.calls)-2], []string{"checkout", "feature/x"}) {
+		t.Fatalf("expected checkout of feature/x, calls %v", runner.calls)
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("Entries returned error: %v", err)
+	}
+	last := entries[len(entries)-1]
+	if last.Repo != older || last.From != "main" || last.Branch != "feature/x" || last.Action != "checkout" {
+		t.Fatalf("unexpected recorded entry: %+v", last)
+	}
+}
+
+func TestRunReposCommandWithoutHistory(t *testing.T) {
+	t.Parallel()
+
+	store := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	errOut := &bytes.Buffer{}
+	code := runReposCommand(context.Background(), store, git.NewDefaultClientAt, config.Default(), testStyle, nil, newKeys(""), &bytes.Buffer{}, errOut)
+	if code != 1 || !strings.Contains(errOut.String(), "no repositories recorded yet") {
+		t.Fatalf("unexpected result: code %d, stderr %q", code, errOut.String())
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+var resetModes = []ui.Branch{
+	{Name: string(git.ResetSoft), Detail: "move the branch only; its changes stay staged"},
+	{Name: string(git.ResetMixed), Detail: "also unstage the changes; the working tree is kept"},
+	{Name: string(git.ResetHard), Detail: "also discard every uncommitted change to tracked files"},
+}
+
+// handleResetAction resets the current branch to the tip of target. It asks
+// for the reset mode, lists the commits and, for --hard, the uncommitted
+// changes that would be lost, and asks for confirmation: y for --soft and
+// --mixed, the current branch name for --hard. It reports false when the user
+// backed out.
+func handleResetAction(ctx context.Context, client *git.Client, style selectorStyle, layout ui.Layout, in io.Reader, out io.Writer, target string) (bool, error) {
+	if client == nil {
+		return false, fmt.Errorf("git client is not configured")
+	}
+
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	menu := style.selector(in, out, ui.ActionDetails{
+		Name:        fmt.Sprintf("Reset '%s' to '%s'", current, target),
+		Description: "Choose what happens to the index and the working tree.",
+		EnterLabel:  "reset with the selected mode",
+	})
+	menu.SetLayout(layout)
+	picked, err := menu.Select(resetModes)
+	if err != nil {
+		return false, err
+	}
+	if picked.Quit {
+		return false, nil
+	}
+	mode := git.ResetMode(picked.Branch)
+
+	impact, err := client.ResetImpact(ctx, target)
+	if err != nil {
+		return false, err
+	}
+	warning := resetWarning(current, target, mode, impact)
+
+	var confirmed bool
+	if mode == git.ResetHard {
+		confirmed, err = ui.TypedConfirmation{Warning: warning, Token: current}.Confirm(in, out)
+	} else {
+		confirmed, err = ui.Confirmation{Summary: warning}.Confirm(in, out)
+	}
+	if err != nil || !confirmed {
+		return false, err
+	}
+
+	message, err := client.Reset(ctx, target, mode)
+	if err != nil {
+		return false, err
+	}
+	printIfNotEmpty(out, message)
+	fmt.Fprintf(out, "To undo the reset, run: git reset --%s ORIG_HEAD\n", mode)
+	return true, nil
+}
+
+// resetWarning describes what resetting current to target in mode takes away.
+func resetWarning(current, target string, mode git.ResetMode, impact git.ResetImpact) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "git reset --%s %s moves '%s' to the tip of '%s'.\n", mode, target, current, target)
+	if len(impact.Commits) == 0 {
+		fmt.Fprintf(&b, "'%s' has no commits that '%s' lacks.\n", current, target)
+	} else {
+		fmt.Fprintf(&b, "These commits will no longer be on '%s':\n", current)
+		for _, commit := range impact.Commits {
+			fmt.Fprintf(&b, "  %s\n", commit)
+		}
+	}
+
+	switch mode {
+	case git.ResetSoft:
+		b.WriteString("Their changes stay staged.")
+	case git.ResetMixed:
+		b.WriteString("Their changes stay in the working tree, unstaged.")
+	case git.ResetHard:
+		if len(impact.Changes) > 0 {
+			b.WriteString("These uncommitted changes will be DISCARDED and cannot be recovered:\n")
+			for _, change := range impact.Changes {
+				fmt.Fprintf(&b, "  %s\n", change)
+			}
+		}
+		b.WriteString("Their changes are discarded from the working tree as well.")
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+func TestHandleResetAction(t *testing.T) {
+	t.Parallel()
+
+	outputs := map[string]string{
+		"rev-parse --abbrev-ref HEAD":              "feature",
+		"log --no-color --format=%h %s main..HEAD": "abc1234 Add cache",
+		"status --porcelain --untracked-files=no":  " M README.md",
+		"reset --hard main":                        "HEAD is now at def5678 Release",
+	}
+	impactCalls := [][]string{
+		{"rev-parse", "--abbrev-ref", "HEAD"},
+		{"log", "--no-color", "--format=%h %s", "main..HEAD"},
+		{"status", "--porcelain", "--untracked-files=no"},
+	}
+
+	cases := map[string]struct {
+		keys      string
+		want      bool
+		wantCalls [][]string
+		wantOut   []string
+	}{
+		"soft": {
+			keys:      "1\ny\n",
+			want:      true,
+			wantCalls: append(impactCalls, []string{"reset", "--soft", "main"}),
+			wantOut:   []string{"  abc1234 Add cache\n", "Their changes stay staged.", "git reset --soft ORIG_HEAD"},
+		},
+		"mixed declined": {
+			keys:      "2\n\n",
+			wantCalls: impactCalls,
+			wantOut:   []string{"Their changes stay in the working tree, unstaged."},
+		},
+		"hard": {
+			keys:      "3\nfeature\n",
+			want:      true,
+			wantCalls: append(impactCalls, []string{"reset", "--hard", "main"}),
+			wantOut:   []string{"DISCARDED", "  M README.md\n", "Type feature to confirm", "HEAD is now at def5678 Release"},
+		},
+		"hard answered y": {
+			keys:      "3\ny\n",
+			wantCalls: impactCalls,
+			wantOut:   []string{`"y" does not match "feature"`},
+		},
+		"quit": {
+			keys:      "q\n",
+			wantCalls: [][]string{{"rev-parse", "--abbrev-ref", "HEAD"}},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: outputs}
+			out := &bytes.Buffer{}
+			got, err := handleResetAction(context.Background(), git.NewClient(runner), testStyle, ui.LayoutPlain, newKeys(tc.keys), out, "main")
+			if err != nil {
+				t.Fatalf("handleResetAction returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("handleResetAction() = %v, want %v", got, tc.want)
+			}
+			if !reflect.DeepEqual(runner.calls, tc.wantCalls) {
+				t.Fatalf("unexpected git calls: got %v, want %v", runner.calls, tc.wantCalls)
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("expected output to contain %q, got %q", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"branch-navigator/internal/ui"
+)
+
+// The outcomes a result reports.
+const (
+	// outcomeDone means the action ran, or with --print that its command
+	// was printed.
+	outcomeDone = "done"
+	// outcomeQuit means nothing was picked.
+	outcomeQuit = "quit"
+	// outcomeCancelled means a branch was picked but a later question,
+	// such as a confirmation, was answered no.
+	outcomeCancelled = "cancelled"
+	// outcomeFailed means the run ended with an error.
+	outcomeFailed = "failed"
+)
+
+// runResult is the JSON object written to --result-file or --result-fd when
+// a run ends, apart from the output meant for people.
+type runResult struct {
+	Action string `json:"action"`
+	// Branch is the picked branch, and Remote reports whether it is a
+	// remote-tracking one.
+	Branch string `json:"branch,omitempty"`
+	Remote bool   `json:"remote,omitempty"`
+	// Branches lists the branches marked with --multi.
+	Branches []string `json:"branches,omitempty"`
+	// Target is the branch the action made, such as the one --create or
+	// --copy created, when it is not Branch.
+	Target string `json:"target,omitempty"`
+	// Worktree is the worktree a bare repository checked the branch out in.
+	Worktree string `json:"worktree,omitempty"`
+	Outcome  string `json:"outcome"`
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// resultChannel writes the runResult of a run, once, to the file of
+// --result-file or the descriptor of --result-fd. A nil channel writes
+// nothing.
+type resultChannel struct {
+	w       io.WriteCloser
+	errOut  io.Writer
+	result  runResult
+	written bool
+}
+
+// openResultChannel opens the destination named by opts, or returns nil when
+// none is named. The file of --result-file is created up front so that a
+// bad path fails before anything runs; a failure to write the result later
+// is reported on errOut.
+func openResultChannel(opts cliOptions, errOut io.Writer) (*resultChannel, error) {
+	var w io.WriteCloser
+	switch {
+	case opts.resultFile != "":
+		f, err := os.Create(opts.resultFile)
+		if err != nil {
+			return nil, fmt.Errorf("--result-file: %w", err)
+		}
+		w = f
+	case opts.resultFD != 0:
+		f := os.NewFile(uintptr(opts.resultFD), fmt.Sprintf("fd %d", opts.resultFD))
+		if f == nil {
+			return nil, fmt.Errorf("--result-fd %d: not an open file descriptor", opts.resultFD)
+		}
+		if _, err := f.Stat(); err != nil {
+			// Closing the unusable descriptor keeps the finalizer of f from
+			// closing a file opened later under the same number.
+			f.Close()
+			return nil, fmt.Errorf("--result-fd %d: not an open file descriptor", opts.resultFD)
+		}
+		w = f
+	default:
+		return nil, nil
+	}
+	return &resultChannel{w: w, errOut: errOut, result: runResult{Action: string(opts.action)}}, nil
+}
+
+// pick records the selection the action applies to.
+func (c *resultChannel) pick(act action, result ui.Result, multi bool) {
+	if c == nil {
+		return
+	}
+	c.result.Action = string(act)
+	if multi {
+		c.result.Branches = result.Marked
+		return
+	}
+	c.result.Branch = result.Branch
+	c.result.Remote = result.Remote
+}
+
+// made records the branch the action made when it is not the picked one.
+func (c *resultChannel) made(target string) {
+	if c != nil && target != c.result.Branch {
+		c.result.Target = target
+	}
+}
+
+// openedWorktree records the worktree the branch was checked out in.
+func (c *resultChannel) openedWorktree(path string) {
+	if c != nil {
+		c.result.Worktree = path
+	}
+}
+
+// finish writes the result with its outcome and the exit code of the run,
+// and closes the destination. Only the first call writes, so a deferred
+// call covers every return that did not finish already.
+func (c *resultChannel) finish(outcome string, code int, err error) {
+	if c == nil || c.written {
+		return
+	}
+	c.written = true
+	c.result.Outcome = outcome
+	c.result.ExitCode = code
+	if err != nil {
+		c.result.Error = err.Error()
+	}
+	data, err := json.Marshal(c.result)
+	if err == nil {
+		_, err = c.w.Write(append(data, '\n'))
+	}
+	if closeErr := c.w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(c.errOut, "warning: the result was not written: %v\n", err)
+	}
+}
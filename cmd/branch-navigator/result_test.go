@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/ui"
+)
+
+func TestResultChannelWritesOnce(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "result.json")
+	results, err := openResultChannel(cliOptions{action: actionCreate, resultFile: path}, io.Discard)
+	if err != nil {
+		t.Fatalf("openResultChannel returned error: %v", err)
+	}
+	results.pick(actionCreate, ui.Result{Branch: "origin/main", Remote: true}, false)
+	results.made("feature/login")
+	results.finish(outcomeDone, 0, nil)
+	results.finish(outcomeFailed, 1, errors.New("too late"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	var got runResult
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, data)
+	}
+	want := runResult{Action: "create", Branch: "origin/main", Remote: true, Target: "feature/login", Outcome: outcomeDone}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("result = %+v, want %+v", got, want)
+	}
+	if strings.Count(string(data), "\n") != 1 {
+		t.Fatalf("expected a single JSON line, got %q", data)
+	}
+}
+
+func TestResultChannelMarkedBranches(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "result.json")
+	results, err := openResultChannel(cliOptions{action: actionMergeCheck, resultFile: path}, io.Discard)
+	if err != nil {
+		t.Fatalf("openResultChannel returned error: %v", err)
+	}
+	results.pick(actionMergeCheck, ui.Result{Marked: []string{"a", "b"}}, true)
+	results.finish(outcomeFailed, 1, errors.New("merge-check failed for 1 of 2 branches"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	want := `{"action":"merge-check","branches":["a","b"],"outcome":"failed","exitCode":1,"error":"merge-check failed for 1 of 2 branches"}` + "\n"
+	if string(data) != want {
+		t.Fatalf("result = %s, want %s", data, want)
+	}
+}
+
+func TestResultChannelNone(t *testing.T) {
+	t.Parallel()
+
+	results, err := openResultChannel(cliOptions{}, io.Discard)
+	if err != nil || results != nil {
+		t.Fatalf("openResultChannel() = %v, %v; want no channel", results, err)
+	}
+	// A nil channel ignores every call.
+	results.pick(actionCheckout, ui.Result{Branch: "main"}, false)
+	results.finish(outcomeDone, 0, nil)
+
+	errOut := &bytes.Buffer{}
+	if _, err := openResultChannel(cliOptions{resultFD: 1000}, errOut); err == nil || !strings.Contains(err.Error(), "not an open file descriptor") {
+		t.Fatalf("expected a closed descriptor to be rejected, got %v", err)
+	}
+}
+
+func TestParseArgsResultChannel(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"file":     {args: []string{"--result-file", "out.json"}},
+		"fd":       {args: []string{"--result-fd", "3"}},
+		"both":     {args: []string{"--result-file", "out.json", "--result-fd", "3"}, wantErr: "--result-file and --result-fd cannot be combined"},
+		"terminal": {args: []string{"--result-fd", "1"}, wantErr: "--result-fd must be 3 or higher"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseArgs(tc.args, io.Discard, io.Discard)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("parseArgs returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
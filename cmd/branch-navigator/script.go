@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"branch-navigator/internal/ui"
+
+	"golang.org/x/term"
+)
+
+// scriptEnv names the environment variable read when --script is not given,
+// for demo recordings that cannot change the command line.
+const scriptEnv = "BRANCH_NAVIGATOR_SCRIPT"
+
+// sharedStdin returns the reader the selector and every prompt after it
+// share for one run. Piped input is buffered once, so that the lines the
+// selector has not read stay there for the confirmations; a terminal is
+// returned as is, so that the selector can switch it to raw mode.
+func sharedStdin(file *os.File) io.Reader {
+	if term.IsTerminal(int(file.Fd())) {
+		return file
+	}
+	return bufio.NewReader(file)
+}
+
+// selectorInput returns where the branch selector reads its keys: the
+// keystrokes of opts.script when it is set, and stdin otherwise. The script
+// drives the selector only; later prompts still read stdin.
+func selectorInput(opts cliOptions, stdin io.Reader) (io.Reader, error) {
+	if opts.script == "" {
+		return stdin, nil
+	}
+	if opts.plain || opts.pick != "" || opts.stdin {
+		return nil, fmt.Errorf("--script drives the interactive selector and cannot be used with --plain, --pick, or --stdin")
+	}
+	keys, err := ui.ParseScript(opts.script)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(keys), nil
+}
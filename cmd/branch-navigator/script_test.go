@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/ui"
+)
+
+func TestSelectorInput(t *testing.T) {
+	t.Parallel()
+
+	stdin := strings.NewReader("typed")
+	cases := map[string]struct {
+		opts    cliOptions
+		want    string
+		wantErr string
+	}{
+		"stdin without a script": {want: "typed"},
+		"script keys":            {opts: cliOptions{script: "j / a enter"}, want: "j/a\r"},
+		"unknown key":            {opts: cliOptions{script: "ctrl-f1"}, wantErr: `unknown key "ctrl-f1" in script`},
+		"with plain":             {opts: cliOptions{script: "enter", plain: true}, wantErr: "cannot be used with --plain, --pick, or --stdin"},
+		"with pick":              {opts: cliOptions{script: "enter", pick: "main"}, wantErr: "cannot be used with --plain, --pick, or --stdin"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input, err := selectorInput(tc.opts, stdin)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectorInput returned error: %v", err)
+			}
+			if tc.opts.script == "" {
+				if input != io.Reader(stdin) {
+					t.Fatalf("expected stdin, got %T", input)
+				}
+				return
+			}
+			got, err := io.ReadAll(input)
+			if err != nil {
+				t.Fatalf("read script: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("keys = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseArgsScript(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"--script", " j enter "}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if opts.script != "j enter" {
+		t.Fatalf("script = %q, want %q", opts.script, "j enter")
+	}
+	if got := selectorLayout(opts); got != ui.LayoutInteractive {
+		t.Fatalf("selectorLayout() = %v, want the interactive layout", got)
+	}
+	if strings.Contains(rootCommand.Usage(""), "--script") {
+		t.Fatalf("help documents the hidden --script flag:\n%s", rootCommand.Usage(""))
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/jsonrpc"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/history"
+)
+
+// codeConfirmationRequired is the error code of a perform request that would
+// have asked for confirmation in the selector; repeating it with confirmed
+// set runs it.
+const codeConfirmationRequired = 1
+
+// servedActions are the actions perform runs: the ones that need nothing
+// but a branch, so that an editor can offer them without a terminal.
+var servedActions = []action{actionCheckout, actionMerge, actionMergeCheck, actionPush, actionArchive}
+
+// branchesParams are the params of the branches method.
+type branchesParams struct {
+	// Limit caps the number of branches after the current one; 0 lists
+	// every local branch.
+	Limit int `json:"limit"`
+}
+
+// performParams are the params of the perform method.
+type performParams struct {
+	Action string `json:"action"`
+	Branch string `json:"branch"`
+	// Remote checks out a remote-tracking branch as a local one that tracks it.
+	Remote bool `json:"remote"`
+	// Confirmed answers the question a merge into a protected branch, or
+	// any merge with confirm.merge, would ask.
+	Confirmed bool `json:"confirmed"`
+}
+
+// performResult is the result of the perform method.
+type performResult struct {
+	Action string `json:"action"`
+	// Branch is the branch acted on; for a remote checkout, the local one.
+	Branch  string `json:"branch"`
+	Outcome string `json:"outcome"`
+	// Output is what git printed, which the terminal would have shown.
+	Output string `json:"output,omitempty"`
+}
+
+// runServe answers JSON-RPC requests on in with responses on out until in
+// is closed, and returns the process exit code. Nothing is asked on the
+// terminal: questions the selector would ask come back as errors that the
+// client answers by repeating the request.
+func runServe(ctx context.Context, client *git.Client, cfg config.Config, store *history.Store, in io.Reader, out, errOut io.Writer) int {
+	server := jsonrpc.NewServer()
+	server.Handle("branches", func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params branchesParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		if params.Limit < 0 {
+			return nil, jsonrpc.InvalidParams("limit must be zero or positive")
+		}
+		return servedBranches(ctx, client, cfg, store, params.Limit, time.Now())
+	})
+	server.Handle("perform", func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params performParams
+		if err := decodeParams(raw, &params); err != nil {
+			return nil, err
+		}
+		return performAction(ctx, client, cfg, store, errOut, params)
+	})
+	if err := server.Serve(ctx, in, out); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+// decodeParams reads raw into params; requests without params keep the
+// zero values.
+func decodeParams(raw json.RawMessage, params any) error {
+	if raw == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return jsonrpc.InvalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+// servedBranches returns the local branches in the order the selector lists
+// them, the current branch first, with the details list --json prints.
+func servedBranches(ctx context.Context, client *git.Client, cfg config.Config, store *history.Store, limit int, now time.Time) ([]listEntry, error) {
+	nav, err := navigator.New(client)
+	if err != nil {
+		return nil, err
+	}
+	opts := cliOptions{action: actionCheckout, limit: limit, now: now}
+	ranked, err := loadBranches(ctx, client, nav, opts, scorerFor(cfg), store)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := listEntries(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]listEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+
+	served := make([]listEntry, 0, len(ranked))
+	for _, branch := range ranked {
+		entry, ok := byName[branch.Name]
+		if !ok {
+			entry = listEntry{Name: branch.Name, Current: branch.Current}
+		}
+		served = append(served, entry)
+	}
+	return served, nil
+}
+
+// performAction runs one of servedActions on a branch and returns what git
+// printed. Failures of git come back as errors carrying that output.
+func performAction(ctx context.Context, client *git.Client, cfg config.Config, store *history.Store, errOut io.Writer, params performParams) (performResult, error) {
+	act := action(params.Action)
+	served := false
+	for _, candidate := range servedActions {
+		served = served || candidate == act
+	}
+	switch {
+	case !served:
+		names := make([]string, len(servedActions))
+		for i, candidate := range servedActions {
+			names[i] = string(candidate)
+		}
+		return performResult{}, jsonrpc.InvalidParams("unknown action %q (expected %s)", params.Action, strings.Join(names, ", "))
+	case strings.TrimSpace(params.Branch) == "":
+		return performResult{}, jsonrpc.InvalidParams("perform needs a branch")
+	case params.Remote && act != actionCheckout:
+		return performResult{}, jsonrpc.InvalidParams("remote can only be used with checkout")
+	}
+	if _, err := checkOperation(ctx, client, act, false); err != nil {
+		return performResult{}, err
+	}
+
+	from, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return performResult{}, err
+	}
+	result := performResult{Action: string(act), Branch: params.Branch}
+	var output strings.Builder
+	switch act {
+	case actionCheckout:
+		checkout := client.CheckoutBranch
+		if params.Remote {
+			checkout = client.CheckoutRemoteBranch
+			result.Branch = localBranchName(params.Branch, true)
+		}
+		var message string
+		message, err = checkout(ctx, params.Branch)
+		output.WriteString(message)
+		result.Outcome = "checked out"
+	case actionMerge:
+		if !params.Confirmed {
+			question, err := mergeQuestion(ctx, client, cfg, params.Branch)
+			if err != nil {
+				return performResult{}, err
+			}
+			if question != "" {
+				return performResult{}, &jsonrpc.Error{Code: codeConfirmationRequired, Message: question}
+			}
+		}
+		var merged git.MergeResult
+		merged, err = client.MergeBranch(ctx, params.Branch, mergeOptions(cfg, mergeFlags{}))
+		output.WriteString(merged.Stdout + "\n" + merged.Stderr)
+		result.Outcome = "merged"
+	default:
+		// The steps of --multi ask nothing; the output they would print
+		// is collected instead.
+		result.Outcome, err = batchStep(client, cfg, selectorStyle{}, act, &output)(ctx, params.Branch)
+	}
+	result.Output = strings.TrimSpace(output.String())
+	if err != nil {
+		// The output tells why git failed, such as the conflicts of a merge.
+		result.Outcome = ""
+		return performResult{}, &jsonrpc.Error{Code: jsonrpc.CodeServerError, Message: err.Error(), Data: result}
+	}
+	recordAction(ctx, store, client, errOut, act, from, result.Branch)
+	return result, nil
+}
+
+// mergeQuestion returns what the selector would ask before merging source
+// into the current branch, or "" when it would merge right away.
+func mergeQuestion(ctx context.Context, client *git.Client, cfg config.Config, source string) (string, error) {
+	patterns, err := protectedBranches(ctx, client, cfg)
+	if err != nil {
+		return "", err
+	}
+	target, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+	target = strings.TrimSpace(target)
+	protected := isProtected(target, patterns)
+	if !protected && !cfg.ConfirmMerge {
+		return "", nil
+	}
+	prediction, err := client.PredictMerge(ctx, source)
+	question := mergeSummary(source, target, prediction, err)
+	if protected {
+		question += fmt.Sprintf("'%s' is a protected branch.", target)
+	}
+	return strings.TrimSpace(question), nil
+}
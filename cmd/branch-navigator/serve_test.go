@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/pkg/gittest"
+)
+
+func TestRunServe(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("feature/old")
+	repo.Commit("old work")
+	repo.CheckoutNew("feature/new")
+	repo.Commit("new work")
+	repo.Visit("feature/old", "feature/new", gittest.DefaultBranch)
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"branches"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"branches","params":{"limit":1}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"perform","params":{"action":"merge","branch":"feature/old"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"perform","params":{"action":"merge","branch":"feature/old","confirmed":true}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"perform","params":{"action":"checkout","branch":"feature/new"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"perform","params":{"action":"merge-check","branch":"feature/old"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"perform","params":{"action":"delete","branch":"feature/old"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"perform","params":{"action":"checkout","branch":"missing"}}`,
+	}, "\n")
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	if code := runServe(context.Background(), client, config.Default(), nil, strings.NewReader(in), out, errOut); code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, errOut.String())
+	}
+
+	type response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	var responses []response
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var r response
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatalf("failed to decode a response: %v\n%s", err, out.String())
+		}
+		responses = append(responses, r)
+	}
+	if len(responses) != 8 {
+		t.Fatalf("got %d responses, want 8", len(responses))
+	}
+
+	names := func(raw json.RawMessage) []string {
+		t.Helper()
+		var entries []listEntry
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			t.Fatalf("failed to decode branches: %v", err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name)
+		}
+		return names
+	}
+	if got, want := strings.Join(names(responses[0].Result), " "), "main feature/new feature/old"; got != want {
+		t.Fatalf("branches = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(names(responses[1].Result), " "), "main feature/new"; got != want {
+		t.Fatalf("branches with limit 1 = %s, want %s", got, want)
+	}
+
+	if e := responses[2].Error; e == nil || e.Code != codeConfirmationRequired || !strings.Contains(e.Message, "'main' is a protected branch.") {
+		t.Fatalf("unconfirmed merge into main = %+v, want a confirmation error", e)
+	}
+	want := []string{
+		3: `"outcome":"merged"`,
+		4: `"outcome":"checked out"`,
+		5: `"outcome":"merges cleanly"`,
+	}
+	for i := 3; i <= 5; i++ {
+		if responses[i].Error != nil || !strings.Contains(string(responses[i].Result), want[i]) {
+			t.Fatalf("response %d = %s, error %+v; want %s", responses[i].ID, responses[i].Result, responses[i].Error, want[i])
+		}
+	}
+	if got := repo.Git("branch", "--show-current"); got != "feature/new" {
+		t.Fatalf("current branch = %s, want feature/new", got)
+	}
+	if e := responses[6].Error; e == nil || e.Code != -32602 || !strings.Contains(e.Message, `unknown action "delete"`) {
+		t.Fatalf("delete = %+v, want an invalid params error", e)
+	}
+	if e := responses[7].Error; e == nil || e.Code != -32000 {
+		t.Fatalf("checkout of a missing branch = %+v, want a server error", e)
+	}
+}
+
+func TestRunServeErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		request string
+		fail    string
+		merging bool
+		broken  bool
+		// code and message describe the error response; code 0 expects
+		// none, since the server stops first.
+		code    int
+		message string
+	}{
+		"malformed params":      {request: `{"method":"branches","params":{"limit":"all"}}`, code: -32602, message: "invalid params"},
+		"negative limit":        {request: `{"method":"branches","params":{"limit":-1}}`, code: -32602, message: "limit must be zero or positive"},
+		"listing fails":         {request: `{"method":"branches"}`, fail: "for-each-ref", code: -32000, message: "for-each-ref"},
+		"malformed perform":     {request: `{"method":"perform","params":[]}`, code: -32602, message: "invalid params"},
+		"missing branch":        {request: `{"method":"perform","params":{"action":"checkout","branch":" "}}`, code: -32602, message: "perform needs a branch"},
+		"remote merge":          {request: `{"method":"perform","params":{"action":"merge","branch":"origin/x","remote":true}}`, code: -32602, message: "remote can only be used with checkout"},
+		"merge in progress":     {request: `{"method":"perform","params":{"action":"checkout","branch":"feature/x"}}`, merging: true, code: -32000, message: "merge"},
+		"current branch fails":  {request: `{"method":"perform","params":{"action":"checkout","branch":"feature/x"}}`, fail: "rev-parse --abbrev-ref HEAD", code: -32000, message: "rev-parse --abbrev-ref HEAD"},
+		"merge question fails":  {request: `{"method":"perform","params":{"action":"merge","branch":"feature/x"}}`, fail: "config --local --get " + protectedBranchesKey, code: -32000, message: protectedBranchesKey},
+		"remote checkout fails": {request: `{"method":"perform","params":{"action":"checkout","branch":"origin/x","remote":true}}`, code: -32000, message: "origin/x"},
+		"unreadable requests":   {broken: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := gittest.New(t)
+			repo.Branch("feature/x", "")
+			if tc.merging {
+				head := repo.Git("rev-parse", "HEAD")
+				if err := os.WriteFile(filepath.Join(repo.Dir, ".git", "MERGE_HEAD"), []byte(head+"\n"), 0o644); err != nil {
+					t.Fatalf("failed to start a merge: %v", err)
+				}
+			}
+			client := git.NewClient(failingRunner{Runner: &git.CLI{Dir: repo.Dir, Env: repo.Env()}, prefix: tc.fail})
+			var in io.Reader = strings.NewReader(`{"jsonrpc":"2.0","id":1,` + strings.TrimPrefix(tc.request, "{"))
+			wantCode := 0
+			if tc.broken {
+				in, wantCode = iotest.ErrReader(errors.New("stdin closed")), 1
+			}
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			if code := runServe(context.Background(), client, config.Default(), nil, in, out, errOut); code != wantCode {
+				t.Fatalf("exit code %d, want %d (stderr %q)", code, wantCode, errOut.String())
+			}
+			if tc.broken {
+				if !strings.Contains(errOut.String(), "stdin closed") || out.Len() != 0 {
+					t.Fatalf("stdout %q, stderr %q; want only the read error", out.String(), errOut.String())
+				}
+				return
+			}
+
+			var response struct {
+				Error *struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode the response: %v\n%s", err, out.String())
+			}
+			if e := response.Error; e == nil || e.Code != tc.code || !strings.Contains(e.Message, tc.message) {
+				t.Fatalf("error = %+v, want code %d mentioning %q", e, tc.code, tc.message)
+			}
+		})
+	}
+}
+
+func TestParseArgsServe(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"alone":      {args: []string{"--serve"}},
+		"git config": {args: []string{"--serve", "--git-config", "core.quotePath=false", "--git-config", "color.ui=never"}},
+		"action":     {args: []string{"--serve", "-m"}, wantErr: "--serve takes its requests on stdin"},
+		"operand":    {args: []string{"--serve", "main"}, wantErr: "--serve takes its requests on stdin"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := parseArgs(tc.args, io.Discard, io.Discard)
+			if tc.wantErr == "" {
+				if err != nil || !opts.serve {
+					t.Fatalf("parseArgs() = %+v, %v; want serve", opts, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"branch-navigator/internal/platform/session"
+	"branch-navigator/internal/ui"
+
+	"golang.org/x/term"
+)
+
+// loadReplay reads the session of --replay and returns the options it was
+// recorded with, set up to replay it rather than record it again.
+func loadReplay(path string) (cliOptions, session.Session, error) {
+	recorded, err := session.Load(path)
+	if err != nil {
+		return cliOptions{}, session.Session{}, err
+	}
+	opts, err := parseArgs(recorded.Args, io.Discard, io.Discard)
+	if err != nil {
+		return cliOptions{}, session.Session{}, fmt.Errorf("session %s: %w", path, err)
+	}
+	opts.record = ""
+	// A replay stops before the action, so it has no result to report.
+	opts.resultFile, opts.resultFD = "", 0
+	opts.replay = path
+	opts.now = recorded.Now
+	return opts, recorded, nil
+}
+
+// newSessionRecorder returns the recorder of --record or --replay, or nil
+// when neither is given. A replay records again, to compare with the
+// recording, on the recorded terminal size.
+func newSessionRecorder(opts cliOptions, args []string, replayed session.Session) *session.Recorder {
+	switch {
+	case opts.replay != "":
+		return session.NewRecorder(replayed.Args, replayed.Cols, replayed.Rows, replayed.Now)
+	case opts.record != "":
+		cols, rows, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			cols, rows = 0, 0
+		}
+		return session.NewRecorder(args, cols, rows, opts.now)
+	default:
+		return nil
+	}
+}
+
+// reportReplay writes how the replay went and returns the exit code: 0 when
+// it drew what was recorded, 1 otherwise.
+func reportReplay(recorded, replayed session.Session, result ui.Result, out, errOut io.Writer) int {
+	switch {
+	case result.Branch != "":
+		fmt.Fprintf(out, "Replay ended on %s; the action was not run.\n", result.Branch)
+	case len(result.Marked) > 0:
+		fmt.Fprintf(out, "Replay ended on %s; the action was not run.\n", strings.Join(result.Marked, ", "))
+	default:
+		fmt.Fprintln(out, "Replay ended without a selection.")
+	}
+	diffs := session.Diff(recorded, replayed)
+	if len(diffs) == 0 {
+		fmt.Fprintf(out, "All %d frames matched the recording.\n", len(recorded.Frames()))
+		return 0
+	}
+	for _, diff := range diffs {
+		fmt.Fprintf(errOut, "replay: %s\n", diff)
+	}
+	return 1
+}
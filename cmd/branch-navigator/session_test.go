@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/platform/session"
+	"branch-navigator/internal/ui"
+)
+
+func TestLoadReplay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recorder := session.NewRecorder([]string{"-m", "--record", "bug.json", "--no-header"}, 100, 30, now)
+	recorder.Key([]byte("j\r"))
+	path := filepath.Join(t.TempDir(), "bug.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	opts, recorded, err := loadReplay(path)
+	if err != nil {
+		t.Fatalf("loadReplay returned error: %v", err)
+	}
+	if opts.action != actionMerge || !opts.noHeader || opts.record != "" || opts.replay != path || !opts.now.Equal(now) {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+	if recorded.Cols != 100 || recorded.Rows != 30 {
+		t.Fatalf("recorded size = %dx%d, want 100x30", recorded.Cols, recorded.Rows)
+	}
+	if got := selectorLayout(opts); got != ui.LayoutInteractive {
+		t.Fatalf("selectorLayout() = %v, want the interactive layout", got)
+	}
+}
+
+func TestReportReplay(t *testing.T) {
+	t.Parallel()
+
+	recorded := session.Session{Events: []session.Event{{Kind: session.KindFrame, Frame: "> main"}}}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := reportReplay(recorded, recorded, ui.Result{Branch: "main"}, out, errOut); code != 0 {
+		t.Fatalf("matching replay exited %d; stderr:\n%s", code, errOut)
+	}
+	if got := out.String(); got != "Replay ended on main; the action was not run.\nAll 1 frames matched the recording.\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	out.Reset()
+	replayed := session.Session{Events: []session.Event{{Kind: session.KindFrame, Frame: "> other"}}}
+	if code := reportReplay(recorded, replayed, ui.Result{Quit: true}, out, errOut); code != 1 {
+		t.Fatalf("differing replay exited %d, want 1", code)
+	}
+	if !strings.Contains(out.String(), "without a selection") || !strings.Contains(errOut.String(), "replay: frame 1 differs at line 1") {
+		t.Fatalf("unexpected report:\nstdout: %s\nstderr: %s", out, errOut)
+	}
+}
+
+func TestParseArgsRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"record":             {args: []string{"--record", "bug.json", "-m"}},
+		"replay alone":       {args: []string{"--replay", "bug.json"}},
+		"replay with a flag": {args: []string{"--replay", "bug.json", "-m"}, wantErr: "--replay runs with the options of the recording"},
+		"record with plain":  {args: []string{"--record", "bug.json", "--plain"}, wantErr: "--record needs the interactive selector"},
+		"record with pick":   {args: []string{"--record", "bug.json", "--pick", "main"}, wantErr: "--record needs the interactive selector"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			_, err := parseArgs(tc.args, usage, usage)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("parseArgs returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/state"
+	"branch-navigator/internal/ui"
+)
+
+// openState returns the default selector state store, or nil when its
+// location cannot be determined.
+func openState() *state.Store {
+	store, err := state.OpenDefault()
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// loadRepoState returns the repository root and the selector state saved for
+// it. Failures are reported as warnings and yield an empty repository path,
+// which disables saving.
+func loadRepoState(ctx context.Context, store *state.Store, client *git.Client, errOut io.Writer) (string, state.RepoState) {
+	if store == nil || client == nil {
+		return "", state.RepoState{}
+	}
+	repo, err := client.RepoRoot(ctx)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: failed to load selector state: %v\n", err)
+		return "", state.RepoState{}
+	}
+	saved, err := store.Load(repo)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: failed to load selector state: %v\n", err)
+		return repo, state.RepoState{}
+	}
+	return repo, saved
+}
+
+// selectorState converts saved state into the selector's starting position.
+// When the last selected branch is the current one, the cursor starts on the
+// branch it was reached from so that toggling between a pair takes one key.
+func selectorState(saved state.RepoState, current string) ui.State {
+	cursor := saved.Branch
+	if cursor == current && saved.From != "" {
+		cursor = saved.From
+	}
+	return ui.State{Cursor: cursor, Filter: saved.Filter}
+}
+
+// nextRepoState folds a selector result into the saved state. Quitting keeps
+// the previous selection and only updates the filter.
+func nextRepoState(saved state.RepoState, result ui.Result, current string) state.RepoState {
+	next := saved
+	next.Filter = result.Filter
+	if !result.Quit && result.Branch != "" {
+		next.Branch = result.Branch
+		next.From = current
+	}
+	return next
+}
+
+// saveRepoState persists st for repo, reporting failures as warnings.
+func saveRepoState(store *state.Store, repo string, st state.RepoState, errOut io.Writer) {
+	if store == nil || repo == "" {
+		return
+	}
+	if err := store.Save(repo, st); err != nil {
+		fmt.Fprintf(errOut, "warning: failed to save selector state: %v\n", err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/state"
+	"branch-navigator/internal/ui"
+)
+
+func TestSelectorState(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		saved   state.RepoState
+		current string
+		want    ui.State
+	}{
+		"nothing saved": {
+			current: "main",
+			want:    ui.State{},
+		},
+		"last selection elsewhere": {
+			saved:   state.RepoState{Branch: "feature/x", From: "main", Filter: "feat"},
+			current: "main",
+			want:    ui.State{Cursor: "feature/x", Filter: "feat"},
+		},
+		"toggle back to previous branch": {
+			saved:   state.RepoState{Branch: "feature/x", From: "main"},
+			current: "feature/x",
+			want:    ui.State{Cursor: "main"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := selectorState(tc.saved, tc.current); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("selectorState() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNextRepoState(t *testing.T) {
+	t.Parallel()
+
+	saved := state.RepoState{Branch: "feature/x", From: "main", Filter: "old"}
+
+	if got, want := nextRepoState(saved, ui.Result{Branch: "topic", Filter: "top"}, "feature/x"), (state.RepoState{Branch: "topic", From: "feature/x", Filter: "top"}); got != want {
+		t.Fatalf("selection: got %+v, want %+v", got, want)
+	}
+	if got, want := nextRepoState(saved, ui.Result{Quit: true, Filter: "new"}, "main"), (state.RepoState{Branch: "feature/x", From: "main", Filter: "new"}); got != want {
+		t.Fatalf("quit: got %+v, want %+v", got, want)
+	}
+}
+
+func TestRepoStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	store := state.Open(filepath.Join(t.TempDir(), "state.json"))
+	client := git.NewClient(&recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": "/src/app\n"}})
+	errOut := &bytes.Buffer{}
+
+	repo, saved := loadRepoState(context.Background(), store, client, errOut)
+	if repo != "/src/app" || saved != (state.RepoState{}) {
+		t.Fatalf("loadRepoState() = %q, %+v", repo, saved)
+	}
+	saveRepoState(store, repo, state.RepoState{Branch: "topic", From: "main"}, errOut)
+
+	_, saved = loadRepoState(context.Background(), store, client, errOut)
+	if want := (state.RepoState{Branch: "topic", From: "main"}); saved != want {
+		t.Fatalf("reloaded state = %+v, want %+v", saved, want)
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("unexpected warnings: %q", errOut.String())
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"branch-navigator/internal/platform/history"
+	"branch-navigator/internal/ui"
+)
+
+// runStatsCommand implements the stats subcommand and returns the process exit code.
+func runStatsCommand(store *history.Store, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator stats", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(out, statsCommand.Usage(programName))
+	}
+	asJSON := fs.Bool("json", false, statsCommand.FlagUsage("json"))
+	limit := fs.Int("n", 10, statsCommand.FlagUsage("n"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprint(errOut, statsCommand.Usage(programName))
+		return 2
+	}
+	if *limit <= 0 {
+		fmt.Fprintln(errOut, "limit must be greater than 0")
+		return 2
+	}
+	if store == nil {
+		fmt.Fprintln(errOut, "history store is not available")
+		return 1
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	stats := history.Summarize(entries, *limit)
+
+	if *asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats); err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		return 0
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(out, "No history recorded yet in %s\n", store.Path())
+		return 0
+	}
+	if err := writeStatsTable(out, stats); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+func writeStatsTable(out io.Writer, stats history.Stats) error {
+	w := ui.NewColumnWriter(out, 2)
+	fmt.Fprintln(w, "REPOSITORY\tSWITCHES\tACTIONS")
+	for _, repo := range stats.Repos {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", repo.Path, repo.Switches, repo.Actions)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "BRANCH\tUSES\tREPOSITORY")
+	for _, branch := range stats.Branches {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", branch.Branch, branch.Count, branch.Repo)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ACTION\tCOUNT")
+	for _, action := range stats.Actions {
+		fmt.Fprintf(w, "%s\t%d\n", action.Action, action.Count)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/platform/history"
+)
+
+func statsStore(t *testing.T) *history.Store {
+	t.Helper()
+	store := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	for _, entry := range []history.Entry{
+		{Time: time.Unix(1, 0), Repo: "/src/app", Action: "checkout", Branch: "feature/x"},
+		{Time: time.Unix(2, 0), Repo: "/src/app", Action: "checkout", Branch: "feature/x"},
+		{Time: time.Unix(3, 0), Repo: "/src/app", Action: "merge", Branch: "main"},
+		{Time: time.Unix(4, 0), Repo: "/src/lib", Action: "checkout", Branch: "main"},
+	} {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+	return store
+}
+
+func TestRunStatsCommandTable(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	if code := runStatsCommand(statsStore(t), nil, out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	want := "REPOSITORY  SWITCHES  ACTIONS\n" +
+		"/src/app    2         3\n" +
+		"/src/lib    1         1\n" +
+		"\n" +
+		"BRANCH     USES  REPOSITORY\n" +
+		"feature/x  2     /src/app\n" +
+		"main       1     /src/app\n" +
+		"main       1     /src/lib\n" +
+		"\n" +
+		"ACTION    COUNT\n" +
+		"checkout  3\n" +
+		"merge     1\n"
+	if got := out.String(); got != want {
+		t.Fatalf("stats output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunStatsCommandJSON(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	if code := runStatsCommand(statsStore(t), []string{"--json", "-n", "1"}, out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var got history.Stats
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if len(got.Repos) != 2 || got.Repos[0].Path != "/src/app" || got.Repos[0].Switches != 2 {
+		t.Fatalf("unexpected repos %+v", got.Repos)
+	}
+	if len(got.Branches) != 1 || got.Branches[0].Branch != "feature/x" || got.Branches[0].Count != 2 {
+		t.Fatalf("unexpected branches %+v", got.Branches)
+	}
+	if len(got.Actions) != 2 || got.Actions[0] != (history.ActionStats{Action: "checkout", Count: 3}) {
+		t.Fatalf("unexpected actions %+v", got.Actions)
+	}
+}
+
+func TestRunStatsCommandEmpty(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	store := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	if code := runStatsCommand(store, nil, out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if !strings.HasPrefix(out.String(), "No history recorded yet") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestRunStatsCommandErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		store    *history.Store
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		"no store":       {wantCode: 1, wantErr: "history store is not available"},
+		"bad limit":      {store: history.Open("unused"), args: []string{"-n", "0"}, wantCode: 2, wantErr: "limit must be greater than 0"},
+		"extra argument": {store: history.Open("unused"), args: []string{"extra"}, wantCode: 2, wantErr: "Usage: branch-navigator stats"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			errOut := &bytes.Buffer{}
+			if code := runStatsCommand(tc.store, tc.args, &bytes.Buffer{}, errOut); code != tc.wantCode {
+				t.Fatalf("exit code %d, want %d", code, tc.wantCode)
+			}
+			if !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("stderr %q does not contain %q", errOut.String(), tc.wantErr)
+			}
+		})
+	}
+}
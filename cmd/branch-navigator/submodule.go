@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// pickSubmodule lets the user choose one of the initialized submodules of
+// the repository client works in and returns its directory, relative to the
+// current directory. ok is false when the user quits.
+func pickSubmodule(ctx context.Context, client *git.Client, style selectorStyle, layout ui.Layout, in io.Reader, out io.Writer) (dir string, ok bool, err error) {
+	if client == nil {
+		return "", false, fmt.Errorf("git client is not configured")
+	}
+
+	submodules, err := client.Submodules(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	entries := make([]ui.Branch, 0, len(submodules))
+	for _, submodule := range submodules {
+		// An uninitialized submodule has no working tree to run git in.
+		if submodule.Initialized {
+			entries = append(entries, ui.Branch{Name: submodule.Path, Detail: submoduleDetail(submodule)})
+		}
+	}
+	if len(entries) == 0 {
+		if len(submodules) > 0 {
+			return "", false, errors.New("no submodule is initialized; run git submodule update --init first")
+		}
+		return "", false, errors.New("the repository has no submodules")
+	}
+
+	picker := style.selector(in, out, ui.ActionDetails{
+		Name:        "Choose submodule",
+		Description: "Select the submodule to run the action in.",
+		EnterLabel:  "choose a branch in the submodule",
+	})
+	picker.SetLayout(layout)
+	picked, err := picker.Select(entries)
+	if err != nil || picked.Quit {
+		return "", false, err
+	}
+	return picked.Branch, true, nil
+}
+
+// submoduleDetail describes the checked-out commit of a submodule, such as
+// (heads/main, modified).
+func submoduleDetail(submodule git.Submodule) string {
+	tags := []string{}
+	if submodule.Describe != "" {
+		tags = append(tags, submodule.Describe)
+	}
+	if submodule.Modified {
+		tags = append(tags, "modified")
+	}
+	if submodule.Conflicted {
+		tags = append(tags, "conflicted")
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(tags, ", ") + ")"
+}
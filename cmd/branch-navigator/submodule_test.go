@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+func TestPickSubmodule(t *testing.T) {
+	t.Parallel()
+
+	status := "-1111111111111111111111111111111111111111 vendor/missing\n" +
+		" 2222222222222222222222222222222222222222 libs/core (heads/main)\n" +
+		"+3333333333333333333333333333333333333333 libs/ui (v1.2.0-3-g3333333)"
+
+	cases := map[string]struct {
+		keys    string
+		layout  ui.Layout
+		wantDir string
+		wantOK  bool
+	}{
+		"first":  {keys: "\r", layout: ui.LayoutAuto, wantDir: "libs/core", wantOK: true},
+		"second": {keys: "j\r", layout: ui.LayoutAuto, wantDir: "libs/ui", wantOK: true},
+		"plain":  {keys: "2\n", layout: ui.LayoutPlain, wantDir: "libs/ui", wantOK: true},
+		"quit":   {keys: "q", layout: ui.LayoutAuto},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"submodule status --recursive": status}}
+			out := &bytes.Buffer{}
+			dir, ok, err := pickSubmodule(context.Background(), git.NewClient(runner), testStyle, tc.layout, newKeys(tc.keys), out)
+			if err != nil {
+				t.Fatalf("pickSubmodule returned error: %v", err)
+			}
+			if dir != tc.wantDir || ok != tc.wantOK {
+				t.Fatalf("pickSubmodule() = %q, %v; want %q, %v", dir, ok, tc.wantDir, tc.wantOK)
+			}
+			if strings.Contains(out.String(), "vendor/missing") {
+				t.Fatalf("uninitialized submodule was listed: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestPickSubmoduleWithoutSubmodules(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		status string
+		want   string
+	}{
+		"none":          {want: "no submodules"},
+		"uninitialized": {status: "-1111111111111111111111111111111111111111 vendor/missing", want: "git submodule update --init"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"submodule status --recursive": tc.status}}
+			_, _, err := pickSubmodule(context.Background(), git.NewClient(runner), testStyle, ui.LayoutAuto, newKeys(""), &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestSubmoduleDetail(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		submodule git.Submodule
+		want      string
+	}{
+		"clean":      {submodule: git.Submodule{Describe: "heads/main"}, want: "(heads/main)"},
+		"modified":   {submodule: git.Submodule{Describe: "v1.2.0", Modified: true}, want: "(v1.2.0, modified)"},
+		"conflicted": {submodule: git.Submodule{Conflicted: true}, want: "(conflicted)"},
+		"bare":       {submodule: git.Submodule{}, want: ""},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := submoduleDetail(tc.submodule); got != tc.want {
+				t.Fatalf("submoduleDetail() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/discover"
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// sweepCandidate is a branch that sweep offers to delete.
+type sweepCandidate struct {
+	// repo is the repository directory and label its path below the root.
+	repo   string
+	label  string
+	branch string
+	// merged reports that the branch is merged into the base branch, gone
+	// that its upstream no longer exists, and unpushed that it has commits
+	// no remote has.
+	merged   bool
+	gone     bool
+	unpushed bool
+}
+
+// row returns the selector row of the candidate. The name joins the
+// repository and branch, since branch names repeat across repositories.
+func (c sweepCandidate) row() ui.Branch {
+	tags := []string{}
+	if c.merged {
+		tags = append(tags, "merged")
+	}
+	if c.gone {
+		tags = append(tags, "upstream gone")
+	}
+	if c.unpushed {
+		tags = append(tags, "unpushed")
+	}
+	return ui.Branch{Name: c.label + ": " + c.branch, Detail: "(" + strings.Join(tags, ", ") + ")", Merged: c.merged}
+}
+
+// runSweepCommand implements the sweep subcommand and returns the process exit code.
+func runSweepCommand(ctx context.Context, newClient clientFactory, cfg config.Config, style selectorStyle, args []string, in io.Reader, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator sweep", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, sweepCommand.Usage(programName))
+	}
+	root := fs.String("root", ".", sweepCommand.FlagUsage("root"))
+	depth := fs.Int("depth", discover.DefaultDepth, sweepCommand.FlagUsage("depth"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(errOut, "unexpected argument %q\n", fs.Arg(0))
+		return 2
+	}
+	if *depth <= 0 {
+		fmt.Fprintln(errOut, "depth must be greater than 0")
+		return 2
+	}
+
+	repos, err := discover.Repositories(*root, discover.Options{MaxDepth: *depth})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if len(repos) == 0 {
+		fmt.Fprintf(errOut, "no git repositories found under %s\n", *root)
+		return 1
+	}
+
+	var candidates []sweepCandidate
+	err = style.progress(ctx, errOut, "Scanning repositories", func(ctx context.Context) error {
+		for _, repo := range repos {
+			found, err := sweepCandidates(ctx, newClient(repo), cfg, repo, repoLabel(*root, repo))
+			if err != nil {
+				// One broken repository should not stop the sweep.
+				fmt.Fprintf(errOut, "warning: skipping %s: %v\n", repo, err)
+				continue
+			}
+			candidates = append(candidates, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintf(out, "No merged or gone branches in %s.\n", repositoryCount(len(repos)))
+		return 0
+	}
+
+	rows := make([]ui.Branch, len(candidates))
+	byName := make(map[string]sweepCandidate, len(candidates))
+	for i, candidate := range candidates {
+		rows[i] = candidate.row()
+		byName[rows[i].Name] = candidate
+	}
+	picker := style.selector(in, out, ui.ActionDetails{
+		Name:        "Sweep branches",
+		Description: fmt.Sprintf("Mark the merged and gone branches to delete across %s.", repositoryCount(len(repos))),
+		EnterLabel:  "delete the marked branches",
+	})
+	picker.SetMultiSelect(true)
+	result, err := picker.Select(rows)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if result.Quit || len(result.Marked) == 0 {
+		return 0
+	}
+
+	chosen := make([]sweepCandidate, len(result.Marked))
+	touched := map[string]bool{}
+	unpushed := []string{}
+	for i, name := range result.Marked {
+		chosen[i] = byName[name]
+		touched[chosen[i].repo] = true
+		if chosen[i].unpushed {
+			unpushed = append(unpushed, name)
+		}
+	}
+	summary := fmt.Sprintf("Delete %d %s in %s? Each is backed up first.", len(chosen), plural(len(chosen), "branch", "branches"), repositoryCount(len(touched)))
+	var confirmed bool
+	if len(unpushed) > 0 {
+		// Unpushed commits survive only in the backups, so a y typed out of
+		// habit is not enough.
+		confirmed, err = ui.TypedConfirmation{
+			Warning: summary + fmt.Sprintf("\nWarning: %d of them %s commits that no remote has: %s.", len(unpushed), plural(len(unpushed), "has", "have"), strings.Join(unpushed, ", ")),
+			Token:   "delete",
+		}.Confirm(in, out)
+	} else {
+		confirmed, err = ui.Confirmation{Summary: summary}.Confirm(in, out)
+	}
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if !confirmed {
+		fmt.Fprintln(out, "Nothing deleted.")
+		return 0
+	}
+
+	failed := 0
+	for _, repo := range repos {
+		if !touched[repo] {
+			continue
+		}
+		client := newClient(repo)
+		for _, candidate := range chosen {
+			if candidate.repo != repo {
+				continue
+			}
+			if err := sweepBranch(ctx, client, candidate, out); err != nil {
+				fmt.Fprintf(errOut, "%s: %v\n", candidate.label, err)
+				failed++
+			}
+		}
+		if retention := backupRetention(cfg); retention > 0 {
+			if _, err := client.PruneBackups(ctx, time.Now().Add(-retention)); err != nil {
+				fmt.Fprintf(errOut, "warning: %s: failed to prune expired branch backups: %v\n", repo, err)
+			}
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// sweepCandidates returns the local branches of the repository client works
+// in that are merged into the base branch or whose upstream is gone. The
+// current branch and the base branch are never offered.
+func sweepCandidates(ctx context.Context, client *git.Client, cfg config.Config, repo, label string) ([]sweepCandidate, error) {
+	base, err := baseBranchName(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergedBranchSet(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := client.BranchStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []sweepCandidate{}
+	for _, status := range statuses {
+		if status.Name == current || status.Name == base {
+			continue
+		}
+		if merged[status.Name] || status.Gone {
+			candidates = append(candidates, sweepCandidate{
+				repo:     repo,
+				label:    label,
+				branch:   status.Name,
+				merged:   merged[status.Name],
+				gone:     status.Gone,
+				unpushed: isUnpushed(status, merged[status.Name]),
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// sweepBranch backs up and deletes a chosen branch. Every branch is
+// force-deleted: git branch -d checks the current branch or the upstream
+// rather than the base branch a merged branch was found merged into, and a
+// branch that is gone but not merged was marked and confirmed, with the
+// backup keeping its commits. The backup is removed again when the
+// deletion fails.
+func sweepBranch(ctx context.Context, client *git.Client, candidate sweepCandidate, out io.Writer) error {
+	backup, err := client.BackupBranch(ctx, candidate.branch, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to back up branch '%s' before deletion: %w", candidate.branch, err)
+	}
+	result, err := client.DeleteBranch(ctx, candidate.branch, git.DeleteOptions{Force: true})
+	if err != nil {
+		// The branch is still there, so the backup would only linger.
+		_ = client.DeleteBackup(ctx, backup)
+		return err
+	}
+	if stdout := strings.TrimSpace(result.Stdout); stdout != "" {
+		fmt.Fprintf(out, "%s: %s\n", candidate.label, stdout)
+	}
+	printBackupHint(out, backup)
+	return nil
+}
+
+// repoLabel names repo by its path below root, or by its directory name
+// when it is the root itself.
+func repoLabel(root, repo string) string {
+	rel, err := filepath.Rel(root, repo)
+	if err != nil || rel == "." {
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+		return filepath.Base(repo)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func repositoryCount(n int) string {
+	return fmt.Sprintf("%d %s", n, plural(n, "repository", "repositories"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/pkg/gittest"
+)
+
+// sweepStatuses is the BranchStatuses command the sweep reads branches with.
+const sweepStatuses = "for-each-ref --sort=-committerdate --format=%(refname:lstrip=2)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track) refs/heads"
+
+// sweepRepos creates a repository directory under root for each name and
+// returns clients that answer for them from outputs.
+func sweepRepos(t *testing.T, root string, outputs map[string]map[string]string) (map[string]*recordingRunner, clientFactory) {
+	t.Helper()
+	runners := map[string]*recordingRunner{}
+	for name, repoOutputs := range outputs {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		runners[dir] = &recordingRunner{outputs: repoOutputs}
+	}
+	return runners, func(dir string) *git.Client {
+		runner, ok := runners[dir]
+		if !ok {
+			t.Errorf("client opened in unexpected directory %q", dir)
+			runner = &recordingRunner{}
+		}
+		return git.NewClient(runner)
+	}
+}
+
+// hasCall reports whether runner ran the git command args.
+func hasCall(runner *recordingRunner, args ...string) bool {
+	for _, call := range runner.calls {
+		if reflect.DeepEqual(call, args) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunSweepCommand(t *testing.T) {
+	t.Parallel()
+
+	repoOutputs := func(merged, statuses string) map[string]string {
+		return map[string]string{
+			"rev-parse --abbrev-ref HEAD":                                            "main",
+			"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes": merged,
+			sweepStatuses: statuses,
+		}
+	}
+
+	cases := map[string]struct {
+		keys        string
+		wantDeleted map[string][]string
+		wantOut     string
+	}{
+		"delete every marked branch": {
+			keys: "a\rdelete\n",
+			wantDeleted: map[string][]string{
+				"api":     {"-D", "done"},
+				"web/app": {"-D", "gone"},
+			},
+			wantOut: "Delete 2 branches in 2 repositories? Each is backed up first.\nWarning: 1 of them has commits that no remote has: web/app: gone.\n",
+		},
+		"delete one branch": {
+			keys:        "j\rdelete\n",
+			wantDeleted: map[string][]string{"web/app": {"-D", "gone"}},
+			wantOut:     "Delete 1 branch in 1 repository?",
+		},
+		"unpushed answered y": {
+			keys:    "j\ry\n",
+			wantOut: "Nothing deleted.",
+		},
+		"merged answered y": {
+			keys:        "\ry\n",
+			wantDeleted: map[string][]string{"api": {"-D", "done"}},
+			wantOut:     "Delete 1 branch in 1 repository? Each is backed up first.\nProceed? [y/N]",
+		},
+		"declined": {
+			keys:    "a\rn\n",
+			wantOut: "Nothing deleted.",
+		},
+		"quit": {
+			keys: "q",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			runners, factory := sweepRepos(t, root, map[string]map[string]string{
+				"api": repoOutputs("refs/heads/main\nrefs/heads/done\nrefs/remotes/origin/done",
+					"main\t300\torigin/main\t\ndone\t200\t\t\nwip\t100\t\t"),
+				"web/app": repoOutputs("refs/heads/main",
+					"main\t300\torigin/main\t\ngone\t200\torigin/gone\t[gone]"),
+			})
+			cfg := config.Default()
+			cfg.Base = "main"
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			code := runSweepCommand(context.Background(), factory, cfg, testStyle, []string{"--root", root}, newKeys(tc.keys), out, errOut)
+			if code != 0 {
+				t.Fatalf("exit code = %d, stderr %q", code, errOut.String())
+			}
+			if !strings.Contains(out.String(), "api: done (merged)") || !strings.Contains(out.String(), "web/app: gone") {
+				t.Fatalf("expected both candidates to be listed, got %q", out.String())
+			}
+			if strings.Contains(out.String(), "wip") {
+				t.Fatalf("unmerged branch was offered: %q", out.String())
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+			for repo, runner := range runners {
+				label, _ := filepath.Rel(root, repo)
+				deleted := []string{}
+				for _, call := range runner.calls {
+					if call[0] == "branch" {
+						deleted = call[1:]
+					}
+				}
+				want := tc.wantDeleted[filepath.ToSlash(label)]
+				if want == nil {
+					want = []string{}
+				}
+				if !reflect.DeepEqual(deleted, want) {
+					t.Fatalf("%s: deleted %v, want %v (calls %v)", label, deleted, want, runner.calls)
+				}
+				if len(want) > 0 && !hasCall(runner, "rev-parse", "--verify", "refs/heads/"+want[1]+"^{commit}") {
+					t.Fatalf("%s: %s was not backed up first, calls %v", label, want[1], runner.calls)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSweepCommandPipedInput(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	runners, factory := sweepRepos(t, root, map[string]map[string]string{
+		"api": {
+			"rev-parse --abbrev-ref HEAD":                                            "main",
+			"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes": "refs/heads/main\nrefs/heads/done",
+			sweepStatuses: "main\t300\t\t\ndone\t200\t\t",
+		},
+	})
+	cfg := config.Default()
+	cfg.Base = "main"
+
+	// The selection and the answer arrive in one write, as they do from
+	// printf: the confirmation has to find the line the selector left.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := w.WriteString("\ry\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	out := &bytes.Buffer{}
+	code := runSweepCommand(context.Background(), factory, cfg, testStyle, []string{"--root", root}, sharedStdin(r), out, &bytes.Buffer{})
+	if code != 0 {
+		t.Fatalf("exit code = %d, stdout %q", code, out.String())
+	}
+	if !hasCall(runners[filepath.Join(root, "api")], "branch", "-D", "done") {
+		t.Fatalf("piped confirmation was lost: stdout %q", out.String())
+	}
+}
+
+func TestRunSweepCommandWithoutCandidates(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	_, factory := sweepRepos(t, root, map[string]map[string]string{
+		"api": {"rev-parse --abbrev-ref HEAD": "main", sweepStatuses: "main\t300\t\t"},
+	})
+	cfg := config.Default()
+	cfg.Base = "main"
+
+	out := &bytes.Buffer{}
+	code := runSweepCommand(context.Background(), factory, cfg, testStyle, []string{"--root", root}, newKeys(""), out, &bytes.Buffer{})
+	if code != 0 || !strings.Contains(out.String(), "No merged or gone branches in 1 repository.") {
+		t.Fatalf("unexpected result: code %d, stdout %q", code, out.String())
+	}
+}
+
+func TestRunSweepCommandErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		"no repositories": {
+			args:     []string{"--root", t.TempDir()},
+			wantCode: 1,
+			wantErr:  "no git repositories found under",
+		},
+		"missing root": {
+			args:     []string{"--root", filepath.Join(t.TempDir(), "missing")},
+			wantCode: 1,
+			wantErr:  "no such file or directory",
+		},
+		"invalid depth": {
+			args:     []string{"--depth", "0"},
+			wantCode: 2,
+			wantErr:  "depth must be greater than 0",
+		},
+		"extra argument": {
+			args:     []string{"src"},
+			wantCode: 2,
+			wantErr:  `unexpected argument "src"`,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			errOut := &bytes.Buffer{}
+			code := runSweepCommand(context.Background(), git.NewDefaultClientAt, config.Default(), testStyle, tc.args, newKeys(""), &bytes.Buffer{}, errOut)
+			if code != tc.wantCode || !strings.Contains(errOut.String(), tc.wantErr) {
+				t.Fatalf("code %d, stderr %q; want code %d and %q", code, errOut.String(), tc.wantCode, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSweepBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("done")
+	repo.Commit("done work")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.Git("merge", "--quiet", "--no-ff", "-m", "merge done", "done")
+	repo.Branch("busy", "")
+	repo.AddWorktree("busy")
+	// other lacks the commits of done, so git branch -d would refuse it.
+	repo.Git("checkout", "--quiet", "-b", "other", "HEAD~1")
+	client := git.NewClient(&git.CLI{Dir: repo.Dir, Env: repo.Env()})
+	ctx := context.Background()
+
+	out := &bytes.Buffer{}
+	if err := sweepBranch(ctx, client, sweepCandidate{label: "api", branch: "done", merged: true}, out); err != nil {
+		t.Fatalf("sweepBranch returned error: %v", err)
+	}
+	if repo.Git("branch", "--list", "done") != "" {
+		t.Fatal("done was not deleted")
+	}
+
+	err := sweepBranch(ctx, client, sweepCandidate{label: "api", branch: "busy", merged: true}, out)
+	if err == nil {
+		t.Fatal("expected an error for a branch checked out in a worktree")
+	}
+	backups, err := client.Backups(ctx)
+	if err != nil {
+		t.Fatalf("Backups returned error: %v", err)
+	}
+	for _, backup := range backups {
+		if backup.Branch == "busy" {
+			t.Fatalf("backup %s of the kept branch was left behind", backup.Ref)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/match"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/history"
+)
+
+// maxSwitchAlternatives bounds how many candidates an ambiguous switch lists.
+const maxSwitchAlternatives = 10
+
+// runSwitchCommand implements the switch subcommand and returns the process exit code.
+func runSwitchCommand(ctx context.Context, client *git.Client, store *history.Store, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator switch", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(out, switchCommand.Usage(programName))
+	}
+	forceState := fs.Bool("force-state", false, switchCommand.FlagUsage("force-state"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		fmt.Fprint(errOut, switchCommand.Usage(programName))
+		return 2
+	}
+
+	candidates, current, err := switchCandidates(ctx, client)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	ranked := match.Rank(query, candidates)
+	if len(ranked) == 0 {
+		fmt.Fprintf(errOut, "no branch matches '%s'\n", query)
+		return 1
+	}
+	if len(ranked) > 1 && ranked[0].Score == ranked[1].Score {
+		fmt.Fprintf(errOut, "'%s' is ambiguous; matching branches:\n", query)
+		for i, m := range ranked {
+			if i == maxSwitchAlternatives || m.Score < ranked[0].Score {
+				break
+			}
+			fmt.Fprintf(errOut, "  %s\n", m.Candidate)
+		}
+		return 1
+	}
+
+	if _, err := checkOperation(ctx, client, actionCheckout, *forceState); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	message, err := client.CheckoutBranch(ctx, ranked[0].Candidate)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	printIfNotEmpty(out, message)
+	if ranked[0].Candidate != current {
+		recordAction(ctx, store, client, errOut, actionCheckout, current, ranked[0].Candidate)
+	}
+	return 0
+}
+
+// switchCandidates returns every local branch in recency order, with the
+// current branch (also returned separately) last so that it only wins when
+// nothing else matches as well.
+func switchCandidates(ctx context.Context, client *git.Client) ([]string, string, error) {
+	nav, err := navigator.New(client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	all, err := client.BranchesByCommitDate(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(all) == 0 {
+		return nil, "", nil
+	}
+
+	recent, err := nav.RecentBranches(ctx, len(all))
+	if err != nil {
+		return nil, "", err
+	}
+	current, err := client.CurrentBranch(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return append(recent, current), current, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func TestRunSwitchCommand(t *testing.T) {
+	t.Parallel()
+
+	outputs := map[string]string{
+		"rev-parse --abbrev-ref HEAD":                                                "main",
+		"reflog -n 300 --date=unix --format=%gd%x09%gs":                              "checkout: moving from fix/login to main\ncheckout: moving from feature/login-form to fix/login",
+		"for-each-ref --format=%(refname:lstrip=2) --sort=-committerdate refs/heads": "main\nfeature/login-form\nfix/login\nfeature/signup\nfeature/signin",
+		"branch --list --format=%(refname:lstrip=2)":                                 "feature/login-form\nfeature/signin\nfeature/signup\nfix/login\nmain",
+	}
+
+	cases := map[string]struct {
+		args         []string
+		wantCode     int
+		wantCheckout string
+		wantStderr   string
+	}{
+		"best-match": {
+			args:         []string{"login"},
+			wantCode:     0,
+			wantCheckout: "fix/login",
+		},
+		"fuzzy": {
+			args:         []string{"lgnfrm"},
+			wantCode:     0,
+			wantCheckout: "feature/login-form",
+		},
+		"ambiguous": {
+			args:       []string{"sign"},
+			wantCode:   1,
+			wantStderr: "'sign' is ambiguous; matching branches:\n  feature/signup\n  feature/signin\n",
+		},
+		"no-match": {
+			args:       []string{"zzz"},
+			wantCode:   1,
+			wantStderr: "no branch matches 'zzz'",
+		},
+		"missing-query": {
+			args:       nil,
+			wantCode:   2,
+			wantStderr: "Usage: branch-navigator switch <query>",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: outputs}
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			code := runSwitchCommand(context.Background(), git.NewClient(runner), nil, tc.args, out, errOut)
+			if code != tc.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr %q)", code, tc.wantCode, errOut.String())
+			}
+
+			var checkout []string
+			for _, call := range runner.calls {
+				if call[0] == "checkout" {
+					checkout = call
+				}
+			}
+			if tc.wantCheckout != "" {
+				if want := []string{"checkout", tc.wantCheckout}; !reflect.DeepEqual(checkout, want) {
+					t.Fatalf("unexpected checkout call: got %v, want %v", checkout, want)
+				}
+			} else if checkout != nil {
+				t.Fatalf("unexpected checkout call: %v", checkout)
+			}
+			if tc.wantStderr != "" && !strings.Contains(errOut.String(), tc.wantStderr) {
+				t.Fatalf("expected stderr to contain %q, got %q", tc.wantStderr, errOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+// runThemesCommand implements the themes subcommand and returns the process
+// exit code. With --pick the chosen theme is written to the configuration
+// file at configPath.
+func runThemesCommand(configPath string, cfg config.Config, depth ui.ColorDepth, getenv func(string) string, args []string, in io.Reader, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("branch-navigator themes", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, themesCommand.Usage(programName))
+	}
+	pick := fs.Bool("pick", false, themesCommand.FlagUsage("pick"))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	options := themeOptions(depth)
+	if !*pick {
+		for i, option := range options {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			marker := ""
+			if strings.EqualFold(option.Name, cfg.Theme) {
+				marker = " (configured)"
+			}
+			fmt.Fprintf(out, "%s%s\n", option.Name, marker)
+			if err := ui.WriteThemeSample(out, option.Theme); err != nil {
+				fmt.Fprintln(errOut, err)
+				return 1
+			}
+		}
+		return 0
+	}
+
+	choice, ok, err := ui.New(in, out, ui.ActionDetails{}).PickTheme(options, cfg.Theme)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	if !ok {
+		return 0
+	}
+	if err := config.SetString(configPath, "theme", choice.Name); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	fmt.Fprintf(out, "Saved theme = %q to %s\n", choice.Name, configPath)
+	if name := strings.TrimSpace(getenv("BRANCH_NAVIGATOR_THEME")); name != "" {
+		fmt.Fprintf(errOut, "warning: BRANCH_NAVIGATOR_THEME=%q takes precedence over the configuration file\n", name)
+	}
+	return 0
+}
+
+// themeOptions returns the dark and light variant of every theme family,
+// mapped to basic colors on 16-color terminals as they would be drawn.
+func themeOptions(depth ui.ColorDepth) []ui.ThemeOption {
+	var options []ui.ThemeOption
+	for _, family := range ui.AvailableThemeNames() {
+		for _, variant := range []string{"dark", "light"} {
+			name := family + "-" + variant
+			resolved, _ := ui.ThemeFamilyByName(name)
+			theme := resolved.Dark
+			if depth == ui.Colors16 {
+				theme = theme.Basic()
+			}
+			options = append(options, ui.ThemeOption{Name: name, Theme: theme})
+		}
+	}
+	return options
+}
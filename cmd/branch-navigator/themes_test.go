@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/ui"
+)
+
+func TestRunThemesCommandPreviewsEveryTheme(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.Theme = "Nord-Light"
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	if code := runThemesCommand("", cfg, ui.Colors256, fakeEnvLookup(nil), nil, strings.NewReader(""), out, errOut); code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, errOut.String())
+	}
+	for _, name := range ui.AvailableThemeNames() {
+		for _, variant := range []string{"-dark\n", "-light"} {
+			if !strings.Contains(out.String(), name+variant) {
+				t.Fatalf("output missing %s%s:\n%s", name, variant, out.String())
+			}
+		}
+	}
+	if !strings.Contains(out.String(), "nord-light (configured)\n") {
+		t.Fatalf("configured theme not marked:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), ui.ThemeGruvboxLight.Selected+"> feature/login") {
+		t.Fatalf("gruvbox-light sample missing:\n%q", out.String())
+	}
+}
+
+func TestRunThemesCommandBasicColors(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	if code := runThemesCommand("", config.Default(), ui.Colors16, fakeEnvLookup(nil), nil, strings.NewReader(""), out, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if strings.Contains(out.String(), "38;5;") {
+		t.Fatalf("16-color preview uses 256-color sequences:\n%q", out.String())
+	}
+}
+
+func TestRunThemesCommandPickSavesChoice(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("base = \"main\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	env := fakeEnvLookup(map[string]string{"BRANCH_NAVIGATOR_THEME": "classic"})
+	if code := runThemesCommand(path, config.Default(), ui.Colors256, env, []string{"--pick"}, strings.NewReader("jj\r"), out, errOut); code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, errOut.String())
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if cfg.Theme != "nord-dark" || cfg.Base != "main" {
+		t.Fatalf("config = %+v, want theme nord-dark and base kept", cfg)
+	}
+	if !strings.Contains(out.String(), `Saved theme = "nord-dark"`) {
+		t.Fatalf("confirmation missing:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "BRANCH_NAVIGATOR_THEME=\"classic\" takes precedence") {
+		t.Fatalf("override warning missing: %q", errOut.String())
+	}
+}
+
+func TestRunThemesCommandPickQuitKeepsConfig(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if code := runThemesCommand(path, config.Default(), ui.Colors256, fakeEnvLookup(nil), []string{"--pick"}, strings.NewReader("jq"), &bytes.Buffer{}, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("config file written after quitting: %v", err)
+	}
+}
+
+func TestRunThemesCommandRejectsArguments(t *testing.T) {
+	t.Parallel()
+
+	errOut := &bytes.Buffer{}
+	if code := runThemesCommand("", config.Default(), ui.Colors256, fakeEnvLookup(nil), []string{"nord"}, strings.NewReader(""), &bytes.Buffer{}, errOut); code != 2 {
+		t.Fatalf("exit code %d, want 2", code)
+	}
+	if !strings.Contains(errOut.String(), "Usage: branch-navigator themes") {
+		t.Fatalf("usage missing from stderr: %q", errOut.String())
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"runtime"
+
+	"branch-navigator/internal/app"
+	"branch-navigator/internal/git"
+)
+
+// runFollowUps runs the --then steps, already expanded from the configured
+// pipelines, after an action succeeded. pull is built in; every other step
+// is a shell command run in the root of the repository client works in.
+func runFollowUps(ctx context.Context, client *git.Client, steps []string, in io.Reader, out, errOut io.Writer) error {
+	// Outside a repository the steps run in the current directory.
+	root, _ := client.RepoRoot(ctx)
+	pipeline := app.NewPipeline(map[string]app.StepFunc{
+		"pull": client.Pull,
+	}, func(ctx context.Context, command string) error {
+		return runShellStep(ctx, root, command, in, out, errOut)
+	})
+	return pipeline.Run(ctx, steps, errOut)
+}
+
+// runShellStep runs command through the shell in dir, attached to the
+// caller's input and output.
+func runShellStep(ctx context.Context, dir, command string, in io.Reader, out, errOut io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Stdin = in
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	return cmd.Run()
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func TestRunFollowUps(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the steps use sh")
+	}
+	root := t.TempDir()
+	runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": root}}
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	err := runFollowUps(context.Background(), git.NewClient(runner), []string{"echo ready", "pwd"}, strings.NewReader(""), out, errOut)
+	if err != nil {
+		t.Fatalf("runFollowUps returned error: %v", err)
+	}
+	dir, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "ready\n") || !strings.Contains(got, dir) {
+		t.Fatalf("unexpected step output %q", got)
+	}
+	if got := errOut.String(); got != "==> [1/2] echo ready\n==> [2/2] pwd\n" {
+		t.Fatalf("unexpected announcements %q", got)
+	}
+}
+
+func TestRunFollowUpsStops(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the steps use sh")
+	}
+	runner := &recordingRunner{outputs: map[string]string{"rev-parse --show-toplevel": t.TempDir()}}
+	out := &bytes.Buffer{}
+	err := runFollowUps(context.Background(), git.NewClient(runner), []string{"exit 3", "echo unreachable"}, strings.NewReader(""), out, &bytes.Buffer{})
+	if err == nil || err.Error() != `step "exit 3" failed: exit status 3` {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if strings.Contains(out.String(), "unreachable") {
+		t.Fatalf("a step after the failure ran: %q", out.String())
+	}
+}
+
+func TestParseArgsThen(t *testing.T) {
+	t.Parallel()
+
+	usage := &bytes.Buffer{}
+	opts, err := parseArgs([]string{"-c", "--then", "pull", "--then", "make setup"}, usage, usage)
+	if err != nil {
+		t.Fatalf("parseArgs returned error: %v", err)
+	}
+	if got := strings.Join(opts.then, "|"); got != "pull|make setup" {
+		t.Fatalf("then = %q, want pull|make setup", got)
+	}
+
+	cases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"empty step":   {args: []string{"--then", " "}, wantErr: "--then needs a step"},
+		"with print":   {args: []string{"--print", "--then", "pull"}, wantErr: "--then cannot be used with --print"},
+		"with remotes": {args: []string{"--remote-admin", "--then", "pull"}, wantErr: "--then cannot be used with"},
+	}
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			usage := &bytes.Buffer{}
+			_, err := parseArgs(tc.args, usage, usage)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,568 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/match"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/metacache"
+	"branch-navigator/internal/timefmt"
+	"branch-navigator/internal/ui"
+)
+
+// defaultVisibility lists merged and stale branches but leaves remote-tracking
+// branches hidden until toggled with r.
+var defaultVisibility = ui.Visibility{Merged: true, Stale: true}
+
+// acceptsRemoteBranches reports whether act can operate on a remote-tracking branch.
+func acceptsRemoteBranches(act action) bool {
+	switch act {
+	case actionCheckout, actionMerge, actionMergeCheck, actionRebase, actionLog, actionDiff, actionBrowse, actionCreate, actionCopy, actionReset:
+		return true
+	default:
+		return false
+	}
+}
+
+// branchAnnotator labels merged and stale rows so that they can be toggled
+// in the selector, and notes when each branch was last checked out, how
+// many commits it has over the base branch, how far it is ahead of and
+// behind its upstream, and the subject of its last commit. It reads the ref
+// metadata and the reflog once, so that rows loaded later can be labelled
+// without further git calls; the slower counts and subjects are read by
+// enrich while the selector is already open. Merged state and commit counts
+// are skipped when no base branch can be determined.
+type branchAnnotator struct {
+	client *git.Client
+	dates  map[string]time.Time
+	merged map[string]bool
+	// empty holds the local branches whose tip is the tip of the base,
+	// labelled empty instead of merged.
+	empty map[string]bool
+	// labels holds the labels attached to local branches.
+	labels map[string][]string
+	// base is the branch commits are counted against; "" skips the counts.
+	base string
+	// defaultBranch is the repository's default branch, marked with a badge;
+	// "" when none is found. pinned keeps it right below the current branch.
+	defaultBranch string
+	pinned        bool
+	// mu guards the fields enrich fills in: commits, tracks, and subjects.
+	mu sync.RWMutex
+	// commits counts the commits each branch has that the base lacks; it is
+	// empty until read and when they cannot be counted.
+	commits map[string]int
+	// tracks holds the upstream tracking state of each local branch;
+	// tracked reports that it has been read.
+	tracks  map[string]git.BranchStatus
+	tracked bool
+	// subjects holds the subject of the last commit of each branch.
+	subjects map[string]string
+	// updates receives after each part enrich reads and is closed once it
+	// is done.
+	updates chan struct{}
+	// cache holds the merged state, commit counts, and tracking of earlier
+	// runs under keys, the current ref state; cached reports that they were
+	// found there and need not be computed. cache is nil without caching.
+	cache       *metadataCache
+	keys        map[string]metacache.Key
+	cached      bool
+	staleBefore time.Time
+	// visited holds the latest reflog checkout of each branch; it is empty
+	// when the reflog cannot be read.
+	visited    map[string]time.Time
+	timeFormat timefmt.Format
+	now        time.Time
+	// remotes lists the remote-tracking branches offered for the action, most recent first.
+	remotes []string
+	// authors holds the last commit author of each branch as "Name <email>".
+	authors map[string]string
+	filter  branchFilter
+	// containing holds the branches that contain filter.contains.
+	containing map[string]bool
+}
+
+// branchFilter keeps the branches whose last commit matches --author and
+// falls within --since and --before, that contain the --contains commit,
+// and, with --no-merged, that are not merged into the base branch. The zero
+// value keeps every branch.
+type branchFilter struct {
+	// author matches "Name <email>"; nil matches any author.
+	author *match.Filter
+	// since and before bound the commit date; a zero time leaves that side open.
+	since  time.Time
+	before time.Time
+	// contains is a commit the branches must contain; empty keeps every branch.
+	contains string
+	// noMerged drops the branches that the merged labels mark.
+	noMerged bool
+}
+
+// active reports whether the filter drops any branches.
+func (f branchFilter) active() bool {
+	return f.author != nil || !f.since.IsZero() || !f.before.IsZero() || f.contains != "" || f.noMerged
+}
+
+// matches reports whether a branch last committed at date by author is kept.
+func (f branchFilter) matches(author string, date time.Time) bool {
+	if f.author != nil && !f.author.Match(author) {
+		return false
+	}
+	if !f.since.IsZero() && date.Before(f.since) {
+		return false
+	}
+	return f.before.IsZero() || !date.After(f.before)
+}
+
+// newBranchAnnotator reads the metadata for the labels; maxReflog bounds the
+// reflog entries searched for visit times, as for the navigator. filter
+// drops the branches whose last commit it does not match. With a cache, the
+// metadata saved by an earlier run is reused while no ref has moved.
+func newBranchAnnotator(ctx context.Context, client *git.Client, act action, cfg config.Config, maxReflog int, filter branchFilter, now time.Time, cache *metadataCache) (*branchAnnotator, error) {
+	refs, err := client.BranchRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a := &branchAnnotator{
+		client:   client,
+		commits:  map[string]int{},
+		tracks:   map[string]git.BranchStatus{},
+		subjects: map[string]string{},
+		updates:  make(chan struct{}, 1),
+		dates:    make(map[string]time.Time, len(refs)),
+		authors:  make(map[string]string, len(refs)),
+		filter:   filter,
+		visited:  map[string]time.Time{},
+		now:      now,
+	}
+	a.timeFormat = displayTimeFormat(cfg, timefmt.Relative)
+	for _, ref := range refs {
+		a.dates[ref.Name] = ref.CommitDate
+		a.authors[ref.Name] = ref.Author
+		if ref.Remote && acceptsRemoteBranches(act) {
+			a.remotes = append(a.remotes, ref.Name)
+		}
+	}
+
+	if filter.contains != "" {
+		names, err := client.BranchesContaining(ctx, filter.contains)
+		if err != nil {
+			return nil, err
+		}
+		a.containing = make(map[string]bool, len(names))
+		for _, name := range names {
+			a.containing[name] = true
+		}
+	}
+
+	if a.base, err = baseBranchName(ctx, client, cfg); err != nil {
+		return nil, err
+	}
+	a.defaultBranch = a.base
+	if strings.TrimSpace(cfg.Base) != "" {
+		// The badge is informational, so an undetectable default only drops it.
+		a.defaultBranch, _ = client.DefaultBranch(ctx)
+	}
+	for _, ref := range refs {
+		if !ref.Remote && ref.Name == a.defaultBranch {
+			a.pinned = cfg.UIPinDefault
+		}
+	}
+	if cache != nil {
+		a.useCache(ctx, cache)
+	}
+	if !a.cached {
+		if a.merged, err = mergedBranchSet(ctx, client, a.base); err != nil {
+			return nil, err
+		}
+	}
+	if a.empty, err = emptyBranchSet(ctx, client, a.base); err != nil {
+		return nil, err
+	}
+	if cfg.StaleAfterDays > 0 {
+		a.staleBefore = now.AddDate(0, 0, -cfg.StaleAfterDays)
+	}
+	// Labels are informational too, so unreadable ones are left out.
+	a.labels, _ = branchLabels(ctx, client)
+	// Visit times are informational, so an unreadable reflog only drops them.
+	if visits, err := client.ReflogVisits(ctx, maxReflog, 0); err == nil {
+		for _, visit := range visits {
+			if !visit.Time.IsZero() {
+				a.visited[visit.Branch] = visit.Time
+			}
+		}
+	}
+	return a, nil
+}
+
+// useCache takes the merged state, commit counts, and tracking from cache
+// when it holds them for the current tip of every branch, its upstream, and
+// the base. Otherwise enrich saves them there once computed. The cache only
+// saves work, so a failure to read it is ignored.
+func (a *branchAnnotator) useCache(ctx context.Context, cache *metadataCache) {
+	states, err := a.client.RefStates(ctx)
+	if err != nil {
+		return
+	}
+	a.cache = cache
+	a.keys = refKeys(states, a.base)
+	entries, hit, err := cache.store.Lookup(cache.repo, a.keys)
+	if err != nil || !hit {
+		return
+	}
+
+	upstreams := make(map[string]string, len(states))
+	for _, state := range states {
+		upstreams[state.Name] = state.Upstream
+	}
+	a.cached = true
+	a.tracked = true
+	a.merged = map[string]bool{}
+	for name, entry := range entries {
+		if entry.Merged {
+			a.merged[name] = true
+		}
+		if entry.Commits > 0 {
+			a.commits[name] = entry.Commits
+		}
+		if upstreams[name] != "" || entry.Ahead > 0 || entry.Behind > 0 || entry.Gone {
+			a.tracks[name] = git.BranchStatus{Name: name, Upstream: upstreams[name], Ahead: entry.Ahead, Behind: entry.Behind, Gone: entry.Gone}
+		}
+	}
+}
+
+// saveCache records the merged state, commit counts, and tracking under the
+// ref state they were computed for.
+func (a *branchAnnotator) saveCache() {
+	a.mu.RLock()
+	entries := make(map[string]metacache.Entry, len(a.keys))
+	for name := range a.keys {
+		track := a.tracks[name]
+		entries[name] = metacache.Entry{
+			Merged:  a.merged[name],
+			Commits: a.commits[name],
+			Ahead:   track.Ahead,
+			Behind:  track.Behind,
+			Gone:    track.Gone,
+		}
+	}
+	a.mu.RUnlock()
+	_ = a.cache.store.Save(a.cache.repo, a.keys, entries)
+}
+
+// enrich reads the commit counts, the upstream tracking state, and the
+// commit subjects concurrently, sending on updates as each arrives, and
+// closes updates once all are in or ctx is cancelled. Like visit times they
+// are informational, so any that cannot be read are left out. Counts and
+// tracking found in the cache are not read again; freshly read ones are
+// saved there when both succeeded.
+func (a *branchAnnotator) enrich(ctx context.Context) {
+	defer close(a.updates)
+	var counted, tracked bool
+	parts := []func(){
+		func() {
+			if subjects, err := a.client.BranchSubjects(ctx); err == nil {
+				a.mu.Lock()
+				a.subjects = subjects
+				a.mu.Unlock()
+			}
+		},
+	}
+	if !a.cached {
+		parts = append(parts,
+			func() {
+				commits, err := commitCountSet(ctx, a.client, a.base)
+				if err != nil {
+					return
+				}
+				a.mu.Lock()
+				a.commits = commits
+				a.mu.Unlock()
+				counted = true
+			},
+			func() {
+				statuses, err := a.client.BranchStatuses(ctx)
+				if err != nil {
+					return
+				}
+				a.mu.Lock()
+				for _, status := range statuses {
+					a.tracks[status.Name] = status
+				}
+				a.tracked = true
+				a.mu.Unlock()
+				tracked = true
+			},
+		)
+	}
+
+	var wg sync.WaitGroup
+	for _, read := range parts {
+		wg.Add(1)
+		go func(read func()) {
+			defer wg.Done()
+			read()
+			// A pending update already covers this one.
+			select {
+			case a.updates <- struct{}{}:
+			default:
+			}
+		}(read)
+	}
+	wg.Wait()
+	if a.cache != nil && counted && tracked {
+		a.saveCache()
+	}
+}
+
+// annotate classifies branches and appends up to limit remote-tracking
+// branches after them, or every one when limit is 0. The default branch is
+// pinned first when configured. With an active filter, branches it does not
+// match are dropped and at most limit others follow the current branch.
+func (a *branchAnnotator) annotate(branches []ui.Branch, limit int) annotatedRows {
+	branches = a.pin(branches)
+	if a.filter.active() {
+		branches = a.keep(branches)
+		if limit > 0 && len(branches) > limit+1 {
+			branches = branches[:limit+1]
+		}
+	}
+	a.classify(branches)
+	return a.rows(append(branches, a.remoteRows(limit).branches...))
+}
+
+// pin moves the default branch right below the current branch, or to the
+// top when the current branch does not lead branches, adding it when it is
+// missing. It returns branches unchanged unless ui.pin_default is set.
+func (a *branchAnnotator) pin(branches []ui.Branch) []ui.Branch {
+	if !a.pinned {
+		return branches
+	}
+	pinned := make([]ui.Branch, 0, len(branches)+1)
+	rest := branches
+	if len(branches) > 0 && branches[0].Current {
+		if branches[0].Name == a.defaultBranch {
+			return branches
+		}
+		pinned = append(pinned, branches[0])
+		rest = branches[1:]
+	}
+	pinned = append(pinned, ui.Branch{Name: a.defaultBranch})
+	for _, branch := range rest {
+		if branch.Name != a.defaultBranch {
+			pinned = append(pinned, branch)
+		}
+	}
+	return pinned
+}
+
+// remoteRows returns up to limit classified remote-tracking rows, or every one when limit is 0.
+func (a *branchAnnotator) remoteRows(limit int) annotatedRows {
+	rows := make([]ui.Branch, 0, len(a.remotes))
+	for _, name := range a.remotes {
+		rows = append(rows, ui.Branch{Name: name, Remote: true})
+	}
+	rows = a.keep(rows)
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	a.classify(rows)
+	return a.rows(rows)
+}
+
+// rows provides classified branches to the selector.
+func (a *branchAnnotator) rows(branches []ui.Branch) annotatedRows {
+	return annotatedRows{annotator: a, branches: branches}
+}
+
+// annotatedRows provides classified branches to the selector and builds the
+// detail text of a row only when the selector draws it, so that long lists
+// open without formatting a label for every branch.
+type annotatedRows struct {
+	annotator *branchAnnotator
+	branches  []ui.Branch
+}
+
+func (r annotatedRows) Len() int { return len(r.branches) }
+
+func (r annotatedRows) Branch(i int) ui.Branch { return r.branches[i] }
+
+func (r annotatedRows) Detail(i int) string { return r.annotator.detail(r.branches[i]) }
+
+// keep returns the current branch and the branches matched by the filter.
+func (a *branchAnnotator) keep(branches []ui.Branch) []ui.Branch {
+	if !a.filter.active() {
+		return branches
+	}
+	kept := branches[:0]
+	for _, branch := range branches {
+		if branch.Current || a.kept(branch.Name) {
+			kept = append(kept, branch)
+		}
+	}
+	return kept
+}
+
+// kept reports whether the filter selects the branch called name.
+func (a *branchAnnotator) kept(name string) bool {
+	switch {
+	case !a.filter.matches(a.authors[name], a.dates[name]):
+		return false
+	case a.filter.contains != "" && !a.containing[name]:
+		return false
+	case a.filter.noMerged && a.merged[name]:
+		return false
+	}
+	return true
+}
+
+// classify sets the merged and stale state and the labels of each branch.
+func (a *branchAnnotator) classify(branches []ui.Branch) {
+	for i := range branches {
+		branch := &branches[i]
+		branch.Merged = a.merged[branch.Name]
+		branch.Default = !branch.Remote && a.defaultBranch != "" && branch.Name == a.defaultBranch
+		if !branch.Remote {
+			branch.Labels = a.labels[branch.Name]
+		}
+		if date, ok := a.dates[branch.Name]; ok && !a.staleBefore.IsZero() && !date.IsZero() {
+			branch.Stale = date.Before(a.staleBefore)
+		}
+	}
+}
+
+// detail returns the detail text of a classified branch: its states, when
+// it was last visited, how many commits it has over the base when it is not
+// merged, how far it is ahead of and behind its upstream, and whether it
+// has commits its upstream lacks, followed by the subject of its last
+// commit. Parts enrich has not read yet are left out.
+func (a *branchAnnotator) detail(branch ui.Branch) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	tags := []string{}
+	if visited, ok := a.visited[branch.Name]; ok && !branch.Current {
+		tags = append(tags, "visited "+a.timeFormat.Format(visited, a.now))
+	}
+	if n := a.commits[branch.Name]; n > 0 && !branch.Merged {
+		tags = append(tags, commitCountLabel(n))
+	}
+	if track := trackLabel(a.tracks[branch.Name]); track != "" {
+		tags = append(tags, track)
+	}
+	if a.tracked && !branch.Remote && isUnpushed(a.tracks[branch.Name], branch.Merged || branch.Name == a.base) {
+		tags = append(tags, "unpushed")
+	}
+	switch {
+	case branch.Merged && a.empty[branch.Name]:
+		tags = append(tags, "empty")
+	case branch.Merged:
+		tags = append(tags, "merged")
+	}
+	if branch.Stale {
+		tags = append(tags, "stale")
+	}
+	parts := []string{}
+	if len(tags) > 0 {
+		parts = append(parts, "("+strings.Join(tags, ", ")+")")
+	}
+	if subject := a.subjects[branch.Name]; subject != "" {
+		parts = append(parts, subject)
+	}
+	return strings.Join(parts, " ")
+}
+
+// trackLabel describes how far a branch is ahead of and behind its
+// upstream, as ↑2 ↓1, or that the upstream is gone; it is empty for a
+// branch in sync with its upstream or without one.
+func trackLabel(status git.BranchStatus) string {
+	if status.Gone {
+		return "upstream gone"
+	}
+	counts := []string{}
+	if status.Ahead > 0 {
+		counts = append(counts, "↑"+strconv.Itoa(status.Ahead))
+	}
+	if status.Behind > 0 {
+		counts = append(counts, "↓"+strconv.Itoa(status.Behind))
+	}
+	return strings.Join(counts, " ")
+}
+
+// isUnpushed reports whether a local branch has commits that its upstream
+// lacks: it is ahead of the upstream, or it is not merged and has no
+// upstream or one that is gone. Deleting such a branch loses work.
+func isUnpushed(status git.BranchStatus, merged bool) bool {
+	return status.Ahead > 0 || (!merged && (status.Upstream == "" || status.Gone))
+}
+
+// commitCountLabel describes n commits over the base branch.
+func commitCountLabel(n int) string {
+	if n == 1 {
+		return "1 commit"
+	}
+	return strconv.Itoa(n) + " commits"
+}
+
+// baseBranchName returns the configured or detected base branch, or "" when
+// none can be determined.
+func baseBranchName(ctx context.Context, client *git.Client, cfg config.Config) (string, error) {
+	base, err := client.BaseBranch(ctx, cfg.Base)
+	if errors.Is(err, git.ErrBaseBranchNotFound) {
+		return "", nil
+	}
+	return base, err
+}
+
+// commitCountSet returns how many commits each branch has that base lacks.
+// It is empty when base is "".
+func commitCountSet(ctx context.Context, client *git.Client, base string) (map[string]int, error) {
+	if base == "" {
+		return map[string]int{}, nil
+	}
+	return client.CommitCounts(ctx, base)
+}
+
+// mergedBranchSet returns the branches merged into base, excluding base
+// itself. It is empty when base is "".
+func mergedBranchSet(ctx context.Context, client *git.Client, base string) (map[string]bool, error) {
+	merged := map[string]bool{}
+	if base == "" {
+		return merged, nil
+	}
+
+	names, err := client.MergedBranches(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if name != base {
+			merged[name] = true
+		}
+	}
+	return merged, nil
+}
+
+// emptyBranchSet returns the local branches other than base whose tip is
+// the tip of base. It is empty when base is "".
+func emptyBranchSet(ctx context.Context, client *git.Client, base string) (map[string]bool, error) {
+	empty := map[string]bool{}
+	if base == "" {
+		return empty, nil
+	}
+
+	names, err := client.EmptyBranches(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		empty[name] = true
+	}
+	return empty, nil
+}
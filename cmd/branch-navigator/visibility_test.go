@@ -0,0 +1,564 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/match"
+	"branch-navigator/internal/navigator"
+	"branch-navigator/internal/platform/config"
+	"branch-navigator/internal/platform/metacache"
+	"branch-navigator/internal/ui"
+)
+
+func TestBranchAnnotator(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	old := now.AddDate(0, 0, -100).Unix()
+	recent := now.AddDate(0, 0, -1).Unix()
+	refs := "refs/heads/feature/x\t" + itoa(recent) + "\n" +
+		"refs/remotes/origin/HEAD\t" + itoa(recent) + "\trefs/remotes/origin/main\n" +
+		"refs/remotes/origin/topic\t" + itoa(recent) + "\n" +
+		"refs/remotes/origin/main\t" + itoa(recent) + "\n" +
+		"refs/heads/main\t" + itoa(recent) + "\n" +
+		"refs/heads/old\t" + itoa(old) + "\n"
+	reflog := "HEAD@{" + itoa(now.Add(-time.Hour).Unix()) + "}\tcheckout: moving from feature/x to main\n" +
+		"HEAD@{" + itoa(now.Add(-3*time.Hour).Unix()) + "}\tcheckout: moving from main to feature/x\n" +
+		"HEAD@{" + itoa(now.AddDate(0, 0, -10).Unix()) + "}\tcheckout: moving from main to old\n"
+
+	cases := map[string]struct {
+		act   action
+		limit int
+		want  []ui.Branch
+	}{
+		"checkout lists remote branches": {
+			act:   actionCheckout,
+			limit: 10,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "feature/x", Detail: "(visited 3h ago, 2 commits, unpushed)"},
+				{Name: "old", Merged: true, Stale: true, Detail: "(visited 10d ago, merged, stale)"},
+				{Name: "origin/topic", Remote: true, Detail: "(1 commit)"},
+				{Name: "origin/main", Remote: true, Merged: true, Detail: "(merged)"},
+			},
+		},
+		"no limit lists every remote branch": {
+			act:   actionRebase,
+			limit: 0,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "feature/x", Detail: "(visited 3h ago, 2 commits, unpushed)"},
+				{Name: "old", Merged: true, Stale: true, Detail: "(visited 10d ago, merged, stale)"},
+				{Name: "origin/topic", Remote: true, Detail: "(1 commit)"},
+				{Name: "origin/main", Remote: true, Merged: true, Detail: "(merged)"},
+			},
+		},
+		"remote branches respect the limit": {
+			act:   actionMerge,
+			limit: 1,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "feature/x", Detail: "(visited 3h ago, 2 commits, unpushed)"},
+				{Name: "old", Merged: true, Stale: true, Detail: "(visited 10d ago, merged, stale)"},
+				{Name: "origin/topic", Remote: true, Detail: "(1 commit)"},
+			},
+		},
+		"delete lists local branches only": {
+			act:   actionDelete,
+			limit: 10,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "feature/x", Detail: "(visited 3h ago, 2 commits, unpushed)"},
+				{Name: "old", Merged: true, Stale: true, Detail: "(visited 10d ago, merged, stale)"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{
+				"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": refs,
+				"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                  "origin/main",
+				"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes": "refs/heads/main\nrefs/heads/old\nrefs/remotes/origin/main\n",
+				"reflog -n 300 --date=unix --format=%gd%x09%gs":                          reflog,
+				"--version": "git version 2.43.0",
+				"for-each-ref --format=%(refname)%09%(symref)%09%(ahead-behind:main) refs/heads refs/remotes": "refs/heads/feature/x\t\t2 0\n" +
+					"refs/remotes/origin/topic\t\t1 5\nrefs/heads/old\t\t0 9\nrefs/heads/main\t\t0 0\n",
+			}}
+			branches := []ui.Branch{{Name: "main", Current: true}, {Name: "feature/x"}, {Name: "old"}}
+
+			annotator, err := newBranchAnnotator(context.Background(), git.NewClient(runner), tc.act, config.Default(), navigator.DefaultMaxReflog, branchFilter{}, now, nil)
+			if err != nil {
+				t.Fatalf("newBranchAnnotator returned error: %v", err)
+			}
+			annotator.enrich(context.Background())
+			rows := annotator.annotate(branches, tc.limit)
+			for i := 0; i < rows.Len(); i++ {
+				if detail := rows.Branch(i).Detail; detail != "" {
+					t.Fatalf("row %d carries detail %q before it is drawn", i, detail)
+				}
+			}
+			got := rowsOf(rows)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("annotate() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBranchAnnotatorEnrich(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	recent := itoa(now.AddDate(0, 0, -1).Unix())
+	runner := &recordingRunner{outputs: map[string]string{
+		"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": "refs/heads/main\t" + recent + "\n" +
+			"refs/heads/feature/x\t" + recent + "\n" +
+			"refs/heads/feature/y\t" + recent + "\n",
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                  "origin/main",
+		"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes": "refs/heads/main\n",
+		"--version": "git version 2.43.0",
+		"for-each-ref --format=%(refname)%09%(symref)%09%(ahead-behind:main) refs/heads refs/remotes": "refs/heads/feature/x\t\t2 0\nrefs/heads/feature/y\t\t1 0\n",
+		"for-each-ref --sort=-committerdate --format=%(refname:lstrip=2)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track) refs/heads": "feature/x\t" + recent + "\torigin/feature/x\t[ahead 1, behind 3]\n" +
+			"feature/y\t" + recent + "\torigin/feature/y\t[gone]\n",
+		"for-each-ref --format=%(refname)%09%(symref)%09%(contents:subject) refs/heads refs/remotes": "refs/heads/feature/x\t\tfix login\nrefs/heads/feature/y\t\tdrop me\n",
+	}}
+	annotator, err := newBranchAnnotator(context.Background(), git.NewClient(runner), actionCheckout, config.Default(), navigator.DefaultMaxReflog, branchFilter{}, now, nil)
+	if err != nil {
+		t.Fatalf("newBranchAnnotator returned error: %v", err)
+	}
+	rows := annotator.annotate([]ui.Branch{{Name: "main", Current: true}, {Name: "feature/x"}, {Name: "feature/y"}}, 0)
+	if detail := rows.Detail(1); detail != "" {
+		t.Fatalf("detail before enrich = %q, want none", detail)
+	}
+
+	go annotator.enrich(context.Background())
+	received := 0
+	for range annotator.updates {
+		received++
+	}
+	if received == 0 {
+		t.Fatal("enrich announced no updates")
+	}
+	want := []ui.Branch{
+		{Name: "main", Current: true, Default: true},
+		{Name: "feature/x", Detail: "(2 commits, ↑1 ↓3, unpushed) fix login"},
+		{Name: "feature/y", Detail: "(1 commit, upstream gone, unpushed) drop me"},
+	}
+	if got := rowsOf(rows); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows after enrich = %+v, want %+v", got, want)
+	}
+}
+
+func TestBranchAnnotatorCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	recent := itoa(now.AddDate(0, 0, -1).Unix())
+	refs := "refs/heads/main\t" + recent + "\n" +
+		"refs/heads/feature/x\t" + recent + "\n" +
+		"refs/heads/old\t" + recent + "\n"
+	refStates := "refs/heads/main\taaa\t\t\nrefs/heads/feature/x\tbbb\t\torigin/feature/x\nrefs/heads/old\tccc\t\t\n" +
+		"refs/remotes/origin/feature/x\tddd\t\t\n"
+	computed := map[string]string{
+		"--version": "git version 2.43.0",
+		"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes":                                                                     "refs/heads/main\nrefs/heads/old\n",
+		"for-each-ref --format=%(refname)%09%(symref)%09%(ahead-behind:main) refs/heads refs/remotes":                                                "refs/heads/feature/x\t\t2 0\n",
+		"for-each-ref --sort=-committerdate --format=%(refname:lstrip=2)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track) refs/heads": "feature/x\t" + recent + "\torigin/feature/x\t[ahead 1]\n",
+	}
+	want := []ui.Branch{
+		{Name: "main", Current: true, Default: true},
+		{Name: "feature/x", Detail: "(2 commits, ↑1, unpushed)"},
+		{Name: "old", Merged: true, Detail: "(merged)"},
+	}
+	cache := &metadataCache{store: metacache.Open(filepath.Join(t.TempDir(), "metadata.json")), repo: "/src/repo"}
+
+	// run annotates the branches with refStates as the state of the refs and
+	// reports whether the metadata had to be computed.
+	run := func(refStates string) bool {
+		t.Helper()
+		outputs := map[string]string{
+			"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": refs,
+			"for-each-ref --format=%(refname)%09%(objectname)%09%(symref)%09%(upstream:short) refs/heads refs/remotes":                                          refStates,
+			"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                                                                                             "origin/main",
+		}
+		for args, out := range computed {
+			outputs[args] = out
+		}
+		runner := &recordingRunner{outputs: outputs}
+		annotator, err := newBranchAnnotator(context.Background(), git.NewClient(runner), actionDelete, config.Default(), navigator.DefaultMaxReflog, branchFilter{}, now, cache)
+		if err != nil {
+			t.Fatalf("newBranchAnnotator returned error: %v", err)
+		}
+		annotator.enrich(context.Background())
+		rows := annotator.annotate([]ui.Branch{{Name: "main", Current: true}, {Name: "feature/x"}, {Name: "old"}}, 0)
+		if got := rowsOf(rows); !reflect.DeepEqual(got, want) {
+			t.Fatalf("rows = %+v, want %+v", got, want)
+		}
+		for _, call := range runner.calls {
+			if _, ok := computed[strings.Join(call, " ")]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !run(refStates) {
+		t.Fatal("the first run should compute the metadata")
+	}
+	if run(refStates) {
+		t.Fatal("unchanged refs should reuse the cached metadata")
+	}
+	if !run(strings.Replace(refStates, "ddd", "eee", 1)) {
+		t.Fatal("a moved upstream should compute the metadata again")
+	}
+	if run(strings.Replace(refStates, "ddd", "eee", 1)) {
+		t.Fatal("the recomputed metadata should be cached")
+	}
+}
+
+func TestBranchAnnotatorFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	recent := itoa(now.AddDate(0, 0, -1).Unix())
+	outputs := map[string]string{
+		"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": "refs/heads/main\t" + recent + "\t\tGrace Hopper <grace@example.com>\n" +
+			"refs/heads/ada/one\t" + recent + "\t\tAda Lovelace <ada@example.com>\n" +
+			"refs/heads/grace/two\t" + recent + "\t\tGrace Hopper <grace@example.com>\n" +
+			"refs/heads/ada/three\t" + recent + "\t\tAda Lovelace <ada@example.com>\n" +
+			"refs/remotes/origin/ada/four\t" + recent + "\t\tAda Lovelace <ada@example.com>\n" +
+			"refs/remotes/origin/grace/five\t" + recent + "\t\tGrace Hopper <grace@example.com>\n",
+		"for-each-ref --contains=abc1234 --format=%(refname) refs/heads refs/remotes": "refs/heads/grace/two\nrefs/heads/ada/three\nrefs/remotes/origin/grace/five\n",
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                       "origin/main",
+		"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes":      "refs/heads/main\nrefs/heads/ada/one\nrefs/remotes/origin/ada/four\n",
+	}
+	author, err := match.NewFilter("ada@", match.ModeRegex)
+	if err != nil {
+		t.Fatalf("NewFilter returned error: %v", err)
+	}
+
+	cases := map[string]struct {
+		filter branchFilter
+		limit  int
+		want   []ui.Branch
+	}{
+		"author applies before the limit": {
+			filter: branchFilter{author: author},
+			limit:  1,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "ada/one", Merged: true, Detail: "(merged)"},
+				{Name: "origin/ada/four", Remote: true, Merged: true, Detail: "(merged)"},
+			},
+		},
+		"contains": {
+			filter: branchFilter{contains: "abc1234"},
+			limit:  10,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "grace/two"},
+				{Name: "ada/three"},
+				{Name: "origin/grace/five", Remote: true},
+			},
+		},
+		"no merged": {
+			filter: branchFilter{noMerged: true},
+			limit:  10,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "grace/two"},
+				{Name: "ada/three"},
+				{Name: "origin/grace/five", Remote: true},
+			},
+		},
+		"author and contains": {
+			filter: branchFilter{author: author, contains: "abc1234"},
+			limit:  10,
+			want: []ui.Branch{
+				{Name: "main", Current: true, Default: true},
+				{Name: "ada/three"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: outputs}
+			annotator, err := newBranchAnnotator(context.Background(), git.NewClient(runner), actionCheckout, config.Default(), navigator.DefaultMaxReflog, tc.filter, now, nil)
+			if err != nil {
+				t.Fatalf("newBranchAnnotator returned error: %v", err)
+			}
+			branches := []ui.Branch{{Name: "main", Current: true}, {Name: "ada/one"}, {Name: "grace/two"}, {Name: "ada/three"}}
+			got := rowsOf(annotator.annotate(branches, tc.limit))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("annotate() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBranchFilterMatches(t *testing.T) {
+	t.Parallel()
+
+	author, err := match.NewFilter("ada", match.ModeRegex)
+	if err != nil {
+		t.Fatalf("NewFilter returned error: %v", err)
+	}
+	may := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		filter branchFilter
+		author string
+		date   time.Time
+		want   bool
+	}{
+		"zero filter":         {author: "Grace <grace@example.com>", date: may, want: true},
+		"other author":        {filter: branchFilter{author: author}, author: "Grace <grace@example.com>", date: may},
+		"inside the window":   {filter: branchFilter{since: may, before: june}, date: may.AddDate(0, 0, 10), want: true},
+		"on since":            {filter: branchFilter{since: may}, date: may, want: true},
+		"older than since":    {filter: branchFilter{since: may}, date: may.Add(-time.Second)},
+		"newer than before":   {filter: branchFilter{before: june}, date: june.Add(time.Second)},
+		"author and window":   {filter: branchFilter{author: author, since: may}, author: "Ada <ada@example.com>", date: june, want: true},
+		"author outside time": {filter: branchFilter{author: author, before: may}, author: "Ada <ada@example.com>", date: june},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.filter.matches(tc.author, tc.date); got != tc.want {
+				t.Fatalf("matches(%q, %v) = %v, want %v", tc.author, tc.date, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBranchAnnotatorPin(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		pin      bool
+		branches []ui.Branch
+		want     []string
+	}{
+		"off keeps recency order": {
+			branches: []ui.Branch{{Name: "feature/x", Current: true}, {Name: "old"}, {Name: "main"}},
+			want:     []string{"feature/x", "old", "main"},
+		},
+		"moves the default below the current branch": {
+			pin:      true,
+			branches: []ui.Branch{{Name: "feature/x", Current: true}, {Name: "old"}, {Name: "main"}},
+			want:     []string{"feature/x", "main", "old"},
+		},
+		"adds a default beyond the limit": {
+			pin:      true,
+			branches: []ui.Branch{{Name: "feature/x", Current: true}, {Name: "old"}},
+			want:     []string{"feature/x", "main", "old"},
+		},
+		"leaves a current default in place": {
+			pin:      true,
+			branches: []ui.Branch{{Name: "main", Current: true}, {Name: "old"}},
+			want:     []string{"main", "old"},
+		},
+		"leads without a current branch": {
+			pin:      true,
+			branches: []ui.Branch{{Name: "old"}, {Name: "main"}},
+			want:     []string{"main", "old"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			annotator := &branchAnnotator{defaultBranch: "main", pinned: tc.pin}
+			var got []string
+			for _, branch := range annotator.pin(tc.branches) {
+				got = append(got, branch.Name)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("pin() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStreamBranches(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	recent := now.AddDate(0, 0, -1).Unix()
+	runner := &recordingRunner{outputs: map[string]string{
+		"rev-parse --abbrev-ref HEAD": "main",
+		"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": "refs/heads/main\t" + itoa(recent) + "\n" +
+			"refs/heads/feature/x\t" + itoa(recent) + "\n" +
+			"refs/heads/old\t" + itoa(recent) + "\n" +
+			"refs/remotes/origin/topic\t" + itoa(recent) + "\n",
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                      "origin/main",
+		"for-each-ref --merged=main --format=%(refname) refs/heads refs/remotes":     "refs/heads/main\nrefs/heads/old\n",
+		"for-each-ref --format=%(refname:lstrip=2) --sort=-committerdate refs/heads": "main\nfeature/x\nold\n",
+		"branch --list --format=%(refname:lstrip=2)":                                 "feature/x\nmain\nold\n",
+	}}
+	client := git.NewClient(runner)
+	nav, err := navigator.New(client)
+	if err != nil {
+		t.Fatalf("navigator.New returned error: %v", err)
+	}
+	annotator, err := newBranchAnnotator(context.Background(), client, actionCheckout, config.Default(), navigator.DefaultMaxReflog, branchFilter{}, now, nil)
+	if err != nil {
+		t.Fatalf("newBranchAnnotator returned error: %v", err)
+	}
+
+	first, load, err := streamBranches(context.Background(), client, nav, annotator)
+	if err != nil {
+		t.Fatalf("streamBranches returned error: %v", err)
+	}
+	if want := []ui.Branch{{Name: "main", Current: true, Default: true}}; !reflect.DeepEqual(rowsOf(first), want) {
+		t.Fatalf("first rows = %+v, want %+v", first, want)
+	}
+
+	pages := [][]ui.Branch{
+		{{Name: "feature/x"}},
+		{{Name: "old", Merged: true, Detail: "(merged)"}},
+		{{Name: "origin/topic", Remote: true}},
+		nil,
+	}
+	for i, want := range pages {
+		page, err := load(1)
+		if err != nil {
+			t.Fatalf("load returned error: %v", err)
+		}
+		if got := rowsOf(page); !reflect.DeepEqual(got, want) {
+			t.Fatalf("page %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestStreamBranchesPinsDefault(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	recent := now.AddDate(0, 0, -1).Unix()
+	runner := &recordingRunner{outputs: map[string]string{
+		"rev-parse --abbrev-ref HEAD": "feature/x",
+		"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": "refs/heads/feature/x\t" + itoa(recent) + "\n" +
+			"refs/heads/old\t" + itoa(recent) + "\n" +
+			"refs/heads/main\t" + itoa(recent) + "\n",
+		"symbolic-ref --quiet --short refs/remotes/origin/HEAD":                      "origin/main",
+		"for-each-ref --format=%(refname:lstrip=2) --sort=-committerdate refs/heads": "feature/x\nold\nmain\n",
+		"branch --list --format=%(refname:lstrip=2)":                                 "feature/x\nmain\nold\n",
+	}}
+	client := git.NewClient(runner)
+	nav, err := navigator.New(client)
+	if err != nil {
+		t.Fatalf("navigator.New returned error: %v", err)
+	}
+	cfg := config.Default()
+	cfg.UIPinDefault = true
+	annotator, err := newBranchAnnotator(context.Background(), client, actionDelete, cfg, navigator.DefaultMaxReflog, branchFilter{}, now, nil)
+	if err != nil {
+		t.Fatalf("newBranchAnnotator returned error: %v", err)
+	}
+
+	first, load, err := streamBranches(context.Background(), client, nav, annotator)
+	if err != nil {
+		t.Fatalf("streamBranches returned error: %v", err)
+	}
+	if want := []ui.Branch{{Name: "feature/x", Current: true}, {Name: "main", Default: true}}; !reflect.DeepEqual(rowsOf(first), want) {
+		t.Fatalf("first rows = %+v, want %+v", rowsOf(first), want)
+	}
+	var rest []ui.Branch
+	for {
+		page, err := load(1)
+		if err != nil {
+			t.Fatalf("load returned error: %v", err)
+		}
+		if page == nil {
+			break
+		}
+		rest = append(rest, rowsOf(page)...)
+	}
+	if want := []ui.Branch{{Name: "old"}}; !reflect.DeepEqual(rest, want) {
+		t.Fatalf("later rows = %+v, want %+v", rest, want)
+	}
+}
+
+func TestStreamBranchesSkipsPagesByOtherAuthors(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	recent := itoa(now.AddDate(0, 0, -1).Unix())
+	runner := &recordingRunner{outputs: map[string]string{
+		"rev-parse --abbrev-ref HEAD": "main",
+		"for-each-ref --sort=-committerdate --format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail) refs/heads refs/remotes": "refs/heads/main\t" + recent + "\t\tAda <ada@example.com>\n" +
+			"refs/heads/feature/x\t" + recent + "\t\tGrace <grace@example.com>\n" +
+			"refs/heads/feature/y\t" + recent + "\t\tGrace <grace@example.com>\n" +
+			"refs/heads/old\t" + recent + "\t\tAda <ada@example.com>\n",
+		"for-each-ref --format=%(refname:lstrip=2) --sort=-committerdate refs/heads": "main\nfeature/x\nfeature/y\nold\n",
+		"branch --list --format=%(refname:lstrip=2)":                                 "feature/x\nfeature/y\nmain\nold\n",
+	}}
+	client := git.NewClient(runner)
+	nav, err := navigator.New(client)
+	if err != nil {
+		t.Fatalf("navigator.New returned error: %v", err)
+	}
+	author, err := match.NewFilter("ada", match.ModeRegex)
+	if err != nil {
+		t.Fatalf("NewFilter returned error: %v", err)
+	}
+	annotator, err := newBranchAnnotator(context.Background(), client, actionDelete, config.Default(), navigator.DefaultMaxReflog, branchFilter{author: author}, now, nil)
+	if err != nil {
+		t.Fatalf("newBranchAnnotator returned error: %v", err)
+	}
+
+	_, load, err := streamBranches(context.Background(), client, nav, annotator)
+	if err != nil {
+		t.Fatalf("streamBranches returned error: %v", err)
+	}
+	page, err := load(1)
+	if err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+	if got, want := rowsOf(page), []ui.Branch{{Name: "old"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("first page = %+v, want %+v", got, want)
+	}
+}
+
+// rowsOf returns the rows of p with their detail text filled in, or nil
+// when p has none.
+func rowsOf(p ui.Provider) []ui.Branch {
+	if p == nil || p.Len() == 0 {
+		return nil
+	}
+	rows := make([]ui.Branch, p.Len())
+	for i := range rows {
+		rows[i] = p.Branch(i)
+		rows[i].Detail = p.Detail(i)
+	}
+	return rows
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"branch-navigator/internal/git"
+	"branch-navigator/internal/ui"
+)
+
+// worktreeActionDetails replaces the checkout labels in a bare repository,
+// where checking out means opening a worktree. The branch HEAD names is
+// not checked out anywhere yet, so it can be chosen too.
+var worktreeActionDetails = ui.ActionDetails{
+	Name:         "Open worktree",
+	Description:  "This repository is bare; the selected branch is checked out in a worktree of its own.",
+	EnterLabel:   "open a worktree for the selected branch",
+	AllowCurrent: true,
+}
+
+// bareRepoInfo returns the layout of the repository client works in when it
+// is bare, and the zero RepoInfo otherwise or when it cannot be told.
+func bareRepoInfo(ctx context.Context, client *git.Client) git.RepoInfo {
+	if info, err := client.RepoInfo(ctx); err == nil && info.Bare() {
+		return info
+	}
+	return git.RepoInfo{}
+}
+
+// localBranchName returns the local branch a selection is checked out as:
+// the branch itself, or a remote-tracking branch without its remote.
+func localBranchName(branch string, remote bool) string {
+	if !remote {
+		return branch
+	}
+	if _, name, ok := strings.Cut(branch, "/"); ok {
+		return name
+	}
+	return branch
+}
+
+// worktreePath returns where the worktree of branch is created in a bare
+// repository: a directory under root named after the branch, with slashes
+// turned into dashes so that feature/x does not nest.
+func worktreePath(root, branch string) string {
+	return filepath.Join(root, strings.ReplaceAll(branch, "/", "-"))
+}
+
+// handleWorktreeCheckout stands in for checkout in a bare repository. It
+// points at the worktree the branch is already checked out in, or offers
+// to create one, and returns the worktree path; "" means the user declined.
+func handleWorktreeCheckout(ctx context.Context, client *git.Client, info git.RepoInfo, in io.Reader, out io.Writer, branch string, remote bool) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("git client is not configured")
+	}
+	local := localBranchName(branch, remote)
+
+	worktrees, err := client.Worktrees(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, worktree := range worktrees {
+		if worktree.Branch == local {
+			fmt.Fprintf(out, "'%s' is already checked out in %s\n", local, worktree.Path)
+			return worktree.Path, nil
+		}
+	}
+
+	path := worktreePath(info.Root, local)
+	confirmed, err := ui.Confirmation{
+		Summary: fmt.Sprintf("This repository is bare, so '%s' cannot be checked out here. Create a worktree for it at %s?", local, path),
+	}.Confirm(in, out)
+	if err != nil || !confirmed {
+		return "", err
+	}
+	message, err := client.AddWorktree(ctx, path, local)
+	if err != nil {
+		return "", err
+	}
+	printIfNotEmpty(out, message)
+	fmt.Fprintf(out, "Worktree ready: cd %s\n", shellQuote(path))
+	return path, nil
+}
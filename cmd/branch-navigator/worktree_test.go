@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"branch-navigator/internal/git"
+)
+
+func TestHandleWorktreeCheckout(t *testing.T) {
+	t.Parallel()
+
+	worktreeList := "worktree /src/app/.bare\nbare\n\nworktree /src/app/main\nHEAD aaa\nbranch refs/heads/main"
+	info := git.RepoInfo{Kind: git.RepoWorktreeHub, GitDir: "/src/app/.bare", Root: "/src/app"}
+
+	cases := map[string]struct {
+		branch   string
+		remote   bool
+		answer   string
+		wantPath string
+		wantAdd  []string
+		wantOut  string
+	}{
+		"create": {
+			branch:   "feature/x",
+			answer:   "y\n",
+			wantPath: "/src/app/feature-x",
+			wantAdd:  []string{"worktree", "add", "/src/app/feature-x", "feature/x"},
+			wantOut:  "Worktree ready: cd /src/app/feature-x\n",
+		},
+		"remote branch": {
+			branch:   "origin/fix/y",
+			remote:   true,
+			answer:   "y\n",
+			wantPath: "/src/app/fix-y",
+			wantAdd:  []string{"worktree", "add", "/src/app/fix-y", "fix/y"},
+		},
+		"declined": {
+			branch:  "feature/x",
+			answer:  "n\n",
+			wantOut: "Create a worktree for it at /src/app/feature-x?",
+		},
+		"already checked out": {
+			branch:   "main",
+			wantPath: "/src/app/main",
+			wantOut:  "'main' is already checked out in /src/app/main\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &recordingRunner{outputs: map[string]string{"worktree list --porcelain": worktreeList}}
+			out := &bytes.Buffer{}
+			path, err := handleWorktreeCheckout(context.Background(), git.NewClient(runner), info, strings.NewReader(tc.answer), out, tc.branch, tc.remote)
+			if err != nil {
+				t.Fatalf("handleWorktreeCheckout returned error: %v", err)
+			}
+			if path != tc.wantPath {
+				t.Fatalf("path = %q, want %q", path, tc.wantPath)
+			}
+			var added []string
+			for _, call := range runner.calls {
+				if call[0] == "worktree" && call[1] == "add" {
+					added = call
+				}
+			}
+			if !reflect.DeepEqual(added, tc.wantAdd) {
+				t.Fatalf("worktree add = %v, want %v", added, tc.wantAdd)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, out.String())
+			}
+		})
+	}
+}
@@ -4,4 +4,4 @@ go 1.22
 
 require golang.org/x/term v0.29.0
 
-require golang.org/x/sys v0.30.0 // indirect
+require golang.org/x/sys v0.30.0
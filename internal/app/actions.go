@@ -0,0 +1,221 @@
+package app
+
+import "branch-navigator/internal/ui"
+
+// Action names an action of the selector, such as checkout or merge. The
+// actions defined in the configuration file use their own names.
+type Action string
+
+// The built-in actions.
+const (
+	ActionCheckout    Action = "checkout"
+	ActionMerge       Action = "merge"
+	ActionMergeCheck  Action = "merge-check"
+	ActionDelete      Action = "delete"
+	ActionArchive     Action = "archive"
+	ActionUnarchive   Action = "unarchive"
+	ActionRebase      Action = "rebase-interactive"
+	ActionRemoteAdmin Action = "remote-admin"
+	ActionHeadHistory Action = "head-history"
+	ActionPush        Action = "push"
+	ActionLog         Action = "log"
+	ActionDiff        Action = "diff"
+	ActionBrowse      Action = "browse"
+	ActionCreate      Action = "create"
+	ActionCopy        Action = "copy"
+	ActionReset       Action = "reset"
+)
+
+// ActionSpec registers an action: the flag that selects it, how the selector
+// describes it, and which selected branches the --menu offers it for.
+type ActionSpec struct {
+	Action Action
+	// Flag selects the action on the command line, as -flag or --flag.
+	Flag    string
+	Details ui.ActionDetails
+	// Menu lists the action in the --menu shown after a branch is picked;
+	// actions that pick something other than a branch stay out of it.
+	Menu bool
+	// LocalOnly keeps the action off the menu for remote-tracking branches.
+	LocalOnly bool
+}
+
+// Actions lists every built-in action in the order the flags are reported
+// in errors and offered in the --menu. The flag-based entry points and the
+// menu both read it.
+var Actions = []ActionSpec{
+	{
+		Action: ActionCheckout,
+		Flag:   "c",
+		Details: ui.ActionDetails{
+			Name:        "Checkout branch",
+			Description: "Switch to the selected branch.",
+			EnterLabel:  "checkout the selected branch",
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionMerge,
+		Flag:   "m",
+		Details: ui.ActionDetails{
+			Name:        "Merge branch",
+			Description: "Merge the selected branch into the current branch.",
+			EnterLabel:  "merge the selected branch into the current branch",
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionMergeCheck,
+		Flag:   "merge-check",
+		Details: ui.ActionDetails{
+			Name:        "Merge check",
+			Description: "Check whether the selected branch merges cleanly into the current branch, without merging it.",
+			EnterLabel:  "check the merge of the selected branch",
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionDelete,
+		Flag:   "d",
+		Details: ui.ActionDetails{
+			Name:        "Delete branch",
+			Description: "Delete the selected local branch.",
+			EnterLabel:  "delete the selected branch",
+		},
+		Menu:      true,
+		LocalOnly: true,
+	},
+	{
+		Action: ActionArchive,
+		Flag:   "archive",
+		Details: ui.ActionDetails{
+			Name:        "Archive branch",
+			Description: "Tag the selected branch as archive/<branch> and delete it.",
+			EnterLabel:  "archive the selected branch",
+		},
+		Menu:      true,
+		LocalOnly: true,
+	},
+	{
+		Action: ActionUnarchive,
+		Flag:   "unarchive",
+		Details: ui.ActionDetails{
+			Name:        "Unarchive branch",
+			Description: "Restore the selected branch from its archive tag.",
+			EnterLabel:  "restore the selected branch",
+		},
+	},
+	{
+		Action: ActionRebase,
+		Flag:   "rebase-i",
+		Details: ui.ActionDetails{
+			Name:        "Interactive rebase",
+			Description: "Rebase the current branch onto the selected branch with git rebase -i.",
+			EnterLabel:  "rebase onto the selected branch",
+		},
+		Menu: true,
+	},
+	{Action: ActionRemoteAdmin, Flag: "remote-admin"},
+	{Action: ActionHeadHistory, Flag: "head-history"},
+	{
+		Action: ActionPush,
+		Flag:   "push",
+		Details: ui.ActionDetails{
+			Name:         "Push branch",
+			Description:  "Push the selected branch to its remote.",
+			EnterLabel:   "push the selected branch",
+			AllowCurrent: true,
+		},
+		Menu:      true,
+		LocalOnly: true,
+	},
+	{
+		Action: ActionLog,
+		Flag:   "log",
+		Details: ui.ActionDetails{
+			Name:        "Branch log",
+			Description: "Show the commits on the selected branch that the current branch does not have.",
+			EnterLabel:  "show the log of the selected branch",
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionDiff,
+		Flag:   "diff",
+		Details: ui.ActionDetails{
+			Name:        "Branch diff",
+			Description: "Show what the selected branch changed since it forked from the current branch.",
+			EnterLabel:  "show the diff of the selected branch",
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionBrowse,
+		Flag:   "browse",
+		Details: ui.ActionDetails{
+			Name:         "Browse branch",
+			Description:  "Open the selected branch on its code host in the web browser.",
+			EnterLabel:   "open the selected branch in the browser",
+			AllowCurrent: true,
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionCreate,
+		Flag:   "create",
+		Details: ui.ActionDetails{
+			Name:         "Create branch",
+			Description:  "Create a branch from the selected branch and switch to it.",
+			EnterLabel:   "create a branch from the selected branch",
+			AllowCurrent: true,
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionCopy,
+		Flag:   "copy",
+		Details: ui.ActionDetails{
+			Name:         "Copy branch",
+			Description:  "Create a branch at the tip of the selected branch without switching to it.",
+			EnterLabel:   "copy the selected branch",
+			AllowCurrent: true,
+		},
+		Menu: true,
+	},
+	{
+		Action: ActionReset,
+		Flag:   "reset",
+		Details: ui.ActionDetails{
+			Name:        "Reset branch",
+			Description: "Reset the current branch to the tip of the selected branch.",
+			EnterLabel:  "reset the current branch to the selected branch",
+		},
+		Menu: true,
+	},
+}
+
+// LookupAction returns the registry entry of act.
+func LookupAction(act Action) (ActionSpec, bool) {
+	for _, spec := range Actions {
+		if spec.Action == act {
+			return spec, true
+		}
+	}
+	return ActionSpec{}, false
+}
+
+// MenuActions returns the actions the --menu offers for selected: those of
+// Actions followed by extra, such as the actions defined in the
+// configuration, that are registered for the menu and accept a
+// remote-tracking branch when it is one, and the current branch when it is
+// that.
+func MenuActions(selected ui.Branch, extra []ActionSpec) []ActionSpec {
+	var specs []ActionSpec
+	for _, spec := range append(append([]ActionSpec{}, Actions...), extra...) {
+		if !spec.Menu || (selected.Remote && spec.LocalOnly) || (selected.Current && !spec.Details.AllowCurrent) {
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
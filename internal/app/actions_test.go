@@ -0,0 +1,79 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"branch-navigator/internal/ui"
+)
+
+func TestActions(t *testing.T) {
+	t.Parallel()
+
+	seen := map[Action]bool{}
+	flags := map[string]bool{}
+	for _, spec := range Actions {
+		if seen[spec.Action] {
+			t.Errorf("action %s is registered twice", spec.Action)
+		}
+		seen[spec.Action] = true
+		if spec.Flag == "" || flags[spec.Flag] {
+			t.Errorf("action %s has a missing or shared flag %q", spec.Action, spec.Flag)
+		}
+		flags[spec.Flag] = true
+		if spec.Menu && spec.Details.Name == "" {
+			t.Errorf("menu action %s has no selector details", spec.Action)
+		}
+	}
+	if spec, ok := LookupAction(ActionDelete); !ok || spec.Flag != "d" {
+		t.Fatalf("LookupAction(delete) = %+v, %v", spec, ok)
+	}
+	if _, ok := LookupAction("fixup"); ok {
+		t.Fatal("LookupAction found an action that is not registered")
+	}
+}
+
+func TestMenuActions(t *testing.T) {
+	t.Parallel()
+
+	custom := ActionSpec{Action: "fixup", Details: ui.ActionDetails{Name: "fixup"}, Menu: true}
+	cases := map[string]struct {
+		selected ui.Branch
+		extra    []ActionSpec
+		want     []Action
+	}{
+		"local branch": {
+			selected: ui.Branch{Name: "feature/x"},
+			want:     []Action{ActionCheckout, ActionMerge, ActionMergeCheck, ActionDelete, ActionArchive, ActionRebase, ActionPush, ActionLog, ActionDiff, ActionBrowse, ActionCreate, ActionCopy, ActionReset},
+		},
+		"remote branch": {
+			selected: ui.Branch{Name: "origin/feature/x", Remote: true},
+			want:     []Action{ActionCheckout, ActionMerge, ActionMergeCheck, ActionRebase, ActionLog, ActionDiff, ActionBrowse, ActionCreate, ActionCopy, ActionReset},
+		},
+		"current branch": {
+			selected: ui.Branch{Name: "main", Current: true},
+			want:     []Action{ActionPush, ActionBrowse, ActionCreate, ActionCopy},
+		},
+		"extra actions last": {
+			selected: ui.Branch{Name: "origin/feature/x", Remote: true},
+			extra:    []ActionSpec{custom},
+			want:     []Action{ActionCheckout, ActionMerge, ActionMergeCheck, ActionRebase, ActionLog, ActionDiff, ActionBrowse, ActionCreate, ActionCopy, ActionReset, "fixup"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []Action
+			for _, spec := range MenuActions(tc.selected, tc.extra) {
+				got = append(got, spec.Action)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("MenuActions() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
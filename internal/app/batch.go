@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BatchFunc applies an action to one item and returns a short outcome,
+// such as "pushed to origin", for the report.
+type BatchFunc func(ctx context.Context, item string) (string, error)
+
+// BatchResult is the outcome of one item of a batch.
+type BatchResult struct {
+	Item    string
+	Outcome string
+	// Err is why the item failed; nil means it succeeded.
+	Err error
+	// Skipped reports that the item never ran because the batch was
+	// cancelled first.
+	Skipped bool
+}
+
+// BatchReport collects the outcome of every item of a batch in order.
+type BatchReport struct {
+	Results []BatchResult
+}
+
+// Failed returns how many items failed or were skipped.
+func (r BatchReport) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if result.Err != nil || result.Skipped {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Exit codes of a batch, so that scripts can tell a partial failure from a
+// complete one.
+const (
+	// BatchSucceeded means every item succeeded.
+	BatchSucceeded = 0
+	// BatchFailed means no item succeeded.
+	BatchFailed = 1
+	// BatchPartial means some items succeeded and others failed.
+	BatchPartial = 4
+)
+
+// ExitCode returns BatchSucceeded, BatchPartial, or BatchFailed.
+func (r BatchReport) ExitCode() int {
+	switch failed := r.Failed(); {
+	case failed == 0:
+		return BatchSucceeded
+	case failed < len(r.Results):
+		return BatchPartial
+	default:
+		return BatchFailed
+	}
+}
+
+// Write prints one line per item, marked ✓ or ✗, followed by the totals.
+func (r BatchReport) Write(out io.Writer) {
+	width := 0
+	for _, result := range r.Results {
+		width = max(width, len(result.Item))
+	}
+	for _, result := range r.Results {
+		mark, outcome := "✓", result.Outcome
+		switch {
+		case result.Skipped:
+			mark, outcome = "-", "skipped"
+		case result.Err != nil:
+			mark, outcome = "✗", result.Err.Error()
+		}
+		fmt.Fprintf(out, "  %s %-*s  %s\n", mark, width, result.Item, firstLine(outcome))
+	}
+	failed := r.Failed()
+	fmt.Fprintf(out, "%d succeeded, %d failed.\n", len(r.Results)-failed, failed)
+}
+
+// RunBatch applies fn to every item in order, announcing each on out. A
+// failing item does not stop the batch; cancelling ctx does, and the items
+// that did not run are reported as skipped.
+func RunBatch(ctx context.Context, items []string, fn BatchFunc, out io.Writer) BatchReport {
+	report := BatchReport{Results: make([]BatchResult, len(items))}
+	for i, item := range items {
+		report.Results[i].Item = item
+		if ctx.Err() != nil {
+			report.Results[i].Skipped = true
+			continue
+		}
+		fmt.Fprintf(out, "==> [%d/%d] %s\n", i+1, len(items), item)
+		report.Results[i].Outcome, report.Results[i].Err = fn(ctx, item)
+	}
+	return report
+}
+
+// firstLine keeps multi-line git errors to one row of the report.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
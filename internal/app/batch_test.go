@@ -0,0 +1,71 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		failing  map[string]bool
+		cancel   string
+		wantCode int
+		wantOut  []string
+	}{
+		"all succeed": {
+			wantCode: BatchSucceeded,
+			wantOut:  []string{"==> [1/3] feature/a\n", "  ✓ feature/a  done feature/a\n", "3 succeeded, 0 failed.\n"},
+		},
+		"partial failure": {
+			failing:  map[string]bool{"feature/b": true},
+			wantCode: BatchPartial,
+			wantOut:  []string{"==> [3/3] topic\n", "  ✗ feature/b  rejected\n", "  ✓ topic      done topic\n", "2 succeeded, 1 failed.\n"},
+		},
+		"all fail": {
+			failing:  map[string]bool{"feature/a": true, "feature/b": true, "topic": true},
+			wantCode: BatchFailed,
+			wantOut:  []string{"0 succeeded, 3 failed.\n"},
+		},
+		"cancelled": {
+			cancel:   "feature/a",
+			wantCode: BatchPartial,
+			wantOut:  []string{"  - feature/b  skipped\n", "1 succeeded, 2 failed.\n"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			out := &bytes.Buffer{}
+			report := RunBatch(ctx, []string{"feature/a", "feature/b", "topic"}, func(ctx context.Context, item string) (string, error) {
+				if item == tc.cancel {
+					cancel()
+				}
+				if tc.failing[item] {
+					return "", errors.New("rejected\nhint: fetch first")
+				}
+				return "done " + item, nil
+			}, out)
+			report.Write(out)
+
+			if got := report.ExitCode(); got != tc.wantCode {
+				t.Fatalf("ExitCode() = %d, want %d", got, tc.wantCode)
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package app chains follow-up steps onto the actions of branch-navigator,
+// such as pulling and running a setup command after a checkout, and applies
+// an action to several branches at once. It also holds the registry of
+// actions that the flags and the --menu are built from.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StepFunc runs a built-in step.
+type StepFunc func(ctx context.Context) error
+
+// ShellFunc runs a step that is not built in as a shell command.
+type ShellFunc func(ctx context.Context, command string) error
+
+// Pipeline runs the steps that follow a successful action. A step is the
+// name of a built-in step, such as pull, or a shell command.
+type Pipeline struct {
+	builtins map[string]StepFunc
+	shell    ShellFunc
+}
+
+// NewPipeline returns a Pipeline that runs the named built-in steps itself
+// and every other step through shell.
+func NewPipeline(builtins map[string]StepFunc, shell ShellFunc) *Pipeline {
+	return &Pipeline{builtins: builtins, shell: shell}
+}
+
+// Run runs steps in order, announcing each on out. The first step that
+// fails stops the pipeline and its error names the step.
+func (p *Pipeline) Run(ctx context.Context, steps []string, out io.Writer) error {
+	for i, step := range steps {
+		fmt.Fprintf(out, "==> [%d/%d] %s\n", i+1, len(steps), step)
+		var err error
+		if builtin, ok := p.builtins[step]; ok {
+			err = builtin(ctx)
+		} else if p.shell != nil {
+			err = p.shell(ctx, step)
+		} else {
+			err = errors.New("no shell to run the step")
+		}
+		if err != nil {
+			return fmt.Errorf("step %q failed: %w", step, err)
+		}
+	}
+	return nil
+}
+
+// Expand replaces the steps that name a pipeline in named with the steps of
+// that pipeline, which may name further pipelines. A pipeline that reaches
+// itself is an error. Blank steps are dropped.
+func Expand(steps []string, named map[string][]string) ([]string, error) {
+	return expand(steps, named, nil)
+}
+
+func expand(steps []string, named map[string][]string, path []string) ([]string, error) {
+	var expanded []string
+	for _, step := range steps {
+		step = strings.TrimSpace(step)
+		if step == "" {
+			continue
+		}
+		inner, ok := named[step]
+		if !ok {
+			expanded = append(expanded, step)
+			continue
+		}
+		for _, name := range path {
+			if name == step {
+				return nil, fmt.Errorf("pipeline %q refers to itself: %s", step, strings.Join(append(path, step), " -> "))
+			}
+		}
+		steps, err := expand(inner, named, append(path, step))
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, steps...)
+	}
+	return expanded, nil
+}
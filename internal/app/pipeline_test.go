@@ -0,0 +1,115 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	named := map[string][]string{
+		"setup":  {"pull", "make setup"},
+		"fresh":  {"setup", "make test"},
+		"loop":   {"pull", "around"},
+		"around": {"loop"},
+	}
+
+	cases := map[string]struct {
+		steps   []string
+		want    []string
+		wantErr string
+	}{
+		"plain steps":      {steps: []string{"pull", "make setup"}, want: []string{"pull", "make setup"}},
+		"named pipeline":   {steps: []string{"setup"}, want: []string{"pull", "make setup"}},
+		"nested pipelines": {steps: []string{"fresh", "echo done"}, want: []string{"pull", "make setup", "make test", "echo done"}},
+		"blank steps":      {steps: []string{" ", "pull"}, want: []string{"pull"}},
+		"cycle":            {steps: []string{"loop"}, wantErr: `pipeline "loop" refers to itself: loop -> around -> loop`},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Expand(tc.steps, named)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("Expand() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Expand() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		steps   []string
+		failing string
+		wantRan []string
+		wantErr string
+	}{
+		"all steps": {
+			steps:   []string{"pull", "make setup"},
+			wantRan: []string{"builtin pull", "shell make setup"},
+		},
+		"stops at a failure": {
+			steps:   []string{"make setup", "pull"},
+			failing: "make setup",
+			wantRan: []string{"shell make setup"},
+			wantErr: `step "make setup" failed: exit status 2`,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var ran []string
+			pipeline := NewPipeline(map[string]StepFunc{
+				"pull": func(ctx context.Context) error {
+					ran = append(ran, "builtin pull")
+					return nil
+				},
+			}, func(ctx context.Context, command string) error {
+				ran = append(ran, "shell "+command)
+				if command == tc.failing {
+					return errors.New("exit status 2")
+				}
+				return nil
+			})
+
+			out := &bytes.Buffer{}
+			err := pipeline.Run(context.Background(), tc.steps, out)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("Run() error = %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Run returned error: %v", err)
+			}
+			if !reflect.DeepEqual(ran, tc.wantRan) {
+				t.Fatalf("ran %q, want %q", ran, tc.wantRan)
+			}
+			if !strings.Contains(out.String(), "==> [1/2] "+tc.steps[0]+"\n") {
+				t.Fatalf("expected the first step to be announced, got %q", out.String())
+			}
+		})
+	}
+}
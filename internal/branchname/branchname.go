@@ -0,0 +1,102 @@
+// Package branchname builds branch names from templates such as
+// feature/{ticket}-{slug}, whose placeholders are filled with slugified text.
+package branchname
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Template is a parsed branch name template.
+type Template struct {
+	// parts alternates literal text and placeholder names; placeholders are
+	// the odd entries.
+	parts []string
+}
+
+// Parse reads a template in which {name} marks a placeholder. Names consist
+// of letters, digits, underscores, and hyphens.
+func Parse(text string) (Template, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Template{}, errors.New("branch name template is empty")
+	}
+
+	parts := []string{}
+	rest := text
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			parts = append(parts, rest)
+			break
+		}
+		if rest[open] == '}' {
+			return Template{}, fmt.Errorf("branch name template %q has a } without a {", text)
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return Template{}, fmt.Errorf("branch name template %q has an unclosed {", text)
+		}
+		name := rest[open+1 : open+end]
+		if !validName(name) {
+			return Template{}, fmt.Errorf("branch name template %q has an invalid placeholder {%s}", text, name)
+		}
+		parts = append(parts, rest[:open], name)
+		rest = rest[open+end+1:]
+	}
+	return Template{parts: parts}, nil
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Placeholders returns the placeholder names in order of first appearance.
+func (t Template) Placeholders() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for i := 1; i < len(t.parts); i += 2 {
+		if name := t.parts[i]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Expand fills every placeholder with the slugified value from values. A
+// value that is missing or has nothing left after slugifying is an error.
+func (t Template) Expand(values map[string]string) (string, error) {
+	var b strings.Builder
+	for i, part := range t.parts {
+		if i%2 == 0 {
+			b.WriteString(part)
+			continue
+		}
+		slug := Slugify(values[part])
+		if slug == "" {
+			return "", fmt.Errorf("{%s} needs a value with letters or digits", part)
+		}
+		b.WriteString(slug)
+	}
+	return b.String(), nil
+}
+
+// Slugify lower-cases text and joins its runs of letters and digits with
+// hyphens, so that "Fix login: 2FA" becomes "fix-login-2fa".
+func Slugify(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
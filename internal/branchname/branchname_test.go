@@ -0,0 +1,86 @@
+package branchname
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		text             string
+		wantPlaceholders []string
+		wantErr          string
+	}{
+		"placeholders":      {text: "feature/{ticket}-{slug}", wantPlaceholders: []string{"ticket", "slug"}},
+		"repeated":          {text: "{user}/{slug}-{user}", wantPlaceholders: []string{"user", "slug"}},
+		"literal only":      {text: "spike", wantPlaceholders: []string{}},
+		"empty":             {text: "  ", wantErr: "template is empty"},
+		"unclosed":          {text: "feature/{ticket", wantErr: "unclosed {"},
+		"stray close":       {text: "feature/ticket}", wantErr: "} without a {"},
+		"empty placeholder": {text: "feature/{}", wantErr: "invalid placeholder {}"},
+		"invalid name":      {text: "feature/{a b}", wantErr: "invalid placeholder {a b}"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := Parse(tc.text)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tc.text, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tc.text, err)
+			}
+			if got := tmpl.Placeholders(); !reflect.DeepEqual(got, tc.wantPlaceholders) {
+				t.Fatalf("Placeholders() = %q, want %q", got, tc.wantPlaceholders)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := Parse("feature/{ticket}-{slug}")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	got, err := tmpl.Expand(map[string]string{"ticket": "ABC-123", "slug": "  Fix login: 2FA! "})
+	if err != nil {
+		t.Fatalf("Expand returned error: %v", err)
+	}
+	if want := "feature/abc-123-fix-login-2fa"; got != want {
+		t.Fatalf("Expand() = %q, want %q", got, want)
+	}
+
+	if _, err := tmpl.Expand(map[string]string{"ticket": "ABC-123", "slug": "?!"}); err == nil || !strings.Contains(err.Error(), "{slug} needs a value") {
+		t.Fatalf("expected an error for an empty slug, got %v", err)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Fix the login bug":  "fix-the-login-bug",
+		"  --Trim--me--  ":   "trim-me",
+		"Ünïcode straße":     "ünïcode-straße",
+		"v1.2 / release #42": "v1-2-release-42",
+		"!!!":                "",
+	}
+	for text, want := range cases {
+		if got := Slugify(text); got != want {
+			t.Fatalf("Slugify(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
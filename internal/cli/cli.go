@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Flag documents a command-line flag.
+type Flag struct {
+	// Name is the flag name without dashes; one-letter names render as -x and
+	// longer ones as --name.
+	Name string
+	// Arg names the flag's value in help text; boolean flags leave it empty.
+	Arg   string
+	Usage string
+	// Hidden keeps the flag out of -h and the man page; it is meant for
+	// tooling rather than everyday use.
+	Hidden bool
+}
+
+// Section is an extra titled block of the man page, such as ENVIRONMENT or FILES.
+type Section struct {
+	Title string
+	Body  string
+}
+
+// Command documents the program or one of its subcommands.
+type Command struct {
+	// Name is the program name for the root command and the subcommand name otherwise.
+	Name string
+	// Synopsis follows the command name in usage lines, for example "[-n N] <query>".
+	Synopsis string
+	// Summary is the one-line description used in command lists and the man page NAME section.
+	Summary string
+	// Description is free-form text; blank lines separate paragraphs.
+	Description string
+	Flags       []Flag
+	Commands    []Command
+	// Sections are rendered in the man page only.
+	Sections []Section
+}
+
+// Flag returns the flag called name.
+func (c Command) Flag(name string) (Flag, bool) {
+	for _, f := range c.Flags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// FlagUsage returns the usage of the flag called name, or an empty string when
+// the flag is not documented.
+func (c Command) FlagUsage(name string) string {
+	f, _ := c.Flag(name)
+	return f.Usage
+}
+
+// Usage renders the help text printed by -h. parent is the program name for
+// subcommands and empty for the root command.
+func (c Command) Usage(parent string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s\n", joinNonEmpty(parent, c.Name, c.Synopsis))
+	if description := strings.TrimSpace(c.Description); description != "" {
+		fmt.Fprintf(&b, "\n%s\n", description)
+	}
+	if len(c.Flags) > 0 {
+		b.WriteString("\nOptions:\n")
+		for _, f := range c.Flags {
+			if f.Hidden {
+				continue
+			}
+			fmt.Fprintf(&b, "%s\t%s\n", flagLabel(f), f.Usage)
+		}
+	}
+	if len(c.Commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		for _, sub := range c.Commands {
+			fmt.Fprintf(&b, "  %s\t%s\n", joinNonEmpty(sub.Name, sub.Synopsis), sub.Summary)
+		}
+	}
+	return b.String()
+}
+
+// Help renders the long-form help: the root usage followed by the usage of
+// every subcommand.
+func Help(root Command) string {
+	var b strings.Builder
+	b.WriteString(root.Usage(""))
+	for _, sub := range root.Commands {
+		b.WriteString("\n")
+		b.WriteString(sub.Usage(root.Name))
+	}
+	return b.String()
+}
+
+// flagLabel renders the indented flag column used by Usage, keeping long-only
+// flags aligned after the space a short flag would take.
+func flagLabel(f Flag) string {
+	label := "  -" + f.Name
+	if len(f.Name) > 1 {
+		label = "      --" + f.Name
+	}
+	if f.Arg != "" {
+		label += " " + f.Arg
+	}
+	return label
+}
+
+func joinNonEmpty(parts ...string) string {
+	kept := parts[:0:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+var testCommand = Command{
+	Name:        "tool",
+	Synopsis:    "[-v] [-n N]",
+	Summary:     "do things",
+	Description: "Tool does things.\n\nIt does them well.",
+	Flags: []Flag{
+		{Name: "v", Usage: "verbose output"},
+		{Name: "n", Arg: "N", Usage: "number of things"},
+		{Name: "dry-run", Usage: "only print what would happen"},
+		{Name: "trace", Arg: "FILE", Usage: "write a debug trace", Hidden: true},
+	},
+	Commands: []Command{
+		{Name: "sub", Synopsis: "<arg>", Summary: "run the subcommand", Flags: []Flag{{Name: "all", Usage: "everything"}}},
+		{Name: "bare", Summary: "a subcommand without arguments"},
+	},
+	Sections: []Section{{Title: "Environment", Body: "TOOL_HOME sets the home."}},
+}
+
+func TestCommandUsage(t *testing.T) {
+	t.Parallel()
+
+	want := "Usage: tool [-v] [-n N]\n" +
+		"\n" +
+		"Tool does things.\n" +
+		"\n" +
+		"It does them well.\n" +
+		"\n" +
+		"Options:\n" +
+		"  -v\tverbose output\n" +
+		"  -n N\tnumber of things\n" +
+		"      --dry-run\tonly print what would happen\n" +
+		"\n" +
+		"Commands:\n" +
+		"  sub <arg>\trun the subcommand\n" +
+		"  bare\ta subcommand without arguments\n"
+	if got := testCommand.Usage(""); got != want {
+		t.Fatalf("Usage() =\n%s\nwant\n%s", got, want)
+	}
+
+	sub := testCommand.Commands[0].Usage(testCommand.Name)
+	if !strings.HasPrefix(sub, "Usage: tool sub <arg>\n") || !strings.Contains(sub, "      --all\teverything\n") {
+		t.Fatalf("unexpected subcommand usage:\n%s", sub)
+	}
+}
+
+func TestCommandFlagUsage(t *testing.T) {
+	t.Parallel()
+
+	if got := testCommand.FlagUsage("n"); got != "number of things" {
+		t.Fatalf("FlagUsage(n) = %q", got)
+	}
+	if got := testCommand.FlagUsage("trace"); got != "write a debug trace" {
+		t.Fatalf("FlagUsage(trace) = %q", got)
+	}
+	if got := testCommand.FlagUsage("missing"); got != "" {
+		t.Fatalf("FlagUsage(missing) = %q, want empty", got)
+	}
+}
+
+func TestHelpIncludesSubcommands(t *testing.T) {
+	t.Parallel()
+
+	help := Help(testCommand)
+	for _, want := range []string{"Usage: tool [-v] [-n N]\n", "Usage: tool sub <arg>\n", "Usage: tool bare\n"} {
+		if !strings.Contains(help, want) {
+			t.Fatalf("Help() missing %q:\n%s", want, help)
+		}
+	}
+}
+
+func TestMan(t *testing.T) {
+	t.Parallel()
+
+	page := Man(testCommand, "2024-05-01")
+	for _, want := range []string{
+		".TH TOOL 1 \"2024\\-05\\-01\" \"tool\" \"User Commands\"\n",
+		".SH NAME\ntool \\- do things\n",
+		".SH SYNOPSIS\n.B tool\n[\\-v] [\\-n N]\n.br\n.B tool sub\n<arg>\n",
+		".SH DESCRIPTION\nTool does things.\n.PP\nIt does them well.\n",
+		".TP\n.B \\-v\nverbose output\n",
+		".TP\n.BI \\-n \" N\"\nnumber of things\n",
+		".TP\n.B \\-\\-dry\\-run\n",
+		".SS \"sub <arg>\"\nrun the subcommand\n.TP\n.B \\-\\-all\neverything\n",
+		".SH ENVIRONMENT\nTOOL_HOME sets the home.\n",
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("man page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "trace") {
+		t.Fatalf("man page documents the hidden flag:\n%s", page)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"plain":        "plain",
+		"a-b":          `a\-b`,
+		`back\slash`:   `back\eslash`,
+		".starts":      `\&.starts`,
+		"'quote start": `\&'quote start`,
+	}
+	for in, want := range cases {
+		if got := escape(in); got != want {
+			t.Errorf("escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Man renders root as a section 1 roff man page dated date.
+func Man(root Command, date string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s 1 %s %s \"User Commands\"\n", escape(strings.ToUpper(root.Name)), quote(date), quote(root.Name))
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", escape(root.Name), escape(root.Summary))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, ".B %s\n%s\n", escape(root.Name), escape(root.Synopsis))
+	for _, sub := range root.Commands {
+		fmt.Fprintf(&b, ".br\n.B %s %s\n%s\n", escape(root.Name), escape(sub.Name), escape(sub.Synopsis))
+	}
+
+	if root.Description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		writeParagraphs(&b, root.Description)
+	}
+	if len(root.Flags) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		writeFlags(&b, root.Flags)
+	}
+	if len(root.Commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, sub := range root.Commands {
+			fmt.Fprintf(&b, ".SS %s\n", quote(joinNonEmpty(sub.Name, sub.Synopsis)))
+			text := sub.Description
+			if strings.TrimSpace(text) == "" {
+				text = sub.Summary
+			}
+			writeParagraphs(&b, text)
+			writeFlags(&b, sub.Flags)
+		}
+	}
+	for _, section := range root.Sections {
+		fmt.Fprintf(&b, ".SH %s\n", escape(strings.ToUpper(section.Title)))
+		writeParagraphs(&b, section.Body)
+	}
+	return b.String()
+}
+
+func writeFlags(b *strings.Builder, flags []Flag) {
+	for _, f := range flags {
+		if f.Hidden {
+			continue
+		}
+		dashes := "-"
+		if len(f.Name) > 1 {
+			dashes = "--"
+		}
+		b.WriteString(".TP\n")
+		if f.Arg != "" {
+			fmt.Fprintf(b, ".BI %s \" %s\"\n", escape(dashes+f.Name), escape(f.Arg))
+		} else {
+			fmt.Fprintf(b, ".B %s\n", escape(dashes+f.Name))
+		}
+		fmt.Fprintf(b, "%s\n", escape(f.Usage))
+	}
+}
+
+// writeParagraphs emits text with blank lines turned into paragraph breaks;
+// roff refills the lines within a paragraph.
+func writeParagraphs(b *strings.Builder, text string) {
+	for i, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if i > 0 {
+			b.WriteString(".PP\n")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(paragraph), "\n") {
+			fmt.Fprintf(b, "%s\n", escape(strings.TrimSpace(line)))
+		}
+	}
+}
+
+// escape protects text from roff interpretation: backslashes and hyphens are
+// escaped and lines starting with a control character are guarded.
+func escape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	text = strings.ReplaceAll(text, "-", `\-`)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+func quote(text string) string {
+	return `"` + strings.ReplaceAll(escape(text), `"`, `\(dq`) + `"`
+}
@@ -0,0 +1,88 @@
+// Package discover finds the git repositories below a directory, such as
+// every project checked out under ~/src.
+package discover
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDepth is how many directory levels below the root are searched
+// when Options.MaxDepth is zero.
+const DefaultDepth = 4
+
+// Options tunes the search.
+type Options struct {
+	// MaxDepth bounds how many levels below the root are searched; the root
+	// is level 0. Zero uses DefaultDepth.
+	MaxDepth int
+}
+
+// Repositories returns the working tree of every git repository at or below
+// root, in lexical order. A directory counts as a repository when it holds
+// a .git directory or file, so worktrees are found as well. The search does
+// not descend into repositories, hidden directories, or symbolic links, and
+// directories that cannot be read are skipped.
+func Repositories(root string, opts Options) ([]string, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, errors.New("root directory is required")
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultDepth
+	}
+	root = filepath.Clean(root)
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "discover", Path: root, Err: errors.New("not a directory")}
+	}
+
+	repos := []string{}
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(entry.Name(), ".") {
+			return fs.SkipDir
+		}
+		if isRepository(path) {
+			repos = append(repos, path)
+			return fs.SkipDir
+		}
+		if depth(root, path) >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// isRepository reports whether dir is the top of a git working tree.
+func isRepository(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// depth returns how many levels path is below root.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
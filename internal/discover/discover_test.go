@@ -0,0 +1,109 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepositories(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, dir := range []string{
+		"api/.git",
+		"api/vendor/lib/.git",
+		"web/frontend/.git",
+		"tools/deep/er/than/four/.git",
+		".cache/hidden/.git",
+		"notes",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	// A linked worktree has a .git file instead of a directory.
+	if err := os.MkdirAll(filepath.Join(root, "api-wt"), 0o755); err != nil {
+		t.Fatalf("failed to create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "api-wt", ".git"), []byte("gitdir: ../api/.git/worktrees/api-wt\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .git file: %v", err)
+	}
+
+	cases := map[string]struct {
+		root string
+		opts Options
+		want []string
+	}{
+		"default depth": {
+			root: root,
+			want: []string{"api", "api-wt", "web/frontend"},
+		},
+		"deeper search": {
+			root: root,
+			opts: Options{MaxDepth: 6},
+			want: []string{"api", "api-wt", "tools/deep/er/than/four", "web/frontend"},
+		},
+		"shallow search": {
+			root: root,
+			opts: Options{MaxDepth: 1},
+			want: []string{"api", "api-wt"},
+		},
+		"root is a repository": {
+			root: filepath.Join(root, "api"),
+			want: []string{"."},
+		},
+		"no repositories": {
+			root: filepath.Join(root, "notes"),
+			want: []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Repositories(tc.root, tc.opts)
+			if err != nil {
+				t.Fatalf("Repositories returned error: %v", err)
+			}
+			want := make([]string, len(tc.want))
+			for i, rel := range tc.want {
+				want[i] = filepath.Join(tc.root, rel)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Repositories() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRepositoriesErrors(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	cases := map[string]string{
+		"empty root":   "",
+		"missing root": filepath.Join(t.TempDir(), "missing"),
+		"file root":    file,
+	}
+
+	for name, root := range cases {
+		name := name
+		root := root
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := Repositories(root, Options{}); err == nil {
+				t.Fatalf("expected error for %q", root)
+			}
+		})
+	}
+}
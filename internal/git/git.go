@@ -5,8 +5,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Runner executes git commands.
@@ -19,8 +25,33 @@ type CombinedRunner interface {
 	RunWithCombinedOutput(ctx context.Context, args ...string) (string, string, error)
 }
 
+// InteractiveRunner executes git attached to the caller's terminal so that
+// editors and prompts spawned by git can interact with the user directly.
+type InteractiveRunner interface {
+	RunInteractive(ctx context.Context, args ...string) error
+}
+
+// PromptRunner executes git attached to the caller's terminal so that
+// credential and passphrase prompts reach the user, while still capturing
+// stderr to describe failures.
+type PromptRunner interface {
+	RunWithPrompts(ctx context.Context, args ...string) (string, error)
+}
+
 // CLI executes git commands using the local git binary.
-type CLI struct{}
+type CLI struct {
+	// Dir is the working directory for git; empty means the process's current directory.
+	Dir string
+	// Config holds key=value settings passed to every invocation as git -c key=value.
+	Config []string
+	// Env is the environment for git; nil inherits the process's environment.
+	Env []string
+	// Log records every command with its duration and error; nil logs nothing.
+	Log *slog.Logger
+	// Observe, when set, is called after every command with its arguments,
+	// its trimmed stdout when that was captured, and its error.
+	Observe func(args []string, stdout string, err error)
+}
 
 // NewCLI constructs a CLI Runner.
 func NewCLI() *CLI {
@@ -35,16 +66,20 @@ func (c *CLI) Run(ctx context.Context, args ...string) (string, error) {
 
 // RunWithCombinedOutput invokes git and returns trimmed stdout and stderr strings.
 func (c *CLI) RunWithCombinedOutput(ctx context.Context, args ...string) (string, string, error) {
-	cmdArgs := append([]string{"-c", "color.ui=always"}, args...)
-	cmd := exec.CommandContext(ctx, "git", cmdArgs...)
+	cmd := exec.CommandContext(ctx, "git", c.withConfig([]string{"-c", "color.ui=always"}, args)...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	started := time.Now()
 	err := cmd.Run()
 	outStr := strings.TrimSpace(stdout.String())
 	errStr := strings.TrimSpace(stderr.String())
+	c.log(args, started, err, errStr)
+	c.observe(args, outStr, err)
 	if err != nil {
 		if errStr != "" {
 			return outStr, errStr, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, errStr)
@@ -55,9 +90,101 @@ func (c *CLI) RunWithCombinedOutput(ctx context.Context, args ...string) (string
 	return outStr, errStr, nil
 }
 
+// RunWithPrompts invokes git with inherited stdin, stdout, and stderr, and
+// returns a copy of what git wrote to stderr.
+func (c *CLI) RunWithPrompts(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", c.withConfig(nil, args)...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	var stderr bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+
+	started := time.Now()
+	err := cmd.Run()
+	errStr := strings.TrimSpace(stderr.String())
+	c.log(args, started, err, errStr)
+	c.observe(args, "", err)
+	if err != nil {
+		if errStr != "" {
+			return errStr, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, lastLine(errStr))
+		}
+		return errStr, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return errStr, nil
+}
+
+// lastLine returns the final line of s, which for git is usually the fatal
+// error after progress and hints.
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[i+1:])
+	}
+	return s
+}
+
+// RunInteractive invokes git with inherited stdin, stdout, and stderr. Output is not captured.
+func (c *CLI) RunInteractive(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", c.withConfig(nil, args)...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	started := time.Now()
+	err := cmd.Run()
+	c.log(args, started, err, "")
+	c.observe(args, "", err)
+	if err != nil {
+		return fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// log records a finished command that started at started. stderr is what
+// git wrote there when it was captured.
+func (c *CLI) log(args []string, started time.Time, err error, stderr string) {
+	if c.Log == nil {
+		return
+	}
+	attrs := []any{"args", args, "dir", c.Dir, "duration", time.Since(started)}
+	if err == nil {
+		c.Log.Debug("git", attrs...)
+		return
+	}
+	// Many commands are probes that are expected to fail, so a failure is
+	// only a warning; the caller decides whether it is an error.
+	c.Log.Warn("git", append(attrs, "err", err.Error(), "stderr", stderr)...)
+}
+
+// observe hands a finished command to Observe, if set.
+func (c *CLI) observe(args []string, stdout string, err error) {
+	if c.Observe != nil {
+		c.Observe(args, stdout, err)
+	}
+}
+
+// withConfig returns the git arguments for args: the fixed options first,
+// then a -c option for every Config entry, so that users can override the
+// fixed ones, then args.
+func (c *CLI) withConfig(options, args []string) []string {
+	cmdArgs := make([]string, 0, len(options)+2*len(c.Config)+len(args))
+	cmdArgs = append(cmdArgs, options...)
+	for _, setting := range c.Config {
+		cmdArgs = append(cmdArgs, "-c", setting)
+	}
+	return append(cmdArgs, args...)
+}
+
 // Client provides higher-level git helpers used by the navigator.
 type Client struct {
-	runner Runner
+	runner  Runner
+	retry   RetryPolicy
+	prompts bool
+	// useSwitch checks branches out with git switch where git has it.
+	useSwitch bool
 }
 
 // NewClient constructs a Client using the supplied Runner.
@@ -70,6 +197,86 @@ func NewDefaultClient() *Client {
 	return NewClient(NewCLI())
 }
 
+// SetRetryPolicy makes fetch, prune, and push retry failures that look
+// transient. Other commands are never retried.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	if c != nil {
+		c.retry = policy
+	}
+}
+
+// SetCredentialPrompts runs fetch, prune, and push attached to the terminal
+// when the Runner supports it, so that git can ask for a username, password,
+// or SSH passphrase. Their output then goes straight to the terminal and is
+// not returned.
+func (c *Client) SetCredentialPrompts(enabled bool) {
+	if c != nil {
+		c.prompts = enabled
+	}
+}
+
+// SetSwitch makes CheckoutBranch, CheckoutRemoteBranch, CheckoutMerge, and
+// CheckoutDetached run git switch instead of git checkout. A branch that
+// exists only on remotes is then guessed with --guess, which picks the
+// remote named by checkout.defaultRemote when several have it. Before git
+// 2.23, which has no git switch, they keep running git checkout.
+func (c *Client) SetSwitch(enabled bool) {
+	if c != nil {
+		c.useSwitch = enabled
+	}
+}
+
+// switchCommand returns the subcommand that checks branches out: switch
+// when SetSwitch turned it on and git has it, and checkout otherwise.
+func (c *Client) switchCommand(ctx context.Context) string {
+	if !c.useSwitch {
+		return "checkout"
+	}
+	version, err := c.Version(ctx)
+	if err != nil || CompareVersions(version, switchMinVersion) < 0 {
+		return "checkout"
+	}
+	return "switch"
+}
+
+// CredentialPrompts reports whether network commands run attached to the
+// terminal.
+func (c *Client) CredentialPrompts() bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.runner.(PromptRunner)
+	return c.prompts && ok
+}
+
+// runNetwork runs a command that talks to a remote, following the retry
+// policy and the credential prompt setting.
+func (c *Client) runNetwork(ctx context.Context, args ...string) (string, string, error) {
+	runner := c.network()
+	if c.CredentialPrompts() {
+		_, err := runner.(PromptRunner).RunWithPrompts(ctx, args...)
+		return "", "", err
+	}
+	if combined, ok := runner.(CombinedRunner); ok {
+		return combined.RunWithCombinedOutput(ctx, args...)
+	}
+	stdout, err := runner.Run(ctx, args...)
+	return stdout, "", err
+}
+
+// network returns the Runner for commands that talk to a remote.
+func (c *Client) network() Runner {
+	if c.retry.Retries <= 0 {
+		return c.runner
+	}
+	return newRetryRunner(c.runner, c.retry)
+}
+
+// NewDefaultClientAt constructs a Client backed by a CLI Runner operating in dir.
+func NewDefaultClientAt(dir string) *Client {
+	return NewClient(&CLI{Dir: dir})
+}
+
 // FastForwardStrategy controls the fast-forward behavior of git merge.
 type FastForwardStrategy int
 
@@ -85,7 +292,13 @@ const (
 // MergeOptions configures merge behavior.
 type MergeOptions struct {
 	FastForward FastForwardStrategy
-	ExtraArgs   []string
+	// GPGSign signs the merge commit, with SigningKey when it is set and
+	// with git's default key otherwise.
+	GPGSign    bool
+	SigningKey string
+	// Signoff adds a Signed-off-by trailer to the merge commit.
+	Signoff   bool
+	ExtraArgs []string
 }
 
 // MergeResult captures stdout and stderr emitted by git merge.
@@ -94,6 +307,14 @@ type MergeResult struct {
 	Stderr string
 }
 
+// MergePrediction is the outcome of a trial merge that leaves the index and
+// working tree untouched.
+type MergePrediction struct {
+	// Conflicts lists the paths that would conflict; empty means the merge
+	// applies cleanly.
+	Conflicts []string
+}
+
 // DeleteOptions configures delete behavior.
 type DeleteOptions struct {
 	Force bool
@@ -105,21 +326,270 @@ type DeleteResult struct {
 	Stderr string
 }
 
+// Remote describes a configured git remote.
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Submodule describes a submodule of the current repository, as listed by
+// git submodule status.
+type Submodule struct {
+	// Path is the submodule's directory relative to the current directory.
+	Path string
+	// Commit is the commit checked out in the submodule, or the one the
+	// superproject records when it is not initialized.
+	Commit string
+	// Describe names Commit after a tag or branch, such as heads/main; it
+	// may be empty.
+	Describe string
+	// Initialized reports whether the submodule is checked out.
+	Initialized bool
+	// Modified reports that the checked-out commit is not the recorded one.
+	Modified bool
+	// Conflicted reports merge conflicts in the recorded commit.
+	Conflicted bool
+}
+
+// RepoKind tells how the repository git runs in is laid out.
+type RepoKind int
+
+const (
+	// RepoWorkTree is a repository with a working tree, including a linked
+	// worktree.
+	RepoWorkTree RepoKind = iota
+	// RepoBare is a bare repository entered directly, such as project.git.
+	RepoBare
+	// RepoWorktreeHub is a directory whose .git file points at a bare
+	// repository, usually .bare, with a worktree per branch beside it.
+	RepoWorktreeHub
+)
+
+// RepoInfo describes the repository git runs in.
+type RepoInfo struct {
+	Kind RepoKind
+	// GitDir is the absolute path of the repository's git directory.
+	GitDir string
+	// Root is the directory new worktrees of a bare repository go in: the
+	// hub, or the directory containing the bare repository. It is empty
+	// for RepoWorkTree.
+	Root string
+}
+
+// Bare reports whether the repository has no working tree to check
+// branches out in.
+func (i RepoInfo) Bare() bool {
+	return i.Kind != RepoWorkTree
+}
+
+// CommonDir returns the git directory every worktree of the repository
+// shares, which holds its branches and configuration: the one named by the
+// commondir file of a linked worktree, and GitDir otherwise.
+func (i RepoInfo) CommonDir() string {
+	data, err := os.ReadFile(filepath.Join(i.GitDir, "commondir"))
+	if err != nil {
+		return i.GitDir
+	}
+	common := strings.TrimSpace(string(data))
+	if common == "" {
+		return i.GitDir
+	}
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(i.GitDir, common)
+	}
+	return filepath.Clean(common)
+}
+
+// Operation is a multi-step git command left in progress in the working
+// tree, waiting for the user to continue or abort it.
+type Operation int
+
+const (
+	// OperationNone means nothing is in progress.
+	OperationNone Operation = iota
+	// OperationMerge is a merge stopped on conflicts.
+	OperationMerge
+	// OperationRebase is a rebase, or git am, that has not finished.
+	OperationRebase
+	// OperationCherryPick and OperationRevert stopped on conflicts.
+	OperationCherryPick
+	OperationRevert
+	// OperationBisect is a bisect session that has not been reset.
+	OperationBisect
+)
+
+// String returns the git command of the operation, such as "rebase".
+func (o Operation) String() string {
+	switch o {
+	case OperationMerge:
+		return "merge"
+	case OperationRebase:
+		return "rebase"
+	case OperationCherryPick:
+		return "cherry-pick"
+	case OperationRevert:
+		return "revert"
+	case OperationBisect:
+		return "bisect"
+	default:
+		return ""
+	}
+}
+
+// operationMarkers lists the files and directories, relative to the git
+// directory, that git keeps while an operation is in progress. A rebase
+// comes first, since it may stop in the middle of a cherry-pick or merge.
+var operationMarkers = []struct {
+	name      string
+	operation Operation
+}{
+	{"rebase-merge", OperationRebase},
+	{"rebase-apply", OperationRebase},
+	{"MERGE_HEAD", OperationMerge},
+	{"CHERRY_PICK_HEAD", OperationCherryPick},
+	{"REVERT_HEAD", OperationRevert},
+	{"BISECT_LOG", OperationBisect},
+}
+
+// Worktree is a working tree attached to the repository, as listed by git
+// worktree list.
+type Worktree struct {
+	Path string
+	// Branch is the short name of the checked-out branch; it is empty for
+	// a detached HEAD and for the bare repository itself.
+	Branch string
+	Bare   bool
+}
+
+// RemoteResult captures stdout and stderr emitted by remote maintenance commands.
+type RemoteResult struct {
+	Stdout string
+	Stderr string
+}
+
+// Upstream identifies the remote branch a local branch tracks.
+type Upstream struct {
+	Remote string
+	Branch string
+}
+
+// String returns the upstream in <remote>/<branch> form, or an empty string when unset.
+func (u Upstream) String() string {
+	if u.Remote == "" || u.Branch == "" {
+		return ""
+	}
+	return u.Remote + "/" + u.Branch
+}
+
+// PushOptions configures push behavior.
+type PushOptions struct {
+	// Remote receives the push. It defaults to the branch upstream's remote, then origin.
+	Remote string
+	// SetUpstream records the pushed branch as the upstream of the local branch.
+	SetUpstream bool
+}
+
+// PushResult captures stdout and stderr emitted by git push.
+type PushResult struct {
+	Stdout string
+	Stderr string
+}
+
+// BranchRef describes a local or remote-tracking branch and the date of its tip commit.
+type BranchRef struct {
+	// Name is the short ref name, such as feature/x or origin/feature/x.
+	Name       string
+	Remote     bool
+	CommitDate time.Time
+	// Author is the author of the last commit as "Name <email>".
+	Author string
+}
+
+// RefState is the commit a local or remote-tracking branch points at.
+type RefState struct {
+	// Name is the short ref name, such as feature/x or origin/feature/x.
+	Name string
+	// Hash is the full hash of the commit at the tip.
+	Hash string
+	// Upstream is the short name of the branch a local branch tracks, such
+	// as origin/main; empty when unset.
+	Upstream string
+}
+
+// BranchStatus describes a local branch and how it relates to its upstream.
+type BranchStatus struct {
+	Name       string
+	CommitDate time.Time
+	// Upstream is the short name of the tracked branch, such as origin/main; empty when unset.
+	Upstream string
+	// Ahead and Behind count the commits not yet pushed and not yet pulled.
+	Ahead  int
+	Behind int
+	// Gone reports that the configured upstream no longer exists.
+	Gone bool
+}
+
+// DefaultRemote is the remote used when nothing else identifies one.
+const DefaultRemote = "origin"
+
 var (
 	// ErrBranchNotFullyMerged indicates the branch has not been fully merged.
 	ErrBranchNotFullyMerged = errors.New("branch is not fully merged")
 	// ErrDeleteCurrentBranch indicates the branch to delete is currently checked out.
 	ErrDeleteCurrentBranch = errors.New("cannot delete the current branch")
+	// ErrBaseBranchNotFound indicates no default branch could be determined.
+	ErrBaseBranchNotFound = errors.New("cannot determine the base branch")
+	// ErrLocalChangesOverwritten indicates a checkout was refused because it
+	// would overwrite uncommitted changes.
+	ErrLocalChangesOverwritten = errors.New("local changes would be overwritten by checkout")
+	// ErrMergePredictionUnsupported indicates git is too old for merge-tree --write-tree.
+	ErrMergePredictionUnsupported = errors.New("conflict prediction needs git " + mergeTreeMinVersion + " or newer")
 )
 
+// mergeTreeMinVersion is the first git release with merge-tree --write-tree.
+const mergeTreeMinVersion = "2.38"
+
+// switchMinVersion is the first git release with git switch.
+const switchMinVersion = "2.23"
+
+// aheadBehindMinVersion is the first git release with %(ahead-behind:<base>)
+// in for-each-ref.
+const aheadBehindMinVersion = "2.41"
+
+// fallbackBaseBranches lists the conventional default branch names probed when origin/HEAD is unset.
+var fallbackBaseBranches = []string{"main", "master"}
+
+// archiveTagPrefix namespaces the tags that preserve archived branch tips.
+const archiveTagPrefix = "archive/"
+
+// backupRefPrefix namespaces the refs that keep deleted branch tips reachable.
+const backupRefPrefix = "refs/branch-navigator/backup/"
+
+// Backup describes a branch tip saved before deletion.
+type Backup struct {
+	Branch  string
+	Ref     string
+	SHA     string
+	Created time.Time
+}
+
 func (opts MergeOptions) args() []string {
-	args := make([]string, 0, len(opts.ExtraArgs)+1)
+	args := make([]string, 0, len(opts.ExtraArgs)+3)
 	switch opts.FastForward {
 	case FastForwardOnly:
 		args = append(args, "--ff-only")
 	case FastForwardNoFF:
 		args = append(args, "--no-ff")
 	}
+	switch {
+	case opts.GPGSign && opts.SigningKey != "":
+		args = append(args, "--gpg-sign="+opts.SigningKey)
+	case opts.GPGSign:
+		args = append(args, "--gpg-sign")
+	}
+	if opts.Signoff {
+		args = append(args, "--signoff")
+	}
 	args = append(args, opts.ExtraArgs...)
 	return args
 }
@@ -136,16 +606,140 @@ func (c *Client) CurrentBranch(ctx context.Context) (string, error) {
 	return out, nil
 }
 
-// ReflogBranchMoves returns branch names discovered in the HEAD reflog.
-func (c *Client) ReflogBranchMoves(ctx context.Context) ([]string, error) {
+// Version returns the installed git version, such as 2.43.0.
+func (c *Client) Version(ctx context.Context) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "--version")
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return "", fmt.Errorf("unexpected git --version output %q", strings.TrimSpace(out))
+	}
+	return fields[2], nil
+}
+
+// CompareVersions compares the leading numeric components of two dotted
+// versions, returning -1, 0, or 1.
+func CompareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	parts := []int{}
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// RepoRoot returns the absolute path of the working tree's top-level
+// directory. A bare repository has none, so RepoInfo.Root stands in for it.
+func (c *Client) RepoRoot(ctx context.Context) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		if info, infoErr := c.RepoInfo(ctx); infoErr == nil && info.Bare() {
+			return info.Root, nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ReflogVisit is the most recent checkout of a branch recorded in the HEAD reflog.
+type ReflogVisit struct {
+	Branch string
+	// Time is when the branch was checked out; zero when git did not report it.
+	Time time.Time
+}
+
+// ReflogVisits returns the distinct branches checked out in the HEAD reflog
+// with the time of their latest checkout, most recent first. maxEntries
+// bounds how many reflog entries git prints and maxBranches stops parsing
+// once that many branches were found; zero leaves either unbounded.
+func (c *Client) ReflogVisits(ctx context.Context, maxEntries, maxBranches int) ([]ReflogVisit, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	args := []string{"reflog"}
+	if maxEntries > 0 {
+		args = append(args, "-n", strconv.Itoa(maxEntries))
+	}
+	out, err := c.runner.Run(ctx, append(args, "--date=unix", "--format=%gd%x09%gs")...)
+	if err != nil {
+		return nil, err
+	}
+	return parseReflogEntries(out, maxBranches), nil
+}
+
+// ReflogBranchMoves returns the branch names of ReflogVisits.
+func (c *Client) ReflogBranchMoves(ctx context.Context, maxEntries, maxBranches int) ([]string, error) {
+	visits, err := c.ReflogVisits(ctx, maxEntries, maxBranches)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(visits))
+	for i, visit := range visits {
+		names[i] = visit.Branch
+	}
+	return names, nil
+}
+
+// HeadEntry is one position of HEAD recorded in the reflog: a checkout,
+// commit, reset, rebase step, or anything else that moved it.
+type HeadEntry struct {
+	// Selector names the entry the way git does, such as HEAD@{2}.
+	Selector string
+	Hash     string
+	Short    string
+	// Subject is the reflog message, such as "reset: moving to HEAD~1".
+	Subject string
+	// Time is when HEAD moved; zero when git did not report it.
+	Time time.Time
+}
+
+// HeadHistory returns the entries of the HEAD reflog, most recent first.
+// Unlike ReflogVisits it keeps every entry, including detached checkouts
+// and resets. maxEntries bounds how many are read; zero reads them all.
+func (c *Client) HeadHistory(ctx context.Context, maxEntries int) ([]HeadEntry, error) {
 	if c == nil || c.runner == nil {
 		return nil, errors.New("git client is not configured")
 	}
-	out, err := c.runner.Run(ctx, "reflog", "--format=%gs")
+	args := []string{"reflog"}
+	if maxEntries > 0 {
+		args = append(args, "-n", strconv.Itoa(maxEntries))
+	}
+	out, err := c.runner.Run(ctx, append(args, "--date=unix", "--format=%H%x09%h%x09%gd%x09%gs")...)
 	if err != nil {
 		return nil, err
 	}
-	return parseReflogSubjects(out), nil
+	return parseHeadHistory(out), nil
 }
 
 // BranchesByCommitDate returns local branches ordered by most recent commit date.
@@ -153,11 +747,23 @@ func (c *Client) BranchesByCommitDate(ctx context.Context) ([]string, error) {
 	if c == nil || c.runner == nil {
 		return nil, errors.New("git client is not configured")
 	}
-	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname:short)", "--sort=-committerdate", "refs/heads")
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname:lstrip=2)", "--sort=-committerdate", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return splitRefNames(out), nil
+}
+
+// LocalBranches returns every local branch name in a single git call.
+func (c *Client) LocalBranches(ctx context.Context) ([]string, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "branch", "--list", "--format=%(refname:lstrip=2)")
 	if err != nil {
 		return nil, err
 	}
-	return splitAndFilter(out), nil
+	return splitRefNames(out), nil
 }
 
 // BranchExists reports whether the provided local branch exists.
@@ -179,8 +785,91 @@ func (c *Client) BranchExists(ctx context.Context, branch string) (bool, error)
 	return true, nil
 }
 
-// CheckoutBranch switches the working tree to the specified local branch.
-func (c *Client) CheckoutBranch(ctx context.Context, branch string) (string, error) {
+// ConfigScope selects the git configuration file read or written by
+// ConfigValue and SetConfigValue.
+type ConfigScope string
+
+const (
+	// ConfigGlobal is the user's ~/.gitconfig.
+	ConfigGlobal ConfigScope = "--global"
+	// ConfigLocal is the current repository's .git/config.
+	ConfigLocal ConfigScope = "--local"
+	// ConfigMerged reads every file git reads, the repository's last, as
+	// git itself sees the key. It is only for reading.
+	ConfigMerged ConfigScope = "--includes"
+)
+
+// ConfigValue returns the value of key in scope. ok is false when the key is not set.
+func (c *Client) ConfigValue(ctx context.Context, scope ConfigScope, key string) (value string, ok bool, err error) {
+	if c == nil || c.runner == nil {
+		return "", false, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "config", string(scope), "--get", key)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return out, true, nil
+}
+
+// ConfigValues returns the value of every key in scope that matches the
+// regular expression pattern. git reports the section and variable parts of
+// each key in lowercase and leaves subsections, such as branch names, as
+// they are.
+func (c *Client) ConfigValues(ctx context.Context, scope ConfigScope, pattern string) (map[string]string, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "config", string(scope), "--get-regexp", pattern)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		values[key] = value
+	}
+	return values, nil
+}
+
+// SetConfigValue sets key to value in scope, replacing any previous value.
+func (c *Client) SetConfigValue(ctx context.Context, scope ConfigScope, key, value string) error {
+	if c == nil || c.runner == nil {
+		return errors.New("git client is not configured")
+	}
+	_, err := c.runner.Run(ctx, "config", string(scope), key, value)
+	return err
+}
+
+// UnsetConfigValue removes key from scope. A key that is not set is left
+// alone.
+func (c *Client) UnsetConfigValue(ctx context.Context, scope ConfigScope, key string) error {
+	if c == nil || c.runner == nil {
+		return errors.New("git client is not configured")
+	}
+	_, err := c.runner.Run(ctx, "config", string(scope), "--unset-all", key)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 {
+		// git exits with 5 when there is nothing to unset.
+		return nil
+	}
+	return err
+}
+
+// ArchiveBranch tags the tip of branch as archive/<branch> and then force-deletes
+// the local branch. It returns the created tag name.
+func (c *Client) ArchiveBranch(ctx context.Context, branch string) (string, error) {
 	if c == nil || c.runner == nil {
 		return "", errors.New("git client is not configured")
 	}
@@ -194,50 +883,1234 @@ func (c *Client) CheckoutBranch(ctx context.Context, branch string) (string, err
 		return "", err
 	}
 	if branch == current {
-		return fmt.Sprintf("already on '%s'", branch), nil
+		return "", fmt.Errorf("cannot archive the current branch '%s'", branch)
 	}
 
-	out, err := c.runner.Run(ctx, "checkout", branch)
-	if err != nil {
+	tag := archiveTagPrefix + branch
+	if _, err := c.runner.Run(ctx, "tag", tag, "refs/heads/"+branch); err != nil {
 		return "", err
 	}
-	return out, nil
+	if _, err := c.runner.Run(ctx, "branch", "-D", branch); err != nil {
+		return tag, err
+	}
+	return tag, nil
 }
 
-// MergeBranch merges the provided branch into the current branch.
-func (c *Client) MergeBranch(ctx context.Context, branch string, opts MergeOptions) (MergeResult, error) {
+// ArchivedBranches returns the names of archived branches, most recently archived first.
+func (c *Client) ArchivedBranches(ctx context.Context) ([]string, error) {
 	if c == nil || c.runner == nil {
-		return MergeResult{}, errors.New("git client is not configured")
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname:short)", "--sort=-creatordate", "refs/tags/"+archiveTagPrefix)
+	if err != nil {
+		return nil, err
+	}
+	lines := splitRefNames(out)
+	branches := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if name := strings.TrimPrefix(line, archiveTagPrefix); name != line && name != "" {
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
+}
+
+// UnarchiveBranch recreates branch from its archive/<branch> tag and removes the tag.
+func (c *Client) UnarchiveBranch(ctx context.Context, branch string) error {
+	if c == nil || c.runner == nil {
+		return errors.New("git client is not configured")
 	}
 	branch = strings.TrimSpace(branch)
 	if branch == "" {
-		return MergeResult{}, errors.New("branch name is required")
+		return errors.New("branch name is required")
 	}
 
-	args := []string{"merge"}
-	args = append(args, opts.args()...)
-	args = append(args, branch)
-
-	if combined, ok := c.runner.(CombinedRunner); ok {
-		stdout, stderr, err := combined.RunWithCombinedOutput(ctx, args...)
-		return MergeResult{Stdout: stdout, Stderr: stderr}, err
+	tag := archiveTagPrefix + branch
+	if _, err := c.runner.Run(ctx, "branch", branch, "refs/tags/"+tag); err != nil {
+		return err
 	}
-
-	stdout, err := c.runner.Run(ctx, args...)
-	return MergeResult{Stdout: stdout}, err
+	if _, err := c.runner.Run(ctx, "tag", "-d", tag); err != nil {
+		return err
+	}
+	return nil
 }
 
-// DeleteBranch removes the specified local branch, optionally forcing deletion.
-func (c *Client) DeleteBranch(ctx context.Context, branch string, opts DeleteOptions) (DeleteResult, error) {
+// BackupBranch records the tip of branch under refs/branch-navigator/backup/<branch>@<unix-time>
+// so it stays reachable after the branch is deleted.
+func (c *Client) BackupBranch(ctx context.Context, branch string, now time.Time) (Backup, error) {
 	if c == nil || c.runner == nil {
-		return DeleteResult{}, errors.New("git client is not configured")
+		return Backup{}, errors.New("git client is not configured")
 	}
 	branch = strings.TrimSpace(branch)
 	if branch == "" {
-		return DeleteResult{}, errors.New("branch name is required")
+		return Backup{}, errors.New("branch name is required")
 	}
 
-	current, err := c.CurrentBranch(ctx)
+	sha, err := c.runner.Run(ctx, "rev-parse", "--verify", "refs/heads/"+branch+"^{commit}")
+	if err != nil {
+		return Backup{}, err
+	}
+	sha = strings.TrimSpace(sha)
+
+	created := time.Unix(now.Unix(), 0)
+	ref := fmt.Sprintf("%s%s@%d", backupRefPrefix, branch, created.Unix())
+	if _, err := c.runner.Run(ctx, "update-ref", ref, sha); err != nil {
+		return Backup{}, err
+	}
+	return Backup{Branch: branch, Ref: ref, SHA: sha, Created: created}, nil
+}
+
+// DeleteBackup removes a backup, such as one taken for a deletion that
+// then failed.
+func (c *Client) DeleteBackup(ctx context.Context, backup Backup) error {
+	if c == nil || c.runner == nil {
+		return errors.New("git client is not configured")
+	}
+	if !strings.HasPrefix(backup.Ref, backupRefPrefix) {
+		return fmt.Errorf("%q is not a branch backup", backup.Ref)
+	}
+	_, err := c.runner.Run(ctx, "update-ref", "-d", backup.Ref)
+	return err
+}
+
+// Backups lists the saved branch tips.
+func (c *Client) Backups(ctx context.Context) ([]Backup, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname)%09%(objectname)", backupRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := splitAndFilter(out)
+	backups := make([]Backup, 0, len(lines))
+	for _, line := range lines {
+		if backup, ok := parseBackupLine(line); ok {
+			backups = append(backups, backup)
+		}
+	}
+	return backups, nil
+}
+
+// PruneBackups deletes backups created before cutoff and returns them.
+func (c *Client) PruneBackups(ctx context.Context, cutoff time.Time) ([]Backup, error) {
+	backups, err := c.Backups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make([]Backup, 0, len(backups))
+	for _, backup := range backups {
+		if !backup.Created.Before(cutoff) {
+			continue
+		}
+		if _, err := c.runner.Run(ctx, "update-ref", "-d", backup.Ref); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, backup)
+	}
+	return pruned, nil
+}
+
+func parseBackupLine(line string) (Backup, bool) {
+	ref, sha, ok := strings.Cut(line, "\t")
+	if !ok || !strings.HasPrefix(ref, backupRefPrefix) {
+		return Backup{}, false
+	}
+	name := strings.TrimPrefix(ref, backupRefPrefix)
+	idx := strings.LastIndex(name, "@")
+	if idx <= 0 {
+		return Backup{}, false
+	}
+	unix, err := strconv.ParseInt(name[idx+1:], 10, 64)
+	if err != nil {
+		return Backup{}, false
+	}
+	return Backup{
+		Branch:  name[:idx],
+		Ref:     ref,
+		SHA:     strings.TrimSpace(sha),
+		Created: time.Unix(unix, 0),
+	}, true
+}
+
+// RebaseInteractive starts git rebase -i onto the provided branch with git attached to the terminal.
+func (c *Client) RebaseInteractive(ctx context.Context, onto string) error {
+	if c == nil || c.runner == nil {
+		return errors.New("git client is not configured")
+	}
+	onto = strings.TrimSpace(onto)
+	if onto == "" {
+		return errors.New("branch name is required")
+	}
+
+	interactive, ok := c.runner.(InteractiveRunner)
+	if !ok {
+		return errors.New("git runner does not support interactive commands")
+	}
+	return interactive.RunInteractive(ctx, "rebase", "-i", onto)
+}
+
+// Pull runs git pull for the current branch with git attached to the
+// terminal, so that its progress and prompts are shown.
+func (c *Client) Pull(ctx context.Context) error {
+	if c == nil || c.runner == nil {
+		return errors.New("git client is not configured")
+	}
+	interactive, ok := c.runner.(InteractiveRunner)
+	if !ok {
+		return errors.New("git runner does not support interactive commands")
+	}
+	return interactive.RunInteractive(ctx, "pull")
+}
+
+// BranchUpstream returns the upstream tracked by the local branch. The zero Upstream means none is configured.
+func (c *Client) BranchUpstream(ctx context.Context, branch string) (Upstream, error) {
+	if c == nil || c.runner == nil {
+		return Upstream{}, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return Upstream{}, errors.New("branch name is required")
+	}
+
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(upstream:remotename)%09%(upstream:remoteref)", "refs/heads/"+branch)
+	if err != nil {
+		return Upstream{}, err
+	}
+	remote, ref, _ := strings.Cut(strings.TrimSpace(out), "\t")
+	if remote == "" || ref == "" {
+		return Upstream{}, nil
+	}
+	return Upstream{Remote: remote, Branch: strings.TrimPrefix(ref, "refs/heads/")}, nil
+}
+
+// PushBranch pushes the local branch to its remote, optionally setting it as the upstream.
+func (c *Client) PushBranch(ctx context.Context, branch string, opts PushOptions) (PushResult, error) {
+	if c == nil || c.runner == nil {
+		return PushResult{}, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return PushResult{}, errors.New("branch name is required")
+	}
+
+	remote := strings.TrimSpace(opts.Remote)
+	if remote == "" {
+		remote = DefaultRemote
+	}
+	args := []string{"push"}
+	if opts.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, remote, branch)
+
+	stdout, stderr, err := c.runNetwork(ctx, args...)
+	return PushResult{Stdout: stdout, Stderr: stderr}, err
+}
+
+// Remotes returns the configured remotes with their fetch URLs in git's listing order.
+func (c *Client) Remotes(ctx context.Context) ([]Remote, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "remote", "-v")
+	if err != nil {
+		return nil, err
+	}
+	return parseRemotes(out), nil
+}
+
+// RemotesWithBranch returns the remotes, in git's listing order, that have a
+// remote-tracking branch named branch, such as origin for origin/feature/x.
+func (c *Client) RemotesWithBranch(ctx context.Context, branch string) ([]Remote, error) {
+	remotes, err := c.Remotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname)", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]bool{}
+	for _, ref := range splitAndFilter(out) {
+		refs[ref] = true
+	}
+	found := []Remote{}
+	for _, remote := range remotes {
+		if refs["refs/remotes/"+remote.Name+"/"+branch] {
+			found = append(found, remote)
+		}
+	}
+	return found, nil
+}
+
+// Submodules returns the submodules of the current repository, nested ones
+// included, in the order git submodule status lists them.
+func (c *Client) Submodules(ctx context.Context) ([]Submodule, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "submodule", "status", "--recursive")
+	if err != nil {
+		return nil, err
+	}
+	submodules := []Submodule{}
+	for _, line := range splitAndFilter(out) {
+		if submodule, ok := parseSubmoduleLine(line); ok {
+			submodules = append(submodules, submodule)
+		}
+	}
+	return submodules, nil
+}
+
+// RepoInfo detects whether the repository is a regular one with a working
+// tree, a bare repository, or a worktree hub.
+func (c *Client) RepoInfo(ctx context.Context) (RepoInfo, error) {
+	if c == nil || c.runner == nil {
+		return RepoInfo{}, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "rev-parse", "--is-bare-repository", "--absolute-git-dir")
+	if err != nil {
+		return RepoInfo{}, err
+	}
+	lines := splitAndFilter(out)
+	if len(lines) < 2 {
+		return RepoInfo{}, fmt.Errorf("unexpected git rev-parse output %q", strings.TrimSpace(out))
+	}
+	info := RepoInfo{Kind: RepoWorkTree, GitDir: lines[1]}
+	if lines[0] != "true" {
+		return info, nil
+	}
+	info.Kind = RepoBare
+	info.Root = filepath.Dir(info.GitDir)
+	// A hub keeps a .git file next to the bare repository that points at it.
+	if stat, err := os.Lstat(filepath.Join(info.Root, ".git")); err == nil && stat.Mode().IsRegular() {
+		info.Kind = RepoWorktreeHub
+	}
+	return info, nil
+}
+
+// InProgress reports the operation left in progress in the working tree,
+// such as a rebase that stopped on a conflict, or OperationNone.
+func (c *Client) InProgress(ctx context.Context) (Operation, error) {
+	if c == nil || c.runner == nil {
+		return OperationNone, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return OperationNone, err
+	}
+	gitDir := strings.TrimSpace(out)
+	if gitDir == "" {
+		return OperationNone, errors.New("git did not report the git directory")
+	}
+	for _, marker := range operationMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker.name)); err == nil {
+			return marker.operation, nil
+		}
+	}
+	return OperationNone, nil
+}
+
+// Worktrees lists the working trees attached to the repository, the main
+// one first.
+func (c *Client) Worktrees(ctx context.Context) ([]Worktree, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	worktrees := []Worktree{}
+	for _, line := range splitAndFilter(out) {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			worktrees = append(worktrees, Worktree{Path: value})
+		case "branch":
+			if n := len(worktrees); n > 0 {
+				worktrees[n-1].Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		case "bare":
+			if n := len(worktrees); n > 0 {
+				worktrees[n-1].Bare = true
+			}
+		}
+	}
+	return worktrees, nil
+}
+
+// AddWorktree checks branch out in a new working tree at path. A branch
+// that only exists on a single remote is created to track it, as git
+// worktree add does.
+func (c *Client) AddWorktree(ctx context.Context, path, branch string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	path = strings.TrimSpace(path)
+	branch = strings.TrimSpace(branch)
+	if path == "" {
+		return "", errors.New("worktree path is required")
+	}
+	if branch == "" {
+		return "", errors.New("branch name is required")
+	}
+	return c.runner.Run(ctx, "worktree", "add", path, branch)
+}
+
+// parseSubmoduleLine decodes a line of git submodule status, such as
+// "+1234abcd lib/x (heads/main)". A leading space, which marks an
+// up-to-date submodule, may have been trimmed.
+func parseSubmoduleLine(line string) (Submodule, bool) {
+	submodule := Submodule{Initialized: true}
+	switch line[0] {
+	case '-':
+		submodule.Initialized = false
+		line = line[1:]
+	case '+':
+		submodule.Modified = true
+		line = line[1:]
+	case 'U':
+		submodule.Conflicted = true
+		line = line[1:]
+	}
+	commit, rest, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok || commit == "" {
+		return Submodule{}, false
+	}
+	submodule.Commit = commit
+	if i := strings.LastIndex(rest, " ("); i >= 0 && strings.HasSuffix(rest, ")") {
+		submodule.Describe = rest[i+2 : len(rest)-1]
+		rest = rest[:i]
+	}
+	submodule.Path = unquoteRefName(rest)
+	return submodule, submodule.Path != ""
+}
+
+// FetchRemote runs git fetch for the named remote.
+func (c *Client) FetchRemote(ctx context.Context, remote string) (RemoteResult, error) {
+	return c.runRemoteCommand(ctx, true, remote, "fetch", remote)
+}
+
+// PruneRemote deletes stale remote-tracking branches of the named remote.
+func (c *Client) PruneRemote(ctx context.Context, remote string) (RemoteResult, error) {
+	return c.runRemoteCommand(ctx, true, remote, "remote", "prune", remote)
+}
+
+// SetRemoteURL changes the URL of the named remote.
+func (c *Client) SetRemoteURL(ctx context.Context, remote, url string) (RemoteResult, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return RemoteResult{}, errors.New("remote URL is required")
+	}
+	return c.runRemoteCommand(ctx, false, remote, "remote", "set-url", remote, url)
+}
+
+// runRemoteCommand runs a remote maintenance command. Commands that contact
+// the remote pass network so that they follow the retry policy.
+func (c *Client) runRemoteCommand(ctx context.Context, network bool, remote string, args ...string) (RemoteResult, error) {
+	if c == nil || c.runner == nil {
+		return RemoteResult{}, errors.New("git client is not configured")
+	}
+	if strings.TrimSpace(remote) == "" {
+		return RemoteResult{}, errors.New("remote name is required")
+	}
+
+	if network {
+		stdout, stderr, err := c.runNetwork(ctx, args...)
+		return RemoteResult{Stdout: stdout, Stderr: stderr}, err
+	}
+	if combined, ok := c.runner.(CombinedRunner); ok {
+		stdout, stderr, err := combined.RunWithCombinedOutput(ctx, args...)
+		return RemoteResult{Stdout: stdout, Stderr: stderr}, err
+	}
+
+	stdout, err := c.runner.Run(ctx, args...)
+	return RemoteResult{Stdout: stdout}, err
+}
+
+// BranchRefs returns local and remote-tracking branches, most recent commit
+// first. Symbolic refs such as origin/HEAD are skipped.
+func (c *Client) BranchRefs(ctx context.Context) ([]BranchRef, error) {
+	return c.branchRefs(ctx, "refs/heads", "refs/remotes")
+}
+
+// LocalBranchRefs returns the local branches, most recent commit first.
+func (c *Client) LocalBranchRefs(ctx context.Context) ([]BranchRef, error) {
+	return c.branchRefs(ctx, "refs/heads")
+}
+
+// branchRefs lists the branches under the given ref prefixes, most recent
+// commit first.
+func (c *Client) branchRefs(ctx context.Context, prefixes ...string) ([]BranchRef, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	args := []string{"for-each-ref", "--sort=-committerdate", "--format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail)"}
+	out, err := c.runner.Run(ctx, append(args, prefixes...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []BranchRef{}
+	for _, line := range splitAndFilter(out) {
+		if ref, ok := parseBranchRefLine(line); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+func parseBranchRefLine(line string) (BranchRef, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 2 || (len(fields) > 2 && fields[2] != "") {
+		return BranchRef{}, false
+	}
+	fields[0] = unquoteRefName(fields[0])
+
+	var ref BranchRef
+	switch {
+	case strings.HasPrefix(fields[0], "refs/heads/"):
+		ref.Name = strings.TrimPrefix(fields[0], "refs/heads/")
+	case strings.HasPrefix(fields[0], "refs/remotes/"):
+		ref.Name = strings.TrimPrefix(fields[0], "refs/remotes/")
+		ref.Remote = true
+	default:
+		return BranchRef{}, false
+	}
+	if ref.Name == "" {
+		return BranchRef{}, false
+	}
+	if seconds, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+		ref.CommitDate = time.Unix(seconds, 0)
+	}
+	if len(fields) > 3 {
+		ref.Author = fields[3]
+	}
+	return ref, true
+}
+
+// RefStates returns the tip of every local and remote-tracking branch, and
+// the upstream of each local one, in a single cheap call. Symbolic refs
+// such as origin/HEAD are skipped.
+func (c *Client) RefStates(ctx context.Context) ([]RefState, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname)%09%(objectname)%09%(symref)%09%(upstream:short)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+
+	states := []RefState{}
+	for _, line := range splitAndFilter(out) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || (len(fields) > 2 && fields[2] != "") {
+			continue
+		}
+		state := RefState{Hash: fields[1]}
+		switch name := unquoteRefName(fields[0]); {
+		case strings.HasPrefix(name, "refs/heads/"):
+			state.Name = strings.TrimPrefix(name, "refs/heads/")
+		case strings.HasPrefix(name, "refs/remotes/"):
+			state.Name = strings.TrimPrefix(name, "refs/remotes/")
+		default:
+			continue
+		}
+		if len(fields) > 3 {
+			state.Upstream = unquoteRefName(fields[3])
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// BranchStatuses returns every local branch with its upstream tracking
+// information, most recent commit first.
+func (c *Client) BranchStatuses(ctx context.Context) ([]BranchStatus, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--sort=-committerdate", "--format=%(refname:lstrip=2)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []BranchStatus{}
+	for _, line := range splitAndFilter(out) {
+		fields := strings.Split(line, "\t")
+		status := BranchStatus{Name: unquoteRefName(fields[0])}
+		if len(fields) > 1 {
+			if seconds, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				status.CommitDate = time.Unix(seconds, 0)
+			}
+		}
+		if len(fields) > 2 {
+			status.Upstream = unquoteRefName(fields[2])
+		}
+		if len(fields) > 3 {
+			status.Ahead, status.Behind, status.Gone = parseTrack(fields[3])
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// BranchSubjects returns the subject line of the last commit of every local
+// and remote-tracking branch, keyed by the same short names as BranchRefs.
+func (c *Client) BranchSubjects(ctx context.Context) (map[string]string, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname)%09%(symref)%09%(contents:subject)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	subjects := map[string]string{}
+	for _, line := range splitAndFilter(out) {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 || fields[1] != "" {
+			continue
+		}
+		switch name := unquoteRefName(fields[0]); {
+		case strings.HasPrefix(name, "refs/heads/"):
+			subjects[strings.TrimPrefix(name, "refs/heads/")] = fields[2]
+		case strings.HasPrefix(name, "refs/remotes/"):
+			subjects[strings.TrimPrefix(name, "refs/remotes/")] = fields[2]
+		}
+	}
+	return subjects, nil
+}
+
+// parseTrack decodes %(upstream:track) output such as "[ahead 2, behind 1]" or "[gone]".
+func parseTrack(track string) (ahead, behind int, gone bool) {
+	track = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(track), "["), "]")
+	for _, part := range strings.Split(track, ",") {
+		word, count, _ := strings.Cut(strings.TrimSpace(part), " ")
+		n, _ := strconv.Atoi(count)
+		switch word {
+		case "ahead":
+			ahead = n
+		case "behind":
+			behind = n
+		case "gone":
+			gone = true
+		}
+	}
+	return ahead, behind, gone
+}
+
+// MergedBranches returns the local and remote-tracking branches whose tips are
+// reachable from base, using the same short names as BranchRefs.
+func (c *Client) MergedBranches(ctx context.Context, base string) ([]string, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return nil, errors.New("base branch is required")
+	}
+	return c.branchesWith(ctx, "--merged="+base)
+}
+
+// EmptyBranches returns the local branches other than base whose tip is the
+// tip of base, so that they have no commits of their own, such as branches
+// created for work that never started.
+func (c *Client) EmptyBranches(ctx context.Context, base string) ([]string, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return nil, errors.New("base branch is required")
+	}
+	out, err := c.runner.Run(ctx, "for-each-ref", "--points-at="+base, "--format=%(refname)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, name := range splitRefNames(out) {
+		if name = strings.TrimPrefix(name, "refs/heads/"); name != base {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// BranchesContaining returns the local and remote-tracking branches that
+// contain commit, as git branch --contains does, using the same short names
+// as BranchRefs.
+func (c *Client) BranchesContaining(ctx context.Context, commit string) ([]string, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	commit = strings.TrimSpace(commit)
+	if commit == "" {
+		return nil, errors.New("commit is required")
+	}
+	return c.branchesWith(ctx, "--contains="+commit)
+}
+
+// branchesWith lists the short names of the local and remote-tracking
+// branches selected by a for-each-ref filter option such as --merged=main.
+func (c *Client) branchesWith(ctx context.Context, option string) ([]string, error) {
+	out, err := c.runner.Run(ctx, "for-each-ref", option, "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, name := range splitRefNames(out) {
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			names = append(names, strings.TrimPrefix(name, "refs/heads/"))
+		case strings.HasPrefix(name, "refs/remotes/"):
+			names = append(names, strings.TrimPrefix(name, "refs/remotes/"))
+		}
+	}
+	return names, nil
+}
+
+// CommitCounts returns how many commits each branch has that base lacks,
+// keyed by the same short names as BranchRefs. With git 2.41 or newer a
+// single for-each-ref counts every local and remote-tracking branch; older
+// versions run git rev-list --count for each local branch only.
+func (c *Client) CommitCounts(ctx context.Context, base string) (map[string]int, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return nil, errors.New("base branch is required")
+	}
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if CompareVersions(version, aheadBehindMinVersion) < 0 {
+		return c.countCommitsPerBranch(ctx, base)
+	}
+
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(refname)%09%(symref)%09%(ahead-behind:"+base+")", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, line := range splitAndFilter(out) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 || fields[1] != "" {
+			continue
+		}
+		ahead, _, _ := strings.Cut(fields[2], " ")
+		n, err := strconv.Atoi(ahead)
+		if err != nil {
+			continue
+		}
+		switch name := unquoteRefName(fields[0]); {
+		case strings.HasPrefix(name, "refs/heads/"):
+			counts[strings.TrimPrefix(name, "refs/heads/")] = n
+		case strings.HasPrefix(name, "refs/remotes/"):
+			counts[strings.TrimPrefix(name, "refs/remotes/")] = n
+		}
+	}
+	return counts, nil
+}
+
+// countCommitsPerBranch is CommitCounts for git releases without
+// %(ahead-behind), at the cost of one git call per local branch.
+func (c *Client) countCommitsPerBranch(ctx context.Context, base string) (map[string]int, error) {
+	branches, err := c.LocalBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(branches))
+	for _, branch := range branches {
+		out, err := c.runner.Run(ctx, "rev-list", "--count", base+"..refs/heads/"+branch)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(out))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git rev-list --count output %q", strings.TrimSpace(out))
+		}
+		counts[branch] = n
+	}
+	return counts, nil
+}
+
+// DefaultBranch returns the repository's default branch as advertised by
+// refs/remotes/origin/HEAD, falling back to the first existing of main and master.
+func (c *Client) DefaultBranch(ctx context.Context) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+
+	out, err := c.runner.Run(ctx, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if err == nil {
+		if branch := strings.TrimPrefix(strings.TrimSpace(out), "origin/"); branch != "" {
+			return branch, nil
+		}
+	}
+
+	for _, candidate := range fallbackBaseBranches {
+		exists, err := c.BranchExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return candidate, nil
+		}
+	}
+	return "", ErrBaseBranchNotFound
+}
+
+// BaseBranch returns override when set and present locally, otherwise the detected default branch.
+func (c *Client) BaseBranch(ctx context.Context, override string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	override = strings.TrimSpace(override)
+	if override == "" {
+		return c.DefaultBranch(ctx)
+	}
+
+	exists, err := c.BranchExists(ctx, override)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("%w: configured base %q does not exist", ErrBaseBranchNotFound, override)
+	}
+	return override, nil
+}
+
+// CheckoutRemoteBranch creates a local branch tracking the remote-tracking
+// branch ref (such as origin/feature/x) and switches to it.
+func (c *Client) CheckoutRemoteBranch(ctx context.Context, ref string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("branch name is required")
+	}
+	out, err := c.runner.Run(ctx, c.switchCommand(ctx), "--track", ref)
+	return out, checkoutError(err)
+}
+
+// CheckoutBranch switches the working tree to the specified local branch.
+func (c *Client) CheckoutBranch(ctx context.Context, branch string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return "", errors.New("branch name is required")
+	}
+
+	current, err := c.CurrentBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+	if branch == current {
+		return fmt.Sprintf("already on '%s'", branch), nil
+	}
+
+	args := []string{"checkout", branch}
+	if command := c.switchCommand(ctx); command == "switch" {
+		// --guess tracks a remote branch of the same name even when
+		// checkout.guess is off in the git config.
+		args = []string{command, "--guess", branch}
+	}
+	out, err := c.runner.Run(ctx, args...)
+	if err != nil {
+		return "", checkoutError(err)
+	}
+	return out, nil
+}
+
+// CheckoutDetached detaches HEAD at commit. git switch refuses anything
+// but a branch without --detach, so the flag is passed to either command.
+// When merge is set, uncommitted changes that conflict with commit are
+// merged into it as with CheckoutMerge.
+func (c *Client) CheckoutDetached(ctx context.Context, commit string, merge bool) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	commit = strings.TrimSpace(commit)
+	if commit == "" {
+		return "", errors.New("commit is required")
+	}
+	args := []string{c.switchCommand(ctx), "--detach"}
+	if merge {
+		args = append(args, "-m")
+	}
+	out, err := c.runner.Run(ctx, append(args, commit)...)
+	if merge {
+		return out, err
+	}
+	return out, checkoutError(err)
+}
+
+// CheckoutMerge switches to ref like CheckoutBranch, or CheckoutRemoteBranch
+// when remote is set, but with git checkout -m: uncommitted changes that
+// conflict with the target are merged into it instead of refusing the
+// switch. Changes that do not merge cleanly are left as conflicts.
+func (c *Client) CheckoutMerge(ctx context.Context, ref string, remote bool) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("branch name is required")
+	}
+	args := []string{c.switchCommand(ctx), "-m"}
+	if remote {
+		args = append(args, "--track")
+	}
+	return c.runner.Run(ctx, append(args, ref)...)
+}
+
+// checkoutError marks the error of a checkout refused over uncommitted
+// changes with ErrLocalChangesOverwritten, keeping git's message.
+func checkoutError(err error) error {
+	if err == nil || !strings.Contains(err.Error(), "would be overwritten by checkout") {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrLocalChangesOverwritten, err)
+}
+
+// CreateBranch creates branch at start, a local or remote-tracking branch,
+// and switches to it.
+func (c *Client) CreateBranch(ctx context.Context, branch, start string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	branch, start = strings.TrimSpace(branch), strings.TrimSpace(start)
+	if branch == "" || start == "" {
+		return "", errors.New("branch name and start point are required")
+	}
+	return c.runner.Run(ctx, "checkout", "-b", branch, start)
+}
+
+// CreateBranchAt creates branch at start without switching to it.
+func (c *Client) CreateBranchAt(ctx context.Context, branch, start string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	branch, start = strings.TrimSpace(branch), strings.TrimSpace(start)
+	if branch == "" || start == "" {
+		return "", errors.New("branch name and start point are required")
+	}
+	return c.runner.Run(ctx, "branch", branch, start)
+}
+
+// ResetMode selects what git reset does to the index and the working tree.
+type ResetMode string
+
+const (
+	// ResetSoft moves the branch only, keeping the changes staged.
+	ResetSoft ResetMode = "soft"
+	// ResetMixed also resets the index, keeping the changes in the working tree.
+	ResetMixed ResetMode = "mixed"
+	// ResetHard also resets the working tree, discarding uncommitted changes.
+	ResetHard ResetMode = "hard"
+)
+
+// WorkTreeStatus counts the uncommitted changes in the working tree, as git
+// status --porcelain lists them.
+type WorkTreeStatus struct {
+	// Modified counts tracked files with staged or unstaged changes,
+	// including added, deleted, and renamed ones.
+	Modified int
+	// Untracked counts untracked files; an untracked directory counts once.
+	Untracked int
+	// Conflicted counts files with unresolved merge conflicts.
+	Conflicted int
+}
+
+// Clean reports whether there is nothing to commit or stash.
+func (s WorkTreeStatus) Clean() bool {
+	return s == WorkTreeStatus{}
+}
+
+// WorkTreeStatus counts the uncommitted changes in the working tree.
+func (c *Client) WorkTreeStatus(ctx context.Context) (WorkTreeStatus, error) {
+	if c == nil || c.runner == nil {
+		return WorkTreeStatus{}, errors.New("git client is not configured")
+	}
+	out, err := c.runner.Run(ctx, "status", "--porcelain")
+	if err != nil {
+		return WorkTreeStatus{}, err
+	}
+	return parseStatus(splitAndFilter(out)), nil
+}
+
+// parseStatus counts git status --porcelain lines. The lines may have been
+// trimmed, so that " M file" arrives as "M file"; only the status letters
+// matter here.
+func parseStatus(lines []string) WorkTreeStatus {
+	var status WorkTreeStatus
+	for _, line := range lines {
+		code, _, _ := strings.Cut(line, " ")
+		switch {
+		case code == "??":
+			status.Untracked++
+		case code == "!!":
+		case code == "DD" || code == "AA" || strings.Contains(code, "U"):
+			status.Conflicted++
+		default:
+			status.Modified++
+		}
+	}
+	return status
+}
+
+// Stash saves the uncommitted changes, untracked files included, in a new
+// stash entry described by message and cleans the working tree.
+func (c *Client) Stash(ctx context.Context, message string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	args := []string{"stash", "push", "--include-untracked"}
+	if message = strings.TrimSpace(message); message != "" {
+		args = append(args, "-m", message)
+	}
+	return c.runner.Run(ctx, args...)
+}
+
+// ResetImpact describes what resetting the current branch to a target
+// takes away from it.
+type ResetImpact struct {
+	// Commits are the one-line summaries of the commits on HEAD that the
+	// target lacks, newest first.
+	Commits []string
+	// Changes are the uncommitted changes to tracked files as trimmed git
+	// status --porcelain lines, which a hard reset discards.
+	Changes []string
+}
+
+// ResetImpact reports what resetting the current branch to target would
+// take away from it.
+func (c *Client) ResetImpact(ctx context.Context, target string) (ResetImpact, error) {
+	if c == nil || c.runner == nil {
+		return ResetImpact{}, errors.New("git client is not configured")
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return ResetImpact{}, errors.New("reset target is required")
+	}
+
+	commits, err := c.runner.Run(ctx, "log", "--no-color", "--format=%h %s", target+"..HEAD")
+	if err != nil {
+		return ResetImpact{}, err
+	}
+	changes, err := c.runner.Run(ctx, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return ResetImpact{}, err
+	}
+	return ResetImpact{Commits: splitAndFilter(commits), Changes: splitAndFilter(changes)}, nil
+}
+
+// Reset moves the current branch to target with git reset in mode.
+func (c *Client) Reset(ctx context.Context, target string, mode ResetMode) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("reset target is required")
+	}
+	switch mode {
+	case ResetSoft, ResetMixed, ResetHard:
+	default:
+		return "", fmt.Errorf("unknown reset mode %q", mode)
+	}
+	return c.runner.Run(ctx, "reset", "--"+string(mode), target)
+}
+
+// MergeBranch merges the provided branch into the current branch.
+func (c *Client) MergeBranch(ctx context.Context, branch string, opts MergeOptions) (MergeResult, error) {
+	if c == nil || c.runner == nil {
+		return MergeResult{}, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return MergeResult{}, errors.New("branch name is required")
+	}
+
+	args := []string{"merge"}
+	args = append(args, opts.args()...)
+	args = append(args, branch)
+
+	if combined, ok := c.runner.(CombinedRunner); ok {
+		stdout, stderr, err := combined.RunWithCombinedOutput(ctx, args...)
+		return MergeResult{Stdout: stdout, Stderr: stderr}, err
+	}
+
+	stdout, err := c.runner.Run(ctx, args...)
+	return MergeResult{Stdout: stdout}, err
+}
+
+// BranchLog returns the log of the commits on branch that HEAD does not
+// contain. color asks git for ANSI colors, which suit a terminal or pager.
+func (c *Client) BranchLog(ctx context.Context, branch string, color bool) (string, error) {
+	return c.runOutput(ctx, branch, color, nil, "log", "--decorate", "HEAD.."+strings.TrimSpace(branch))
+}
+
+// Commit is a commit listed by RecentCommits.
+type Commit struct {
+	Short   string
+	Subject string
+}
+
+// RecentCommits returns up to n of the latest commits of branch, newest
+// first.
+func (c *Client) RecentCommits(ctx context.Context, branch string, n int) ([]Commit, error) {
+	if c == nil || c.runner == nil {
+		return nil, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return nil, errors.New("branch name is required")
+	}
+	out, err := c.runner.Run(ctx, "log", "--max-count="+strconv.Itoa(n), "--format=%h%x09%s", branch, "--")
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range splitAndFilter(out) {
+		short, subject, _ := strings.Cut(line, "\t")
+		commits = append(commits, Commit{Short: short, Subject: subject})
+	}
+	return commits, nil
+}
+
+// BranchDescription returns the description of a local branch set with
+// git branch --edit-description, or "" when it has none.
+func (c *Client) BranchDescription(ctx context.Context, branch string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return "", errors.New("branch name is required")
+	}
+	out, err := c.runner.Run(ctx, "config", "--get", "branch."+branch+".description")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// DiffOptions configures BranchDiff.
+type DiffOptions struct {
+	// Color asks git for ANSI colors, which suit a terminal or pager.
+	Color bool
+	// External is a command git runs for each changed file instead of its
+	// own diff, as with diff.external.
+	External string
+}
+
+// BranchDiff returns the changes branch made since it forked from HEAD.
+func (c *Client) BranchDiff(ctx context.Context, branch string, opts DiffOptions) (string, error) {
+	revisions := "HEAD..." + strings.TrimSpace(branch)
+	if external := strings.TrimSpace(opts.External); external != "" {
+		return c.runOutput(ctx, branch, opts.Color, []string{"-c", "diff.external=" + external}, "diff", "--ext-diff", revisions)
+	}
+	return c.runOutput(ctx, branch, opts.Color, nil, "diff", revisions)
+}
+
+// runOutput runs a git command that produces output meant for reading,
+// such as log or diff, with or without colors. options go before the
+// command, as with git -c.
+func (c *Client) runOutput(ctx context.Context, branch string, color bool, options []string, command string, args ...string) (string, error) {
+	if c == nil || c.runner == nil {
+		return "", errors.New("git client is not configured")
+	}
+	if strings.TrimSpace(branch) == "" {
+		return "", errors.New("branch name is required")
+	}
+	colorArg := "--no-color"
+	if color {
+		colorArg = "--color=always"
+	}
+	cmdArgs := append(append(options, command, colorArg), args...)
+	return c.runner.Run(ctx, cmdArgs...)
+}
+
+// PredictMerge performs a trial merge of branch into HEAD with
+// git merge-tree --write-tree and reports the conflicting paths. It returns
+// ErrMergePredictionUnsupported when git is older than 2.38.
+func (c *Client) PredictMerge(ctx context.Context, branch string) (MergePrediction, error) {
+	if c == nil || c.runner == nil {
+		return MergePrediction{}, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return MergePrediction{}, errors.New("branch name is required")
+	}
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		return MergePrediction{}, err
+	}
+	if CompareVersions(version, mergeTreeMinVersion) < 0 {
+		return MergePrediction{}, fmt.Errorf("%w (found %s)", ErrMergePredictionUnsupported, version)
+	}
+
+	// merge-tree exits 1 when the merge conflicts, still printing the tree
+	// and the conflicting paths, so the output decides rather than the error.
+	out, runErr := c.runner.Run(ctx, "merge-tree", "--write-tree", "--name-only", "--no-messages", "HEAD", branch)
+	lines := splitAndFilter(out)
+	if len(lines) == 0 || !isObjectID(lines[0]) {
+		if runErr != nil {
+			return MergePrediction{}, runErr
+		}
+		return MergePrediction{}, fmt.Errorf("unexpected git merge-tree output %q", strings.TrimSpace(out))
+	}
+	if runErr == nil {
+		return MergePrediction{}, nil
+	}
+	if len(lines) == 1 {
+		return MergePrediction{}, runErr
+	}
+	return MergePrediction{Conflicts: lines[1:]}, nil
+}
+
+// isObjectID reports whether s is a full SHA-1 or SHA-256 object name.
+func isObjectID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteBranch removes the specified local branch, optionally forcing deletion.
+func (c *Client) DeleteBranch(ctx context.Context, branch string, opts DeleteOptions) (DeleteResult, error) {
+	if c == nil || c.runner == nil {
+		return DeleteResult{}, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return DeleteResult{}, errors.New("branch name is required")
+	}
+
+	current, err := c.CurrentBranch(ctx)
 	if err != nil {
 		return DeleteResult{}, err
 	}
@@ -283,6 +2156,107 @@ func (c *Client) DeleteBranch(ctx context.Context, branch string, opts DeleteOpt
 	return result, nil
 }
 
+// FullyMerged reports whether git branch -d would delete branch without
+// force: whether its tip is reachable from its upstream, or from HEAD when
+// the upstream is unset or gone.
+func (c *Client) FullyMerged(ctx context.Context, branch string) (bool, error) {
+	if c == nil || c.runner == nil {
+		return false, errors.New("git client is not configured")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return false, errors.New("branch name is required")
+	}
+
+	out, err := c.runner.Run(ctx, "for-each-ref", "--format=%(upstream)", "refs/heads/"+branch)
+	if err != nil {
+		return false, err
+	}
+	reference := "HEAD"
+	if upstream := strings.TrimSpace(out); upstream != "" {
+		exists, err := c.refExists(ctx, upstream)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			reference = upstream
+		}
+	}
+	_, err = c.runner.Run(ctx, "merge-base", "--is-ancestor", "refs/heads/"+branch, reference)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoteBranchExists reports whether the remote-tracking branch
+// <remote>/<branch> exists locally.
+func (c *Client) RemoteBranchExists(ctx context.Context, remote, branch string) (bool, error) {
+	if c == nil || c.runner == nil {
+		return false, errors.New("git client is not configured")
+	}
+	if strings.TrimSpace(remote) == "" || strings.TrimSpace(branch) == "" {
+		return false, nil
+	}
+	return c.refExists(ctx, "refs/remotes/"+remote+"/"+branch)
+}
+
+func (c *Client) refExists(ctx context.Context, ref string) (bool, error) {
+	_, err := c.runner.Run(ctx, "show-ref", "--verify", "--quiet", ref)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteRemoteBranch deletes branch on remote with git push --delete, which
+// also removes its remote-tracking branch.
+func (c *Client) DeleteRemoteBranch(ctx context.Context, remote, branch string) (PushResult, error) {
+	if c == nil || c.runner == nil {
+		return PushResult{}, errors.New("git client is not configured")
+	}
+	remote = strings.TrimSpace(remote)
+	branch = strings.TrimSpace(branch)
+	if remote == "" || branch == "" {
+		return PushResult{}, errors.New("remote and branch name are required")
+	}
+	stdout, stderr, err := c.runNetwork(ctx, "push", remote, "--delete", branch)
+	return PushResult{Stdout: stdout, Stderr: stderr}, err
+}
+
+func parseRemotes(output string) []Remote {
+	remotes := []Remote{}
+	seen := map[string]int{}
+	for _, line := range splitAndFilter(output) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, url := fields[0], fields[1]
+		kind := ""
+		if len(fields) > 2 {
+			kind = fields[2]
+		}
+		if idx, ok := seen[name]; ok {
+			if kind == "(fetch)" {
+				remotes[idx].URL = url
+			}
+			continue
+		}
+		seen[name] = len(remotes)
+		remotes = append(remotes, Remote{Name: name, URL: url})
+	}
+	return remotes
+}
+
 func isNotFullyMerged(stdout, stderr string) bool {
 	combined := strings.TrimSpace(stdout + "\n" + stderr)
 	if combined == "" {
@@ -292,15 +2266,65 @@ func isNotFullyMerged(stdout, stderr string) bool {
 	return strings.Contains(combined, "not fully merged")
 }
 
-func parseReflogSubjects(output string) []string {
-	lines := splitAndFilter(output)
-	branches := make([]string, 0, len(lines))
-	for _, line := range lines {
-		if branch, ok := extractBranchFromSubject(line); ok {
-			branches = append(branches, branch)
+// parseReflogEntries extracts distinct branch visits from reflog lines in
+// order, stopping after maxBranches visits when maxBranches is positive. Each
+// line is a subject, optionally preceded by a HEAD@{<unix time>} selector and
+// a tab.
+func parseReflogEntries(output string, maxBranches int) []ReflogVisit {
+	var visits []ReflogVisit
+	seen := make(map[string]struct{})
+	for len(output) > 0 && (maxBranches <= 0 || len(visits) < maxBranches) {
+		line, rest, _ := strings.Cut(output, "\n")
+		output = rest
+		when, subject := splitReflogSelector(strings.TrimSpace(line))
+		branch, ok := extractBranchFromSubject(subject)
+		if !ok {
+			continue
+		}
+		if _, dup := seen[branch]; dup {
+			continue
+		}
+		seen[branch] = struct{}{}
+		visits = append(visits, ReflogVisit{Branch: branch, Time: when})
+	}
+	return visits
+}
+
+// parseHeadHistory parses HeadHistory lines of the form
+// <hash>\t<short>\tHEAD@{<unix time>}\t<subject>. Entries are numbered by
+// their position, as git numbers them.
+func parseHeadHistory(output string) []HeadEntry {
+	var entries []HeadEntry
+	for _, line := range strings.Split(output, "\n") {
+		hash, rest, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || hash == "" {
+			continue
 		}
+		short, rest, _ := strings.Cut(rest, "\t")
+		when, subject := splitReflogSelector(rest)
+		entries = append(entries, HeadEntry{
+			Selector: fmt.Sprintf("HEAD@{%d}", len(entries)),
+			Hash:     hash,
+			Short:    short,
+			Subject:  strings.TrimSpace(subject),
+			Time:     when,
+		})
+	}
+	return entries
+}
+
+// splitReflogSelector separates a leading HEAD@{<unix time>} selector from
+// the subject. Lines without one are returned unchanged with a zero time.
+func splitReflogSelector(line string) (time.Time, string) {
+	selector, subject, ok := strings.Cut(line, "\t")
+	if !ok || !strings.HasPrefix(selector, "HEAD@{") || !strings.HasSuffix(selector, "}") {
+		return time.Time{}, line
+	}
+	seconds, err := strconv.ParseInt(selector[len("HEAD@{"):len(selector)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, subject
 	}
-	return branches
+	return time.Unix(seconds, 0), subject
 }
 
 func splitAndFilter(s string) []string {
@@ -315,46 +2339,3 @@ func splitAndFilter(s string) []string {
 	}
 	return out
 }
-
-func extractBranchFromSubject(subject string) (string, bool) {
-	if subject == "" {
-		return "", false
-	}
-
-	const (
-		prefixMoveFrom  = "checkout: moving from "
-		prefixMoveTo    = "checkout: moving to "
-		prefixSwitching = "checkout: switching to "
-	)
-
-	switch {
-	case strings.HasPrefix(subject, prefixMoveFrom):
-		rest := strings.TrimPrefix(subject, prefixMoveFrom)
-		idx := strings.LastIndex(rest, " to ")
-		if idx == -1 {
-			return "", false
-		}
-		branch := strings.TrimSpace(rest[idx+4:])
-		branch = strings.Trim(branch, "'\"")
-		if branch == "" {
-			return "", false
-		}
-		return branch, true
-	case strings.HasPrefix(subject, prefixMoveTo):
-		branch := strings.TrimSpace(strings.TrimPrefix(subject, prefixMoveTo))
-		branch = strings.Trim(branch, "'\"")
-		if branch == "" {
-			return "", false
-		}
-		return branch, true
-	case strings.HasPrefix(subject, prefixSwitching):
-		branch := strings.TrimSpace(strings.TrimPrefix(subject, prefixSwitching))
-		branch = strings.Trim(branch, "'\"")
-		if branch == "" {
-			return "", false
-		}
-		return branch, true
-	default:
-		return "", false
-	}
-}
@@ -1,14 +1,20 @@
 package git
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExtractBranchFromSubject(t *testing.T) {
@@ -49,6 +55,76 @@ func TestExtractBranchFromSubject(t *testing.T) {
 			branch:  "",
 			ok:      false,
 		},
+		"rebase-finish": {
+			subject: "rebase (finish): returning to refs/heads/feature/rebased",
+			branch:  "feature/rebased",
+			ok:      true,
+		},
+		"rebase-interactive-finish": {
+			subject: "rebase -i (finish): returning to refs/heads/topic",
+			branch:  "topic",
+			ok:      true,
+		},
+		"pull-rebase-finish": {
+			subject: "pull --rebase (finish): returning to refs/heads/main",
+			branch:  "main",
+			ok:      true,
+		},
+		"rebase-finished-old-git": {
+			subject: "rebase finished: returning to refs/heads/legacy",
+			branch:  "legacy",
+			ok:      true,
+		},
+		"rebase-start": {
+			subject: "rebase (start): checkout main",
+			branch:  "",
+			ok:      false,
+		},
+		"rebase-pick": {
+			subject: "rebase (pick): add feature",
+			branch:  "",
+			ok:      false,
+		},
+		"rebase-finish-detached": {
+			subject: "rebase (finish): returning to 1a2b3c4d",
+			branch:  "",
+			ok:      false,
+		},
+		"reset-branch": {
+			subject: "reset: moving to release/1.2",
+			branch:  "release/1.2",
+			ok:      true,
+		},
+		"reset-full-ref": {
+			subject: "reset: moving to refs/heads/hotfix",
+			branch:  "hotfix",
+			ok:      true,
+		},
+		"reset-revision": {
+			subject: "reset: moving to HEAD~1",
+			branch:  "",
+			ok:      false,
+		},
+		"reset-head": {
+			subject: "reset: moving to HEAD",
+			branch:  "",
+			ok:      false,
+		},
+		"reset-hash": {
+			subject: "reset: moving to 0123456789abcdef0123456789abcdef01234567",
+			branch:  "",
+			ok:      false,
+		},
+		"reset-reflog-selector": {
+			subject: "reset: moving to main@{1}",
+			branch:  "",
+			ok:      false,
+		},
+		"branch-rename": {
+			subject: "Branch: renamed refs/heads/old-name to refs/heads/new-name",
+			branch:  "new-name",
+			ok:      true,
+		},
 	}
 
 	for name, tc := range cases {
@@ -64,20 +140,71 @@ func TestExtractBranchFromSubject(t *testing.T) {
 	}
 }
 
-func TestParseReflogSubjects(t *testing.T) {
+func TestParseReflogEntries(t *testing.T) {
 	t.Parallel()
 
-	input := "checkout: moving from main to feature/one\ncheckout: switching to 'feature/two'\ncommit: add something"
-	got := parseReflogSubjects(input)
-	want := []string{"feature/one", "feature/two"}
+	input := "HEAD@{1700000300}\tcheckout: moving from main to feature/one\n" +
+		"HEAD@{1700000200}\tcheckout: switching to 'feature/two'\n" +
+		"HEAD@{1700000150}\tcommit: add something\n" +
+		"HEAD@{1700000100}\tcheckout: moving from feature/two to feature/one\n" +
+		"HEAD@{1700000000}\tcheckout: moving from feature/one to main\n"
+	one := ReflogVisit{Branch: "feature/one", Time: time.Unix(1700000300, 0)}
+	two := ReflogVisit{Branch: "feature/two", Time: time.Unix(1700000200, 0)}
+	mainVisit := ReflogVisit{Branch: "main", Time: time.Unix(1700000000, 0)}
 
-	if len(got) != len(want) {
-		t.Fatalf("parseReflogSubjects returned %d entries, want %d", len(got), len(want))
+	cases := map[string]struct {
+		input       string
+		maxBranches int
+		want        []ReflogVisit
+	}{
+		"unbounded":       {input: input, want: []ReflogVisit{one, two, mainVisit}},
+		"stops early":     {input: input, maxBranches: 2, want: []ReflogVisit{one, two}},
+		"bound too high":  {input: input, maxBranches: 10, want: []ReflogVisit{one, two, mainVisit}},
+		"subjects only":   {input: "checkout: moving from main to feature/one\ncheckout: moving from feature/one to main", want: []ReflogVisit{{Branch: "feature/one"}, {Branch: "main"}}},
+		"unparsable time": {input: "HEAD@{yesterday}\tcheckout: moving from main to feature/one", want: []ReflogVisit{{Branch: "feature/one"}}},
 	}
-	for i := range want {
-		if got[i] != want[i] {
-			t.Fatalf("parseReflogSubjects[%d] = %q, want %q", i, got[i], want[i])
-		}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := parseReflogEntries(tc.input, tc.maxBranches); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("parseReflogEntries() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientReflogBranchMoves(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		maxEntries int
+		args       []string
+	}{
+		"bounded":   {maxEntries: 300, args: []string{"reflog", "-n", "300", "--date=unix", "--format=%gd%x09%gs"}},
+		"unbounded": {args: []string{"reflog", "--date=unix", "--format=%gd%x09%gs"}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: tc.args, stdout: "checkout: moving from main to feature/one\ncheckout: moving from feature/one to main"},
+			}}
+			got, err := NewClient(runner).ReflogBranchMoves(context.Background(), tc.maxEntries, 0)
+			if err != nil {
+				t.Fatalf("ReflogBranchMoves returned error: %v", err)
+			}
+			if want := []string{"feature/one", "main"}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("ReflogBranchMoves() = %v, want %v", got, want)
+			}
+		})
 	}
 }
 
@@ -118,6 +245,11 @@ func (r *scriptRunner) RunWithCombinedOutput(ctx context.Context, args ...string
 	return call.stdout, call.stderr, call.err
 }
 
+func (r *scriptRunner) RunInteractive(ctx context.Context, args ...string) error {
+	_, _, err := r.RunWithCombinedOutput(ctx, args...)
+	return err
+}
+
 func (r *scriptRunner) Exhausted() bool {
 	return r.index == len(r.calls)
 }
@@ -131,6 +263,7 @@ func TestClientCheckoutBranch(t *testing.T) {
 	cases := map[string]struct {
 		calls     []scriptCall
 		branch    string
+		useSwitch bool
 		wantOut   string
 		wantErr   error
 		wantCalls int
@@ -144,6 +277,28 @@ func TestClientCheckoutBranch(t *testing.T) {
 			wantOut:   "Switched to branch 'feature/test'",
 			wantCalls: 2,
 		},
+		"switch": {
+			branch:    "feature/test",
+			useSwitch: true,
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+				{args: []string{"--version"}, stdout: "git version 2.43.0"},
+				{args: []string{"switch", "--guess", "feature/test"}, stdout: "Switched to branch 'feature/test'"},
+			},
+			wantOut:   "Switched to branch 'feature/test'",
+			wantCalls: 3,
+		},
+		"switch before git 2.23": {
+			branch:    "feature/test",
+			useSwitch: true,
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+				{args: []string{"--version"}, stdout: "git version 2.20.1"},
+				{args: []string{"checkout", "feature/test"}, stdout: "Switched to branch 'feature/test'"},
+			},
+			wantOut:   "Switched to branch 'feature/test'",
+			wantCalls: 3,
+		},
 		"already-on": {
 			branch: "feature/test",
 			calls: []scriptCall{
@@ -161,6 +316,15 @@ func TestClientCheckoutBranch(t *testing.T) {
 			wantErr:   gitErr,
 			wantCalls: 2,
 		},
+		"local-changes": {
+			branch: "feature/test",
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+				{args: []string{"checkout", "feature/test"}, err: errors.New("git checkout feature/test: exit status 1: error: Your local changes to the following files would be overwritten by checkout:\n\tmain.go")},
+			},
+			wantErr:   ErrLocalChangesOverwritten,
+			wantCalls: 2,
+		},
 	}
 
 	for name, tc := range cases {
@@ -171,6 +335,7 @@ func TestClientCheckoutBranch(t *testing.T) {
 
 			runner := &scriptRunner{testingT: t, calls: tc.calls}
 			client := NewClient(runner)
+			client.SetSwitch(tc.useSwitch)
 
 			out, err := client.CheckoutBranch(ctx, tc.branch)
 
@@ -206,6 +371,7 @@ func TestClientMergeBranch(t *testing.T) {
 	cases := map[string]struct {
 		calls   []scriptCall
 		branch  string
+		opts    MergeOptions
 		stdout  string
 		stderr  string
 		wantErr error
@@ -218,6 +384,20 @@ func TestClientMergeBranch(t *testing.T) {
 				{args: []string{"merge", "feature/topic"}, stdout: "Updating abc..def"},
 			},
 		},
+		"signed with sign-off": {
+			branch: "feature/topic",
+			opts:   MergeOptions{GPGSign: true, Signoff: true},
+			calls: []scriptCall{
+				{args: []string{"merge", "--gpg-sign", "--signoff", "feature/topic"}},
+			},
+		},
+		"signed with key": {
+			branch: "feature/topic",
+			opts:   MergeOptions{FastForward: FastForwardNoFF, GPGSign: true, SigningKey: "ABCD1234"},
+			calls: []scriptCall{
+				{args: []string{"merge", "--no-ff", "--gpg-sign=ABCD1234", "feature/topic"}},
+			},
+		},
 		"conflict": {
 			branch:  "feature/topic",
 			stdout:  "Auto-merging file.go",
@@ -238,7 +418,7 @@ func TestClientMergeBranch(t *testing.T) {
 			runner := &scriptRunner{testingT: t, calls: tc.calls}
 			client := NewClient(runner)
 
-			result, err := client.MergeBranch(ctx, tc.branch, MergeOptions{})
+			result, err := client.MergeBranch(ctx, tc.branch, tc.opts)
 			if tc.wantErr != nil {
 				if !errors.Is(err, tc.wantErr) {
 					t.Fatalf("expected error %v, got %v", tc.wantErr, err)
@@ -261,6 +441,174 @@ func TestClientMergeBranch(t *testing.T) {
 	}
 }
 
+func TestClientBranchLogAndDiff(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"log", "--color=always", "--decorate", "HEAD..feature/x"}, stdout: "commit abc"},
+		{args: []string{"diff", "--no-color", "HEAD...origin/feature/y"}, stdout: "diff --git a/x b/x"},
+		{args: []string{"-c", "diff.external=difft", "diff", "--color=always", "--ext-diff", "HEAD...feature/x"}, stdout: "x --- Rust"},
+	}}
+	client := NewClient(runner)
+
+	log, err := client.BranchLog(ctx, "feature/x", true)
+	if err != nil || log != "commit abc" {
+		t.Fatalf("BranchLog() = %q, %v", log, err)
+	}
+	diff, err := client.BranchDiff(ctx, " origin/feature/y ", DiffOptions{})
+	if err != nil || diff != "diff --git a/x b/x" {
+		t.Fatalf("BranchDiff() = %q, %v", diff, err)
+	}
+	external, err := client.BranchDiff(ctx, "feature/x", DiffOptions{Color: true, External: "difft"})
+	if err != nil || external != "x --- Rust" {
+		t.Fatalf("BranchDiff() with an external tool = %q, %v", external, err)
+	}
+	if _, err := client.BranchLog(ctx, " ", true); err == nil {
+		t.Fatal("expected an error for an empty branch name")
+	}
+}
+
+func TestClientRecentCommitsAndDescription(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"log", "--max-count=3", "--format=%h%x09%s", "feature/x", "--"}, stdout: "abc1234\tAdd parser\ndef5678\tFix\ttabs\n"},
+		{args: []string{"config", "--get", "branch.feature/x.description"}, stdout: "Parser rewrite\n"},
+		{args: []string{"config", "--get", "branch.other.description"}, err: exitError(t, 1)},
+	}}
+	client := NewClient(runner)
+
+	commits, err := client.RecentCommits(ctx, "feature/x", 3)
+	want := []Commit{{Short: "abc1234", Subject: "Add parser"}, {Short: "def5678", Subject: "Fix\ttabs"}}
+	if err != nil || !reflect.DeepEqual(commits, want) {
+		t.Fatalf("RecentCommits() = %+v, %v, want %+v", commits, err, want)
+	}
+	if description, err := client.BranchDescription(ctx, "feature/x"); err != nil || description != "Parser rewrite" {
+		t.Fatalf("BranchDescription() = %q, %v", description, err)
+	}
+	if description, err := client.BranchDescription(ctx, "other"); err != nil || description != "" {
+		t.Fatalf("BranchDescription() without one = %q, %v", description, err)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientCommitCounts(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		version string
+		calls   []scriptCall
+		want    map[string]int
+	}{
+		"ahead-behind in one call": {
+			version: "2.43.0",
+			calls: []scriptCall{{
+				args: []string{"for-each-ref", "--format=%(refname)%09%(symref)%09%(ahead-behind:main)", "refs/heads", "refs/remotes"},
+				stdout: "refs/heads/main\t\t0 0\n" +
+					"refs/heads/feature/x\t\t3 1\n" +
+					"refs/remotes/origin/HEAD\trefs/remotes/origin/main\t0 0\n" +
+					"refs/remotes/origin/topic\t\t1 4\n",
+			}},
+			want: map[string]int{"main": 0, "feature/x": 3, "origin/topic": 1},
+		},
+		"rev-list per local branch on old git": {
+			version: "2.39.2",
+			calls: []scriptCall{
+				{args: []string{"branch", "--list", "--format=%(refname:lstrip=2)"}, stdout: "feature/x\nmain\n"},
+				{args: []string{"rev-list", "--count", "main..refs/heads/feature/x"}, stdout: "3\n"},
+				{args: []string{"rev-list", "--count", "main..refs/heads/main"}, stdout: "0\n"},
+			},
+			want: map[string]int{"main": 0, "feature/x": 3},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := append([]scriptCall{{args: []string{"--version"}, stdout: "git version " + tc.version}}, tc.calls...)
+			runner := &scriptRunner{testingT: t, calls: calls}
+			got, err := NewClient(runner).CommitCounts(context.Background(), "main")
+			if err != nil {
+				t.Fatalf("CommitCounts returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("CommitCounts() = %v, want %v", got, tc.want)
+			}
+			if runner.index != len(calls) {
+				t.Fatalf("expected %d git calls, got %d", len(calls), runner.index)
+			}
+		})
+	}
+}
+
+func TestClientPredictMerge(t *testing.T) {
+	t.Parallel()
+
+	tree := "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	mergeTree := []string{"merge-tree", "--write-tree", "--name-only", "--no-messages", "HEAD", "feature/topic"}
+	exitErr := errors.New("exit status 1")
+
+	cases := map[string]struct {
+		version       string
+		calls         []scriptCall
+		wantConflicts []string
+		wantErr       error
+	}{
+		"clean": {
+			version: "2.43.0",
+			calls:   []scriptCall{{args: mergeTree, stdout: tree}},
+		},
+		"conflicts": {
+			version:       "2.43.0",
+			calls:         []scriptCall{{args: mergeTree, stdout: tree + "\nREADME.md\ninternal/ui/ui.go\n\n", err: exitErr}},
+			wantConflicts: []string{"README.md", "internal/ui/ui.go"},
+		},
+		"merge-tree failure": {
+			version: "2.43.0",
+			calls:   []scriptCall{{args: mergeTree, err: exitErr}},
+			wantErr: exitErr,
+		},
+		"old git": {
+			version: "2.37.1",
+			wantErr: ErrMergePredictionUnsupported,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := append([]scriptCall{{args: []string{"--version"}, stdout: "git version " + tc.version}}, tc.calls...)
+			runner := &scriptRunner{testingT: t, calls: calls}
+			got, err := NewClient(runner).PredictMerge(context.Background(), "feature/topic")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("PredictMerge error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PredictMerge returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Conflicts, tc.wantConflicts) {
+				t.Fatalf("Conflicts = %#v, want %#v", got.Conflicts, tc.wantConflicts)
+			}
+			if runner.index != len(calls) {
+				t.Fatalf("expected %d git calls, got %d", len(calls), runner.index)
+			}
+		})
+	}
+}
+
 func TestClientDeleteBranch(t *testing.T) {
 	t.Parallel()
 
@@ -384,3 +732,1545 @@ printf 'done\n'
 		t.Fatalf("unexpected git args: got %v, want %v", args, want)
 	}
 }
+
+func TestCLIPassesConfigOverrides(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script test is not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "git_args")
+
+	script := `#!/bin/sh
+printf '%s\n' "$@" > "$BN_ARGS_PATH"
+`
+	path := filepath.Join(dir, "git")
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		t.Fatalf("failed to create mock git: %v", err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("BN_ARGS_PATH", argsFile)
+
+	cli := &CLI{Config: []string{"merge.ff=only", "advice.detachedHead=false"}}
+	cases := map[string]struct {
+		run  func() error
+		want []string
+	}{
+		"captured": {
+			run: func() error {
+				_, _, err := cli.RunWithCombinedOutput(context.Background(), "merge", "main")
+				return err
+			},
+			want: []string{"-c", "color.ui=always", "-c", "merge.ff=only", "-c", "advice.detachedHead=false", "merge", "main"},
+		},
+		"interactive": {
+			run:  func() error { return cli.RunInteractive(context.Background(), "rebase", "-i", "main") },
+			want: []string{"-c", "merge.ff=only", "-c", "advice.detachedHead=false", "rebase", "-i", "main"},
+		},
+	}
+
+	for name, tc := range cases {
+		if err := tc.run(); err != nil {
+			t.Fatalf("%s: run returned error: %v", name, err)
+		}
+		data, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatalf("%s: failed to read args file: %v", name, err)
+		}
+		args := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if !reflect.DeepEqual(args, tc.want) {
+			t.Fatalf("%s: unexpected git args: got %v, want %v", name, args, tc.want)
+		}
+	}
+}
+
+func exitError(t *testing.T, code int) error {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell exit codes are not supported on Windows")
+	}
+	err := exec.Command("sh", "-c", fmt.Sprintf("exit %d", code)).Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected exit error, got %v", err)
+	}
+	return err
+}
+
+func TestClientBaseBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	notFound := exitError(t, 1)
+	unset := exitError(t, 1)
+
+	cases := map[string]struct {
+		override string
+		calls    []scriptCall
+		want     string
+		wantErr  error
+	}{
+		"origin-head": {
+			calls: []scriptCall{
+				{args: []string{"symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"}, stdout: "origin/develop"},
+			},
+			want: "develop",
+		},
+		"fallback-main": {
+			calls: []scriptCall{
+				{args: []string{"symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"}, err: unset},
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/main"}},
+			},
+			want: "main",
+		},
+		"fallback-master": {
+			calls: []scriptCall{
+				{args: []string{"symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"}, err: unset},
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/main"}, err: notFound},
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/master"}},
+			},
+			want: "master",
+		},
+		"not-found": {
+			calls: []scriptCall{
+				{args: []string{"symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"}, err: unset},
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/main"}, err: notFound},
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/master"}, err: notFound},
+			},
+			wantErr: ErrBaseBranchNotFound,
+		},
+		"override": {
+			override: "develop",
+			calls: []scriptCall{
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/develop"}},
+			},
+			want: "develop",
+		},
+		"override-missing": {
+			override: "develop",
+			calls: []scriptCall{
+				{args: []string{"show-ref", "--verify", "--quiet", "refs/heads/develop"}, err: notFound},
+			},
+			wantErr: ErrBaseBranchNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: tc.calls}
+			client := NewClient(runner)
+
+			got, err := client.BaseBranch(ctx, tc.override)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("BaseBranch(%q) = %q, want %q", tc.override, got, tc.want)
+			}
+			if !runner.Exhausted() {
+				t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+			}
+		})
+	}
+}
+
+func TestClientArchiveBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tagErr := errors.New("tag already exists")
+
+	cases := map[string]struct {
+		branch  string
+		calls   []scriptCall
+		wantTag string
+		wantErr error
+	}{
+		"success": {
+			branch: "feature/old",
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+				{args: []string{"tag", "archive/feature/old", "refs/heads/feature/old"}},
+				{args: []string{"branch", "-D", "feature/old"}, stdout: "Deleted branch feature/old (was abc1234)."},
+			},
+			wantTag: "archive/feature/old",
+		},
+		"tag-exists": {
+			branch: "feature/old",
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+				{args: []string{"tag", "archive/feature/old", "refs/heads/feature/old"}, err: tagErr},
+			},
+			wantErr: tagErr,
+		},
+		"current-branch": {
+			branch: "main",
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+			},
+			wantErr: errors.New("cannot archive the current branch 'main'"),
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: tc.calls}
+			client := NewClient(runner)
+
+			tag, err := client.ArchiveBranch(ctx, tc.branch)
+			if tc.wantErr != nil {
+				if err == nil || (!errors.Is(err, tc.wantErr) && err.Error() != tc.wantErr.Error()) {
+					t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tag != tc.wantTag {
+				t.Fatalf("unexpected tag: got %q, want %q", tag, tc.wantTag)
+			}
+			if !runner.Exhausted() {
+				t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+			}
+		})
+	}
+}
+
+func TestClientArchivedBranches(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"for-each-ref", "--format=%(refname:short)", "--sort=-creatordate", "refs/tags/archive/"},
+			stdout: "archive/feature/b\narchive/feature/a\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.ArchivedBranches(context.Background())
+	if err != nil {
+		t.Fatalf("ArchivedBranches returned error: %v", err)
+	}
+	want := []string{"feature/b", "feature/a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ArchivedBranches() = %v, want %v", got, want)
+	}
+}
+
+func TestClientLocalBranches(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"branch", "--list", "--format=%(refname:lstrip=2)"},
+			stdout: "feature/a\nmain\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.LocalBranches(context.Background())
+	if err != nil {
+		t.Fatalf("LocalBranches returned error: %v", err)
+	}
+	want := []string{"feature/a", "main"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("LocalBranches() = %v, want %v", got, want)
+	}
+}
+
+func TestClientConfigValue(t *testing.T) {
+	t.Parallel()
+
+	unset := exitError(t, 1)
+	broken := exitError(t, 3)
+
+	cases := map[string]struct {
+		call    scriptCall
+		want    string
+		wantOK  bool
+		wantErr bool
+	}{
+		"set":    {call: scriptCall{args: []string{"config", "--global", "--get", "alias.nav"}, stdout: "!branch-navigator"}, want: "!branch-navigator", wantOK: true},
+		"unset":  {call: scriptCall{args: []string{"config", "--global", "--get", "alias.nav"}, err: unset}},
+		"broken": {call: scriptCall{args: []string{"config", "--global", "--get", "alias.nav"}, err: broken}, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{tc.call}}
+			got, ok, err := NewClient(runner).ConfigValue(context.Background(), ConfigGlobal, "alias.nav")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ConfigValue error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want || ok != tc.wantOK {
+				t.Fatalf("ConfigValue() = %q, %v, want %q, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestClientSetConfigValue(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"config", "--local", "alias.nav", "!branch-navigator -m"}},
+	}}
+	if err := NewClient(runner).SetConfigValue(context.Background(), ConfigLocal, "alias.nav", "!branch-navigator -m"); err != nil {
+		t.Fatalf("SetConfigValue returned error: %v", err)
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+}
+
+func TestClientConfigValues(t *testing.T) {
+	t.Parallel()
+
+	args := []string{"config", "--local", "--get-regexp", `^branch\..*\.navigatorlabels$`}
+	cases := map[string]struct {
+		call    scriptCall
+		want    map[string]string
+		wantErr bool
+	}{
+		"set": {
+			call: scriptCall{args: args, stdout: "branch.feature/X.navigatorlabels waiting on review, demo\nbranch.v1.2.navigatorlabels\n"},
+			want: map[string]string{"branch.feature/X.navigatorlabels": "waiting on review, demo", "branch.v1.2.navigatorlabels": ""},
+		},
+		"none":   {call: scriptCall{args: args, err: exitError(t, 1)}, want: map[string]string{}},
+		"broken": {call: scriptCall{args: args, err: exitError(t, 3)}, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{tc.call}}
+			got, err := NewClient(runner).ConfigValues(context.Background(), ConfigLocal, `^branch\..*\.navigatorlabels$`)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ConfigValues error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ConfigValues() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientUnsetConfigValue(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err     error
+		wantErr bool
+	}{
+		"set":     {},
+		"not set": {err: exitError(t, 5)},
+		"broken":  {err: exitError(t, 3), wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: []string{"config", "--local", "--unset-all", "branch.main.navigatorLabels"}, err: tc.err},
+			}}
+			err := NewClient(runner).UnsetConfigValue(context.Background(), ConfigLocal, "branch.main.navigatorLabels")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("UnsetConfigValue error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientUnarchiveBranch(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"branch", "feature/old", "refs/tags/archive/feature/old"}},
+		{args: []string{"tag", "-d", "archive/feature/old"}, stdout: "Deleted tag 'archive/feature/old' (was abc1234)"},
+	}}
+	client := NewClient(runner)
+
+	if err := client.UnarchiveBranch(context.Background(), "feature/old"); err != nil {
+		t.Fatalf("UnarchiveBranch returned error: %v", err)
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+}
+
+func TestClientBackupBranch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"rev-parse", "--verify", "refs/heads/feature/x^{commit}"}, stdout: "abc1234"},
+		{args: []string{"update-ref", "refs/branch-navigator/backup/feature/x@1700000000", "abc1234"}},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.BackupBranch(context.Background(), "feature/x", now)
+	if err != nil {
+		t.Fatalf("BackupBranch returned error: %v", err)
+	}
+	want := Backup{
+		Branch:  "feature/x",
+		Ref:     "refs/branch-navigator/backup/feature/x@1700000000",
+		SHA:     "abc1234",
+		Created: now,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("BackupBranch() = %+v, want %+v", got, want)
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+}
+
+func TestClientDeleteBackup(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"update-ref", "-d", "refs/branch-navigator/backup/feature/x@1700000000"}},
+	}}
+	client := NewClient(runner)
+
+	if err := client.DeleteBackup(context.Background(), Backup{Ref: "refs/branch-navigator/backup/feature/x@1700000000"}); err != nil {
+		t.Fatalf("DeleteBackup returned error: %v", err)
+	}
+	if err := client.DeleteBackup(context.Background(), Backup{Ref: "refs/heads/feature/x"}); err == nil {
+		t.Fatal("expected an error for a ref outside the backups")
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+}
+
+func TestClientPruneBackups(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"for-each-ref", "--format=%(refname)%09%(objectname)", "refs/branch-navigator/backup/"},
+			stdout: strings.Join([]string{
+				"refs/branch-navigator/backup/old@1000\taaa",
+				"refs/branch-navigator/backup/feature/new@3000\tbbb",
+				"refs/branch-navigator/backup/malformed\tccc",
+			}, "\n"),
+		},
+		{args: []string{"update-ref", "-d", "refs/branch-navigator/backup/old@1000"}},
+	}}
+	client := NewClient(runner)
+
+	pruned, err := client.PruneBackups(context.Background(), time.Unix(2000, 0))
+	if err != nil {
+		t.Fatalf("PruneBackups returned error: %v", err)
+	}
+	want := []Backup{{Branch: "old", Ref: "refs/branch-navigator/backup/old@1000", SHA: "aaa", Created: time.Unix(1000, 0)}}
+	if !reflect.DeepEqual(pruned, want) {
+		t.Fatalf("PruneBackups() = %+v, want %+v", pruned, want)
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+}
+
+type plainRunner struct{}
+
+func (plainRunner) Run(ctx context.Context, args ...string) (string, error) {
+	return "", nil
+}
+
+func TestClientRebaseInteractive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"rebase", "-i", "main"}},
+	}}
+	if err := NewClient(runner).RebaseInteractive(ctx, "main"); err != nil {
+		t.Fatalf("RebaseInteractive returned error: %v", err)
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+
+	if err := NewClient(plainRunner{}).RebaseInteractive(ctx, "main"); err == nil {
+		t.Fatal("expected error when the runner cannot run interactively")
+	}
+}
+
+func TestClientPull(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{{args: []string{"pull"}}}}
+	if err := NewClient(runner).Pull(ctx); err != nil {
+		t.Fatalf("Pull returned error: %v", err)
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+
+	if err := NewClient(plainRunner{}).Pull(ctx); err == nil {
+		t.Fatal("expected error when the runner cannot run interactively")
+	}
+}
+
+func TestParseRemotes(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		"origin\tgit@github.com:org/repo.git (fetch)",
+		"origin\tgit@github.com:org/repo-push.git (push)",
+		"upstream\thttps://example.com/repo.git (push)",
+		"upstream\thttps://example.com/repo-fetch.git (fetch)",
+	}, "\n")
+
+	got := parseRemotes(input)
+	want := []Remote{
+		{Name: "origin", URL: "git@github.com:org/repo.git"},
+		{Name: "upstream", URL: "https://example.com/repo-fetch.git"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseRemotes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientRemoteCommands(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"fetch", "origin"}, stderr: "From github.com:org/repo"},
+		{args: []string{"remote", "prune", "origin"}, stdout: "Pruning origin"},
+		{args: []string{"remote", "set-url", "origin", "git@example.com:new.git"}},
+	}}
+	client := NewClient(runner)
+
+	fetched, err := client.FetchRemote(ctx, "origin")
+	if err != nil || fetched.Stderr != "From github.com:org/repo" {
+		t.Fatalf("FetchRemote() = %+v, %v", fetched, err)
+	}
+	pruned, err := client.PruneRemote(ctx, "origin")
+	if err != nil || pruned.Stdout != "Pruning origin" {
+		t.Fatalf("PruneRemote() = %+v, %v", pruned, err)
+	}
+	if _, err := client.SetRemoteURL(ctx, "origin", "git@example.com:new.git"); err != nil {
+		t.Fatalf("SetRemoteURL returned error: %v", err)
+	}
+	if _, err := client.SetRemoteURL(ctx, "origin", " "); err == nil {
+		t.Fatal("expected error for empty URL")
+	}
+	if !runner.Exhausted() {
+		t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+	}
+}
+
+func TestClientBranchUpstream(t *testing.T) {
+	t.Parallel()
+
+	format := "--format=%(upstream:remotename)%09%(upstream:remoteref)"
+	cases := map[string]struct {
+		stdout string
+		want   Upstream
+	}{
+		"tracking": {stdout: "origin\trefs/heads/feature/x", want: Upstream{Remote: "origin", Branch: "feature/x"}},
+		"none":     {stdout: "\t", want: Upstream{}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: []string{"for-each-ref", format, "refs/heads/feature/x"}, stdout: tc.stdout},
+			}}
+			got, err := NewClient(runner).BranchUpstream(context.Background(), "feature/x")
+			if err != nil {
+				t.Fatalf("BranchUpstream returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("BranchUpstream() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientPushBranch(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		opts PushOptions
+		args []string
+	}{
+		"default-remote": {
+			args: []string{"push", "origin", "feature/x"},
+		},
+		"set-upstream": {
+			opts: PushOptions{SetUpstream: true},
+			args: []string{"push", "--set-upstream", "origin", "feature/x"},
+		},
+		"explicit-remote": {
+			opts: PushOptions{Remote: "fork"},
+			args: []string{"push", "fork", "feature/x"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: tc.args, stderr: "To example.com:repo.git"},
+			}}
+			result, err := NewClient(runner).PushBranch(context.Background(), "feature/x", tc.opts)
+			if err != nil {
+				t.Fatalf("PushBranch returned error: %v", err)
+			}
+			if result.Stderr != "To example.com:repo.git" {
+				t.Fatalf("unexpected stderr: %q", result.Stderr)
+			}
+			if !runner.Exhausted() {
+				t.Fatalf("not all git calls were consumed: %d of %d", runner.index, len(runner.calls))
+			}
+		})
+	}
+}
+
+func TestUpstreamString(t *testing.T) {
+	t.Parallel()
+
+	if got := (Upstream{Remote: "origin", Branch: "main"}).String(); got != "origin/main" {
+		t.Fatalf("Upstream.String() = %q, want origin/main", got)
+	}
+	if got := (Upstream{}).String(); got != "" {
+		t.Fatalf("Upstream.String() = %q, want empty", got)
+	}
+}
+
+func TestClientRepoRoot(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"rev-parse", "--show-toplevel"}, stdout: "/src/repo\n"},
+	}}
+	got, err := NewClient(runner).RepoRoot(context.Background())
+	if err != nil {
+		t.Fatalf("RepoRoot returned error: %v", err)
+	}
+	if got != "/src/repo" {
+		t.Fatalf("RepoRoot() = %q, want /src/repo", got)
+	}
+}
+
+func TestCLIRunsInDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script test is not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\npwd\n"
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0o700); err != nil {
+		t.Fatalf("failed to create mock git: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	workdir := t.TempDir()
+	out, err := (&CLI{Dir: workdir}).Run(context.Background(), "status")
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want, err := filepath.EvalSymlinks(workdir)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(out); got != want {
+		t.Fatalf("git ran in %q, want %q", out, want)
+	}
+}
+
+func TestCLILogsCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script test is not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$3\" = merge ]; then echo 'fatal: refusing' >&2; exit 128; fi\n"
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0o700); err != nil {
+		t.Fatalf("failed to create mock git: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var logs bytes.Buffer
+	cli := &CLI{Log: slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+	if _, err := cli.Run(context.Background(), "status"); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if _, err := cli.Run(context.Background(), "merge", "main"); err == nil {
+		t.Fatal("expected merge to fail")
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a record per command, got %q", logs.String())
+	}
+	want := []map[string]any{
+		{"level": "DEBUG", "msg": "git", "args": []any{"status"}},
+		{"level": "WARN", "msg": "git", "args": []any{"merge", "main"}, "stderr": "fatal: refusing"},
+	}
+	for i, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("record %d is not JSON: %v", i, err)
+		}
+		if _, ok := record["duration"]; !ok {
+			t.Fatalf("record %d has no duration: %v", i, record)
+		}
+		for key, value := range want[i] {
+			if !reflect.DeepEqual(record[key], value) {
+				t.Fatalf("record %d %s = %v, want %v", i, key, record[key], value)
+			}
+		}
+	}
+}
+
+func TestCLIObservesCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script test is not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$3\" = merge ]; then exit 1; fi\necho \"  out  \"\n"
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0o700); err != nil {
+		t.Fatalf("failed to create mock git: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	type observed struct {
+		args   []string
+		stdout string
+		failed bool
+	}
+	var got []observed
+	cli := &CLI{Observe: func(args []string, stdout string, err error) {
+		got = append(got, observed{args: args, stdout: stdout, failed: err != nil})
+	}}
+	if _, err := cli.Run(context.Background(), "status"); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if _, err := cli.Run(context.Background(), "merge", "main"); err == nil {
+		t.Fatal("expected merge to fail")
+	}
+
+	want := []observed{
+		{args: []string{"status"}, stdout: "out"},
+		{args: []string{"merge", "main"}, failed: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("observed %+v, want %+v", got, want)
+	}
+}
+
+func TestClientBranchRefs(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"for-each-ref", "--sort=-committerdate", "--format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail)", "refs/heads", "refs/remotes"},
+			stdout: "refs/heads/feature/x\t200\t\tAda Lovelace <ada@example.com>\n" +
+				"refs/remotes/origin/HEAD\t200\trefs/remotes/origin/main\tAda Lovelace <ada@example.com>\n" +
+				"refs/remotes/origin/feature/y\t150\t\tGrace Hopper <grace@example.com>\n" +
+				"refs/heads/main\t100\t\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.BranchRefs(context.Background())
+	if err != nil {
+		t.Fatalf("BranchRefs returned error: %v", err)
+	}
+	want := []BranchRef{
+		{Name: "feature/x", CommitDate: time.Unix(200, 0), Author: "Ada Lovelace <ada@example.com>"},
+		{Name: "origin/feature/y", Remote: true, CommitDate: time.Unix(150, 0), Author: "Grace Hopper <grace@example.com>"},
+		{Name: "main", CommitDate: time.Unix(100, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("BranchRefs() = %+v, want %+v", got, want)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientMergedBranches(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"for-each-ref", "--merged=main", "--format=%(refname)", "refs/heads", "refs/remotes"},
+			stdout: "refs/heads/main\nrefs/heads/old\nrefs/remotes/origin/old\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.MergedBranches(context.Background(), " main ")
+	if err != nil {
+		t.Fatalf("MergedBranches returned error: %v", err)
+	}
+	want := []string{"main", "old", "origin/old"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MergedBranches() = %v, want %v", got, want)
+	}
+
+	if _, err := client.MergedBranches(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty base")
+	}
+}
+
+func TestClientEmptyBranches(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"for-each-ref", "--points-at=main", "--format=%(refname)", "refs/heads"},
+			stdout: "refs/heads/main\nrefs/heads/spike\nrefs/heads/feature/later\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.EmptyBranches(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("EmptyBranches returned error: %v", err)
+	}
+	if want := []string{"spike", "feature/later"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("EmptyBranches() = %v, want %v", got, want)
+	}
+
+	if _, err := client.EmptyBranches(context.Background(), " "); err == nil {
+		t.Fatal("expected error for empty base")
+	}
+}
+
+func TestClientBranchesContaining(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"for-each-ref", "--contains=abc1234", "--format=%(refname)", "refs/heads", "refs/remotes"},
+			stdout: "refs/heads/feature/x\nrefs/remotes/origin/main\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.BranchesContaining(context.Background(), "abc1234")
+	if err != nil {
+		t.Fatalf("BranchesContaining returned error: %v", err)
+	}
+	want := []string{"feature/x", "origin/main"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("BranchesContaining() = %v, want %v", got, want)
+	}
+
+	if _, err := client.BranchesContaining(context.Background(), " "); err == nil {
+		t.Fatal("expected error for an empty commit")
+	}
+}
+
+func TestClientSubmodules(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"submodule", "status", "--recursive"},
+			stdout: " 1111111111111111111111111111111111111111 lib/core (heads/main)\n" +
+				"+2222222222222222222222222222222222222222 lib/ui (v1.2.0-3-g2222222)\n" +
+				"-3333333333333333333333333333333333333333 vendor/old\n" +
+				"U4444444444444444444444444444444444444444 lib/merge\n" +
+				" 5555555555555555555555555555555555555555 lib/core/deps/z lib (heads/dev)\n",
+		},
+	}}
+
+	got, err := NewClient(runner).Submodules(context.Background())
+	if err != nil {
+		t.Fatalf("Submodules returned error: %v", err)
+	}
+	want := []Submodule{
+		{Path: "lib/core", Commit: "1111111111111111111111111111111111111111", Describe: "heads/main", Initialized: true},
+		{Path: "lib/ui", Commit: "2222222222222222222222222222222222222222", Describe: "v1.2.0-3-g2222222", Initialized: true, Modified: true},
+		{Path: "vendor/old", Commit: "3333333333333333333333333333333333333333"},
+		{Path: "lib/merge", Commit: "4444444444444444444444444444444444444444", Initialized: true, Conflicted: true},
+		{Path: "lib/core/deps/z lib", Commit: "5555555555555555555555555555555555555555", Describe: "heads/dev", Initialized: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Submodules() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	hub := t.TempDir()
+	if err := os.WriteFile(filepath.Join(hub, ".git"), []byte("gitdir: ./.bare\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .git file: %v", err)
+	}
+	plain := t.TempDir()
+
+	cases := map[string]struct {
+		stdout string
+		want   RepoInfo
+	}{
+		"work tree": {
+			stdout: "false\n/src/app/.git\n",
+			want:   RepoInfo{Kind: RepoWorkTree, GitDir: "/src/app/.git"},
+		},
+		"bare": {
+			stdout: "true\n" + filepath.Join(plain, "app.git") + "\n",
+			want:   RepoInfo{Kind: RepoBare, GitDir: filepath.Join(plain, "app.git"), Root: plain},
+		},
+		"worktree hub": {
+			stdout: "true\n" + filepath.Join(hub, ".bare") + "\n",
+			want:   RepoInfo{Kind: RepoWorktreeHub, GitDir: filepath.Join(hub, ".bare"), Root: hub},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: []string{"rev-parse", "--is-bare-repository", "--absolute-git-dir"}, stdout: tc.stdout},
+			}}
+			got, err := NewClient(runner).RepoInfo(context.Background())
+			if err != nil {
+				t.Fatalf("RepoInfo returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("RepoInfo() = %+v, want %+v", got, tc.want)
+			}
+			if got.Bare() != (tc.want.Kind != RepoWorkTree) {
+				t.Fatalf("Bare() = %v for %+v", got.Bare(), got)
+			}
+		})
+	}
+}
+
+func TestRepoInfoCommonDir(t *testing.T) {
+	t.Parallel()
+
+	common := t.TempDir()
+	worktree := filepath.Join(common, "worktrees", "feature")
+	if err := os.MkdirAll(worktree, 0o755); err != nil {
+		t.Fatalf("failed to create worktree dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "commondir"), []byte("../..\n"), 0o644); err != nil {
+		t.Fatalf("failed to write commondir: %v", err)
+	}
+
+	if got := (RepoInfo{GitDir: common}).CommonDir(); got != common {
+		t.Fatalf("CommonDir() of the main worktree = %q, want %q", got, common)
+	}
+	if got := (RepoInfo{GitDir: worktree}).CommonDir(); got != common {
+		t.Fatalf("CommonDir() of a linked worktree = %q, want %q", got, common)
+	}
+}
+
+func TestClientRepoRootInBareRepository(t *testing.T) {
+	t.Parallel()
+
+	workTreeErr := errors.New("fatal: this operation must be run in a work tree")
+	cases := map[string]struct {
+		calls   []scriptCall
+		want    string
+		wantErr error
+	}{
+		"bare repository": {
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--show-toplevel"}, err: workTreeErr},
+				{args: []string{"rev-parse", "--is-bare-repository", "--absolute-git-dir"}, stdout: "true\n/srv/app.git\n"},
+			},
+			want: "/srv",
+		},
+		"not a repository": {
+			calls: []scriptCall{
+				{args: []string{"rev-parse", "--show-toplevel"}, err: workTreeErr},
+				{args: []string{"rev-parse", "--is-bare-repository", "--absolute-git-dir"}, err: errors.New("fatal: not a git repository")},
+			},
+			wantErr: workTreeErr,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: tc.calls}
+			got, err := NewClient(runner).RepoRoot(context.Background())
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("RepoRoot error = %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Fatalf("RepoRoot() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientInProgress(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		markers []string
+		want    Operation
+	}{
+		"nothing":                  {want: OperationNone},
+		"merge":                    {markers: []string{"MERGE_HEAD"}, want: OperationMerge},
+		"interactive rebase":       {markers: []string{"rebase-merge/"}, want: OperationRebase},
+		"am or apply rebase":       {markers: []string{"rebase-apply/"}, want: OperationRebase},
+		"rebase stopped on a pick": {markers: []string{"rebase-merge/", "CHERRY_PICK_HEAD"}, want: OperationRebase},
+		"cherry-pick":              {markers: []string{"CHERRY_PICK_HEAD"}, want: OperationCherryPick},
+		"revert":                   {markers: []string{"REVERT_HEAD"}, want: OperationRevert},
+		"bisect":                   {markers: []string{"BISECT_LOG"}, want: OperationBisect},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gitDir := t.TempDir()
+			for _, marker := range tc.markers {
+				path := filepath.Join(gitDir, marker)
+				var err error
+				if strings.HasSuffix(marker, "/") {
+					err = os.Mkdir(path, 0o755)
+				} else {
+					err = os.WriteFile(path, []byte("aaa\n"), 0o644)
+				}
+				if err != nil {
+					t.Fatalf("failed to create %s: %v", marker, err)
+				}
+			}
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: []string{"rev-parse", "--absolute-git-dir"}, stdout: gitDir + "\n"},
+			}}
+			got, err := NewClient(runner).InProgress(context.Background())
+			if err != nil {
+				t.Fatalf("InProgress returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("InProgress() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientWorkTreeStatus(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		stdout string
+		want   WorkTreeStatus
+	}{
+		"clean": {},
+		"changes": {
+			// The first line lost its leading space to trimming.
+			stdout: "M README.md\nM  main.go\nMM go.sum\nA  new.go\nD  old.go\nR  a.go -> b.go\n?? notes/\n?? scratch.txt\n!! bin/\n",
+			want:   WorkTreeStatus{Modified: 6, Untracked: 2},
+		},
+		"conflicts": {
+			stdout: "UU main.go\nAA both.go\nDD gone.go\nAU added.go\nM  other.go\n",
+			want:   WorkTreeStatus{Modified: 1, Conflicted: 4},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{
+				{args: []string{"status", "--porcelain"}, stdout: tc.stdout},
+			}}
+			got, err := NewClient(runner).WorkTreeStatus(context.Background())
+			if err != nil {
+				t.Fatalf("WorkTreeStatus returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("WorkTreeStatus() = %+v, want %+v", got, tc.want)
+			}
+			if got.Clean() != (tc.want == WorkTreeStatus{}) {
+				t.Fatalf("Clean() = %v for %+v", got.Clean(), got)
+			}
+		})
+	}
+}
+
+func TestClientStash(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"stash", "push", "--include-untracked", "-m", "before switching to feature/x"}, stdout: "Saved working directory and index state On main: before switching to feature/x"},
+		{args: []string{"stash", "push", "--include-untracked"}},
+	}}
+	client := NewClient(runner)
+	out, err := client.Stash(context.Background(), "before switching to feature/x")
+	if err != nil || !strings.Contains(out, "Saved working directory") {
+		t.Fatalf("Stash() = %q, %v", out, err)
+	}
+	if _, err := client.Stash(context.Background(), " "); err != nil {
+		t.Fatalf("Stash without message returned error: %v", err)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("not every expected git call was made")
+	}
+}
+
+func TestClientWorktrees(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"worktree", "list", "--porcelain"},
+			stdout: "worktree /src/app/.bare\nbare\n\n" +
+				"worktree /src/app/main\nHEAD aaa\nbranch refs/heads/main\n\n" +
+				"worktree /src/app/review\nHEAD bbb\ndetached\n",
+		},
+	}}
+
+	got, err := NewClient(runner).Worktrees(context.Background())
+	if err != nil {
+		t.Fatalf("Worktrees returned error: %v", err)
+	}
+	want := []Worktree{
+		{Path: "/src/app/.bare", Bare: true},
+		{Path: "/src/app/main", Branch: "main"},
+		{Path: "/src/app/review"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Worktrees() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientAddWorktree(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"worktree", "add", "/src/app/feature-x", "feature/x"}, stdout: "Preparing worktree (checking out 'feature/x')"},
+	}}
+	client := NewClient(runner)
+	out, err := client.AddWorktree(context.Background(), "/src/app/feature-x", "feature/x")
+	if err != nil || out != "Preparing worktree (checking out 'feature/x')" {
+		t.Fatalf("AddWorktree() = %q, %v", out, err)
+	}
+	if _, err := client.AddWorktree(context.Background(), "", "feature/x"); err == nil {
+		t.Fatal("expected error for empty path")
+	}
+	if _, err := client.AddWorktree(context.Background(), "/src/app/x", " "); err == nil {
+		t.Fatal("expected error for empty branch")
+	}
+}
+
+func TestClientRefStates(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"for-each-ref", "--format=%(refname)%09%(objectname)%09%(symref)%09%(upstream:short)", "refs/heads", "refs/remotes"},
+			stdout: "refs/heads/main\taaa\t\torigin/main\n" +
+				"refs/heads/feature/x\tbbb\t\t\n" +
+				"refs/remotes/origin/HEAD\taaa\trefs/remotes/origin/main\t\n" +
+				"refs/remotes/origin/main\taaa\t\t\n",
+		},
+	}}
+
+	got, err := NewClient(runner).RefStates(context.Background())
+	if err != nil {
+		t.Fatalf("RefStates returned error: %v", err)
+	}
+	want := []RefState{
+		{Name: "main", Hash: "aaa", Upstream: "origin/main"},
+		{Name: "feature/x", Hash: "bbb"},
+		{Name: "origin/main", Hash: "aaa"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RefStates() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientBranchSubjects(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"for-each-ref", "--format=%(refname)%09%(symref)%09%(contents:subject)", "refs/heads", "refs/remotes"},
+			stdout: "refs/heads/main\t\tMerge pull request #12\n" +
+				"refs/heads/feature/x\t\tfix:\tlogin\n" +
+				"refs/heads/empty\t\t\n" +
+				"refs/remotes/origin/HEAD\trefs/remotes/origin/main\tMerge pull request #12\n" +
+				"refs/remotes/origin/topic\t\tadd topic\n",
+		},
+	}}
+
+	got, err := NewClient(runner).BranchSubjects(context.Background())
+	if err != nil {
+		t.Fatalf("BranchSubjects returned error: %v", err)
+	}
+	want := map[string]string{"main": "Merge pull request #12", "feature/x": "fix:\tlogin", "origin/topic": "add topic"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("BranchSubjects() = %v, want %v", got, want)
+	}
+}
+
+func TestClientCreateBranch(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"checkout", "-b", "feature/abc-1-login", "origin/main"},
+			stdout: "branch 'feature/abc-1-login' set up to track 'origin/main'.",
+		},
+	}}
+	client := NewClient(runner)
+
+	out, err := client.CreateBranch(context.Background(), " feature/abc-1-login ", "origin/main")
+	if err != nil {
+		t.Fatalf("CreateBranch returned error: %v", err)
+	}
+	if !strings.Contains(out, "set up to track") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if _, err := client.CreateBranch(context.Background(), "", "main"); err == nil {
+		t.Fatal("expected error for an empty branch name")
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientCreateBranchAt(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"branch", "experiment/cache", "feature/x"}},
+	}}
+	client := NewClient(runner)
+
+	if _, err := client.CreateBranchAt(context.Background(), "experiment/cache", "feature/x"); err != nil {
+		t.Fatalf("CreateBranchAt returned error: %v", err)
+	}
+	if _, err := client.CreateBranchAt(context.Background(), "experiment/cache", " "); err == nil {
+		t.Fatal("expected error for an empty start point")
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientResetImpact(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"log", "--no-color", "--format=%h %s", "origin/main..HEAD"}, stdout: "abc1234 Add cache\ndef5678 Start cache\n"},
+		{args: []string{"status", "--porcelain", "--untracked-files=no"}, stdout: " M README.md\n"},
+	}}
+	got, err := NewClient(runner).ResetImpact(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatalf("ResetImpact returned error: %v", err)
+	}
+	want := ResetImpact{Commits: []string{"abc1234 Add cache", "def5678 Start cache"}, Changes: []string{"M README.md"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ResetImpact() = %+v, want %+v", got, want)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientReset(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"reset", "--hard", "origin/main"}, stdout: "HEAD is now at abc1234 Add cache"},
+	}}
+	client := NewClient(runner)
+
+	out, err := client.Reset(context.Background(), "origin/main", ResetHard)
+	if err != nil {
+		t.Fatalf("Reset returned error: %v", err)
+	}
+	if out != "HEAD is now at abc1234 Add cache" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if _, err := client.Reset(context.Background(), "origin/main", ResetMode("keep")); err == nil {
+		t.Fatal("expected error for an unknown mode")
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientCheckoutRemoteBranch(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"checkout", "--track", "origin/feature/y"},
+			stdout: "branch 'feature/y' set up to track 'origin/feature/y'.",
+		},
+	}}
+	client := NewClient(runner)
+
+	out, err := client.CheckoutRemoteBranch(context.Background(), "origin/feature/y")
+	if err != nil {
+		t.Fatalf("CheckoutRemoteBranch returned error: %v", err)
+	}
+	if !strings.Contains(out, "set up to track") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientCheckoutMerge(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		ref    string
+		remote bool
+		args   []string
+	}{
+		"local":  {ref: "feature/x", args: []string{"checkout", "-m", "feature/x"}},
+		"remote": {ref: "origin/feature/y", remote: true, args: []string{"checkout", "-m", "--track", "origin/feature/y"}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{{args: tc.args, stdout: "M\tmain.go"}}}
+			out, err := NewClient(runner).CheckoutMerge(context.Background(), tc.ref, tc.remote)
+			if err != nil {
+				t.Fatalf("CheckoutMerge returned error: %v", err)
+			}
+			if out != "M\tmain.go" {
+				t.Fatalf("unexpected output %q", out)
+			}
+			if !runner.Exhausted() {
+				t.Fatal("expected all scripted calls to be consumed")
+			}
+		})
+	}
+}
+
+func TestClientCheckoutDetached(t *testing.T) {
+	t.Parallel()
+
+	version := scriptCall{args: []string{"--version"}, stdout: "git version 2.43.0"}
+	cases := map[string]struct {
+		useSwitch bool
+		merge     bool
+		calls     []scriptCall
+		wantErr   error
+	}{
+		"checkout": {calls: []scriptCall{{args: []string{"checkout", "--detach", "abc1234"}}}},
+		"switch":   {useSwitch: true, calls: []scriptCall{version, {args: []string{"switch", "--detach", "abc1234"}}}},
+		"merge":    {useSwitch: true, merge: true, calls: []scriptCall{version, {args: []string{"switch", "--detach", "-m", "abc1234"}}}},
+		"local-changes": {
+			calls: []scriptCall{
+				{args: []string{"checkout", "--detach", "abc1234"}, err: errors.New("git checkout --detach abc1234: exit status 1: error: Your local changes to the following files would be overwritten by checkout:\n\tmain.go")},
+			},
+			wantErr: ErrLocalChangesOverwritten,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: tc.calls}
+			client := NewClient(runner)
+			client.SetSwitch(tc.useSwitch)
+			if _, err := client.CheckoutDetached(context.Background(), "abc1234", tc.merge); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("CheckoutDetached() error = %v, want %v", err, tc.wantErr)
+			}
+			if !runner.Exhausted() {
+				t.Fatal("expected all scripted calls to be consumed")
+			}
+		})
+	}
+}
+
+func TestClientHeadHistory(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"reflog", "-n", "3", "--date=unix", "--format=%H%x09%h%x09%gd%x09%gs"},
+			stdout: "aaaa1111\taaaa111\tHEAD@{300}\treset: moving to HEAD~1\n" +
+				"bbbb2222\tbbbb222\tHEAD@{200}\tcheckout: moving from main to bbbb2222\n" +
+				"cccc3333\tcccc333\tHEAD@{100}\tcommit: Add parser\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.HeadHistory(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("HeadHistory returned error: %v", err)
+	}
+	want := []HeadEntry{
+		{Selector: "HEAD@{0}", Hash: "aaaa1111", Short: "aaaa111", Subject: "reset: moving to HEAD~1", Time: time.Unix(300, 0)},
+		{Selector: "HEAD@{1}", Hash: "bbbb2222", Short: "bbbb222", Subject: "checkout: moving from main to bbbb2222", Time: time.Unix(200, 0)},
+		{Selector: "HEAD@{2}", Hash: "cccc3333", Short: "cccc333", Subject: "commit: Add parser", Time: time.Unix(100, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("HeadHistory() = %+v, want %+v", got, want)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected all scripted calls to be consumed")
+	}
+}
+
+func TestClientBranchStatuses(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args: []string{"for-each-ref", "--sort=-committerdate", "--format=%(refname:lstrip=2)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track)", "refs/heads"},
+			stdout: "feature/x\t300\torigin/feature/x\t[ahead 2, behind 1]\n" +
+				"main\t200\torigin/main\t\n" +
+				"old\t150\torigin/old\t[gone]\n" +
+				"local\t100\t\t\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	got, err := client.BranchStatuses(context.Background())
+	if err != nil {
+		t.Fatalf("BranchStatuses returned error: %v", err)
+	}
+	want := []BranchStatus{
+		{Name: "feature/x", CommitDate: time.Unix(300, 0), Upstream: "origin/feature/x", Ahead: 2, Behind: 1},
+		{Name: "main", CommitDate: time.Unix(200, 0), Upstream: "origin/main"},
+		{Name: "old", CommitDate: time.Unix(150, 0), Upstream: "origin/old", Gone: true},
+		{Name: "local", CommitDate: time.Unix(100, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("BranchStatuses() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTrack(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		ahead, behind int
+		gone          bool
+	}{
+		"":                    {},
+		"[ahead 3]":           {ahead: 3},
+		"[behind 4]":          {behind: 4},
+		"[ahead 1, behind 2]": {ahead: 1, behind: 2},
+		"[gone]":              {gone: true},
+	}
+	for track, want := range cases {
+		ahead, behind, gone := parseTrack(track)
+		if ahead != want.ahead || behind != want.behind || gone != want.gone {
+			t.Errorf("parseTrack(%q) = %d, %d, %v; want %d, %d, %v", track, ahead, behind, gone, want.ahead, want.behind, want.gone)
+		}
+	}
+}
+
+func TestClientVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		stdout  string
+		want    string
+		wantErr bool
+	}{
+		"linux":   {stdout: "git version 2.43.0\n", want: "2.43.0"},
+		"macOS":   {stdout: "git version 2.39.3 (Apple Git-146)\n", want: "2.39.3"},
+		"windows": {stdout: "git version 2.45.1.windows.1\n", want: "2.45.1.windows.1"},
+		"garbage": {stdout: "hello\n", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &scriptRunner{testingT: t, calls: []scriptCall{{args: []string{"--version"}, stdout: tc.stdout}}}
+			got, err := NewClient(runner).Version(context.Background())
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Version returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Version() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"2.43.0", "2.16", 1},
+		{"2.16", "2.16.0", 0},
+		{"2.9.5", "2.16", -1},
+		{"2.45.1.windows.1", "2.45.1", 0},
+		{"3.0", "2.99", 1},
+	}
+	for _, tc := range cases {
+		if got := CompareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
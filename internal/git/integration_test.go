@@ -0,0 +1,298 @@
+package git
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"branch-navigator/pkg/gittest"
+)
+
+// realClient returns a Client that runs git on repo with its environment.
+func realClient(repo *gittest.Repo) *Client {
+	return NewClient(&CLI{Dir: repo.Dir, Env: repo.Env()})
+}
+
+func TestIntegrationReflogVisits(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/a", "")
+	repo.Branch("feature/b", "")
+	repo.Visit("feature/a", "feature/b", gittest.DefaultBranch, "feature/a")
+
+	visits, err := realClient(repo).ReflogVisits(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("ReflogVisits returned error: %v", err)
+	}
+	got := make([]string, len(visits))
+	for i, visit := range visits {
+		got[i] = visit.Branch
+		if visit.Time.IsZero() {
+			t.Fatalf("visit of %s has no time", visit.Branch)
+		}
+	}
+	want := []string{"feature/a", gittest.DefaultBranch, "feature/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReflogVisits() branches = %v, want %v", got, want)
+	}
+}
+
+func TestIntegrationReflogVisitsBeyondCheckouts(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("topic")
+	repo.Commit("work on topic")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.Commit("work on main")
+	// Rebasing another branch checks it out without a checkout entry.
+	repo.Git("rebase", gittest.DefaultBranch, "topic")
+	repo.Git("branch", "-m", "topic", "renamed")
+	repo.Git("reset", "--hard", gittest.DefaultBranch)
+
+	visits, err := realClient(repo).ReflogVisits(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("ReflogVisits returned error: %v", err)
+	}
+	got := make([]string, len(visits))
+	for i, visit := range visits {
+		got[i] = visit.Branch
+	}
+	want := []string{gittest.DefaultBranch, "renamed", "topic"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReflogVisits() branches = %v, want %v", got, want)
+	}
+}
+
+func TestIntegrationBranchRefsWithRemote(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("feature/a")
+	repo.Commit("work on a")
+	repo.AddRemote("origin")
+	repo.Push("origin", "feature/a")
+
+	refs, err := realClient(repo).BranchRefs(context.Background())
+	if err != nil {
+		t.Fatalf("BranchRefs returned error: %v", err)
+	}
+	got := map[string]bool{}
+	for _, ref := range refs {
+		got[ref.Name] = ref.Remote
+		if ref.Author != gittest.Author {
+			t.Fatalf("author of %s = %q, want %q", ref.Name, ref.Author, gittest.Author)
+		}
+	}
+	want := map[string]bool{"feature/a": false, "origin/feature/a": true, gittest.DefaultBranch: false}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("BranchRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestIntegrationCurrentBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.Branch("feature/a", "")
+	worktree := repo.AddWorktree("feature/a")
+	repo.Detach(gittest.DefaultBranch)
+
+	cases := map[string]struct {
+		dir  string
+		want string
+	}{
+		"detached HEAD": {dir: repo.Dir, want: "HEAD"},
+		"worktree":      {dir: worktree, want: "feature/a"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient(&CLI{Dir: tc.dir, Env: repo.Env()})
+			got, err := client.CurrentBranch(context.Background())
+			if err != nil {
+				t.Fatalf("CurrentBranch returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("CurrentBranch() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIntegrationFullyMerged(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.AddRemote("origin")
+	repo.Branch("merged", "")
+	repo.CheckoutNew("pushed")
+	repo.Commit("pushed work")
+	repo.Push("origin", "pushed")
+	repo.Git("branch", "--set-upstream-to=origin/pushed")
+	repo.CheckoutNew("unmerged")
+	repo.Commit("local work")
+	repo.Checkout(gittest.DefaultBranch)
+
+	client := realClient(repo)
+	for branch, want := range map[string]bool{"merged": true, "pushed": true, "unmerged": false} {
+		got, err := client.FullyMerged(context.Background(), branch)
+		if err != nil {
+			t.Fatalf("FullyMerged(%q) returned error: %v", branch, err)
+		}
+		if got != want {
+			t.Errorf("FullyMerged(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestIntegrationDeleteRemoteBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.AddRemote("origin")
+	repo.Branch("feature/a", "")
+	repo.Push("origin", "feature/a")
+
+	client := realClient(repo)
+	ctx := context.Background()
+	if exists, err := client.RemoteBranchExists(ctx, "origin", "feature/a"); err != nil || !exists {
+		t.Fatalf("RemoteBranchExists before deletion = %v, %v; want true", exists, err)
+	}
+	if _, err := client.DeleteRemoteBranch(ctx, "origin", "feature/a"); err != nil {
+		t.Fatalf("DeleteRemoteBranch returned error: %v", err)
+	}
+	if exists, err := client.RemoteBranchExists(ctx, "origin", "feature/a"); err != nil || exists {
+		t.Fatalf("RemoteBranchExists after deletion = %v, %v; want false", exists, err)
+	}
+}
+
+func TestIntegrationSwitchGuessesDefaultRemote(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("feature/x")
+	repo.Commit("work on x")
+	repo.AddRemote("origin")
+	repo.AddRemote("upstream")
+	repo.Push("origin", "feature/x")
+	repo.Push("upstream", "feature/x")
+	repo.Checkout(gittest.DefaultBranch)
+	repo.Git("branch", "-D", "feature/x")
+	repo.Git("config", "checkout.defaultRemote", "upstream")
+
+	client := realClient(repo)
+	client.SetSwitch(true)
+	if _, err := client.CheckoutBranch(context.Background(), "feature/x"); err != nil {
+		t.Fatalf("CheckoutBranch returned error: %v", err)
+	}
+	if got := repo.Git("rev-parse", "--abbrev-ref", "feature/x@{upstream}"); got != "upstream/feature/x" {
+		t.Fatalf("feature/x tracks %q, want upstream/feature/x", got)
+	}
+}
+
+func TestIntegrationSwitchDetachesAtCommit(t *testing.T) {
+	t.Parallel()
+
+	// git switch refuses a commit unless --detach is given.
+	repo := gittest.New(t)
+	commit := repo.Git("rev-parse", "--short", "HEAD")
+	repo.Commit("second")
+
+	client := realClient(repo)
+	client.SetSwitch(true)
+	if _, err := client.CheckoutDetached(context.Background(), commit, false); err != nil {
+		t.Fatalf("CheckoutDetached returned error: %v", err)
+	}
+	if got := repo.Git("rev-parse", "--short", "HEAD"); got != commit {
+		t.Fatalf("HEAD = %s, want %s", got, commit)
+	}
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != "HEAD" {
+		t.Fatalf("HEAD is on %q, want it detached", got)
+	}
+}
+
+func TestIntegrationRemotesWithBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := gittest.New(t)
+	repo.CheckoutNew("feature/x")
+	repo.Commit("work on x")
+	repo.AddRemote("origin")
+	repo.AddRemote("upstream")
+	repo.AddRemote("fork")
+	repo.Push("upstream", "feature/x")
+	repo.Push("origin", "feature/x")
+	repo.Push("fork", gittest.DefaultBranch)
+	client := realClient(repo)
+	ctx := context.Background()
+
+	remotes, err := client.RemotesWithBranch(ctx, "feature/x")
+	if err != nil {
+		t.Fatalf("RemotesWithBranch returned error: %v", err)
+	}
+	var names []string
+	for _, remote := range remotes {
+		names = append(names, remote.Name)
+	}
+	if want := []string{"origin", "upstream"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("RemotesWithBranch() = %v, want %v", names, want)
+	}
+	if remotes, err := client.RemotesWithBranch(ctx, "x"); err != nil || len(remotes) != 0 {
+		t.Fatalf("RemotesWithBranch(x) = %v, %v; want none", remotes, err)
+	}
+
+	repo.Git("config", "checkout.defaultRemote", "upstream")
+	if value, ok, err := client.ConfigValue(ctx, ConfigMerged, "checkout.defaultRemote"); err != nil || !ok || value != "upstream" {
+		t.Fatalf("ConfigValue(merged) = %q, %v, %v; want upstream", value, ok, err)
+	}
+}
+
+func TestIntegrationLocalBranchesBesideTag(t *testing.T) {
+	t.Parallel()
+
+	// A tag of the same name makes %(refname:short) print heads/release,
+	// which is not a branch name.
+	repo := gittest.New(t)
+	repo.Branch("release", "")
+	repo.Git("tag", "release")
+	client := realClient(repo)
+	ctx := context.Background()
+	want := []string{gittest.DefaultBranch, "release"}
+
+	got, err := client.LocalBranches(ctx)
+	if err != nil {
+		t.Fatalf("LocalBranches returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("LocalBranches() = %v, want %v", got, want)
+	}
+
+	byDate, err := client.BranchesByCommitDate(ctx)
+	if err != nil {
+		t.Fatalf("BranchesByCommitDate returned error: %v", err)
+	}
+	sort.Strings(byDate)
+	if !reflect.DeepEqual(byDate, want) {
+		t.Fatalf("BranchesByCommitDate() = %v, want %v", byDate, want)
+	}
+
+	statuses, err := client.BranchStatuses(ctx)
+	if err != nil {
+		t.Fatalf("BranchStatuses returned error: %v", err)
+	}
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.Name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("BranchStatuses() names = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,93 @@
+package git
+
+import "strings"
+
+// unquoteRefName decodes a ref name that git printed in C-style quotes, as it
+// does for names with unusual bytes when core.quotePath is enabled. Ref names
+// cannot contain backslashes, so a quoted name is recognised by its
+// surrounding double quotes together with at least one escape sequence.
+// Names that are not quoted this way are returned unchanged.
+func unquoteRefName(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' || !strings.Contains(s, `\`) {
+		return s
+	}
+	if decoded, ok := unquoteC(s[1 : len(s)-1]); ok {
+		return decoded
+	}
+	return s
+}
+
+// unquoteC decodes the body of a C-style quoted string as written by git:
+// the escapes \a, \b, \t, \n, \v, \f, \r, \", \\, and three-digit octal
+// bytes. It reports false when the body is malformed.
+func unquoteC(body string) (string, bool) {
+	var b strings.Builder
+	b.Grow(len(body))
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c == '"' {
+			return "", false
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(body) {
+			return "", false
+		}
+		switch esc := body[i]; esc {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(esc)
+		case '0', '1', '2', '3':
+			if i+2 >= len(body) || !isOctal(body[i+1]) || !isOctal(body[i+2]) {
+				return "", false
+			}
+			b.WriteByte((esc-'0')<<6 | (body[i+1]-'0')<<3 | (body[i+2] - '0'))
+			i += 2
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// unquoteSubjectName extracts a branch name from a reflog subject, decoding
+// C-style quotes and dropping a matching pair of plain quotes around it.
+func unquoteSubjectName(s string) string {
+	s = strings.TrimSpace(s)
+	if decoded := unquoteRefName(s); decoded != s {
+		return decoded
+	}
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// splitRefNames splits one-name-per-line git output, decoding quoted names.
+func splitRefNames(s string) []string {
+	names := splitAndFilter(s)
+	for i, name := range names {
+		names[i] = unquoteRefName(name)
+	}
+	return names
+}
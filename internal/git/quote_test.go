@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestUnquoteRefName(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  string
+	}{
+		"plain":              {input: "feature/login", want: "feature/login"},
+		"utf8":               {input: "feature/café", want: "feature/café"},
+		"emoji":              {input: "fix/🐛-crash", want: "fix/🐛-crash"},
+		"octal-utf8":         {input: `"feature/caf\303\251"`, want: "feature/café"},
+		"octal-emoji":        {input: `"fix/\360\237\220\233-crash"`, want: "fix/🐛-crash"},
+		"escaped-quote":      {input: `"say-\"hi\""`, want: `say-"hi"`},
+		"control-escapes":    {input: `"a\tb"`, want: "a\tb"},
+		"literal-quotes":     {input: `"quoted"`, want: `"quoted"`},
+		"malformed-octal":    {input: `"caf\30"`, want: `"caf\30"`},
+		"unknown-escape":     {input: `"a\qb"`, want: `"a\qb"`},
+		"unescaped-quote":    {input: `"a"b\t"`, want: `"a"b\t"`},
+		"trailing-backslash": {input: `"ab\"`, want: `"ab\"`},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := unquoteRefName(tc.input); got != tc.want {
+				t.Fatalf("unquoteRefName(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractBranchFromSubjectExoticNames(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		subject string
+		want    string
+	}{
+		"utf8":          {subject: "checkout: moving from main to feature/日本語", want: "feature/日本語"},
+		"emoji":         {subject: "checkout: moving from main to fix/🐛", want: "fix/🐛"},
+		"c-quoted":      {subject: `checkout: moving from main to "feature/caf\303\251"`, want: "feature/café"},
+		"inner-quote":   {subject: "checkout: moving to it's-done", want: "it's-done"},
+		"single-quoted": {subject: "checkout: switching to 'feature/naïve'", want: "feature/naïve"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := extractBranchFromSubject(tc.subject)
+			if !ok || got != tc.want {
+				t.Fatalf("extractBranchFromSubject(%q) = (%q, %v), want %q", tc.subject, got, ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestClientParsesQuotedRefNames(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{
+			args:   []string{"for-each-ref", "--format=%(refname:lstrip=2)", "--sort=-committerdate", "refs/heads"},
+			stdout: "\"feature/caf\\303\\251\"\nfix/🐛\n",
+		},
+		{
+			args:   []string{"for-each-ref", "--sort=-committerdate", "--format=%(refname)%09%(committerdate:unix)%09%(symref)%09%(authorname) %(authoremail)", "refs/heads", "refs/remotes"},
+			stdout: "\"refs/heads/feature/caf\\303\\251\"\t100\t\n",
+		},
+	}}
+	client := NewClient(runner)
+
+	names, err := client.BranchesByCommitDate(context.Background())
+	if err != nil {
+		t.Fatalf("BranchesByCommitDate returned error: %v", err)
+	}
+	if want := []string{"feature/café", "fix/🐛"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("BranchesByCommitDate() = %q, want %q", names, want)
+	}
+
+	refs, err := client.BranchRefs(context.Background())
+	if err != nil {
+		t.Fatalf("BranchRefs returned error: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Name != "feature/café" || refs[0].Remote {
+		t.Fatalf("BranchRefs() = %+v", refs)
+	}
+}
+
+func TestClientCheckoutExoticName(t *testing.T) {
+	t.Parallel()
+
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, stdout: "main"},
+		{args: []string{"checkout", "feature/café-🐛"}},
+	}}
+	client := NewClient(runner)
+
+	if _, err := client.CheckoutBranch(context.Background(), "feature/café-🐛"); err != nil {
+		t.Fatalf("CheckoutBranch returned error: %v", err)
+	}
+	if !runner.Exhausted() {
+		t.Fatal("expected every scripted git call to run")
+	}
+}
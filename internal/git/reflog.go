@@ -0,0 +1,124 @@
+package git
+
+import "strings"
+
+// subjectParsers recognize the reflog subjects that leave HEAD on a branch.
+// Each returns the branch its subject names, and false for subjects of
+// other forms.
+var subjectParsers = []func(subject string) (string, bool){
+	parseCheckoutSubject,
+	parseRebaseSubject,
+	parseResetSubject,
+	parseRenameSubject,
+}
+
+// extractBranchFromSubject returns the branch a reflog subject moved HEAD
+// to, as the first of subjectParsers that recognizes it says.
+func extractBranchFromSubject(subject string) (string, bool) {
+	if subject == "" {
+		return "", false
+	}
+	for _, parse := range subjectParsers {
+		if branch, ok := parse(subject); ok {
+			return branch, true
+		}
+	}
+	return "", false
+}
+
+// parseCheckoutSubject handles the subjects of git checkout and git switch,
+// -b, -B, and -c included: "checkout: moving from <old> to <new>", and the
+// "checkout: moving to" and "checkout: switching to" forms of older gits.
+func parseCheckoutSubject(subject string) (string, bool) {
+	const (
+		prefixMoveFrom  = "checkout: moving from "
+		prefixMoveTo    = "checkout: moving to "
+		prefixSwitching = "checkout: switching to "
+	)
+
+	switch {
+	case strings.HasPrefix(subject, prefixMoveFrom):
+		rest := strings.TrimPrefix(subject, prefixMoveFrom)
+		idx := strings.LastIndex(rest, " to ")
+		if idx == -1 {
+			return "", false
+		}
+		return nonEmpty(unquoteSubjectName(rest[idx+4:]))
+	case strings.HasPrefix(subject, prefixMoveTo):
+		return nonEmpty(unquoteSubjectName(strings.TrimPrefix(subject, prefixMoveTo)))
+	case strings.HasPrefix(subject, prefixSwitching):
+		return nonEmpty(unquoteSubjectName(strings.TrimPrefix(subject, prefixSwitching)))
+	default:
+		return "", false
+	}
+}
+
+// parseRebaseSubject handles the entry a rebase writes when it finishes
+// and checks the rebased branch out again: "rebase (finish): returning to
+// refs/heads/<branch>", with "rebase -i" or "pull --rebase" as the action
+// in some cases, and "rebase finished: returning to ..." from older gits.
+// Entries written while the rebase runs leave HEAD detached.
+func parseRebaseSubject(subject string) (string, bool) {
+	const returning = ": returning to "
+
+	action, target, ok := strings.Cut(subject, returning)
+	if !ok || !(strings.HasSuffix(action, " (finish)") || action == "rebase finished") {
+		return "", false
+	}
+	return localBranch(target)
+}
+
+// parseResetSubject handles "reset: moving to <target>" when the target is
+// a branch name rather than a commit or a revision expression such as
+// HEAD~1.
+func parseResetSubject(subject string) (string, bool) {
+	target, ok := strings.CutPrefix(subject, "reset: moving to ")
+	if !ok {
+		return "", false
+	}
+	target = unquoteSubjectName(target)
+	if target == "HEAD" || isHexHash(target) || strings.ContainsAny(target, "~^:@{") {
+		return "", false
+	}
+	return nonEmpty(strings.TrimPrefix(target, "refs/heads/"))
+}
+
+// parseRenameSubject handles "Branch: renamed refs/heads/<old> to
+// refs/heads/<new>", written when git branch -m renames the current branch.
+func parseRenameSubject(subject string) (string, bool) {
+	rest, ok := strings.CutPrefix(subject, "Branch: renamed ")
+	if !ok {
+		return "", false
+	}
+	idx := strings.LastIndex(rest, " to ")
+	if idx == -1 {
+		return "", false
+	}
+	return localBranch(rest[idx+4:])
+}
+
+// localBranch returns the branch name of a refs/heads/ ref in a subject.
+func localBranch(ref string) (string, bool) {
+	name, ok := strings.CutPrefix(unquoteSubjectName(ref), "refs/heads/")
+	if !ok {
+		return "", false
+	}
+	return nonEmpty(name)
+}
+
+func nonEmpty(name string) (string, bool) {
+	return name, name != ""
+}
+
+// isHexHash reports whether s looks like an abbreviated or full object name.
+func isHexHash(s string) bool {
+	if len(s) < 7 || len(s) > 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
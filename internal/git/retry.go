@@ -0,0 +1,155 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides how network commands such as fetch and push are
+// retried after failures that look transient.
+type RetryPolicy struct {
+	// Retries is how many times a failed command is run again; 0 disables retrying.
+	Retries int
+	// Delay is the wait before the first retry. It doubles for every further retry.
+	Delay time.Duration
+	// Notify, when set, is called before each retry.
+	Notify func(RetryAttempt)
+}
+
+// RetryAttempt describes a transient failure that is about to be retried.
+type RetryAttempt struct {
+	// Args are the git arguments of the failed command.
+	Args []string
+	// Attempt is the number of the attempt about to start, counting from 1.
+	Attempt int
+	// Attempts is the total number of attempts the policy allows.
+	Attempts int
+	// Wait is how long the retry is delayed.
+	Wait time.Duration
+	// Reason is the line of git's output that marked the failure as transient.
+	Reason string
+}
+
+// transientFailures are lower-case fragments of git and curl messages for
+// failures that are worth retrying.
+var transientFailures = []string{
+	"timed out",
+	"connection reset",
+	"could not resolve host",
+	"temporary failure in name resolution",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"unexpected disconnect",
+	"rpc failed",
+	"gnutls recv error",
+	"ssl_read",
+}
+
+// retryRunner wraps the Runner used for network commands and retries them
+// according to policy.
+type retryRunner struct {
+	runner Runner
+	policy RetryPolicy
+	sleep  func(context.Context, time.Duration) error
+}
+
+func newRetryRunner(runner Runner, policy RetryPolicy) *retryRunner {
+	return &retryRunner{runner: runner, policy: policy, sleep: sleepContext}
+}
+
+// Run implements Runner.
+func (r *retryRunner) Run(ctx context.Context, args ...string) (string, error) {
+	var stdout string
+	err := r.retry(ctx, args, func() (string, error) {
+		var err error
+		stdout, err = r.runner.Run(ctx, args...)
+		return "", err
+	})
+	return stdout, err
+}
+
+// RunWithCombinedOutput implements CombinedRunner, falling back to Run when
+// the wrapped Runner does not expose stderr.
+func (r *retryRunner) RunWithCombinedOutput(ctx context.Context, args ...string) (string, string, error) {
+	combined, ok := r.runner.(CombinedRunner)
+	if !ok {
+		stdout, err := r.Run(ctx, args...)
+		return stdout, "", err
+	}
+	var stdout, stderr string
+	err := r.retry(ctx, args, func() (string, error) {
+		var err error
+		stdout, stderr, err = combined.RunWithCombinedOutput(ctx, args...)
+		return stderr, err
+	})
+	return stdout, stderr, err
+}
+
+// RunWithPrompts implements PromptRunner, falling back to
+// RunWithCombinedOutput when the wrapped Runner cannot attach to the terminal.
+func (r *retryRunner) RunWithPrompts(ctx context.Context, args ...string) (string, error) {
+	prompter, ok := r.runner.(PromptRunner)
+	if !ok {
+		_, stderr, err := r.RunWithCombinedOutput(ctx, args...)
+		return stderr, err
+	}
+	var stderr string
+	err := r.retry(ctx, args, func() (string, error) {
+		var err error
+		stderr, err = prompter.RunWithPrompts(ctx, args...)
+		return stderr, err
+	})
+	return stderr, err
+}
+
+// retry calls run until it succeeds, fails for a reason that is not
+// transient, ctx is done, or the policy runs out of retries.
+func (r *retryRunner) retry(ctx context.Context, args []string, run func() (stderr string, err error)) error {
+	attempts := r.policy.Retries + 1
+	wait := r.policy.Delay
+	for attempt := 1; ; attempt++ {
+		stderr, err := run()
+		if err == nil || attempt == attempts || ctx.Err() != nil {
+			return err
+		}
+		reason, ok := transientReason(stderr, err)
+		if !ok {
+			return err
+		}
+		if r.policy.Notify != nil {
+			r.policy.Notify(RetryAttempt{Args: args, Attempt: attempt + 1, Attempts: attempts, Wait: wait, Reason: reason})
+		}
+		if sleepErr := r.sleep(ctx, wait); sleepErr != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+// transientReason returns the first line of stderr or err that matches a
+// transient failure.
+func transientReason(stderr string, err error) (string, bool) {
+	for _, text := range []string{stderr, err.Error()} {
+		for _, line := range strings.Split(text, "\n") {
+			lower := strings.ToLower(line)
+			for _, fragment := range transientFailures {
+				if strings.Contains(lower, fragment) {
+					return strings.TrimSpace(line), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetryRunner(t *testing.T) {
+	t.Parallel()
+
+	fetch := []string{"fetch", "origin"}
+	timeout := errors.New("git fetch origin: exit status 128")
+	timeoutStderr := "fatal: unable to access 'https://example.com/repo.git/': Connection timed out after 30001 milliseconds"
+	denied := errors.New("git fetch origin: exit status 128")
+	deniedStderr := "remote: Permission to org/repo.git denied."
+
+	cases := map[string]struct {
+		calls     []scriptCall
+		wantErr   error
+		wantWaits []time.Duration
+		wantNotes []RetryAttempt
+	}{
+		"success": {
+			calls: []scriptCall{{args: fetch, stdout: "ok"}},
+		},
+		"transient then success": {
+			calls: []scriptCall{
+				{args: fetch, stderr: timeoutStderr, err: timeout},
+				{args: fetch, stdout: "ok"},
+			},
+			wantWaits: []time.Duration{time.Second},
+			wantNotes: []RetryAttempt{{Args: fetch, Attempt: 2, Attempts: 3, Wait: time.Second, Reason: timeoutStderr}},
+		},
+		"permanent failure": {
+			calls:   []scriptCall{{args: fetch, stderr: deniedStderr, err: denied}},
+			wantErr: denied,
+		},
+		"retries exhausted": {
+			calls: []scriptCall{
+				{args: fetch, stderr: timeoutStderr, err: timeout},
+				{args: fetch, stderr: "fatal: the remote end hung up unexpectedly", err: timeout},
+				{args: fetch, stderr: timeoutStderr, err: timeout},
+			},
+			wantErr:   timeout,
+			wantWaits: []time.Duration{time.Second, 2 * time.Second},
+			wantNotes: []RetryAttempt{
+				{Args: fetch, Attempt: 2, Attempts: 3, Wait: time.Second, Reason: timeoutStderr},
+				{Args: fetch, Attempt: 3, Attempts: 3, Wait: 2 * time.Second, Reason: "fatal: the remote end hung up unexpectedly"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var notes []RetryAttempt
+			var waits []time.Duration
+			inner := &scriptRunner{testingT: t, calls: tc.calls}
+			runner := newRetryRunner(inner, RetryPolicy{
+				Retries: 2,
+				Delay:   time.Second,
+				Notify:  func(a RetryAttempt) { notes = append(notes, a) },
+			})
+			runner.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+
+			_, _, err := runner.RunWithCombinedOutput(context.Background(), fetch...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tc.wantErr)
+			}
+			if inner.index != len(tc.calls) {
+				t.Fatalf("expected %d git calls, got %d", len(tc.calls), inner.index)
+			}
+			if !reflect.DeepEqual(waits, tc.wantWaits) {
+				t.Fatalf("waits = %v, want %v", waits, tc.wantWaits)
+			}
+			if !reflect.DeepEqual(notes, tc.wantNotes) {
+				t.Fatalf("notes = %#v, want %#v", notes, tc.wantNotes)
+			}
+		})
+	}
+}
+
+func TestRetryRunnerStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	timeout := errors.New("Connection timed out")
+	inner := &scriptRunner{testingT: t, calls: []scriptCall{{args: []string{"push", "origin", "main"}, err: timeout}}}
+	runner := newRetryRunner(inner, RetryPolicy{Retries: 3, Delay: time.Hour})
+	runner.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	if _, err := runner.Run(ctx, "push", "origin", "main"); !errors.Is(err, timeout) {
+		t.Fatalf("error = %v, want %v", err, timeout)
+	}
+	if inner.index != 1 {
+		t.Fatalf("expected 1 git call, got %d", inner.index)
+	}
+}
+
+func TestClientRetriesOnlyNetworkCommands(t *testing.T) {
+	t.Parallel()
+
+	reset := errors.New("fatal: read error: Connection reset by peer")
+	runner := &scriptRunner{testingT: t, calls: []scriptCall{
+		{args: []string{"fetch", "origin"}, err: reset},
+		{args: []string{"fetch", "origin"}},
+		{args: []string{"remote", "set-url", "origin", "git@example.com:repo.git"}, err: reset},
+	}}
+	client := NewClient(runner)
+	client.SetRetryPolicy(RetryPolicy{Retries: 1})
+
+	if _, err := client.FetchRemote(context.Background(), "origin"); err != nil {
+		t.Fatalf("FetchRemote returned error: %v", err)
+	}
+	if _, err := client.SetRemoteURL(context.Background(), "origin", "git@example.com:repo.git"); !errors.Is(err, reset) {
+		t.Fatalf("SetRemoteURL error = %v, want %v", err, reset)
+	}
+	if runner.index != len(runner.calls) {
+		t.Fatalf("expected %d git calls, got %d", len(runner.calls), runner.index)
+	}
+}
+
+// promptScriptRunner is a scriptRunner that can also attach to the terminal.
+type promptScriptRunner struct {
+	*scriptRunner
+	prompted int
+}
+
+func (r *promptScriptRunner) RunWithPrompts(ctx context.Context, args ...string) (string, error) {
+	r.prompted++
+	_, stderr, err := r.RunWithCombinedOutput(ctx, args...)
+	return stderr, err
+}
+
+func TestClientCredentialPrompts(t *testing.T) {
+	t.Parallel()
+
+	push := []string{"push", "origin", "feature/x"}
+	timeout := errors.New("git push origin feature/x: exit status 128")
+
+	t.Run("attached with retries", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &promptScriptRunner{scriptRunner: &scriptRunner{testingT: t, calls: []scriptCall{
+			{args: push, stderr: "fatal: unable to access 'https://example.com/': Operation timed out", err: timeout},
+			{args: push, stdout: "pushed", stderr: "To https://example.com/"},
+		}}}
+		client := NewClient(runner)
+		client.SetRetryPolicy(RetryPolicy{Retries: 1})
+		client.SetCredentialPrompts(true)
+		if !client.CredentialPrompts() {
+			t.Fatal("CredentialPrompts() = false, want true")
+		}
+
+		result, err := client.PushBranch(context.Background(), "feature/x", PushOptions{})
+		if err != nil {
+			t.Fatalf("PushBranch returned error: %v", err)
+		}
+		if result != (PushResult{}) {
+			t.Fatalf("result = %#v, want output left on the terminal", result)
+		}
+		if runner.prompted != 2 {
+			t.Fatalf("attached runs = %d, want 2", runner.prompted)
+		}
+	})
+
+	t.Run("runner without terminal support", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &scriptRunner{testingT: t, calls: []scriptCall{{args: push, stdout: "pushed"}}}
+		client := NewClient(runner)
+		client.SetCredentialPrompts(true)
+		if client.CredentialPrompts() {
+			t.Fatal("CredentialPrompts() = true for a runner that cannot attach")
+		}
+		result, err := client.PushBranch(context.Background(), "feature/x", PushOptions{})
+		if err != nil {
+			t.Fatalf("PushBranch returned error: %v", err)
+		}
+		if result.Stdout != "pushed" {
+			t.Fatalf("Stdout = %q, want captured output", result.Stdout)
+		}
+	})
+}
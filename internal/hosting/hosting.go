@@ -0,0 +1,119 @@
+package hosting
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Repository identifies a repository on a code host.
+type Repository struct {
+	// Host is the web host, such as github.com, without a user or port.
+	Host string
+	// Path is the repository path on the host, such as org/repo, without .git.
+	Path string
+}
+
+// ParseRemoteURL extracts the host and repository path from a git remote URL
+// in any of the forms git accepts for network remotes: https://host/org/repo.git,
+// ssh://git@host:22/org/repo.git, git://host/org/repo, or the scp-like
+// git@host:org/repo.git.
+func ParseRemoteURL(raw string) (Repository, error) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	if strings.Contains(raw, "://") {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return Repository{}, fmt.Errorf("cannot parse remote URL %q: %w", raw, err)
+		}
+		if parsed.Scheme == "file" {
+			return Repository{}, fmt.Errorf("remote URL %q is a local path", raw)
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	} else {
+		// scp-like syntax: [user@]host:path. A colon after a slash means a
+		// local path, as in git.
+		colon := strings.Index(raw, ":")
+		if colon <= 0 || strings.Contains(raw[:colon], "/") {
+			return Repository{}, fmt.Errorf("remote URL %q is a local path", raw)
+		}
+		host, path = raw[:colon], raw[colon+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return Repository{}, fmt.Errorf("remote URL %q has no host or repository path", raw)
+	}
+	return Repository{Host: strings.ToLower(host), Path: path}, nil
+}
+
+// Placeholders in a URL template given to BranchURL.
+const (
+	placeholderHost   = "{host}"
+	placeholderPath   = "{path}"
+	placeholderBranch = "{branch}"
+)
+
+// BranchURL returns the web page of branch in repo. GitHub, GitLab, and
+// Bitbucket are recognized by their host names, including self-hosted ones
+// whose names contain github, gitlab, or bitbucket. Any other host needs
+// template, which may use {host}, {path}, and {branch}; a non-empty template
+// also takes precedence for self-hosted servers.
+func BranchURL(repo Repository, branch, template string) (string, error) {
+	escaped := escapeBranch(branch)
+	if strings.TrimSpace(template) != "" && !isPublicHost(repo.Host) {
+		return strings.NewReplacer(
+			placeholderHost, repo.Host,
+			placeholderPath, repo.Path,
+			placeholderBranch, escaped,
+		).Replace(template), nil
+	}
+
+	base := "https://" + repo.Host + "/" + repo.Path
+	switch {
+	case strings.Contains(repo.Host, "github"):
+		return base + "/tree/" + escaped, nil
+	case strings.Contains(repo.Host, "gitlab"):
+		return base + "/-/tree/" + escaped, nil
+	case strings.Contains(repo.Host, "bitbucket"):
+		return base + "/branch/" + escaped, nil
+	}
+	return "", fmt.Errorf("unknown code host %s; set browse.url_template to build its URLs", repo.Host)
+}
+
+// PullRequestsURL returns the page listing the pull requests, or merge
+// requests, opened from branch in repo. Only GitHub and GitLab hosts are
+// recognized, by their host names as in BranchURL.
+func PullRequestsURL(repo Repository, branch string) (string, error) {
+	base := "https://" + repo.Host + "/" + repo.Path
+	branch = strings.TrimSpace(branch)
+	switch {
+	case strings.Contains(repo.Host, "github"):
+		return base + "/pulls?q=" + url.QueryEscape("is:pr head:"+branch), nil
+	case strings.Contains(repo.Host, "gitlab"):
+		return base + "/-/merge_requests?state=all&source_branch=" + url.QueryEscape(branch), nil
+	}
+	return "", fmt.Errorf("no pull request page is known for %s", repo.Host)
+}
+
+// isPublicHost reports whether host is one of the public code hosts, whose
+// URL layout is known.
+func isPublicHost(host string) bool {
+	switch host {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		return true
+	}
+	return false
+}
+
+// escapeBranch escapes each path segment of branch, keeping the slashes.
+func escapeBranch(branch string) string {
+	segments := strings.Split(strings.TrimSpace(branch), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
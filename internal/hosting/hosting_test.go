@@ -0,0 +1,172 @@
+package hosting
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		url     string
+		want    Repository
+		wantErr string
+	}{
+		"https":           {url: "https://github.com/org/repo.git", want: Repository{Host: "github.com", Path: "org/repo"}},
+		"https no .git":   {url: "https://gitlab.com/group/sub/repo/", want: Repository{Host: "gitlab.com", Path: "group/sub/repo"}},
+		"https with user": {url: "https://user@bitbucket.org/team/repo.git", want: Repository{Host: "bitbucket.org", Path: "team/repo"}},
+		"ssh with port":   {url: "ssh://git@git.example.com:2222/tools/repo.git", want: Repository{Host: "git.example.com", Path: "tools/repo"}},
+		"scp-like":        {url: "git@GitHub.com:org/repo.git", want: Repository{Host: "github.com", Path: "org/repo"}},
+		"git protocol":    {url: "git://example.org/repo", want: Repository{Host: "example.org", Path: "repo"}},
+		"local path":      {url: "/srv/git/repo.git", wantErr: "local path"},
+		"relative path":   {url: "./repo:x", wantErr: "local path"},
+		"file URL":        {url: "file:///srv/git/repo.git", wantErr: "local path"},
+		"no path":         {url: "https://github.com/", wantErr: "no host or repository path"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRemoteURL(tc.url)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v (%+v)", tc.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRemoteURL returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("ParseRemoteURL() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBranchURL(t *testing.T) {
+	t.Parallel()
+
+	template := "https://{host}/{path}/src/branch/{branch}"
+
+	cases := map[string]struct {
+		repo     Repository
+		branch   string
+		template string
+		want     string
+		wantErr  string
+	}{
+		"github": {
+			repo:   Repository{Host: "github.com", Path: "org/repo"},
+			branch: "feature/login#2",
+			want:   "https://github.com/org/repo/tree/feature/login%232",
+		},
+		"gitlab": {
+			repo:   Repository{Host: "gitlab.com", Path: "group/sub/repo"},
+			branch: "main",
+			want:   "https://gitlab.com/group/sub/repo/-/tree/main",
+		},
+		"bitbucket": {
+			repo:   Repository{Host: "bitbucket.org", Path: "team/repo"},
+			branch: "fix bug",
+			want:   "https://bitbucket.org/team/repo/branch/fix%20bug",
+		},
+		"self-hosted gitlab": {
+			repo:   Repository{Host: "gitlab.example.com", Path: "tools/repo"},
+			branch: "main",
+			want:   "https://gitlab.example.com/tools/repo/-/tree/main",
+		},
+		"template for self-hosted": {
+			repo:     Repository{Host: "git.example.com", Path: "tools/repo"},
+			branch:   "feature/x",
+			template: template,
+			want:     "https://git.example.com/tools/repo/src/branch/feature/x",
+		},
+		"template ignored for github.com": {
+			repo:     Repository{Host: "github.com", Path: "org/repo"},
+			branch:   "main",
+			template: template,
+			want:     "https://github.com/org/repo/tree/main",
+		},
+		"unknown host": {
+			repo:    Repository{Host: "git.example.com", Path: "tools/repo"},
+			branch:  "main",
+			wantErr: "set browse.url_template",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := BranchURL(tc.repo, tc.branch, tc.template)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v (%q)", tc.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BranchURL returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("BranchURL() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPullRequestsURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		repo    Repository
+		branch  string
+		want    string
+		wantErr string
+	}{
+		"github": {
+			repo:   Repository{Host: "github.com", Path: "org/repo"},
+			branch: "feature/login",
+			want:   "https://github.com/org/repo/pulls?q=is%3Apr+head%3Afeature%2Flogin",
+		},
+		"gitlab": {
+			repo:   Repository{Host: "gitlab.example.com", Path: "group/repo"},
+			branch: "fix#2",
+			want:   "https://gitlab.example.com/group/repo/-/merge_requests?state=all&source_branch=fix%232",
+		},
+		"unknown host": {
+			repo:    Repository{Host: "bitbucket.org", Path: "team/repo"},
+			branch:  "main",
+			wantErr: "no pull request page is known for bitbucket.org",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := PullRequestsURL(tc.repo, tc.branch)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v (%q)", tc.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PullRequestsURL returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("PullRequestsURL() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
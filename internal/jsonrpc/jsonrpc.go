@@ -0,0 +1,159 @@
+// Package jsonrpc serves JSON-RPC 2.0 over a stream with one message per
+// line, the framing editor plugins find easiest to speak over a child
+// process's stdin and stdout.
+//
+// Requests are handled one at a time in the order they arrive, so that a
+// plugin never races two git operations against each other. Batches are not
+// supported.
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Version is the value of the jsonrpc member of every message.
+const Version = "2.0"
+
+// The error codes defined by JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is reported for an error a handler returns that is not
+	// an *Error.
+	CodeServerError = -32000
+)
+
+// maxMessageSize bounds a single request line.
+const maxMessageSize = 1 << 20
+
+// Error is a JSON-RPC error object. Handlers return one to choose the code
+// and data of a failure; any other error is reported with CodeServerError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// InvalidParams returns the error for params a handler cannot use.
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler runs one method. params is the raw params member, nil when the
+// request has none, and the result is marshaled as the result member.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Server dispatches requests to the handlers of their methods.
+type Server struct {
+	handlers map[string]Handler
+}
+
+// NewServer returns a server without methods.
+func NewServer() *Server {
+	return &Server{handlers: map[string]Handler{}}
+}
+
+// Handle registers h as the handler of method, replacing any earlier one.
+func (s *Server) Handle(method string, h Handler) {
+	s.handlers[method] = h
+}
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Serve reads requests from in and writes a response line to out for every
+// request with an id; notifications, which have none, get no response. It
+// returns nil once in reaches EOF, ctx's error once ctx is done, or the
+// error that made in or out unusable.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	encoder := json.NewEncoder(out)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp, ok := s.dispatch(ctx, line)
+		if !ok {
+			continue
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// dispatch runs the request in line and returns its response, or false for
+// a notification.
+func (s *Server) dispatch(ctx context.Context, line []byte) (response, bool) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return failure(nil, &Error{Code: CodeParseError, Message: "parse error: " + err.Error()}), true
+	}
+	notification := len(req.ID) == 0
+	if req.Version != Version || req.Method == "" {
+		return failure(req.ID, &Error{Code: CodeInvalidRequest, Message: `invalid request: want "jsonrpc": "2.0" and a method`}), true
+	}
+	handler, ok := s.handlers[req.Method]
+	if !ok {
+		return failure(req.ID, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}), !notification
+	}
+	if bytes.Equal(req.Params, []byte("null")) {
+		req.Params = nil
+	}
+	result, err := handler(ctx, req.Params)
+	if notification {
+		return response{}, false
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeServerError, Message: err.Error()}
+		}
+		return failure(req.ID, rpcErr), true
+	}
+	if result == nil {
+		// A successful response must carry a result.
+		result = struct{}{}
+	}
+	return response{Version: Version, ID: req.ID, Result: result}, true
+}
+
+func failure(id json.RawMessage, err *Error) response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return response{Version: Version, ID: id, Error: err}
+}
@@ -0,0 +1,78 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	var notified []string
+	server.Handle("echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil || p.Text == "" {
+			return nil, InvalidParams("echo needs text")
+		}
+		return p, nil
+	})
+	server.Handle("fail", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, errors.New("git failed")
+	})
+	server.Handle("note", func(ctx context.Context, params json.RawMessage) (any, error) {
+		notified = append(notified, string(params))
+		return nil, nil
+	})
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+		``,
+		`{"jsonrpc":"2.0","id":"two","method":"echo","params":{}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"fail"}`,
+		`{"jsonrpc":"2.0","method":"note","params":[1]}`,
+		`{"jsonrpc":"2.0","method":"missing"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"missing"}`,
+		`{"id":5,"method":"echo"}`,
+		`{not json`,
+		`{"jsonrpc":"2.0","id":6,"method":"note","params":null}`,
+	}, "\n")
+	out := &bytes.Buffer{}
+	if err := server.Serve(context.Background(), strings.NewReader(in), out); err != nil {
+		t.Fatalf("Serve returned error: %v", err)
+	}
+
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","id":"two","error":{"code":-32602,"message":"echo needs text"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32000,"message":"git failed"}}`,
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32601,"message":"unknown method \"missing\""}}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32600,"message":"invalid request: want \"jsonrpc\": \"2.0\" and a method"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error: invalid character 'n' looking for beginning of object key string"}}`,
+		`{"jsonrpc":"2.0","id":6,"result":{}}`,
+	}
+	got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("responses:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(notified) != 2 || notified[0] != "[1]" || notified[1] != "" {
+		t.Fatalf("note received %q, want the notification's params and then none", notified)
+	}
+}
+
+func TestServeStopsWithContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewServer().Serve(ctx, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"x"}`+"\n"), &bytes.Buffer{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Serve returned %v, want context.Canceled", err)
+	}
+}
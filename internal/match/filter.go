@@ -0,0 +1,74 @@
+package match
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Mode selects how a filter query is interpreted.
+type Mode int
+
+const (
+	// ModeFuzzy matches candidates containing the query characters in order.
+	ModeFuzzy Mode = iota
+	// ModeRegex treats the query as a regular expression.
+	ModeRegex
+)
+
+// Filter decides which candidates a query selects. The zero value matches everything.
+type Filter struct {
+	query         string
+	caseSensitive bool
+	re            *regexp.Regexp
+}
+
+// CaseSensitive reports whether query should match case-sensitively under
+// smart case, which is the case exactly when it contains an upper-case letter.
+func CaseSensitive(query string) bool {
+	for _, r := range query {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFilter compiles query for mode using smart case. An invalid regular
+// expression is reported as an error.
+func NewFilter(query string, mode Mode) (*Filter, error) {
+	query = strings.TrimSpace(query)
+	f := &Filter{query: query, caseSensitive: CaseSensitive(query)}
+	if mode != ModeRegex || query == "" {
+		return f, nil
+	}
+
+	pattern := query
+	if !f.caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	f.re = re
+	return f, nil
+}
+
+// Match reports whether candidate is selected by the filter.
+func (f *Filter) Match(candidate string) bool {
+	if f == nil || f.query == "" {
+		return true
+	}
+	if f.re != nil {
+		return f.re.MatchString(candidate)
+	}
+
+	query := f.query
+	if !f.caseSensitive {
+		query = strings.ToLower(query)
+		candidate = strings.ToLower(candidate)
+	}
+	_, ok := subsequenceScore([]rune(query), []rune(candidate))
+	return ok
+}
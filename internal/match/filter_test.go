@@ -0,0 +1,80 @@
+package match
+
+import "testing"
+
+func TestCaseSensitive(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"":        false,
+		"feature": false,
+		"Feature": true,
+		"fix-42":  false,
+		"ÉTÉ":     true,
+	}
+	for query, want := range cases {
+		if got := CaseSensitive(query); got != want {
+			t.Errorf("CaseSensitive(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		query     string
+		mode      Mode
+		candidate string
+		want      bool
+	}{
+		"empty matches everything":          {query: "", candidate: "main", want: true},
+		"fuzzy subsequence":                 {query: "fab", candidate: "feature/alpha-beta", want: true},
+		"fuzzy out of order":                {query: "baf", candidate: "feature/alpha-beta", want: false},
+		"lower-case query ignores case":     {query: "jira", candidate: "feature/JIRA-12", want: true},
+		"upper-case query respects case":    {query: "Jira", candidate: "feature/JIRA-12", want: false},
+		"upper-case query exact case":       {query: "JIRA", candidate: "feature/JIRA-12", want: true},
+		"regex anchors":                     {query: "^release/", mode: ModeRegex, candidate: "release/1.2", want: true},
+		"regex no match":                    {query: "^release/", mode: ModeRegex, candidate: "hotfix/release", want: false},
+		"regex lower-case ignores case":     {query: "jira-\\d+", mode: ModeRegex, candidate: "feature/JIRA-12", want: true},
+		"regex upper-case respects case":    {query: "Jira-\\d+", mode: ModeRegex, candidate: "feature/JIRA-12", want: false},
+		"regex is not fuzzy":                {query: "fab", mode: ModeRegex, candidate: "feature/alpha-beta", want: false},
+		"surrounding whitespace is ignored": {query: " main ", candidate: "main", want: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := NewFilter(tc.query, tc.mode)
+			if err != nil {
+				t.Fatalf("NewFilter returned error: %v", err)
+			}
+			if got := filter.Match(tc.candidate); got != tc.want {
+				t.Fatalf("Match(%q) with query %q = %v, want %v", tc.candidate, tc.query, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewFilterRejectsInvalidRegex(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewFilter("feature/(", ModeRegex); err == nil {
+		t.Fatal("expected error for invalid regular expression")
+	}
+	if _, err := NewFilter("feature/(", ModeFuzzy); err != nil {
+		t.Fatalf("fuzzy filter should accept any query, got %v", err)
+	}
+}
+
+func TestNilFilterMatchesEverything(t *testing.T) {
+	t.Parallel()
+
+	var filter *Filter
+	if !filter.Match("anything") {
+		t.Fatal("nil filter should match every candidate")
+	}
+}
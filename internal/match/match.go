@@ -0,0 +1,116 @@
+package match
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreExact       = 1000
+	scoreSegment     = 100
+	scorePrefix      = 60
+	scoreSubstring   = 40
+	scoreChar        = 10
+	scoreConsecutive = 15
+	scoreBoundary    = 20
+	maxGapPenalty    = 30
+)
+
+// Match pairs a candidate with its score against a query.
+type Match struct {
+	Candidate string
+	Score     int
+}
+
+// Score reports how well query fuzzy-matches candidate, ignoring case. ok is
+// false when the query characters do not appear in order within candidate.
+// Exact matches score highest, followed by exact final path segments, prefixes,
+// and substrings; scattered
+// subsequences score lowest, with bonuses for consecutive characters and
+// characters at word boundaries such as after '/' or '-'.
+func Score(query, candidate string) (int, bool) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	c := strings.ToLower(candidate)
+	if q == "" {
+		return 0, true
+	}
+	if q == c {
+		return scoreExact, true
+	}
+
+	score, ok := subsequenceScore([]rune(q), []rune(c))
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case lastSegment(c) == q:
+		score += scoreSegment
+	case strings.HasPrefix(c, q) || strings.HasPrefix(lastSegment(c), q):
+		score += scorePrefix
+	case strings.Contains(c, q):
+		score += scoreSubstring
+	}
+	return score, true
+}
+
+// Rank scores every candidate against query and returns the matching ones,
+// best first. Candidates with equal scores keep their input order.
+func Rank(query string, candidates []string) []Match {
+	matches := make([]Match, 0, len(candidates))
+	for _, candidate := range candidates {
+		if score, ok := Score(query, candidate); ok {
+			matches = append(matches, Match{Candidate: candidate, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+func subsequenceScore(query, candidate []rune) (int, bool) {
+	score := 0
+	gaps := 0
+	last := -1
+	qi := 0
+	for ci := 0; ci < len(candidate) && qi < len(query); ci++ {
+		if candidate[ci] != query[qi] {
+			continue
+		}
+		score += scoreChar
+		if last >= 0 && ci == last+1 {
+			score += scoreConsecutive
+		} else if last >= 0 {
+			gaps += ci - last - 1
+		}
+		if ci == 0 || isBoundary(candidate[ci-1]) {
+			score += scoreBoundary
+		}
+		last = ci
+		qi++
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	if gaps > maxGapPenalty {
+		gaps = maxGapPenalty
+	}
+	return score - gaps, true
+}
+
+func isBoundary(r rune) bool {
+	switch r {
+	case '/', '-', '_', '.', ' ':
+		return true
+	}
+	return unicode.IsSpace(r)
+}
+
+func lastSegment(s string) string {
+	if idx := strings.LastIndex(s, "/"); idx >= 0 {
+		return s[idx+1:]
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		query     string
+		candidate string
+		wantOK    bool
+	}{
+		"empty-query":      {query: "", candidate: "main", wantOK: true},
+		"exact":            {query: "main", candidate: "main", wantOK: true},
+		"case-insensitive": {query: "MAIN", candidate: "main", wantOK: true},
+		"subsequence":      {query: "fl", candidate: "feature/login", wantOK: true},
+		"out-of-order":     {query: "lf", candidate: "feature/login", wantOK: false},
+		"missing-char":     {query: "mainx", candidate: "main", wantOK: false},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, ok := Score(tc.query, tc.candidate)
+			if ok != tc.wantOK {
+				t.Fatalf("Score(%q, %q) ok = %v, want %v", tc.query, tc.candidate, ok, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestScoreOrdering(t *testing.T) {
+	t.Parallel()
+
+	// Each pair lists a better match followed by a worse one for the same query.
+	cases := map[string]struct {
+		query  string
+		better string
+		worse  string
+	}{
+		"exact-over-prefix":        {query: "main", better: "main", worse: "main-old"},
+		"prefix-over-substring":    {query: "log", better: "feature/login", worse: "fix/catalog"},
+		"substring-over-scattered": {query: "login", better: "fix/login-page", worse: "fix/logo-index"},
+		"boundary-over-middle":     {query: "fb", better: "feature/bar", worse: "fabric"},
+		"consecutive-over-gappy":   {query: "abc", better: "xabcx", worse: "axbxc"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			better, ok := Score(tc.query, tc.better)
+			if !ok {
+				t.Fatalf("Score(%q, %q) did not match", tc.query, tc.better)
+			}
+			worse, ok := Score(tc.query, tc.worse)
+			if !ok {
+				t.Fatalf("Score(%q, %q) did not match", tc.query, tc.worse)
+			}
+			if better <= worse {
+				t.Fatalf("expected %q (%d) to outscore %q (%d) for %q", tc.better, better, tc.worse, worse, tc.query)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	t.Parallel()
+
+	got := Rank("login", []string{"main", "feature/login-form", "fix/login", "docs"})
+	names := make([]string, 0, len(got))
+	for _, m := range got {
+		names = append(names, m.Candidate)
+	}
+	want := []string{"fix/login", "feature/login-form"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("Rank() = %v, want %v", names, want)
+	}
+}
@@ -3,20 +3,56 @@ package navigator
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
+	"time"
+
+	"branch-navigator/internal/git"
 )
 
 // GitService describes the git functionality required by the navigator.
 type GitService interface {
 	CurrentBranch(ctx context.Context) (string, error)
-	ReflogBranchMoves(ctx context.Context) ([]string, error)
+	ReflogBranchMoves(ctx context.Context, maxEntries, maxBranches int) ([]string, error)
 	BranchesByCommitDate(ctx context.Context) ([]string, error)
 	BranchExists(ctx context.Context, branch string) (bool, error)
 }
 
+// BranchLister is implemented by git services that can list every local
+// branch at once. The navigator then checks candidates against that snapshot
+// instead of calling BranchExists for each one.
+type BranchLister interface {
+	LocalBranches(ctx context.Context) ([]string, error)
+}
+
+// Timeline is implemented by git services that report when branches were
+// checked out and committed to. The navigator then orders branches by the
+// later of the two, so that a branch committed to minutes ago ranks above
+// one last checked out a week ago, instead of listing every branch found
+// in the reflog before the rest.
+type Timeline interface {
+	ReflogVisits(ctx context.Context, maxEntries, maxBranches int) ([]git.ReflogVisit, error)
+	LocalBranchRefs(ctx context.Context) ([]git.BranchRef, error)
+}
+
+// DefaultMaxReflog is the number of reflog entries read unless SetMaxReflog
+// says otherwise. Long-lived repositories have tens of thousands of entries,
+// but the branches worth listing are found among the most recent ones.
+const DefaultMaxReflog = 300
+
+// reflogCandidateFactor is how many reflog branches are parsed per branch to
+// be listed, leaving room for branches deleted since they were checked out.
+const reflogCandidateFactor = 2
+
 // Navigator coordinates branch retrieval using GitService.
 type Navigator struct {
 	git GitService
+	// maxReflog bounds the reflog entries read; 0 reads the whole reflog.
+	maxReflog int
+	// local is the snapshot of local branches, taken on first use; nil when
+	// unavailable, in which case BranchExists is called per candidate.
+	local       map[string]struct{}
+	localLoaded bool
 }
 
 // New constructs a Navigator bound to the provided GitService.
@@ -24,7 +60,15 @@ func New(git GitService) (*Navigator, error) {
 	if git == nil {
 		return nil, errors.New("git service is required")
 	}
-	return &Navigator{git: git}, nil
+	return &Navigator{git: git, maxReflog: DefaultMaxReflog}, nil
+}
+
+// SetMaxReflog bounds how many reflog entries are read; 0 reads the whole
+// reflog. Branches beyond the bound are still listed by commit date.
+func (n *Navigator) SetMaxReflog(max int) {
+	if n != nil && max >= 0 {
+		n.maxReflog = max
+	}
 }
 
 // RecentBranches returns up to limit recent branch names excluding the current branch, deduplicated.
@@ -36,55 +80,139 @@ func (n *Navigator) RecentBranches(ctx context.Context, limit int) ([]string, er
 		return nil, nil
 	}
 
-	current, err := n.git.CurrentBranch(ctx)
+	stream, err := n.stream(ctx, limit)
 	if err != nil {
 		return nil, err
 	}
+	return stream.Next(ctx, limit)
+}
 
-	results := make([]string, 0, limit)
-	seen := map[string]struct{}{current: struct{}{}}
+// Stream lists recent branches in the same order as RecentBranches, but only
+// checks candidates as more branches are requested, so that long histories
+// can be shown before every branch has been inspected.
+type Stream struct {
+	nav     *Navigator
+	seen    map[string]struct{}
+	pending []string
+	// reflogErr is kept so that it can be reported alongside a fallback failure.
+	reflogErr error
+	// fallback reports whether the commit-date fallback has been queued.
+	fallback bool
+}
 
-	reflogBranches, err := n.git.ReflogBranchMoves(ctx)
-	var reflogErr error
-	if err != nil {
-		reflogErr = err
-	} else {
-		results, err = n.appendBranches(ctx, results, reflogBranches, seen, limit)
-		if err != nil {
-			return nil, err
-		}
-		if len(results) >= limit {
-			return results, nil
-		}
+// Stream reads the reflog and returns a Stream positioned before its first branch.
+func (n *Navigator) Stream(ctx context.Context) (*Stream, error) {
+	return n.stream(ctx, 0)
+}
+
+// stream is Stream for callers that need at most want branches, which lets
+// reflog parsing stop early; want <= 0 parses every read entry.
+func (n *Navigator) stream(ctx context.Context, want int) (*Stream, error) {
+	if n == nil || n.git == nil {
+		return nil, errors.New("navigator is not configured")
 	}
 
-	fallbackBranches, err := n.git.BranchesByCommitDate(ctx)
+	current, err := n.git.CurrentBranch(ctx)
 	if err != nil {
-		if reflogErr != nil {
-			return nil, errors.Join(reflogErr, err)
-		}
 		return nil, err
 	}
 
-	results, err = n.appendBranches(ctx, results, fallbackBranches, seen, limit)
-	if err != nil {
+	s := &Stream{nav: n, seen: map[string]struct{}{current: {}}}
+	maxBranches := 0
+	if want > 0 {
+		maxBranches = want * reflogCandidateFactor
+	}
+	if timeline, ok := n.git.(Timeline); ok {
+		if branches, err := n.byLatestActivity(ctx, timeline, maxBranches); err == nil && len(branches) > 0 {
+			// Every local branch is listed already, which also makes them
+			// the snapshot branchExists checks against.
+			n.local = make(map[string]struct{}, len(branches))
+			for _, branch := range branches {
+				n.local[branch] = struct{}{}
+			}
+			n.localLoaded = true
+			s.pending, s.fallback = branches, true
+			return s, nil
+		}
+	}
+	s.pending, s.reflogErr = n.git.ReflogBranchMoves(ctx, n.maxReflog, maxBranches)
+	return s, nil
+}
+
+// byLatestActivity returns the local branches ordered by their latest
+// activity, the later of their last checkout in the reflog and their last
+// commit, most recent first. Ties keep reflog order, then commit order. A
+// reflog that cannot be read leaves commit dates alone.
+func (n *Navigator) byLatestActivity(ctx context.Context, timeline Timeline, maxBranches int) ([]string, error) {
+	refs, err := timeline.LocalBranchRefs(ctx)
+	if err != nil || len(refs) == 0 {
 		return nil, err
 	}
+	visits, _ := timeline.ReflogVisits(ctx, n.maxReflog, maxBranches)
 
-	return results, nil
+	latest := make(map[string]time.Time, len(refs))
+	for _, ref := range refs {
+		latest[ref.Name] = ref.CommitDate
+	}
+
+	branches := make([]string, 0, len(refs))
+	listed := make(map[string]struct{}, len(refs))
+	add := func(name string, when time.Time) {
+		last, ok := latest[name]
+		if !ok {
+			return
+		}
+		if when.After(last) {
+			latest[name] = when
+		}
+		if _, dup := listed[name]; !dup {
+			listed[name] = struct{}{}
+			branches = append(branches, name)
+		}
+	}
+	for _, visit := range visits {
+		add(visit.Branch, visit.Time)
+	}
+	for _, ref := range refs {
+		add(ref.Name, time.Time{})
+	}
+	sort.SliceStable(branches, func(i, j int) bool {
+		return latest[branches[i]].After(latest[branches[j]])
+	})
+	return branches, nil
 }
 
-func (n *Navigator) appendBranches(ctx context.Context, current []string, candidates []string, seen map[string]struct{}, limit int) ([]string, error) {
-	for _, candidate := range candidates {
-		candidate = strings.TrimSpace(candidate)
+// Next returns up to max further branches, or every remaining branch when max
+// is zero or less. An empty result means the stream is exhausted.
+func (s *Stream) Next(ctx context.Context, max int) ([]string, error) {
+	var results []string
+	for max <= 0 || len(results) < max {
+		if len(s.pending) == 0 {
+			if s.fallback {
+				break
+			}
+			s.fallback = true
+			branches, err := s.nav.git.BranchesByCommitDate(ctx)
+			if err != nil {
+				if s.reflogErr != nil {
+					return nil, errors.Join(s.reflogErr, err)
+				}
+				return nil, err
+			}
+			s.pending = branches
+			continue
+		}
+
+		candidate := strings.TrimSpace(s.pending[0])
+		s.pending = s.pending[1:]
 		if candidate == "" {
 			continue
 		}
-		if _, ok := seen[candidate]; ok {
+		if _, ok := s.seen[candidate]; ok {
 			continue
 		}
 
-		exists, err := n.git.BranchExists(ctx, candidate)
+		exists, err := s.nav.branchExists(ctx, candidate)
 		if err != nil {
 			return nil, err
 		}
@@ -92,11 +220,30 @@ func (n *Navigator) appendBranches(ctx context.Context, current []string, candid
 			continue
 		}
 
-		seen[candidate] = struct{}{}
-		current = append(current, candidate)
-		if len(current) >= limit {
-			break
+		s.seen[candidate] = struct{}{}
+		results = append(results, candidate)
+	}
+	return results, nil
+}
+
+// branchExists reports whether branch exists locally, consulting the local
+// branch snapshot when the git service provides one. A failed snapshot falls
+// back to GitService.BranchExists.
+func (n *Navigator) branchExists(ctx context.Context, branch string) (bool, error) {
+	if !n.localLoaded {
+		n.localLoaded = true
+		if lister, ok := n.git.(BranchLister); ok {
+			if names, err := lister.LocalBranches(ctx); err == nil {
+				n.local = make(map[string]struct{}, len(names))
+				for _, name := range names {
+					n.local[name] = struct{}{}
+				}
+			}
 		}
 	}
-	return current, nil
+	if n.local != nil {
+		_, ok := n.local[branch]
+		return ok, nil
+	}
+	return n.git.BranchExists(ctx, branch)
 }
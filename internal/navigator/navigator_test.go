@@ -5,6 +5,9 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"branch-navigator/internal/git"
 )
 
 type fakeGit struct {
@@ -17,13 +20,17 @@ type fakeGit struct {
 	errFallback  error
 	errExists    error
 	existsErrFor string
+	existsCalls  int
+	// reflogBounds records the maxEntries and maxBranches of the last reflog read.
+	reflogBounds [2]int
 }
 
 func (f *fakeGit) CurrentBranch(ctx context.Context) (string, error) {
 	return f.current, f.errCurrent
 }
 
-func (f *fakeGit) ReflogBranchMoves(ctx context.Context) ([]string, error) {
+func (f *fakeGit) ReflogBranchMoves(ctx context.Context, maxEntries, maxBranches int) ([]string, error) {
+	f.reflogBounds = [2]int{maxEntries, maxBranches}
 	if f.errReflog != nil {
 		return nil, f.errReflog
 	}
@@ -38,6 +45,7 @@ func (f *fakeGit) BranchesByCommitDate(ctx context.Context) ([]string, error) {
 }
 
 func (f *fakeGit) BranchExists(ctx context.Context, branch string) (bool, error) {
+	f.existsCalls++
 	if f.errExists != nil && (f.existsErrFor == "" || f.existsErrFor == branch) {
 		return false, f.errExists
 	}
@@ -195,3 +203,268 @@ func TestNavigatorMissingConfiguration(t *testing.T) {
 		t.Fatal("expected error when navigator is nil")
 	}
 }
+
+func TestStreamNext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	git := &fakeGit{
+		current:  "main",
+		reflog:   []string{"a", "main", "gone", "b", "a"},
+		fallback: []string{"c", "b", "d"},
+		exists:   map[string]bool{"a": true, "b": true, "c": true, "d": true},
+	}
+	nav, err := New(git)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	stream, err := nav.Stream(ctx)
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+
+	pages := [][]string{{"a", "b"}, {"c"}, {"d"}, nil}
+	sizes := []int{2, 1, 0, 5}
+	for i, want := range pages {
+		got, err := stream.Next(ctx, sizes[i])
+		if err != nil {
+			t.Fatalf("Next returned error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("page %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestStreamFallbackError(t *testing.T) {
+	t.Parallel()
+
+	reflogFailed := errors.New("reflog failed")
+	fallbackFailed := errors.New("fallback failed")
+	nav, err := New(&fakeGit{current: "main", errReflog: reflogFailed, errFallback: fallbackFailed})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	stream, err := nav.Stream(context.Background())
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	if _, err := stream.Next(context.Background(), 1); !errors.Is(err, reflogFailed) || !errors.Is(err, fallbackFailed) {
+		t.Fatalf("expected joined reflog and fallback errors, got %v", err)
+	}
+}
+
+// listingGit adds a local branch snapshot to fakeGit.
+type listingGit struct {
+	*fakeGit
+	local     []string
+	errLocal  error
+	listCalls int
+}
+
+func (l *listingGit) LocalBranches(ctx context.Context) ([]string, error) {
+	l.listCalls++
+	if l.errLocal != nil {
+		return nil, l.errLocal
+	}
+	return append([]string(nil), l.local...), nil
+}
+
+func TestNavigatorUsesLocalBranchSnapshot(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		errLocal        error
+		wantExistsCalls int
+	}{
+		"snapshot": {
+			wantExistsCalls: 0,
+		},
+		"snapshot-error-falls-back": {
+			errLocal:        errors.New("branch --list failed"),
+			wantExistsCalls: 4,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			git := &listingGit{
+				fakeGit: &fakeGit{
+					current:  "main",
+					reflog:   []string{"a", "gone", "b"},
+					fallback: []string{"c"},
+					exists:   map[string]bool{"a": true, "b": true, "c": true},
+				},
+				local:    []string{"main", "a", "b", "c"},
+				errLocal: tc.errLocal,
+			}
+			nav, err := New(git)
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				got, err := nav.RecentBranches(context.Background(), 3)
+				if err != nil {
+					t.Fatalf("RecentBranches returned error: %v", err)
+				}
+				if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+					t.Fatalf("RecentBranches() = %v, want %v", got, want)
+				}
+			}
+			if git.listCalls != 1 {
+				t.Fatalf("LocalBranches called %d times, want 1", git.listCalls)
+			}
+			if git.existsCalls != tc.wantExistsCalls*2 {
+				t.Fatalf("BranchExists called %d times, want %d", git.existsCalls, tc.wantExistsCalls*2)
+			}
+		})
+	}
+}
+
+func TestNavigatorReflogBounds(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cases := map[string]struct {
+		maxReflog *int
+		read      func(*Navigator) error
+		want      [2]int
+	}{
+		"recent branches stop after enough candidates": {
+			read: func(n *Navigator) error { _, err := n.RecentBranches(ctx, 10); return err },
+			want: [2]int{DefaultMaxReflog, 20},
+		},
+		"stream parses every read entry": {
+			read: func(n *Navigator) error { _, err := n.Stream(ctx); return err },
+			want: [2]int{DefaultMaxReflog, 0},
+		},
+		"whole reflog": {
+			maxReflog: new(int),
+			read:      func(n *Navigator) error { _, err := n.RecentBranches(ctx, 5); return err },
+			want:      [2]int{0, 10},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			git := &fakeGit{current: "main"}
+			nav, err := New(git)
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+			if tc.maxReflog != nil {
+				nav.SetMaxReflog(*tc.maxReflog)
+			}
+			if err := tc.read(nav); err != nil {
+				t.Fatalf("read returned error: %v", err)
+			}
+			if git.reflogBounds != tc.want {
+				t.Fatalf("reflog read with bounds %v, want %v", git.reflogBounds, tc.want)
+			}
+		})
+	}
+}
+
+// timelineGit adds reflog times and commit dates to fakeGit.
+type timelineGit struct {
+	*fakeGit
+	visits    []git.ReflogVisit
+	refs      []git.BranchRef
+	errVisits error
+	errRefs   error
+}
+
+func (f *timelineGit) ReflogVisits(ctx context.Context, maxEntries, maxBranches int) ([]git.ReflogVisit, error) {
+	f.reflogBounds = [2]int{maxEntries, maxBranches}
+	return f.visits, f.errVisits
+}
+
+func (f *timelineGit) LocalBranchRefs(ctx context.Context) ([]git.BranchRef, error) {
+	return f.refs, f.errRefs
+}
+
+func TestNavigatorOrdersByLatestActivity(t *testing.T) {
+	t.Parallel()
+
+	at := func(minutes int) time.Time {
+		return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(minutes) * time.Minute)
+	}
+	visits := []git.ReflogVisit{
+		{Branch: "visited", Time: at(-60 * 24 * 7)},
+		{Branch: "main", Time: at(-60 * 24 * 8)},
+		{Branch: "gone", Time: at(-60 * 24 * 9)},
+		{Branch: "both", Time: at(-60 * 24 * 10)},
+	}
+	refs := []git.BranchRef{
+		{Name: "committed", CommitDate: at(-5)},
+		{Name: "both", CommitDate: at(-60)},
+		{Name: "main", CommitDate: at(-90)},
+		{Name: "visited", CommitDate: at(-60 * 24 * 30)},
+		{Name: "stale", CommitDate: at(-60 * 24 * 60)},
+	}
+
+	cases := map[string]struct {
+		git  *timelineGit
+		want []string
+	}{
+		"merged by time": {
+			git:  &timelineGit{visits: visits, refs: refs},
+			want: []string{"committed", "both", "visited", "stale"},
+		},
+		"reflog unreadable": {
+			git:  &timelineGit{visits: visits, refs: refs, errVisits: errors.New("no reflog")},
+			want: []string{"committed", "both", "visited", "stale"},
+		},
+		"ties keep reflog order": {
+			git: &timelineGit{
+				visits: []git.ReflogVisit{{Branch: "b"}, {Branch: "a"}},
+				refs:   []git.BranchRef{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			},
+			want: []string{"b", "a", "c"},
+		},
+		"refs unreadable falls back": {
+			git: &timelineGit{
+				fakeGit: &fakeGit{reflog: []string{"visited"}, fallback: []string{"committed"}, exists: map[string]bool{"visited": true, "committed": true}},
+				visits:  visits,
+				errRefs: errors.New("for-each-ref failed"),
+			},
+			want: []string{"visited", "committed"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.git.fakeGit == nil {
+				tc.git.fakeGit = &fakeGit{}
+			}
+			tc.git.current = "main"
+			nav, err := New(tc.git)
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+			got, err := nav.RecentBranches(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("RecentBranches returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("RecentBranches() = %v, want %v", got, tc.want)
+			}
+			if tc.git.existsCalls != 0 && tc.git.errRefs == nil {
+				t.Fatalf("BranchExists called %d times, want the refs to serve as the snapshot", tc.git.existsCalls)
+			}
+		})
+	}
+}
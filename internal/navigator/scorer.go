@@ -0,0 +1,109 @@
+package navigator
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// rankingPoolFactor widens the candidate pool when ranking so that branches
+// used often but switched away from long ago can still rank into the list.
+const rankingPoolFactor = 3
+
+// Weights controls how much each signal contributes to a branch's score.
+type Weights struct {
+	// Frequency weighs how often the branch was checked out, relative to the most used candidate.
+	Frequency float64
+	// Recency weighs how recently the branch was checked out, decaying by the scorer's half-life.
+	Recency float64
+	// Reflog weighs the branch's position in recency order, 1 for the most recent and approaching 0 for the last.
+	Reflog float64
+}
+
+// Visit summarizes the recorded checkouts of a branch.
+type Visit struct {
+	Count int
+	Last  time.Time
+}
+
+// Scorer ranks branches by blending checkout frequency, checkout recency,
+// and position in recency order. The zero value keeps recency order.
+type Scorer struct {
+	// HalfLife is the age at which a checkout's recency signal halves; zero or less disables decay.
+	HalfLife time.Duration
+	Weights  Weights
+}
+
+// UsesHistory reports whether the scorer needs recorded visits to rank branches.
+func (s Scorer) UsesHistory() bool {
+	return s.Weights.Frequency != 0 || s.Weights.Recency != 0
+}
+
+// Score returns the score of a branch at position pos among n candidates in
+// recency order, where maxCount is the highest visit count among them.
+func (s Scorer) Score(visit Visit, pos, n, maxCount int, now time.Time) float64 {
+	var frequency, recency, reflog float64
+	if maxCount > 0 {
+		frequency = float64(visit.Count) / float64(maxCount)
+	}
+	if !visit.Last.IsZero() {
+		recency = 1
+		if age := now.Sub(visit.Last); s.HalfLife > 0 && age > 0 {
+			recency = math.Exp2(-float64(age) / float64(s.HalfLife))
+		}
+	}
+	if n > 0 {
+		reflog = 1 - float64(pos)/float64(n)
+	}
+	return s.Weights.Frequency*frequency + s.Weights.Recency*recency + s.Weights.Reflog*reflog
+}
+
+// Rank returns branches, given in recency order, sorted by descending score.
+// Ties keep their recency order.
+func (s Scorer) Rank(branches []string, visits map[string]Visit, now time.Time) []string {
+	maxCount := 0
+	for _, branch := range branches {
+		if count := visits[branch].Count; count > maxCount {
+			maxCount = count
+		}
+	}
+
+	scores := make(map[string]float64, len(branches))
+	for i, branch := range branches {
+		scores[branch] = s.Score(visits[branch], i, len(branches), maxCount, now)
+	}
+
+	ranked := append([]string(nil), branches...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+	return ranked
+}
+
+// RankedBranches returns up to limit recent branches ordered by scorer, or
+// every recent branch when limit is zero or less. When the scorer ignores
+// visits the order matches RecentBranches.
+func (n *Navigator) RankedBranches(ctx context.Context, limit int, scorer Scorer, visits map[string]Visit, now time.Time) ([]string, error) {
+	if !scorer.UsesHistory() {
+		stream, err := n.stream(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+		return stream.Next(ctx, limit)
+	}
+
+	stream, err := n.stream(ctx, limit*rankingPoolFactor)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := stream.Next(ctx, limit*rankingPoolFactor)
+	if err != nil {
+		return nil, err
+	}
+	ranked := scorer.Rank(pool, visits, now)
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
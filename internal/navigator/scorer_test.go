@@ -0,0 +1,196 @@
+package navigator
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScorerScore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	cases := map[string]struct {
+		scorer   Scorer
+		visit    Visit
+		pos      int
+		n        int
+		maxCount int
+		want     float64
+	}{
+		"reflog-first": {
+			scorer: Scorer{Weights: Weights{Reflog: 1}},
+			pos:    0,
+			n:      4,
+			want:   1,
+		},
+		"reflog-last": {
+			scorer: Scorer{Weights: Weights{Reflog: 1}},
+			pos:    3,
+			n:      4,
+			want:   0.25,
+		},
+		"frequency-relative-to-max": {
+			scorer:   Scorer{Weights: Weights{Frequency: 2}},
+			visit:    Visit{Count: 3},
+			maxCount: 6,
+			want:     1,
+		},
+		"frequency-without-visits": {
+			scorer: Scorer{Weights: Weights{Frequency: 1}},
+			want:   0,
+		},
+		"recency-one-half-life": {
+			scorer: Scorer{HalfLife: 7 * day, Weights: Weights{Recency: 1}},
+			visit:  Visit{Count: 1, Last: now.Add(-7 * day)},
+			want:   0.5,
+		},
+		"recency-two-half-lives": {
+			scorer: Scorer{HalfLife: day, Weights: Weights{Recency: 1}},
+			visit:  Visit{Count: 1, Last: now.Add(-2 * day)},
+			want:   0.25,
+		},
+		"recency-without-decay": {
+			scorer: Scorer{Weights: Weights{Recency: 1}},
+			visit:  Visit{Count: 1, Last: now.Add(-100 * day)},
+			want:   1,
+		},
+		"recency-never-visited": {
+			scorer: Scorer{HalfLife: day, Weights: Weights{Recency: 1}},
+			want:   0,
+		},
+		"blend": {
+			scorer:   Scorer{HalfLife: day, Weights: Weights{Frequency: 1, Recency: 2, Reflog: 0.5}},
+			visit:    Visit{Count: 1, Last: now.Add(-day)},
+			pos:      1,
+			n:        2,
+			maxCount: 4,
+			want:     0.25 + 1 + 0.25,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.scorer.Score(tc.visit, tc.pos, tc.n, tc.maxCount, now)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Fatalf("Score() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScorerRank(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	branches := []string{"a", "b", "c"}
+	visits := map[string]Visit{
+		"b": {Count: 1, Last: now.Add(-time.Hour)},
+		"c": {Count: 10, Last: now.Add(-30 * day)},
+	}
+
+	cases := map[string]struct {
+		scorer Scorer
+		want   []string
+	}{
+		"zero-value-keeps-reflog-order": {
+			want: []string{"a", "b", "c"},
+		},
+		"reflog": {
+			scorer: Scorer{Weights: Weights{Reflog: 1}},
+			want:   []string{"a", "b", "c"},
+		},
+		"frequency": {
+			scorer: Scorer{Weights: Weights{Frequency: 1}},
+			want:   []string{"c", "b", "a"},
+		},
+		"recency": {
+			scorer: Scorer{HalfLife: day, Weights: Weights{Recency: 1}},
+			want:   []string{"b", "c", "a"},
+		},
+		"reflog-outweighs-frequency": {
+			scorer: Scorer{Weights: Weights{Frequency: 0.1, Reflog: 1}},
+			want:   []string{"a", "b", "c"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.scorer.Rank(branches, visits, now)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Rank() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNavigatorRankedBranches(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	git := &fakeGit{
+		current:  "main",
+		reflog:   []string{"a", "b", "c", "d"},
+		fallback: []string{"e"},
+		exists:   map[string]bool{"a": true, "b": true, "c": true, "d": true, "e": true},
+	}
+	visits := map[string]Visit{"d": {Count: 5, Last: now}}
+
+	cases := map[string]struct {
+		limit  int
+		scorer Scorer
+		want   []string
+	}{
+		"reflog-only": {
+			limit:  2,
+			scorer: Scorer{Weights: Weights{Reflog: 1}},
+			want:   []string{"a", "b"},
+		},
+		"frequency-pulls-from-pool": {
+			limit:  2,
+			scorer: Scorer{Weights: Weights{Frequency: 1, Reflog: 0.5}},
+			want:   []string{"d", "a"},
+		},
+		"unlimited": {
+			scorer: Scorer{Weights: Weights{Reflog: 1}},
+			want:   []string{"a", "b", "c", "d", "e"},
+		},
+		"unlimited-ranked": {
+			scorer: Scorer{Weights: Weights{Frequency: 1, Reflog: 0.5}},
+			want:   []string{"d", "a", "b", "c", "e"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			nav, err := New(git)
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+			got, err := nav.RankedBranches(context.Background(), tc.limit, tc.scorer, visits, now)
+			if err != nil {
+				t.Fatalf("RankedBranches returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("RankedBranches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Browser opens URLs with the BROWSER variable or the platform's opener.
+type Browser struct {
+	goos     string
+	getenv   func(string) string
+	lookPath func(string) (string, error)
+	start    func(path string, args []string) error
+}
+
+// New returns a Browser for the running platform.
+func New() *Browser {
+	return &Browser{
+		goos:     runtime.GOOS,
+		getenv:   os.Getenv,
+		lookPath: exec.LookPath,
+		start:    runAttached,
+	}
+}
+
+// Open shows url in a web browser. BROWSER, a list of commands separated
+// like PATH entries as understood by xdg-open and Python's webbrowser module,
+// is tried first; then open on macOS, rundll32 on Windows, and xdg-open, wslview, or
+// sensible-browser elsewhere.
+func (b *Browser) Open(url string) error {
+	if b == nil {
+		return errors.New("browser is not configured")
+	}
+	for _, candidate := range b.commands(url) {
+		path, err := b.lookPath(candidate[0])
+		if err != nil {
+			continue
+		}
+		if err := b.start(path, candidate[1:]); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no browser could be started; open %s manually", url)
+}
+
+// commands lists the command lines to try, each a program followed by its
+// arguments.
+func (b *Browser) commands(url string) [][]string {
+	commands := [][]string{}
+	for _, entry := range strings.Split(b.getenv("BROWSER"), string(os.PathListSeparator)) {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		replaced := false
+		for i, field := range fields {
+			if strings.Contains(field, "%s") {
+				fields[i] = strings.ReplaceAll(field, "%s", url)
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields = append(fields, url)
+		}
+		commands = append(commands, fields)
+	}
+
+	switch b.goos {
+	case "darwin":
+		return append(commands, []string{"open", url})
+	case "windows":
+		return append(commands, []string{"rundll32", "url.dll,FileProtocolHandler", url})
+	}
+	return append(commands,
+		[]string{"xdg-open", url},
+		[]string{"wslview", url},
+		[]string{"sensible-browser", url},
+	)
+}
+
+// runAttached runs the opener with the terminal attached, so that a text
+// browser named in BROWSER can take it over. Graphical openers return as
+// soon as the browser has the URL.
+func runAttached(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,173 @@
+package browser
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBrowserOpen(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://github.com/org/repo/tree/main"
+
+	cases := map[string]struct {
+		goos      string
+		browser   string
+		installed map[string]bool
+		failing   map[string]bool
+		wantRuns  [][]string
+		wantErr   bool
+	}{
+		"macOS": {
+			goos:      "darwin",
+			installed: map[string]bool{"open": true},
+			wantRuns:  [][]string{{"open", url}},
+		},
+		"windows": {
+			goos:      "windows",
+			installed: map[string]bool{"rundll32": true},
+			wantRuns:  [][]string{{"rundll32", "url.dll,FileProtocolHandler", url}},
+		},
+		"linux falls back to wslview": {
+			goos:      "linux",
+			installed: map[string]bool{"wslview": true},
+			wantRuns:  [][]string{{"wslview", url}},
+		},
+		"BROWSER first": {
+			goos:      "linux",
+			browser:   "firefox --new-tab",
+			installed: map[string]bool{"firefox": true, "xdg-open": true},
+			wantRuns:  [][]string{{"firefox", "--new-tab", url}},
+		},
+		"BROWSER placeholder and fallback": {
+			goos:      "linux",
+			browser:   "missing:lynx -accept_all_cookies %s",
+			installed: map[string]bool{"lynx": true, "xdg-open": true},
+			failing:   map[string]bool{"lynx": true},
+			wantRuns:  [][]string{{"lynx", "-accept_all_cookies", url}, {"xdg-open", url}},
+		},
+		"BROWSER before open on macOS": {
+			goos:      "darwin",
+			browser:   "w3m",
+			installed: map[string]bool{"w3m": true, "open": true},
+			wantRuns:  [][]string{{"w3m", url}},
+		},
+		"BROWSER before rundll32 on Windows": {
+			goos:      "windows",
+			browser:   "firefox.exe",
+			installed: map[string]bool{"firefox.exe": true, "rundll32": true},
+			wantRuns:  [][]string{{"firefox.exe", url}},
+		},
+		"BROWSER empty entries skipped": {
+			goos:      "linux",
+			browser:   ":  :elinks",
+			installed: map[string]bool{"elinks": true},
+			wantRuns:  [][]string{{"elinks", url}},
+		},
+		"BROWSER fails without opener": {
+			goos:      "linux",
+			browser:   "firefox",
+			installed: map[string]bool{"firefox": true},
+			failing:   map[string]bool{"firefox": true},
+			wantRuns:  [][]string{{"firefox", url}},
+			wantErr:   true,
+		},
+		"nothing installed": {
+			goos:    "linux",
+			wantErr: true,
+		},
+		"macOS open fails": {
+			goos:      "darwin",
+			installed: map[string]bool{"open": true, "xdg-open": true},
+			failing:   map[string]bool{"open": true},
+			wantRuns:  [][]string{{"open", url}},
+			wantErr:   true,
+		},
+		"windows without rundll32": {
+			goos:      "windows",
+			installed: map[string]bool{"xdg-open": true},
+			wantErr:   true,
+		},
+		"unsupported platform without openers": {
+			goos:      "plan9",
+			installed: map[string]bool{"open": true, "rundll32": true},
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var runs [][]string
+			b := &Browser{
+				goos:   tc.goos,
+				getenv: func(key string) string { return map[string]string{"BROWSER": tc.browser}[key] },
+				lookPath: func(name string) (string, error) {
+					if tc.installed[name] {
+						return name, nil
+					}
+					return "", errors.New("not found")
+				},
+				start: func(path string, args []string) error {
+					runs = append(runs, append([]string{path}, args...))
+					if tc.failing[path] {
+						return errors.New("failed")
+					}
+					return nil
+				},
+			}
+
+			err := b.Open(url)
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), url) {
+					t.Fatalf("expected an error naming the URL, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Open returned error: %v", err)
+			}
+			if !reflect.DeepEqual(runs, tc.wantRuns) {
+				t.Fatalf("runs = %q, want %q", runs, tc.wantRuns)
+			}
+		})
+	}
+}
+
+func TestBrowserNotConfigured(t *testing.T) {
+	t.Parallel()
+
+	var b *Browser
+	if err := b.Open("https://example.com"); err == nil || err.Error() != "browser is not configured" {
+		t.Fatalf("Open on a nil browser = %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	b := New()
+	if b.goos != runtime.GOOS || b.getenv == nil || b.lookPath == nil || b.start == nil {
+		t.Fatalf("New() = %+v", b)
+	}
+}
+
+func TestRunAttached(t *testing.T) {
+	t.Parallel()
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	if err := runAttached(sh, []string{"-c", "exit 0"}); err != nil {
+		t.Fatalf("runAttached returned error: %v", err)
+	}
+	if err := runAttached(sh, []string{"-c", "exit 3"}); err == nil {
+		t.Fatal("runAttached ignored a failing opener")
+	}
+}
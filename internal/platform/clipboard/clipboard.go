@@ -0,0 +1,107 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// command describes a platform clipboard tool that reads the text on stdin.
+type command struct {
+	name string
+	args []string
+}
+
+// Clipboard copies text with the platform clipboard tool, falling back to the
+// OSC 52 terminal escape sequence when no tool works or the session is remote.
+type Clipboard struct {
+	terminal io.Writer
+	goos     string
+	getenv   func(string) string
+	lookPath func(string) (string, error)
+	run      func(path string, args []string, input string) error
+}
+
+// New returns a Clipboard that writes OSC 52 sequences to terminal.
+func New(terminal io.Writer) *Clipboard {
+	return &Clipboard{
+		terminal: terminal,
+		goos:     runtime.GOOS,
+		getenv:   os.Getenv,
+		lookPath: exec.LookPath,
+		run:      runWithInput,
+	}
+}
+
+// Copy places text on the clipboard.
+func (c *Clipboard) Copy(text string) error {
+	if c == nil {
+		return errors.New("clipboard is not configured")
+	}
+	if !c.remote() {
+		for _, cmd := range c.commands() {
+			path, err := c.lookPath(cmd.name)
+			if err != nil {
+				continue
+			}
+			if err := c.run(path, cmd.args, text); err == nil {
+				return nil
+			}
+		}
+	}
+	return c.osc52(text)
+}
+
+// remote reports whether the process runs inside an SSH session, where local
+// clipboard tools would copy on the wrong machine.
+func (c *Clipboard) remote() bool {
+	return c.getenv("SSH_TTY") != "" || c.getenv("SSH_CONNECTION") != ""
+}
+
+func (c *Clipboard) commands() []command {
+	switch c.goos {
+	case "darwin":
+		return []command{{name: "pbcopy"}}
+	case "windows":
+		return []command{{name: "clip.exe"}}
+	}
+
+	commands := []command{}
+	if c.getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, command{name: "wl-copy"})
+	}
+	if c.getenv("DISPLAY") != "" {
+		commands = append(commands,
+			command{name: "xclip", args: []string{"-selection", "clipboard"}},
+			command{name: "xsel", args: []string{"--clipboard", "--input"}},
+		)
+	}
+	return commands
+}
+
+// osc52 asks the terminal emulator to set the clipboard. Inside tmux the
+// sequence is wrapped in a passthrough so that it reaches the outer terminal.
+func (c *Clipboard) osc52(text string) error {
+	if c.terminal == nil {
+		return errors.New("no clipboard tool available")
+	}
+	sequence := "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if c.getenv("TMUX") != "" {
+		sequence = "\033Ptmux;" + strings.ReplaceAll(sequence, "\033", "\033\033") + "\033\\"
+	}
+	if _, err := io.WriteString(c.terminal, sequence); err != nil {
+		return fmt.Errorf("failed to write clipboard escape sequence: %w", err)
+	}
+	return nil
+}
+
+func runWithInput(path string, args []string, input string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.Run()
+}
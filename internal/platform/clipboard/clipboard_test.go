@@ -0,0 +1,209 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type fakeEnv map[string]string
+
+func (e fakeEnv) get(key string) string {
+	return e[key]
+}
+
+func TestClipboardCopy(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		goos      string
+		env       fakeEnv
+		installed map[string]bool
+		failing   map[string]bool
+		wantRuns  []string
+		wantOSC   string
+	}{
+		"macOS uses pbcopy": {
+			goos:      "darwin",
+			installed: map[string]bool{"pbcopy": true},
+			wantRuns:  []string{"pbcopy"},
+		},
+		"wayland prefers wl-copy": {
+			goos:      "linux",
+			env:       fakeEnv{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: map[string]bool{"wl-copy": true, "xclip": true},
+			wantRuns:  []string{"wl-copy"},
+		},
+		"falls through failing tools": {
+			goos:      "linux",
+			env:       fakeEnv{"DISPLAY": ":0"},
+			installed: map[string]bool{"xclip": true, "xsel": true},
+			failing:   map[string]bool{"xclip": true},
+			wantRuns:  []string{"xclip -selection clipboard", "xsel --clipboard --input"},
+		},
+		"windows uses clip.exe": {
+			goos:      "windows",
+			env:       fakeEnv{"DISPLAY": ":0"},
+			installed: map[string]bool{"clip.exe": true, "xclip": true},
+			wantRuns:  []string{"clip.exe"},
+		},
+		"wayland without wl-copy uses xclip": {
+			goos:      "linux",
+			env:       fakeEnv{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: map[string]bool{"xclip": true},
+			wantRuns:  []string{"xclip -selection clipboard"},
+		},
+		"xsel alone": {
+			goos:      "freebsd",
+			env:       fakeEnv{"DISPLAY": ":0"},
+			installed: map[string]bool{"xsel": true},
+			wantRuns:  []string{"xsel --clipboard --input"},
+		},
+		"every tool fails": {
+			goos:      "linux",
+			env:       fakeEnv{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: map[string]bool{"wl-copy": true, "xclip": true, "xsel": true},
+			failing:   map[string]bool{"wl-copy": true, "xclip": true, "xsel": true},
+			wantRuns:  []string{"wl-copy", "xclip -selection clipboard", "xsel --clipboard --input"},
+			wantOSC:   "\033]52;c;ZmVhdHVyZS94\a",
+		},
+		"windows without clip.exe": {
+			goos:    "windows",
+			wantOSC: "\033]52;c;ZmVhdHVyZS94\a",
+		},
+		"no display uses OSC 52": {
+			goos:      "linux",
+			installed: map[string]bool{"xclip": true},
+			wantOSC:   "\033]52;c;ZmVhdHVyZS94\a",
+		},
+		"ssh skips local tools": {
+			goos:      "darwin",
+			env:       fakeEnv{"SSH_TTY": "/dev/pts/1"},
+			installed: map[string]bool{"pbcopy": true},
+			wantOSC:   "\033]52;c;ZmVhdHVyZS94\a",
+		},
+		"ssh connection skips local tools": {
+			goos:      "linux",
+			env:       fakeEnv{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22", "DISPLAY": "localhost:10.0"},
+			installed: map[string]bool{"xclip": true},
+			wantOSC:   "\033]52;c;ZmVhdHVyZS94\a",
+		},
+		"tmux wraps OSC 52": {
+			goos:    "linux",
+			env:     fakeEnv{"TMUX": "/tmp/tmux-1/default,1,0"},
+			wantOSC: "\033Ptmux;\033\033]52;c;ZmVhdHVyZS94\a\033\\",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			terminal := &bytes.Buffer{}
+			runs := []string{}
+			c := &Clipboard{
+				terminal: terminal,
+				goos:     tc.goos,
+				getenv:   tc.env.get,
+				lookPath: func(name string) (string, error) {
+					if tc.installed[name] {
+						return name, nil
+					}
+					return "", errors.New("not found")
+				},
+				run: func(path string, args []string, input string) error {
+					runs = append(runs, strings.Join(append([]string{path}, args...), " "))
+					if input != "feature/x" {
+						t.Errorf("unexpected input %q", input)
+					}
+					if tc.failing[path] {
+						return errors.New("failed")
+					}
+					return nil
+				},
+			}
+
+			if err := c.Copy("feature/x"); err != nil {
+				t.Fatalf("Copy returned error: %v", err)
+			}
+			if len(tc.wantRuns) == 0 {
+				tc.wantRuns = []string{}
+			}
+			if !reflect.DeepEqual(runs, tc.wantRuns) {
+				t.Fatalf("ran %v, want %v", runs, tc.wantRuns)
+			}
+			if got := terminal.String(); got != tc.wantOSC {
+				t.Fatalf("terminal output = %q, want %q", got, tc.wantOSC)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("closed")
+}
+
+func TestClipboardCopyErrors(t *testing.T) {
+	t.Parallel()
+
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+	cases := map[string]struct {
+		clipboard *Clipboard
+		want      string
+	}{
+		"nil clipboard": {want: "clipboard is not configured"},
+		"no tool and no terminal": {
+			clipboard: &Clipboard{goos: "linux", getenv: fakeEnv{}.get, lookPath: missing},
+			want:      "no clipboard tool available",
+		},
+		"terminal write fails": {
+			clipboard: &Clipboard{terminal: failingWriter{}, goos: "linux", getenv: fakeEnv{}.get, lookPath: missing},
+			want:      "failed to write clipboard escape sequence: closed",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tc.clipboard.Copy("feature/x"); err == nil || err.Error() != tc.want {
+				t.Fatalf("Copy error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	terminal := &bytes.Buffer{}
+	c := New(terminal)
+	if c.terminal != terminal || c.goos != runtime.GOOS || c.getenv == nil || c.lookPath == nil || c.run == nil {
+		t.Fatalf("New(terminal) = %+v", c)
+	}
+}
+
+func TestRunWithInput(t *testing.T) {
+	t.Parallel()
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	if err := runWithInput(sh, []string{"-c", `test "$(cat)" = feature/x`}, "feature/x"); err != nil {
+		t.Fatalf("runWithInput did not pass the text on stdin: %v", err)
+	}
+	if err := runWithInput(sh, []string{"-c", "exit 1"}, ""); err == nil {
+		t.Fatal("runWithInput ignored a failing command")
+	}
+}
@@ -0,0 +1,501 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"branch-navigator/internal/branchname"
+	"branch-navigator/internal/platform/xdg"
+	"branch-navigator/internal/timefmt"
+	"branch-navigator/internal/ui"
+)
+
+const (
+	fileName     = "config.toml"
+	repoFileName = "branch-navigator.toml"
+)
+
+// Config holds the user settings read from the configuration file.
+type Config struct {
+	// Base overrides automatic detection of the repository's base branch.
+	Base string
+	// BackupRetentionDays controls how long deleted branch tips are kept; 0 keeps them forever.
+	BackupRetentionDays int
+	// StaleAfterDays marks branches whose last commit is older than this many days as stale; 0 disables it.
+	StaleAfterDays int
+	// PushAutoSetupUpstream adds --set-upstream when pushing a branch without an
+	// upstream, tracking it on the branch's configured or default remote.
+	PushAutoSetupUpstream bool
+	// CheckoutSwitch checks out with git switch, which guesses the remote
+	// of a branch that only exists remotely, following
+	// checkout.defaultRemote. Git before 2.23 keeps using checkout.
+	CheckoutSwitch bool
+	// ConfirmMerge asks for confirmation, showing the source and target
+	// branches, before merging.
+	ConfirmMerge bool
+	// ProtectedBranches are patterns, such as "release/*", naming the
+	// branches that a merge into asks to type the branch name first. Empty
+	// turns the check off.
+	ProtectedBranches []string
+	// DeleteRemote also deletes the upstream branch of the same name when
+	// the delete action removes a local branch, after asking. It is off by
+	// default, so that -d never changes a remote unless asked to.
+	DeleteRemote bool
+	// MergeGPGSign signs the merge commits made by the merge action, as
+	// --gpg-sign does.
+	MergeGPGSign bool
+	// MergeSignoff adds a Signed-off-by trailer to the merge commits made by
+	// the merge action, as --signoff does.
+	MergeSignoff bool
+	// BrowseURLTemplate builds branch URLs for self-hosted code hosts from
+	// {host}, {path}, and {branch}.
+	BrowseURLTemplate string
+	// CreateTemplate names the branches made by the create action, such as
+	// feature/{ticket}-{slug}. Empty asks for the whole name.
+	CreateTemplate string
+	// DiffTool formats the output of the diff action, such as "delta" or
+	// "difftastic". Empty shows git's own diff.
+	DiffTool string
+	// LogFile receives JSON logs of git commands, renders, and errors for
+	// bug reports. Empty turns logging off; BRANCH_NAVIGATOR_LOG overrides it.
+	LogFile string
+	// NetworkRetries is how many times fetch, prune, and push are retried
+	// after failures that look transient; 0 disables retrying.
+	NetworkRetries int
+	// NetworkRetryDelaySeconds is the wait before the first retry. It doubles
+	// for every further retry.
+	NetworkRetryDelaySeconds float64
+	// SearchRegex makes the selector filter treat queries as regular expressions instead of fuzzy patterns.
+	SearchRegex bool
+	// RankingHalfLifeDays is the age in days at which a checkout's recency signal halves.
+	RankingHalfLifeDays float64
+	// RankingFrequencyWeight weighs how often a branch was checked out.
+	RankingFrequencyWeight float64
+	// RankingRecencyWeight weighs how recently a branch was checked out.
+	RankingRecencyWeight float64
+	// RankingReflogWeight weighs a branch's position in the reflog.
+	RankingReflogWeight float64
+	// TimeFormat is how dates are displayed: "relative", "short", or a Go time
+	// layout. Empty keeps the default of each column.
+	TimeFormat string
+	// Theme names the color theme used when neither --theme nor
+	// BRANCH_NAVIGATOR_THEME is set. Empty keeps the default.
+	Theme string
+	// UIBorder draws a rounded border around the selector.
+	UIBorder bool
+	// UIMarker is drawn before the highlighted row; empty highlights the whole
+	// row instead.
+	UIMarker string
+	// UIMarkerColor colors the marker: a color name, a 256-color index, or
+	// #rrggbb. Empty uses the theme.
+	UIMarkerColor string
+	// UIHeader is the selector header style: "full", "compact", or "none".
+	// Empty means full.
+	UIHeader string
+	// UIDensity is the selector spacing: "comfortable" or "compact". Empty
+	// means comfortable.
+	UIDensity string
+	// UISeparators draws rules instead of blank separating lines.
+	UISeparators bool
+	// UIPinDefault keeps the default branch right below the current branch
+	// instead of in recency order.
+	UIPinDefault bool
+	// UIAutoRefresh refreshes the selector whenever the refs change on
+	// disk, as --watch does.
+	UIAutoRefresh bool
+	// Pipelines names lists of follow-up steps that --then accepts in place
+	// of a single step, from the keys of the [pipelines] table.
+	Pipelines map[string][]string
+	// Actions holds the user-defined actions of the [actions.<name>] tables.
+	Actions map[string]CustomAction
+}
+
+// CustomAction is an action defined in the configuration, offered by
+// --action <name> and in the --menu.
+type CustomAction struct {
+	// Command runs through the shell in the repository root, with {branch}
+	// replaced by the selected branch.
+	Command string
+	// Description explains the action in the selector and the menu.
+	Description string
+	// Confirm asks before running the command.
+	Confirm bool
+}
+
+// Default returns the settings used when no configuration file exists.
+func Default() Config {
+	return Config{
+		BackupRetentionDays:      30,
+		StaleAfterDays:           90,
+		RankingHalfLifeDays:      7,
+		RankingReflogWeight:      1,
+		UIMarker:                 ">",
+		NetworkRetries:           2,
+		NetworkRetryDelaySeconds: 1,
+		ProtectedBranches:        []string{"main", "master", "release/*"},
+	}
+}
+
+// Path returns the location of the configuration file. BRANCH_NAVIGATOR_CONFIG
+// takes precedence, followed by $XDG_CONFIG_HOME and ~/.config.
+func Path() (string, error) {
+	if path := strings.TrimSpace(os.Getenv("BRANCH_NAVIGATOR_CONFIG")); path != "" {
+		return path, nil
+	}
+	dir, err := xdg.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// RepoPath returns the location of the overrides of one repository, kept
+// in the git directory commonDir its worktrees share so that they are
+// neither committed nor lost with a worktree.
+func RepoPath(commonDir string) string {
+	return filepath.Join(commonDir, repoFileName)
+}
+
+// Load reads the configuration file at Path. A missing file yields Default.
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the configuration file at path. A missing file yields Default.
+func LoadFile(path string) (Config, error) {
+	return LoadFiles(path)
+}
+
+// LoadFiles reads the configuration files at paths on top of Default, each
+// overriding the settings of the ones before it, as a repository's file
+// does the user's. Missing files are skipped.
+func LoadFiles(paths ...string) (Config, error) {
+	cfg := Default()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return Config{}, err
+		}
+		if err := cfg.merge(string(data)); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := cfg.check(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Parse decodes configuration file contents on top of Default.
+func Parse(data string) (Config, error) {
+	cfg := Default()
+	if err := cfg.merge(data); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.check(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// merge decodes configuration file contents on top of c.
+func (c *Config) merge(data string) error {
+	values, err := parse(data)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := c.set(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check reports settings that are only invalid once every file is read,
+// such as an action without a command.
+func (c *Config) check() error {
+	names := make([]string, 0, len(c.Actions))
+	for name := range c.Actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c.Actions[name].Command == "" {
+			return fmt.Errorf("actions.%s.command: must be set", name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) set(key string, value any) error {
+	switch key {
+	case "base":
+		return setString(&c.Base, key, value)
+	case "backup.retention_days":
+		return setNonNegativeInt(&c.BackupRetentionDays, key, value)
+	case "stale.after_days":
+		return setNonNegativeInt(&c.StaleAfterDays, key, value)
+	case "push.auto_setup_upstream":
+		return setBool(&c.PushAutoSetupUpstream, key, value)
+	case "checkout.switch":
+		return setBool(&c.CheckoutSwitch, key, value)
+	case "confirm.merge":
+		return setBool(&c.ConfirmMerge, key, value)
+	case "confirm.protected":
+		return setPatterns(&c.ProtectedBranches, key, value)
+	case "delete.remote":
+		return setBool(&c.DeleteRemote, key, value)
+	case "merge.gpg_sign":
+		return setBool(&c.MergeGPGSign, key, value)
+	case "merge.signoff":
+		return setBool(&c.MergeSignoff, key, value)
+	case "browse.url_template":
+		if err := setString(&c.BrowseURLTemplate, key, value); err != nil {
+			return err
+		}
+		if c.BrowseURLTemplate != "" && !strings.Contains(c.BrowseURLTemplate, "{branch}") {
+			return fmt.Errorf("%s: must contain {branch}", key)
+		}
+		return nil
+	case "create.template":
+		if err := setString(&c.CreateTemplate, key, value); err != nil {
+			return err
+		}
+		if c.CreateTemplate == "" {
+			return nil
+		}
+		if _, err := branchname.Parse(c.CreateTemplate); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	case "diff.tool":
+		return setString(&c.DiffTool, key, value)
+	case "log.file":
+		return setString(&c.LogFile, key, value)
+	case "network.retries":
+		return setNonNegativeInt(&c.NetworkRetries, key, value)
+	case "network.retry_delay_seconds":
+		return setNonNegativeFloat(&c.NetworkRetryDelaySeconds, key, value)
+	case "search.regex":
+		return setBool(&c.SearchRegex, key, value)
+	case "ranking.half_life_days":
+		if err := setNonNegativeFloat(&c.RankingHalfLifeDays, key, value); err != nil {
+			return err
+		}
+		if c.RankingHalfLifeDays == 0 {
+			return fmt.Errorf("%s: must be greater than 0", key)
+		}
+		return nil
+	case "ranking.frequency_weight":
+		return setNonNegativeFloat(&c.RankingFrequencyWeight, key, value)
+	case "ranking.recency_weight":
+		return setNonNegativeFloat(&c.RankingRecencyWeight, key, value)
+	case "ranking.reflog_weight":
+		return setNonNegativeFloat(&c.RankingReflogWeight, key, value)
+	case "ui.border":
+		return setBool(&c.UIBorder, key, value)
+	case "ui.header":
+		if err := setString(&c.UIHeader, key, value); err != nil {
+			return err
+		}
+		if _, err := ui.ParseHeader(c.UIHeader); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	case "ui.density":
+		if err := setString(&c.UIDensity, key, value); err != nil {
+			return err
+		}
+		if _, err := ui.ParseDensity(c.UIDensity); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	case "ui.separators":
+		return setBool(&c.UISeparators, key, value)
+	case "ui.pin_default":
+		return setBool(&c.UIPinDefault, key, value)
+	case "ui.auto_refresh":
+		return setBool(&c.UIAutoRefresh, key, value)
+	case "ui.marker":
+		if err := setString(&c.UIMarker, key, value); err != nil {
+			return err
+		}
+		if strings.IndexFunc(c.UIMarker, unicode.IsControl) != -1 {
+			return fmt.Errorf("%s: must not contain control characters", key)
+		}
+		return nil
+	case "ui.marker_color":
+		if err := setString(&c.UIMarkerColor, key, value); err != nil {
+			return err
+		}
+		if _, err := ui.ParseColor(c.UIMarkerColor); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	case "theme":
+		return setString(&c.Theme, key, value)
+	case "time.format":
+		if err := setString(&c.TimeFormat, key, value); err != nil {
+			return err
+		}
+		if c.TimeFormat == "" {
+			return nil
+		}
+		if _, err := timefmt.Parse(c.TimeFormat); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	default:
+		if name, ok := strings.CutPrefix(key, "pipelines."); ok {
+			return c.setPipeline(key, name, value)
+		}
+		if rest, ok := strings.CutPrefix(key, "actions."); ok {
+			return c.setAction(key, rest, value)
+		}
+		return fmt.Errorf("unknown key %q", key)
+	}
+}
+
+// setPipeline records the steps of the pipeline name, given as an array of
+// non-empty strings.
+func (c *Config) setPipeline(key, name string, value any) error {
+	if name == "" || strings.Contains(name, ".") {
+		return fmt.Errorf("%s: pipeline names must not contain dots", key)
+	}
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return fmt.Errorf("%s: expected a non-empty array of steps", key)
+	}
+	steps := make([]string, len(items))
+	for i, item := range items {
+		step, ok := item.(string)
+		if !ok || strings.TrimSpace(step) == "" {
+			return fmt.Errorf("%s: steps must be non-empty strings", key)
+		}
+		steps[i] = strings.TrimSpace(step)
+	}
+	if c.Pipelines == nil {
+		c.Pipelines = map[string][]string{}
+	}
+	c.Pipelines[name] = steps
+	return nil
+}
+
+// setAction sets one field of a user-defined action; rest is the part of
+// key after "actions.", such as "deploy.command".
+func (c *Config) setAction(key, rest string, value any) error {
+	name, field, ok := strings.Cut(rest, ".")
+	if !ok || name == "" || strings.Contains(field, ".") {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	action := c.Actions[name]
+	var err error
+	switch field {
+	case "command":
+		err = setString(&action.Command, key, value)
+	case "description":
+		err = setString(&action.Description, key, value)
+	case "confirm":
+		err = setBool(&action.Confirm, key, value)
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	if err != nil {
+		return err
+	}
+	if c.Actions == nil {
+		c.Actions = map[string]CustomAction{}
+	}
+	c.Actions[name] = action
+	return nil
+}
+
+// setPatterns sets dst to an array of branch name patterns, which may be
+// empty.
+func setPatterns(dst *[]string, key string, value any) error {
+	items, ok := value.([]any)
+	if !ok {
+		return fmt.Errorf("%s: expected an array of patterns", key)
+	}
+	patterns := make([]string, len(items))
+	for i, item := range items {
+		pattern, ok := item.(string)
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("%s: patterns must be non-empty strings", key)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q", key, pattern)
+		}
+		patterns[i] = strings.TrimSpace(pattern)
+	}
+	*dst = patterns
+	return nil
+}
+
+func setString(dst *string, key string, value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%s: expected a string", key)
+	}
+	*dst = strings.TrimSpace(s)
+	return nil
+}
+
+func setBool(dst *bool, key string, value any) error {
+	b, ok := value.(bool)
+	if !ok {
+		return fmt.Errorf("%s: expected true or false", key)
+	}
+	*dst = b
+	return nil
+}
+
+func setNonNegativeInt(dst *int, key string, value any) error {
+	n, ok := value.(int64)
+	if !ok {
+		return fmt.Errorf("%s: expected an integer", key)
+	}
+	if n < 0 {
+		return fmt.Errorf("%s: must not be negative", key)
+	}
+	*dst = int(n)
+	return nil
+}
+
+func setNonNegativeFloat(dst *float64, key string, value any) error {
+	var f float64
+	switch v := value.(type) {
+	case int64:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return fmt.Errorf("%s: expected a number", key)
+	}
+	if f < 0 {
+		return fmt.Errorf("%s: must not be negative", key)
+	}
+	*dst = f
+	return nil
+}
@@ -0,0 +1,408 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	t.Parallel()
+
+	input := `
+# global settings
+base = "develop" # trailing comment
+name = 'lit#eral'
+count = 1_000
+ratio = 0.5
+enabled = true
+list = ["a", 'b,c', 3]
+
+[section]
+key = "value"
+nested.key = false
+`
+
+	got, err := parse(input)
+	if err != nil {
+		t.Fatalf("parse returned error: %v", err)
+	}
+
+	want := map[string]any{
+		"base":               "develop",
+		"name":               "lit#eral",
+		"count":              int64(1000),
+		"ratio":              0.5,
+		"enabled":            true,
+		"list":               []any{"a", "b,c", int64(3)},
+		"section.key":        "value",
+		"section.nested.key": false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parse() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  string
+	}{
+		"missing-equals":    {input: "base", want: "expected key = value"},
+		"missing-value":     {input: "base =", want: "missing value"},
+		"bad-key":           {input: "ba se = 1", want: "invalid key"},
+		"unterminated":      {input: `base = "main`, want: "unterminated string"},
+		"duplicate":         {input: "base = 'a'\nbase = 'b'", want: "duplicate key"},
+		"bad-header":        {input: "[section", want: "invalid table header"},
+		"array-of-tables":   {input: "[[section]]", want: "invalid table header"},
+		"unsupported-value": {input: "base = main", want: "unsupported value"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parse(tc.input)
+			if err == nil {
+				t.Fatalf("expected error for %q", tc.input)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("unexpected error: got %v, want substring %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func withDefaults(edit func(*Config)) Config {
+	cfg := Default()
+	edit(&cfg)
+	return cfg
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   string
+		want    Config
+		wantErr string
+	}{
+		"empty": {
+			input: "",
+			want:  Default(),
+		},
+		"base": {
+			input: `base = "develop"`,
+			want:  withDefaults(func(c *Config) { c.Base = "develop" }),
+		},
+		"theme": {
+			input: `theme = "nord-light"`,
+			want:  withDefaults(func(c *Config) { c.Theme = "nord-light" }),
+		},
+		"ui-border": {
+			input: "[ui]\nborder = true",
+			want:  withDefaults(func(c *Config) { c.UIBorder = true }),
+		},
+		"ui-pin-default": {
+			input: "[ui]\npin_default = true",
+			want:  withDefaults(func(c *Config) { c.UIPinDefault = true }),
+		},
+		"ui-auto-refresh": {
+			input: "[ui]\nauto_refresh = true",
+			want:  withDefaults(func(c *Config) { c.UIAutoRefresh = true }),
+		},
+		"pipelines": {
+			input: "[pipelines]\nsetup = ['pull', 'make setup']\nfresh = [\"setup\", \"make test\"]",
+			want: withDefaults(func(c *Config) {
+				c.Pipelines = map[string][]string{
+					"setup": {"pull", "make setup"},
+					"fresh": {"setup", "make test"},
+				}
+			}),
+		},
+		"pipeline-not-an-array": {
+			input:   "pipelines.setup = 'pull'",
+			wantErr: "pipelines.setup: expected a non-empty array of steps",
+		},
+		"pipeline-empty-step": {
+			input:   "pipelines.setup = ['pull', ' ']",
+			wantErr: "pipelines.setup: steps must be non-empty strings",
+		},
+		"pipeline-dotted-name": {
+			input:   "pipelines.a.b = ['pull']",
+			wantErr: "pipelines.a.b: pipeline names must not contain dots",
+		},
+		"actions": {
+			input: "[actions.fixup]\ncommand = 'git rebase -i --autosquash {branch}'\ndescription = 'Squash fixups'\nconfirm = true\n[actions.open]\ncommand = 'gh pr view {branch}'",
+			want: withDefaults(func(c *Config) {
+				c.Actions = map[string]CustomAction{
+					"fixup": {Command: "git rebase -i --autosquash {branch}", Description: "Squash fixups", Confirm: true},
+					"open":  {Command: "gh pr view {branch}"},
+				}
+			}),
+		},
+		"action-without-command": {
+			input:   "actions.fixup.description = 'Squash fixups'",
+			wantErr: "actions.fixup.command: must be set",
+		},
+		"action-unknown-field": {
+			input:   "actions.fixup.run = 'make'",
+			wantErr: `unknown key "actions.fixup.run"`,
+		},
+		"action-wrong-type": {
+			input:   "actions.fixup.confirm = 'yes'",
+			wantErr: "actions.fixup.confirm: expected true or false",
+		},
+		"ui-border-wrong-type": {
+			input:   "ui.border = 'rounded'",
+			wantErr: "expected true or false",
+		},
+		"ui-header": {
+			input: "ui.header = 'compact'",
+			want:  withDefaults(func(c *Config) { c.UIHeader = "compact" }),
+		},
+		"ui-header-invalid": {
+			input:   "ui.header = 'tiny'",
+			wantErr: `ui.header: unknown header style "tiny"`,
+		},
+		"ui-density": {
+			input: "[ui]\ndensity = 'cozy'\nseparators = true",
+			want: withDefaults(func(c *Config) {
+				c.UIDensity = "cozy"
+				c.UISeparators = true
+			}),
+		},
+		"ui-density-invalid": {
+			input:   "ui.density = 'dense'",
+			wantErr: `ui.density: unknown density "dense"`,
+		},
+		"ui-marker": {
+			input: "[ui]\nmarker = '➜'\nmarker_color = 'bright-red'",
+			want: withDefaults(func(c *Config) {
+				c.UIMarker = "➜"
+				c.UIMarkerColor = "bright-red"
+			}),
+		},
+		"ui-marker-none": {
+			input: "ui.marker = ''",
+			want:  withDefaults(func(c *Config) { c.UIMarker = "" }),
+		},
+		"ui-marker-control-character": {
+			input:   `ui.marker = "\u001b[5m>"`,
+			wantErr: "must not contain control characters",
+		},
+		"ui-marker-color-invalid": {
+			input:   "ui.marker_color = 'teal'",
+			wantErr: `ui.marker_color: invalid color "teal"`,
+		},
+		"backup-retention": {
+			input: "[backup]\nretention_days = 7",
+			want:  withDefaults(func(c *Config) { c.BackupRetentionDays = 7 }),
+		},
+		"backup-retention-negative": {
+			input:   "backup.retention_days = -1",
+			wantErr: "must not be negative",
+		},
+		"backup-retention-wrong-type": {
+			input:   "backup.retention_days = '7'",
+			wantErr: "expected an integer",
+		},
+		"push-auto-setup-upstream": {
+			input: "[push]\nauto_setup_upstream = true",
+			want:  withDefaults(func(c *Config) { c.PushAutoSetupUpstream = true }),
+		},
+		"push-auto-setup-upstream-wrong-type": {
+			input:   "push.auto_setup_upstream = 'yes'",
+			wantErr: "expected true or false",
+		},
+		"confirm-merge": {
+			input: "[confirm]\nmerge = true",
+			want:  withDefaults(func(c *Config) { c.ConfirmMerge = true }),
+		},
+		"confirm-protected": {
+			input: "[confirm]\nprotected = ['main', 'hotfix/*']",
+			want:  withDefaults(func(c *Config) { c.ProtectedBranches = []string{"main", "hotfix/*"} }),
+		},
+		"confirm-protected-empty": {
+			input: "confirm.protected = []",
+			want:  withDefaults(func(c *Config) { c.ProtectedBranches = []string{} }),
+		},
+		"confirm-protected-invalid-pattern": {
+			input:   "confirm.protected = ['release/[']",
+			wantErr: `confirm.protected: invalid pattern "release/["`,
+		},
+		"delete-remote": {
+			input: "[delete]\nremote = true",
+			want:  withDefaults(func(c *Config) { c.DeleteRemote = true }),
+		},
+		"merge-signing": {
+			input: "[merge]\ngpg_sign = true\nsignoff = true",
+			want:  withDefaults(func(c *Config) { c.MergeGPGSign, c.MergeSignoff = true, true }),
+		},
+		"browse-url-template": {
+			input: "[browse]\nurl_template = 'https://{host}/{path}/src/branch/{branch}'",
+			want:  withDefaults(func(c *Config) { c.BrowseURLTemplate = "https://{host}/{path}/src/branch/{branch}" }),
+		},
+		"browse-url-template-without-branch": {
+			input:   "browse.url_template = 'https://{host}/{path}'",
+			wantErr: "must contain {branch}",
+		},
+		"create-template": {
+			input: "[create]\ntemplate = 'feature/{ticket}-{slug}'",
+			want:  withDefaults(func(c *Config) { c.CreateTemplate = "feature/{ticket}-{slug}" }),
+		},
+		"create-template-unclosed": {
+			input:   "create.template = 'feature/{ticket'",
+			wantErr: "create.template: branch name template \"feature/{ticket\" has an unclosed {",
+		},
+		"diff-tool": {
+			input: "[diff]\ntool = 'delta --side-by-side'",
+			want:  withDefaults(func(c *Config) { c.DiffTool = "delta --side-by-side" }),
+		},
+		"log-file": {
+			input: "[log]\nfile = '/tmp/branch-navigator.log'",
+			want:  withDefaults(func(c *Config) { c.LogFile = "/tmp/branch-navigator.log" }),
+		},
+		"network-retries": {
+			input: "[network]\nretries = 0\nretry_delay_seconds = 0.5",
+			want: withDefaults(func(c *Config) {
+				c.NetworkRetries = 0
+				c.NetworkRetryDelaySeconds = 0.5
+			}),
+		},
+		"network-retries-negative": {
+			input:   "network.retries = -1",
+			wantErr: "network.retries",
+		},
+		"search-regex": {
+			input: "[search]\nregex = true",
+			want:  withDefaults(func(c *Config) { c.SearchRegex = true }),
+		},
+		"stale-after-days": {
+			input: "[stale]\nafter_days = 0",
+			want:  withDefaults(func(c *Config) { c.StaleAfterDays = 0 }),
+		},
+		"ranking": {
+			input: "[ranking]\nhalf_life_days = 3.5\nfrequency_weight = 2\nrecency_weight = 0.5\nreflog_weight = 0",
+			want: withDefaults(func(c *Config) {
+				c.RankingHalfLifeDays = 3.5
+				c.RankingFrequencyWeight = 2
+				c.RankingRecencyWeight = 0.5
+				c.RankingReflogWeight = 0
+			}),
+		},
+		"ranking-half-life-zero": {
+			input:   "ranking.half_life_days = 0",
+			wantErr: "must be greater than 0",
+		},
+		"ranking-weight-negative": {
+			input:   "ranking.frequency_weight = -1.5",
+			wantErr: "must not be negative",
+		},
+		"ranking-weight-wrong-type": {
+			input:   "ranking.recency_weight = 'high'",
+			wantErr: "expected a number",
+		},
+		"time-format": {
+			input: "[time]\nformat = 'short'",
+			want:  withDefaults(func(c *Config) { c.TimeFormat = "short" }),
+		},
+		"time-format-layout": {
+			input: "time.format = 'Jan 2 15:04'",
+			want:  withDefaults(func(c *Config) { c.TimeFormat = "Jan 2 15:04" }),
+		},
+		"time-format-invalid": {
+			input:   "time.format = 'yesterday'",
+			wantErr: `time.format: time format "yesterday" is not relative, short, or a Go time layout`,
+		},
+		"base-wrong-type": {
+			input:   "base = 1",
+			wantErr: "base: expected a string",
+		},
+		"unknown-key": {
+			input:   "colour = 'red'",
+			wantErr: `unknown key "colour"`,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Parse(tc.input)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Parse() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	cfg, err := LoadFile(filepath.Join(dir, "missing.toml"))
+	if err != nil {
+		t.Fatalf("LoadFile returned error for missing file: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("expected default config, got %+v", cfg)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("base = \"trunk\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if cfg.Base != "trunk" {
+		t.Fatalf("expected base trunk, got %q", cfg.Base)
+	}
+
+	if err := os.WriteFile(path, []byte("base ="), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error mentioning %s, got %v", path, err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("BRANCH_NAVIGATOR_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	got, err := Path()
+	if err != nil {
+		t.Fatalf("Path returned error: %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "branch-navigator", "config.toml"); got != want {
+		t.Fatalf("Path() = %q, want %q", got, want)
+	}
+
+	t.Setenv("BRANCH_NAVIGATOR_CONFIG", "/etc/bn.toml")
+	got, err = Path()
+	if err != nil {
+		t.Fatalf("Path returned error: %v", err)
+	}
+	if got != "/etc/bn.toml" {
+		t.Fatalf("Path() = %q, want override", got)
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parse reads the TOML subset supported by the configuration file and returns
+// its values keyed by their fully qualified dotted names.
+//
+// Supported syntax: comments, [table] headers, bare and dotted keys, basic and
+// literal strings, integers, floats, booleans, and single-line arrays.
+func parse(data string) (map[string]any, error) {
+	values := map[string]any{}
+	prefix := ""
+
+	for i, raw := range strings.Split(data, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", lineNo, line)
+			}
+			name, err := parseKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			prefix = name + "."
+			continue
+		}
+
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key, err := parseKey(line[:idx])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		value, err := parseValue(strings.TrimSpace(line[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", lineNo, key, err)
+		}
+
+		key = prefix + key
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// stripComment removes a trailing # comment that is not part of a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseKey(raw string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(raw), ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("invalid key %q", strings.TrimSpace(raw))
+		}
+		for _, r := range part {
+			if !isBareKeyRune(r) {
+				return "", fmt.Errorf("invalid key %q", strings.TrimSpace(raw))
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func isBareKeyRune(r rune) bool {
+	return r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func parseValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case strings.HasPrefix(raw, `"`):
+		if len(raw) < 2 || !strings.HasSuffix(raw, `"`) {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return raw[1 : len(raw)-1], nil
+	case strings.HasPrefix(raw, "["):
+		return parseArray(raw)
+	}
+
+	cleaned := strings.ReplaceAll(raw, "_", "")
+	if n, err := strconv.ParseInt(cleaned, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(cleaned, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", raw)
+}
+
+func parseArray(raw string) ([]any, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("arrays must be written on a single line")
+	}
+	body := strings.TrimSpace(raw[1 : len(raw)-1])
+	items := []any{}
+	for body != "" {
+		end := arrayItemEnd(body)
+		item := strings.TrimSpace(body[:end])
+		if item != "" {
+			value, err := parseValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		if end >= len(body) {
+			break
+		}
+		body = strings.TrimSpace(body[end+1:])
+	}
+	return items, nil
+}
+
+// arrayItemEnd returns the index of the comma terminating the first item in s,
+// or len(s) when the item runs to the end.
+func arrayItemEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Setting is a key of the configuration file together with its value,
+// written as it would be in the file.
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// fields reads the value of every key that is not part of a [pipelines] or
+// [actions.<name>] table.
+var fields = map[string]func(c *Config) any{
+	"base":                        func(c *Config) any { return c.Base },
+	"backup.retention_days":       func(c *Config) any { return c.BackupRetentionDays },
+	"stale.after_days":            func(c *Config) any { return c.StaleAfterDays },
+	"push.auto_setup_upstream":    func(c *Config) any { return c.PushAutoSetupUpstream },
+	"checkout.switch":             func(c *Config) any { return c.CheckoutSwitch },
+	"confirm.merge":               func(c *Config) any { return c.ConfirmMerge },
+	"confirm.protected":           func(c *Config) any { return c.ProtectedBranches },
+	"delete.remote":               func(c *Config) any { return c.DeleteRemote },
+	"merge.gpg_sign":              func(c *Config) any { return c.MergeGPGSign },
+	"merge.signoff":               func(c *Config) any { return c.MergeSignoff },
+	"browse.url_template":         func(c *Config) any { return c.BrowseURLTemplate },
+	"create.template":             func(c *Config) any { return c.CreateTemplate },
+	"diff.tool":                   func(c *Config) any { return c.DiffTool },
+	"log.file":                    func(c *Config) any { return c.LogFile },
+	"network.retries":             func(c *Config) any { return c.NetworkRetries },
+	"network.retry_delay_seconds": func(c *Config) any { return c.NetworkRetryDelaySeconds },
+	"search.regex":                func(c *Config) any { return c.SearchRegex },
+	"ranking.half_life_days":      func(c *Config) any { return c.RankingHalfLifeDays },
+	"ranking.frequency_weight":    func(c *Config) any { return c.RankingFrequencyWeight },
+	"ranking.recency_weight":      func(c *Config) any { return c.RankingRecencyWeight },
+	"ranking.reflog_weight":       func(c *Config) any { return c.RankingReflogWeight },
+	"ui.border":                   func(c *Config) any { return c.UIBorder },
+	"ui.header":                   func(c *Config) any { return c.UIHeader },
+	"ui.density":                  func(c *Config) any { return c.UIDensity },
+	"ui.separators":               func(c *Config) any { return c.UISeparators },
+	"ui.pin_default":              func(c *Config) any { return c.UIPinDefault },
+	"ui.auto_refresh":             func(c *Config) any { return c.UIAutoRefresh },
+	"ui.marker":                   func(c *Config) any { return c.UIMarker },
+	"ui.marker_color":             func(c *Config) any { return c.UIMarkerColor },
+	"theme":                       func(c *Config) any { return c.Theme },
+	"time.format":                 func(c *Config) any { return c.TimeFormat },
+}
+
+// Get returns the value of key in c, written as it would be in the
+// configuration file. Keys of pipelines and actions that are not defined
+// are unknown.
+func (c Config) Get(key string) (string, error) {
+	if field, ok := fields[key]; ok {
+		return encode(field(&c)), nil
+	}
+	if name, ok := strings.CutPrefix(key, "pipelines."); ok {
+		if steps, ok := c.Pipelines[name]; ok {
+			return encode(steps), nil
+		}
+	}
+	if rest, ok := strings.CutPrefix(key, "actions."); ok {
+		name, field, _ := strings.Cut(rest, ".")
+		if action, ok := c.Actions[name]; ok {
+			switch field {
+			case "command":
+				return encode(action.Command), nil
+			case "description":
+				return encode(action.Description), nil
+			case "confirm":
+				return encode(action.Confirm), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown key %q", key)
+}
+
+// Settings returns every setting of c, defaults included, sorted by key.
+func (c Config) Settings() []Setting {
+	settings := make([]Setting, 0, len(fields)+len(c.Pipelines)+3*len(c.Actions))
+	for key, field := range fields {
+		settings = append(settings, Setting{Key: key, Value: encode(field(&c))})
+	}
+	for name, steps := range c.Pipelines {
+		settings = append(settings, Setting{Key: "pipelines." + name, Value: encode(steps)})
+	}
+	for name, action := range c.Actions {
+		prefix := "actions." + name + "."
+		settings = append(settings,
+			Setting{Key: prefix + "command", Value: encode(action.Command)},
+			Setting{Key: prefix + "description", Value: encode(action.Description)},
+			Setting{Key: prefix + "confirm", Value: encode(action.Confirm)},
+		)
+	}
+	sortSettings(settings)
+	return settings
+}
+
+// FileSettings returns the settings written in the configuration file at
+// path, sorted by key. A missing file has none.
+func FileSettings(path string) ([]Setting, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	values, err := parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		settings = append(settings, Setting{Key: key, Value: encode(value)})
+	}
+	sortSettings(settings)
+	return settings, nil
+}
+
+// Set writes key to the configuration file at path, as SetString does.
+// value is taken as is for keys holding text; otherwise it is read as it
+// would be written in the file, and lists may also be given as
+// comma-separated items, as in "main, release/*".
+func Set(path, key, value string) error {
+	encoded, err := encodeInput(key, value)
+	if err != nil {
+		return err
+	}
+	return write(path, key, encoded)
+}
+
+// encodeInput returns value written as it would be in the file for key.
+func encodeInput(key, value string) (string, error) {
+	var current any
+	defaults := Default()
+	switch {
+	case fields[key] != nil:
+		current = fields[key](&defaults)
+	case strings.HasPrefix(key, "pipelines."):
+		current = []string{}
+	case strings.HasPrefix(key, "actions."):
+		current = ""
+		if strings.HasSuffix(key, ".confirm") {
+			current = false
+		}
+	default:
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+
+	switch current.(type) {
+	case string:
+		return strconv.Quote(value), nil
+	case []string:
+		if trimmed := strings.TrimSpace(value); !strings.HasPrefix(trimmed, "[") {
+			items := []string{}
+			for _, item := range strings.Split(trimmed, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			return encode(items), nil
+		}
+	}
+	if _, err := parseValue(strings.TrimSpace(value)); err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// encode writes a value as it would be in the configuration file.
+func encode(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = encode(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sortSettings(settings []Setting) {
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldsAreSettable(t *testing.T) {
+	t.Parallel()
+
+	// Every key Get and Settings report must be one the file accepts, with
+	// the value they report for it.
+	defaults := Default()
+	for _, setting := range defaults.Settings() {
+		cfg, err := Parse(setting.Key + " = " + setting.Value)
+		if err != nil {
+			t.Errorf("Parse(%s = %s) returned error: %v", setting.Key, setting.Value, err)
+			continue
+		}
+		if !reflect.DeepEqual(cfg, defaults) {
+			t.Errorf("%s = %s changed the defaults to %+v", setting.Key, setting.Value, cfg)
+		}
+	}
+}
+
+func TestConfigGet(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse("theme = 'nord'\n[ranking]\nhalf_life_days = 3.5\n[pipelines]\nsetup = ['pull', 'make']\n[actions.deploy]\ncommand = 'make deploy {branch}'\n")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	cases := map[string]string{
+		"theme":                   `"nord"`,
+		"ranking.half_life_days":  "3.5",
+		"backup.retention_days":   "30",
+		"ui.border":               "false",
+		"confirm.protected":       `["main", "master", "release/*"]`,
+		"pipelines.setup":         `["pull", "make"]`,
+		"actions.deploy.command":  `"make deploy {branch}"`,
+		"actions.deploy.confirm":  "false",
+		"actions.deploy.unknown":  "",
+		"pipelines.missing":       "",
+		"no.such_key":             "",
+		"actions.missing.command": "",
+	}
+	for key, want := range cases {
+		got, err := cfg.Get(key)
+		if want == "" {
+			if err == nil || !strings.Contains(err.Error(), "unknown key") {
+				t.Errorf("Get(%q) = %q, %v; want an unknown key error", key, got, err)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	for _, step := range [][2]string{
+		{"theme", "gruvbox"},
+		{"ui.border", "true"},
+		{"stale.after_days", "14"},
+		{"confirm.protected", "main, release/*"},
+		{"pipelines.setup", `["pull", "make setup"]`},
+		{"actions.deploy.command", "make deploy {branch}"},
+		{"actions.deploy.confirm", "true"},
+	} {
+		if err := Set(path, step[0], step[1]); err != nil {
+			t.Fatalf("Set(%s, %s) returned error: %v", step[0], step[1], err)
+		}
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	want := Default()
+	want.Theme = "gruvbox"
+	want.UIBorder = true
+	want.StaleAfterDays = 14
+	want.ProtectedBranches = []string{"main", "release/*"}
+	want.Pipelines = map[string][]string{"setup": {"pull", "make setup"}}
+	want.Actions = map[string]CustomAction{"deploy": {Command: "make deploy {branch}", Confirm: true}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("LoadFile() = %+v, want %+v", cfg, want)
+	}
+
+	for _, bad := range [][2]string{
+		{"colour", "red"},
+		{"ui.border", "yes"},
+		{"stale.after_days", "-1"},
+	} {
+		if err := Set(path, bad[0], bad[1]); err == nil {
+			t.Errorf("Set(%s, %s) succeeded, want an error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestLoadFilesOverrides(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	user := filepath.Join(dir, "config.toml")
+	repo := RepoPath(dir)
+	if err := os.WriteFile(user, []byte("theme = 'nord'\n[stale]\nafter_days = 10\n[actions.deploy]\ncommand = 'make deploy'\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.WriteFile(repo, []byte("theme = 'gruvbox'\n[actions.deploy]\nconfirm = true\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFiles(user, repo, filepath.Join(dir, "missing.toml"))
+	if err != nil {
+		t.Fatalf("LoadFiles returned error: %v", err)
+	}
+	if cfg.Theme != "gruvbox" || cfg.StaleAfterDays != 10 {
+		t.Fatalf("theme = %q, stale.after_days = %d; want the repository's theme and the user's days", cfg.Theme, cfg.StaleAfterDays)
+	}
+	if want := (CustomAction{Command: "make deploy", Confirm: true}); cfg.Actions["deploy"] != want {
+		t.Fatalf("actions.deploy = %+v, want %+v", cfg.Actions["deploy"], want)
+	}
+
+	settings, err := FileSettings(repo)
+	if err != nil {
+		t.Fatalf("FileSettings returned error: %v", err)
+	}
+	want := []Setting{{Key: "actions.deploy.confirm", Value: "true"}, {Key: "theme", Value: `"gruvbox"`}}
+	if !reflect.DeepEqual(settings, want) {
+		t.Fatalf("FileSettings() = %+v, want %+v", settings, want)
+	}
+}
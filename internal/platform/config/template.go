@@ -0,0 +1,175 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// template is the file config init writes: every setting at its default,
+// commented out, with what it does. Lines starting with "#" and a key are
+// settings; uncommenting one keeps the default until its value is changed.
+const template = `# branch-navigator configuration.
+#
+# Every setting is listed at its default and commented out. Uncomment a line
+# and change its value to override it, or run
+#   branch-navigator config set KEY VALUE
+# which edits this file and keeps the comments. "" leaves a setting unset.
+
+# Branch used as the comparison point for merged checks (unset: origin/HEAD,
+# then main or master)
+#base = ""
+
+# Color theme when neither --theme nor BRANCH_NAVIGATOR_THEME is set, such as
+# "nord" or "gruvbox-light" (unset: catppuccin, following the terminal)
+#theme = ""
+
+[backup]
+# Days to keep tips of deleted branches under refs/branch-navigator/backup/
+# (0 = forever)
+#retention_days = 30
+
+[stale]
+# Days without commits after which a branch counts as stale (0 = never)
+#after_days = 90
+
+[push]
+# Add --set-upstream when pushing a branch that has no upstream yet, tracking
+# it on the branch's configured or default remote
+#auto_setup_upstream = false
+
+[checkout]
+# Check out with git switch (git 2.23 or newer), which picks the remote named
+# by git's checkout.defaultRemote when a branch exists on several remotes
+#switch = false
+
+[confirm]
+# Show "Merge <source> → <target>" and ask for y before -m merges
+#merge = false
+# Branches into which -m merges only after you type the branch name ([] = none;
+# a repository's branch-navigator.protectedBranches git setting takes
+# precedence)
+#protected = ["main", "master", "release/*"]
+
+[delete]
+# Also delete the upstream of the same name, such as origin/feature/x, when -d
+# deletes a branch (after confirming; protected branches are never deleted)
+#remote = false
+
+[merge]
+# Sign the merge commits of -m with GPG (same as -S)
+#gpg_sign = false
+# Add a Signed-off-by trailer to the merge commits of -m (same as --signoff)
+#signoff = false
+
+[create]
+# Name of the branches made by --create, such as "feature/{ticket}-{slug}";
+# the form asks for every {placeholder} (unset: ask for the whole name; a
+# repository's branch-navigator.createTemplate git setting takes precedence)
+#template = ""
+
+[browse]
+# URL of a branch on a self-hosted code host for --browse, such as
+# "https://{host}/{path}/src/branch/{branch}"; {host}, {path}, and {branch}
+# are filled in (unset: GitHub, GitLab, and Bitbucket are known)
+#url_template = ""
+
+[diff]
+# Formatter for --diff: a tool that reads a diff on stdin, such as "delta" or
+# "diff-so-fancy", or "difftastic", which git runs as its external diff
+# (unset: git's own diff)
+#tool = ""
+
+[log]
+# File to append JSON logs of git commands, their durations, renders, and
+# errors to for bug reports (BRANCH_NAVIGATOR_LOG takes precedence; unset: no
+# logging)
+#file = ""
+
+[network]
+# Retries of fetch, prune, and push after transient failures such as timeouts
+# or reset connections (0 = never)
+#retries = 2
+# Seconds before the first retry; the wait doubles for every further retry
+#retry_delay_seconds = 1
+
+[search]
+# Treat filter queries as regular expressions instead of fuzzy patterns (same
+# as --regex)
+#regex = false
+
+[ui]
+# Draw a rounded border around the selector, with the action name as its title
+#border = false
+# Header above the list: "full" (action and description), "compact" (one line
+# with the action and repository), or "none" (same as --no-header)
+#header = "full"
+# Spacing: "comfortable" keeps blank lines around the list, "compact" drops
+# them so more branches fit
+#density = "comfortable"
+# Draw thin rules instead of the blank lines in the comfortable density
+#separators = false
+# Cursor marker before the highlighted row ("" highlights the whole row instead)
+#marker = ">"
+# Marker color: a name such as "red" or "bright-cyan", a 256-color index, or
+# "#rrggbb" (unset: the theme's)
+#marker_color = ""
+# Keep the default branch (origin/HEAD) right below the current branch instead
+# of in recency order
+#pin_default = false
+# Refresh the list while it is open whenever branches change outside of it
+# (same as --watch)
+#auto_refresh = false
+
+[ranking]
+# How the list blends checkout frequency, checkout recency, and reflog order.
+# Age in days at which a checkout's recency counts half
+#half_life_days = 7
+# Weight of checkouts relative to your most used branch
+#frequency_weight = 0
+# Weight of recency: 1 for a checkout just now, halving every half_life_days
+#recency_weight = 0
+# Weight of the reflog: 1 for the latest reflog entry, falling toward 0
+#reflog_weight = 1
+
+[time]
+# How dates are shown: "relative" (3h ago), "short" (2024-05-01), or a Go time
+# layout such as "Jan 2 15:04" (unset: relative labels in the selector and
+# 2006-01-02 15:04 in history)
+#format = ""
+
+# Pipelines name lists of follow-up steps that --then accepts, for example:
+# [pipelines]
+# setup = ["pull", "make setup"]
+
+# Actions add commands to --action and the --menu; {branch} is replaced by the
+# selected branch, for example:
+# [actions.deploy]
+# command = "make deploy BRANCH={branch}"
+# description = "deploy the branch to staging"
+# confirm = true
+`
+
+// WriteTemplate writes a configuration file to path that lists every
+// setting at its default, commented out. An existing file is only replaced
+// when force is set; otherwise the error matches fs.ErrExist.
+func WriteTemplate(path string, force bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if !force {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteString(template); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(template), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
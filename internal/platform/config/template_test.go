@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// templateSetting matches a commented-out setting of the template.
+var templateSetting = regexp.MustCompile(`^#([a-z_]+) = `)
+
+func TestTemplateListsEveryDefault(t *testing.T) {
+	t.Parallel()
+
+	if cfg, err := Parse(template); err != nil || !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("Parse(template) = %+v, %v; want the defaults", cfg, err)
+	}
+
+	// Uncommenting every setting must keep the defaults, and cover every key.
+	lines := strings.Split(template, "\n")
+	table := ""
+	seen := map[string]bool{}
+	for i, line := range lines {
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[]") + "."
+			continue
+		}
+		if m := templateSetting.FindStringSubmatch(line); m != nil {
+			lines[i] = strings.TrimPrefix(line, "#")
+			seen[table+m[1]] = true
+		}
+	}
+	cfg, err := Parse(strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatalf("Parse(uncommented template) returned error: %v", err)
+	}
+	want := Default()
+	// Named the way the comments explain them; empty means the same.
+	want.UIHeader = "full"
+	want.UIDensity = "comfortable"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("uncommented template = %+v, want %+v", cfg, want)
+	}
+	for key := range fields {
+		if !seen[key] {
+			t.Errorf("template does not list %s", key)
+		}
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "branch-navigator", "config.toml")
+	if err := WriteTemplate(path, false); err != nil {
+		t.Fatalf("WriteTemplate returned error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("theme = 'nord'\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := WriteTemplate(path, false); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("WriteTemplate over an existing file returned %v, want fs.ErrExist", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "theme = 'nord'\n" {
+		t.Fatalf("existing file was changed: %q", data)
+	}
+	if err := WriteTemplate(path, true); err != nil {
+		t.Fatalf("WriteTemplate with force returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != template {
+		t.Fatalf("forced write left %q", data)
+	}
+}
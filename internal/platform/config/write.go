@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SetString writes key = value to the configuration file at path. An existing
+// assignment of key is replaced in place, as is a commented-out one such as
+// "#theme = """; otherwise the key is added before the first table so the
+// rest of the file, comments included, is kept. The file is
+// created when missing, and the result must still be a valid configuration.
+func SetString(path, key, value string) error {
+	return write(path, key, strconv.Quote(value))
+}
+
+// write sets key to the encoded value in the configuration file at path, as
+// SetString describes. The settings of the file are checked on their own,
+// since an action may be completed by another file.
+func write(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	updated, err := assign(string(data), key, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	check := Default()
+	if err := check.merge(updated); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(updated), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// assign returns data with the fully qualified key set to the encoded value.
+func assign(data, key, value string) (string, error) {
+	lines := strings.Split(data, "\n")
+	prefix := ""
+	firstTable := -1
+	// commented is the line of a commented-out assignment of key, such as
+	// those WriteTemplate writes, taken over when key is not set.
+	commented, commentedLocal := -1, ""
+	for i, raw := range lines {
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			if local, ok := commentedAssignment(raw); ok && commented == -1 && prefix+local == key {
+				commented, commentedLocal = i, local
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, err := parseKey(strings.Trim(line, "[]"))
+			if err != nil {
+				return "", fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if firstTable == -1 {
+				firstTable = i
+			}
+			prefix = name + "."
+			continue
+		}
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			continue
+		}
+		local, err := parseKey(line[:idx])
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if prefix+local == key {
+			lines[i] = strings.TrimSpace(line[:idx]) + " = " + value
+			return strings.Join(lines, "\n"), nil
+		}
+	}
+
+	if commented != -1 {
+		lines[commented] = commentedLocal + " = " + value
+		return strings.Join(lines, "\n"), nil
+	}
+	assignment := key + " = " + value
+	if firstTable == -1 {
+		data = strings.TrimRight(data, "\n")
+		if data == "" {
+			return assignment + "\n", nil
+		}
+		return data + "\n" + assignment + "\n", nil
+	}
+	lines = append(lines[:firstTable], append([]string{assignment, ""}, lines[firstTable:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// commentedAssignment returns the key of a commented-out assignment such as
+// "#retention_days = 30". Prose comments, which start with "# ", are not
+// assignments.
+func commentedAssignment(raw string) (string, bool) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(raw), "#")
+	if !ok || body == "" || body[0] == ' ' || body[0] == '\t' {
+		return "", false
+	}
+	idx := strings.Index(body, "=")
+	if idx == -1 {
+		return "", false
+	}
+	local, err := parseKey(body[:idx])
+	if err != nil {
+		return "", false
+	}
+	return local, true
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		data string
+		key  string
+		want string
+	}{
+		"empty file": {
+			data: "",
+			key:  "theme",
+			want: "theme = \"nord\"\n",
+		},
+		"appended without tables": {
+			data: "base = \"main\"\n",
+			key:  "theme",
+			want: "base = \"main\"\ntheme = \"nord\"\n",
+		},
+		"replaced in place": {
+			data: "# colors\ntheme = 'gruvbox' # warm\nbase = \"main\"\n",
+			key:  "theme",
+			want: "# colors\ntheme = \"nord\"\nbase = \"main\"\n",
+		},
+		"added before the first table": {
+			data: "base = \"main\"\n\n[stale]\nafter_days = 30\n",
+			key:  "theme",
+			want: "base = \"main\"\n\ntheme = \"nord\"\n\n[stale]\nafter_days = 30\n",
+		},
+		"replaced inside a table": {
+			data: "[time]\nformat = \"short\"\n",
+			key:  "time.format",
+			want: "[time]\nformat = \"nord\"\n",
+		},
+		"commented default taken over": {
+			data: "[time]\n# How dates are shown\n#format = \"\"\n",
+			key:  "time.format",
+			want: "[time]\n# How dates are shown\nformat = \"nord\"\n",
+		},
+		"commented default of another table": {
+			data: "[time]\n#format = \"\"\n[log]\n# format = \"\"\n",
+			key:  "log.format",
+			want: "log.format = \"nord\"\n\n[time]\n#format = \"\"\n[log]\n# format = \"\"\n",
+		},
+		"table key outside the table": {
+			data: "[stale]\nformat = 1\n",
+			key:  "format",
+			want: "format = \"nord\"\n\n[stale]\nformat = 1\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := assign(tc.data, tc.key, `"nord"`)
+			if err != nil {
+				t.Fatalf("assign returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("assign() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetString(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "branch-navigator", "config.toml")
+	if err := SetString(path, "theme", "solarized-light"); err != nil {
+		t.Fatalf("SetString returned error: %v", err)
+	}
+	if err := SetString(path, "theme", "one-dark"); err != nil {
+		t.Fatalf("SetString returned error: %v", err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if cfg.Theme != "one-dark" {
+		t.Fatalf("Theme = %q, want one-dark", cfg.Theme)
+	}
+
+	if err := os.WriteFile(path, []byte("colour = 'red'\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := SetString(path, "theme", "nord"); err == nil || !strings.Contains(err.Error(), `unknown key "colour"`) {
+		t.Fatalf("expected the invalid file to be rejected, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if string(data) != "colour = 'red'\n" {
+		t.Fatalf("invalid file was rewritten: %q", data)
+	}
+}
@@ -0,0 +1,291 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"branch-navigator/internal/platform/jsonfile"
+	"branch-navigator/internal/platform/xdg"
+)
+
+const fileName = "history.jsonl"
+
+// MaxEntries bounds the number of entries kept in the store; older entries are
+// discarded when the file is compacted.
+const MaxEntries = 5000
+
+// Entry records one action performed through the navigator.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo"`
+	Action string    `json:"action"`
+	From   string    `json:"from,omitempty"`
+	Branch string    `json:"branch"`
+}
+
+// RepoVisit summarizes when a repository was last used.
+type RepoVisit struct {
+	Path        string
+	LastVisited time.Time
+}
+
+// Store persists entries as JSON lines in a single file.
+type Store struct {
+	path  string
+	limit int
+}
+
+// DefaultPath returns the history file location inside the XDG state directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Open returns a Store backed by the file at path. The file is created lazily.
+func Open(path string) *Store {
+	return &Store{path: path, limit: MaxEntries}
+}
+
+// OpenDefault returns a Store backed by DefaultPath.
+func OpenDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Append adds entry to the store, compacting the file once it exceeds MaxEntries.
+func (s *Store) Append(entry Entry) error {
+	if s == nil || s.path == "" {
+		return errors.New("history store is not configured")
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	entries, err := s.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) > s.limit {
+		return s.write(entries[len(entries)-s.limit:])
+	}
+	return nil
+}
+
+// Entries returns all stored entries, oldest first. A missing file yields no entries.
+func (s *Store) Entries() ([]Entry, error) {
+	if s == nil || s.path == "" {
+		return nil, errors.New("history store is not configured")
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("%s: line %d: %w", s.path, lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries []Entry) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return jsonfile.WriteFile(s.path, buf.Bytes())
+}
+
+// Repos returns the distinct repositories found in entries, most recently visited first.
+func Repos(entries []Entry) []RepoVisit {
+	latest := map[string]time.Time{}
+	for _, entry := range entries {
+		if entry.Repo == "" {
+			continue
+		}
+		if visited, ok := latest[entry.Repo]; !ok || entry.Time.After(visited) {
+			latest[entry.Repo] = entry.Time
+		}
+	}
+
+	visits := make([]RepoVisit, 0, len(latest))
+	for path, visited := range latest {
+		visits = append(visits, RepoVisit{Path: path, LastVisited: visited})
+	}
+	sort.Slice(visits, func(i, j int) bool {
+		if visits[i].LastVisited.Equal(visits[j].LastVisited) {
+			return visits[i].Path < visits[j].Path
+		}
+		return visits[i].LastVisited.After(visits[j].LastVisited)
+	})
+	return visits
+}
+
+// RepoStats counts the actions recorded in one repository.
+type RepoStats struct {
+	Path     string `json:"path"`
+	Switches int    `json:"switches"`
+	Actions  int    `json:"actions"`
+}
+
+// BranchStats counts the actions that targeted one branch.
+type BranchStats struct {
+	Repo   string `json:"repo"`
+	Branch string `json:"branch"`
+	Count  int    `json:"count"`
+}
+
+// ActionStats counts how often one action was used.
+type ActionStats struct {
+	Action string `json:"action"`
+	Count  int    `json:"count"`
+}
+
+// Stats summarizes a set of entries, each list ordered by descending count.
+type Stats struct {
+	Repos    []RepoStats   `json:"repos"`
+	Branches []BranchStats `json:"branches"`
+	Actions  []ActionStats `json:"actions"`
+}
+
+// switchAction is the action name recorded for checkouts.
+const switchAction = "checkout"
+
+// Summarize aggregates entries into per-repository, per-branch, and per-action
+// counts, keeping at most branchLimit branches (all of them when branchLimit is 0).
+func Summarize(entries []Entry, branchLimit int) Stats {
+	repos := map[string]*RepoStats{}
+	branches := map[[2]string]*BranchStats{}
+	actions := map[string]*ActionStats{}
+	for _, entry := range entries {
+		repo, ok := repos[entry.Repo]
+		if !ok {
+			repo = &RepoStats{Path: entry.Repo}
+			repos[entry.Repo] = repo
+		}
+		repo.Actions++
+		if entry.Action == switchAction {
+			repo.Switches++
+		}
+
+		if entry.Branch != "" {
+			key := [2]string{entry.Repo, entry.Branch}
+			branch, ok := branches[key]
+			if !ok {
+				branch = &BranchStats{Repo: entry.Repo, Branch: entry.Branch}
+				branches[key] = branch
+			}
+			branch.Count++
+		}
+
+		action, ok := actions[entry.Action]
+		if !ok {
+			action = &ActionStats{Action: entry.Action}
+			actions[entry.Action] = action
+		}
+		action.Count++
+	}
+
+	stats := Stats{
+		Repos:    make([]RepoStats, 0, len(repos)),
+		Branches: make([]BranchStats, 0, len(branches)),
+		Actions:  make([]ActionStats, 0, len(actions)),
+	}
+	for _, repo := range repos {
+		stats.Repos = append(stats.Repos, *repo)
+	}
+	for _, branch := range branches {
+		stats.Branches = append(stats.Branches, *branch)
+	}
+	for _, action := range actions {
+		stats.Actions = append(stats.Actions, *action)
+	}
+
+	sort.Slice(stats.Repos, func(i, j int) bool {
+		a, b := stats.Repos[i], stats.Repos[j]
+		if a.Actions != b.Actions {
+			return a.Actions > b.Actions
+		}
+		return a.Path < b.Path
+	})
+	sort.Slice(stats.Branches, func(i, j int) bool {
+		a, b := stats.Branches[i], stats.Branches[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Branch < b.Branch
+	})
+	sort.Slice(stats.Actions, func(i, j int) bool {
+		a, b := stats.Actions[i], stats.Actions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Action < b.Action
+	})
+	if branchLimit > 0 && len(stats.Branches) > branchLimit {
+		stats.Branches = stats.Branches[:branchLimit]
+	}
+	return stats
+}
@@ -0,0 +1,251 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreAppendAndEntries(t *testing.T) {
+	t.Parallel()
+
+	store := Open(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("Entries on missing file returned error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries, got %v", entries)
+	}
+
+	first := Entry{Time: time.Unix(100, 0).UTC(), Repo: "/src/a", Action: "checkout", From: "main", Branch: "feature/x"}
+	second := Entry{Time: time.Unix(200, 0).UTC(), Repo: "/src/b", Action: "merge", Branch: "topic"}
+	for _, entry := range []Entry{first, second} {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	entries, err = store.Entries()
+	if err != nil {
+		t.Fatalf("Entries returned error: %v", err)
+	}
+	if want := []Entry{first, second}; !reflect.DeepEqual(entries, want) {
+		t.Fatalf("Entries() = %+v, want %+v", entries, want)
+	}
+}
+
+func TestStoreCompacts(t *testing.T) {
+	t.Parallel()
+
+	store := Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	store.limit = 5
+	for i := 0; i < store.limit+3; i++ {
+		if err := store.Append(Entry{Time: time.Unix(int64(i), 0).UTC(), Repo: "/src/a", Action: "checkout", Branch: "b"}); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("Entries returned error: %v", err)
+	}
+	if len(entries) != store.limit {
+		t.Fatalf("expected %d entries after compaction, got %d", store.limit, len(entries))
+	}
+	if got := entries[0].Time.Unix(); got != 3 {
+		t.Fatalf("expected oldest entries to be dropped, first entry time %d", got)
+	}
+}
+
+func TestStoreRejectsCorruptLines(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+	if _, err := Open(path).Entries(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected line error, got %v", err)
+	}
+}
+
+func TestStoreTrimsAtLimit(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		appends   int
+		wantFirst int64
+		wantLines int
+	}{
+		"below limit":  {appends: 2, wantFirst: 0, wantLines: 2},
+		"at limit":     {appends: 3, wantFirst: 0, wantLines: 3},
+		"one over":     {appends: 4, wantFirst: 1, wantLines: 3},
+		"several over": {appends: 7, wantFirst: 4, wantLines: 3},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "history.jsonl")
+			store := Open(path)
+			store.limit = 3
+			for i := 0; i < tc.appends; i++ {
+				if err := store.Append(Entry{Time: time.Unix(int64(i), 0).UTC(), Repo: "/src/a", Action: "checkout", Branch: "b"}); err != nil {
+					t.Fatalf("Append returned error: %v", err)
+				}
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lines := strings.Count(string(data), "\n"); lines != tc.wantLines {
+				t.Fatalf("file has %d lines, want %d", lines, tc.wantLines)
+			}
+			entries, err := store.Entries()
+			if err != nil {
+				t.Fatalf("Entries returned error: %v", err)
+			}
+			if got := entries[0].Time.Unix(); got != tc.wantFirst {
+				t.Fatalf("first entry time = %d, want %d", got, tc.wantFirst)
+			}
+			if files, err := os.ReadDir(filepath.Dir(path)); err != nil || len(files) != 1 {
+				t.Fatalf("temporary file left behind: %v, %v", files, err)
+			}
+		})
+	}
+}
+
+func TestStoreUnreadableFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	notDir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	corrupt := filepath.Join(dir, "corrupt.jsonl")
+	if err := os.WriteFile(corrupt, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+	long := filepath.Join(dir, "long.jsonl")
+	if err := os.WriteFile(long, []byte(strings.Repeat("x", 2*1024*1024)+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	cases := map[string]struct {
+		store       *Store
+		wantEntries string
+		wantAppend  string
+	}{
+		"nil store":         {store: nil, wantEntries: "not configured", wantAppend: "not configured"},
+		"empty path":        {store: Open(""), wantEntries: "not configured", wantAppend: "not configured"},
+		"path is directory": {store: Open(dir), wantEntries: "is a directory", wantAppend: "is a directory"},
+		"parent is a file":  {store: Open(filepath.Join(notDir, "history.jsonl")), wantEntries: "not a directory", wantAppend: "not a directory"},
+		"corrupt line":      {store: Open(corrupt), wantEntries: "line 1", wantAppend: "line 1"},
+		"line too long":     {store: Open(long), wantEntries: "token too long", wantAppend: "token too long"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := tc.store.Entries(); err == nil || !strings.Contains(err.Error(), tc.wantEntries) {
+				t.Fatalf("Entries error = %v, want it to contain %q", err, tc.wantEntries)
+			}
+			if err := tc.store.Append(Entry{Repo: "/src/a", Action: "checkout", Branch: "b"}); err == nil || !strings.Contains(err.Error(), tc.wantAppend) {
+				t.Fatalf("Append error = %v, want it to contain %q", err, tc.wantAppend)
+			}
+		})
+	}
+}
+
+func TestOpenDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BRANCH_NAVIGATOR_STATE_DIR", dir)
+
+	store, err := OpenDefault()
+	if err != nil {
+		t.Fatalf("OpenDefault returned error: %v", err)
+	}
+	if want := filepath.Join(dir, "history.jsonl"); store.Path() != want {
+		t.Fatalf("Path() = %q, want %q", store.Path(), want)
+	}
+	if (*Store)(nil).Path() != "" {
+		t.Fatal("Path() of a nil store is not empty")
+	}
+}
+
+func TestRepos(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{Time: time.Unix(100, 0), Repo: "/src/a"},
+		{Time: time.Unix(300, 0), Repo: "/src/b"},
+		{Time: time.Unix(200, 0), Repo: "/src/a"},
+		{Time: time.Unix(400, 0), Repo: ""},
+	}
+
+	got := Repos(entries)
+	want := []RepoVisit{
+		{Path: "/src/b", LastVisited: time.Unix(300, 0)},
+		{Path: "/src/a", LastVisited: time.Unix(200, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Repos() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{Repo: "/src/a", Action: "checkout", Branch: "feature/x"},
+		{Repo: "/src/a", Action: "checkout", Branch: "main"},
+		{Repo: "/src/a", Action: "checkout", Branch: "feature/x"},
+		{Repo: "/src/a", Action: "merge", Branch: "feature/x"},
+		{Repo: "/src/b", Action: "delete", Branch: "old"},
+		{Repo: "/src/b", Action: "checkout", Branch: "main"},
+	}
+
+	got := Summarize(entries, 0)
+	want := Stats{
+		Repos: []RepoStats{
+			{Path: "/src/a", Switches: 3, Actions: 4},
+			{Path: "/src/b", Switches: 1, Actions: 2},
+		},
+		Branches: []BranchStats{
+			{Repo: "/src/a", Branch: "feature/x", Count: 3},
+			{Repo: "/src/a", Branch: "main", Count: 1},
+			{Repo: "/src/b", Branch: "main", Count: 1},
+			{Repo: "/src/b", Branch: "old", Count: 1},
+		},
+		Actions: []ActionStats{
+			{Action: "checkout", Count: 4},
+			{Action: "delete", Count: 1},
+			{Action: "merge", Count: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Summarize() = %+v, want %+v", got, want)
+	}
+
+	if limited := Summarize(entries, 2); len(limited.Branches) != 2 || limited.Branches[0].Branch != "feature/x" {
+		t.Fatalf("Summarize with limit = %+v", limited.Branches)
+	}
+
+	empty := Summarize(nil, 5)
+	if len(empty.Repos) != 0 || len(empty.Branches) != 0 || len(empty.Actions) != 0 {
+		t.Fatalf("Summarize(nil) = %+v, want empty lists", empty)
+	}
+}
@@ -0,0 +1,84 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	want := map[string]int{"a": 1, "b": 2}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	got := map[string]int{}
+	if err := Load(path, &got); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Load() = %v, want %v", got, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("file mode = %v, want 0600", perm)
+	}
+	if files, err := os.ReadDir(filepath.Dir(path)); err != nil || len(files) != 1 {
+		t.Fatalf("directory holds %v, %v; want only the saved file", files, err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Parallel()
+
+	got := map[string]int{"kept": 1}
+	if err := Load(filepath.Join(t.TempDir(), "missing.json"), &got); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]int{"kept": 1}) {
+		t.Fatalf("Load() changed the value to %v", got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notDir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		run  func() error
+		want string
+	}{
+		"corrupt file":     {run: func() error { return Load(corrupt, &map[string]int{}) }, want: corrupt + ": unexpected end of JSON input"},
+		"unreadable path":  {run: func() error { return Load(dir, &map[string]int{}) }, want: "is a directory"},
+		"parent is a file": {run: func() error { return Save(filepath.Join(notDir, "state.json"), 1) }, want: "not a directory"},
+		"not encodable":    {run: func() error { return Save(filepath.Join(dir, "func.json"), func() {}) }, want: "unsupported type"},
+		"path is a dir":    {run: func() error { return WriteFile(dir, nil) }, want: "file exists"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tc.run(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package logfile
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvVar names the environment variable that sets the log file. It takes
+// precedence over the log.file setting.
+const EnvVar = "BRANCH_NAVIGATOR_LOG"
+
+// Path returns the log file chosen by EnvVar or, when it is unset,
+// configured; "" means logging is off.
+func Path(getenv func(string) string, configured string) string {
+	if path := strings.TrimSpace(getenv(EnvVar)); path != "" {
+		return path
+	}
+	return strings.TrimSpace(configured)
+}
+
+// Open appends JSON log records to the file at path, creating it and its
+// directory when needed. Records are written as they are logged, so the
+// file is complete even when the process exits without closing it. An
+// empty path returns a nil Logger, which callers treat as logging off.
+func Open(path string) (*slog.Logger, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	handler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(handler).With("pid", os.Getpid()), nil
+}
@@ -0,0 +1,79 @@
+package logfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		env        string
+		configured string
+		want       string
+	}{
+		"off":                   {},
+		"configured":            {configured: "/tmp/nav.log", want: "/tmp/nav.log"},
+		"environment overrides": {env: " /var/log/nav.json ", configured: "/tmp/nav.log", want: "/var/log/nav.json"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := func(key string) string {
+				if key == EnvVar {
+					return tc.env
+				}
+				return ""
+			}
+			if got := Path(getenv, tc.configured); got != tc.want {
+				t.Fatalf("Path() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "logs", "nav.log")
+	for i := 0; i < 2; i++ {
+		logger, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open returned error: %v", err)
+		}
+		logger.Debug("git", "args", []string{"status"})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected both records to be appended, got %q", data)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["msg"] != "git" || record["level"] != "DEBUG" || record["pid"] == nil {
+		t.Fatalf("unexpected record %v", record)
+	}
+}
+
+func TestOpenWithoutPath(t *testing.T) {
+	t.Parallel()
+
+	logger, err := Open("")
+	if err != nil || logger != nil {
+		t.Fatalf("Open(\"\") = %v, %v; want nil, nil", logger, err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package metacache keeps the branch metadata that is slow to compute, such
+// as merged state and commit counts, between runs. Each branch's entry is
+// keyed by the commits its metadata depends on, so an entry is reused only
+// while the branch, its upstream, and the base branch are where they were.
+package metacache
+
+import (
+	"errors"
+	"path/filepath"
+
+	"branch-navigator/internal/platform/jsonfile"
+	"branch-navigator/internal/platform/xdg"
+)
+
+const fileName = "metadata.json"
+
+// Key holds the commits a branch's metadata was computed from. Upstream is
+// empty for a branch without one, and Base when no base branch is known.
+type Key struct {
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream,omitempty"`
+	Base     string `json:"base,omitempty"`
+}
+
+// Entry is the metadata computed for a branch.
+type Entry struct {
+	// Merged reports whether the branch is merged into the base.
+	Merged bool `json:"merged,omitempty"`
+	// Commits counts the commits the branch has that the base lacks.
+	Commits int `json:"commits,omitempty"`
+	// Ahead and Behind count the commits not yet pushed and not yet pulled;
+	// Gone reports that the upstream no longer exists.
+	Ahead  int  `json:"ahead,omitempty"`
+	Behind int  `json:"behind,omitempty"`
+	Gone   bool `json:"gone,omitempty"`
+}
+
+// Record is an Entry together with the Key it is valid for.
+type Record struct {
+	Key   Key   `json:"key"`
+	Entry Entry `json:"entry"`
+}
+
+// Store persists the records of each repository, by branch name, in a
+// single JSON file.
+type Store struct {
+	path string
+}
+
+// DefaultPath returns the cache file location inside the XDG state directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Open returns a Store backed by the file at path. The file is created lazily.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// OpenDefault returns a Store backed by DefaultPath.
+func OpenDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
+
+// Lookup returns the entries saved for repo when there is one for every
+// branch in keys with the same key, and reports whether there was. A
+// single moved ref makes the whole lookup miss, since the metadata is
+// computed for every branch at once.
+func (s *Store) Lookup(repo string, keys map[string]Key) (map[string]Entry, bool, error) {
+	all, err := s.read()
+	if err != nil {
+		return nil, false, err
+	}
+	saved := all[repo]
+	entries := make(map[string]Entry, len(keys))
+	for name, key := range keys {
+		record, ok := saved[name]
+		if !ok || record.Key != key {
+			return nil, false, nil
+		}
+		entries[name] = record.Entry
+	}
+	return entries, true, nil
+}
+
+// Save replaces the records of repo with entries under keys. Branches
+// without an entry are saved with the zero Entry.
+func (s *Store) Save(repo string, keys map[string]Key, entries map[string]Entry) error {
+	if repo == "" {
+		return errors.New("repository path is required")
+	}
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	records := make(map[string]Record, len(keys))
+	for name, key := range keys {
+		records[name] = Record{Key: key, Entry: entries[name]}
+	}
+	all[repo] = records
+	return jsonfile.Save(s.path, all)
+}
+
+func (s *Store) read() (map[string]map[string]Record, error) {
+	if s == nil || s.path == "" {
+		return nil, errors.New("metadata cache is not configured")
+	}
+	all := map[string]map[string]Record{}
+	if err := jsonfile.Load(s.path, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,195 @@
+package metacache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStoreLookup(t *testing.T) {
+	t.Parallel()
+
+	keys := map[string]Key{
+		"main":      {Branch: "aaa", Upstream: "bbb", Base: "aaa"},
+		"feature/x": {Branch: "ccc", Base: "aaa"},
+	}
+	entries := map[string]Entry{"feature/x": {Commits: 2, Ahead: 1}}
+
+	cases := map[string]struct {
+		lookup map[string]Key
+		hit    bool
+	}{
+		"unchanged refs":     {lookup: keys, hit: true},
+		"subset of branches": {lookup: map[string]Key{"feature/x": keys["feature/x"]}, hit: true},
+		"branch moved": {lookup: map[string]Key{
+			"main":      keys["main"],
+			"feature/x": {Branch: "ddd", Base: "aaa"},
+		}},
+		"upstream moved": {lookup: map[string]Key{
+			"main":      {Branch: "aaa", Upstream: "eee", Base: "aaa"},
+			"feature/x": keys["feature/x"],
+		}},
+		"base moved": {lookup: map[string]Key{
+			"main":      keys["main"],
+			"feature/x": {Branch: "ccc", Base: "fff"},
+		}},
+		"upstream gained": {lookup: map[string]Key{
+			"main":      keys["main"],
+			"feature/x": {Branch: "ccc", Upstream: "ccc", Base: "aaa"},
+		}},
+		"upstream removed": {lookup: map[string]Key{
+			"main":      {Branch: "aaa", Base: "aaa"},
+			"feature/x": keys["feature/x"],
+		}},
+		"base unknown": {lookup: map[string]Key{
+			"main":      keys["main"],
+			"feature/x": {Branch: "ccc"},
+		}},
+		"new branch": {lookup: map[string]Key{
+			"main":      keys["main"],
+			"feature/x": keys["feature/x"],
+			"feature/y": {Branch: "ccc", Base: "aaa"},
+		}},
+	}
+
+	store := Open(filepath.Join(t.TempDir(), "nested", "metadata.json"))
+	if _, hit, err := store.Lookup("/src/a", keys); err != nil || hit {
+		t.Fatalf("Lookup on missing file = %v, %v; want a miss", hit, err)
+	}
+	if err := store.Save("/src/a", keys, entries); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if _, hit, err := store.Lookup("/src/other", keys); err != nil || hit {
+		t.Fatalf("Lookup of another repository = %v, %v; want a miss", hit, err)
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, hit, err := store.Lookup("/src/a", tc.lookup)
+			if err != nil {
+				t.Fatalf("Lookup returned error: %v", err)
+			}
+			if hit != tc.hit {
+				t.Fatalf("Lookup hit = %v, want %v", hit, tc.hit)
+			}
+			if !hit {
+				return
+			}
+			want := map[string]Entry{}
+			for name := range tc.lookup {
+				want[name] = entries[name]
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Lookup() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestStoreSaveReplacesRepository(t *testing.T) {
+	t.Parallel()
+
+	store := Open(filepath.Join(t.TempDir(), "metadata.json"))
+	old := map[string]Key{"gone": {Branch: "aaa"}}
+	if err := store.Save("/src/a", old, nil); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	other := map[string]Key{"main": {Branch: "bbb"}}
+	if err := store.Save("/src/b", other, map[string]Entry{"main": {Merged: true}}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if err := store.Save("/src/a", map[string]Key{"main": {Branch: "ccc"}}, nil); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if _, hit, _ := store.Lookup("/src/a", old); hit {
+		t.Fatal("records of a deleted branch survived a save")
+	}
+	got, hit, err := store.Lookup("/src/b", other)
+	if err != nil || !hit || !got["main"].Merged {
+		t.Fatalf("other repository = %+v, %v, %v", got, hit, err)
+	}
+}
+
+func TestStoreRejectsEmptyRepo(t *testing.T) {
+	t.Parallel()
+
+	if err := Open(filepath.Join(t.TempDir(), "metadata.json")).Save("", nil, nil); err == nil {
+		t.Fatal("expected error for empty repository path")
+	}
+}
+
+func TestStoreRejectsCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	if _, _, err := Open(path).Lookup("/src/a", nil); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error mentioning path, got %v", err)
+	}
+}
+
+func TestStoreUnreadableFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	notDir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	keys := map[string]Key{"main": {Branch: "aaa"}}
+
+	cases := map[string]struct {
+		store *Store
+		want  string
+	}{
+		"nil store":         {store: nil, want: "not configured"},
+		"empty path":        {store: Open(""), want: "not configured"},
+		"path is directory": {store: Open(dir), want: "is a directory"},
+		"parent is a file":  {store: Open(filepath.Join(notDir, "metadata.json")), want: "not a directory"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, hit, err := tc.store.Lookup("/src/a", keys); err == nil || hit || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Lookup = %v, %v; want an error containing %q", hit, err, tc.want)
+			}
+			if err := tc.store.Save("/src/a", keys, nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Save error = %v, want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestOpenDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BRANCH_NAVIGATOR_STATE_DIR", dir)
+
+	path, err := DefaultPath()
+	if err != nil || path != filepath.Join(dir, "metadata.json") {
+		t.Fatalf("DefaultPath() = %q, %v", path, err)
+	}
+	store, err := OpenDefault()
+	if err != nil {
+		t.Fatalf("OpenDefault returned error: %v", err)
+	}
+	keys := map[string]Key{"main": {Branch: "aaa"}}
+	if err := store.Save("/src/a", keys, nil); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if _, hit, err := Open(path).Lookup("/src/a", keys); err != nil || !hit {
+		t.Fatalf("Lookup at the default path = %v, %v; want a hit", hit, err)
+	}
+}
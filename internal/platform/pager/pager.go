@@ -0,0 +1,114 @@
+package pager
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultCommand is used when neither GIT_PAGER nor PAGER is set.
+const defaultCommand = "less -R"
+
+// Command returns the pager command line: GIT_PAGER, then PAGER, then
+// less -R. Like git, it returns "" when the chosen variable is set to an
+// empty value or to cat, which turns paging off.
+func Command(lookupEnv func(string) (string, bool)) string {
+	command := defaultCommand
+	for _, name := range []string{"GIT_PAGER", "PAGER"} {
+		if value, ok := lookupEnv(name); ok {
+			command = value
+			break
+		}
+	}
+	command = strings.TrimSpace(command)
+	if command == "cat" {
+		return ""
+	}
+	return command
+}
+
+// Pager is where output-heavy commands write. It feeds a pager process when
+// the output is a terminal and writes directly otherwise.
+type Pager struct {
+	io.Writer
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// Open starts the pager for out. As git does, it sets LESS=FRX and LV=-c
+// when they are unset, so that colors survive and short output is printed
+// without waiting for q. When out is not a terminal, paging is turned off,
+// or the pager cannot be started, the Pager writes straight to out.
+func Open(out io.Writer, lookupEnv func(string) (string, bool)) (*Pager, error) {
+	return open(out, lookupEnv, func(file *os.File) bool {
+		return term.IsTerminal(int(file.Fd()))
+	})
+}
+
+// open is Open with the terminal check supplied by the caller.
+func open(out io.Writer, lookupEnv func(string) (string, bool), isTerminal func(*os.File) bool) (*Pager, error) {
+	file, ok := out.(*os.File)
+	command := Command(lookupEnv)
+	if !ok || command == "" || !isTerminal(file) {
+		return &Pager{Writer: out}, nil
+	}
+
+	cmd := shellCommand(command)
+	cmd.Stdout = file
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), defaultSettings(lookupEnv)...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return &Pager{Writer: out}, nil
+	}
+	return &Pager{Writer: stdin, cmd: cmd, stdin: stdin}, nil
+}
+
+// defaultSettings returns the variables Open adds to the pager's
+// environment: LESS=FRX and LV=-c, each only when it is unset.
+func defaultSettings(lookupEnv func(string) (string, bool)) []string {
+	settings := []string{}
+	for _, setting := range []string{"LESS=FRX", "LV=-c"} {
+		name, _, _ := strings.Cut(setting, "=")
+		if _, set := lookupEnv(name); !set {
+			settings = append(settings, setting)
+		}
+	}
+	return settings
+}
+
+// Close ends the input and waits for the user to leave the pager. A pager
+// that exits with a non-zero status, for example after q before the end of
+// the output, is not an error.
+func (p *Pager) Close() error {
+	if p == nil || p.cmd == nil {
+		return nil
+	}
+	p.stdin.Close()
+	err := p.cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
+// Paging reports whether output goes through a pager process.
+func (p *Pager) Paging() bool {
+	return p != nil && p.cmd != nil
+}
+
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
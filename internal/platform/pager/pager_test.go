@@ -0,0 +1,165 @@
+package pager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func fakeLookupEnv(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		env  map[string]string
+		want string
+	}{
+		"default":         {want: "less -R"},
+		"PAGER":           {env: map[string]string{"PAGER": "most"}, want: "most"},
+		"GIT_PAGER first": {env: map[string]string{"GIT_PAGER": "delta", "PAGER": "most"}, want: "delta"},
+		"empty disables":  {env: map[string]string{"GIT_PAGER": "", "PAGER": "most"}, want: ""},
+		"cat disables":    {env: map[string]string{"PAGER": " cat "}, want: ""},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Command(fakeLookupEnv(tc.env)); got != tc.want {
+				t.Fatalf("Command() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOpenWritesDirectlyWithoutTerminal(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	p, err := Open(out, fakeLookupEnv(nil))
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if p.Paging() {
+		t.Fatal("Paging() = true for a buffer")
+	}
+	if _, err := p.Write([]byte("\033[33mcommit abc\033[m\n")); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if out.String() != "\033[33mcommit abc\033[m\n" {
+		t.Fatalf("output = %q, want the colored text unchanged", out.String())
+	}
+}
+
+func TestDefaultSettings(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		env  map[string]string
+		want []string
+	}{
+		"both unset":      {want: []string{"LESS=FRX", "LV=-c"}},
+		"LESS set":        {env: map[string]string{"LESS": "-S"}, want: []string{"LV=-c"}},
+		"LESS set empty":  {env: map[string]string{"LESS": ""}, want: []string{"LV=-c"}},
+		"LV set":          {env: map[string]string{"LV": "-a"}, want: []string{"LESS=FRX"}},
+		"both set":        {env: map[string]string{"LESS": "R", "LV": "-c"}, want: []string{}},
+		"PAGER is unused": {env: map[string]string{"PAGER": "most"}, want: []string{"LESS=FRX", "LV=-c"}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := defaultSettings(fakeLookupEnv(tc.env)); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("defaultSettings() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the pagers use sh")
+	}
+	t.Parallel()
+
+	// echoLess is a pager that shows the LESS it was started with before
+	// the text.
+	const echoLess = `printf '%s|' "$LESS"; cat`
+	cases := map[string]struct {
+		env        map[string]string
+		terminal   bool
+		wantPaging bool
+		want       string
+	}{
+		"pager":           {env: map[string]string{"PAGER": echoLess}, terminal: true, wantPaging: true, want: "FRX|text\n"},
+		"GIT_PAGER":       {env: map[string]string{"GIT_PAGER": echoLess, "PAGER": "false"}, terminal: true, wantPaging: true, want: "FRX|text\n"},
+		"quit before end": {env: map[string]string{"PAGER": "exit 3"}, terminal: true, wantPaging: true},
+		"no terminal":     {env: map[string]string{"PAGER": echoLess}, want: "text\n"},
+		"disabled":        {env: map[string]string{"GIT_PAGER": "", "PAGER": echoLess}, terminal: true, want: "text\n"},
+		"cat":             {env: map[string]string{"PAGER": "cat"}, terminal: true, want: "text\n"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "out")
+			out, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer out.Close()
+
+			p, err := open(out, fakeLookupEnv(tc.env), func(*os.File) bool { return tc.terminal })
+			if err != nil {
+				t.Fatalf("open returned error: %v", err)
+			}
+			if p.Paging() != tc.wantPaging {
+				t.Fatalf("Paging() = %v, want %v", p.Paging(), tc.wantPaging)
+			}
+			// A pager that has quit may refuse the text; only Close reports.
+			_, _ = p.Write([]byte("text\n"))
+			if err := p.Close(); err != nil {
+				t.Fatalf("Close returned error: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("output = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCloseNil(t *testing.T) {
+	t.Parallel()
+
+	var p *Pager
+	if p.Paging() {
+		t.Fatal("Paging() = true for a nil pager")
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+// Package refwatch notices when the branches of a repository change on
+// disk, such as after a fetch or a checkout in another terminal.
+//
+// It polls the files git keeps refs in instead of subscribing to file
+// system events, which needs no platform-specific code or dependency and
+// costs a stat per loose ref each interval. Events would not save much:
+// inotify and its kin do not watch directories recursively, so every
+// directory below refs/ would need a watch of its own, added as git
+// creates them, and git replaces ref files by renaming lock files, which
+// some backends report late or coalesce. A list that refreshes within a
+// second or two of a fetch is soon enough for someone looking at it.
+package refwatch
+
+import (
+	"context"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultInterval is how often Watch looks at the refs unless told
+// otherwise.
+const DefaultInterval = time.Second
+
+// Dirs returns the directories whose refs describe the branches of a
+// repository: gitDir, which holds HEAD, and, for a linked worktree, the
+// commonDir shared with the other worktrees, which holds the branches.
+func Dirs(gitDir, commonDir string) []string {
+	dirs := []string{gitDir}
+	if commonDir != "" && filepath.Clean(commonDir) != filepath.Clean(gitDir) {
+		dirs = append(dirs, commonDir)
+	}
+	return dirs
+}
+
+// Watch looks at HEAD, packed-refs, and the loose refs of dirs every
+// interval and sends on the returned channel once they changed and then
+// stayed the same for an interval, so that a fetch updating many refs one
+// after another is announced once it is done. A send that finds one still
+// pending is dropped. The channel is closed once ctx is done.
+//
+// The refs are fingerprinted before Watch returns, so callers start it
+// before they read what the refs describe: a change landing while they
+// read is then announced rather than taken as the starting point.
+func Watch(ctx context.Context, dirs []string, interval time.Duration) <-chan struct{} {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	changed := make(chan struct{}, 1)
+	// Taken before returning, so that any change made afterwards counts.
+	last := Fingerprint(dirs)
+	go func() {
+		defer close(changed)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		pending := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := Fingerprint(dirs)
+			switch {
+			case current != last:
+				last, pending = current, true
+			case pending:
+				pending = false
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return changed
+}
+
+// Fingerprint summarizes the path, size, and modification time of HEAD,
+// packed-refs, and every loose ref in dirs. It changes whenever git
+// creates, moves, or deletes a ref there.
+func Fingerprint(dirs []string) uint64 {
+	h := fnv.New64a()
+	add := func(path string, info fs.FileInfo) {
+		h.Write([]byte(path))
+		h.Write([]byte{0})
+		if info != nil {
+			h.Write([]byte(strconv.FormatInt(info.Size(), 10)))
+			h.Write([]byte(strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+		}
+		h.Write([]byte{0})
+	}
+	for _, dir := range dirs {
+		for _, name := range []string{"HEAD", "packed-refs"} {
+			path := filepath.Join(dir, name)
+			info, _ := os.Stat(path)
+			add(path, info)
+		}
+		// A ref file vanishing half way through the walk is just another
+		// change, caught by the next look.
+		_ = filepath.WalkDir(filepath.Join(dir, "refs"), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			add(path, info)
+			return nil
+		})
+	}
+	return h.Sum64()
+}
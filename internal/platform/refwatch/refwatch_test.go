@@ -0,0 +1,87 @@
+package refwatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeRef writes a loose ref file below dir, creating its directories.
+func writeRef(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirs(t *testing.T) {
+	t.Parallel()
+
+	common := t.TempDir()
+	worktree := filepath.Join(common, "worktrees", "feature")
+
+	if got, want := Dirs(common, common), []string{common}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dirs(main) = %v, want %v", got, want)
+	}
+	if got, want := Dirs(worktree, common), []string{worktree, common}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dirs(worktree) = %v, want %v", got, want)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRef(t, dir, "HEAD", "ref: refs/heads/main\n")
+	writeRef(t, dir, "refs/heads/main", "aaaa\n")
+	dirs := []string{dir}
+
+	before := Fingerprint(dirs)
+	if again := Fingerprint(dirs); again != before {
+		t.Fatal("fingerprint changed without any change to the refs")
+	}
+	writeRef(t, dir, "refs/heads/feature", "bbbb\n")
+	added := Fingerprint(dirs)
+	if added == before {
+		t.Fatal("fingerprint missed a new branch")
+	}
+	if err := os.Remove(filepath.Join(dir, "refs/heads/feature")); err != nil {
+		t.Fatal(err)
+	}
+	if Fingerprint(dirs) == added {
+		t.Fatal("fingerprint missed a deleted branch")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRef(t, dir, "HEAD", "ref: refs/heads/main\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := Watch(ctx, []string{dir}, 5*time.Millisecond)
+
+	select {
+	case <-changed:
+		t.Fatal("announced a change before any")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	writeRef(t, dir, "refs/heads/feature", "bbbb\n")
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the new branch to be announced")
+	}
+
+	cancel()
+	for range changed {
+	}
+}
@@ -0,0 +1,272 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the format of the session files written by Recorder. Load
+// rejects other versions.
+const Version = 1
+
+// Kind tells what an Event captured.
+type Kind string
+
+const (
+	// KindKey is input read by the selector.
+	KindKey Kind = "key"
+	// KindFrame is a frame drawn by the selector.
+	KindFrame Kind = "frame"
+	// KindGit is a git command that finished.
+	KindGit Kind = "git"
+)
+
+// Session is a recorded run: the options it was started with, the terminal
+// it drew on, and what happened in order.
+type Session struct {
+	Version int `json:"version"`
+	// Args are the command-line arguments of the run.
+	Args []string `json:"args"`
+	// Cols and Rows are the terminal size; zeros mean it was not a terminal.
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+	// Now is when the run started; relative times are drawn against it.
+	Now    time.Time `json:"now"`
+	Events []Event   `json:"events"`
+}
+
+// Event is one thing that happened during a run.
+type Event struct {
+	// At is the time since the run started, in milliseconds.
+	At   int64 `json:"at_ms"`
+	Kind Kind  `json:"kind"`
+	// Keys holds the input of a KindKey event.
+	Keys string `json:"keys,omitempty"`
+	// Frame holds the text of a KindFrame event.
+	Frame string `json:"frame,omitempty"`
+	// Git holds the arguments of a KindGit event; Output is the SHA-256 of
+	// what the command printed, so that the recording does not carry the
+	// repository's contents, and Err its error.
+	Git    []string `json:"git,omitempty"`
+	Output string   `json:"output_sha256,omitempty"`
+	Err    string   `json:"err,omitempty"`
+}
+
+// Recorder collects the events of a run. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	session Session
+	// started is when recording began; event offsets count from it.
+	started time.Time
+	// now reads the clock; tests replace it.
+	now func() time.Time
+}
+
+// NewRecorder starts a recording of a run with args on a terminal of cols
+// by rows cells that draws relative times against now. A replay passes the
+// values of the recording it replays.
+func NewRecorder(args []string, cols, rows int, now time.Time) *Recorder {
+	return &Recorder{
+		session: Session{Version: Version, Args: args, Cols: cols, Rows: rows, Now: now},
+		started: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// Key records input read by the selector.
+func (r *Recorder) Key(keys []byte) {
+	r.add(Event{Kind: KindKey, Keys: string(keys)})
+}
+
+// Frame records a frame drawn by the selector.
+func (r *Recorder) Frame(text string) {
+	r.add(Event{Kind: KindFrame, Frame: text})
+}
+
+// Git records a finished git command with a hash of its output.
+func (r *Recorder) Git(args []string, stdout string, err error) {
+	event := Event{Kind: KindGit, Git: append([]string(nil), args...), Output: hash(stdout)}
+	if err != nil {
+		event.Err = err.Error()
+	}
+	r.add(event)
+}
+
+func (r *Recorder) add(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.At = r.now().Sub(r.started).Milliseconds()
+	r.session.Events = append(r.session.Events, event)
+}
+
+// Session returns what was recorded so far.
+func (r *Recorder) Session() Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.session
+	s.Events = append([]Event(nil), s.Events...)
+	return s
+}
+
+// Save writes what was recorded so far to path as JSON, replacing the file.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Session(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create session directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+	return nil
+}
+
+// Load reads a session written by Recorder.Save.
+func Load(path string) (Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Session{}, fmt.Errorf("read session: %w", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Session{}, fmt.Errorf("parse session %s: %w", path, err)
+	}
+	if s.Version != Version {
+		return Session{}, fmt.Errorf("session %s has version %d; this build replays version %d", path, s.Version, Version)
+	}
+	return s, nil
+}
+
+// Frames returns the frames of s in the order they were drawn.
+func (s Session) Frames() []string {
+	var frames []string
+	for _, event := range s.Events {
+		if event.Kind == KindFrame {
+			frames = append(frames, event.Frame)
+		}
+	}
+	return frames
+}
+
+// Keys returns a reader that delivers the input of s, each read at the
+// offset it was recorded at; sleep waits between them. Once the input runs
+// out the reader returns io.EOF.
+func (s Session) Keys(sleep func(time.Duration)) io.Reader {
+	var keys []Event
+	for _, event := range s.Events {
+		if event.Kind == KindKey {
+			keys = append(keys, event)
+		}
+	}
+	return &player{keys: keys, sleep: sleep}
+}
+
+// player is the reader returned by Session.Keys.
+type player struct {
+	keys  []Event
+	sleep func(time.Duration)
+	// at is the offset of the last event delivered, and pending what is
+	// left of it when the caller's buffer was too small.
+	at      int64
+	pending string
+}
+
+func (p *player) Read(b []byte) (int, error) {
+	if p.pending == "" {
+		if len(p.keys) == 0 {
+			return 0, io.EOF
+		}
+		next := p.keys[0]
+		p.keys = p.keys[1:]
+		if wait := next.At - p.at; wait > 0 {
+			p.sleep(time.Duration(wait) * time.Millisecond)
+		}
+		p.at = max(p.at, next.At)
+		p.pending = next.Keys
+	}
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+// Diff lists how replayed differs from recorded: git commands whose output
+// changed, which means the repository is no longer in the recorded state,
+// and frames drawn differently. Each command is reported once, and only
+// the first frame that differs. It is empty when the replay matches.
+func Diff(recorded, replayed Session) []string {
+	var diffs []string
+	outputs := make(map[string][]string)
+	reported := make(map[string]bool)
+	for _, event := range recorded.Events {
+		if event.Kind == KindGit {
+			key := strings.Join(event.Git, "\x00")
+			outputs[key] = append(outputs[key], event.Output)
+		}
+	}
+	for _, event := range replayed.Events {
+		if event.Kind != KindGit {
+			continue
+		}
+		key := strings.Join(event.Git, "\x00")
+		want, ok := outputs[key]
+		if !ok || len(want) == 0 {
+			continue
+		}
+		outputs[key] = want[1:]
+		if want[0] != event.Output && !reported[key] {
+			reported[key] = true
+			diffs = append(diffs, fmt.Sprintf("git %s printed different output than in the recording", strings.Join(event.Git, " ")))
+		}
+	}
+
+	want, got := recorded.Frames(), replayed.Frames()
+	for i := 0; i < min(len(want), len(got)); i++ {
+		if want[i] == got[i] {
+			continue
+		}
+		line, wantLine, gotLine := firstDifference(want[i], got[i])
+		diffs = append(diffs, fmt.Sprintf("frame %d differs at line %d:\n  recorded: %q\n  replayed: %q", i+1, line, wantLine, gotLine))
+		break
+	}
+	if len(want) != len(got) {
+		diffs = append(diffs, fmt.Sprintf("%d frames were recorded but %d were drawn", len(want), len(got)))
+	}
+	return diffs
+}
+
+// firstDifference returns the first line, counted from 1, where a and b
+// differ, with its text in each.
+func firstDifference(a, b string) (int, string, string) {
+	aLines := strings.Split(a, "\n")
+	bLines := strings.Split(b, "\n")
+	for i := 0; i < max(len(aLines), len(bLines)); i++ {
+		var aLine, bLine string
+		if i < len(aLines) {
+			aLine = strings.TrimSuffix(aLines[i], "\r")
+		}
+		if i < len(bLines) {
+			bLine = strings.TrimSuffix(bLines[i], "\r")
+		}
+		if aLine != bLine {
+			return i + 1, aLine, bLine
+		}
+	}
+	return 0, "", ""
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,165 @@
+package session
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var started = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestRecorder returns a recorder whose clock advances 10ms per event.
+func newTestRecorder() *Recorder {
+	r := NewRecorder([]string{"-m"}, 80, 24, started)
+	r.started = started
+	tick := started
+	r.now = func() time.Time {
+		tick = tick.Add(10 * time.Millisecond)
+		return tick
+	}
+	return r
+}
+
+func TestRecorderSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRecorder()
+	r.Git([]string{"branch"}, "secret-branch", nil)
+	r.Frame("> main\r\n")
+	r.Key([]byte("j"))
+	r.Git([]string{"merge", "x"}, "", errors.New("conflict"))
+
+	path := filepath.Join(t.TempDir(), "sub", "session.json")
+	if err := r.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := Session{
+		Version: Version,
+		Args:    []string{"-m"},
+		Cols:    80,
+		Rows:    24,
+		Now:     started,
+		Events: []Event{
+			{At: 10, Kind: KindGit, Git: []string{"branch"}, Output: hash("secret-branch")},
+			{At: 20, Kind: KindFrame, Frame: "> main\r\n"},
+			{At: 30, Kind: KindKey, Keys: "j"},
+			{At: 40, Kind: KindGit, Git: []string{"merge", "x"}, Output: hash(""), Err: "conflict"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "secret-branch") {
+		t.Fatalf("session stores git output:\n%s", data)
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(path, []byte(`{"version": 7}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "has version 7") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
+
+func TestKeysReplaysAtRecordedOffsets(t *testing.T) {
+	t.Parallel()
+
+	s := Session{Events: []Event{
+		{At: 5, Kind: KindFrame, Frame: "first"},
+		{At: 100, Kind: KindKey, Keys: "jj"},
+		{At: 250, Kind: KindKey, Keys: "\r"},
+	}}
+	var waits []time.Duration
+	keys := s.Keys(func(d time.Duration) { waits = append(waits, d) })
+
+	buf := make([]byte, 1)
+	var got strings.Builder
+	for {
+		n, err := keys.Read(buf)
+		got.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read returned error: %v", err)
+		}
+	}
+	if got.String() != "jj\r" {
+		t.Fatalf("keys = %q, want %q", got.String(), "jj\r")
+	}
+	want := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond}
+	if !reflect.DeepEqual(waits, want) {
+		t.Fatalf("waits = %v, want %v", waits, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	recorded := Session{Events: []Event{
+		{Kind: KindGit, Git: []string{"branch"}, Output: hash("main")},
+		{Kind: KindFrame, Frame: "header\r\n> main\r\n"},
+		{Kind: KindFrame, Frame: "header\r\n> other\r\n"},
+	}}
+
+	cases := map[string]struct {
+		replayed []Event
+		want     []string
+	}{
+		"matches": {
+			replayed: recorded.Events,
+		},
+		"repository changed": {
+			replayed: []Event{
+				{Kind: KindGit, Git: []string{"branch"}, Output: hash("main\nnew")},
+				{Kind: KindGit, Git: []string{"branch"}, Output: hash("main\nnew")},
+				recorded.Events[1],
+				recorded.Events[2],
+			},
+			want: []string{"git branch printed different output than in the recording"},
+		},
+		"frame differs": {
+			replayed: []Event{
+				recorded.Events[0],
+				recorded.Events[1],
+				{Kind: KindFrame, Frame: "header\r\n> main\r\n"},
+			},
+			want: []string{"frame 2 differs at line 2:\n  recorded: \"> other\"\n  replayed: \"> main\""},
+		},
+		"extra frame": {
+			replayed: append(append([]Event(nil), recorded.Events...), Event{Kind: KindFrame, Frame: "more"}),
+			want:     []string{"2 frames were recorded but 3 were drawn"},
+		},
+		"unrecorded command": {
+			replayed: append([]Event{{Kind: KindGit, Git: []string{"status"}, Output: hash("dirty")}}, recorded.Events...),
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Diff(recorded, Session{Events: tc.replayed})
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Diff() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
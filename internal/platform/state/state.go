@@ -0,0 +1,83 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+
+	"branch-navigator/internal/platform/jsonfile"
+	"branch-navigator/internal/platform/xdg"
+)
+
+const fileName = "state.json"
+
+// RepoState captures where the selector was left in a repository.
+type RepoState struct {
+	// Branch is the branch selected most recently.
+	Branch string `json:"branch,omitempty"`
+	// From is the branch that was current when Branch was selected.
+	From string `json:"from,omitempty"`
+	// Filter is the filter query active when the selector closed.
+	Filter string `json:"filter,omitempty"`
+}
+
+// Store persists RepoState per repository in a single JSON file.
+type Store struct {
+	path string
+}
+
+// DefaultPath returns the state file location inside the XDG state directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Open returns a Store backed by the file at path. The file is created lazily.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// OpenDefault returns a Store backed by DefaultPath.
+func OpenDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
+
+// Load returns the state saved for repo. Unknown repositories and a missing
+// file yield the zero RepoState.
+func (s *Store) Load(repo string) (RepoState, error) {
+	all, err := s.read()
+	if err != nil {
+		return RepoState{}, err
+	}
+	return all[repo], nil
+}
+
+// Save records st as the state of repo, replacing any previous value.
+func (s *Store) Save(repo string, st RepoState) error {
+	if repo == "" {
+		return errors.New("repository path is required")
+	}
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[repo] = st
+	return jsonfile.Save(s.path, all)
+}
+
+func (s *Store) read() (map[string]RepoState, error) {
+	if s == nil || s.path == "" {
+		return nil, errors.New("state store is not configured")
+	}
+	all := map[string]RepoState{}
+	if err := jsonfile.Load(s.path, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,137 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	store := Open(filepath.Join(t.TempDir(), "nested", "state.json"))
+
+	got, err := store.Load("/src/a")
+	if err != nil {
+		t.Fatalf("Load on missing file returned error: %v", err)
+	}
+	if got != (RepoState{}) {
+		t.Fatalf("expected zero state, got %+v", got)
+	}
+
+	a := RepoState{Branch: "feature/x", From: "main", Filter: "feat"}
+	b := RepoState{Branch: "topic"}
+	if err := store.Save("/src/a", a); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if err := store.Save("/src/b", b); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	for repo, want := range map[string]RepoState{"/src/a": a, "/src/b": b, "/src/c": {}} {
+		got, err := store.Load(repo)
+		if err != nil {
+			t.Fatalf("Load(%q) returned error: %v", repo, err)
+		}
+		if got != want {
+			t.Fatalf("Load(%q) = %+v, want %+v", repo, got, want)
+		}
+	}
+}
+
+func TestStoreRejectsEmptyRepo(t *testing.T) {
+	t.Parallel()
+
+	if err := Open(filepath.Join(t.TempDir(), "state.json")).Save("", RepoState{}); err == nil {
+		t.Fatal("expected error for empty repository path")
+	}
+}
+
+func TestStoreRejectsCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if _, err := Open(path).Load("/src/a"); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error mentioning path, got %v", err)
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("[1, 2"), 0o600); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	notDir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	cases := map[string]struct {
+		store    *Store
+		wantLoad string
+		wantSave string
+	}{
+		"nil store":         {store: nil, wantLoad: "not configured", wantSave: "not configured"},
+		"empty path":        {store: Open(""), wantLoad: "not configured", wantSave: "not configured"},
+		"corrupt file":      {store: Open(corrupt), wantLoad: corrupt, wantSave: corrupt},
+		"path is directory": {store: Open(dir), wantLoad: "is a directory", wantSave: "is a directory"},
+		"parent is a file":  {store: Open(filepath.Join(notDir, "state.json")), wantLoad: "not a directory", wantSave: "not a directory"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := tc.store.Load("/src/a"); err == nil || !strings.Contains(err.Error(), tc.wantLoad) {
+				t.Fatalf("Load error = %v, want it to contain %q", err, tc.wantLoad)
+			}
+			if err := tc.store.Save("/src/a", RepoState{Branch: "main"}); err == nil || !strings.Contains(err.Error(), tc.wantSave) {
+				t.Fatalf("Save error = %v, want it to contain %q", err, tc.wantSave)
+			}
+		})
+	}
+}
+
+func TestStoreKeepsCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if err := Open(path).Save("/src/a", RepoState{Branch: "main"}); err == nil {
+		t.Fatal("Save over a corrupt file succeeded")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "{not json" {
+		t.Fatalf("corrupt file was replaced: %q, %v", data, err)
+	}
+}
+
+func TestOpenDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BRANCH_NAVIGATOR_STATE_DIR", dir)
+
+	path, err := DefaultPath()
+	if err != nil || path != filepath.Join(dir, "state.json") {
+		t.Fatalf("DefaultPath() = %q, %v", path, err)
+	}
+	store, err := OpenDefault()
+	if err != nil {
+		t.Fatalf("OpenDefault returned error: %v", err)
+	}
+	if err := store.Save("/src/a", RepoState{Branch: "main"}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("state was not written to the default path: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AppName names the per-application subdirectory inside the XDG base directories.
+const AppName = "branch-navigator"
+
+// ConfigDir returns $XDG_CONFIG_HOME/branch-navigator, defaulting to ~/.config/branch-navigator.
+func ConfigDir() (string, error) {
+	return appDir("XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns $XDG_STATE_HOME/branch-navigator, defaulting to ~/.local/state/branch-navigator.
+// BRANCH_NAVIGATOR_STATE_DIR overrides both.
+func StateDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("BRANCH_NAVIGATOR_STATE_DIR")); dir != "" {
+		return dir, nil
+	}
+	return appDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+func appDir(envVar, homeRelative string) (string, error) {
+	if dir := strings.TrimSpace(os.Getenv(envVar)); dir != "" {
+		return filepath.Join(dir, AppName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate home directory: %w", err)
+	}
+	return filepath.Join(home, homeRelative, AppName), nil
+}
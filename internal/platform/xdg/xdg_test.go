@@ -0,0 +1,38 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	t.Setenv("BRANCH_NAVIGATOR_STATE_DIR", "")
+
+	if got, err := ConfigDir(); err != nil || got != filepath.Join("/xdg/config", AppName) {
+		t.Fatalf("ConfigDir() = %q, %v", got, err)
+	}
+	if got, err := StateDir(); err != nil || got != filepath.Join("/xdg/state", AppName) {
+		t.Fatalf("StateDir() = %q, %v", got, err)
+	}
+
+	t.Setenv("BRANCH_NAVIGATOR_STATE_DIR", "/custom/state")
+	if got, err := StateDir(); err != nil || got != "/custom/state" {
+		t.Fatalf("StateDir() = %q, %v", got, err)
+	}
+}
+
+func TestDirsFallBackToHome(t *testing.T) {
+	t.Setenv("HOME", "/home/nav")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("BRANCH_NAVIGATOR_STATE_DIR", "")
+
+	if got, err := ConfigDir(); err != nil || got != filepath.Join("/home/nav", ".config", AppName) {
+		t.Fatalf("ConfigDir() = %q, %v", got, err)
+	}
+	if got, err := StateDir(); err != nil || got != filepath.Join("/home/nav", ".local", "state", AppName) {
+		t.Fatalf("StateDir() = %q, %v", got, err)
+	}
+}
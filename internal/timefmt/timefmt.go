@@ -0,0 +1,124 @@
+// Package timefmt renders timestamps for display, either relative to the
+// current time or with a Go time layout, so that every date column follows
+// the same user setting. It also reads the dates given to --since and
+// --before.
+package timefmt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format renders timestamps in one of the supported styles. The zero value
+// is Relative.
+type Format struct {
+	// layout is the Go time layout applied in local time; empty means relative.
+	layout string
+}
+
+var (
+	// Relative renders the time elapsed since a timestamp, such as "3h ago".
+	Relative = Format{}
+	// Short renders the local date, such as "2024-05-01".
+	Short = Format{layout: "2006-01-02"}
+)
+
+// Layout returns a Format that renders timestamps in local time with the
+// given Go time layout.
+func Layout(layout string) Format {
+	return Format{layout: layout}
+}
+
+// Parse reads a format setting: "relative", "short", or a Go time layout
+// such as "Jan 2 15:04".
+func Parse(spec string) (Format, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "":
+		return Format{}, errors.New("time format is empty")
+	case "relative":
+		return Relative, nil
+	case "short":
+		return Short, nil
+	}
+	// A layout without any reference-time element prints the same text for every timestamp.
+	sample := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	if sample.Format(spec) == spec {
+		return Format{}, fmt.Errorf("time format %q is not relative, short, or a Go time layout such as 2006-01-02 15:04", spec)
+	}
+	return Layout(spec), nil
+}
+
+// Format renders t, measuring relative formats from now.
+func (f Format) Format(t, now time.Time) string {
+	if f.layout != "" {
+		return t.Local().Format(f.layout)
+	}
+	return ago(now.Sub(t))
+}
+
+// ago describes d in its largest whole unit.
+func ago(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < day:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	case d < 14*day:
+		return fmt.Sprintf("%dd ago", int(d/day))
+	case d < 60*day:
+		return fmt.Sprintf("%dw ago", int(d/(7*day)))
+	case d < 365*day:
+		return fmt.Sprintf("%dmo ago", int(d/(30*day)))
+	default:
+		return fmt.Sprintf("%dy ago", int(d/(365*day)))
+	}
+}
+
+// dateUnits maps the unit names accepted by ParseDate to a function that
+// moves a time back by n units.
+var dateUnits = map[string]func(t time.Time, n int) time.Time{
+	"second": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Second) },
+	"minute": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Minute) },
+	"hour":   func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Hour) },
+	"day":    func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -n) },
+	"week":   func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -7*n) },
+	"month":  func(t time.Time, n int) time.Time { return t.AddDate(0, -n, 0) },
+	"year":   func(t time.Time, n int) time.Time { return t.AddDate(-n, 0, 0) },
+}
+
+// ParseDate reads a point in time the way git's --since and --before do for
+// the common cases: a relative age such as "2.weeks", "3 months ago", or
+// "1.year.ago", measured back from now, or an absolute date such as
+// "2024-05-01" (local midnight) or an RFC 3339 timestamp.
+func ParseDate(spec string, now time.Time) (time.Time, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return time.Time{}, errors.New("date is empty")
+	}
+	if t, err := time.Parse(time.RFC3339, spec); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", spec, now.Location()); err == nil {
+		return t, nil
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool { return r == '.' || r == ' ' })
+	if len(words) == 3 && words[2] == "ago" {
+		words = words[:2]
+	}
+	if len(words) == 2 {
+		n, err := strconv.Atoi(words[0])
+		back, ok := dateUnits[strings.TrimSuffix(words[1], "s")]
+		if err == nil && n >= 0 && ok {
+			return back(now, n), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date %q is not an age such as 2.weeks or a date such as 2024-05-01", spec)
+}
@@ -0,0 +1,134 @@
+package timefmt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		spec    string
+		want    Format
+		wantErr string
+	}{
+		"relative":       {spec: "relative", want: Relative},
+		"short":          {spec: " short ", want: Short},
+		"layout":         {spec: "Jan 2 15:04", want: Layout("Jan 2 15:04")},
+		"empty":          {spec: "", wantErr: "time format is empty"},
+		"not a layout":   {spec: "yesterday", wantErr: `time format "yesterday" is not relative, short, or a Go time layout`},
+		"strftime style": {spec: "%Y-%m-%d", wantErr: "is not relative, short, or a Go time layout"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tc.spec)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tc.spec, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tc.spec, err)
+			}
+			if got != tc.want {
+				t.Fatalf("Parse(%q) = %+v, want %+v", tc.spec, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000_000, 0)
+	cases := map[string]struct {
+		ago  time.Duration
+		want string
+	}{
+		"seconds": {ago: 30 * time.Second, want: "just now"},
+		"future":  {ago: -time.Hour, want: "just now"},
+		"minutes": {ago: 5 * time.Minute, want: "5m ago"},
+		"hours":   {ago: 3*time.Hour + 59*time.Minute, want: "3h ago"},
+		"days":    {ago: 13 * 24 * time.Hour, want: "13d ago"},
+		"weeks":   {ago: 15 * 24 * time.Hour, want: "2w ago"},
+		"months":  {ago: 90 * 24 * time.Hour, want: "3mo ago"},
+		"years":   {ago: 800 * 24 * time.Hour, want: "2y ago"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Relative.Format(now.Add(-tc.ago), now); got != tc.want {
+				t.Fatalf("Relative.Format(-%v) = %q, want %q", tc.ago, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatLayout(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, time.May, 1, 12, 30, 0, 0, time.Local)
+	if got := Short.Format(ts, ts.Add(time.Hour)); got != "2024-05-01" {
+		t.Fatalf("Short.Format() = %q, want 2024-05-01", got)
+	}
+	if got := Layout("Jan 2 15:04").Format(ts, ts); got != "May 1 12:30" {
+		t.Fatalf("Layout.Format() = %q, want May 1 12:30", got)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.May, 31, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		spec    string
+		want    time.Time
+		wantErr string
+	}{
+		"dotted weeks":   {spec: "2.weeks", want: time.Date(2024, time.May, 17, 12, 0, 0, 0, time.UTC)},
+		"dotted ago":     {spec: "1.year.ago", want: time.Date(2023, time.May, 31, 12, 0, 0, 0, time.UTC)},
+		"spaced ago":     {spec: "3 months ago", want: time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)},
+		"singular unit":  {spec: "1.day", want: time.Date(2024, time.May, 30, 12, 0, 0, 0, time.UTC)},
+		"hours":          {spec: "36.hours", want: time.Date(2024, time.May, 30, 0, 0, 0, 0, time.UTC)},
+		"date":           {spec: "2024-05-01", want: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		"timestamp":      {spec: "2024-05-01T09:30:00Z", want: time.Date(2024, time.May, 1, 9, 30, 0, 0, time.UTC)},
+		"empty":          {spec: " ", wantErr: "date is empty"},
+		"unknown unit":   {spec: "2.fortnights", wantErr: `date "2.fortnights" is not an age`},
+		"missing number": {spec: "weeks", wantErr: "is not an age"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseDate(tc.spec, now)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseDate(%q) error = %v, want %q", tc.spec, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%q) returned error: %v", tc.spec, err)
+			}
+			if !got.Equal(tc.want) {
+				t.Fatalf("ParseDate(%q) = %v, want %v", tc.spec, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Background is the brightness of the terminal background.
+type Background int
+
+const (
+	// BackgroundUnknown means the terminal did not say; dark themes are used.
+	BackgroundUnknown Background = iota
+	// BackgroundDark is a dark terminal background.
+	BackgroundDark
+	// BackgroundLight is a light terminal background.
+	BackgroundLight
+)
+
+// oscBackgroundQuery asks the terminal for its background color (OSC 11).
+const oscBackgroundQuery = "\033]11;?\033\\"
+
+// DetectBackground reports whether the terminal background is light or
+// dark. It sends an OSC 11 query to the controlling terminal and waits at
+// most timeout for the answer, then falls back to the COLORFGBG variable
+// that some terminals export.
+func DetectBackground(getenv func(string) string, timeout time.Duration) Background {
+	return detectBackground(getenv, func() (string, error) { return queryBackground(timeout) })
+}
+
+func detectBackground(getenv func(string) string, query func() (string, error)) Background {
+	if name := getenv("TERM"); name != "" && name != "dumb" {
+		if reply, err := query(); err == nil {
+			if bg, ok := parseBackgroundReply(reply); ok {
+				return bg
+			}
+		}
+	}
+	if bg, ok := parseColorFGBG(getenv("COLORFGBG")); ok {
+		return bg
+	}
+	return BackgroundUnknown
+}
+
+// queryBackground sends the OSC 11 query to /dev/tty in raw mode and returns
+// the reply. The descriptor is never switched to blocking mode, so that the
+// read deadline stays effective.
+func queryBackground(timeout time.Duration) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+	var fd int
+	var state *term.State
+	var rawErr error
+	if err := conn.Control(func(f uintptr) {
+		fd = int(f)
+		state, rawErr = term.MakeRaw(fd)
+	}); err != nil {
+		return "", err
+	}
+	if rawErr != nil {
+		return "", rawErr
+	}
+	defer term.Restore(fd, state)
+
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(tty, oscBackgroundQuery); err != nil {
+		return "", err
+	}
+	return readOSCReply(tty)
+}
+
+// readOSCReply reads an operating system command reply up to its BEL or ST
+// terminator.
+func readOSCReply(r io.Reader) (string, error) {
+	const maxReply = 64
+	var reply []byte
+	buf := make([]byte, 1)
+	for len(reply) < maxReply {
+		n, err := r.Read(buf)
+		if n > 0 {
+			reply = append(reply, buf[0])
+			if buf[0] == '\a' || strings.HasSuffix(string(reply), "\033\\") {
+				return string(reply), nil
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("terminal reply is too long")
+}
+
+// parseBackgroundReply reads the color from a reply such as
+// ESC ] 11 ; rgb:1e1e/1e1e/2e2e ESC \ and classifies it by luminance.
+func parseBackgroundReply(reply string) (Background, bool) {
+	_, color, ok := strings.Cut(reply, "rgb:")
+	if !ok {
+		return BackgroundUnknown, false
+	}
+	color = strings.TrimRight(color, "\a\033\\")
+	parts := strings.Split(color, "/")
+	if len(parts) != 3 {
+		return BackgroundUnknown, false
+	}
+	var channels [3]float64
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 4 {
+			return BackgroundUnknown, false
+		}
+		value, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return BackgroundUnknown, false
+		}
+		channels[i] = float64(value) / float64(uint64(1)<<(4*len(part))-1)
+	}
+	luminance := 0.2126*channels[0] + 0.7152*channels[1] + 0.0722*channels[2]
+	if luminance > 0.5 {
+		return BackgroundLight, true
+	}
+	return BackgroundDark, true
+}
+
+// parseColorFGBG classifies COLORFGBG values such as "15;0", whose last field
+// is the ANSI color of the background.
+func parseColorFGBG(value string) (Background, bool) {
+	fields := strings.Split(strings.TrimSpace(value), ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return BackgroundUnknown, false
+	}
+	if bg == 7 || bg >= 9 {
+		return BackgroundLight, true
+	}
+	return BackgroundDark, true
+}
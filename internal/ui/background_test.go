@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseBackgroundReply(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		reply  string
+		want   Background
+		wantOK bool
+	}{
+		"dark, ST terminator":     {reply: "\033]11;rgb:1e1e/1e1e/2e2e\033\\", want: BackgroundDark, wantOK: true},
+		"light, BEL terminator":   {reply: "\033]11;rgb:eeee/f1f1/f5f5\a", want: BackgroundLight, wantOK: true},
+		"two digits per channel":  {reply: "\033]11;rgb:fd/f6/e3\033\\", want: BackgroundLight, wantOK: true},
+		"saturated blue is dark":  {reply: "\033]11;rgb:0000/0000/ffff\033\\", want: BackgroundDark, wantOK: true},
+		"missing color":           {reply: "\033]11;?\033\\"},
+		"too few channels":        {reply: "\033]11;rgb:ffff/ffff\033\\"},
+		"not hexadecimal":         {reply: "\033]11;rgb:zzzz/ffff/ffff\033\\"},
+		"channel with five chars": {reply: "\033]11;rgb:fffff/ffff/ffff\033\\"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseBackgroundReply(tc.reply)
+			if got != tc.want || ok != tc.wantOK {
+				t.Fatalf("parseBackgroundReply(%q) = %v, %v, want %v, %v", tc.reply, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseColorFGBG(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		value  string
+		want   Background
+		wantOK bool
+	}{
+		"dark":         {value: "15;0", want: BackgroundDark, wantOK: true},
+		"light":        {value: "0;15", want: BackgroundLight, wantOK: true},
+		"white":        {value: "0;7", want: BackgroundLight, wantOK: true},
+		"rxvt default": {value: "0;default;15", want: BackgroundLight, wantOK: true},
+		"unset":        {value: ""},
+		"not a number": {value: "15;default"},
+		"out of range": {value: "15;16"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseColorFGBG(tc.value)
+			if got != tc.want || ok != tc.wantOK {
+				t.Fatalf("parseColorFGBG(%q) = %v, %v, want %v, %v", tc.value, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadOSCReply(t *testing.T) {
+	t.Parallel()
+
+	got, err := readOSCReply(strings.NewReader("\033]11;rgb:0000/0000/0000\033\\jk"))
+	if err != nil {
+		t.Fatalf("readOSCReply returned error: %v", err)
+	}
+	if want := "\033]11;rgb:0000/0000/0000\033\\"; got != want {
+		t.Fatalf("readOSCReply() = %q, want %q", got, want)
+	}
+	if _, err := readOSCReply(strings.NewReader("\033]11;rgb:0000")); err == nil {
+		t.Fatal("expected an error for an unterminated reply")
+	}
+}
+
+func TestDetectBackground(t *testing.T) {
+	t.Parallel()
+
+	lightReply := func() (string, error) { return "\033]11;rgb:ffff/ffff/ffff\033\\", nil }
+	noReply := func() (string, error) { return "", errors.New("timeout") }
+
+	cases := map[string]struct {
+		env   map[string]string
+		query func() (string, error)
+		want  Background
+	}{
+		"terminal reply wins": {env: map[string]string{"TERM": "xterm-256color", "COLORFGBG": "15;0"}, query: lightReply, want: BackgroundLight},
+		"COLORFGBG fallback":  {env: map[string]string{"TERM": "xterm-256color", "COLORFGBG": "0;15"}, query: noReply, want: BackgroundLight},
+		"dumb terminal":       {env: map[string]string{"TERM": "dumb", "COLORFGBG": "15;0"}, query: lightReply, want: BackgroundDark},
+		"nothing to go on":    {env: map[string]string{"TERM": "xterm"}, query: noReply, want: BackgroundUnknown},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := func(key string) string { return tc.env[key] }
+			if got := detectBackground(getenv, tc.query); got != tc.want {
+				t.Fatalf("detectBackground() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// borderWidth is how many cells the left and right border take, including
+// the space that pads each side of the contents.
+const borderWidth = 4
+
+// drawBorder wraps the lines of frame in a rounded box with title in the top
+// edge. The box spans width cells, or fits the widest line when width is 0.
+func drawBorder(frame, title string, width int, theme Theme) string {
+	lines := strings.Split(strings.TrimSuffix(frame, lineBreak), lineBreak)
+	inner := width - borderWidth
+	if width <= 0 {
+		inner = displayWidth(title) + 2
+		for _, line := range lines {
+			inner = max(inner, visibleWidth(line))
+		}
+	}
+	inner = max(inner, 1)
+	edge := inner + 2
+
+	var b strings.Builder
+	b.WriteString(theme.Help + "╭")
+	if title = truncateWidth(title, edge-4); title != "" {
+		b.WriteString("─ " + resetColor + theme.ActionLabel + title + resetColor + theme.Help + " ")
+		b.WriteString(strings.Repeat("─", max(edge-3-displayWidth(title), 0)))
+	} else {
+		b.WriteString(strings.Repeat("─", edge))
+	}
+	b.WriteString("╮" + resetColor + lineBreak)
+	for _, line := range lines {
+		if width > 0 {
+			line = truncateVisible(line, inner)
+		}
+		pad := max(inner-visibleWidth(line), 0)
+		b.WriteString(theme.Help + "│" + resetColor + " " + line + strings.Repeat(" ", pad) + " " + theme.Help + "│" + resetColor + lineBreak)
+	}
+	b.WriteString(theme.Help + "╰" + strings.Repeat("─", edge) + "╯" + resetColor + lineBreak)
+	return b.String()
+}
+
+// truncateVisible shortens s to at most max cells like truncateWidth, keeping
+// its SGR escape sequences intact.
+func truncateVisible(s string, max int) string {
+	if visibleWidth(s) <= max {
+		return s
+	}
+	var b strings.Builder
+	width := 0
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "\033[") {
+			end := strings.IndexByte(s[i:], 'm')
+			if end == -1 {
+				break
+			}
+			b.WriteString(s[i : i+end+1])
+			i += end + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runeWidth(r)
+		if width+w > max-1 {
+			break
+		}
+		b.WriteRune(r)
+		width += w
+		i += size
+	}
+	return b.String() + ellipsis + resetColor
+}
+
+// visibleWidth returns the number of cells s occupies on screen, ignoring
+// the SGR escape sequences used for colors.
+func visibleWidth(s string) int {
+	width := 0
+	for {
+		start := strings.Index(s, "\033[")
+		if start == -1 {
+			return width + displayWidth(s)
+		}
+		width += displayWidth(s[:start])
+		end := strings.IndexByte(s[start:], 'm')
+		if end == -1 {
+			return width
+		}
+		s = s[start+end+1:]
+	}
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  int
+	}{
+		"plain":            {input: "main", want: 4},
+		"colored":          {input: "\033[1;38;5;111mmain\033[0m", want: 4},
+		"colored segments": {input: "  \033[37mfix\033[0m\033[90m (merged)\033[0m", want: 14},
+		"wide runes":       {input: "\033[37m日本語\033[0m", want: 6},
+		"unterminated":     {input: "ab\033[38;5", want: 2},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := visibleWidth(tc.input); got != tc.want {
+				t.Fatalf("visibleWidth(%q) = %d, want %d", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTruncateVisible(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		max   int
+		want  string
+	}{
+		"fits":             {input: "\033[90mhelp\033[0m", max: 4, want: "\033[90mhelp\033[0m"},
+		"shortened":        {input: "\033[90mj/k to move\033[0m", max: 6, want: "\033[90mj/k t…\033[0m"},
+		"across sequences": {input: "\033[1mab\033[0m\033[90mcdef\033[0m", max: 4, want: "\033[1mab\033[0m\033[90mc…\033[0m"},
+		"wide runes":       {input: "日本語", max: 4, want: "日…\033[0m"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := truncateVisible(tc.input, tc.max); got != tc.want {
+				t.Fatalf("truncateVisible(%q, %d) = %q, want %q", tc.input, tc.max, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDrawBorder(t *testing.T) {
+	t.Parallel()
+
+	frame := "Select:" + lineBreak + "\033[1m> main\033[0m" + lineBreak + lineBreak
+	cases := map[string]struct {
+		title string
+		width int
+		want  []string
+	}{
+		"fits contents": {
+			title: "Checkout",
+			want: []string{
+				"╭─ Checkout ─╮",
+				"│ Select:    │",
+				"│ > main     │",
+				"│            │",
+				"╰────────────╯",
+			},
+		},
+		"spans the screen": {
+			title: "Checkout",
+			width: 16,
+			want: []string{
+				"╭─ Checkout ───╮",
+				"│ Select:      │",
+				"│ > main       │",
+				"│              │",
+				"╰──────────────╯",
+			},
+		},
+		"untitled": {
+			width: 11,
+			want: []string{
+				"╭─────────╮",
+				"│ Select: │",
+				"│ > main  │",
+				"│         │",
+				"╰─────────╯",
+			},
+		},
+		"long title is shortened": {
+			title: "Merge into the current branch",
+			width: 16,
+			want: []string{
+				"╭─ Merge int… ─╮",
+				"│ Select:      │",
+				"│ > main       │",
+				"│              │",
+				"╰──────────────╯",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := plainLines(strings.TrimSuffix(drawBorder(frame, tc.title, tc.width, ThemeNord), lineBreak))
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Fatalf("drawBorder() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSelectWithBorder(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/a-rather-long-branch-name"},
+	}
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+	ui.width = 28
+	ui.SetBorder(true)
+	if _, err := ui.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	lines := plainLines(framesFromOutput(t, output.String())[0])
+	if !strings.HasPrefix(lines[0], "╭─ "+checkoutAction.Name+" ") {
+		t.Fatalf("top border should carry the action name: %q", lines[0])
+	}
+	if containsPrefix(lines, "│ Action:") {
+		t.Fatalf("action header should move into the border title: %q", lines)
+	}
+	if !containsPrefix(lines, "│   feature/a-rather-long… │") {
+		t.Fatalf("branch row not shortened to fit inside the border: %q", lines)
+	}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if w := displayWidth(line); w != 28 {
+			t.Fatalf("line %q is %d cells wide, want 28", line, w)
+		}
+	}
+}
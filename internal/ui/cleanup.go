@@ -0,0 +1,51 @@
+package ui
+
+import "fmt"
+
+// Cleanup plans the removal of a group of rows for the e key, such as every
+// branch without commits of its own. It returns the question to confirm and
+// the step that removes them, which returns the notice to show afterwards.
+// A nil step means there is nothing to remove; the question is then shown
+// as a notice instead.
+type Cleanup func() (question string, run func() (string, error), err error)
+
+// SetCleanup enables the e key, which asks the question c plans and, once
+// it is confirmed, runs the removal and reads the rows again with the
+// Reloader. label completes the help line, as in "e to <label>".
+func (u *UI) SetCleanup(label string, c Cleanup) {
+	if u != nil {
+		u.cleanup, u.cleanupLabel = c, label
+	}
+}
+
+// clean plans the cleanup and asks to confirm it. A failing plan or removal
+// shows why in the notice line and keeps the rows.
+func (u *UI) clean(view *listView) bool {
+	if u.cleanup == nil {
+		return false
+	}
+	question, run, err := u.cleanup()
+	switch {
+	case err != nil:
+		view.notice = fmt.Sprintf("cleanup failed: %v", err)
+		return true
+	case run == nil:
+		view.notice = question
+		return true
+	}
+	view.confirm(question, func() (Result, bool, error) {
+		notice, err := run()
+		if err != nil {
+			notice = fmt.Sprintf("cleanup failed: %v", err)
+		}
+		if _, err := u.reload(view); err != nil {
+			return Result{}, false, err
+		}
+		// A failed reload shows its own notice instead.
+		if view.notice == "" {
+			view.notice = notice
+		}
+		return Result{}, false, nil
+	})
+	return true
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSelectCleansUp(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input  string
+		plan   Cleanup
+		runs   int
+		notice string
+	}{
+		"confirmed": {
+			input: "ey",
+			plan: func() (string, func() (string, error), error) {
+				return "Delete 1 empty branch?", func() (string, error) { return "deleted 1 empty branch", nil }, nil
+			},
+			runs:   1,
+			notice: "deleted 1 empty branch",
+		},
+		"declined": {
+			input: "en",
+			plan: func() (string, func() (string, error), error) {
+				return "Delete 1 empty branch?", func() (string, error) { return "deleted 1 empty branch", nil }, nil
+			},
+		},
+		"nothing to remove": {
+			input: "e",
+			plan: func() (string, func() (string, error), error) {
+				return "no empty branches", nil, nil
+			},
+			notice: "no empty branches",
+		},
+		"plan fails": {
+			input: "e",
+			plan: func() (string, func() (string, error), error) {
+				return "", nil, errors.New("no base branch")
+			},
+			notice: "cleanup failed: no base branch",
+		},
+		"removal fails": {
+			input: "ey",
+			plan: func() (string, func() (string, error), error) {
+				return "Delete 1 empty branch?", func() (string, error) { return "", errors.New("locked") }, nil
+			},
+			runs:   1,
+			notice: "cleanup failed: locked",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runs, reloads := 0, 0
+			plan := func() (string, func() (string, error), error) {
+				question, run, err := tc.plan()
+				if run == nil {
+					return question, nil, err
+				}
+				return question, func() (string, error) {
+					runs++
+					return run()
+				}, err
+			}
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString(tc.input+"q"), output, checkoutAction)
+			ui.SetCleanup("delete empty branches", plan)
+			ui.SetReloader(func() (Reloaded, error) {
+				reloads++
+				return Reloaded{Rows: Branches{{Name: "main", Current: true}}}, nil
+			})
+			if _, err := ui.Select([]Branch{{Name: "main", Current: true}, {Name: "empty"}}); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if runs != tc.runs || reloads != tc.runs {
+				t.Fatalf("ran %d times and reloaded %d times, want %d", runs, reloads, tc.runs)
+			}
+
+			frames := framesFromOutput(t, output.String())
+			if !containsPrefix(plainLines(frames[0]), "j/k or ↑/↓ to move, / to filter, e to delete empty branches,") {
+				t.Fatalf("cleanup hint missing from frame %q", plainLines(frames[0]))
+			}
+			last := plainLines(frames[len(frames)-1])
+			if tc.notice == "" {
+				for _, line := range last {
+					if strings.HasPrefix(line, "deleted") || strings.HasPrefix(line, "cleanup failed") {
+						t.Fatalf("unexpected notice in frame %q", last)
+					}
+				}
+				return
+			}
+			if !containsPrefix(last, tc.notice) {
+				t.Fatalf("notice %q missing from frame %q", tc.notice, last)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// ColumnWriter aligns tab-separated cells like text/tabwriter, but measures
+// cells in terminal cells, so that columns after CJK or emoji text line up.
+// Consecutive lines containing a tab form a block whose columns are aligned
+// together; the text after a line's last tab is not padded.
+type ColumnWriter struct {
+	out     io.Writer
+	padding int
+	buf     bytes.Buffer
+}
+
+// NewColumnWriter returns a ColumnWriter that writes to out, leaving padding
+// spaces after the widest cell of each column.
+func NewColumnWriter(out io.Writer, padding int) *ColumnWriter {
+	return &ColumnWriter{out: out, padding: padding}
+}
+
+// Write buffers p until Flush.
+func (w *ColumnWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Flush aligns the buffered lines and writes them out.
+func (w *ColumnWriter) Flush() error {
+	text := w.buf.String()
+	w.buf.Reset()
+	lines := strings.SplitAfter(text, "\n")
+
+	var b strings.Builder
+	for start := 0; start < len(lines); {
+		end := start + 1
+		if strings.Contains(lines[start], "\t") {
+			for end < len(lines) && strings.Contains(lines[end], "\t") {
+				end++
+			}
+		}
+		w.writeBlock(&b, lines[start:end])
+		start = end
+	}
+	_, err := io.WriteString(w.out, b.String())
+	return err
+}
+
+// writeBlock pads the cells of lines to the widest cell of their column.
+func (w *ColumnWriter) writeBlock(b *strings.Builder, lines []string) {
+	rows := make([][]string, len(lines))
+	widths := []int{}
+	for i, line := range lines {
+		rows[i] = strings.Split(line, "\t")
+		for col, cell := range rows[i][:len(rows[i])-1] {
+			if col == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[col] = max(widths[col], displayWidth(cell))
+		}
+	}
+	for _, cells := range rows {
+		last := len(cells) - 1
+		for col, cell := range cells[:last] {
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[col]-displayWidth(cell)+w.padding))
+		}
+		b.WriteString(cells[last])
+	}
+}
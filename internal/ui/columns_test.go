@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestColumnWriter(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  string
+	}{
+		"ascii": {
+			input: "1\tmain\trepo\n10\tfeature/x\trepo\n",
+			want:  "1   main       repo\n10  feature/x  repo\n",
+		},
+		"cjk": {
+			input: "機能/ログイン\t3\nmain\t12\n",
+			want:  "機能/ログイン  3\nmain           12\n",
+		},
+		"emoji": {
+			input: "fix/🐛\t1\nfix/bug\t2\n",
+			want:  "fix/🐛   1\nfix/bug  2\n",
+		},
+		"combining": {
+			input: "café\tx\ncafe-long\ty\n",
+			want:  "café       x\ncafe-long  y\n",
+		},
+		"blank lines separate blocks": {
+			input: "REPOSITORY\tSWITCHES\n/src/a\t1\n\nBRANCH\tUSES\nfeature/long-name\t2\n",
+			want:  "REPOSITORY  SWITCHES\n/src/a      1\n\nBRANCH             USES\nfeature/long-name  2\n",
+		},
+		"ragged rows": {
+			input: "a\tb\tc\nlonger\td\n",
+			want:  "a       b  c\nlonger  d\n",
+		},
+		"no trailing newline": {
+			input: "a\tb\nccc\td",
+			want:  "a    b\nccc  d",
+		},
+		"no tabs": {
+			input: "plain text\n",
+			want:  "plain text\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			w := NewColumnWriter(out, 2)
+			fmt.Fprint(w, tc.input)
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush returned error: %v", err)
+			}
+			if out.String() != tc.want {
+				t.Fatalf("got\n%q\nwant\n%q", out.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineReader returns the buffered reader the prompts read in from. A
+// *bufio.Reader is used as is, so that a selector and the prompts after it
+// can share one buffer: a fresh one per prompt would swallow the piped lines
+// meant for the next prompt.
+func LineReader(in io.Reader) *bufio.Reader {
+	if reader, ok := in.(*bufio.Reader); ok {
+		return reader
+	}
+	return bufio.NewReader(in)
+}
+
+// TypedConfirmation guards a destructive action by making the user type a
+// token, such as the branch name, instead of answering y.
+type TypedConfirmation struct {
+	// Warning explains what is about to happen and is printed before the prompt.
+	Warning string
+	// Token is the text the user has to type to confirm.
+	Token string
+}
+
+// Confirm prints the warning and prompt, then reads one line from in. It
+// reports true only when the line equals Token, ignoring surrounding white
+// space; an empty answer, EOF, or any other text declines.
+func (c TypedConfirmation) Confirm(in io.Reader, out io.Writer) (bool, error) {
+	if strings.TrimSpace(c.Token) == "" {
+		return false, errors.New("confirmation token is empty")
+	}
+	if warning := strings.TrimSpace(c.Warning); warning != "" {
+		if _, err := fmt.Fprintln(out, warning); err != nil {
+			return false, err
+		}
+	}
+	if _, err := fmt.Fprintf(out, "Type %s to confirm, or press Enter to cancel: ", c.Token); err != nil {
+		return false, err
+	}
+
+	line, err := LineReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer := strings.TrimSpace(line)
+	if answer == c.Token {
+		return true, nil
+	}
+	if answer != "" {
+		if _, err := fmt.Fprintf(out, "%q does not match %q.\n", answer, c.Token); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Confirmation asks a yes/no question before an action that is hard to undo.
+// Unlike TypedConfirmation it accepts y or yes, and defaults to no.
+type Confirmation struct {
+	// Summary describes the pending action and is printed before the prompt.
+	Summary string
+}
+
+// Confirm prints the summary and a [y/N] prompt, then reads one line from in.
+// It reports true only for y or yes in any case; an empty answer, EOF, or any
+// other text declines.
+func (c Confirmation) Confirm(in io.Reader, out io.Writer) (bool, error) {
+	if summary := strings.TrimSpace(c.Summary); summary != "" {
+		if _, err := fmt.Fprintln(out, summary); err != nil {
+			return false, err
+		}
+	}
+	if _, err := fmt.Fprint(out, "Proceed? [y/N] "); err != nil {
+		return false, err
+	}
+
+	line, err := LineReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// Form asks for a line of text per field, such as the placeholders of a
+// branch name template.
+type Form struct {
+	// Title is printed before the first field.
+	Title string
+	// Fields are the labels of the values to ask for, in order.
+	Fields []string
+}
+
+// Fill prints each field label as a prompt and reads one line for it. It
+// reports false when an answer is empty or input ends, which cancels the
+// form.
+func (f Form) Fill(in io.Reader, out io.Writer) (map[string]string, bool, error) {
+	if title := strings.TrimSpace(f.Title); title != "" {
+		if _, err := fmt.Fprintln(out, title); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// One reader serves every field, so that answers typed ahead are not lost.
+	reader := LineReader(in)
+	values := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		if _, err := fmt.Fprintf(out, "%s: ", field); err != nil {
+			return nil, false, err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, false, err
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			return nil, false, nil
+		}
+		values[field] = answer
+	}
+	return values, true, nil
+}
+
+// Choice asks the user to pick one of several options by its key, such as
+// whether to stash changes before a checkout.
+type Choice struct {
+	// Summary describes the situation and is printed before the options.
+	Summary string
+	// Options are listed in order, one per line.
+	Options []ChoiceOption
+	// Default is the key picked by an empty answer or the end of input.
+	Default string
+}
+
+// ChoiceOption is one answer to a Choice.
+type ChoiceOption struct {
+	// Key is the letter typed to pick the option.
+	Key   string
+	Label string
+}
+
+// Ask prints the summary, the options, and a prompt, then reads answers
+// from in until one matches an option key, ignoring case. It returns the
+// key of the picked option.
+func (c Choice) Ask(in io.Reader, out io.Writer) (string, error) {
+	if len(c.Options) == 0 {
+		return "", errors.New("choice has no options")
+	}
+	if summary := strings.TrimSpace(c.Summary); summary != "" {
+		if _, err := fmt.Fprintln(out, summary); err != nil {
+			return "", err
+		}
+	}
+	keys := make([]string, len(c.Options))
+	for i, option := range c.Options {
+		if _, err := fmt.Fprintf(out, "  %s) %s\n", option.Key, option.Label); err != nil {
+			return "", err
+		}
+		keys[i] = option.Key
+		if strings.EqualFold(option.Key, c.Default) {
+			keys[i] = strings.ToUpper(option.Key)
+		}
+	}
+
+	reader := LineReader(in)
+	for {
+		if _, err := fmt.Fprintf(out, "Choose [%s] ", strings.Join(keys, "/")); err != nil {
+			return "", err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		answer := strings.TrimSpace(line)
+		for _, option := range c.Options {
+			if strings.EqualFold(answer, option.Key) {
+				return option.Key, nil
+			}
+		}
+		if answer == "" || err != nil {
+			return c.Default, nil
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypedConfirmation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   string
+		want    bool
+		wantOut string
+	}{
+		"exact token":       {input: "feature/x\n", want: true},
+		"surrounding space": {input: "  feature/x  \n", want: true},
+		"without newline":   {input: "feature/x", want: true},
+		"y is not enough":   {input: "y\n", wantOut: `"y" does not match "feature/x".`},
+		"case sensitive":    {input: "Feature/X\n", wantOut: `"Feature/X" does not match "feature/x".`},
+		"empty answer":      {input: "\n"},
+		"eof":               {},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			confirm := TypedConfirmation{Warning: "Branch 'feature/x' will be force-deleted.", Token: "feature/x"}
+			got, err := confirm.Confirm(strings.NewReader(tc.input), out)
+			if err != nil {
+				t.Fatalf("Confirm returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Confirm() = %v, want %v", got, tc.want)
+			}
+			prompt := "Branch 'feature/x' will be force-deleted.\nType feature/x to confirm, or press Enter to cancel: "
+			if !strings.HasPrefix(out.String(), prompt) {
+				t.Fatalf("output = %q, want prefix %q", out.String(), prompt)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("output = %q, want it to contain %q", out.String(), tc.wantOut)
+			}
+		})
+	}
+}
+
+func TestTypedConfirmationRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (TypedConfirmation{}).Confirm(strings.NewReader("\n"), &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an empty token")
+	}
+}
+
+func TestConfirmation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  bool
+	}{
+		"y":               {input: "y\n", want: true},
+		"yes":             {input: "yes\n", want: true},
+		"upper case":      {input: " YES \n", want: true},
+		"without newline": {input: "y", want: true},
+		"n":               {input: "n\n"},
+		"other text":      {input: "merge\n"},
+		"empty answer":    {input: "\n"},
+		"eof":             {},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			got, err := Confirmation{Summary: "Merge feature/x → main"}.Confirm(strings.NewReader(tc.input), out)
+			if err != nil {
+				t.Fatalf("Confirm returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Confirm() = %v, want %v", got, tc.want)
+			}
+			if want := "Merge feature/x → main\nProceed? [y/N] "; out.String() != want {
+				t.Fatalf("output = %q, want %q", out.String(), want)
+			}
+		})
+	}
+}
+
+func TestFormFill(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input      string
+		wantValues map[string]string
+		wantOK     bool
+		wantOut    string
+	}{
+		"every field":     {input: "ABC-123\n Fix login \n", wantValues: map[string]string{"ticket": "ABC-123", "slug": "Fix login"}, wantOK: true, wantOut: "New branch\nticket: slug: "},
+		"without newline": {input: "ABC-123\nFix login", wantValues: map[string]string{"ticket": "ABC-123", "slug": "Fix login"}, wantOK: true, wantOut: "New branch\nticket: slug: "},
+		"empty answer":    {input: "\nFix login\n", wantOut: "New branch\nticket: "},
+		"eof":             {input: "ABC-123\n", wantOut: "New branch\nticket: slug: "},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			form := Form{Title: "New branch", Fields: []string{"ticket", "slug"}}
+			values, ok, err := form.Fill(strings.NewReader(tc.input), out)
+			if err != nil {
+				t.Fatalf("Fill returned error: %v", err)
+			}
+			if ok != tc.wantOK {
+				t.Fatalf("Fill() ok = %v, want %v", ok, tc.wantOK)
+			}
+			if tc.wantOK && !reflect.DeepEqual(values, tc.wantValues) {
+				t.Fatalf("Fill() = %v, want %v", values, tc.wantValues)
+			}
+			if out.String() != tc.wantOut {
+				t.Fatalf("output = %q, want %q", out.String(), tc.wantOut)
+			}
+		})
+	}
+}
+
+func TestChoiceAsk(t *testing.T) {
+	t.Parallel()
+
+	choice := Choice{
+		Summary: "3 modified, 1 untracked",
+		Options: []ChoiceOption{
+			{Key: "p", Label: "proceed"},
+			{Key: "s", Label: "stash first"},
+			{Key: "c", Label: "cancel"},
+		},
+		Default: "p",
+	}
+	menu := "3 modified, 1 untracked\n  p) proceed\n  s) stash first\n  c) cancel\n"
+	prompt := "Choose [P/s/c] "
+
+	cases := map[string]struct {
+		input   string
+		want    string
+		prompts int
+	}{
+		"key":             {input: "s\n", want: "s", prompts: 1},
+		"upper case":      {input: " C \n", want: "c", prompts: 1},
+		"without newline": {input: "s", want: "s", prompts: 1},
+		"empty answer":    {input: "\n", want: "p", prompts: 1},
+		"eof":             {want: "p", prompts: 1},
+		"retry":           {input: "x\nc\n", want: "c", prompts: 2},
+		"unknown at eof":  {input: "x", want: "p", prompts: 1},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			got, err := choice.Ask(strings.NewReader(tc.input), out)
+			if err != nil {
+				t.Fatalf("Ask returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("Ask() = %q, want %q", got, tc.want)
+			}
+			if want := menu + strings.Repeat(prompt, tc.prompts); out.String() != want {
+				t.Fatalf("output = %q, want %q", out.String(), want)
+			}
+		})
+	}
+}
+
+func TestChoiceWithoutOptions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (Choice{}).Ask(strings.NewReader("p\n"), &bytes.Buffer{}); err == nil {
+		t.Fatal("expected error for a choice without options")
+	}
+}
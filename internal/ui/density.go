@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Density selects the vertical spacing of the selector.
+type Density int
+
+const (
+	// DensityComfortable separates the header, the list, and the help text
+	// with blank lines, or with rules when separators are enabled.
+	DensityComfortable Density = iota
+	// DensityCompact leaves the separating lines out so more rows fit.
+	DensityCompact
+)
+
+// defaultRuleWidth is the width of separator rules when the output is not a
+// terminal.
+const defaultRuleWidth = 40
+
+// ParseDensity parses a density: "comfortable" (or "cozy") or "compact".
+func ParseDensity(s string) (Density, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "comfortable", "cozy":
+		return DensityComfortable, nil
+	case "compact":
+		return DensityCompact, nil
+	default:
+		return DensityComfortable, fmt.Errorf("unknown density %q (use comfortable or compact)", s)
+	}
+}
+
+// SetDensity selects the vertical spacing. separators draws a rule instead
+// of each blank separating line in the comfortable density.
+func (u *UI) SetDensity(d Density, separators bool) {
+	if u != nil {
+		u.density = d
+		u.separators = separators
+	}
+}
+
+// writeSeparator writes the line that separates parts of the frame, if any.
+func (u *UI) writeSeparator(w io.Writer, theme Theme) error {
+	switch {
+	case u.density == DensityCompact:
+		return nil
+	case u.separators:
+		width := u.rowWidth()
+		if width <= 0 {
+			width = defaultRuleWidth
+		}
+		_, err := fmt.Fprintf(w, "%s%s%s%s", theme.Help, strings.Repeat("─", width), resetColor, lineBreak)
+		return err
+	default:
+		_, err := fmt.Fprint(w, lineBreak)
+		return err
+	}
+}
+
+// separatorRows returns how many lines writeSeparator takes.
+func (u *UI) separatorRows() int {
+	if u.density == DensityCompact {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseDensity(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   string
+		want    Density
+		wantErr bool
+	}{
+		"default":     {input: "", want: DensityComfortable},
+		"comfortable": {input: "comfortable", want: DensityComfortable},
+		"cozy":        {input: "Cozy", want: DensityComfortable},
+		"compact":     {input: "compact", want: DensityCompact},
+		"unknown":     {input: "dense", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseDensity(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseDensity(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("ParseDensity(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectDensity(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		density    Density
+		separators bool
+		want       []string
+		chrome     int
+	}{
+		"comfortable": {
+			density: DensityComfortable,
+			want: []string{
+				"Action: Checkout branch",
+				"Switch to the selected branch.",
+				"",
+				"Select a branch:",
+				"> main (current branch)",
+				"",
+				"j/k or ↑/↓ to move, / to filter, Enter to checkout the selected branch, q to exit",
+			},
+			chrome: reservedRows,
+		},
+		"comfortable with separators": {
+			density:    DensityComfortable,
+			separators: true,
+			want: []string{
+				"Action: Checkout branch",
+				"Switch to the selected branch.",
+				strings.Repeat("─", 30),
+				"Select a branch:",
+				"> main (current branch)",
+				strings.Repeat("─", 30),
+				"j/k or ↑/↓ to move, / to filter, Enter to checkout the selected branch, q to exit",
+			},
+			chrome: reservedRows,
+		},
+		"compact": {
+			density: DensityCompact,
+			want: []string{
+				"Action: Checkout branch",
+				"Switch to the selected branch.",
+				"Select a branch:",
+				"> main (current branch)",
+				"j/k or ↑/↓ to move, / to filter, Enter to checkout the selected branch, q to exit",
+			},
+			chrome: reservedRows - 2,
+		},
+		"compact ignores separators": {
+			density:    DensityCompact,
+			separators: true,
+			want: []string{
+				"Action: Checkout branch",
+				"Switch to the selected branch.",
+				"Select a branch:",
+				"> main (current branch)",
+				"j/k or ↑/↓ to move, / to filter, Enter to checkout the selected branch, q to exit",
+			},
+			chrome: reservedRows - 2,
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+			ui.width = 30
+			ui.SetDensity(tc.density, tc.separators)
+			if _, err := ui.Select([]Branch{{Name: "main", Current: true}}); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			got := plainLines(strings.TrimSuffix(framesFromOutput(t, output.String())[0], lineBreak))
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Fatalf("frame =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+			}
+			if rows := ui.chromeRows(); rows != tc.chrome {
+				t.Fatalf("chromeRows() = %d, want %d", rows, tc.chrome)
+			}
+		})
+	}
+}
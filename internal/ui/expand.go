@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Expander supplies the lines of the detail block drawn beneath a row that
+// is expanded with Tab or o, such as its upstream and latest commits. It is
+// called once per expansion, so it may run git.
+type Expander func(branch Branch) ([]string, error)
+
+// SetExpander enables Tab and o, which expand the highlighted row into a
+// block of lines from e beneath it and collapse it again on the next press.
+func (u *UI) SetExpander(e Expander) {
+	if u != nil {
+		u.expander = e
+	}
+}
+
+// detailIndent is how far the lines of an expanded row are indented past
+// the row names.
+const detailIndent = "  "
+
+// expand expands the highlighted row, or collapses it when it is expanded
+// already, and reports whether anything changed. A failing Expander leaves
+// the row collapsed and shows why in the notice line.
+func (u *UI) expand(view *listView) bool {
+	if u.expander == nil || view.cursor >= len(view.visible) {
+		return false
+	}
+	idx := view.visible[view.cursor]
+	if _, ok := view.expanded[idx]; ok {
+		delete(view.expanded, idx)
+		return true
+	}
+	lines, err := u.expander(view.rows.Branch(idx))
+	if err != nil {
+		view.notice = fmt.Sprintf("details unavailable: %v", err)
+		return true
+	}
+	if len(lines) == 0 {
+		lines = []string{"no details"}
+	}
+	if view.expanded == nil {
+		view.expanded = map[int][]string{}
+	}
+	view.expanded[idx] = lines
+	return true
+}
+
+// drawDetails writes up to limit lines of the detail block of an expanded
+// row, indented beneath its name, and returns how many it wrote. A negative
+// limit writes every line.
+func (u *UI) drawDetails(w io.Writer, lines []string, limit int, theme Theme) (int, error) {
+	if limit >= 0 && len(lines) > limit {
+		lines = lines[:limit]
+	}
+	indent := strings.Repeat(" ", u.marker.width()) + detailIndent
+	width := u.rowWidth()
+	for _, line := range lines {
+		if width > 0 {
+			line = truncateWidth(line, max(width-displayWidth(indent), 1))
+		}
+		if _, err := fmt.Fprint(w, indent+theme.Help+line+resetColor+lineBreak); err != nil {
+			return 0, err
+		}
+	}
+	return len(lines), nil
+}
+
+// drawRowDetails draws the details of visible row i, if it is expanded,
+// taking their lines from room unless room is negative.
+func (u *UI) drawRowDetails(w io.Writer, view *listView, i int, room *int, theme Theme) error {
+	lines, ok := view.expanded[view.visible[i]]
+	if !ok {
+		return nil
+	}
+	n, err := u.drawDetails(w, lines, *room, theme)
+	if *room >= 0 {
+		*room -= n
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectExpandsRows(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{{Name: "main", Current: true}, {Name: "feature"}, {Name: "fix"}}
+	var expanded []string
+	output := &bytes.Buffer{}
+	selector := New(bytes.NewBufferString("j\toq"), output, checkoutAction)
+	selector.SetExpander(func(branch Branch) ([]string, error) {
+		expanded = append(expanded, branch.Name)
+		return []string{"upstream     origin/" + branch.Name, "commits      abc1234 Add parser"}, nil
+	})
+	if _, err := selector.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if want := []string{"feature"}; !reflect.DeepEqual(expanded, want) {
+		t.Fatalf("expanded %v, want %v", expanded, want)
+	}
+
+	frames := framesFromOutput(t, output.String())
+	open := plainLines(frames[2])
+	want := []string{"> feature", "    upstream     origin/feature", "    commits      abc1234 Add parser", "  fix"}
+	for i, line := range open {
+		if line == want[0] {
+			if got := open[i : i+len(want)]; !reflect.DeepEqual(got, want) {
+				t.Fatalf("expanded rows = %q, want %q", got, want)
+			}
+			break
+		}
+	}
+	if !containsPrefix(open, "    commits") {
+		t.Fatalf("details missing from frame %q", open)
+	}
+	if !containsPrefix(open, "j/k or ↑/↓ to move, / to filter, Tab for details, Enter to") {
+		t.Fatalf("details hint missing from frame %q", open)
+	}
+	if closed := plainLines(frames[3]); containsPrefix(closed, "    upstream") {
+		t.Fatalf("details still shown after collapsing: %q", closed)
+	}
+}
+
+func TestSelectExpandError(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	selector := New(bytes.NewBufferString("\tq"), output, checkoutAction)
+	selector.SetExpander(func(Branch) ([]string, error) {
+		return nil, errors.New("git log failed")
+	})
+	if _, err := selector.Select([]Branch{{Name: "main"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	frames := framesFromOutput(t, output.String())
+	if lines := plainLines(frames[1]); !containsPrefix(lines, "details unavailable: git log failed") {
+		t.Fatalf("error notice missing from frame %q", lines)
+	}
+}
+
+func TestSelectIgnoresExpandWithoutExpander(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	if _, err := New(bytes.NewBufferString("\tq"), output, checkoutAction).Select([]Branch{{Name: "main"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if frames := framesFromOutput(t, output.String()); len(frames) != 1 {
+		t.Fatalf("drew %d frames, want only the first", len(frames))
+	}
+}
+
+func TestListViewWindowWithExpandedRows(t *testing.T) {
+	t.Parallel()
+
+	rows := Branches{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+	cases := map[string]struct {
+		cursor    int
+		expanded  map[int][]string
+		wantStart int
+		wantEnd   int
+	}{
+		"details push rows below off screen": {cursor: 0, expanded: map[int][]string{0: {"x", "y"}}, wantStart: 0, wantEnd: 2},
+		"cursor details stay in view":        {cursor: 2, expanded: map[int][]string{2: {"x", "y"}}, wantStart: 1, wantEnd: 3},
+		"taller than the screen":             {cursor: 2, expanded: map[int][]string{2: {"1", "2", "3", "4", "5"}}, wantStart: 2, wantEnd: 3},
+		"end of the list fills the screen":   {cursor: 4, expanded: map[int][]string{3: {"x"}}, wantStart: 2, wantEnd: 5},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			view := newListView(rows, "", 0, nil, nil)
+			view.height = 4
+			view.cursor = tc.cursor
+			view.expanded = tc.expanded
+			start, end := view.window()
+			if start != tc.wantStart || end != tc.wantEnd {
+				t.Fatalf("window() = %d, %d, want %d, %d", start, end, tc.wantStart, tc.wantEnd)
+			}
+		})
+	}
+}
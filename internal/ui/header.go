@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Header selects how much of the action header the selector draws.
+type Header int
+
+const (
+	// HeaderFull draws the action name and description above the list.
+	HeaderFull Header = iota
+	// HeaderCompact draws a single line with the action name and repository.
+	HeaderCompact
+	// HeaderNone leaves the header out.
+	HeaderNone
+)
+
+// fullHeaderRows is how many lines HeaderFull takes: the action name, the
+// description, and a separator.
+const fullHeaderRows = 3
+
+// ParseHeader parses a header style: "full", "compact", or "none".
+func ParseHeader(s string) (Header, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "full":
+		return HeaderFull, nil
+	case "compact":
+		return HeaderCompact, nil
+	case "none":
+		return HeaderNone, nil
+	default:
+		return HeaderFull, fmt.Errorf("unknown header style %q (use full, compact, or none)", s)
+	}
+}
+
+// SetHeader selects the header style.
+func (u *UI) SetHeader(h Header) {
+	if u != nil {
+		u.header = h
+	}
+}
+
+// SetRepository names the repository shown by the compact header.
+func (u *UI) SetRepository(name string) {
+	if u != nil {
+		u.repo = name
+	}
+}
+
+// SetWarning shows text in a banner above the header, whatever the header
+// style, for conditions that must not go unnoticed; "" removes it.
+func (u *UI) SetWarning(text string) {
+	if u != nil {
+		u.warning = strings.TrimSpace(text)
+	}
+}
+
+// bannerStyle draws the warning banner in reverse video, which stands out
+// in every theme.
+const bannerStyle = "\033[1;7m"
+
+// drawBanner writes the warning banner, if any.
+func (u *UI) drawBanner(w io.Writer) error {
+	if u.warning == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "%s ! %s %s%s", bannerStyle, u.warning, resetColor, lineBreak)
+	return err
+}
+
+// compactHeader returns the action name and repository on one line.
+func (u *UI) compactHeader() string {
+	var parts []string
+	for _, part := range []string{u.action.Name, u.repo} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// borderTitle returns the text shown in the top edge of the border.
+func (u *UI) borderTitle() string {
+	switch u.header {
+	case HeaderNone:
+		return ""
+	case HeaderCompact:
+		return u.compactHeader()
+	default:
+		return strings.TrimSpace(u.action.Name)
+	}
+}
+
+// headerRows returns how many lines the header and the warning banner take
+// above the list.
+func (u *UI) headerRows() int {
+	banner := 0
+	if u.warning != "" {
+		banner = 1
+	}
+	switch {
+	case u.header == HeaderNone:
+		return banner
+	case u.header == HeaderCompact && u.border:
+		return banner
+	case u.header == HeaderCompact:
+		return banner + 1
+	}
+	rows := banner + fullHeaderRows - 1 + u.separatorRows()
+	if u.border {
+		// The action name moves into the border title.
+		rows--
+	}
+	return rows
+}
@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   string
+		want    Header
+		wantErr bool
+	}{
+		"default": {input: "", want: HeaderFull},
+		"full":    {input: "full", want: HeaderFull},
+		"compact": {input: "Compact", want: HeaderCompact},
+		"none":    {input: "none", want: HeaderNone},
+		"unknown": {input: "tiny", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseHeader(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseHeader(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("ParseHeader(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectHeaderStyles(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		header Header
+		border bool
+		want   []string
+		absent []string
+		rows   int
+	}{
+		"full": {
+			header: HeaderFull,
+			want:   []string{"Action: Checkout branch", "Switch to the selected branch.", "Select a branch:"},
+			rows:   3,
+		},
+		"compact": {
+			header: HeaderCompact,
+			want:   []string{"Checkout branch · app", "Select a branch:"},
+			absent: []string{"Action:", "Switch to the selected branch."},
+			rows:   1,
+		},
+		"none": {
+			header: HeaderNone,
+			want:   []string{"Select a branch:"},
+			absent: []string{"Checkout branch", "Switch to the selected branch."},
+		},
+		"full with border": {
+			header: HeaderFull,
+			border: true,
+			want:   []string{"╭─ Checkout branch ─", "│ Switch to the selected branch."},
+			absent: []string{"Action:"},
+			rows:   2,
+		},
+		"compact with border": {
+			header: HeaderCompact,
+			border: true,
+			want:   []string{"╭─ Checkout branch · app ─", "│ Select a branch:"},
+		},
+		"none with border": {
+			header: HeaderNone,
+			border: true,
+			want:   []string{"╭──────", "│ Select a branch:"},
+			absent: []string{"Checkout branch"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+			ui.width = 40
+			ui.SetBorder(tc.border)
+			ui.SetHeader(tc.header)
+			ui.SetRepository("app")
+			if _, err := ui.Select([]Branch{{Name: "main", Current: true}}); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			frame := strings.Join(plainLines(framesFromOutput(t, output.String())[0]), "\n")
+			for _, want := range tc.want {
+				if !strings.Contains(frame, want) {
+					t.Fatalf("frame missing %q:\n%s", want, frame)
+				}
+			}
+			for _, absent := range tc.absent {
+				if strings.Contains(frame, absent) {
+					t.Fatalf("frame should not contain %q:\n%s", absent, frame)
+				}
+			}
+			if got := ui.headerRows(); got != tc.rows {
+				t.Fatalf("headerRows() = %d, want %d", got, tc.rows)
+			}
+		})
+	}
+}
+
+func TestSelectPlainCompactHeader(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("1\n"), output, checkoutAction)
+	ui.SetLayout(LayoutPlain)
+	ui.SetHeader(HeaderCompact)
+	ui.SetRepository("app")
+	if _, err := ui.Select([]Branch{{Name: "main"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if !strings.HasPrefix(output.String(), "Checkout branch · app\n1) main\n") {
+		t.Fatalf("unexpected plain output:\n%s", output.String())
+	}
+}
+
+func TestSelectWarningBanner(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		header Header
+		border bool
+		rows   int
+	}{
+		"full":           {header: HeaderFull, rows: 4},
+		"compact":        {header: HeaderCompact, rows: 2},
+		"compact border": {header: HeaderCompact, border: true, rows: 1},
+		"none":           {header: HeaderNone, rows: 1},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+			ui.width = 60
+			ui.SetBorder(tc.border)
+			ui.SetHeader(tc.header)
+			ui.SetWarning("A rebase is in progress.")
+			if _, err := ui.Select([]Branch{{Name: "main", Current: true}}); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			frame := framesFromOutput(t, output.String())[0]
+			if !strings.Contains(frame, bannerStyle+" ! A rebase is in progress. "+resetColor) {
+				t.Fatalf("frame missing the warning banner:\n%q", frame)
+			}
+			if got := ui.headerRows(); got != tc.rows {
+				t.Fatalf("headerRows() = %d, want %d", got, tc.rows)
+			}
+		})
+	}
+}
+
+func TestSelectPlainWarning(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("1\n"), output, checkoutAction)
+	ui.SetLayout(LayoutPlain)
+	ui.SetHeader(HeaderNone)
+	ui.SetWarning("A merge is in progress.")
+	if _, err := ui.Select([]Branch{{Name: "main"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if !strings.HasPrefix(output.String(), "warning: A merge is in progress.\n1) main\n") {
+		t.Fatalf("unexpected plain output:\n%s", output.String())
+	}
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	saveCursor    = "\0337"
+	restoreCursor = "\0338"
+	clearBelow    = "\033[J"
+)
+
+// Height sizes an inline selector, drawn in the lines below the cursor
+// instead of on the whole screen. The zero value keeps the full screen.
+type Height struct {
+	// Lines is a fixed number of lines.
+	Lines int
+	// Percent, when set, takes that share of the terminal height instead.
+	Percent int
+}
+
+// ParseHeight parses a height given as a number of lines ("15") or as a
+// percentage of the terminal height ("40%").
+func ParseHeight(s string) (Height, error) {
+	s = strings.TrimSpace(s)
+	if value, ok := strings.CutSuffix(s, "%"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 100 {
+			return Height{}, fmt.Errorf("invalid height %q: percentages must be between 1%% and 100%%", s)
+		}
+		return Height{Percent: n}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return Height{}, fmt.Errorf("invalid height %q: use a number of lines or a percentage such as 40%%", s)
+	}
+	return Height{Lines: n}, nil
+}
+
+// SetHeight draws the selector inline in the given height; the zero Height
+// uses the whole screen.
+func (u *UI) SetHeight(h Height) {
+	if u != nil {
+		u.inline = h
+	}
+}
+
+// inlineLines returns how many lines the inline selector takes on the
+// current terminal, or 0 to use the whole screen. Percentages need a known
+// terminal size. The height leaves room for at least three rows.
+func (u *UI) inlineLines() int {
+	if u.inline == (Height{}) {
+		return 0
+	}
+	_, termRows := u.terminalSize()
+	lines := u.inline.Lines
+	if u.inline.Percent > 0 {
+		if termRows <= 0 {
+			return 0
+		}
+		lines = termRows * u.inline.Percent / 100
+	}
+	lines = max(lines, u.chromeRows()+3)
+	if termRows > 0 {
+		lines = min(lines, termRows)
+	}
+	return lines
+}
+
+// enterInline reserves the lines of an inline selector below the cursor,
+// scrolling the screen when needed, and saves the position of their first
+// line so every frame can be drawn from there.
+func (u *UI) enterInline() error {
+	lines := u.inlineLines()
+	if lines == 0 {
+		return nil
+	}
+	reserve := "\r" + strings.Repeat("\n", lines-1)
+	if lines > 1 {
+		reserve += fmt.Sprintf("\033[%dA", lines-1)
+	}
+	if _, err := fmt.Fprint(u.out, reserve+saveCursor); err != nil {
+		return err
+	}
+	u.inlineRows = lines
+	return nil
+}
+
+// leaveInline clears the lines used by the inline selector and leaves the
+// cursor where it started. It does nothing for the full-screen selector or
+// when called again.
+func (u *UI) leaveInline() {
+	if u.inlineRows == 0 {
+		return
+	}
+	fmt.Fprint(u.out, restoreCursor+clearBelow)
+	u.inlineRows = 0
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseHeight(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   string
+		want    Height
+		wantErr bool
+	}{
+		"lines":        {input: "15", want: Height{Lines: 15}},
+		"percent":      {input: "40%", want: Height{Percent: 40}},
+		"whole screen": {input: "100%", want: Height{Percent: 100}},
+		"zero lines":   {input: "0", wantErr: true},
+		"zero percent": {input: "0%", wantErr: true},
+		"over 100":     {input: "120%", wantErr: true},
+		"not a number": {input: "half", wantErr: true},
+		"empty":        {input: "", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseHeight(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseHeight(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("ParseHeight(%q) = %+v, want %+v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectInline(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{{Name: "main", Current: true}}
+	for i := 0; i < 20; i++ {
+		branches = append(branches, Branch{Name: fmt.Sprintf("feature/%02d", i)})
+	}
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("j\r"), output, checkoutAction)
+	ui.SetHeight(Height{Lines: 15})
+	result, err := ui.Select(branches)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if result.Branch != "feature/00" {
+		t.Fatalf("unexpected branch selected: %q", result.Branch)
+	}
+
+	out := output.String()
+	reserve := "\r" + strings.Repeat("\n", 14) + "\033[14A" + saveCursor
+	if !strings.HasPrefix(out, reserve) {
+		t.Fatalf("output should start by reserving 15 lines: %q", out)
+	}
+	if strings.Contains(out, cursorHome) {
+		t.Fatalf("inline selector must not clear the screen: %q", out)
+	}
+	if !strings.HasSuffix(out, restoreCursor+clearBelow) {
+		t.Fatalf("inline selector should clear its lines on exit: %q", out)
+	}
+	frames := strings.Split(strings.TrimPrefix(out, reserve), restoreCursor)
+	// Two frames, then the empty remainder after the final clear.
+	if len(frames) != 4 {
+		t.Fatalf("got %d chunks, want 2 frames and the exit: %q", len(frames), frames)
+	}
+	for _, frame := range frames[1:3] {
+		frame = frameText(frame)
+		lines := strings.Split(frame, lineBreak)
+		if len(lines) > 15 {
+			t.Fatalf("frame has %d lines, want at most 15:\n%s", len(lines), frame)
+		}
+		if strings.HasSuffix(frame, lineBreak) {
+			t.Fatalf("frame must not end with a line break: %q", frame)
+		}
+	}
+}
+
+func TestSelectInlineMinimumHeight(t *testing.T) {
+	t.Parallel()
+
+	ui := New(bytes.NewBufferString("q"), &bytes.Buffer{}, checkoutAction)
+	ui.SetHeight(Height{Lines: 2})
+	if got, want := ui.inlineLines(), ui.chromeRows()+3; got != want {
+		t.Fatalf("inlineLines() = %d, want %d", got, want)
+	}
+	ui.SetHeight(Height{Percent: 40})
+	if got := ui.inlineLines(); got != 0 {
+		t.Fatalf("percent height without a terminal should use the full screen, got %d", got)
+	}
+}
+
+func TestSelectInlineAlreadyOn(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("\r"), output, checkoutAction)
+	ui.SetHeight(Height{Lines: 15})
+	if _, err := ui.Select([]Branch{{Name: "main", Current: true}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if !strings.HasSuffix(output.String(), restoreCursor+clearBelow+"already on 'main'"+lineBreak) {
+		t.Fatalf("message should follow the cleared selector: %q", output.String())
+	}
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"bufio"
+	"io"
+)
+
+// inputState is a layer of the selector's key handling. Keys go to the
+// topmost layer, and Esc pops it, so that Esc always undoes the most recent
+// step: a question, then the filter prompt, then the filter it left
+// applied, then the marks.
+type inputState int
+
+const (
+	// stateNormal moves the cursor and runs the action. It lies beneath
+	// every other layer and is never popped.
+	stateNormal inputState = iota
+	// stateMarking holds the rows marked in multi-select mode; popping it
+	// clears the marks.
+	stateMarking
+	// stateFiltered keeps a filter query applied after its prompt closed;
+	// popping it clears the query.
+	stateFiltered
+	// stateFiltering types into the filter query.
+	stateFiltering
+	// stateConfirming asks a question, answered with y; any other key
+	// pops it.
+	stateConfirming
+	// stateLabeling types the labels of a branch; popping it discards
+	// them.
+	stateLabeling
+)
+
+// String names the state for debug logs.
+func (s inputState) String() string {
+	switch s {
+	case stateMarking:
+		return "marking"
+	case stateFiltered:
+		return "filtered"
+	case stateFiltering:
+		return "filtering"
+	case stateConfirming:
+		return "confirming"
+	case stateLabeling:
+		return "labeling"
+	default:
+		return "normal"
+	}
+}
+
+// inputStack holds the layers above stateNormal, the most recent last.
+// Each state appears at most once.
+type inputStack []inputState
+
+// top returns the layer that receives keys.
+func (s inputStack) top() inputState {
+	if len(s) == 0 {
+		return stateNormal
+	}
+	return s[len(s)-1]
+}
+
+// has reports whether state is one of the layers.
+func (s inputStack) has(state inputState) bool {
+	for _, layer := range s {
+		if layer == state {
+			return true
+		}
+	}
+	return false
+}
+
+// push moves state to the top, removing it from further down first.
+func (s *inputStack) push(state inputState) {
+	if state == stateNormal {
+		return
+	}
+	s.remove(state)
+	*s = append(*s, state)
+}
+
+// pop removes the top layer and returns it; stateNormal stays.
+func (s *inputStack) pop() inputState {
+	top := s.top()
+	if len(*s) > 0 {
+		*s = (*s)[:len(*s)-1]
+	}
+	return top
+}
+
+// remove takes state out of the stack wherever it is.
+func (s *inputStack) remove(state inputState) {
+	kept := (*s)[:0]
+	for _, layer := range *s {
+		if layer != state {
+			kept = append(kept, layer)
+		}
+	}
+	*s = kept
+}
+
+// escape pops the top layer of view and undoes what it holds. It reports
+// whether anything changed; Esc with no layer to pop does nothing.
+func (u *UI) escape(view *listView) bool {
+	switch view.input.pop() {
+	case stateConfirming:
+		view.question, view.answer = "", nil
+	case stateLabeling:
+		view.labeling, view.labels = "", ""
+	case stateFiltering:
+		// The query stays applied, so the rows it matched can be marked
+		// or walked with j and k; the next Esc clears it.
+		if view.query != "" {
+			view.input.push(stateFiltered)
+		}
+	case stateFiltered:
+		view.setQuery("")
+	case stateMarking:
+		clear(view.marked)
+	default:
+		return false
+	}
+	return true
+}
+
+// confirm asks question in the notice line and calls answer if the next key
+// is y. Any other key, Esc included, drops the question.
+func (v *listView) confirm(question string, answer func() (Result, bool, error)) {
+	v.question, v.answer = question, answer
+	v.input.push(stateConfirming)
+}
+
+// handleConfirmKey answers the pending question with key b.
+func (u *UI) handleConfirmKey(view *listView, b byte) (Result, bool, error) {
+	answer := view.answer
+	view.input.pop()
+	view.question, view.answer = "", nil
+	if (b == 'y' || b == 'Y') && answer != nil {
+		return answer()
+	}
+	return Result{}, false, nil
+}
+
+// escapeSequence is what followed an ESC byte: a CSI or SS3 sequence, such
+// as ESC [ A for the up arrow or ESC [ 15 ~ for F5, or nothing, when Esc was
+// pressed on its own.
+type escapeSequence struct {
+	// bare reports that Esc was pressed on its own.
+	bare bool
+	// params holds the parameter bytes of a CSI sequence, such as "15".
+	params string
+	// final is the last byte of the sequence, such as 'A'; 0 when the
+	// input ended before it.
+	final byte
+}
+
+// maxEscapeParams bounds how many parameter bytes readEscape reads, so that
+// garbage on the input does not swallow the keys after it.
+const maxEscapeParams = 16
+
+// readEscape reads the rest of a sequence that began with ESC. A terminal
+// sends the bytes of a sequence together, so an ESC with nothing buffered
+// after it, or followed by anything but [ or O, is the Esc key itself; the
+// byte after it is left for the next read.
+func readEscape(reader *bufio.Reader) (escapeSequence, error) {
+	if reader.Buffered() == 0 {
+		return escapeSequence{bare: true}, nil
+	}
+	next, err := reader.Peek(1)
+	if err != nil {
+		return escapeSequence{bare: true}, nil
+	}
+	if next[0] != '[' && next[0] != 'O' {
+		return escapeSequence{bare: true}, nil
+	}
+	introducer, _ := reader.ReadByte()
+
+	var params []byte
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return escapeSequence{}, nil
+		}
+		if err != nil {
+			return escapeSequence{}, err
+		}
+		// SS3 sequences, such as ESC O A from arrows in application mode,
+		// take no parameters.
+		if introducer == '[' && b >= 0x20 && b <= 0x3f && len(params) < maxEscapeParams {
+			params = append(params, b)
+			continue
+		}
+		return escapeSequence{params: string(params), final: b}, nil
+	}
+}
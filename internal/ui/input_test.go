@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectEscapePopsOneLayer(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{{Name: "main", Current: true}, {Name: "done"}, {Name: "feature"}}
+
+	cases := map[string]struct {
+		input  string
+		multi  bool
+		filter string
+		want   Result
+	}{
+		"esc leaves the filter prompt with the query applied": {
+			input: "/fe\x1bj\r",
+			want:  Result{Branch: "feature", Filter: "fe"},
+		},
+		"second esc clears the query": {
+			input: "/fe\x1b\x1bk\r",
+			want:  Result{Branch: "done"},
+		},
+		"esc clears a restored filter": {
+			input:  "\x1bk\r",
+			filter: "fe",
+			want:   Result{Branch: "done"},
+		},
+		"esc with nothing to pop does nothing": {
+			input: "\x1bj\r",
+			want:  Result{Branch: "done"},
+		},
+		"arrows still move while filtering": {
+			input: "/e\x1b[B\r",
+			want:  Result{Branch: "feature", Filter: "e"},
+		},
+		"esc clears the marks": {
+			input: "j \x1b\r",
+			multi: true,
+			want:  Result{Branch: "feature", Marked: []string{"feature"}},
+		},
+		"filter is popped before the marks": {
+			input: "j /feat\x1b\x1b\r",
+			multi: true,
+			want:  Result{Marked: []string{"done"}},
+		},
+		"marks made after filtering are popped first": {
+			input: "/e\x1b \x1b\x1b\r",
+			multi: true,
+			want:  Result{Branch: "feature", Marked: []string{"feature"}},
+		},
+		"quit with marks asks first": {
+			input: "j qn\r",
+			multi: true,
+			want:  Result{Marked: []string{"done"}},
+		},
+		"quit with marks confirmed": {
+			input: "j qy",
+			multi: true,
+			want:  Result{Quit: true},
+		},
+		"esc takes back the question": {
+			input: "j q\x1bq\x1b[A\r",
+			multi: true,
+			want:  Result{Marked: []string{"done"}},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ui := New(bytes.NewBufferString(tc.input), &bytes.Buffer{}, checkoutAction)
+			ui.SetMultiSelect(tc.multi)
+			result, err := ui.SelectWithState(branches, State{Filter: tc.filter})
+			if err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Fatalf("result = %+v, want %+v", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectRendersInputLayers(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("j /o\x1b q"), output, checkoutAction)
+	ui.SetMultiSelect(true)
+	if _, err := ui.Select([]Branch{{Name: "main", Current: true}, {Name: "done"}, {Name: "old"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	frames := framesFromOutput(t, output.String())
+	want := map[int]string{
+		3: "type to filter, ↑/↓ to move, Enter to select, Esc to stop filtering",
+		5: "j/k or ↑/↓ to move, / to filter, Space to mark, a to mark all, Esc to clear the filter, Enter to",
+		6: "j/k or ↑/↓ to move, / to filter, Space to mark, a to mark all, Esc to clear the marks, Enter to",
+		7: "y to confirm, any other key to go back",
+	}
+	for frame, hint := range want {
+		if lines := plainLines(frames[frame]); !containsPrefix(lines, hint) {
+			t.Fatalf("frame %d missing %q: %q", frame, hint, lines)
+		}
+	}
+	if lines := plainLines(frames[7]); !containsPrefix(lines, "Discard 2 marked branches and quit?") {
+		t.Fatalf("question missing from %q", lines)
+	}
+}
+
+func TestReadEscape(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input    string
+		want     escapeSequence
+		wantNext string
+	}{
+		"bare":          {input: "", want: escapeSequence{bare: true}},
+		"bare then key": {input: "j", want: escapeSequence{bare: true}, wantNext: "j"},
+		"arrow":         {input: "[B", want: escapeSequence{final: 'B'}},
+		"application":   {input: "OA", want: escapeSequence{final: 'A'}},
+		"f5":            {input: "[15~x", want: escapeSequence{params: "15", final: '~'}, wantNext: "x"},
+		"cut short":     {input: "[1", want: escapeSequence{}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := bufio.NewReader(strings.NewReader("\x1b" + tc.input))
+			if _, err := reader.ReadByte(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readEscape(reader)
+			if err != nil {
+				t.Fatalf("readEscape returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("readEscape() = %+v, want %+v", got, tc.want)
+			}
+			rest := make([]byte, 8)
+			n, _ := reader.Read(rest)
+			if string(rest[:n]) != tc.wantNext {
+				t.Fatalf("left %q unread, want %q", rest[:n], tc.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Labeler saves the labels typed for a branch with the l key; no labels
+// removes them all.
+type Labeler func(branch Branch, labels []string) error
+
+// SetLabeler enables the l key, which edits the labels of the highlighted
+// branch as a comma-separated list, hands them to l on Enter, and reads the
+// rows again with the Reloader so that their chips show the change.
+func (u *UI) SetLabeler(l Labeler) {
+	if u != nil {
+		u.labeler = l
+	}
+}
+
+// labelColors are the colors of the label chips. Each label keeps the same
+// one, picked by its text, so that equal labels are easy to spot. They are
+// basic ANSI colors, which every terminal and theme can show.
+var labelColors = []string{
+	"\033[1;33m",
+	"\033[1;36m",
+	"\033[1;35m",
+	"\033[1;34m",
+	"\033[1;32m",
+	"\033[1;31m",
+}
+
+// labelColor returns the color of the chip of label.
+func labelColor(label string) string {
+	h := fnv.New32a()
+	h.Write([]byte(label))
+	return labelColors[h.Sum32()%uint32(len(labelColors))]
+}
+
+// labelChips returns the chips of labels without color, as in
+// "[waiting on review] [demo Friday]".
+func labelChips(labels []string) string {
+	chips := make([]string, len(labels))
+	for i, label := range labels {
+		chips[i] = "[" + label + "]"
+	}
+	return strings.Join(chips, " ")
+}
+
+// coloredChips returns the chips of labels in their colors, followed by
+// after, which restores the color of the rest of the row.
+func coloredChips(labels []string, after string) string {
+	chips := make([]string, len(labels))
+	for i, label := range labels {
+		chips[i] = labelColor(label) + "[" + label + "]" + resetColor + after
+	}
+	return strings.Join(chips, " ")
+}
+
+// ParseLabels splits text at commas into labels, dropping empty and
+// repeated ones.
+func ParseLabels(text string) []string {
+	labels := []string{}
+	seen := map[string]bool{}
+	for _, label := range strings.Split(text, ",") {
+		label = strings.Join(strings.Fields(label), " ")
+		if label != "" && !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// startLabeling opens the label prompt for the highlighted branch, filled
+// with its current labels.
+func (u *UI) startLabeling(view *listView) bool {
+	selected, ok := view.selected()
+	if u.labeler == nil || !ok {
+		return false
+	}
+	view.labeling = selected.Name
+	view.labels = strings.Join(selected.Labels, ", ")
+	view.input.push(stateLabeling)
+	return true
+}
+
+// handleLabelKey applies a key typed while the label prompt is open. Enter
+// saves the labels, and Esc discards them.
+func (u *UI) handleLabelKey(reader *bufio.Reader, view *listView, b byte) (bool, error) {
+	switch {
+	case b == 0x1b:
+		seq, err := readEscape(reader)
+		if err != nil || !seq.bare {
+			return false, err
+		}
+		return u.escape(view), nil
+	case b == '\r' || b == '\n':
+		return u.saveLabels(view)
+	case b == 0x7f || b == 0x08: // Backspace
+		if view.labels == "" {
+			return false, nil
+		}
+		_, size := utf8.DecodeLastRuneInString(view.labels)
+		view.labels = view.labels[:len(view.labels)-size]
+		return true, nil
+	case b == 0x15: // Ctrl+U
+		view.labels = ""
+		return true, nil
+	case b < 0x20:
+		return false, nil
+	}
+	view.labels += typedText(reader, b)
+	return true, nil
+}
+
+// saveLabels hands the typed labels to the Labeler and reloads the rows. A
+// failing Labeler shows why in the notice line.
+func (u *UI) saveLabels(view *listView) (bool, error) {
+	name, labels := view.labeling, ParseLabels(view.labels)
+	view.input.pop()
+	view.labeling, view.labels = "", ""
+	selected, ok := view.selected()
+	if !ok || selected.Name != name {
+		selected = Branch{Name: name}
+	}
+	if err := u.labeler(selected, labels); err != nil {
+		view.notice = fmt.Sprintf("labels not saved: %v", err)
+		return true, nil
+	}
+	if _, err := u.reload(view); err != nil {
+		return false, err
+	}
+	if view.notice == "" {
+		view.notice = fmt.Sprintf("labelled '%s' %s", name, labelChips(labels))
+		if len(labels) == 0 {
+			view.notice = fmt.Sprintf("removed the labels of '%s'", name)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectEditsLabels(t *testing.T) {
+	t.Parallel()
+
+	rows := []Branch{{Name: "main", Current: true}, {Name: "feature/x", Labels: []string{"wip"}}}
+	cases := map[string]struct {
+		input  string
+		err    error
+		want   []string
+		saved  bool
+		notice string
+	}{
+		"appended": {
+			input:  "jl, demo Friday\r",
+			want:   []string{"wip", "demo Friday"},
+			saved:  true,
+			notice: "labelled 'feature/x' [wip] [demo Friday]",
+		},
+		"replaced": {
+			input:  "jl\x15waiting on review,,  waiting on review \r",
+			want:   []string{"waiting on review"},
+			saved:  true,
+			notice: "labelled 'feature/x' [waiting on review]",
+		},
+		"cleared": {
+			input:  "jl\x7f\x7f\x7f\r",
+			want:   []string{},
+			saved:  true,
+			notice: "removed the labels of 'feature/x'",
+		},
+		"cancelled with esc": {
+			input: "jlfoo\x1b",
+		},
+		"failing labeler": {
+			input:  "jl\r",
+			err:    errors.New("read-only config"),
+			want:   []string{"wip"},
+			saved:  true,
+			notice: "labels not saved: read-only config",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var saved []string
+			calls, reloads := 0, 0
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString(tc.input+"q"), output, checkoutAction)
+			ui.SetLabeler(func(branch Branch, labels []string) error {
+				calls++
+				if branch.Name != "feature/x" {
+					t.Errorf("labelled %q, want feature/x", branch.Name)
+				}
+				saved = labels
+				return tc.err
+			})
+			ui.SetReloader(func() (Reloaded, error) {
+				reloads++
+				return Reloaded{Rows: Branches(rows)}, nil
+			})
+			if _, err := ui.Select(rows); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			wantCalls, wantReloads := 0, 0
+			if tc.saved {
+				wantCalls = 1
+				if tc.err == nil {
+					wantReloads = 1
+				}
+			}
+			if calls != wantCalls || reloads != wantReloads {
+				t.Fatalf("labeler called %d times and reloaded %d times, want %d and %d", calls, reloads, wantCalls, wantReloads)
+			}
+			if tc.saved && !reflect.DeepEqual(saved, tc.want) {
+				t.Fatalf("labels = %q, want %q", saved, tc.want)
+			}
+
+			frames := framesFromOutput(t, output.String())
+			if !containsPrefix(plainLines(frames[0]), "  feature/x [wip]") {
+				t.Fatalf("label chip missing from frame %q", plainLines(frames[0]))
+			}
+			if !containsPrefix(plainLines(frames[0]), "j/k or ↑/↓ to move, / to filter, l to label,") {
+				t.Fatalf("label hint missing from frame %q", plainLines(frames[0]))
+			}
+			if !containsPrefix(plainLines(frames[2]), "Labels for 'feature/x': wip") {
+				t.Fatalf("label prompt missing from frame %q", plainLines(frames[2]))
+			}
+			if tc.notice != "" {
+				if last := plainLines(frames[len(frames)-1]); !containsPrefix(last, tc.notice) {
+					t.Fatalf("notice %q missing from frame %q", tc.notice, last)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	t.Parallel()
+
+	got := ParseLabels(" waiting on  review, demo Friday,,waiting on review , ")
+	if want := []string{"waiting on review", "demo Friday"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLabels() = %q, want %q", got, want)
+	}
+}
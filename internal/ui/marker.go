@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Marker is the cursor marker drawn before the highlighted row.
+type Marker struct {
+	// Symbol is drawn before the row, followed by a space. Empty draws no
+	// marker and extends the highlight over the whole row instead.
+	Symbol string
+	// Color is an SGR sequence for the symbol, as returned by ParseColor.
+	// Empty draws it in the style of the highlighted row.
+	Color string
+}
+
+// DefaultMarker is the ">" marker used unless SetMarker is called.
+var DefaultMarker = Marker{Symbol: ">"}
+
+// SetMarker replaces the cursor marker drawn before the highlighted row.
+func (u *UI) SetMarker(m Marker) {
+	if u != nil {
+		u.marker = m
+	}
+}
+
+// Basic returns the marker with its color mapped to the basic ANSI colors.
+func (m Marker) Basic() Marker {
+	return Marker{Symbol: m.Symbol, Color: basicSGR(m.Color)}
+}
+
+// width returns the number of cells the marker and its trailing space take.
+func (m Marker) width() int {
+	if m.Symbol == "" {
+		return 0
+	}
+	return displayWidth(m.Symbol) + 1
+}
+
+// prefix returns the marker as drawn at the start of a row styled with
+// theme.Selected.
+func (m Marker) prefix(theme Theme) string {
+	switch {
+	case m.Symbol == "":
+		return ""
+	case m.Color == "":
+		return m.Symbol + " "
+	default:
+		return m.Color + m.Symbol + resetColor + theme.Selected + " "
+	}
+}
+
+// colorNames maps color names to the basic ANSI color they select.
+var colorNames = map[string]int{
+	"black": 0, "red": 1, "green": 2, "yellow": 3, "blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+	"gray": 8, "grey": 8, "bright-black": 8, "bright-red": 9, "bright-green": 10, "bright-yellow": 11,
+	"bright-blue": 12, "bright-magenta": 13, "bright-cyan": 14, "bright-white": 15,
+}
+
+// ParseColor returns the SGR sequence that sets the foreground to spec: a
+// color name such as "red" or "bright-cyan", a 256-color index, or #rrggbb.
+// An empty spec yields an empty sequence.
+func ParseColor(spec string) (string, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return "", nil
+	}
+	if c, ok := colorNames[spec]; ok {
+		return fmt.Sprintf("\033[%dm", basicColorCode(c, false)), nil
+	}
+	if n, err := strconv.Atoi(spec); err == nil && n >= 0 && n <= 255 {
+		return fmt.Sprintf("\033[38;5;%dm", n), nil
+	}
+	if hex, ok := strings.CutPrefix(spec, "#"); ok && len(hex) == 6 {
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return fmt.Sprintf("\033[38;2;%d;%d;%dm", v>>16, v>>8&0xff, v&0xff), nil
+		}
+	}
+	return "", fmt.Errorf("invalid color %q: use a color name, a 256-color index, or #rrggbb", spec)
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		"empty":         {spec: "", want: ""},
+		"name":          {spec: "Red", want: "\033[31m"},
+		"bright name":   {spec: "bright-cyan", want: "\033[96m"},
+		"gray":          {spec: "grey", want: "\033[90m"},
+		"index":         {spec: "203", want: "\033[38;5;203m"},
+		"hex":           {spec: "#F38BA8", want: "\033[38;2;243;139;168m"},
+		"index too big": {spec: "256", wantErr: true},
+		"short hex":     {spec: "#fff", wantErr: true},
+		"unknown name":  {spec: "teal", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseColor(tc.spec)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ParseColor(%q) = %q, want an error", tc.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseColor(%q) returned error: %v", tc.spec, err)
+			}
+			if got != tc.want {
+				t.Fatalf("ParseColor(%q) = %q, want %q", tc.spec, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectWithMarker(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/a-rather-long-branch-name", Detail: "(merged)"},
+	}
+	cases := map[string]struct {
+		marker Marker
+		want   []string
+	}{
+		"default": {
+			marker: DefaultMarker,
+			want:   []string{"> main (current branch)", "  feature/a-rather-… (merged)"},
+		},
+		"wide glyph": {
+			marker: Marker{Symbol: "➜"},
+			want:   []string{"➜ main (current branch)", "  feature/a-rather-… (merged)"},
+		},
+		"double-width glyph": {
+			marker: Marker{Symbol: "👉"},
+			want:   []string{"👉 main (current branch)", "   feature/a-rather… (merged)"},
+		},
+		"none": {
+			marker: Marker{},
+			want:   []string{"main (current branch)        ", "feature/a-rather-lo… (merged)"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+			ui.width = 29
+			ui.SetMarker(tc.marker)
+			if _, err := ui.Select(branches); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			lines := plainLines(framesFromOutput(t, output.String())[0])
+			for _, row := range tc.want {
+				if !containsPrefix(lines, row) {
+					t.Fatalf("missing row %q in %q", row, lines)
+				}
+				if w := displayWidth(row); w > 29 {
+					t.Fatalf("row %q is %d cells wide, want at most 29", row, w)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectWithColoredMarker(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := NewWithTheme(bytes.NewBufferString("q"), output, checkoutAction, ThemeNord)
+	ui.SetMarker(Marker{Symbol: "●", Color: "\033[31m"})
+	if _, err := ui.Select([]Branch{{Name: "main"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	want := ThemeNord.Selected + "\033[31m●" + resetColor + ThemeNord.Selected + " main"
+	if !strings.Contains(output.String(), want) {
+		t.Fatalf("colored marker missing %q:\n%q", want, output.String())
+	}
+}
+
+func TestMarkerBasic(t *testing.T) {
+	t.Parallel()
+
+	got := Marker{Symbol: "➜", Color: "\033[38;5;203m"}.Basic()
+	if got != (Marker{Symbol: "➜", Color: "\033[91m"}) {
+		t.Fatalf("Basic() = %+v", got)
+	}
+}
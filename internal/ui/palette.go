@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is the number of colors a terminal can render.
+type ColorDepth int
+
+const (
+	// Colors16 covers terminals limited to the 8 basic ANSI colors and their bright variants.
+	Colors16 ColorDepth = iota
+	// Colors256 covers terminals with the xterm 256-color palette.
+	Colors256
+	// ColorsTrue covers terminals with 24-bit color.
+	ColorsTrue
+)
+
+// DetectColorDepth guesses the color support of the terminal from TERM,
+// COLORTERM, and variables set by common terminal emulators. Terminals that
+// give no sign of more are assumed to have 16 colors.
+func DetectColorDepth(getenv func(string) string) ColorDepth {
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorsTrue
+	}
+	termName := strings.ToLower(getenv("TERM"))
+	for _, marker := range []string{"256color", "direct", "kitty", "alacritty", "wezterm", "foot", "ghostty"} {
+		if strings.Contains(termName, marker) {
+			return Colors256
+		}
+	}
+	if getenv("COLORTERM") != "" || getenv("WT_SESSION") != "" {
+		return Colors256
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "Apple_Terminal", "vscode", "WezTerm":
+		return Colors256
+	}
+	return Colors16
+}
+
+// Basic returns the theme with every 256-color sequence replaced by the
+// nearest of the 16 basic ANSI colors, for terminals without the extended
+// palette.
+func (t Theme) Basic() Theme {
+	return Theme{
+		ActionLabel:       basicSGR(t.ActionLabel),
+		ActionDescription: basicSGR(t.ActionDescription),
+		Branch:            basicSGR(t.Branch),
+		Selected:          basicSGR(t.Selected),
+		SelectedBadge:     basicSGR(t.SelectedBadge),
+		Badge:             basicSGR(t.Badge),
+		Help:              basicSGR(t.Help),
+	}
+}
+
+// Basic returns the family with both variants mapped to basic ANSI colors.
+func (f ThemeFamily) Basic() ThemeFamily {
+	return ThemeFamily{Dark: f.Dark.Basic(), Light: f.Light.Basic()}
+}
+
+// basicSGR rewrites the 38;5;N and 48;5;N parameters of an SGR escape
+// sequence, and their 24-bit 38;2;R;G;B forms, to basic foreground and
+// background colors, keeping the others.
+func basicSGR(seq string) string {
+	body, ok := strings.CutPrefix(seq, "\033[")
+	if !ok || !strings.HasSuffix(body, "m") {
+		return seq
+	}
+	params := strings.Split(strings.TrimSuffix(body, "m"), ";")
+	out := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		background := params[i] == "48"
+		if (params[i] == "38" || background) && i+2 < len(params) && params[i+1] == "5" {
+			if n, err := strconv.Atoi(params[i+2]); err == nil && n >= 0 && n <= 255 {
+				out = append(out, strconv.Itoa(basicColorCode(nearestBasicColor(n), background)))
+				i += 2
+				continue
+			}
+		}
+		if (params[i] == "38" || background) && i+4 < len(params) && params[i+1] == "2" {
+			if rgb, ok := parseRGB(params[i+2 : i+5]); ok {
+				out = append(out, strconv.Itoa(basicColorCode(basicColorOf(rgb), background)))
+				i += 4
+				continue
+			}
+		}
+		out = append(out, params[i])
+	}
+	return "\033[" + strings.Join(out, ";") + "m"
+}
+
+// basicColorCode returns the SGR code of basic color c (0-15).
+func basicColorCode(c int, background bool) int {
+	base := 30
+	if c >= 8 {
+		base, c = 90, c-8
+	}
+	if background {
+		base += 10
+	}
+	return base + c
+}
+
+// nearestBasicColor maps an xterm 256-color index to a basic color.
+// Nearest-RGB matching turns the pastel tones the themes use into grays, so
+// the hue is kept instead: pale tints and colorless tones become black,
+// gray, white, or bright white by lightness, and colored ones take the basic
+// color of their dominant channels, bright when light.
+func nearestBasicColor(n int) int {
+	if n < 16 {
+		return n
+	}
+	return basicColorOf(xtermRGB(n))
+}
+
+// basicColorOf maps an RGB color to a basic color as nearestBasicColor does.
+func basicColorOf(rgb [3]int) int {
+	hi := max(rgb[0], rgb[1], rgb[2])
+	lo := min(rgb[0], rgb[1], rgb[2])
+	if lo >= 175 {
+		// Pale tints read as white rather than as their hue.
+		if hi < 240 {
+			return 7
+		}
+		return 15
+	}
+	if hi-lo < 30 {
+		switch {
+		case hi < 64:
+			return 0
+		case hi < 160:
+			return 8
+		case hi < 224:
+			return 7
+		default:
+			return 15
+		}
+	}
+	mid := (hi + lo) / 2
+	c := 0
+	for bit, channel := range rgb {
+		if channel > mid {
+			c |= 1 << bit
+		}
+	}
+	if hi >= 200 {
+		c += 8
+	}
+	return c
+}
+
+// parseRGB parses the three channel parameters of a 24-bit SGR color.
+func parseRGB(params []string) ([3]int, bool) {
+	var rgb [3]int
+	for i, param := range params {
+		v, err := strconv.Atoi(param)
+		if err != nil || v < 0 || v > 255 {
+			return rgb, false
+		}
+		rgb[i] = v
+	}
+	return rgb, true
+}
+
+// xtermRGB returns the color of an index in the 6x6x6 cube (16-231) or the
+// gray ramp (232-255) of the xterm palette.
+func xtermRGB(n int) [3]int {
+	if n >= 232 {
+		level := 8 + (n-232)*10
+		return [3]int{level, level, level}
+	}
+	n -= 16
+	level := func(v int) int {
+		if v == 0 {
+			return 0
+		}
+		return 55 + v*40
+	}
+	return [3]int{level(n / 36), level(n / 6 % 6), level(n % 6)}
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectColorDepth(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		env  map[string]string
+		want ColorDepth
+	}{
+		"bare xterm":       {env: map[string]string{"TERM": "xterm"}, want: Colors16},
+		"linux console":    {env: map[string]string{"TERM": "linux"}, want: Colors16},
+		"screen":           {env: map[string]string{"TERM": "screen"}, want: Colors16},
+		"256color":         {env: map[string]string{"TERM": "xterm-256color"}, want: Colors256},
+		"tmux 256color":    {env: map[string]string{"TERM": "tmux-256color"}, want: Colors256},
+		"kitty":            {env: map[string]string{"TERM": "xterm-kitty"}, want: Colors256},
+		"truecolor":        {env: map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"}, want: ColorsTrue},
+		"other colorterm":  {env: map[string]string{"TERM": "xterm", "COLORTERM": "yes"}, want: Colors256},
+		"windows terminal": {env: map[string]string{"TERM": "", "WT_SESSION": "1"}, want: Colors256},
+		"apple terminal":   {env: map[string]string{"TERM": "xterm", "TERM_PROGRAM": "Apple_Terminal"}, want: Colors256},
+		"nothing is known": {env: map[string]string{}, want: Colors16},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			getenv := func(key string) string { return tc.env[key] }
+			if got := DetectColorDepth(getenv); got != tc.want {
+				t.Fatalf("DetectColorDepth() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBasicSGR(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		seq  string
+		want string
+	}{
+		"foreground":            {seq: "\033[38;5;203m", want: "\033[91m"},
+		"foreground and bold":   {seq: "\033[1;38;5;111m", want: "\033[1;94m"},
+		"background":            {seq: "\033[1;38;5;234;48;5;111m", want: "\033[1;30;104m"},
+		"dark gray":             {seq: "\033[38;5;240m", want: "\033[90m"},
+		"pale tint":             {seq: "\033[38;5;189m", want: "\033[97m"},
+		"light gray":            {seq: "\033[38;5;188m", want: "\033[37m"},
+		"basic color unchanged": {seq: "\033[1;36m", want: "\033[1;36m"},
+		"basic index":           {seq: "\033[38;5;2m", want: "\033[32m"},
+		"truecolor":             {seq: "\033[1;38;2;235;111;146m", want: "\033[1;91m"},
+		"truecolor background":  {seq: "\033[48;2;30;30;46m", want: "\033[40m"},
+		"invalid truecolor":     {seq: "\033[38;2;300;0;0m", want: "\033[38;2;300;0;0m"},
+		"reset":                 {seq: "\033[0m", want: "\033[0m"},
+		"not an sgr sequence":   {seq: "plain", want: "plain"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := basicSGR(tc.seq); got != tc.want {
+				t.Fatalf("basicSGR(%q) = %q, want %q", tc.seq, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestThemeBasicUsesNo256ColorSequences(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range AvailableThemeNames() {
+		family, _ := ThemeFamilyByName(name)
+		for _, theme := range []Theme{family.Dark.Basic(), family.Light.Basic()} {
+			for _, seq := range []string{theme.ActionLabel, theme.ActionDescription, theme.Branch, theme.Selected, theme.SelectedBadge, theme.Badge, theme.Help} {
+				if strings.Contains(seq, "38;5;") || strings.Contains(seq, "48;5;") {
+					t.Fatalf("%s: basic theme still uses a 256-color sequence: %q", name, seq)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Layout selects how the selector presents its rows.
+type Layout int
+
+const (
+	// LayoutAuto draws the interactive screen when both streams are
+	// terminals and TERM is not dumb, and falls back to LayoutPlain otherwise.
+	LayoutAuto Layout = iota
+	// LayoutInteractive always draws the interactive screen.
+	LayoutInteractive
+	// LayoutPlain prints a numbered list that is answered by typing a row
+	// number, with no colors, cursor movement, or screen clears.
+	LayoutPlain
+)
+
+// SetLayout overrides how the selector presents its rows; the default is LayoutAuto.
+func (u *UI) SetLayout(layout Layout) {
+	if u != nil {
+		u.layout = layout
+	}
+}
+
+// resolveLayout applies LayoutAuto detection. Streams other than *os.File
+// are assumed to be interactive so that callers can drive the selector
+// directly.
+func (u *UI) resolveLayout() Layout {
+	if u.layout != LayoutAuto {
+		return u.layout
+	}
+	getenv := u.getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if !isTerminal(u.in) || !isTerminal(u.out) || getenv("TERM") == "dumb" {
+		return LayoutPlain
+	}
+	return LayoutInteractive
+}
+
+func isTerminal(stream any) bool {
+	file, ok := stream.(*os.File)
+	if !ok {
+		return true
+	}
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// selectPlain prints every visible row as a numbered list and reads the
+// number of the chosen row from the input. The saved filter and cursor are
+// not applied, but the filter is handed back so that it is not lost.
+func (u *UI) selectPlain(p Provider, state State) (Result, error) {
+	if state.Updates != nil {
+		// The list is printed once, so wait for the final rows.
+		for range state.Updates {
+		}
+	}
+	view := newListView(p, "", state.Mode, state.Visibility, state.Load)
+	if _, err := view.fill(math.MaxInt); err != nil {
+		return Result{}, err
+	}
+	quit := Result{Quit: true, Filter: state.Filter}
+
+	if u.warning != "" {
+		if _, err := fmt.Fprintf(u.out, "warning: %s\n", u.warning); err != nil {
+			return Result{}, err
+		}
+	}
+	switch u.header {
+	case HeaderNone:
+	case HeaderCompact:
+		if text := u.compactHeader(); text != "" {
+			if _, err := fmt.Fprintln(u.out, text); err != nil {
+				return Result{}, err
+			}
+		}
+	default:
+		if name := strings.TrimSpace(u.action.Name); name != "" {
+			if _, err := fmt.Fprintf(u.out, "Action: %s\n", name); err != nil {
+				return Result{}, err
+			}
+		}
+		if description := strings.TrimSpace(u.action.Description); description != "" {
+			if _, err := fmt.Fprintln(u.out, description); err != nil {
+				return Result{}, err
+			}
+		}
+	}
+	if len(view.visible) == 0 {
+		_, err := fmt.Fprintln(u.out, "No branches to select.")
+		return quit, err
+	}
+
+	digits := len(strconv.Itoa(len(view.visible)))
+	for i, idx := range view.visible {
+		branch := view.rows.Branch(idx)
+		suffix := ""
+		if badge := rowBadge(branch); badge != "" {
+			suffix = " " + badge
+		}
+		if len(branch.Labels) > 0 {
+			suffix += " " + labelChips(branch.Labels)
+		}
+		if detail := strings.TrimSpace(view.rows.Detail(idx)); detail != "" && !branch.Current {
+			suffix += " " + detail
+		}
+		if _, err := fmt.Fprintf(u.out, "%*d) %s%s\n", digits, i+1, branch.Name, suffix); err != nil {
+			return Result{}, err
+		}
+	}
+
+	enterLabel := strings.TrimSpace(u.action.EnterLabel)
+	if enterLabel == "" {
+		enterLabel = "select"
+	}
+	reader := LineReader(u.in)
+	if u.multi {
+		return u.readPlainMarks(reader, view, enterLabel, state.Filter)
+	}
+	for {
+		if _, err := fmt.Fprintf(u.out, "Enter a number to %s, or q to quit: ", enterLabel); err != nil {
+			return Result{}, err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Result{}, err
+		}
+		atEOF := err != nil
+		text := strings.TrimSpace(line)
+		if text == "q" || text == "Q" || (text == "" && atEOF) {
+			return quit, nil
+		}
+
+		n, convErr := strconv.Atoi(text)
+		if convErr != nil || n < 1 || n > len(view.visible) {
+			if _, err := fmt.Fprintf(u.out, "Please enter a number from 1 to %d.\n", len(view.visible)); err != nil {
+				return Result{}, err
+			}
+			if atEOF {
+				return quit, nil
+			}
+			continue
+		}
+
+		selected := view.rows.Branch(view.visible[n-1])
+		if selected.Current && !u.action.AllowCurrent {
+			if _, err := fmt.Fprintf(u.out, "already on '%s'\n", selected.Name); err != nil {
+				return Result{}, err
+			}
+			return Result{Branch: selected.Name, AlreadyOn: true, Filter: state.Filter}, nil
+		}
+		return Result{Branch: selected.Name, Remote: selected.Remote, Filter: state.Filter}, nil
+	}
+}
+
+// readPlainMarks reads the numbers of several rows, such as "1 3-5", for
+// multi-select mode. The current branch cannot be chosen.
+func (u *UI) readPlainMarks(reader *bufio.Reader, view *listView, enterLabel, filter string) (Result, error) {
+	quit := Result{Quit: true, Filter: filter}
+	for {
+		if _, err := fmt.Fprintf(u.out, "Enter numbers such as 1 3-5 to %s, or q to quit: ", enterLabel); err != nil {
+			return Result{}, err
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Result{}, err
+		}
+		atEOF := err != nil
+		text := strings.TrimSpace(line)
+		if text == "q" || text == "Q" || (text == "" && atEOF) {
+			return quit, nil
+		}
+
+		numbers, parseErr := parseNumberList(text, len(view.visible))
+		for _, n := range numbers {
+			if parseErr == nil && view.rows.Branch(view.visible[n-1]).Current {
+				parseErr = fmt.Errorf("%d is the current branch", n)
+			}
+		}
+		if parseErr == nil && len(numbers) == 0 {
+			parseErr = errors.New("no rows chosen")
+		}
+		if parseErr != nil {
+			if _, err := fmt.Fprintf(u.out, "%v; enter numbers from 1 to %d.\n", parseErr, len(view.visible)); err != nil {
+				return Result{}, err
+			}
+			if atEOF {
+				return quit, nil
+			}
+			continue
+		}
+
+		for _, n := range numbers {
+			if view.marked == nil {
+				view.marked = map[int]bool{}
+			}
+			view.marked[view.visible[n-1]] = true
+		}
+		return Result{Marked: view.markedNames(), Filter: filter}, nil
+	}
+}
+
+// parseNumberList parses numbers and ranges from 1 to limit separated by
+// spaces or commas, such as "1, 3-5".
+func parseNumberList(text string, limit int) ([]int, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+	numbers := []int{}
+	for _, field := range fields {
+		low, high, isRange := strings.Cut(field, "-")
+		first, err := strconv.Atoi(low)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", field)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(high); err != nil {
+				return nil, fmt.Errorf("%q is not a range", field)
+			}
+		}
+		if first < 1 || last > limit || first > last {
+			return nil, fmt.Errorf("%q is out of range", field)
+		}
+		for n := first; n <= last; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
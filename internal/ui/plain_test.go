@@ -0,0 +1,312 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectPlain(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/x", Detail: "(merged)", Merged: true},
+		{Name: "origin/topic", Remote: true},
+		{Name: "fix/y"},
+	}
+
+	cases := map[string]struct {
+		input      string
+		visibility *Visibility
+		want       Result
+		wantOut    string
+	}{
+		"select": {
+			input: "2\n",
+			want:  Result{Branch: "feature/x", Filter: "saved"},
+		},
+		"remote": {
+			input: "3\n",
+			want:  Result{Branch: "origin/topic", Remote: true, Filter: "saved"},
+		},
+		"retry-after-invalid": {
+			input:   "abc\n9\n4\n",
+			want:    Result{Branch: "fix/y", Filter: "saved"},
+			wantOut: "Please enter a number from 1 to 4.\n",
+		},
+		"current": {
+			input:   "1\n",
+			want:    Result{Branch: "main", AlreadyOn: true, Filter: "saved"},
+			wantOut: "already on 'main'\n",
+		},
+		"quit": {
+			input: "q\n",
+			want:  Result{Quit: true, Filter: "saved"},
+		},
+		"eof": {
+			input: "",
+			want:  Result{Quit: true, Filter: "saved"},
+		},
+		"hidden-rows-are-not-numbered": {
+			input:      "2\n",
+			visibility: &Visibility{Merged: true},
+			want:       Result{Branch: "feature/x", Filter: "saved"},
+			wantOut:    "1) main (current branch)\n2) feature/x (merged)\n3) fix/y\n",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString(tc.input), output, checkoutAction)
+			ui.SetLayout(LayoutPlain)
+			got, err := ui.SelectWithState(branches, State{Filter: "saved", Cursor: "fix/y", Visibility: tc.visibility})
+			if err != nil {
+				t.Fatalf("SelectWithState returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("result = %+v, want %+v", got, tc.want)
+			}
+			out := output.String()
+			if strings.Contains(out, "\033") || strings.Contains(out, "\r") {
+				t.Fatalf("plain output contains control sequences: %q", out)
+			}
+			if tc.wantOut != "" && !strings.Contains(out, tc.wantOut) {
+				t.Fatalf("output %q does not contain %q", out, tc.wantOut)
+			}
+		})
+	}
+}
+
+func TestSelectPlainThenConfirm(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{{Name: "main", Current: true}, {Name: "feature"}}
+	cases := map[string]struct {
+		input string
+		want  bool
+	}{
+		"typed token": {input: "2\nfeature\n", want: true},
+		"declined":    {input: "2\n\n", want: false},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// One reader for the whole run, as main passes piped stdin.
+			in := LineReader(strings.NewReader(tc.input))
+			ui := New(in, io.Discard, checkoutAction)
+			ui.SetLayout(LayoutPlain)
+			got, err := ui.SelectWithState(branches, State{})
+			if err != nil {
+				t.Fatalf("SelectWithState returned error: %v", err)
+			}
+			if got.Branch != "feature" {
+				t.Fatalf("selected %q, want feature", got.Branch)
+			}
+			confirmed, err := TypedConfirmation{Token: got.Branch}.Confirm(in, io.Discard)
+			if err != nil {
+				t.Fatalf("Confirm returned error: %v", err)
+			}
+			if confirmed != tc.want {
+				t.Fatalf("confirmed = %v, want %v", confirmed, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectPlainListing(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{{Name: "main", Current: true}}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		branches = append(branches, Branch{Name: name})
+	}
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("q\n"), output, checkoutAction)
+	ui.SetLayout(LayoutPlain)
+	if _, err := ui.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	want := "Action: Checkout branch\n" +
+		"Switch to the selected branch.\n" +
+		" 1) main (current branch)\n" +
+		" 2) a\n" + " 3) b\n" + " 4) c\n" + " 5) d\n" + " 6) e\n" +
+		" 7) f\n" + " 8) g\n" + " 9) h\n" + "10) i\n" + "11) j\n" +
+		"Enter a number to checkout the selected branch, or q to quit: "
+	if got := output.String(); got != want {
+		t.Fatalf("plain output =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSelectPlainDefaultBadge(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("q\n"), output, checkoutAction)
+	ui.SetLayout(LayoutPlain)
+	branches := []Branch{{Name: "feature/x", Current: true}, {Name: "main", Default: true, Detail: "(merged)"}, {Name: "fix/y"}}
+	if _, err := ui.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	want := "1) feature/x (current branch)\n2) main (default) (merged)\n3) fix/y\n"
+	if got := output.String(); !strings.Contains(got, want) {
+		t.Fatalf("plain output %q does not contain %q", got, want)
+	}
+}
+
+func TestSelectPlainEmpty(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("1\n"), output, checkoutAction)
+	ui.SetLayout(LayoutPlain)
+	got, err := ui.Select(nil)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if !got.Quit || !strings.Contains(output.String(), "No branches to select.") {
+		t.Fatalf("unexpected result %+v with output %q", got, output.String())
+	}
+}
+
+func TestResolveLayout(t *testing.T) {
+	t.Parallel()
+
+	pipeIn, pipeOut, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		pipeIn.Close()
+		pipeOut.Close()
+	})
+
+	cases := map[string]struct {
+		in     io.Reader
+		out    io.Writer
+		term   string
+		layout Layout
+		want   Layout
+	}{
+		"injected-streams":   {in: &bytes.Buffer{}, out: &bytes.Buffer{}, term: "xterm", want: LayoutInteractive},
+		"piped-stdin":        {in: pipeIn, out: &bytes.Buffer{}, term: "xterm", want: LayoutPlain},
+		"piped-stdout":       {in: &bytes.Buffer{}, out: pipeOut, term: "xterm", want: LayoutPlain},
+		"dumb-terminal":      {in: &bytes.Buffer{}, out: &bytes.Buffer{}, term: "dumb", want: LayoutPlain},
+		"forced-interactive": {in: pipeIn, out: pipeOut, term: "dumb", layout: LayoutInteractive, want: LayoutInteractive},
+		"forced-plain":       {in: &bytes.Buffer{}, out: &bytes.Buffer{}, term: "xterm", layout: LayoutPlain, want: LayoutPlain},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ui := New(tc.in, tc.out, checkoutAction)
+			ui.getenv = func(string) string { return tc.term }
+			ui.SetLayout(tc.layout)
+			if got := ui.resolveLayout(); got != tc.want {
+				t.Fatalf("resolveLayout() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectFallsBackToPlainWithoutTerminal(t *testing.T) {
+	t.Parallel()
+
+	pipeIn, pipeOut, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe returned error: %v", err)
+	}
+	defer pipeIn.Close()
+	if _, err := pipeOut.WriteString("2\n"); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	pipeOut.Close()
+
+	output := &bytes.Buffer{}
+	ui := New(pipeIn, output, checkoutAction)
+	got, err := ui.Select([]Branch{{Name: "main", Current: true}, {Name: "feature/x"}})
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if got.Branch != "feature/x" {
+		t.Fatalf("unexpected result %+v", got)
+	}
+	if strings.Contains(output.String(), "\033") {
+		t.Fatalf("fallback output contains escape codes: %q", output.String())
+	}
+}
+
+func TestSelectPlainMultiSelect(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "done"},
+		{Name: "old"},
+		{Name: "feature/x"},
+	}
+
+	cases := map[string]struct {
+		input   string
+		want    Result
+		wantOut string
+	}{
+		"numbers and ranges": {
+			input: "4, 2-3\n",
+			want:  Result{Marked: []string{"done", "old", "feature/x"}},
+		},
+		"current branch is refused": {
+			input:   "1 2\n3\n",
+			want:    Result{Marked: []string{"old"}},
+			wantOut: "1 is the current branch; enter numbers from 1 to 4.\n",
+		},
+		"invalid range": {
+			input:   "3-2\nq\n",
+			want:    Result{Quit: true},
+			wantOut: "\"3-2\" is out of range; enter numbers from 1 to 4.\n",
+		},
+		"eof": {
+			input: "",
+			want:  Result{Quit: true},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString(tc.input), output, checkoutAction)
+			ui.SetLayout(LayoutPlain)
+			ui.SetMultiSelect(true)
+			got, err := ui.Select(branches)
+			if err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("result = %+v, want %+v", got, tc.want)
+			}
+			if !strings.Contains(output.String(), tc.wantOut) {
+				t.Fatalf("expected output to contain %q, got %q", tc.wantOut, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import "io"
+
+// Recorder receives what the interactive selector reads and draws, to save
+// a session that can be replayed later.
+type Recorder interface {
+	// Key receives the bytes of every read from the input, as they arrived.
+	Key(keys []byte)
+	// Frame receives the text of every frame drawn.
+	Frame(text string)
+}
+
+// SetRecorder reports the keys and frames of the interactive selector to r.
+// The plain layout is not recorded.
+func (u *UI) SetRecorder(r Recorder) {
+	if u != nil {
+		u.recorder = r
+	}
+}
+
+// SetSize lays the selector out for a terminal of cols by rows cells instead
+// of asking the terminal, as a replay does to match the recording. Zeros ask
+// the terminal again.
+func (u *UI) SetSize(cols, rows int) {
+	if u != nil {
+		u.cols, u.rows = cols, rows
+	}
+}
+
+// keySource returns the reader keys are read from: the input itself, or
+// the input reporting every read to the recorder.
+func (u *UI) keySource() io.Reader {
+	if u.recorder == nil {
+		return u.in
+	}
+	return recordedInput{r: u.in, recorder: u.recorder}
+}
+
+// recordedInput passes reads through and reports what they returned.
+type recordedInput struct {
+	r        io.Reader
+	recorder Recorder
+}
+
+func (in recordedInput) Read(p []byte) (int, error) {
+	n, err := in.r.Read(p)
+	if n > 0 {
+		in.recorder.Key(append([]byte(nil), p[:n]...))
+	}
+	return n, err
+}
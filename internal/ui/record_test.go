@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeRecorder struct {
+	mu     sync.Mutex
+	keys   bytes.Buffer
+	frames []string
+}
+
+func (r *fakeRecorder) Key(keys []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys.Write(keys)
+}
+
+func (r *fakeRecorder) Frame(text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, text)
+}
+
+func TestRecorderSeesKeysAndFrames(t *testing.T) {
+	t.Parallel()
+
+	recorder := &fakeRecorder{}
+	ui := New(strings.NewReader("j\r"), &bytes.Buffer{}, checkoutAction)
+	ui.SetRecorder(recorder)
+	result, err := ui.Select([]Branch{{Name: "main"}, {Name: "feature/alpha"}})
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if result.Branch != "feature/alpha" {
+		t.Fatalf("Select() = %+v, want feature/alpha", result)
+	}
+	if got := recorder.keys.String(); got != "j\r" {
+		t.Fatalf("recorded keys = %q, want %q", got, "j\r")
+	}
+	if len(recorder.frames) != 2 {
+		t.Fatalf("recorded %d frames, want 2", len(recorder.frames))
+	}
+	if !strings.Contains(recorder.frames[1], "> feature/alpha") {
+		t.Fatalf("second frame does not highlight feature/alpha:\n%q", recorder.frames[1])
+	}
+}
+
+func TestSetSizeOverridesTerminal(t *testing.T) {
+	t.Parallel()
+
+	ui := New(strings.NewReader(""), &bytes.Buffer{}, checkoutAction)
+	ui.SetSize(40, 20)
+	if cols, rows := ui.terminalSize(); cols != 40 || rows != 20 {
+		t.Fatalf("terminalSize() = %d, %d, want 40, 20", cols, rows)
+	}
+	if got, want := ui.listHeight(), 20-ui.chromeRows(); got != want {
+		t.Fatalf("listHeight() = %d, want %d", got, want)
+	}
+	if got := ui.screenWidth(); got != 40 {
+		t.Fatalf("screenWidth() = %d, want 40", got)
+	}
+}
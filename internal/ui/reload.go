@@ -0,0 +1,96 @@
+package ui
+
+import "fmt"
+
+// Reloaded holds the rows read again by a Reloader, with the Loader and
+// Updates that go with them as in State.
+type Reloaded struct {
+	Rows    Provider
+	Load    Loader
+	Updates <-chan struct{}
+}
+
+// Reloader reads the rows again, the way they were read when the selector
+// opened, for Ctrl+R and F5.
+type Reloader func() (Reloaded, error)
+
+// SetReloader enables Ctrl+R and F5, which replace the rows with those r
+// reads, such as after fetching in another terminal. The cursor, the marks,
+// the filter, and the toggles carry over to the new rows.
+func (u *UI) SetReloader(r Reloader) {
+	if u != nil {
+		u.reloader = r
+	}
+}
+
+// reload replaces the rows of view with those the Reloader reads, keeping
+// the cursor on the same branch when it is still listed. A failing Reloader
+// keeps the old rows and shows why in the notice line.
+func (u *UI) reload(view *listView) (bool, error) {
+	if u.reloader == nil {
+		return false, nil
+	}
+	view.notice = "refreshing…"
+	if err := u.render(view); err != nil {
+		return false, err
+	}
+	view.notice = ""
+
+	selected, _ := view.selected()
+	cursor := view.cursor
+	marked := view.markedNames()
+	reloaded, err := u.reloader()
+	if err != nil {
+		view.notice = fmt.Sprintf("refresh failed: %v", err)
+		return true, nil
+	}
+	view.replace(reloaded.Rows, reloaded.Load)
+	// Load at least as far as the cursor was, where the branch most likely
+	// still is.
+	if _, err := view.fill(cursor + 1); err != nil {
+		return false, err
+	}
+	if _, err := u.fill(view); err != nil {
+		return false, err
+	}
+	view.markNames(marked)
+	if !view.moveTo(selected.Name) {
+		view.cursor = min(cursor, max(len(view.visible)-1, 0))
+	}
+	view.updates = reloaded.Updates
+	return true, nil
+}
+
+// reloadOn reloads view each time changed receives until it is closed or
+// stop is, handing the updates of the new rows to watch.
+func (u *UI) reloadOn(changed <-chan struct{}, stop <-chan struct{}, screen *screenLock, view *listView, watch func(<-chan struct{})) {
+	for {
+		select {
+		case <-stop:
+			return
+		case _, ok := <-changed:
+			if !ok {
+				return
+			}
+			screen.Lock()
+			if screen.ended {
+				screen.Unlock()
+				return
+			}
+			updated, err := u.reload(view)
+			if err == nil && updated {
+				err = u.render(view)
+			}
+			if err != nil {
+				screen.err = err
+			}
+			updates := view.updates
+			view.updates = nil
+			screen.Unlock()
+			if err != nil {
+				return
+			}
+			watch(updates)
+		}
+	}
+}
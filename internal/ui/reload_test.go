@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSelectReloads(t *testing.T) {
+	t.Parallel()
+
+	first := Branches{{Name: "main", Current: true}, {Name: "a"}, {Name: "b"}}
+	second := Branches{{Name: "main", Current: true}, {Name: "fetched"}, {Name: "a"}, {Name: "b"}}
+
+	cases := map[string]struct {
+		input string
+		multi bool
+		rows  Branches
+		want  Result
+	}{
+		"ctrl+r keeps the cursor on the branch": {
+			input: "jj\x12\r",
+			rows:  second,
+			want:  Result{Branch: "b"},
+		},
+		"f5 refreshes too": {
+			input: "j\x1b[15~k\r",
+			rows:  second,
+			want:  Result{Branch: "fetched"},
+		},
+		"cursor stays in place when its branch is gone": {
+			input: "jj\x12\r",
+			rows:  Branches{{Name: "main", Current: true}, {Name: "a"}, {Name: "c"}},
+			want:  Result{Branch: "c"},
+		},
+		"marks carry over": {
+			input: "j \x12\r",
+			multi: true,
+			rows:  second,
+			want:  Result{Marked: []string{"a"}},
+		},
+		"filter still applies": {
+			input: "/b\x12\r",
+			rows:  second,
+			want:  Result{Branch: "b", Filter: "b"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reloads := 0
+			ui := New(bytes.NewBufferString(tc.input), &bytes.Buffer{}, checkoutAction)
+			ui.SetMultiSelect(tc.multi)
+			ui.SetReloader(func() (Reloaded, error) {
+				reloads++
+				return Reloaded{Rows: tc.rows}, nil
+			})
+			result, err := ui.SelectFrom(first, State{})
+			if err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if reloads != 1 {
+				t.Fatalf("reloaded %d times, want 1", reloads)
+			}
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Fatalf("result = %+v, want %+v", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectReloadError(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("j\x12\r"), output, checkoutAction)
+	ui.SetReloader(func() (Reloaded, error) {
+		return Reloaded{}, errors.New("not a git repository")
+	})
+	result, err := ui.Select([]Branch{{Name: "main", Current: true}, {Name: "a"}})
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if result.Branch != "a" {
+		t.Fatalf("result = %+v, want the old rows kept", result)
+	}
+	frames := framesFromOutput(t, output.String())
+	if lines := plainLines(frames[2]); !containsPrefix(lines, "refreshing…") {
+		t.Fatalf("progress missing from frame %q", lines)
+	}
+	if lines := plainLines(frames[3]); !containsPrefix(lines, "refresh failed: not a git repository") {
+		t.Fatalf("error missing from frame %q", lines)
+	}
+}
+
+func TestSelectWatchesReloadedUpdates(t *testing.T) {
+	t.Parallel()
+
+	rows := &lateDetails{rows: []Branch{{Name: "main", Current: true}, {Name: "fetched"}}, details: map[string]string{}}
+	updates := make(chan struct{})
+	input, keys := io.Pipe()
+	output := &lockedBuffer{}
+	ui := New(input, output, checkoutAction)
+	ui.SetReloader(func() (Reloaded, error) {
+		return Reloaded{Rows: rows, Updates: updates}, nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ui.Select([]Branch{{Name: "main", Current: true}})
+		done <- err
+	}()
+	if _, err := keys.Write([]byte{0x12}); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	waitForOutput(t, output, "fetched")
+	rows.set("fetched", "(2 commits)")
+	updates <- struct{}{}
+	waitForOutput(t, output, "fetched (2 commits)")
+
+	if _, err := keys.Write([]byte("q")); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+}
+
+func TestSelectReloadsOnChange(t *testing.T) {
+	t.Parallel()
+
+	changed := make(chan struct{})
+	input, keys := io.Pipe()
+	output := &lockedBuffer{}
+	ui := New(input, output, checkoutAction)
+	ui.SetReloader(func() (Reloaded, error) {
+		return Reloaded{Rows: Branches{{Name: "main", Current: true}, {Name: "created"}}}, nil
+	})
+
+	done := make(chan Result, 1)
+	go func() {
+		result, err := ui.SelectFrom(Branches{{Name: "main", Current: true}}, State{Changed: changed})
+		if err != nil {
+			t.Errorf("SelectFrom returned error: %v", err)
+		}
+		done <- result
+	}()
+	waitForOutput(t, output, "main")
+	changed <- struct{}{}
+	waitForOutput(t, output, "created")
+
+	if _, err := keys.Write([]byte("j\r")); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	if result := <-done; result.Branch != "created" {
+		t.Fatalf("result = %+v, want the reloaded row", result)
+	}
+}
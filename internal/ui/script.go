@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scriptKeys maps the key names of a script to the bytes a terminal sends
+// for them.
+var scriptKeys = map[string]string{
+	"enter":     "\r",
+	"esc":       "\x1b",
+	"up":        "\x1b[A",
+	"down":      "\x1b[B",
+	"space":     " ",
+	"tab":       "\t",
+	"backspace": "\x7f",
+}
+
+// ParseScript turns a script such as "j j / feat enter" into the keystrokes
+// it describes, to drive the selector without a terminal in end-to-end tests
+// and demo recordings. Words are separated by spaces. enter, esc, up, down,
+// space, tab, backspace, and ctrl-<letter> name keys; any other word is
+// typed character by character.
+func ParseScript(script string) ([]byte, error) {
+	var keys strings.Builder
+	for _, word := range strings.Fields(script) {
+		name := strings.ToLower(word)
+		if key, ok := scriptKeys[name]; ok {
+			keys.WriteString(key)
+			continue
+		}
+		if letter, ok := strings.CutPrefix(name, "ctrl-"); ok {
+			if len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+				return nil, fmt.Errorf("unknown key %q in script", word)
+			}
+			keys.WriteByte(letter[0] - 'a' + 1)
+			continue
+		}
+		keys.WriteString(word)
+	}
+	return []byte(keys.String()), nil
+}
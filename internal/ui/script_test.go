@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseScript(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		script  string
+		want    string
+		wantErr string
+	}{
+		"moves":        {script: "j j enter", want: "jj\r"},
+		"arrows":       {script: "down  Down up ENTER", want: "\x1b[B\x1b[B\x1b[A\r"},
+		"filter":       {script: "/ feat backspace space enter", want: "/feat\x7f \r"},
+		"control keys": {script: "ctrl-c ctrl-Z", want: "\x03\x1a"},
+		"empty":        {script: "  ", want: ""},
+		"bad control":  {script: "ctrl-1", wantErr: `unknown key "ctrl-1" in script`},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseScript(tc.script)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("ParseScript() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseScript returned error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("ParseScript() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScriptDrivesSelector(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/alpha"},
+		{Name: "feature/beta"},
+	}
+
+	cases := map[string]struct {
+		script string
+		want   Result
+	}{
+		"move":          {script: "j j k enter", want: Result{Branch: "feature/alpha"}},
+		"filter":        {script: "/ betx backspace a enter", want: Result{Branch: "feature/beta", Filter: "beta"}},
+		"runs out":      {script: "j", want: Result{Quit: true}},
+		"quits":         {script: "ctrl-c", want: Result{Quit: true}},
+		"current first": {script: "enter", want: Result{Branch: "main", AlreadyOn: true}},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := ParseScript(tc.script)
+			if err != nil {
+				t.Fatalf("ParseScript returned error: %v", err)
+			}
+			got, err := New(bytes.NewReader(keys), &bytes.Buffer{}, checkoutAction).Select(branches)
+			if err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if got.Branch != tc.want.Branch || got.Quit != tc.want.Quit || got.AlreadyOn != tc.want.AlreadyOn || got.Filter != tc.want.Filter {
+				t.Fatalf("Select() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"time"
+)
+
+// spinnerFrames are drawn in turn while a task runs.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// spinnerInterval is how long each frame stays on screen.
+	spinnerInterval = 80 * time.Millisecond
+	// spinnerDelay keeps quick operations from flashing a spinner.
+	spinnerDelay = 150 * time.Millisecond
+	// clearLine returns to the start of the line and erases it.
+	clearLine = EraseLine
+	// hideCursor and showCursor toggle the terminal cursor around the animation.
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+)
+
+// EraseLine returns the cursor to the start of the line and erases it, so
+// that a message printed while a spinner runs does not share its line.
+const EraseLine = "\r\033[K"
+
+// Spinner animates a status line while a slow operation runs.
+type Spinner struct {
+	out      io.Writer
+	theme    Theme
+	interval time.Duration
+	delay    time.Duration
+}
+
+// NewSpinner constructs a Spinner that draws on out with theme's colors.
+func NewSpinner(out io.Writer, theme Theme) *Spinner {
+	if theme == (Theme{}) {
+		theme = DefaultTheme
+	}
+	return &Spinner{out: out, theme: theme, interval: spinnerInterval, delay: spinnerDelay}
+}
+
+// Run calls task and animates label until it returns, erasing the line
+// afterwards. Nothing is drawn when the task finishes within a short delay.
+// The task receives ctx, so cancelling ctx, for example on Ctrl+C, stops it;
+// Run then reports the operation as cancelled and returns ctx's error. When
+// the task panics, Run restores the cursor and panics in the caller's
+// goroutine, so that the caller's deferred cleanup, such as leaving raw
+// mode, runs before the program dies.
+func (s *Spinner) Run(ctx context.Context, label string, task func(context.Context) error) error {
+	done := make(chan error, 1)
+	panicked := make(chan taskPanic, 1)
+	go func() {
+		defer func() {
+			if value := recover(); value != nil {
+				panicked <- taskPanic{Value: value, Stack: debug.Stack()}
+			}
+		}()
+		done <- task(ctx)
+	}()
+
+	timer := time.NewTimer(s.delay)
+	defer timer.Stop()
+	var ticks <-chan time.Time
+	drawn := false
+	frame := 0
+	for {
+		select {
+		case p := <-panicked:
+			if drawn {
+				fmt.Fprint(s.out, clearLine+showCursor)
+			}
+			panic(p)
+		case err := <-done:
+			if drawn {
+				fmt.Fprint(s.out, clearLine+showCursor)
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+				fmt.Fprintf(s.out, "%s cancelled\n", label)
+				if errors.Is(err, ctxErr) {
+					return err
+				}
+				return fmt.Errorf("%w: %v", ctxErr, err)
+			}
+			return err
+		case <-timer.C:
+			ticker := time.NewTicker(s.interval)
+			defer ticker.Stop()
+			ticks = ticker.C
+			drawn = true
+			fmt.Fprint(s.out, hideCursor)
+			s.draw(label, frame)
+		case <-ticks:
+			frame++
+			s.draw(label, frame)
+		}
+	}
+}
+
+// taskPanic is the panic value with which Spinner.Run passes on a panic
+// of its task. It keeps the task's stack, which the re-panic would lose.
+type taskPanic struct {
+	Value any
+	Stack []byte
+}
+
+// Error prints the original value followed by the task's stack trace.
+func (p taskPanic) Error() string {
+	return fmt.Sprintf("%v\n\ntask goroutine stack:\n%s", p.Value, p.Stack)
+}
+
+func (s *Spinner) draw(label string, frame int) {
+	symbol := spinnerFrames[frame%len(spinnerFrames)]
+	fmt.Fprintf(s.out, "%s%s%s%s %s%s…%s", clearLine, s.theme.Badge, symbol, resetColor, label, s.theme.Help, resetColor)
+}
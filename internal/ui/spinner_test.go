@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpinnerRun(t *testing.T) {
+	t.Parallel()
+
+	taskErr := errors.New("fetch failed")
+
+	cases := map[string]struct {
+		delay   time.Duration
+		task    func(context.Context) error
+		wantErr error
+		check   func(t *testing.T, out string)
+	}{
+		"quick task draws nothing": {
+			delay: time.Hour,
+			task:  func(context.Context) error { return nil },
+			check: func(t *testing.T, out string) {
+				if out != "" {
+					t.Fatalf("output = %q, want nothing", out)
+				}
+			},
+		},
+		"slow task animates and clears": {
+			task: func(context.Context) error {
+				time.Sleep(40 * time.Millisecond)
+				return taskErr
+			},
+			wantErr: taskErr,
+			check: func(t *testing.T, out string) {
+				if !strings.HasPrefix(out, hideCursor+clearLine) {
+					t.Fatalf("output = %q, want it to hide the cursor first", out)
+				}
+				for _, want := range []string{spinnerFrames[0], spinnerFrames[1], "Fetching origin"} {
+					if !strings.Contains(out, want) {
+						t.Fatalf("output = %q, want it to contain %q", out, want)
+					}
+				}
+				if !strings.HasSuffix(out, clearLine+showCursor) {
+					t.Fatalf("output = %q, want it to erase the line and restore the cursor", out)
+				}
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			spinner := NewSpinner(out, Theme{})
+			spinner.delay = tc.delay
+			spinner.interval = 5 * time.Millisecond
+			err := spinner.Run(context.Background(), "Fetching origin", tc.task)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Run error = %v, want %v", err, tc.wantErr)
+			}
+			tc.check(t, out.String())
+		})
+	}
+}
+
+func TestSpinnerRunCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &bytes.Buffer{}
+	spinner := NewSpinner(out, Theme{})
+	spinner.delay = 0
+	spinner.interval = 5 * time.Millisecond
+	err := spinner.Run(ctx, "Pushing feature/x", func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		<-ctx.Done()
+		return errors.New("signal: killed")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want context.Canceled", err)
+	}
+	if !strings.HasSuffix(out.String(), clearLine+showCursor+"Pushing feature/x cancelled\n") {
+		t.Fatalf("output = %q, want a cancellation note", out.String())
+	}
+}
+
+func TestSpinnerRunPanics(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	spinner := NewSpinner(out, Theme{})
+	spinner.delay = 0
+	spinner.interval = 5 * time.Millisecond
+
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		_ = spinner.Run(context.Background(), "Reading branches", func(context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			panic("index out of range")
+		})
+	}()
+
+	p, ok := recovered.(taskPanic)
+	if !ok {
+		t.Fatalf("recovered %#v, want a taskPanic", recovered)
+	}
+	if p.Value != "index out of range" || !strings.Contains(p.Error(), "TestSpinnerRunPanics") {
+		t.Fatalf("panic = %q, want the value and the task's stack", p.Error())
+	}
+	if !strings.HasSuffix(out.String(), clearLine+showCursor) {
+		t.Fatalf("output = %q, want the cursor restored", out.String())
+	}
+}
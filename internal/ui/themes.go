@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ThemeOption is a named theme offered by PickTheme.
+type ThemeOption struct {
+	Name  string
+	Theme Theme
+}
+
+// WriteThemeSample draws a small selector frame in theme so palettes can be
+// compared side by side.
+func WriteThemeSample(w io.Writer, theme Theme) error {
+	return writeSample(w, theme, "\n")
+}
+
+func writeSample(w io.Writer, theme Theme, nl string) error {
+	lines := []string{
+		fmt.Sprintf("%sAction: checkout%s", theme.ActionLabel, resetColor),
+		fmt.Sprintf("%sSwitch to the selected branch%s", theme.ActionDescription, resetColor),
+		fmt.Sprintf("%sSelect a branch:%s", theme.Branch, resetColor),
+		fmt.Sprintf("%s> feature/login (visited 2h ago)%s", theme.Selected, resetColor),
+		fmt.Sprintf("  %smain%s %s%s%s", theme.Branch, resetColor, theme.Badge, currentBadge, resetColor),
+		fmt.Sprintf("  %sfix/typo%s%s (merged)%s", theme.Branch, resetColor, theme.Help, resetColor),
+		fmt.Sprintf("%sj/k or ↑/↓ to move, / to filter, Enter to checkout, q to exit%s", theme.Help, resetColor),
+	}
+	_, err := fmt.Fprint(w, strings.Join(lines, nl)+nl)
+	return err
+}
+
+// PickTheme lists options and previews the highlighted one as the cursor
+// moves. It returns the option chosen with Enter, or false when the user quits.
+func (u *UI) PickTheme(options []ThemeOption, initial string) (ThemeOption, bool, error) {
+	if u == nil {
+		return ThemeOption{}, false, fmt.Errorf("ui is nil")
+	}
+	if u.in == nil || u.out == nil {
+		return ThemeOption{}, false, fmt.Errorf("ui input and output must be configured")
+	}
+	if len(options) == 0 {
+		return ThemeOption{}, false, nil
+	}
+
+	restore, err := u.enterRawMode()
+	if err != nil {
+		return ThemeOption{}, false, err
+	}
+	if restore != nil {
+		defer restore()
+	}
+
+	cursor := 0
+	for i, option := range options {
+		if strings.EqualFold(option.Name, initial) {
+			cursor = i
+		}
+	}
+	reader := LineReader(u.in)
+	for {
+		if err := u.renderThemes(options, cursor); err != nil {
+			return ThemeOption{}, false, err
+		}
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return ThemeOption{}, false, nil
+		}
+		if err != nil {
+			return ThemeOption{}, false, err
+		}
+		if b == 0x1b {
+			seq, err := readEscape(reader)
+			if err != nil {
+				return ThemeOption{}, false, err
+			}
+			switch {
+			case seq.bare:
+				// Esc leaves the theme unchanged, like q.
+				b = 'q'
+			case seq.final == 'A':
+				b = 'k'
+			case seq.final == 'B':
+				b = 'j'
+			default:
+				b = 0
+			}
+		}
+		switch b {
+		case 0x03, 0x04, 0x1a, 'q', 'Q': // Ctrl+C, Ctrl+D, Ctrl+Z
+			return ThemeOption{}, false, nil
+		case '\r', '\n':
+			return options[cursor], true, nil
+		case 'j':
+			if cursor < len(options)-1 {
+				cursor++
+			}
+		case 'k':
+			if cursor > 0 {
+				cursor--
+			}
+		}
+	}
+}
+
+func (u *UI) renderThemes(options []ThemeOption, cursor int) error {
+	theme := options[cursor].Theme
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	fmt.Fprintf(&b, "%sSelect a theme:%s%s", theme.ActionLabel, resetColor, lineBreak)
+	for i, option := range options {
+		if i == cursor {
+			fmt.Fprintf(&b, "%s> %s%s%s", theme.Selected, option.Name, resetColor, lineBreak)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s%s%s%s", theme.Branch, option.Name, resetColor, lineBreak)
+	}
+	b.WriteString(lineBreak)
+	if err := writeSample(&b, theme, lineBreak); err != nil {
+		return err
+	}
+	fmt.Fprintf(&b, "%s%sj/k or ↑/↓ to preview, Enter to save, q to exit%s%s", lineBreak, theme.Help, resetColor, lineBreak)
+	return writeFrame(u.out, b.String())
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var pickerOptions = []ThemeOption{
+	{Name: "nord-dark", Theme: ThemeNord},
+	{Name: "nord-light", Theme: ThemeNordLight},
+	{Name: "gruvbox-dark", Theme: ThemeGruvbox},
+}
+
+func TestWriteThemeSample(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	if err := WriteThemeSample(out, ThemeGruvbox); err != nil {
+		t.Fatalf("WriteThemeSample returned error: %v", err)
+	}
+	for _, want := range []string{
+		ThemeGruvbox.ActionLabel + "Action: checkout",
+		ThemeGruvbox.Selected + "> feature/login",
+		ThemeGruvbox.Badge + currentBadge,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("sample missing %q:\n%q", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "\r") {
+		t.Fatalf("sample should use plain newlines: %q", out.String())
+	}
+}
+
+func TestPickTheme(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input   string
+		initial string
+		want    string
+		wantOK  bool
+	}{
+		"enter on the first":    {input: "\r", want: "nord-dark", wantOK: true},
+		"move with j":           {input: "jj\r", want: "gruvbox-dark", wantOK: true},
+		"arrow keys":            {input: "\x1b[B\x1b[B\x1b[A\r", want: "nord-light", wantOK: true},
+		"starts on initial":     {input: "k\r", initial: "GRUVBOX-DARK", want: "nord-light", wantOK: true},
+		"stays inside the list": {input: "kkk\r", want: "nord-dark", wantOK: true},
+		"quit":                  {input: "jq"},
+		"end of input":          {input: "j"},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ui := New(strings.NewReader(tc.input), &bytes.Buffer{}, ActionDetails{})
+			got, ok, err := ui.PickTheme(pickerOptions, tc.initial)
+			if err != nil {
+				t.Fatalf("PickTheme returned error: %v", err)
+			}
+			if ok != tc.wantOK || got.Name != tc.want {
+				t.Fatalf("PickTheme() = %q, %v; want %q, %v", got.Name, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestPickThemePreviewsHighlightedTheme(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	ui := New(strings.NewReader("j\r"), out, ActionDetails{})
+	if _, _, err := ui.PickTheme(pickerOptions, ""); err != nil {
+		t.Fatalf("PickTheme returned error: %v", err)
+	}
+	frames := strings.Split(out.String(), frameStart)
+	last := frames[len(frames)-1]
+	if !strings.Contains(last, ThemeNordLight.Selected+"> nord-light") || !strings.Contains(last, ThemeNordLight.Selected+"> feature/login") {
+		t.Fatalf("last frame does not preview nord-light:\n%q", last)
+	}
+}
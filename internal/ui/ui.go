@@ -4,13 +4,30 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"branch-navigator/internal/match"
 
 	"golang.org/x/term"
 )
 
-const clearScreen = "\033[2J\033[H"
+const (
+	// cursorHome moves to the top left corner, where full-screen frames start.
+	cursorHome = "\033[H"
+	// clearRight erases the rest of the line, so that a line drawn over a
+	// longer one leaves nothing behind.
+	clearRight = "\033[K"
+	// beginSync and endSync bracket a frame in synchronized output mode, so
+	// that terminals that support it show the frame at once. Others ignore
+	// the sequences.
+	beginSync = "\033[?2026h"
+	endSync   = "\033[?2026l"
+)
 const lineBreak = "\r\n"
 const resetColor = "\033[0m"
 
@@ -91,38 +108,169 @@ var ThemeOneDark = Theme{
 	Help:              "\033[38;5;246m",
 }
 
+// ThemeCatppuccinLatte implements the Catppuccin Latte palette for light terminals.
+var ThemeCatppuccinLatte = Theme{
+	ActionLabel:       "\033[1;38;5;27m",
+	ActionDescription: "\033[38;5;239m",
+	Branch:            "\033[38;5;240m",
+	Selected:          "\033[1;38;5;255;48;5;27m",
+	SelectedBadge:     "\033[1;38;5;157;48;5;27m",
+	Badge:             "\033[1;38;5;70m",
+	Help:              "\033[38;5;244m",
+}
+
+// ThemeNordLight implements a Nord palette for Snow Storm backgrounds.
+var ThemeNordLight = Theme{
+	ActionLabel:       "\033[1;38;5;24m",
+	ActionDescription: "\033[38;5;236m",
+	Branch:            "\033[38;5;238m",
+	Selected:          "\033[1;38;5;255;48;5;67m",
+	SelectedBadge:     "\033[1;38;5;151;48;5;67m",
+	Badge:             "\033[1;38;5;65m",
+	Help:              "\033[38;5;245m",
+}
+
+// ThemeClassicLight provides the classic ANSI palette for light terminals.
+var ThemeClassicLight = Theme{
+	ActionLabel:       "\033[1;34m",
+	ActionDescription: "\033[30m",
+	Branch:            "\033[30m",
+	Selected:          "\033[1;97;44m",
+	SelectedBadge:     "\033[1;92;44m",
+	Badge:             "\033[1;32m",
+	Help:              "\033[90m",
+}
+
+// ThemeSolarizedLight provides a Solarized Light-inspired palette.
+var ThemeSolarizedLight = Theme{
+	ActionLabel:       "\033[1;38;5;32m",
+	ActionDescription: "\033[38;5;240m",
+	Branch:            "\033[38;5;241m",
+	Selected:          "\033[1;38;5;23;48;5;187m",
+	SelectedBadge:     "\033[1;38;5;64;48;5;187m",
+	Badge:             "\033[1;38;5;64m",
+	Help:              "\033[38;5;245m",
+}
+
+// ThemeGruvboxLight provides a Gruvbox Light-inspired palette.
+var ThemeGruvboxLight = Theme{
+	ActionLabel:       "\033[1;38;5;130m",
+	ActionDescription: "\033[38;5;237m",
+	Branch:            "\033[38;5;239m",
+	Selected:          "\033[1;38;5;230;48;5;130m",
+	SelectedBadge:     "\033[1;38;5;193;48;5;130m",
+	Badge:             "\033[1;38;5;100m",
+	Help:              "\033[38;5;243m",
+}
+
+// ThemeOneLight provides a One Light-inspired palette.
+var ThemeOneLight = Theme{
+	ActionLabel:       "\033[1;38;5;33m",
+	ActionDescription: "\033[38;5;237m",
+	Branch:            "\033[38;5;238m",
+	Selected:          "\033[1;38;5;255;48;5;33m",
+	SelectedBadge:     "\033[1;38;5;157;48;5;33m",
+	Badge:             "\033[1;38;5;71m",
+	Help:              "\033[38;5;244m",
+}
+
 // DefaultTheme holds the palette used when no explicit selection is provided.
 var DefaultTheme = ThemeCatppuccin
 
-var themeNames = []string{"catppuccin", "nord", "classic", "solarized", "gruvbox", "onedark"}
+// ThemeFamily pairs the dark and light variants of a palette. Names that pin
+// one variant resolve to a family whose variants are the same theme.
+type ThemeFamily struct {
+	Dark  Theme
+	Light Theme
+}
 
-// AvailableThemeNames returns the canonical list of supported themes.
+// Adaptive reports whether the family has distinct light and dark variants.
+func (f ThemeFamily) Adaptive() bool {
+	return f.Dark != f.Light
+}
+
+// For returns the variant suited to bg; unknown backgrounds get the dark one.
+func (f ThemeFamily) For(bg Background) Theme {
+	if bg == BackgroundLight {
+		return f.Light
+	}
+	return f.Dark
+}
+
+var themeNames = []string{"catppuccin", "nord", "classic", "solarized", "gruvbox", "one"}
+
+// AvailableThemeNames returns the canonical list of supported theme families.
+// Each also accepts a -dark or -light suffix to pin one variant, and a -16
+// suffix for its basic ANSI color variant.
 func AvailableThemeNames() []string {
 	names := make([]string, len(themeNames))
 	copy(names, themeNames)
 	return names
 }
 
-// ThemeByName resolves a theme by its human-readable name.
-func ThemeByName(name string) (Theme, bool) {
-	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "":
-		return DefaultTheme, true
-	case "nord":
-		return ThemeNord, true
-	case "catppuccin", "catppuccin-mocha", "mocha":
-		return ThemeCatppuccin, true
-	case "classic", "ansi":
-		return ThemeClassic, true
-	case "solarized", "solarized-dark":
-		return ThemeSolarized, true
-	case "gruvbox":
-		return ThemeGruvbox, true
-	case "onedark", "one-dark":
-		return ThemeOneDark, true
-	default:
-		return Theme{}, false
+// themeFamilies maps every accepted theme name, in lower case, to its
+// family. Family names such as "gruvbox" follow the terminal background;
+// names such as "gruvbox-light" or "mocha" pin one variant. Each family name
+// and pinned variant, but no alias, also comes with -16 or 16 appended, as
+// in "classic-16", "classic16", or "nord-light-16", for the basic ANSI
+// color variant.
+var themeFamilies = func() map[string]ThemeFamily {
+	pin := func(theme Theme) ThemeFamily { return ThemeFamily{Dark: theme, Light: theme} }
+	canonical := map[string]ThemeFamily{
+		"catppuccin":       {Dark: ThemeCatppuccin, Light: ThemeCatppuccinLatte},
+		"catppuccin-dark":  pin(ThemeCatppuccin),
+		"catppuccin-light": pin(ThemeCatppuccinLatte),
+		"nord":             {Dark: ThemeNord, Light: ThemeNordLight},
+		"nord-dark":        pin(ThemeNord),
+		"nord-light":       pin(ThemeNordLight),
+		"classic":          {Dark: ThemeClassic, Light: ThemeClassicLight},
+		"classic-dark":     pin(ThemeClassic),
+		"classic-light":    pin(ThemeClassicLight),
+		"solarized":        {Dark: ThemeSolarized, Light: ThemeSolarizedLight},
+		"solarized-dark":   pin(ThemeSolarized),
+		"solarized-light":  pin(ThemeSolarizedLight),
+		"gruvbox":          {Dark: ThemeGruvbox, Light: ThemeGruvboxLight},
+		"gruvbox-dark":     pin(ThemeGruvbox),
+		"gruvbox-light":    pin(ThemeGruvboxLight),
+		"one":              {Dark: ThemeOneDark, Light: ThemeOneLight},
+		"one-dark":         pin(ThemeOneDark),
+		"one-light":        pin(ThemeOneLight),
 	}
+	families := make(map[string]ThemeFamily, 3*len(canonical))
+	for name, family := range canonical {
+		families[name] = family
+		families[name+"-16"] = family.Basic()
+		families[name+"16"] = family.Basic()
+	}
+	aliases := map[string]string{
+		"":                 "catppuccin",
+		"catppuccin-mocha": "catppuccin-dark",
+		"mocha":            "catppuccin-dark",
+		"catppuccin-latte": "catppuccin-light",
+		"latte":            "catppuccin-light",
+		"ansi":             "classic",
+		"onedark":          "one-dark",
+		"onelight":         "one-light",
+	}
+	for alias, name := range aliases {
+		families[alias] = families[name]
+	}
+	return families
+}()
+
+// ThemeFamilyByName resolves a theme by its human-readable name, ignoring
+// case and surrounding white space. The name has to match one of
+// themeFamilies exactly; an empty name is the default family.
+func ThemeFamilyByName(name string) (ThemeFamily, bool) {
+	family, ok := themeFamilies[strings.ToLower(strings.TrimSpace(name))]
+	return family, ok
+}
+
+// ThemeByName resolves a theme by its human-readable name, using the dark
+// variant of families.
+func ThemeByName(name string) (Theme, bool) {
+	family, ok := ThemeFamilyByName(name)
+	return family.Dark, ok
 }
 
 // ActionDetails captures the labels describing the currently configured operation.
@@ -130,27 +278,160 @@ type ActionDetails struct {
 	Name        string
 	Description string
 	EnterLabel  string
+	// AllowCurrent lets the current branch be selected instead of reporting "already on".
+	AllowCurrent bool
 }
 
 // Branch represents a branch candidate with metadata required by the UI.
 type Branch struct {
 	Name    string
 	Current bool
+	// Detail is optional supplementary text rendered after the name.
+	Detail string
+	// Merged, Stale, and Remote classify the row for the visibility toggles.
+	Merged bool
+	Stale  bool
+	Remote bool
+	// Default marks the repository's default branch with a badge.
+	Default bool
+	// Labels are short notes attached to the branch, drawn as chips after
+	// its name.
+	Labels []string
+}
+
+// Visibility selects which kinds of rows are listed. The current branch is always shown.
+type Visibility struct {
+	Merged bool
+	Stale  bool
+	Remote bool
+}
+
+// Provider supplies the rows of the selector by index. Branch is read for
+// every row whenever the filter or the toggles change, so it should be
+// cheap and may leave Detail empty; Detail is read only for the rows drawn,
+// so supplementary text that is costly to build is never built for rows
+// that stay off screen.
+type Provider interface {
+	Len() int
+	Branch(i int) Branch
+	Detail(i int) string
 }
 
+// Branches provides rows whose detail text is already in Branch.Detail.
+type Branches []Branch
+
+// Len returns the number of rows.
+func (b Branches) Len() int { return len(b) }
+
+// Branch returns row i.
+func (b Branches) Branch(i int) Branch { return b[i] }
+
+// Detail returns the Detail of row i.
+func (b Branches) Detail(i int) string { return b[i].Detail }
+
+// Loader supplies up to n more rows for a list that is loaded lazily. It
+// returns no rows once the list is exhausted.
+type Loader func(n int) (Provider, error)
+
+// loadPageSize is how many rows are requested from a Loader at a time.
+const loadPageSize = 50
+
+// currentBadge follows the name of the current branch, after a space, and
+// defaultBadge the name of the default branch.
+const (
+	currentBadge = "(current branch)"
+	defaultBadge = "(default)"
+)
+
+// rowBadge returns the badge drawn after the name of branch, or "".
+func rowBadge(branch Branch) string {
+	switch {
+	case branch.Current && branch.Default:
+		return "(current branch, default)"
+	case branch.Current:
+		return currentBadge
+	case branch.Default:
+		return defaultBadge
+	default:
+		return ""
+	}
+}
+
+// reservedRows is how many terminal lines the full header, status, and help
+// text may take around the branch rows.
+const reservedRows = 9
+
 // Result captures the outcome of the branch selection loop.
 type Result struct {
 	Branch    string
 	Quit      bool
 	AlreadyOn bool
+	// Remote reports whether the selected row is a remote-tracking branch.
+	Remote bool
+	// Filter is the filter query active when the selection ended.
+	Filter string
+	// Marked lists the rows chosen in multi-select mode, in list order:
+	// the marked rows, or the highlighted one when none is marked.
+	Marked []string
 }
 
 // UI drives the interactive terminal selection flow.
 type UI struct {
-	in     io.Reader
-	out    io.Writer
-	action ActionDetails
-	theme  Theme
+	in        io.Reader
+	out       io.Writer
+	action    ActionDetails
+	theme     Theme
+	clipboard Clipboard
+	// height overrides the number of branch rows drawn at once; 0 derives it from the terminal size.
+	height int
+	// width overrides the number of cells a row may take; 0 derives it from the terminal size.
+	width int
+	// layout selects between the interactive screen and selectPlain.
+	layout Layout
+	// getenv reads TERM for layout detection; nil uses os.Getenv.
+	getenv func(string) string
+	// border draws a rounded box around the selector.
+	border bool
+	// marker is drawn before the highlighted row.
+	marker Marker
+	// header selects the header style; repo is shown by the compact one.
+	header Header
+	repo   string
+	// density and separators control the lines between parts of the frame.
+	density    Density
+	separators bool
+	// inline draws the selector below the cursor instead of on the whole
+	// screen; inlineRows is the number of lines it took once started.
+	inline     Height
+	inlineRows int
+	// log records every frame drawn; nil logs nothing.
+	log *slog.Logger
+	// recorder receives keys and frames for a session recording.
+	recorder Recorder
+	// cols and rows, when set, replace the size of the output terminal.
+	cols, rows int
+	// multi lets Space mark several rows for Result.Marked.
+	multi bool
+	// warning is shown in a banner above the header.
+	warning string
+	// expander supplies the details of rows expanded with Tab or o; nil
+	// disables expanding.
+	expander Expander
+	// reloader reads the rows again for Ctrl+R and F5; nil disables
+	// refreshing.
+	reloader Reloader
+	// cleanup plans the removal the e key offers, described in the help
+	// line by cleanupLabel; nil disables the key.
+	cleanup      Cleanup
+	cleanupLabel string
+	// labeler saves the labels edited with the l key; nil disables the
+	// key.
+	labeler Labeler
+}
+
+// Clipboard receives branch names copied with the y key.
+type Clipboard interface {
+	Copy(text string) error
 }
 
 // New constructs a UI bound to the given input and output streams.
@@ -163,128 +444,496 @@ func NewWithTheme(input io.Reader, output io.Writer, action ActionDetails, theme
 	if theme == (Theme{}) {
 		theme = DefaultTheme
 	}
-	return &UI{in: input, out: output, action: action, theme: theme}
+	return &UI{in: input, out: output, action: action, theme: theme, marker: DefaultMarker}
+}
+
+// SetClipboard enables the y key, which copies the highlighted branch name to c.
+func (u *UI) SetClipboard(c Clipboard) {
+	if u != nil {
+		u.clipboard = c
+	}
+}
+
+// SetLogger records each frame drawn, with its size and how long it took,
+// in l.
+func (u *UI) SetLogger(l *slog.Logger) {
+	if u != nil {
+		u.log = l
+	}
+}
+
+// SetBorder draws a rounded border around the selector, titled with the
+// action name.
+func (u *UI) SetBorder(enabled bool) {
+	if u != nil {
+		u.border = enabled
+	}
+}
+
+// SetMultiSelect lets Space mark several rows, and a mark or clear every
+// visible one, before Enter ends the selection with all of them in
+// Result.Marked. The plain layout reads several numbers instead.
+func (u *UI) SetMultiSelect(enabled bool) {
+	if u != nil {
+		u.multi = enabled
+	}
+}
+
+// State seeds the selector with a previously active cursor position and filter.
+type State struct {
+	// Cursor names the branch highlighted initially; unknown names leave the cursor on the first row.
+	Cursor string
+	// Filter is the initial filter query.
+	Filter string
+	// Mode selects how the filter query is interpreted.
+	Mode match.Mode
+	// Visibility enables the m, s, and r toggles with the given initial
+	// settings; nil lists every row.
+	Visibility *Visibility
+	// Load, when set, appends rows page by page as the cursor nears the end of the list.
+	Load Loader
+	// Updates, when set, announces that rows of the Provider changed, such
+	// as details filled in by background work, and is closed once they are
+	// final. The rows on screen are redrawn in place on each receive, so the
+	// Provider must then be safe for concurrent use. The plain layout waits
+	// for the close before listing the rows.
+	Updates <-chan struct{}
+	// Changed, when set, announces that the rows are out of date, such as
+	// when branches changed on disk. Each receive reads them again with
+	// the Reloader, as Ctrl+R does; without one it is ignored.
+	Changed <-chan struct{}
 }
 
 // Select renders the branch list and processes key events until completion.
 func (u *UI) Select(branches []Branch) (Result, error) {
+	return u.SelectWithState(branches, State{})
+}
+
+// SelectWithState behaves like Select but starts from the provided cursor and filter.
+func (u *UI) SelectWithState(branches []Branch, state State) (Result, error) {
+	return u.SelectFrom(Branches(branches), state)
+}
+
+// SelectFrom behaves like SelectWithState but reads the rows from p, which
+// may change only as announced on state.Updates. Only the rows on screen are
+// styled and have their detail text read, so lists of thousands of branches
+// scroll as quickly as short ones.
+func (u *UI) SelectFrom(p Provider, state State) (Result, error) {
 	if u == nil {
 		return Result{}, fmt.Errorf("ui is nil")
 	}
 	if u.in == nil || u.out == nil {
 		return Result{}, fmt.Errorf("ui input and output must be configured")
 	}
+	if u.resolveLayout() == LayoutPlain {
+		return u.selectPlain(p, state)
+	}
 
 	restore, err := u.enterRawMode()
 	if err != nil {
 		return Result{}, err
 	}
-	if restore != nil {
-		defer restore()
+	// Whatever ends the loop, a panic included, hand the terminal back in a
+	// usable state; otherwise the shell stays raw until the user runs reset.
+	defer func() {
+		value := recover()
+		u.leaveInline()
+		if restore != nil {
+			restore()
+		}
+		if value != nil {
+			// Start the stack trace on a fresh line with the cursor shown.
+			fmt.Fprint(u.out, showCursor+lineBreak)
+			panic(value)
+		}
+	}()
+
+	if err := u.enterInline(); err != nil {
+		return Result{}, err
 	}
 
-	reader := bufio.NewReader(u.in)
-	index := 0
-	maxIndex := len(branches) - 1
-	if err := u.render(branches, index); err != nil {
+	reader := LineReader(u.keySource())
+	view := newListView(p, state.Filter, state.Mode, state.Visibility, state.Load)
+	view.height = u.listHeight()
+	if _, err := u.fill(view); err != nil {
+		return Result{}, err
+	}
+	view.moveTo(state.Cursor)
+	if err := u.render(view); err != nil {
 		return Result{}, err
 	}
 
+	screen := &screenLock{}
+	stop := make(chan struct{})
+	var watchers sync.WaitGroup
+	// Runs before the terminal is handed back, so that no update draws
+	// after the selector is gone.
+	defer func() {
+		close(stop)
+		watchers.Wait()
+	}()
+	// watch redraws on each of updates; a refresh brings new ones, while
+	// the old ones end once the Reloader stopped whatever sends them.
+	watch := func(updates <-chan struct{}) {
+		if updates == nil {
+			return
+		}
+		watchers.Add(1)
+		go func() {
+			defer watchers.Done()
+			u.watch(updates, stop, screen, view)
+		}()
+	}
+	watch(state.Updates)
+	if state.Changed != nil && u.reloader != nil {
+		watchers.Add(1)
+		go func() {
+			defer watchers.Done()
+			u.reloadOn(state.Changed, stop, screen, view, watch)
+		}()
+	}
+
 	for {
 		b, err := reader.ReadByte()
 		if err != nil {
 			if err == io.EOF {
-				return Result{Quit: true}, nil
+				return Result{Quit: true, Filter: view.query}, nil
 			}
 			return Result{}, err
 		}
 
-		switch b {
-		case 0x03, 0x04, 0x1a: // Ctrl+C, Ctrl+D, Ctrl+Z
-			return Result{Quit: true}, nil
-		case 'j':
-			if index < maxIndex {
-				index++
-				if err := u.render(branches, index); err != nil {
-					return Result{}, err
-				}
+		screen.Lock()
+		result, done, err := u.handleKey(reader, view, b)
+		if err == nil {
+			err = screen.err
+		}
+		screen.ended = done || err != nil
+		updates := view.updates
+		view.updates = nil
+		screen.Unlock()
+		if !done && err == nil {
+			watch(updates)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		if done {
+			return result, nil
+		}
+	}
+}
+
+// screenLock lets keys and background updates take turns with the view
+// and the terminal.
+type screenLock struct {
+	sync.Mutex
+	// ended is set once a key ended the selection; nothing may draw after.
+	ended bool
+	// err is the first error drawing an update, reported on the next key.
+	err error
+}
+
+// watch redraws view each time updates receives until it is closed or stop
+// is. The rows are filtered again first, since their state may have changed.
+func (u *UI) watch(updates <-chan struct{}, stop <-chan struct{}, screen *screenLock, view *listView) {
+	for {
+		select {
+		case <-stop:
+			return
+		case _, ok := <-updates:
+			screen.Lock()
+			if screen.ended {
+				screen.Unlock()
+				return
 			}
-		case 'k':
-			if index > 0 {
-				index--
-				if err := u.render(branches, index); err != nil {
-					return Result{}, err
-				}
+			view.refresh()
+			err := u.render(view)
+			if err != nil {
+				screen.err = err
 			}
-		case 'q', 'Q':
-			return Result{Quit: true}, nil
-		case '\r', '\n':
-			if len(branches) == 0 {
-				return Result{Quit: true}, nil
+			screen.Unlock()
+			if err != nil || !ok {
+				return
 			}
-			selected := branches[index]
-			if selected.Current {
-				if _, err := fmt.Fprintf(u.out, "already on '%s'%s", selected.Name, lineBreak); err != nil {
-					return Result{}, err
-				}
-				return Result{Branch: selected.Name, AlreadyOn: true}, nil
+		}
+	}
+}
+
+// handleKey applies key b, reading the rest of its sequence from reader,
+// and redraws the view when it changed. It reports whether the selection
+// ended, and how.
+func (u *UI) handleKey(reader *bufio.Reader, view *listView, b byte) (Result, bool, error) {
+	quit := func() (Result, bool, error) {
+		return Result{Quit: true, Filter: view.query}, true, nil
+	}
+
+	changed := view.notice != ""
+	view.notice = ""
+	switch top := view.input.top(); {
+	case b == 0x03 || b == 0x04 || b == 0x1a: // Ctrl+C, Ctrl+D, Ctrl+Z
+		return quit()
+	case top == stateConfirming:
+		if b == 0x1b {
+			// Consume the whole sequence, so that an arrow key answers
+			// no instead of leaving its bytes to be read as keys.
+			if _, err := readEscape(reader); err != nil {
+				return Result{}, false, err
 			}
-			return Result{Branch: selected.Name}, nil
-		case 0x1b: // escape sequence
-			if err := u.handleEscape(reader, &index, maxIndex, branches); err != nil {
-				return Result{}, err
+		}
+		if result, done, err := u.handleConfirmKey(view, b); done || err != nil {
+			return result, done, err
+		}
+		changed = true
+	case top == stateLabeling:
+		updated, err := u.handleLabelKey(reader, view, b)
+		if err != nil {
+			return Result{}, false, err
+		}
+		changed = updated || changed
+	case b == 0x12: // Ctrl+R
+		updated, err := u.reload(view)
+		if err != nil {
+			return Result{}, false, err
+		}
+		changed = updated || changed
+	case b == '\r' || b == '\n':
+		if u.multi && len(view.marked) > 0 {
+			return Result{Marked: view.markedNames(), Filter: view.query}, true, nil
+		}
+		selected, ok := view.selected()
+		if !ok {
+			return quit()
+		}
+		if selected.Current && !u.action.AllowCurrent {
+			u.leaveInline()
+			if _, err := fmt.Fprintf(u.out, "already on '%s'%s", selected.Name, lineBreak); err != nil {
+				return Result{}, false, err
 			}
-		default:
-			// ignore other keys
+			return Result{Branch: selected.Name, AlreadyOn: true, Filter: view.query}, true, nil
+		}
+		result := Result{Branch: selected.Name, Remote: selected.Remote, Filter: view.query}
+		if u.multi {
+			result.Marked = []string{selected.Name}
+		}
+		return result, true, nil
+	case b == 0x1b: // escape sequence
+		updated, err := u.handleEscape(reader, view)
+		if err != nil {
+			return Result{}, false, err
+		}
+		changed = updated || changed
+	case top == stateFiltering:
+		changed = u.handleFilterKey(reader, view, b) || changed
+	case b == ' ' && u.multi:
+		changed = view.mark() || changed
+	case b == 'a' && u.multi:
+		changed = view.markAll() || changed
+	case b == '\t' || b == 'o':
+		changed = u.expand(view) || changed
+	case b == 'e':
+		changed = u.clean(view) || changed
+	case b == 'l':
+		changed = u.startLabeling(view) || changed
+	case b == 'j':
+		changed = view.down() || changed
+	case b == 'k':
+		changed = view.up() || changed
+	case b == '/':
+		// Editing the query again replaces the filter left applied.
+		view.input.remove(stateFiltered)
+		view.input.push(stateFiltering)
+		changed = true
+	case b == 'm' || b == 's' || b == 'r':
+		changed = view.toggle(b) || changed
+	case b == 'y' && u.clipboard != nil:
+		if selected, ok := view.selected(); ok {
+			if err := u.clipboard.Copy(selected.Name); err != nil {
+				view.notice = fmt.Sprintf("copy failed: %v", err)
+			} else {
+				view.notice = fmt.Sprintf("copied '%s' to the clipboard", selected.Name)
+			}
+			changed = true
+		}
+	case b == 'q' || b == 'Q':
+		if n := len(view.marked); n > 0 {
+			noun := "branches"
+			if n == 1 {
+				noun = "branch"
+			}
+			view.confirm(fmt.Sprintf("Discard %d marked %s and quit?", n, noun), quit)
+			changed = true
+			break
+		}
+		return quit()
+	default:
+		// ignore other keys
+	}
+
+	loaded, err := u.fill(view)
+	if err != nil {
+		return Result{}, false, err
+	}
+	if changed || loaded {
+		if err := u.render(view); err != nil {
+			return Result{}, false, err
 		}
 	}
+	return Result{}, false, nil
 }
 
-func (u *UI) handleEscape(reader *bufio.Reader, index *int, maxIndex int, branches []Branch) error {
-	next, err := reader.ReadByte()
-	if err == io.EOF {
-		return nil
+// fill loads rows so that a screenful remains below the cursor and reports
+// whether any rows were added.
+func (u *UI) fill(view *listView) (bool, error) {
+	page := view.height
+	if page <= 0 {
+		page = loadPageSize
 	}
-	if err != nil {
-		return err
+	return view.fill(view.cursor + 1 + page)
+}
+
+// listHeight returns how many branch rows fit on the terminal, or 0 when the
+// output is not a terminal and every row should be drawn.
+func (u *UI) listHeight() int {
+	if u.height > 0 {
+		return u.height
 	}
-	if next != '[' {
-		return nil
+	rows := u.inlineRows
+	if rows <= 0 {
+		_, rows = u.terminalSize()
+	}
+	if rows <= 0 {
+		return 0
+	}
+	if rows-u.chromeRows() < 3 {
+		return 3
 	}
+	return rows - u.chromeRows()
+}
 
-	dir, err := reader.ReadByte()
-	if err == io.EOF {
-		return nil
+// chromeRows returns how many terminal lines the header, separators, status,
+// help text, and border may take around the branch rows.
+func (u *UI) chromeRows() int {
+	// reservedRows counts the full header and the separator below the list.
+	rows := reservedRows - fullHeaderRows + u.headerRows() - 1 + u.separatorRows()
+	if u.border {
+		rows += 2
 	}
+	return rows
+}
+
+// rowWidth returns how many cells a branch row may take, or 0 when the output
+// is not a terminal and rows are never shortened.
+func (u *UI) rowWidth() int {
+	cols := u.screenWidth()
+	if cols > 0 && u.border {
+		return max(cols-borderWidth, 1)
+	}
+	return cols
+}
+
+// screenWidth returns the width of the output terminal, or 0 when the output
+// is not a terminal.
+func (u *UI) screenWidth() int {
+	if u.width > 0 {
+		return u.width
+	}
+	cols, _ := u.terminalSize()
+	return cols
+}
+
+// recordFrame hands a frame to the recorder, if any.
+func (u *UI) recordFrame(text string) {
+	if u.recorder != nil {
+		u.recorder.Frame(text)
+	}
+}
+
+// terminalSize returns the size of the output terminal, or zeros when the
+// output is not a terminal. A size set with SetSize takes precedence.
+func (u *UI) terminalSize() (cols, rows int) {
+	if u.cols > 0 || u.rows > 0 {
+		return u.cols, u.rows
+	}
+	file, ok := u.out.(*os.File)
+	if !ok {
+		return 0, 0
+	}
+	cols, rows, err := term.GetSize(int(file.Fd()))
 	if err != nil {
-		return err
+		return 0, 0
 	}
+	return cols, rows
+}
 
-	updated := false
-	switch dir {
-	case 'A':
-		if *index > 0 {
-			*index = *index - 1
-			updated = true
+// handleFilterKey applies a key typed while the filter prompt is active and
+// reports whether the view changed.
+func (u *UI) handleFilterKey(reader *bufio.Reader, view *listView, b byte) bool {
+	switch {
+	case b == 0x7f || b == 0x08: // Backspace
+		if view.backspace() {
+			return true
 		}
-	case 'B':
-		if maxIndex >= 0 && *index < maxIndex {
-			*index = *index + 1
-			updated = true
+		view.input.pop()
+		return true
+	case b == 0x15: // Ctrl+U
+		if view.query == "" {
+			return false
 		}
-	default:
-		return nil
+		view.setQuery("")
+		return true
+	case b < 0x20:
+		return false
 	}
+	view.appendQuery(typedText(reader, b))
+	return true
+}
 
-	if !updated {
-		return nil
+// typedText returns the character typed as b, reading the rest of it from
+// reader when it takes several bytes of UTF-8.
+func typedText(reader *bufio.Reader, b byte) string {
+	text := []byte{b}
+	if b >= utf8.RuneSelf {
+		// Collect the remaining bytes of a multi-byte UTF-8 sequence.
+		for !utf8.FullRune(text) && len(text) < utf8.UTFMax {
+			next, err := reader.ReadByte()
+			if err != nil {
+				break
+			}
+			text = append(text, next)
+		}
 	}
-	return u.render(branches, *index)
+	return string(text)
 }
 
-func (u *UI) render(branches []Branch, selected int) error {
-	if _, err := fmt.Fprint(u.out, clearScreen); err != nil {
-		return err
+// handleEscape reads the rest of an escape sequence: an arrow moves the
+// cursor, F5 refreshes the rows, and Esc on its own pops the top input
+// layer.
+func (u *UI) handleEscape(reader *bufio.Reader, view *listView) (bool, error) {
+	seq, err := readEscape(reader)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case seq.bare:
+		return u.escape(view), nil
+	case seq.final == 'A':
+		return view.up(), nil
+	case seq.final == 'B':
+		return view.down(), nil
+	case seq.final == '~' && seq.params == "15": // F5
+		return u.reload(view)
+	default:
+		return false, nil
+	}
+}
+
+func (u *UI) render(view *listView) error {
+	if u.log != nil {
+		started := time.Now()
+		defer func() {
+			u.log.Debug("render", "rows", len(view.visible), "cursor", view.cursor, "query", view.query, "input", view.input.top(), "duration", time.Since(started))
+		}()
 	}
 
 	theme := u.theme
@@ -292,64 +941,252 @@ func (u *UI) render(branches []Branch, selected int) error {
 		theme = DefaultTheme
 	}
 
+	var frame strings.Builder
+	if err := u.drawFrame(&frame, view, theme); err != nil {
+		return err
+	}
+	text := frame.String()
+	if u.border {
+		text = drawBorder(text, u.borderTitle(), u.screenWidth(), theme)
+	}
+	if u.inlineRows > 0 {
+		// Stay within the reserved lines: a final line break would scroll.
+		lines := strings.Split(strings.TrimSuffix(text, lineBreak), lineBreak)
+		if len(lines) > u.inlineRows {
+			lines = lines[:u.inlineRows]
+		}
+		text = strings.Join(lines, lineBreak)
+		u.recordFrame(text)
+		return writeFrame(u.out, restoreCursor+text)
+	}
+	u.recordFrame(text)
+	return writeFrame(u.out, cursorHome+text)
+}
+
+// writeFrame draws frame over the previous one in a single write. Instead
+// of clearing the screen first, which shows a blank screen until the rest
+// arrives, every line erases what is left of the old one and the lines
+// below the frame are cleared at the end, so that a frame split across
+// packets over SSH never shows half-drawn.
+func writeFrame(w io.Writer, frame string) error {
+	frame = strings.ReplaceAll(frame, lineBreak, clearRight+lineBreak)
+	_, err := io.WriteString(w, beginSync+frame+clearBelow+endSync)
+	return err
+}
+
+// drawHeader writes the header in the selected style. A border shows the
+// action name, or the compact header, in its title instead.
+func (u *UI) drawHeader(w io.Writer, theme Theme) error {
+	if err := u.drawBanner(w); err != nil {
+		return err
+	}
+	switch u.header {
+	case HeaderNone:
+		return nil
+	case HeaderCompact:
+		if text := u.compactHeader(); text != "" && !u.border {
+			_, err := fmt.Fprintf(w, "%s%s%s%s", theme.ActionLabel, text, resetColor, lineBreak)
+			return err
+		}
+		return nil
+	}
+
 	headerPrinted := false
-	if name := strings.TrimSpace(u.action.Name); name != "" {
-		if _, err := fmt.Fprintf(u.out, "%sAction: %s%s%s", theme.ActionLabel, name, resetColor, lineBreak); err != nil {
+	if name := strings.TrimSpace(u.action.Name); name != "" && !u.border {
+		if _, err := fmt.Fprintf(w, "%sAction: %s%s%s", theme.ActionLabel, name, resetColor, lineBreak); err != nil {
 			return err
 		}
 		headerPrinted = true
 	}
 	if description := strings.TrimSpace(u.action.Description); description != "" {
-		if _, err := fmt.Fprintf(u.out, "%s%s%s%s", theme.ActionDescription, description, resetColor, lineBreak); err != nil {
+		if _, err := fmt.Fprintf(w, "%s%s%s%s", theme.ActionDescription, description, resetColor, lineBreak); err != nil {
 			return err
 		}
 		headerPrinted = true
 	}
 	if headerPrinted {
-		if _, err := fmt.Fprint(u.out, lineBreak); err != nil {
+		return u.writeSeparator(w, theme)
+	}
+	return nil
+}
+
+// drawFrame writes the selector contents to w, one line per lineBreak.
+func (u *UI) drawFrame(w io.Writer, view *listView, theme Theme) error {
+	if err := u.drawHeader(w, theme); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%sSelect a branch:%s%s", theme.Branch, resetColor, lineBreak); err != nil {
+		return err
+	}
+	if view.filtering() || view.query != "" {
+		label := "Filter"
+		if view.mode == match.ModeRegex {
+			label = "Regex"
+		}
+		status := ""
+		if view.queryErr != nil {
+			status = theme.Help + " (invalid pattern)"
+		}
+		if _, err := fmt.Fprintf(w, "%s%s: %s%s%s%s", theme.ActionLabel, label, view.query, status, resetColor, lineBreak); err != nil {
 			return err
 		}
 	}
-	if _, err := fmt.Fprintf(u.out, "%sSelect a branch:%s%s", theme.Branch, resetColor, lineBreak); err != nil {
-		return err
+	width := u.rowWidth()
+	cursor := u.marker.prefix(theme)
+	indent := strings.Repeat(" ", u.marker.width())
+	start, end := view.window()
+	// room bounds the detail lines of the last row drawn when the list is
+	// shorter than the highlighted row with its details.
+	room := -1
+	if view.height > 0 {
+		room = view.height - (end - start)
 	}
-	for i, branch := range branches {
-		if i == selected {
-			if branch.Current {
-				if _, err := fmt.Fprintf(u.out, "%s> %s %s(current branch)%s%s", theme.Selected, branch.Name, theme.SelectedBadge, resetColor, lineBreak); err != nil {
-					return err
-				}
-				continue
-			}
-			if _, err := fmt.Fprintf(u.out, "%s> %s%s%s", theme.Selected, branch.Name, resetColor, lineBreak); err != nil {
+	for i := start; i < end; i++ {
+		if i > start {
+			if err := u.drawRowDetails(w, view, i-1, &room, theme); err != nil {
 				return err
 			}
-			continue
 		}
-
+		branch := view.rows.Branch(view.visible[i])
+		detail := ""
+		if text := strings.TrimSpace(view.rows.Detail(view.visible[i])); text != "" {
+			detail = " " + text
+		}
+		if width > 0 {
+			// A long detail, such as a commit subject, gives way to the name.
+			detail = truncateWidth(detail, (width-u.marker.width())/2)
+		}
+		badge := rowBadge(branch)
 		if branch.Current {
-			if _, err := fmt.Fprintf(u.out, "  %s%s%s %s(current branch)%s%s", theme.Branch, branch.Name, resetColor, theme.Badge, resetColor, lineBreak); err != nil {
+			// The badge of the current branch takes the place of its detail.
+			detail = ""
+		}
+		chips := ""
+		if len(branch.Labels) > 0 {
+			chips = " " + labelChips(branch.Labels)
+		}
+		suffix := chips + detail
+		if badge != "" {
+			suffix = " " + badge + chips + detail
+		}
+		name := branch.Name
+		if u.multi {
+			name = checkbox(view.marked[view.visible[i]]) + name
+		}
+		if width > 0 {
+			name = truncateWidth(name, width-u.marker.width()-displayWidth(suffix))
+		}
+		if i == view.cursor {
+			// Without a marker the highlight spans the whole row instead.
+			fill := ""
+			if u.marker.Symbol == "" && width > 0 {
+				fill = strings.Repeat(" ", max(width-displayWidth(name+suffix), 0))
+			}
+			text := name
+			if badge != "" {
+				text += " " + theme.SelectedBadge + badge
+				if chips != "" || detail != "" {
+					text += theme.Selected
+				}
+			}
+			if chips != "" {
+				text += " " + coloredChips(branch.Labels, theme.Selected)
+			}
+			if _, err := fmt.Fprintf(w, "%s%s%s%s%s%s", theme.Selected, cursor, text, detail, fill, resetColor+lineBreak); err != nil {
 				return err
 			}
 			continue
 		}
-		if _, err := fmt.Fprintf(u.out, "  %s%s%s%s", theme.Branch, branch.Name, resetColor, lineBreak); err != nil {
+
+		line := indent + theme.Branch + name + resetColor
+		if badge != "" {
+			line += " " + theme.Badge + badge + resetColor
+		}
+		if chips != "" {
+			line += " " + coloredChips(branch.Labels, "")
+		}
+		if detail != "" {
+			line += theme.Help + detail + resetColor
+		}
+		if _, err := fmt.Fprint(w, line+lineBreak); err != nil {
+			return err
+		}
+	}
+	if end > start {
+		if err := u.drawRowDetails(w, view, end-1, &room, theme); err != nil {
+			return err
+		}
+	}
+	if start > 0 || end < len(view.visible) || !view.exhausted {
+		if _, err := fmt.Fprintf(w, "%s%s%s%s", theme.Help, positionStatus(start, end, len(view.visible), !view.exhausted), resetColor, lineBreak); err != nil {
+			return err
+		}
+	}
+	if err := u.writeSeparator(w, theme); err != nil {
+		return err
+	}
+	if view.visibility != nil {
+		if _, err := fmt.Fprintf(w, "%s%s%s%s", theme.Help, visibilityStatus(*view.visibility), resetColor, lineBreak); err != nil {
+			return err
+		}
+	}
+	if view.notice != "" {
+		if _, err := fmt.Fprintf(w, "%s%s%s%s", theme.ActionDescription, view.notice, resetColor, lineBreak); err != nil {
 			return err
 		}
 	}
-	if _, err := fmt.Fprint(u.out, lineBreak); err != nil {
+	switch view.input.top() {
+	case stateConfirming:
+		_, err := fmt.Fprintf(w, "%s%s%s%s%sy to confirm, any other key to go back%s%s", theme.ActionDescription, view.question, resetColor, lineBreak, theme.Help, resetColor, lineBreak)
+		return err
+	case stateFiltering:
+		_, err := fmt.Fprintf(w, "%stype to filter, ↑/↓ to move, Enter to select, Esc to stop filtering%s%s", theme.Help, resetColor, lineBreak)
+		return err
+	case stateLabeling:
+		_, err := fmt.Fprintf(w, "%sLabels for '%s': %s%s%s%slabels separated by commas, Enter to save, Esc to cancel%s%s", theme.ActionLabel, view.labeling, view.labels, resetColor, lineBreak, theme.Help, resetColor, lineBreak)
 		return err
 	}
 	enterLabel := strings.TrimSpace(u.action.EnterLabel)
 	if enterLabel == "" {
 		enterLabel = "select"
 	}
-	if _, err := fmt.Fprintf(u.out, "%sj/k or ↑/↓ to move, Enter to %s, q to exit%s%s", theme.Help, enterLabel, resetColor, lineBreak); err != nil {
+	copyHint := ""
+	if u.clipboard != nil {
+		copyHint = ", y to copy"
+	}
+	markHint := ""
+	if u.multi {
+		markHint = ", Space to mark, a to mark all"
+	}
+	if u.expander != nil {
+		markHint += ", Tab for details"
+	}
+	if u.cleanup != nil {
+		markHint += ", e to " + u.cleanupLabel
+	}
+	if u.labeler != nil {
+		markHint += ", l to label"
+	}
+	switch view.input.top() {
+	case stateFiltered:
+		markHint += ", Esc to clear the filter"
+	case stateMarking:
+		markHint += ", Esc to clear the marks"
+	}
+	if _, err := fmt.Fprintf(w, "%sj/k or ↑/↓ to move, / to filter%s, Enter to %s%s, q to exit%s%s", theme.Help, markHint, enterLabel, copyHint, resetColor, lineBreak); err != nil {
 		return err
 	}
 	return nil
 }
 
+// checkbox precedes the name of each row in multi-select mode.
+func checkbox(marked bool) string {
+	if marked {
+		return "[x] "
+	}
+	return "[ ] "
+}
+
 func (u *UI) enterRawMode() (func(), error) {
 	file, ok := u.in.(*os.File)
 	if !ok {
@@ -370,3 +1207,27 @@ func (u *UI) enterRawMode() (func(), error) {
 		_ = term.Restore(fd, state)
 	}, nil
 }
+
+// visibilityStatus describes the toggles for the status bar.
+func visibilityStatus(v Visibility) string {
+	state := func(shown bool) string {
+		if shown {
+			return "shown"
+		}
+		return "hidden"
+	}
+	return fmt.Sprintf("[m] merged: %s  [s] stale: %s  [r] remote: %s", state(v.Merged), state(v.Stale), state(v.Remote))
+}
+
+// positionStatus describes which rows are drawn, marking totals that may grow
+// as more rows load.
+func positionStatus(start, end, total int, more bool) string {
+	suffix := ""
+	if more {
+		suffix = "+"
+	}
+	if start == end {
+		return fmt.Sprintf("no rows of %d%s", total, suffix)
+	}
+	return fmt.Sprintf("rows %d-%d of %d%s", start+1, end, total, suffix)
+}
@@ -2,11 +2,23 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"branch-navigator/internal/match"
 )
 
-const clearSequence = "\033[2J\033[H"
+// frameStart begins every full-screen frame.
+const frameStart = beginSync + cursorHome
 
 var checkoutAction = ActionDetails{
 	Name:        "Checkout branch",
@@ -16,17 +28,61 @@ var checkoutAction = ActionDetails{
 
 func framesFromOutput(t *testing.T, output string) []string {
 	t.Helper()
-	frames := strings.Split(output, clearSequence)
-	// remove possible leading empty chunk if output starts with clearSequence
+	frames := strings.Split(output, frameStart)
+	// remove possible leading empty chunk if output starts with frameStart
 	if len(frames) > 0 && frames[0] == "" {
 		frames = frames[1:]
 	}
 	if len(frames) == 0 {
 		t.Fatalf("no frames found in output: %q", output)
 	}
+	for i, frame := range frames {
+		frames[i] = frameText(frame)
+	}
 	return frames
 }
 
+// frameText drops the sequences with which writeFrame erases the previous
+// frame, leaving the text drawn.
+func frameText(frame string) string {
+	frame = strings.Replace(frame, clearBelow+endSync, "", 1)
+	return strings.ReplaceAll(frame, clearRight, "")
+}
+
+func TestWriteFrame(t *testing.T) {
+	t.Parallel()
+
+	out := &countingWriter{}
+	if err := writeFrame(out, cursorHome+"Select:"+lineBreak+"> main"); err != nil {
+		t.Fatalf("writeFrame returned error: %v", err)
+	}
+	want := beginSync + cursorHome + "Select:" + clearRight + lineBreak + "> main" + clearBelow + endSync
+	if out.String() != want {
+		t.Fatalf("writeFrame wrote %q, want %q", out.String(), want)
+	}
+	if out.writes != 1 {
+		t.Fatalf("writeFrame used %d writes, want 1", out.writes)
+	}
+	if strings.Contains(out.String(), "\033[2J") {
+		t.Fatalf("writeFrame must not blank the screen: %q", out.String())
+	}
+}
+
+// countingWriter records how many writes built its contents.
+type countingWriter struct {
+	buf    bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) String() string {
+	return w.buf.String()
+}
+
 func TestSelectMovesWithJAndEnter(t *testing.T) {
 	t.Parallel()
 
@@ -73,7 +129,7 @@ func TestSelectMovesWithJAndEnter(t *testing.T) {
 	if !strings.Contains(last, currentBadge) {
 		t.Fatalf("current branch marker missing or incorrect. frame=%q", last)
 	}
-	if !strings.Contains(output.String(), expectedTheme.Help+"j/k or ↑/↓ to move, Enter to checkout the selected branch, q to exit"+resetColor) {
+	if !strings.Contains(output.String(), expectedTheme.Help+"j/k or ↑/↓ to move, / to filter, Enter to checkout the selected branch, q to exit"+resetColor) {
 		t.Fatalf("help message missing from output: %q", output.String())
 	}
 }
@@ -154,6 +210,34 @@ func TestSelectCurrentBranch(t *testing.T) {
 	}
 }
 
+func TestSelectCurrentBranchAllowed(t *testing.T) {
+	t.Parallel()
+
+	input := bytes.NewBufferString("\r")
+	output := &bytes.Buffer{}
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/alpha", Current: false},
+	}
+
+	ui := New(input, output, ActionDetails{Name: "Push branch", AllowCurrent: true})
+	result, err := ui.Select(branches)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	if result.AlreadyOn {
+		t.Fatal("expected AlreadyOn to be false when the action allows the current branch")
+	}
+	if result.Branch != "main" {
+		t.Fatalf("expected current branch name, got %q", result.Branch)
+	}
+	if strings.Contains(output.String(), "already on") {
+		t.Fatalf("unexpected already on message in output: %q", output.String())
+	}
+}
+
 func TestSelectHandlesControlKeys(t *testing.T) {
 	t.Parallel()
 
@@ -198,7 +282,14 @@ func TestThemeByName(t *testing.T) {
 		{name: "solarized alias", input: "Solarized-Dark", want: ThemeSolarized, wantOkay: true},
 		{name: "gruvbox", input: "gruvbox", want: ThemeGruvbox, wantOkay: true},
 		{name: "one dark alias", input: "one-dark", want: ThemeOneDark, wantOkay: true},
+		{name: "pinned light variant", input: "Gruvbox-Light", want: ThemeGruvboxLight, wantOkay: true},
+		{name: "latte alias", input: "latte", want: ThemeCatppuccinLatte, wantOkay: true},
 		{name: "unknown", input: "rainbow", wantOkay: false},
+		{name: "unknown basic", input: "rainbow-16", wantOkay: false},
+		{name: "basic", input: "Nord-16", want: ThemeNord.Basic(), wantOkay: true},
+		{name: "suffix without dash", input: "classic16", want: ThemeClassic.Basic(), wantOkay: true},
+		{name: "basic alias", input: "mocha-16", wantOkay: false},
+		{name: "basic twice", input: "nord-16-16", wantOkay: false},
 	}
 
 	for _, tt := range tests {
@@ -214,3 +305,865 @@ func TestThemeByName(t *testing.T) {
 		}
 	}
 }
+
+func TestThemeFamilyByName(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input    string
+		light    Theme
+		dark     Theme
+		adaptive bool
+	}{
+		"default":      {input: "", dark: ThemeCatppuccin, light: ThemeCatppuccinLatte, adaptive: true},
+		"family":       {input: "solarized", dark: ThemeSolarized, light: ThemeSolarizedLight, adaptive: true},
+		"one":          {input: "one", dark: ThemeOneDark, light: ThemeOneLight, adaptive: true},
+		"pinned dark":  {input: "nord-dark", dark: ThemeNord, light: ThemeNord},
+		"pinned light": {input: "classic-light", dark: ThemeClassicLight, light: ThemeClassicLight},
+		"basic family": {input: "classic-16", dark: ThemeClassic.Basic(), light: ThemeClassicLight.Basic(), adaptive: true},
+		"basic pinned": {input: "nord-light-16", dark: ThemeNordLight.Basic(), light: ThemeNordLight.Basic()},
+		"basic one":    {input: " One-16 ", dark: ThemeOneDark.Basic(), light: ThemeOneLight.Basic(), adaptive: true},
+		"basic nodash": {input: "nord-light16", dark: ThemeNordLight.Basic(), light: ThemeNordLight.Basic()},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			family, ok := ThemeFamilyByName(tc.input)
+			if !ok {
+				t.Fatalf("ThemeFamilyByName(%q) not found", tc.input)
+			}
+			if family.Adaptive() != tc.adaptive {
+				t.Fatalf("Adaptive() = %v, want %v", family.Adaptive(), tc.adaptive)
+			}
+			if family.For(BackgroundLight) != tc.light || family.For(BackgroundDark) != tc.dark || family.For(BackgroundUnknown) != tc.dark {
+				t.Fatalf("unexpected variants for %q", tc.input)
+			}
+		})
+	}
+}
+
+func TestSelectRendersDetail(t *testing.T) {
+	t.Parallel()
+
+	input := bytes.NewBufferString("q")
+	output := &bytes.Buffer{}
+
+	branches := []Branch{
+		{Name: "origin", Detail: "git@example.com:org/repo.git"},
+		{Name: "upstream", Detail: "https://example.com/repo.git"},
+	}
+
+	ui := New(input, output, ActionDetails{})
+	if _, err := ui.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	frame := framesFromOutput(t, output.String())[0]
+	theme := DefaultTheme
+	if !strings.Contains(frame, theme.Selected+"> origin git@example.com:org/repo.git"+resetColor) {
+		t.Fatalf("selected detail missing or incorrect. frame=%q", frame)
+	}
+	if !strings.Contains(frame, "  "+theme.Branch+"upstream"+resetColor+theme.Help+" https://example.com/repo.git"+resetColor) {
+		t.Fatalf("unselected detail missing or incorrect. frame=%q", frame)
+	}
+}
+
+func TestSelectRendersDefaultBadge(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		branches []Branch
+		want     []string
+	}{
+		"current default": {
+			branches: []Branch{{Name: "main", Current: true, Default: true}, {Name: "feature/x"}},
+			want:     []string{DefaultTheme.Selected + "> main " + DefaultTheme.SelectedBadge + "(current branch, default)"},
+		},
+		"default with detail": {
+			branches: []Branch{{Name: "feature/x", Current: true}, {Name: "main", Default: true, Detail: " (merged)"}},
+			want: []string{
+				DefaultTheme.Selected + "> feature/x " + DefaultTheme.SelectedBadge + "(current branch)",
+				"  " + DefaultTheme.Branch + "main" + resetColor + " " + DefaultTheme.Badge + "(default)" + resetColor + DefaultTheme.Help + " (merged)" + resetColor,
+			},
+		},
+		"highlighted default with detail": {
+			branches: []Branch{{Name: "main", Default: true, Detail: " (merged)"}},
+			want:     []string{DefaultTheme.Selected + "> main " + DefaultTheme.SelectedBadge + "(default)" + DefaultTheme.Selected + " (merged)"},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+			if _, err := ui.Select(tc.branches); err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			frame := framesFromOutput(t, output.String())[0]
+			for _, want := range tc.want {
+				if !strings.Contains(frame, want) {
+					t.Fatalf("frame is missing %q: %q", want, frame)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectWithStateStartsOnCursor(t *testing.T) {
+	t.Parallel()
+
+	input := bytes.NewBufferString("\r")
+	output := &bytes.Buffer{}
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/alpha"},
+		{Name: "feature/beta"},
+	}
+
+	result, err := New(input, output, checkoutAction).SelectWithState(branches, State{Cursor: "feature/beta"})
+	if err != nil {
+		t.Fatalf("SelectWithState returned error: %v", err)
+	}
+	if result.Branch != "feature/beta" {
+		t.Fatalf("unexpected branch selected: got %q", result.Branch)
+	}
+}
+
+func TestSelectLogsRenders(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	ui := New(bytes.NewBufferString("j\r"), &bytes.Buffer{}, checkoutAction)
+	ui.SetLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if _, err := ui.Select([]Branch{{Name: "main", Current: true}, {Name: "feature/alpha"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a record per frame, got %q", logs.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["msg"] != "render" || record["rows"] != float64(2) || record["cursor"] != float64(1) {
+		t.Fatalf("unexpected record %v", record)
+	}
+}
+
+func TestSelectRestoresTerminalOnPanic(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("j\r"), output, checkoutAction)
+	load := func(int) (Provider, error) { panic("loader failed") }
+
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		_, _ = ui.SelectWithState([]Branch{{Name: "main", Current: true}}, State{Load: load})
+	}()
+
+	if recovered != "loader failed" {
+		t.Fatalf("recovered %#v, want the loader's panic", recovered)
+	}
+	if !strings.HasSuffix(output.String(), showCursor+lineBreak) {
+		t.Fatalf("output = %q, want the cursor shown on a fresh line", output.String())
+	}
+}
+
+func TestSelectFilter(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/alpha"},
+		{Name: "feature/beta"},
+		{Name: "bugfix/Beta-crash"},
+	}
+
+	cases := map[string]struct {
+		input      string
+		state      State
+		wantBranch string
+		wantQuit   bool
+		wantFilter string
+	}{
+		"typing narrows the list": {
+			input:      "/beta\r",
+			wantBranch: "feature/beta",
+			wantFilter: "beta",
+		},
+		"arrows move within matches": {
+			input:      "/beta\x1b[B\r",
+			wantBranch: "bugfix/Beta-crash",
+			wantFilter: "beta",
+		},
+		"upper-case query is case-sensitive": {
+			input:      "/Beta\r",
+			wantBranch: "bugfix/Beta-crash",
+			wantFilter: "Beta",
+		},
+		"query matches fuzzily": {
+			input:      "/fbt\r",
+			wantBranch: "feature/beta",
+			wantFilter: "fbt",
+		},
+		"regex mode": {
+			input:      "/^bug\r",
+			state:      State{Mode: match.ModeRegex},
+			wantBranch: "bugfix/Beta-crash",
+			wantFilter: "^bug",
+		},
+		"invalid regex keeps the last matches": {
+			input:      "/fix(\r",
+			state:      State{Mode: match.ModeRegex},
+			wantBranch: "bugfix/Beta-crash",
+			wantFilter: "fix(",
+		},
+		"backspace edits the query": {
+			input:      "/betx\x7fa\r",
+			wantBranch: "feature/beta",
+			wantFilter: "beta",
+		},
+		"ctrl+u clears the query": {
+			input:      "/zzz\x15\r",
+			wantBranch: "main",
+		},
+		"no matches quits with filter": {
+			input:      "/zzz\r",
+			wantQuit:   true,
+			wantFilter: "zzz",
+		},
+		"initial filter is restored": {
+			input:      "q",
+			state:      State{Filter: "alpha"},
+			wantQuit:   true,
+			wantFilter: "alpha",
+		},
+		"initial filter and cursor": {
+			input:      "\r",
+			state:      State{Filter: "beta", Cursor: "bugfix/Beta-crash"},
+			wantBranch: "bugfix/Beta-crash",
+			wantFilter: "beta",
+		},
+		"leaving filter mode keeps q as quit": {
+			input:      "/a\x7f\x7fq",
+			wantQuit:   true,
+			wantFilter: "",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			result, err := New(bytes.NewBufferString(tc.input), output, checkoutAction).SelectWithState(branches, tc.state)
+			if err != nil {
+				t.Fatalf("SelectWithState returned error: %v", err)
+			}
+			if result.Branch != tc.wantBranch || result.Quit != tc.wantQuit || result.Filter != tc.wantFilter {
+				t.Fatalf("result = %+v, want branch %q quit %v filter %q", result, tc.wantBranch, tc.wantQuit, tc.wantFilter)
+			}
+		})
+	}
+}
+
+func TestSelectFilterRendersQuery(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/alpha"},
+	}
+	if _, err := New(bytes.NewBufferString("/alp\r"), output, checkoutAction).Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	frames := framesFromOutput(t, output.String())
+	last := frames[len(frames)-1]
+	if !strings.Contains(last, DefaultTheme.ActionLabel+"Filter: alp"+resetColor) {
+		t.Fatalf("filter line missing. frame=%q", last)
+	}
+	if strings.Contains(last, "main") {
+		t.Fatalf("expected non-matching branch to be hidden. frame=%q", last)
+	}
+}
+
+func TestSelectVisibilityToggles(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true, Merged: true},
+		{Name: "old", Merged: true, Stale: true},
+		{Name: "done", Merged: true},
+		{Name: "origin/topic", Remote: true},
+		{Name: "feature/x"},
+	}
+
+	cases := map[string]struct {
+		input      string
+		visibility *Visibility
+		want       string
+	}{
+		"nil visibility lists every row": {
+			input: "jjj\r",
+			want:  "origin/topic",
+		},
+		"hidden rows are skipped": {
+			input:      "j\r",
+			visibility: &Visibility{Merged: true, Stale: true},
+			want:       "old",
+		},
+		"remote hidden by default": {
+			input:      "jjj\r",
+			visibility: &Visibility{Merged: true, Stale: true},
+			want:       "feature/x",
+		},
+		"r shows remote rows": {
+			input:      "rjjj\r",
+			visibility: &Visibility{Merged: true, Stale: true},
+			want:       "origin/topic",
+		},
+		"m hides merged rows but not the current branch": {
+			input:      "mj\r",
+			visibility: &Visibility{Merged: true, Stale: true, Remote: true},
+			want:       "origin/topic",
+		},
+		"s hides stale rows": {
+			input:      "sj\r",
+			visibility: &Visibility{Merged: true, Stale: true},
+			want:       "done",
+		},
+		"toggles keep the cursor on its branch": {
+			input:      "jjjmr\r",
+			visibility: &Visibility{Merged: true, Stale: true},
+			want:       "feature/x",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := New(bytes.NewBufferString(tc.input), &bytes.Buffer{}, checkoutAction).SelectWithState(branches, State{Visibility: tc.visibility})
+			if err != nil {
+				t.Fatalf("SelectWithState returned error: %v", err)
+			}
+			if result.Branch != tc.want {
+				t.Fatalf("selected %q, want %q", result.Branch, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectMultiSelect(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "done"},
+		{Name: "old"},
+		{Name: "feature/x"},
+	}
+
+	cases := map[string]struct {
+		input string
+		want  Result
+	}{
+		"space marks and moves down": {
+			input: "j  \r",
+			want:  Result{Marked: []string{"done", "old"}},
+		},
+		"space again clears the mark": {
+			input: "j k \r",
+			want:  Result{Branch: "old", Marked: []string{"old"}},
+		},
+		"marks hidden by the filter are kept": {
+			input: "jj /feat\r",
+			want:  Result{Marked: []string{"old"}, Filter: "feat"},
+		},
+		"a marks all but the current branch": {
+			input: "a\r",
+			want:  Result{Marked: []string{"done", "old", "feature/x"}},
+		},
+		"a twice clears every mark": {
+			input: "aaj\r",
+			want:  Result{Branch: "done", Marked: []string{"done"}},
+		},
+		"current branch cannot be marked": {
+			input: " jj \r",
+			want:  Result{Marked: []string{"old"}},
+		},
+		"enter without marks takes the highlighted row": {
+			input: "jjj\r",
+			want:  Result{Branch: "feature/x", Marked: []string{"feature/x"}},
+		},
+		"quit": {
+			input: " q",
+			want:  Result{Quit: true},
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ui := New(bytes.NewBufferString(tc.input), &bytes.Buffer{}, checkoutAction)
+			ui.SetMultiSelect(true)
+			result, err := ui.Select(branches)
+			if err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Fatalf("result = %+v, want %+v", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectMultiSelectRendersMarks(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("j q"), output, checkoutAction)
+	ui.SetMultiSelect(true)
+	if _, err := ui.Select([]Branch{{Name: "main", Current: true}, {Name: "done"}, {Name: "old"}}); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	frames := framesFromOutput(t, output.String())
+	// The last frame asks whether to discard the mark.
+	lines := plainLines(frames[len(frames)-2])
+	for _, want := range []string{"  [ ] main (current branch)", "  [x] done", "> [ ] old", "j/k or ↑/↓ to move, / to filter, Space to mark, a to mark all, Esc to clear the marks, Enter to"} {
+		if !containsPrefix(lines, want) {
+			t.Fatalf("expected a line starting with %q in %q", want, lines)
+		}
+	}
+}
+
+func TestSelectVisibilityStatus(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	visibility := &Visibility{Merged: true}
+	branches := []Branch{{Name: "main", Current: true}}
+	if _, err := New(bytes.NewBufferString("sq"), output, checkoutAction).SelectWithState(branches, State{Visibility: visibility}); err != nil {
+		t.Fatalf("SelectWithState returned error: %v", err)
+	}
+
+	frames := framesFromOutput(t, output.String())
+	if !strings.Contains(frames[0], "[m] merged: shown  [s] stale: hidden  [r] remote: hidden") {
+		t.Fatalf("status bar missing from first frame: %q", frames[0])
+	}
+	if !strings.Contains(frames[len(frames)-1], "[m] merged: shown  [s] stale: shown  [r] remote: hidden") {
+		t.Fatalf("status bar not updated after toggle: %q", frames[len(frames)-1])
+	}
+	if visibility.Stale {
+		t.Fatal("toggling must not modify the caller's Visibility")
+	}
+}
+
+type fakeClipboard struct {
+	copied []string
+	err    error
+}
+
+func (c *fakeClipboard) Copy(text string) error {
+	c.copied = append(c.copied, text)
+	return c.err
+}
+
+func TestSelectCopiesHighlightedBranch(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/alpha"},
+	}
+
+	cases := map[string]struct {
+		clipboard  *fakeClipboard
+		wantCopied []string
+		wantNotice string
+	}{
+		"copied": {
+			clipboard:  &fakeClipboard{},
+			wantCopied: []string{"feature/alpha"},
+			wantNotice: "copied 'feature/alpha' to the clipboard",
+		},
+		"failure is reported": {
+			clipboard:  &fakeClipboard{err: errors.New("no tool")},
+			wantCopied: []string{"feature/alpha"},
+			wantNotice: "copy failed: no tool",
+		},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			selector := New(bytes.NewBufferString("jyq"), output, checkoutAction)
+			selector.SetClipboard(tc.clipboard)
+			result, err := selector.Select(branches)
+			if err != nil {
+				t.Fatalf("Select returned error: %v", err)
+			}
+			if !result.Quit {
+				t.Fatalf("expected quit after copying, got %+v", result)
+			}
+			if !reflect.DeepEqual(tc.clipboard.copied, tc.wantCopied) {
+				t.Fatalf("copied %v, want %v", tc.clipboard.copied, tc.wantCopied)
+			}
+			frames := framesFromOutput(t, output.String())
+			last := frames[len(frames)-1]
+			if !strings.Contains(last, tc.wantNotice) {
+				t.Fatalf("notice %q missing from frame %q", tc.wantNotice, last)
+			}
+			if !strings.Contains(last, "Enter to checkout the selected branch, y to copy, q to exit") {
+				t.Fatalf("copy hint missing from frame %q", last)
+			}
+		})
+	}
+}
+
+func TestSelectIgnoresCopyWithoutClipboard(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	branches := []Branch{{Name: "main", Current: true}}
+	if _, err := New(bytes.NewBufferString("yq"), output, checkoutAction).Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if frames := framesFromOutput(t, output.String()); len(frames) != 1 {
+		t.Fatalf("expected y to be ignored, got %d frames", len(frames))
+	}
+}
+
+func TestSelectScrollsViewport(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{{Name: "main", Current: true}, {Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("jjjkkkq"), output, checkoutAction)
+	ui.height = 2
+	if _, err := ui.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	frames := framesFromOutput(t, output.String())
+	cases := []struct {
+		frame    int
+		rows     []string
+		hidden   []string
+		position string
+	}{
+		{frame: 0, rows: []string{"> main", "  a"}, hidden: []string{"  b"}, position: "rows 1-2 of 5"},
+		{frame: 3, rows: []string{"  b", "> c"}, hidden: []string{"  a", "  d"}, position: "rows 3-4 of 5"},
+		{frame: 5, rows: []string{"> a", "  b"}, hidden: []string{"  c"}, position: "rows 2-3 of 5"},
+	}
+	for _, tc := range cases {
+		lines := plainLines(frames[tc.frame])
+		for _, row := range tc.rows {
+			if !containsPrefix(lines, row) {
+				t.Fatalf("frame %d missing %q: %q", tc.frame, row, lines)
+			}
+		}
+		for _, row := range tc.hidden {
+			if containsPrefix(lines, row) {
+				t.Fatalf("frame %d unexpectedly shows %q: %q", tc.frame, row, lines)
+			}
+		}
+		if !containsPrefix(lines, tc.position) {
+			t.Fatalf("frame %d missing position %q: %q", tc.frame, tc.position, lines)
+		}
+	}
+}
+
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// plainLines splits a frame into lines with color sequences removed.
+func plainLines(frame string) []string {
+	return strings.Split(ansiSequence.ReplaceAllString(frame, ""), lineBreak)
+}
+
+func containsPrefix(lines []string, prefix string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSelectLoadsRowsLazily(t *testing.T) {
+	t.Parallel()
+
+	var requested []int
+	next := 0
+	load := func(n int) (Provider, error) {
+		requested = append(requested, n)
+		var rows Branches
+		for i := 0; i < n && next < 120; i++ {
+			rows = append(rows, Branch{Name: "branch-" + string(rune('a'+next/26)) + string(rune('a'+next%26)), Remote: next == 59})
+			next++
+		}
+		return rows, nil
+	}
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString(strings.Repeat("j", 60)+"\r"), output, checkoutAction)
+	ui.height = 5
+	result, err := ui.SelectWithState([]Branch{{Name: "main", Current: true}}, State{Load: load})
+	if err != nil {
+		t.Fatalf("SelectWithState returned error: %v", err)
+	}
+
+	if result.Branch != "branch-ch" || !result.Remote {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if want := []int{loadPageSize, loadPageSize}; !reflect.DeepEqual(requested, want) {
+		t.Fatalf("loader calls = %v, want %v", requested, want)
+	}
+	frames := framesFromOutput(t, output.String())
+	if !strings.Contains(frames[0], "rows 1-5 of 51+") {
+		t.Fatalf("first frame should report more rows:\n%s", frames[0])
+	}
+}
+
+func TestSelectLoaderError(t *testing.T) {
+	t.Parallel()
+
+	loadErr := errors.New("load failed")
+	ui := New(bytes.NewBufferString("q"), &bytes.Buffer{}, checkoutAction)
+	_, err := ui.SelectWithState([]Branch{{Name: "main", Current: true}}, State{Load: func(int) (Provider, error) {
+		return nil, loadErr
+	}})
+	if !errors.Is(err, loadErr) {
+		t.Fatalf("expected loader error, got %v", err)
+	}
+}
+
+// detailCounter provides n generated rows, the first of them current when
+// current is set, and records which details were read.
+type detailCounter struct {
+	n       int
+	current bool
+	read    map[int]bool
+}
+
+func (d *detailCounter) Len() int { return d.n }
+
+func (d *detailCounter) Branch(i int) Branch {
+	return Branch{Name: fmt.Sprintf("branch-%04d", i), Current: d.current && i == 0}
+}
+
+func (d *detailCounter) Detail(i int) string {
+	d.read[i] = true
+	return fmt.Sprintf("(detail %d)", i)
+}
+
+func TestSelectFromReadsDetailsOnScreenOnly(t *testing.T) {
+	t.Parallel()
+
+	rows := &detailCounter{n: 5000, current: true, read: map[int]bool{}}
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString(strings.Repeat("j", 10)+"\r"), output, checkoutAction)
+	ui.height = 5
+	result, err := ui.SelectFrom(rows, State{})
+	if err != nil {
+		t.Fatalf("SelectFrom returned error: %v", err)
+	}
+
+	if result.Branch != "branch-0010" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	// The window scrolls one row per key from rows 0-4 to rows 6-10.
+	if len(rows.read) != 11 {
+		t.Fatalf("read %d details, want the 11 rows drawn", len(rows.read))
+	}
+	for i := range rows.read {
+		if i > 10 {
+			t.Fatalf("read the detail of off-screen row %d", i)
+		}
+	}
+	frames := framesFromOutput(t, output.String())
+	last := strings.Join(plainLines(frames[len(frames)-1]), lineBreak)
+	if !strings.Contains(last, "branch-0010 (detail 10)") || !strings.Contains(last, "rows 7-11 of 5000") {
+		t.Fatalf("unexpected last frame:\n%s", last)
+	}
+}
+
+func TestSelectFromJoinsLoadedPages(t *testing.T) {
+	t.Parallel()
+
+	pages := []Provider{Branches{{Name: "feature/a"}}, &detailCounter{n: 3, read: map[int]bool{}}}
+	load := func(int) (Provider, error) {
+		if len(pages) == 0 {
+			return nil, nil
+		}
+		page := pages[0]
+		pages = pages[1:]
+		return page, nil
+	}
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("/0002\r"), output, checkoutAction)
+	result, err := ui.SelectFrom(Branches{{Name: "main", Current: true}}, State{Load: load})
+	if err != nil {
+		t.Fatalf("SelectFrom returned error: %v", err)
+	}
+	if result.Branch != "branch-0002" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	frames := framesFromOutput(t, output.String())
+	lines := plainLines(frames[0])
+	for _, row := range []string{"  feature/a", "  branch-0000 (detail 0)", "  branch-0002 (detail 2)"} {
+		if !containsPrefix(lines, row) {
+			t.Fatalf("missing row %q in %q", row, lines)
+		}
+	}
+}
+
+// lockedBuffer collects output written on one goroutine and read on another.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// lateDetails provides rows whose details are filled in while the
+// selector runs.
+type lateDetails struct {
+	mu      sync.Mutex
+	rows    []Branch
+	details map[string]string
+}
+
+func (l *lateDetails) Len() int { return len(l.rows) }
+
+func (l *lateDetails) Branch(i int) Branch { return l.rows[i] }
+
+func (l *lateDetails) Detail(i int) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.details[l.rows[i].Name]
+}
+
+func (l *lateDetails) set(name, detail string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.details[name] = detail
+}
+
+// waitForOutput polls output until it contains text.
+func waitForOutput(t *testing.T, output *lockedBuffer, text string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(ansiSequence.ReplaceAllString(output.String(), ""), text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q in %q", text, output.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSelectFromRedrawsOnUpdates(t *testing.T) {
+	t.Parallel()
+
+	rows := &lateDetails{rows: []Branch{{Name: "main", Current: true}, {Name: "feature/a"}}, details: map[string]string{}}
+	updates := make(chan struct{})
+	input, keys := io.Pipe()
+	output := &lockedBuffer{}
+	ui := New(input, output, checkoutAction)
+
+	type outcome struct {
+		result Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := ui.SelectFrom(rows, State{Updates: updates})
+		done <- outcome{result, err}
+	}()
+
+	waitForOutput(t, output, "feature/a")
+	rows.set("feature/a", "(2 commits)")
+	updates <- struct{}{}
+	waitForOutput(t, output, "feature/a (2 commits)")
+
+	if _, err := keys.Write([]byte("j\r")); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	got := <-done
+	if got.err != nil {
+		t.Fatalf("SelectFrom returned error: %v", got.err)
+	}
+	if got.result.Branch != "feature/a" {
+		t.Fatalf("unexpected result %+v", got.result)
+	}
+
+	// Updates after the selector ended are not drawn.
+	drawn := output.String()
+	select {
+	case updates <- struct{}{}:
+		t.Fatal("an update was received after the selector ended")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if output.String() != drawn {
+		t.Fatal("output changed after the selector ended")
+	}
+}
+
+func TestSelectPlainWaitsForUpdates(t *testing.T) {
+	t.Parallel()
+
+	rows := &lateDetails{rows: []Branch{{Name: "main", Current: true}, {Name: "feature/a"}}, details: map[string]string{}}
+	updates := make(chan struct{})
+	go func() {
+		rows.set("feature/a", "(2 commits)")
+		updates <- struct{}{}
+		close(updates)
+	}()
+
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("q\n"), output, checkoutAction)
+	ui.SetLayout(LayoutPlain)
+	if _, err := ui.SelectFrom(rows, State{Updates: updates}); err != nil {
+		t.Fatalf("SelectFrom returned error: %v", err)
+	}
+	if !strings.Contains(output.String(), "2) feature/a (2 commits)") {
+		t.Fatalf("plain list should show the final details:\n%s", output.String())
+	}
+}
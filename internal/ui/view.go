@@ -0,0 +1,400 @@
+package ui
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"branch-navigator/internal/match"
+)
+
+// listView tracks the rows visible under the current filter and visibility
+// toggles, and the cursor position among them.
+type listView struct {
+	rows   *pages
+	mode   match.Mode
+	query  string
+	filter *match.Filter
+	// input holds the layers of key handling above the normal one, such
+	// as the filter prompt; Esc pops the top one.
+	input inputStack
+	// question is asked in the confirming layer; answer runs when it is
+	// answered with y.
+	question string
+	answer   func() (Result, bool, error)
+	// labels is typed in the labeling layer as the labels of the branch
+	// named labeling, separated by commas.
+	labeling string
+	labels   string
+	// queryErr reports why query could not be compiled; the previous rows stay visible.
+	queryErr error
+	// visibility holds the row toggles; nil shows every row and disables toggling.
+	visibility *Visibility
+	// visible holds indices into rows for the rows currently listed.
+	visible []int
+	// cursor indexes visible.
+	cursor int
+	// notice is a one-off message shown until the next key press.
+	notice string
+	// load supplies further rows on demand; exhausted is set once it has none left.
+	load      Loader
+	exhausted bool
+	// height bounds how many rows are drawn at once; 0 draws every visible row.
+	height int
+	// offset indexes visible for the first drawn row.
+	offset int
+	// marked holds the indices into rows marked in multi-select mode. Marks
+	// survive changes to the filter and toggles.
+	marked map[int]bool
+	// expanded holds the detail lines of the rows expanded beneath their
+	// names, keyed like marked.
+	expanded map[int][]string
+	// updates announces changes to rows that replaced the first ones,
+	// until SelectFrom starts watching it.
+	updates <-chan struct{}
+}
+
+func newListView(rows Provider, query string, mode match.Mode, visibility *Visibility, load Loader) *listView {
+	v := &listView{rows: &pages{}, mode: mode, load: load, exhausted: load == nil}
+	v.rows.add(rows)
+	if visibility != nil {
+		toggles := *visibility
+		v.visibility = &toggles
+	}
+	v.refresh()
+	v.setQuery(query)
+	if v.query != "" {
+		v.input.push(stateFiltered)
+	}
+	return v
+}
+
+// setQuery updates the filter, keeping the cursor on the same branch when it remains visible.
+func (v *listView) setQuery(query string) {
+	v.query = query
+	filter, err := match.NewFilter(query, v.mode)
+	v.queryErr = err
+	if err != nil {
+		return
+	}
+	v.filter = filter
+	v.refresh()
+}
+
+// refresh recomputes the visible rows, keeping the cursor on the same branch when it remains visible.
+func (v *listView) refresh() {
+	previous, hadSelection := v.selected()
+
+	v.visible = v.visible[:0]
+	for p, part := range v.rows.parts {
+		start := v.rows.starts[p]
+		for i, n := 0, part.Len(); i < n; i++ {
+			if branch := part.Branch(i); v.shows(branch) && v.filter.Match(branch.Name) {
+				v.visible = append(v.visible, start+i)
+			}
+		}
+	}
+
+	v.cursor = 0
+	if hadSelection {
+		v.moveTo(previous.Name)
+	}
+}
+
+// filtering reports whether typed keys go to the filter query.
+func (v *listView) filtering() bool {
+	return v.input.top() == stateFiltering
+}
+
+func (v *listView) shows(branch Branch) bool {
+	if v.visibility == nil || branch.Current {
+		return true
+	}
+	if branch.Merged && !v.visibility.Merged {
+		return false
+	}
+	if branch.Stale && !v.visibility.Stale {
+		return false
+	}
+	if branch.Remote && !v.visibility.Remote {
+		return false
+	}
+	return true
+}
+
+// toggle flips the visibility toggle bound to key and reports whether key is a toggle.
+func (v *listView) toggle(key byte) bool {
+	if v.visibility == nil {
+		return false
+	}
+	switch key {
+	case 'm':
+		v.visibility.Merged = !v.visibility.Merged
+	case 's':
+		v.visibility.Stale = !v.visibility.Stale
+	case 'r':
+		v.visibility.Remote = !v.visibility.Remote
+	default:
+		return false
+	}
+	v.refresh()
+	return true
+}
+
+// moveTo places the cursor on the named branch if it is visible and reports whether it did.
+func (v *listView) moveTo(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, idx := range v.visible {
+		if v.rows.Branch(idx).Name == name {
+			v.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+func (v *listView) selected() (Branch, bool) {
+	if v.cursor < 0 || v.cursor >= len(v.visible) {
+		return Branch{}, false
+	}
+	return v.rows.Branch(v.visible[v.cursor]), true
+}
+
+// mark flips the mark of the highlighted row and moves the cursor down, so
+// that consecutive rows are marked by repeating the key. The current branch
+// cannot be marked. It reports whether anything changed.
+func (v *listView) mark() bool {
+	selected, ok := v.selected()
+	if !ok || selected.Current {
+		return false
+	}
+	if v.marked == nil {
+		v.marked = map[int]bool{}
+	}
+	idx := v.visible[v.cursor]
+	if v.marked[idx] {
+		delete(v.marked, idx)
+	} else {
+		v.marked[idx] = true
+	}
+	v.syncMarking()
+	v.down()
+	return true
+}
+
+// markAll marks every visible row other than the current branch, or clears
+// their marks when all of them are marked already. It reports whether
+// anything changed.
+func (v *listView) markAll() bool {
+	if v.marked == nil {
+		v.marked = map[int]bool{}
+	}
+	rows := make([]int, 0, len(v.visible))
+	all := true
+	for _, idx := range v.visible {
+		if v.rows.Branch(idx).Current {
+			continue
+		}
+		rows = append(rows, idx)
+		all = all && v.marked[idx]
+	}
+	for _, idx := range rows {
+		if all {
+			delete(v.marked, idx)
+		} else {
+			v.marked[idx] = true
+		}
+	}
+	v.syncMarking()
+	return len(rows) > 0
+}
+
+// syncMarking moves the marking layer to the top with each change of the
+// marks, since that is the latest step for Esc to undo, and drops it with
+// the last mark.
+func (v *listView) syncMarking() {
+	if len(v.marked) == 0 {
+		v.input.remove(stateMarking)
+		return
+	}
+	v.input.push(stateMarking)
+}
+
+// markedNames returns the names of the marked rows in list order, hidden
+// rows included.
+func (v *listView) markedNames() []string {
+	names := make([]string, 0, len(v.marked))
+	for idx := 0; idx < v.rows.n && len(names) < len(v.marked); idx++ {
+		if v.marked[idx] {
+			names = append(names, v.rows.Branch(idx).Name)
+		}
+	}
+	return names
+}
+
+// markNames marks the loaded rows with the given names, as after the rows
+// were replaced.
+func (v *listView) markNames(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	v.marked = map[int]bool{}
+	for idx := 0; idx < v.rows.n; idx++ {
+		if branch := v.rows.Branch(idx); wanted[branch.Name] && !branch.Current {
+			v.marked[idx] = true
+		}
+	}
+	v.syncMarking()
+}
+
+// replace swaps in rows, and the loader for more of them, for the rows
+// listed so far. The filter and toggles still apply; the marks and the
+// expanded details, which are keyed by row, are dropped.
+func (v *listView) replace(rows Provider, load Loader) {
+	v.rows = &pages{}
+	v.rows.add(rows)
+	v.load, v.exhausted = load, load == nil
+	v.marked, v.expanded = nil, nil
+	v.syncMarking()
+	v.visible = v.visible[:0]
+	v.cursor, v.offset = 0, 0
+	v.refresh()
+}
+
+func (v *listView) up() bool {
+	if v.cursor > 0 {
+		v.cursor--
+		return true
+	}
+	return false
+}
+
+func (v *listView) down() bool {
+	if v.cursor < len(v.visible)-1 {
+		v.cursor++
+		return true
+	}
+	return false
+}
+
+// fill loads pages from the loader until want rows are visible or the loader
+// is exhausted, and reports whether any rows were added.
+func (v *listView) fill(want int) (bool, error) {
+	added := false
+	for !v.exhausted && len(v.visible) < want {
+		rows, err := v.load(loadPageSize)
+		if err != nil {
+			return added, err
+		}
+		if rows == nil || rows.Len() == 0 {
+			v.exhausted = true
+			break
+		}
+		v.rows.add(rows)
+		v.refresh()
+		added = true
+	}
+	return added, nil
+}
+
+// window returns the range of visible rows to draw, scrolling so that the
+// cursor stays in view. Expanded rows take a line for each of their details
+// as well, so that fewer rows fit; the highlighted row is always drawn, even
+// when its details do not fit below it.
+func (v *listView) window() (int, int) {
+	if v.height <= 0 || v.lines(0, len(v.visible)) <= v.height {
+		v.offset = 0
+		return 0, len(v.visible)
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	for v.offset < v.cursor && v.lines(v.offset, v.cursor+1) > v.height {
+		v.offset++
+	}
+	end, used := v.offset, 0
+	for end < len(v.visible) && (end == v.offset || used+v.rowHeight(end) <= v.height) {
+		used += v.rowHeight(end)
+		end++
+	}
+	// At the end of the list, fill the screen with the rows above instead
+	// of leaving it blank below.
+	for end == len(v.visible) && v.offset > 0 && used+v.rowHeight(v.offset-1) <= v.height {
+		v.offset--
+		used += v.rowHeight(v.offset)
+	}
+	return v.offset, end
+}
+
+// rowHeight returns how many lines visible row i takes, its details
+// included.
+func (v *listView) rowHeight(i int) int {
+	return 1 + len(v.expanded[v.visible[i]])
+}
+
+// lines returns how many lines the visible rows from start up to end take.
+func (v *listView) lines(start, end int) int {
+	if len(v.expanded) == 0 {
+		return end - start
+	}
+	n := 0
+	for i := start; i < end; i++ {
+		n += v.rowHeight(i)
+	}
+	return n
+}
+
+func (v *listView) appendQuery(text string) {
+	v.setQuery(v.query + text)
+}
+
+// backspace removes the last rune of the query and reports whether anything was removed.
+func (v *listView) backspace() bool {
+	if v.query == "" {
+		return false
+	}
+	_, size := utf8.DecodeLastRuneInString(v.query)
+	v.setQuery(v.query[:len(v.query)-size])
+	return true
+}
+
+// pages joins the rows read from the initial Provider and from each page a
+// Loader returned into a single Provider, without copying them.
+type pages struct {
+	parts []Provider
+	// starts holds the index of the first row of each part.
+	starts []int
+	n      int
+}
+
+func (p *pages) add(rows Provider) {
+	if rows == nil || rows.Len() == 0 {
+		return
+	}
+	p.parts = append(p.parts, rows)
+	p.starts = append(p.starts, p.n)
+	p.n += rows.Len()
+}
+
+// locate returns the part holding row i and the row's index within it.
+func (p *pages) locate(i int) (Provider, int) {
+	part := sort.Search(len(p.starts), func(k int) bool { return p.starts[k] > i }) - 1
+	return p.parts[part], i - p.starts[part]
+}
+
+func (p *pages) Len() int { return p.n }
+
+func (p *pages) Branch(i int) Branch {
+	part, j := p.locate(i)
+	return part.Branch(j)
+}
+
+func (p *pages) Detail(i int) string {
+	part, j := p.locate(i)
+	return part.Detail(j)
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  int
+	}{
+		"ascii":             {input: "feature/login", want: 13},
+		"latin-accents":     {input: "café", want: 4},
+		"combining-accent":  {input: "café", want: 4},
+		"cjk":               {input: "機能/ログイン", want: 13},
+		"hangul":            {input: "기능", want: 4},
+		"emoji":             {input: "fix/🐛", want: 6},
+		"emoji-zwj":         {input: "👩‍💻", want: 4},
+		"variation":         {input: "☃️", want: 1},
+		"fullwidth":         {input: "ＡＢ", want: 4},
+		"empty":             {input: "", want: 0},
+		"halfwidth-kana":    {input: "ｶﾀｶﾅ", want: 4},
+		"ideographic-space": {input: "a　b", want: 4},
+		"cjk-punctuation":   {input: "「機能」", want: 8},
+		"cjk-extension-b":   {input: "𠮷野家", want: 6},
+		"kana-supplement":   {input: "𛀁", want: 2},
+		"vertical-forms":    {input: "︐", want: 2},
+		"small-forms":       {input: "﹐", want: 2},
+		"hangul-jamo":       {input: "\u1100\u1161\u11a8", want: 2},
+		"bmp-emoji":         {input: "✅ done ⭐", want: 10},
+		"text-symbols":      {input: "→ ✓ ★ ─", want: 7},
+		"skin-tone":         {input: "👍🏽", want: 4},
+		"flag":              {input: "🇯🇵", want: 2},
+		"zero-width-space":  {input: "a\u200bb", want: 2},
+		"controls":          {input: "a\tb\x1b", want: 2},
+		"latin-extended":    {input: "Ærøskøbing", want: 10},
+		"cyrillic":          {input: "ветка", want: 5},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := displayWidth(tc.input); got != tc.want {
+				t.Fatalf("displayWidth(%q) = %d, want %d", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		max   int
+		want  string
+	}{
+		"fits":           {input: "feature/x", max: 9, want: "feature/x"},
+		"ascii":          {input: "feature/login", max: 8, want: "feature…"},
+		"cjk-boundary":   {input: "機能/ログイン", max: 6, want: "機能/…"},
+		"cjk-no-split":   {input: "機能ログイン", max: 6, want: "機能…"},
+		"emoji":          {input: "🐛🐛🐛", max: 5, want: "🐛🐛…"},
+		"combining-kept": {input: "café-and-more", max: 6, want: "café-…"},
+		"combining-cut":  {input: "abcde\u0301f", max: 5, want: "abcd…"},
+		"bmp-emoji":      {input: "✅✅✅", max: 5, want: "✅✅…"},
+		"wide-at-edge":   {input: "a機能", max: 3, want: "a…"},
+		"halfwidth-kana": {input: "ｶﾀｶﾅｶﾀｶﾅ", max: 5, want: "ｶﾀｶﾅ…"},
+		"extension-b":    {input: "𠮷𠮷𠮷", max: 4, want: "𠮷…"},
+		"one-cell":       {input: "feature", max: 1, want: "…"},
+		"no-room":        {input: "feature", max: 0, want: ""},
+		"negative-room":  {input: "feature", max: -3, want: ""},
+	}
+
+	for name, tc := range cases {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := truncateWidth(tc.input, tc.max)
+			if got != tc.want {
+				t.Fatalf("truncateWidth(%q, %d) = %q, want %q", tc.input, tc.max, got, tc.want)
+			}
+			if displayWidth(got) > tc.max && tc.max > 0 {
+				t.Fatalf("truncateWidth(%q, %d) is %d cells wide", tc.input, tc.max, displayWidth(got))
+			}
+		})
+	}
+}
+
+func TestSelectRendersExoticNamesWithinWidth(t *testing.T) {
+	t.Parallel()
+
+	branches := []Branch{
+		{Name: "main", Current: true},
+		{Name: "feature/日本語のとても長いブランチ名"},
+		{Name: "fix/🐛-crash-on-start", Detail: "(merged)"},
+		{Name: "feature/café"},
+		{Name: "feature/x", Detail: "(fix the login form on small screens)"},
+	}
+	output := &bytes.Buffer{}
+	ui := New(bytes.NewBufferString("q"), output, checkoutAction)
+	ui.width = 24
+	if _, err := ui.Select(branches); err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+
+	lines := plainLines(framesFromOutput(t, output.String())[0])
+	want := []string{
+		"> main (current branch)",
+		"  feature/日本語のとて…",
+		"  fix/🐛-crash… (merged)",
+		"  feature/café",
+		"  feature/x (fix the …",
+	}
+	for _, row := range want {
+		if !containsPrefix(lines, row) {
+			t.Fatalf("missing row %q in %q", row, lines)
+		}
+	}
+	for _, line := range lines[4:9] {
+		if w := displayWidth(line); w > 24 {
+			t.Fatalf("row %q is %d cells wide, want at most 24", line, w)
+		}
+	}
+}
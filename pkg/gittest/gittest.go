@@ -0,0 +1,185 @@
+// Package gittest builds throwaway git repositories for tests that need real
+// git instead of a scripted Runner. Every repository lives in a temporary
+// directory removed at the end of the test, ignores the user's and the
+// system's git configuration, and dates its commits and reflog entries from
+// a fixed clock so that tests see the same history on every run.
+package gittest
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Epoch is the date of the initial commit of every Repo.
+var Epoch = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+// Author is the name and email used for every commit.
+const Author = "Git Test <gittest@example.com>"
+
+// DefaultBranch is the branch holding the initial commit.
+const DefaultBranch = "main"
+
+// Repo is a git repository in a temporary directory. Its methods run git
+// with a clock that starts at Epoch and advances a minute per command, and
+// fail the test when git does.
+type Repo struct {
+	// Dir is the root of the working tree.
+	Dir string
+
+	t   testing.TB
+	now time.Time
+}
+
+// New creates a repository with a single empty commit on DefaultBranch. It
+// skips the test when git is not installed.
+func New(t testing.TB) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	r := &Repo{Dir: t.TempDir(), t: t, now: Epoch}
+	r.Git("init", "--quiet", "--initial-branch="+DefaultBranch)
+	r.now = Epoch
+	r.Commit("initial commit")
+	return r
+}
+
+// Env returns the environment the Repo runs git with: the process
+// environment without global or system configuration, and with the commit
+// identity and the current clock. Pass it to commands that run git on Dir
+// themselves.
+func (r *Repo) Env() []string {
+	name, email, _ := strings.Cut(strings.TrimSuffix(Author, ">"), " <")
+	date := r.now.Format(time.RFC3339)
+	return append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_AUTHOR_NAME="+name,
+		"GIT_AUTHOR_EMAIL="+email,
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME="+name,
+		"GIT_COMMITTER_EMAIL="+email,
+		"GIT_COMMITTER_DATE="+date,
+	)
+}
+
+// Now returns the date the next git command records.
+func (r *Repo) Now() time.Time {
+	return r.now
+}
+
+// SetNow sets the date the next git command records; later commands
+// advance from it.
+func (r *Repo) SetNow(now time.Time) {
+	r.now = now
+}
+
+// Git runs git in Dir and returns its trimmed standard output.
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = r.Env()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	r.now = r.now.Add(time.Minute)
+	if err != nil {
+		r.t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String())
+}
+
+// WriteFile writes content to path, relative to Dir, and stages it.
+func (r *Repo) WriteFile(path, content string) {
+	r.t.Helper()
+	full := filepath.Join(r.Dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatalf("create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		r.t.Fatalf("write %s: %v", path, err)
+	}
+	r.Git("add", "--", path)
+}
+
+// Commit commits the staged changes, or an empty commit when nothing is
+// staged, and returns the new commit's full hash.
+func (r *Repo) Commit(message string) string {
+	r.t.Helper()
+	r.Git("commit", "--quiet", "--allow-empty", "-m", message)
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Branch creates branch at start, or at HEAD when start is empty, without
+// checking it out.
+func (r *Repo) Branch(branch, start string) {
+	r.t.Helper()
+	args := []string{"branch", branch}
+	if start != "" {
+		args = append(args, start)
+	}
+	r.Git(args...)
+}
+
+// Checkout switches to branch, which adds a checkout entry to the HEAD
+// reflog.
+func (r *Repo) Checkout(branch string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", branch)
+}
+
+// CheckoutNew creates branch at HEAD and switches to it.
+func (r *Repo) CheckoutNew(branch string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "-b", branch)
+}
+
+// Visit checks out each branch in turn, so that the reflog lists them
+// most recent last.
+func (r *Repo) Visit(branches ...string) {
+	r.t.Helper()
+	for _, branch := range branches {
+		r.Checkout(branch)
+	}
+}
+
+// Detach checks out rev with a detached HEAD.
+func (r *Repo) Detach(rev string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "--detach", rev)
+}
+
+// AddWorktree checks out branch in a new linked worktree and returns its
+// directory. The branch must exist and not be checked out elsewhere.
+func (r *Repo) AddWorktree(branch string) string {
+	r.t.Helper()
+	dir := filepath.Join(r.t.TempDir(), "worktree")
+	r.Git("worktree", "add", "--quiet", dir, branch)
+	return dir
+}
+
+// AddRemote creates an empty bare repository, registers it as the remote
+// name, and returns its directory.
+func (r *Repo) AddRemote(name string) string {
+	r.t.Helper()
+	dir := filepath.Join(r.t.TempDir(), name+".git")
+	r.Git("init", "--quiet", "--bare", dir)
+	r.Git("remote", "add", name, dir)
+	return dir
+}
+
+// Push pushes branches to remote, which also updates their remote-tracking
+// branches.
+func (r *Repo) Push(remote string, branches ...string) {
+	r.t.Helper()
+	r.Git(append([]string{"push", "--quiet", remote}, branches...)...)
+}
@@ -0,0 +1,84 @@
+package gittest
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	repo := New(t)
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != DefaultBranch {
+		t.Fatalf("current branch = %q, want %q", got, DefaultBranch)
+	}
+	if got := repo.Git("log", "--format=%an <%ae> %aI %s"); got != Author+" 2024-01-01T09:00:00+00:00 initial commit" {
+		t.Fatalf("unexpected initial commit %q", got)
+	}
+}
+
+func TestRepoHistory(t *testing.T) {
+	t.Parallel()
+
+	repo := New(t)
+	repo.CheckoutNew("feature/a")
+	repo.WriteFile("docs/a.txt", "a\n")
+	commit := repo.Commit("add a")
+	repo.Branch("feature/b", DefaultBranch)
+	repo.Visit(DefaultBranch, "feature/b", "feature/a")
+
+	if got := repo.Git("rev-parse", "feature/a"); got != commit {
+		t.Fatalf("feature/a = %q, want %q", got, commit)
+	}
+	if got := repo.Git("show", "--format=", "--name-only", "feature/a"); got != "docs/a.txt" {
+		t.Fatalf("unexpected files in the commit: %q", got)
+	}
+	moves := repo.Git("reflog", "--format=%gs", "-3")
+	want := "checkout: moving from feature/b to feature/a\ncheckout: moving from main to feature/b\ncheckout: moving from feature/a to main"
+	if moves != want {
+		t.Fatalf("reflog = %q, want %q", moves, want)
+	}
+
+	at := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	repo.SetNow(at)
+	repo.Commit("dated")
+	if got := repo.Git("log", "-1", "--format=%cI"); got != "2025-06-01T12:00:00+00:00" {
+		t.Fatalf("commit date = %q", got)
+	}
+	if !repo.Now().After(at) {
+		t.Fatalf("clock did not advance past %v: %v", at, repo.Now())
+	}
+}
+
+func TestRepoDetachWorktreeAndRemote(t *testing.T) {
+	t.Parallel()
+
+	repo := New(t)
+	repo.Branch("feature/a", "")
+	repo.Branch("feature/b", "")
+
+	worktree := repo.AddWorktree("feature/b")
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = worktree
+	cmd.Env = repo.Env()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git in worktree: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "feature/b" {
+		t.Fatalf("worktree branch = %q, want feature/b", got)
+	}
+
+	repo.AddRemote("origin")
+	repo.Push("origin", "feature/a")
+	if got := repo.Git("for-each-ref", "--format=%(refname:short)", "refs/remotes"); got != "origin/feature/a" {
+		t.Fatalf("remote-tracking branches = %q", got)
+	}
+
+	repo.Detach("feature/a")
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != "HEAD" {
+		t.Fatalf("HEAD = %q, want a detached HEAD", got)
+	}
+}